@@ -0,0 +1,470 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/gogf/gf/v2/container/gvar"
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/constants"
+	"github.com/yalks/wallet/entity"
+	"github.com/yalks/wallet/logic"
+)
+
+// AdjustmentStatus 调账申请状态
+type AdjustmentStatus = logic.AdjustmentStatus
+
+const (
+	AdjustmentStatusPending  = logic.AdjustmentStatusPending  // 待审批
+	AdjustmentStatusExecuted = logic.AdjustmentStatusExecuted // 已审批并执行
+	AdjustmentStatusRejected = logic.AdjustmentStatusRejected // 已驳回
+)
+
+// ApprovalThreshold 审批阈值
+type ApprovalThreshold = logic.ApprovalThreshold
+
+// AdjustmentApprovalConfig 后台调账双人复核配置（对应配置项 wallet.approvals）
+type AdjustmentApprovalConfig struct {
+	Enforce    bool                `json:"enforce"`    // 开启后 admin_add、admin_deduct、system_adjustment 只能通过审批流程执行（默认开启）
+	Thresholds []ApprovalThreshold `json:"thresholds"` // 金额阈值与所需审批人数，未达到任何阈值时需要 1 位审批人
+}
+
+// approvedAdjustmentKey 标记资金操作由调账审批流程发起
+type approvedAdjustmentKey struct{}
+
+// adjustmentWorkflow 后台调账审批流程
+type adjustmentWorkflow struct {
+	context *logic.SharedLogicContext
+	config  AdjustmentApprovalConfig
+}
+
+// newAdjustmentWorkflow 创建后台调账审批流程
+func newAdjustmentWorkflow(ctx context.Context) *adjustmentWorkflow {
+	return &adjustmentWorkflow{
+		context: logic.GetSharedContext(),
+		config:  loadAdjustmentApprovalConfig(ctx),
+	}
+}
+
+// DefaultAdjustmentApprovalConfig 默认后台调账双人复核配置：强制复核，未配置阈值时需要 1 位审批人
+func DefaultAdjustmentApprovalConfig() AdjustmentApprovalConfig {
+	return AdjustmentApprovalConfig{Enforce: true}
+}
+
+// loadAdjustmentApprovalConfig 从配置中读取后台调账双人复核配置，缺省项使用默认值
+func loadAdjustmentApprovalConfig(ctx context.Context) AdjustmentApprovalConfig {
+	value, err := g.Cfg().Get(ctx, "wallet.approvals")
+	if err != nil || value == nil || value.IsEmpty() {
+		return DefaultAdjustmentApprovalConfig()
+	}
+	return parseAdjustmentApprovalConfig(ctx, value.MapStrVar())
+}
+
+// parseAdjustmentApprovalConfig 解析后台调账双人复核配置，关闭强制复核时输出告警
+func parseAdjustmentApprovalConfig(ctx context.Context, raw map[string]*gvar.Var) AdjustmentApprovalConfig {
+	config := DefaultAdjustmentApprovalConfig()
+
+	if v, ok := raw["enforce"]; ok {
+		config.Enforce = v.Bool()
+	}
+	if !config.Enforce {
+		g.Log().Warning(ctx, "后台调账强制复核已关闭 (wallet.approvals.enforce=false)：admin_add、admin_deduct、system_adjustment 可以不经审批直接执行")
+	}
+	if v, ok := raw["thresholds"]; ok {
+		for _, item := range v.Maps() {
+			amount, err := decimal.NewFromString(gconv.String(item["amount"]))
+			approvals := gconv.Int(item["approvals"])
+			if err != nil || approvals <= 0 {
+				g.Log().Warningf(ctx, "忽略无效的调账审批阈值: %v", item)
+				continue
+			}
+			config.Thresholds = append(config.Thresholds, ApprovalThreshold{
+				Symbol:    gconv.String(item["symbol"]),
+				Amount:    amount,
+				Approvals: approvals,
+			})
+		}
+	}
+
+	return config
+}
+
+// authorize 开启强制复核时，拒绝未经审批流程发起的后台调账
+func (w *adjustmentWorkflow) authorize(ctx context.Context, fundType constants.FundType) error {
+	if !w.config.Enforce || !logic.RequiresAdjustmentApproval(fundType) {
+		return nil
+	}
+	if approved, _ := ctx.Value(approvedAdjustmentKey{}).(bool); approved {
+		return nil
+	}
+	return gerror.Newf("资金类型 %s 需要通过调账审批流程执行，请使用 SubmitAdjustment", fundType)
+}
+
+// SubmitAdjustment 提交后台调账申请，审批通过前不会变动余额
+func (m *walletManager) SubmitAdjustment(ctx context.Context, req *AdjustmentSubmitRequest) (*AdjustmentInfo, error) {
+	if req == nil {
+		return nil, gerror.New("调账申请不能为空")
+	}
+	if req.OperatorID == "" {
+		return nil, gerror.New("提交人不能为空")
+	}
+	if req.UserID == 0 {
+		return nil, gerror.New("用户ID不能为空")
+	}
+	if !logic.RequiresAdjustmentApproval(req.FundType) {
+		return nil, gerror.Newf("资金类型 %s 不属于后台调账", req.FundType)
+	}
+	direction, err := constants.ResolveFundDirection(req.FundType, req.Direction)
+	if err != nil {
+		return nil, gerror.Wrap(err, "资金方向无效")
+	}
+	if req.Amount.LessThanOrEqual(decimal.Zero) {
+		return nil, gerror.New("金额必须大于0")
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, gerror.New("调账原因不能为空")
+	}
+	if _, err := m.userLogic.GetUserByID(ctx, req.UserID); err != nil {
+		return nil, gerror.Wrapf(err, "获取用户信息失败: UserID=%d", req.UserID)
+	}
+	token, err := m.tokenLogic.GetTokenBySymbol(ctx, req.TokenSymbol)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "获取代币信息失败: Symbol=%s", req.TokenSymbol)
+	}
+
+	metadata := ""
+	if len(req.Metadata) > 0 {
+		data, err := json.Marshal(req.Metadata)
+		if err != nil {
+			return nil, gerror.Wrap(err, "序列化调账元数据失败")
+		}
+		metadata = string(data)
+	}
+
+	dao := m.adjustments.context.GetAdjustmentDAO()
+	now := gtime.Now()
+	request := &entity.AdjustmentRequests{
+		UserId:            req.UserID,
+		Symbol:            token.Symbol,
+		FundType:          string(req.FundType),
+		Direction:         string(direction),
+		Amount:            req.Amount,
+		Reason:            strings.TrimSpace(req.Reason),
+		AttachmentRef:     req.AttachmentRef,
+		Metadata:          metadata,
+		RequiredApprovals: logic.RequiredApprovals(m.adjustments.config.Thresholds, token.Symbol, req.Amount),
+		Status:            string(AdjustmentStatusPending),
+		SubmittedBy:       req.OperatorID,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	err = m.RunInTransaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		id, err := dao.CreateRequest(ctx, tx, request)
+		if err != nil {
+			return err
+		}
+		request.Id = id
+		return dao.CreateAction(ctx, tx, &entity.AdjustmentActions{
+			RequestId: id,
+			AdminId:   req.OperatorID,
+			Action:    string(logic.AdjustmentActionSubmit),
+			Comment:   request.Reason,
+			CreatedAt: now,
+		})
+	})
+	if err != nil {
+		return nil, gerror.Wrap(err, "提交调账申请失败")
+	}
+
+	g.Log().Infof(ctx, "调账申请已提交: RequestID=%d, UserID=%d, Amount=%s %s, FundType=%s, Direction=%s, RequiredApprovals=%d, Operator=%s",
+		request.Id, request.UserId, request.Amount.String(), request.Symbol, request.FundType, request.Direction, request.RequiredApprovals, req.OperatorID)
+	return m.GetAdjustment(ctx, request.Id)
+}
+
+// ApproveAdjustment 审批调账申请；审批人数达到要求时在同一事务中执行资金操作。
+// 提交人不能审批自己的申请，每位管理员只能审批一次
+func (m *walletManager) ApproveAdjustment(ctx context.Context, requestID uint64, approverID, comment string) (*AdjustmentInfo, error) {
+	dao := m.adjustments.context.GetAdjustmentDAO()
+	var executed bool
+
+	err := m.RunInTransaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		request, err := m.adjustments.actionable(ctx, tx, requestID, approverID)
+		if err != nil {
+			return err
+		}
+
+		now := gtime.Now()
+		err = dao.CreateAction(ctx, tx, &entity.AdjustmentActions{
+			RequestId: requestID,
+			AdminId:   approverID,
+			Action:    string(logic.AdjustmentActionApprove),
+			Comment:   comment,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return err
+		}
+
+		data, execute := adjustmentUpdate(request, logic.AdjustmentActionApprove, now)
+		if execute {
+			transactionID, err := m.executeAdjustment(ctx, tx, request)
+			if err != nil {
+				return err
+			}
+			data["transaction_id"] = transactionID
+			executed = true
+		}
+
+		updated, err := dao.UpdatePendingRequest(ctx, tx, requestID, request.ApprovalCount, data)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return gerror.Newf("调账申请已被其他审批人更新，请重试: RequestID=%d", requestID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, gerror.Wrapf(err, "审批调账申请失败: RequestID=%d", requestID)
+	}
+
+	g.Log().Infof(ctx, "调账申请已审批: RequestID=%d, Approver=%s, Executed=%v", requestID, approverID, executed)
+	return m.GetAdjustment(ctx, requestID)
+}
+
+// RejectAdjustment 驳回调账申请，不执行资金操作
+func (m *walletManager) RejectAdjustment(ctx context.Context, requestID uint64, approverID, reason string) (*AdjustmentInfo, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, gerror.New("驳回原因不能为空")
+	}
+
+	dao := m.adjustments.context.GetAdjustmentDAO()
+	err := m.RunInTransaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		request, err := m.adjustments.actionable(ctx, tx, requestID, approverID)
+		if err != nil {
+			return err
+		}
+
+		now := gtime.Now()
+		err = dao.CreateAction(ctx, tx, &entity.AdjustmentActions{
+			RequestId: requestID,
+			AdminId:   approverID,
+			Action:    string(logic.AdjustmentActionReject),
+			Comment:   reason,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return err
+		}
+
+		data, _ := adjustmentUpdate(request, logic.AdjustmentActionReject, now)
+		updated, err := dao.UpdatePendingRequest(ctx, tx, requestID, request.ApprovalCount, data)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return gerror.Newf("调账申请已被其他审批人更新，请重试: RequestID=%d", requestID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, gerror.Wrapf(err, "驳回调账申请失败: RequestID=%d", requestID)
+	}
+
+	g.Log().Infof(ctx, "调账申请已驳回: RequestID=%d, Approver=%s", requestID, approverID)
+	return m.GetAdjustment(ctx, requestID)
+}
+
+// GetAdjustment 获取调账申请及其全部审批记录
+func (m *walletManager) GetAdjustment(ctx context.Context, requestID uint64) (*AdjustmentInfo, error) {
+	dao := m.adjustments.context.GetAdjustmentDAO()
+	request, err := dao.GetRequest(ctx, nil, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request == nil {
+		return nil, gerror.Newf("调账申请不存在: RequestID=%d", requestID)
+	}
+
+	actions, err := dao.ListActions(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	info := convertToAdjustmentInfo(request)
+	for _, action := range actions {
+		info.Actions = append(info.Actions, convertToAdjustmentActionInfo(action))
+	}
+	return info, nil
+}
+
+// ListAdjustments 获取调账申请列表（status 为空时不限状态，不含审批记录）
+func (m *walletManager) ListAdjustments(ctx context.Context, status AdjustmentStatus, limit, offset int) ([]*AdjustmentInfo, error) {
+	requests, err := m.adjustments.context.GetAdjustmentDAO().ListRequests(ctx, string(status), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*AdjustmentInfo, 0, len(requests))
+	for _, request := range requests {
+		infos = append(infos, convertToAdjustmentInfo(request))
+	}
+	return infos, nil
+}
+
+// actionable 检查管理员能否审批或驳回申请
+func (w *adjustmentWorkflow) actionable(ctx context.Context, tx gdb.TX, requestID uint64, adminID string) (*entity.AdjustmentRequests, error) {
+	if adminID == "" {
+		return nil, gerror.New("审批人不能为空")
+	}
+
+	dao := w.context.GetAdjustmentDAO()
+	request, err := dao.GetRequest(ctx, tx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request == nil {
+		return nil, gerror.Newf("调账申请不存在: RequestID=%d", requestID)
+	}
+
+	acted, err := dao.HasAction(ctx, tx, requestID, adminID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAdjustmentActor(request, adminID, acted); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// checkAdjustmentActor 校验申请仍待审批、管理员不是提交人且尚未审批过（acted 表示已有该管理员的审批记录）
+func checkAdjustmentActor(request *entity.AdjustmentRequests, adminID string, acted bool) error {
+	if request.Status != string(AdjustmentStatusPending) {
+		return gerror.Newf("调账申请已处理: RequestID=%d, Status=%s", request.Id, request.Status)
+	}
+	if request.SubmittedBy == adminID {
+		return gerror.New("提交人不能审批自己的调账申请")
+	}
+	if acted {
+		return gerror.Newf("管理员已审批过该申请: RequestID=%d, Admin=%s", request.Id, adminID)
+	}
+	return nil
+}
+
+// adjustmentUpdate 计算审批或驳回后申请需要更新的字段，execute 表示本次审批达到所需人数、需要执行资金操作
+func adjustmentUpdate(request *entity.AdjustmentRequests, action logic.AdjustmentAction, now *gtime.Time) (data map[string]any, execute bool) {
+	if action == logic.AdjustmentActionReject {
+		return map[string]any{
+			"status":       string(AdjustmentStatusRejected),
+			"completed_at": now,
+		}, false
+	}
+
+	data = map[string]any{"approval_count": request.ApprovalCount + 1}
+	if request.ApprovalCount+1 >= request.RequiredApprovals {
+		data["status"] = string(AdjustmentStatusExecuted)
+		data["completed_at"] = now
+		execute = true
+	}
+	return data, execute
+}
+
+// executeAdjustment 在审批事务中执行调账资金操作，返回交易ID
+func (m *walletManager) executeAdjustment(ctx context.Context, tx gdb.TX, request *entity.AdjustmentRequests) (uint64, error) {
+	actions, err := m.adjustments.context.GetAdjustmentDAO().ListActions(ctx, request.Id)
+	if err != nil {
+		return 0, err
+	}
+	approvers := make([]string, 0, len(actions))
+	for _, action := range actions {
+		if action.Action == string(logic.AdjustmentActionApprove) {
+			approvers = append(approvers, action.AdminId)
+		}
+	}
+
+	metadata := map[string]string{}
+	if request.Metadata != "" {
+		if err := json.Unmarshal([]byte(request.Metadata), &metadata); err != nil {
+			return 0, gerror.Wrapf(err, "解析调账元数据失败: RequestID=%d", request.Id)
+		}
+	}
+	metadata[constants.MetadataKeyAdjustmentID] = strconv.FormatUint(request.Id, 10)
+	metadata[constants.MetadataKeySubmittedBy] = request.SubmittedBy
+	metadata[constants.MetadataKeyApprovedBy] = strings.Join(approvers, ",")
+	if request.AttachmentRef != "" {
+		metadata[constants.MetadataKeyAttachmentRef] = request.AttachmentRef
+	}
+
+	ctx = logic.WithOperationApproved(context.WithValue(ctx, approvedAdjustmentKey{}, true))
+	result, err := m.processFundOperationInTxInternal(ctx, tx, &FundOperationRequest{
+		UserID:        request.UserId,
+		TokenSymbol:   request.Symbol,
+		Amount:        request.Amount,
+		BusinessID:    "adjustment_" + strconv.FormatUint(request.Id, 10),
+		FundType:      constants.FundType(request.FundType),
+		Direction:     constants.FundDirection(request.Direction),
+		Description:   request.Reason,
+		Metadata:      metadata,
+		RequestSource: "admin",
+	})
+	if err != nil {
+		return 0, gerror.Wrap(err, "执行调账失败")
+	}
+
+	transactionID, err := strconv.ParseUint(result.TransactionID, 10, 64)
+	if err != nil {
+		return 0, gerror.Wrapf(err, "无效的交易ID: %s", result.TransactionID)
+	}
+	return transactionID, nil
+}
+
+// convertToAdjustmentInfo 转换调账申请
+func convertToAdjustmentInfo(request *entity.AdjustmentRequests) *AdjustmentInfo {
+	info := &AdjustmentInfo{
+		ID:                request.Id,
+		UserID:            request.UserId,
+		TokenSymbol:       request.Symbol,
+		FundType:          constants.FundType(request.FundType),
+		Direction:         constants.FundDirection(request.Direction),
+		Amount:            request.Amount,
+		Reason:            request.Reason,
+		AttachmentRef:     request.AttachmentRef,
+		RequiredApprovals: request.RequiredApprovals,
+		ApprovalCount:     request.ApprovalCount,
+		Status:            AdjustmentStatus(request.Status),
+		SubmittedBy:       request.SubmittedBy,
+		TransactionID:     request.TransactionId,
+	}
+	if request.Metadata != "" {
+		_ = json.Unmarshal([]byte(request.Metadata), &info.Metadata)
+	}
+	if request.CreatedAt != nil {
+		info.CreatedAt = request.CreatedAt.String()
+	}
+	if request.CompletedAt != nil {
+		info.CompletedAt = request.CompletedAt.String()
+	}
+	return info
+}
+
+// convertToAdjustmentActionInfo 转换调账审批记录
+func convertToAdjustmentActionInfo(action *entity.AdjustmentActions) *AdjustmentActionInfo {
+	info := &AdjustmentActionInfo{
+		AdminID: action.AdminId,
+		Action:  action.Action,
+		Comment: action.Comment,
+	}
+	if action.CreatedAt != nil {
+		info.CreatedAt = action.CreatedAt.String()
+	}
+	return info
+}
@@ -0,0 +1,101 @@
+package wallet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gogf/gf/v2/container/gvar"
+	"github.com/gogf/gf/v2/os/gtime"
+
+	"github.com/yalks/wallet/constants"
+	"github.com/yalks/wallet/entity"
+	"github.com/yalks/wallet/logic"
+)
+
+func TestParseAdjustmentApprovalConfig(t *testing.T) {
+	ctx := context.Background()
+	if config := parseAdjustmentApprovalConfig(ctx, nil); !config.Enforce || len(config.Thresholds) != 0 {
+		t.Fatalf("defaults = %+v", config)
+	}
+
+	config := parseAdjustmentApprovalConfig(ctx, map[string]*gvar.Var{
+		"enforce": gvar.New(false),
+		"thresholds": gvar.New([]interface{}{
+			map[string]interface{}{"amount": "1000", "approvals": 2},
+			map[string]interface{}{"symbol": "BTC", "amount": "1", "approvals": 3},
+			map[string]interface{}{"amount": "abc", "approvals": 2},
+			map[string]interface{}{"amount": "10", "approvals": 0},
+		}),
+	})
+	if config.Enforce {
+		t.Error("enforce = true, want false")
+	}
+	if len(config.Thresholds) != 2 || config.Thresholds[1].Symbol != "BTC" || config.Thresholds[1].Approvals != 3 {
+		t.Errorf("thresholds = %+v", config.Thresholds)
+	}
+}
+
+func TestAdjustmentAuthorize(t *testing.T) {
+	ctx := context.Background()
+	workflow := &adjustmentWorkflow{config: DefaultAdjustmentApprovalConfig()}
+
+	if err := workflow.authorize(ctx, constants.FundTypeAdminAdd); err == nil {
+		t.Error("direct admin_add accepted with enforcement on")
+	}
+	if err := workflow.authorize(context.WithValue(ctx, approvedAdjustmentKey{}, true), constants.FundTypeAdminAdd); err != nil {
+		t.Errorf("approved admin_add rejected: %v", err)
+	}
+	if err := workflow.authorize(ctx, constants.FundTypeDeposit); err != nil {
+		t.Errorf("deposit rejected: %v", err)
+	}
+
+	workflow.config.Enforce = false
+	if err := workflow.authorize(ctx, constants.FundTypeAdminAdd); err != nil {
+		t.Errorf("admin_add rejected with enforcement off: %v", err)
+	}
+}
+
+func TestCheckAdjustmentActor(t *testing.T) {
+	request := &entity.AdjustmentRequests{Id: 1, Status: string(AdjustmentStatusPending), SubmittedBy: "alice"}
+
+	if err := checkAdjustmentActor(request, "bob", false); err != nil {
+		t.Errorf("other admin: %v", err)
+	}
+	if err := checkAdjustmentActor(request, "alice", false); err == nil {
+		t.Error("self-approval accepted")
+	}
+	if err := checkAdjustmentActor(request, "bob", true); err == nil {
+		t.Error("duplicate approver accepted")
+	}
+
+	for _, status := range []AdjustmentStatus{AdjustmentStatusExecuted, AdjustmentStatusRejected} {
+		request.Status = string(status)
+		if err := checkAdjustmentActor(request, "bob", false); err == nil {
+			t.Errorf("%s request accepted", status)
+		}
+	}
+}
+
+func TestAdjustmentUpdate(t *testing.T) {
+	now := gtime.Now()
+	request := &entity.AdjustmentRequests{RequiredApprovals: 2, Status: string(AdjustmentStatusPending)}
+
+	// 2 人审批：第 1 位审批只累加人数
+	data, execute := adjustmentUpdate(request, logic.AdjustmentActionApprove, now)
+	if execute || data["approval_count"] != 1 || data["status"] != nil {
+		t.Errorf("first approval: data = %v, execute = %v", data, execute)
+	}
+
+	// 第 2 位审批达到人数，执行资金操作
+	request.ApprovalCount = 1
+	data, execute = adjustmentUpdate(request, logic.AdjustmentActionApprove, now)
+	if !execute || data["approval_count"] != 2 || data["status"] != string(AdjustmentStatusExecuted) || data["completed_at"] != now {
+		t.Errorf("final approval: data = %v, execute = %v", data, execute)
+	}
+
+	// 驳回不执行资金操作，也不累加审批人数
+	data, execute = adjustmentUpdate(request, logic.AdjustmentActionReject, now)
+	if execute || data["status"] != string(AdjustmentStatusRejected) || data["approval_count"] != nil {
+		t.Errorf("reject: data = %v, execute = %v", data, execute)
+	}
+}
@@ -0,0 +1,96 @@
+package wallet
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+
+	"github.com/yalks/wallet/logic"
+)
+
+// APIKeyPermission API Key 可调用的接口权限
+type APIKeyPermission = logic.APIKeyPermission
+
+const (
+	APIKeyPermissionAll              = logic.APIKeyPermissionAll              // 全部权限
+	APIKeyPermissionBalancesRead     = logic.APIKeyPermissionBalancesRead     // 查询余额
+	APIKeyPermissionTransactionsRead = logic.APIKeyPermissionTransactionsRead // 查询交易
+	APIKeyPermissionQuotesRead       = logic.APIKeyPermissionQuotesRead       // 预估资金操作
+	APIKeyPermissionOperationsWrite  = logic.APIKeyPermissionOperationsWrite  // 执行资金操作
+	APIKeyPermissionTransfersWrite   = logic.APIKeyPermissionTransfersWrite   // 执行转账
+	APIKeyPermissionEventsRead       = logic.APIKeyPermissionEventsRead       // 订阅余额与交易变动
+)
+
+// APIKey API Key 信息（不含密钥）
+type APIKey = logic.APIKey
+
+// APIKeyScope API Key 的权限范围
+type APIKeyScope = logic.APIKeyScope
+
+// APIKeyAccess 一次接口调用需要的权限
+type APIKeyAccess = logic.APIKeyAccess
+
+// CreateAPIKeyRequest 创建 API Key 请求
+type CreateAPIKeyRequest = logic.CreateAPIKeyRequest
+
+// APIKeyRequest 一次需要认证的 HTTP 请求
+type APIKeyRequest = logic.APIKeyRequest
+
+// APIKeyRateLimitError API Key 超出每分钟请求数
+type APIKeyRateLimitError = logic.APIKeyRateLimitError
+
+// APIKeyCredentials 新建的 API Key；Secret 只返回这一次，无法再次查询
+type APIKeyCredentials struct {
+	Key    *APIKey `json:"key"`
+	Secret string  `json:"secret"`
+}
+
+// API Key 错误码，可通过 gerror.Code(err) 识别
+var (
+	CodeAPIKeyForbidden   = logic.CodeAPIKeyForbidden
+	CodeAPIKeyRateLimited = logic.CodeAPIKeyRateLimited
+)
+
+// SignAPIRequest 客户端计算请求签名，作为 X-Signature 请求头发送
+func SignAPIRequest(secret, method, path, rawQuery, timestamp, nonce string, body []byte) string {
+	return logic.SignAPIRequest(secret, method, path, rawQuery, timestamp, nonce, body)
+}
+
+// WithAPIKey 在 context 中记录认证通过的 API Key
+func WithAPIKey(ctx context.Context, key *APIKey) context.Context {
+	return logic.WithAPIKey(ctx, key)
+}
+
+// APIKeyFromContext 获取 context 中认证通过的 API Key，未认证时返回 nil
+func APIKeyFromContext(ctx context.Context) *APIKey {
+	return logic.APIKeyFromContext(ctx)
+}
+
+// CreateAPIKey 创建 API Key
+func (m *walletManager) CreateAPIKey(ctx context.Context, req *CreateAPIKeyRequest) (*APIKeyCredentials, error) {
+	key, secret, err := m.apiKeyLogic.Create(ctx, req)
+	if err != nil {
+		return nil, gerror.Wrap(err, "创建API Key失败")
+	}
+	return &APIKeyCredentials{Key: key, Secret: secret}, nil
+}
+
+// ListAPIKeys 获取 API Key 列表，includeRevoked 为 true 时包含已吊销的 Key
+func (m *walletManager) ListAPIKeys(ctx context.Context, includeRevoked bool) ([]*APIKey, error) {
+	return m.apiKeyLogic.List(ctx, includeRevoked)
+}
+
+// RevokeAPIKey 吊销 API Key，立即生效
+func (m *walletManager) RevokeAPIKey(ctx context.Context, keyID string) error {
+	return m.apiKeyLogic.Revoke(ctx, keyID)
+}
+
+// AuthenticateAPIKey 认证一次 HTTP 请求
+func (m *walletManager) AuthenticateAPIKey(ctx context.Context, req *APIKeyRequest) (*APIKey, error) {
+	return m.apiKeyLogic.Authenticate(ctx, req)
+}
+
+// PurgeAPIKeyNonces 清理已过重放窗口的请求随机数，返回删除数量
+func (m *walletManager) PurgeAPIKeyNonces(ctx context.Context) (int64, error) {
+	return m.apiKeyLogic.PurgeNonces(ctx)
+}
@@ -0,0 +1,436 @@
+package wallet
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/os/gtimer"
+
+	"github.com/yalks/wallet/entity"
+	"github.com/yalks/wallet/logic"
+)
+
+// AuditConfig 审计哈希链配置（对应配置项 wallet.audit）
+type AuditConfig struct {
+	SigningKey         string        `json:"signingKey"`         // 检查点签名私钥（base64 编码的 32 字节 Ed25519 种子），为空时不能生成检查点
+	CheckpointInterval time.Duration `json:"checkpointInterval"` // 定时生成检查点的间隔
+	BatchSize          int           `json:"batchSize"`          // 校验和生成检查点时每次读取的审计记录数
+}
+
+// DefaultAuditConfig 默认审计哈希链配置
+func DefaultAuditConfig() AuditConfig {
+	return AuditConfig{
+		CheckpointInterval: time.Hour,
+		BatchSize:          500,
+	}
+}
+
+// auditCheckpointSettleDelay 检查点只覆盖写入时间早于该间隔的审计记录
+const auditCheckpointSettleDelay = time.Minute
+
+// 审计链校验失败原因
+const (
+	AuditBrokenSequence     = "sequence_gap"          // 序号不连续（记录被删除或插入）
+	AuditBrokenPrevHash     = "prev_hash_mismatch"    // 与上一条记录的哈希不衔接
+	AuditBrokenHash         = "hash_mismatch"         // 记录哈希与内容不符（记录被修改）
+	AuditBrokenPayload      = "payload_mismatch"      // 交易记录与审计内容不符（交易被修改）
+	AuditBrokenMissing      = "transaction_missing"   // 被审计的交易记录已不存在
+	AuditBrokenStatus       = "status_mismatch"       // 交易当前状态与审计链记录的状态不符
+	AuditBrokenUnaudited    = "unaudited_transaction" // 存在未进入审计链的交易记录
+	AuditBrokenUnknownEntry = "unknown_entry"         // 无法识别的审计记录
+)
+
+// auditTrail 审计哈希链校验与检查点
+type auditTrail struct {
+	context *logic.SharedLogicContext
+	config  AuditConfig
+
+	mu    sync.Mutex
+	entry *gtimer.Entry
+
+	// checkpointMu 保证同一进程内只有一个检查点在生成
+	checkpointMu sync.Mutex
+}
+
+// newAuditTrail 创建审计哈希链校验与检查点组件
+func newAuditTrail(ctx context.Context) *auditTrail {
+	return &auditTrail{
+		context: logic.GetSharedContext(),
+		config:  loadAuditConfig(ctx),
+	}
+}
+
+// loadAuditConfig 从配置中读取审计哈希链配置，缺省项使用默认值
+func loadAuditConfig(ctx context.Context) AuditConfig {
+	config := DefaultAuditConfig()
+
+	value, err := g.Cfg().Get(ctx, "wallet.audit")
+	if err != nil || value == nil || value.IsEmpty() {
+		return config
+	}
+
+	raw := value.MapStrVar()
+	if v, ok := raw["signingKey"]; ok {
+		config.SigningKey = v.String()
+	}
+	if v, ok := raw["checkpointInterval"]; ok {
+		if d, err := time.ParseDuration(v.String()); err == nil && d > 0 {
+			config.CheckpointInterval = d
+		}
+	}
+	if v, ok := raw["batchSize"]; ok && v.Int() > 0 {
+		config.BatchSize = v.Int()
+	}
+
+	return config
+}
+
+// VerifyAuditChain 校验用户某代币钱包的审计哈希链：逐条检查序号连续、哈希衔接、记录哈希，
+// 并将交易记录和当前状态与审计内容比对，报告第一处断裂
+func (m *walletManager) VerifyAuditChain(ctx context.Context, userID uint64, tokenSymbol string) (*AuditVerificationReport, error) {
+	return m.audit.verify(ctx, userID, tokenSymbol)
+}
+
+// CreateAuditCheckpoint 生成签名检查点，覆盖上一个检查点之后的全部审计记录；没有新记录时返回 nil
+func (m *walletManager) CreateAuditCheckpoint(ctx context.Context) (*AuditCheckpointInfo, error) {
+	return m.audit.checkpoint(ctx)
+}
+
+// ExportAuditCheckpoints 以 JSON Lines 格式导出全部检查点（含公钥和签名，可离线校验），返回导出数量
+func (m *walletManager) ExportAuditCheckpoints(ctx context.Context, w io.Writer) (int, error) {
+	return m.audit.export(ctx, w)
+}
+
+// StartAuditCheckpointJob 启动定时生成审计检查点的任务
+func (m *walletManager) StartAuditCheckpointJob(ctx context.Context) error {
+	return m.audit.start(ctx)
+}
+
+// StopAuditCheckpointJob 停止定时生成审计检查点的任务
+func (m *walletManager) StopAuditCheckpointJob(ctx context.Context) {
+	m.audit.stop(ctx)
+}
+
+// start 启动定时任务
+func (a *auditTrail) start(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.entry != nil {
+		return gerror.New("审计检查点任务已启动")
+	}
+	if _, err := a.signingKey(); err != nil {
+		return err
+	}
+
+	a.entry = gtimer.AddSingleton(ctx, a.config.CheckpointInterval, func(ctx context.Context) {
+		if _, err := a.checkpoint(ctx); err != nil {
+			g.Log().Errorf(ctx, "生成审计检查点失败: %v", err)
+		}
+	})
+
+	g.Log().Infof(ctx, "审计检查点任务已启动: Interval=%s", a.config.CheckpointInterval)
+	return nil
+}
+
+// stop 停止定时任务
+func (a *auditTrail) stop(ctx context.Context) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.entry != nil {
+		a.entry.Close()
+		a.entry = nil
+		g.Log().Info(ctx, "审计检查点任务已停止")
+	}
+}
+
+// signingKey 解析配置中的检查点签名私钥
+func (a *auditTrail) signingKey() (ed25519.PrivateKey, error) {
+	if a.config.SigningKey == "" {
+		return nil, gerror.New("未配置审计签名私钥 (wallet.audit.signingKey)")
+	}
+	return logic.ParseAuditSigningKey(a.config.SigningKey)
+}
+
+// verify 按序号逐页校验哈希链，最后比对交易当前状态并检查是否有未进入审计链的交易
+func (a *auditTrail) verify(ctx context.Context, userID uint64, tokenSymbol string) (*AuditVerificationReport, error) {
+	dao := a.context.GetAuditDAO()
+	verifier := newAuditChainVerifier(logic.WalletAggregateID(userID, tokenSymbol))
+	report := &AuditVerificationReport{UserID: userID, TokenSymbol: tokenSymbol, ChainID: verifier.chainID}
+
+	var afterSequence uint64
+	for {
+		entries, err := dao.ListChainEntries(ctx, verifier.chainID, afterSequence, a.config.BatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		// 批量读取本页交易记录的当前值
+		ids := make([]uint64, 0, len(entries))
+		for _, entry := range entries {
+			if entry.EntryType == string(logic.AuditEntryTransaction) {
+				ids = append(ids, entry.RefId)
+			}
+		}
+		rows, err := dao.GetTransactionsByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		transactions := make(map[uint64]*entity.Transactions, len(rows))
+		for _, row := range rows {
+			transactions[row.TransactionId] = row
+		}
+
+		for _, entry := range entries {
+			if reason := verifier.check(entry, transactions[entry.RefId]); reason != "" {
+				return verifier.broken(report, entry.Sequence, entry.RefId, reason), nil
+			}
+		}
+
+		if len(entries) < a.config.BatchSize {
+			break
+		}
+		afterSequence = entries[len(entries)-1].Sequence
+	}
+
+	// 交易当前状态必须与审计链中最后记录的状态一致
+	ids := make([]uint64, 0, len(verifier.transactions))
+	for id := range verifier.transactions {
+		ids = append(ids, id)
+	}
+	for start := 0; start < len(ids); start += a.config.BatchSize {
+		end := min(start+a.config.BatchSize, len(ids))
+		rows, err := dao.GetTransactionsByIDs(ctx, ids[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			audited := verifier.transactions[row.TransactionId]
+			if row.Status != audited.status {
+				return verifier.broken(report, audited.sequence, row.TransactionId, AuditBrokenStatus), nil
+			}
+		}
+	}
+
+	// 审计链覆盖的ID区间内不应存在未审计的交易（区间之前的历史交易不在链上）
+	if verifier.transactionCount > 0 {
+		count, err := dao.CountTransactionsBetween(ctx, userID, tokenSymbol, verifier.firstTransactionID, verifier.lastTransactionID)
+		if err != nil {
+			return nil, err
+		}
+		if count != verifier.transactionCount {
+			return verifier.broken(report, verifier.sequence, 0, AuditBrokenUnaudited), nil
+		}
+	}
+
+	report.Valid = true
+	report.LastValidSequence = verifier.sequence
+	report.LastValidHash = verifier.prevHash
+	return report, nil
+}
+
+// checkpoint 在上一个检查点的根哈希上按ID顺序累加新的审计记录哈希，签名后保存
+func (a *auditTrail) checkpoint(ctx context.Context) (*AuditCheckpointInfo, error) {
+	key, err := a.signingKey()
+	if err != nil {
+		return nil, err
+	}
+
+	a.checkpointMu.Lock()
+	defer a.checkpointMu.Unlock()
+
+	dao := a.context.GetAuditDAO()
+	last, err := dao.GetLastCheckpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoint := &entity.AuditCheckpoints{}
+	if last != nil {
+		checkpoint.PrevRoot = last.Root
+		checkpoint.LastEntryId = last.LastEntryId
+	}
+	checkpoint.Root = checkpoint.PrevRoot
+
+	// 自增ID的分配顺序与事务提交顺序不一定一致，只覆盖已写入超过 auditCheckpointSettleDelay 的记录，
+	// 避免仍在提交中的较小ID记录被后续检查点永久跳过
+	cutoff := time.Now().Add(-auditCheckpointSettleDelay)
+	for done := false; !done; {
+		entries, err := dao.ListEntriesAfterID(ctx, checkpoint.LastEntryId, a.config.BatchSize)
+		if err != nil {
+			return nil, err
+		}
+		done = len(entries) < a.config.BatchSize
+		for _, entry := range entries {
+			if entry.CreatedAt != nil && entry.CreatedAt.Time.After(cutoff) {
+				done = true
+				break
+			}
+			checkpoint.Root = logic.AdvanceCheckpointRoot(checkpoint.Root, entry.Hash)
+			checkpoint.LastEntryId = entry.Id
+			checkpoint.EntryCount++
+		}
+	}
+
+	if checkpoint.EntryCount == 0 {
+		return nil, nil
+	}
+
+	logic.SignAuditCheckpoint(checkpoint, key)
+	checkpoint.CreatedAt = gtime.Now()
+	id, err := dao.CreateCheckpoint(ctx, checkpoint)
+	if err != nil {
+		return nil, err
+	}
+	checkpoint.Id = id
+
+	g.Log().Infof(ctx, "审计检查点已生成: ID=%d, LastEntryID=%d, Entries=%d", id, checkpoint.LastEntryId, checkpoint.EntryCount)
+	return convertToAuditCheckpointInfo(checkpoint), nil
+}
+
+// export 按ID顺序逐页导出检查点
+func (a *auditTrail) export(ctx context.Context, w io.Writer) (int, error) {
+	if w == nil {
+		return 0, gerror.New("检查点输出不能为空")
+	}
+
+	encoder := json.NewEncoder(w)
+	count := 0
+	var afterID uint64
+	for {
+		checkpoints, err := a.context.GetAuditDAO().ListCheckpoints(ctx, afterID, a.config.BatchSize)
+		if err != nil {
+			return count, err
+		}
+		for _, checkpoint := range checkpoints {
+			if err := encoder.Encode(convertToAuditCheckpointInfo(checkpoint)); err != nil {
+				return count, gerror.Wrap(err, "写入检查点失败")
+			}
+			count++
+		}
+		if len(checkpoints) < a.config.BatchSize {
+			break
+		}
+		afterID = checkpoints[len(checkpoints)-1].Id
+	}
+	return count, nil
+}
+
+// auditedTransaction 审计链中记录的交易状态
+type auditedTransaction struct {
+	status   uint   // 审计链中最后记录的状态
+	sequence uint64 // 最后一条相关审计记录的序号
+}
+
+// auditChainVerifier 按序号逐条校验一条哈希链
+type auditChainVerifier struct {
+	chainID  string
+	sequence uint64 // 已校验的最后序号
+	prevHash string // 已校验的最后哈希
+
+	transactions       map[uint64]*auditedTransaction
+	transactionCount   int
+	firstTransactionID uint64
+	lastTransactionID  uint64
+}
+
+func newAuditChainVerifier(chainID string) *auditChainVerifier {
+	return &auditChainVerifier{
+		chainID:      chainID,
+		transactions: make(map[uint64]*auditedTransaction),
+	}
+}
+
+// check 校验下一条审计记录，transaction 为交易记录的当前值（交易记录类型的审计记录使用，不存在时为 nil）；
+// 通过时返回空字符串，否则返回断裂原因
+func (v *auditChainVerifier) check(entry *entity.AuditEntries, transaction *entity.Transactions) string {
+	if entry.Sequence != v.sequence+1 {
+		return AuditBrokenSequence
+	}
+	if entry.PrevHash != v.prevHash {
+		return AuditBrokenPrevHash
+	}
+	if entry.Hash != logic.AuditEntryHash(entry.PrevHash, v.chainID, entry.Sequence, logic.AuditEntryType(entry.EntryType), entry.Payload) {
+		return AuditBrokenHash
+	}
+
+	switch logic.AuditEntryType(entry.EntryType) {
+	case logic.AuditEntryTransaction:
+		var audited logic.TransactionAuditPayload
+		if err := json.Unmarshal([]byte(entry.Payload), &audited); err != nil {
+			return AuditBrokenPayload
+		}
+		if transaction == nil {
+			return AuditBrokenMissing
+		}
+		// 状态以审计内容为准重新生成，状态的后续变化由状态变更记录和最终比对校验
+		payload, err := logic.EncodeAuditPayload(logic.NewTransactionAuditPayload(transaction, audited.Status))
+		if err != nil || payload != entry.Payload {
+			return AuditBrokenPayload
+		}
+		if _, ok := v.transactions[entry.RefId]; ok {
+			return AuditBrokenUnknownEntry
+		}
+		v.transactions[entry.RefId] = &auditedTransaction{status: audited.Status, sequence: entry.Sequence}
+		v.transactionCount++
+		if v.firstTransactionID == 0 || entry.RefId < v.firstTransactionID {
+			v.firstTransactionID = entry.RefId
+		}
+		if entry.RefId > v.lastTransactionID {
+			v.lastTransactionID = entry.RefId
+		}
+	case logic.AuditEntryStatusChange:
+		var change logic.StatusChangeAuditPayload
+		if err := json.Unmarshal([]byte(entry.Payload), &change); err != nil || change.TransactionID != entry.RefId {
+			return AuditBrokenPayload
+		}
+		audited, ok := v.transactions[entry.RefId]
+		if !ok || audited.status != change.OldStatus {
+			return AuditBrokenStatus
+		}
+		audited.status = change.NewStatus
+		audited.sequence = entry.Sequence
+	default:
+		return AuditBrokenUnknownEntry
+	}
+
+	v.sequence = entry.Sequence
+	v.prevHash = entry.Hash
+	return ""
+}
+
+// broken 填充断裂信息
+func (v *auditChainVerifier) broken(report *AuditVerificationReport, sequence, transactionID uint64, reason string) *AuditVerificationReport {
+	report.Valid = false
+	report.LastValidSequence = v.sequence
+	report.LastValidHash = v.prevHash
+	report.BrokenSequence = sequence
+	report.BrokenTransactionID = transactionID
+	report.Reason = reason
+	return report
+}
+
+// convertToAuditCheckpointInfo 转换审计检查点
+func convertToAuditCheckpointInfo(checkpoint *entity.AuditCheckpoints) *AuditCheckpointInfo {
+	info := &AuditCheckpointInfo{
+		ID:          checkpoint.Id,
+		LastEntryID: checkpoint.LastEntryId,
+		EntryCount:  checkpoint.EntryCount,
+		PrevRoot:    checkpoint.PrevRoot,
+		Root:        checkpoint.Root,
+		PublicKey:   checkpoint.PublicKey,
+		Signature:   checkpoint.Signature,
+	}
+	if checkpoint.CreatedAt != nil {
+		info.CreatedAt = checkpoint.CreatedAt.Time
+	}
+	return info
+}
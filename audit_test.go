@@ -0,0 +1,115 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/entity"
+	"github.com/yalks/wallet/logic"
+)
+
+// buildAuditChain builds a valid chain: a transaction followed by a status change
+func buildAuditChain(t *testing.T, chainID string, transaction *entity.Transactions) []*entity.AuditEntries {
+	transactionPayload, err := logic.EncodeAuditPayload(logic.NewTransactionAuditPayload(transaction, 1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	statusPayload, err := logic.EncodeAuditPayload(&logic.StatusChangeAuditPayload{TransactionID: transaction.TransactionId, OldStatus: 1, NewStatus: 0})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var entries []*entity.AuditEntries
+	prevHash := ""
+	for i, item := range []struct {
+		entryType logic.AuditEntryType
+		payload   string
+	}{
+		{logic.AuditEntryTransaction, transactionPayload},
+		{logic.AuditEntryStatusChange, statusPayload},
+	} {
+		sequence := uint64(i + 1)
+		entry := &entity.AuditEntries{
+			ChainId:   chainID,
+			Sequence:  sequence,
+			EntryType: string(item.entryType),
+			RefId:     transaction.TransactionId,
+			Payload:   item.payload,
+			PrevHash:  prevHash,
+			Hash:      logic.AuditEntryHash(prevHash, chainID, sequence, item.entryType, item.payload),
+		}
+		entries = append(entries, entry)
+		prevHash = entry.Hash
+	}
+	return entries
+}
+
+// TestAuditChainVerifier verifies that the first broken link is reported
+func TestAuditChainVerifier(t *testing.T) {
+	chainID := logic.WalletAggregateID(1001, "USDT")
+	newTransaction := func() *entity.Transactions {
+		return &entity.Transactions{
+			TransactionId: 7,
+			UserId:        1001,
+			Symbol:        "USDT",
+			Type:          "deposit",
+			Direction:     "in",
+			Amount:        decimal.RequireFromString("10"),
+			BalanceAfter:  decimal.RequireFromString("10"),
+			Status:        0,
+			CreatedAt:     gtime.NewFromStr("2024-01-02 03:04:05"),
+		}
+	}
+
+	verify := func(entries []*entity.AuditEntries, transaction *entity.Transactions) (string, uint64) {
+		verifier := newAuditChainVerifier(chainID)
+		for _, entry := range entries {
+			if reason := verifier.check(entry, transaction); reason != "" {
+				return reason, entry.Sequence
+			}
+		}
+		if audited := verifier.transactions[transaction.TransactionId]; audited.status != transaction.Status {
+			return AuditBrokenStatus, audited.sequence
+		}
+		return "", 0
+	}
+
+	if reason, _ := verify(buildAuditChain(t, chainID, newTransaction()), newTransaction()); reason != "" {
+		t.Fatalf("expected valid chain, got %s", reason)
+	}
+
+	tests := []struct {
+		name     string
+		tamper   func(entries []*entity.AuditEntries, transaction *entity.Transactions) []*entity.AuditEntries
+		reason   string
+		sequence uint64
+	}{
+		{"deleted entry", func(e []*entity.AuditEntries, _ *entity.Transactions) []*entity.AuditEntries { return e[1:] }, AuditBrokenSequence, 2},
+		{"edited payload", func(e []*entity.AuditEntries, _ *entity.Transactions) []*entity.AuditEntries {
+			e[0].Payload = `{"transaction_id":7}`
+			return e
+		}, AuditBrokenHash, 1},
+		{"relinked entry", func(e []*entity.AuditEntries, _ *entity.Transactions) []*entity.AuditEntries {
+			e[1].PrevHash = "forged"
+			return e
+		}, AuditBrokenPrevHash, 2},
+		{"edited transaction", func(e []*entity.AuditEntries, tx *entity.Transactions) []*entity.AuditEntries {
+			tx.Amount = decimal.RequireFromString("1000")
+			return e
+		}, AuditBrokenPayload, 1},
+		{"edited status", func(e []*entity.AuditEntries, tx *entity.Transactions) []*entity.AuditEntries {
+			tx.Status = 1
+			return e
+		}, AuditBrokenStatus, 2},
+	}
+	for _, tt := range tests {
+		transaction := newTransaction()
+		entries := tt.tamper(buildAuditChain(t, chainID, newTransaction()), transaction)
+		reason, sequence := verify(entries, transaction)
+		if reason != tt.reason || sequence != tt.sequence {
+			t.Errorf("%s: expected %s at %d, got %s at %d", tt.name, tt.reason, tt.sequence, reason, sequence)
+		}
+	}
+}
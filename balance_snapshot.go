@@ -0,0 +1,249 @@
+package wallet
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcron"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/entity"
+	"github.com/yalks/wallet/logic"
+)
+
+// BalanceSnapshotConfig 每日余额快照配置（对应配置项 wallet.snapshots）
+type BalanceSnapshotConfig struct {
+	Cron      string `json:"cron"`      // 快照任务的 cron 表达式（含秒），在每天凌晨为前一天生成快照
+	BatchSize int    `json:"batchSize"` // 每次读取的钱包数
+}
+
+// DefaultBalanceSnapshotConfig 默认每日余额快照配置
+func DefaultBalanceSnapshotConfig() BalanceSnapshotConfig {
+	return BalanceSnapshotConfig{
+		Cron:      "0 10 0 * * *",
+		BatchSize: 500,
+	}
+}
+
+// balanceSnapshotJob 每日余额快照任务
+type balanceSnapshotJob struct {
+	context *logic.SharedLogicContext
+	config  BalanceSnapshotConfig
+
+	mu    sync.Mutex
+	entry *gcron.Entry
+}
+
+// newBalanceSnapshotJob 创建每日余额快照任务
+func newBalanceSnapshotJob(ctx context.Context) *balanceSnapshotJob {
+	return &balanceSnapshotJob{
+		context: logic.GetSharedContext(),
+		config:  loadBalanceSnapshotConfig(ctx),
+	}
+}
+
+// loadBalanceSnapshotConfig 从配置中读取每日余额快照配置，缺省项使用默认值
+func loadBalanceSnapshotConfig(ctx context.Context) BalanceSnapshotConfig {
+	config := DefaultBalanceSnapshotConfig()
+
+	value, err := g.Cfg().Get(ctx, "wallet.snapshots")
+	if err != nil || value == nil || value.IsEmpty() {
+		return config
+	}
+
+	raw := value.MapStrVar()
+	if v, ok := raw["cron"]; ok && v.String() != "" {
+		config.Cron = v.String()
+	}
+	if v, ok := raw["batchSize"]; ok && v.Int() > 0 {
+		config.BatchSize = v.Int()
+	}
+
+	return config
+}
+
+// GetBalanceAt 获取用户某代币在指定时刻的可用余额，由交易链计算：
+// 取该时刻之前最近的日终快照，再以快照之后、该时刻之前的最后一笔成功交易的交易后余额为准
+func (m *walletManager) GetBalanceAt(ctx context.Context, userID uint64, tokenSymbol string, at time.Time) (decimal.Decimal, error) {
+	balance, _, err := m.snapshots.balanceAt(ctx, userID, tokenSymbol, at)
+	return balance, err
+}
+
+// CreateBalanceSnapshots 为指定日期生成全部钱包的日终余额快照（可重复执行，已存在的快照会被覆盖），返回快照数量
+func (m *walletManager) CreateBalanceSnapshots(ctx context.Context, date time.Time) (int, error) {
+	return m.snapshots.run(ctx, date)
+}
+
+// ListBalanceSnapshots 获取某日的日终余额快照（tokenSymbol 为空时不限代币）
+func (m *walletManager) ListBalanceSnapshots(ctx context.Context, date time.Time, tokenSymbol string, limit, offset int) ([]*BalanceSnapshotInfo, error) {
+	snapshots, err := m.snapshots.context.GetBalanceSnapshotDAO().ListSnapshotsByDate(ctx, gtime.New(date), tokenSymbol, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*BalanceSnapshotInfo, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		infos = append(infos, convertToBalanceSnapshotInfo(snapshot))
+	}
+	return infos, nil
+}
+
+// StartBalanceSnapshotJob 启动每日余额快照任务
+func (m *walletManager) StartBalanceSnapshotJob(ctx context.Context) error {
+	return m.snapshots.start(ctx)
+}
+
+// StopBalanceSnapshotJob 停止每日余额快照任务
+func (m *walletManager) StopBalanceSnapshotJob(ctx context.Context) {
+	m.snapshots.stop(ctx)
+}
+
+// start 启动定时任务
+func (j *balanceSnapshotJob) start(ctx context.Context) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.entry != nil {
+		return gerror.New("余额快照任务已启动")
+	}
+
+	entry, err := gcron.AddSingleton(ctx, j.config.Cron, func(ctx context.Context) {
+		yesterday := time.Now().AddDate(0, 0, -1)
+		if _, err := j.run(ctx, yesterday); err != nil {
+			g.Log().Errorf(ctx, "生成余额快照失败: Date=%s, Error=%v", yesterday.Format(time.DateOnly), err)
+		}
+	})
+	if err != nil {
+		return gerror.Wrapf(err, "启动余额快照任务失败: Cron=%s", j.config.Cron)
+	}
+	j.entry = entry
+
+	g.Log().Infof(ctx, "余额快照任务已启动: Cron=%s", j.config.Cron)
+	return nil
+}
+
+// stop 停止定时任务
+func (j *balanceSnapshotJob) stop(ctx context.Context) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.entry != nil {
+		j.entry.Close()
+		j.entry = nil
+		g.Log().Info(ctx, "余额快照任务已停止")
+	}
+}
+
+// run 按钱包ID分批为指定日期生成日终余额快照
+func (j *balanceSnapshotJob) run(ctx context.Context, date time.Time) (int, error) {
+	day := startOfDay(date)
+	dayEnd := day.AddDate(0, 0, 1)
+	if dayEnd.After(time.Now()) {
+		return 0, gerror.Newf("只能为已结束的日期生成快照: Date=%s", day.Format(time.DateOnly))
+	}
+
+	walletDAO := j.context.GetWalletDAO()
+	snapshotDAO := j.context.GetBalanceSnapshotDAO()
+
+	count := 0
+	afterWalletID := 0
+	for {
+		wallets, err := walletDAO.ListWalletsAfter(ctx, afterWalletID, j.config.BatchSize)
+		if err != nil {
+			return count, err
+		}
+
+		for _, wallet := range wallets {
+			balance, lastTransactionID, err := j.balanceAt(ctx, uint64(wallet.UserId), wallet.Symbol, dayEnd)
+			if err != nil {
+				return count, err
+			}
+			err = snapshotDAO.SaveSnapshot(ctx, &entity.BalanceSnapshots{
+				UserId:            uint64(wallet.UserId),
+				Symbol:            wallet.Symbol,
+				SnapshotDate:      gtime.New(day),
+				Balance:           balance,
+				LastTransactionId: lastTransactionID,
+			})
+			if err != nil {
+				return count, err
+			}
+			count++
+		}
+
+		if len(wallets) < j.config.BatchSize {
+			break
+		}
+		afterWalletID = wallets[len(wallets)-1].WalletId
+	}
+
+	g.Log().Infof(ctx, "余额快照生成完成: Date=%s, Wallets=%d", day.Format(time.DateOnly), count)
+	return count, nil
+}
+
+// balanceAt 计算指定时刻的可用余额，同时返回截至该时刻的最后一笔交易ID
+// 先读取最近的快照，只查询快照截止时刻之后的交易；没有快照时才回溯全部历史交易
+func (j *balanceSnapshotJob) balanceAt(ctx context.Context, userID uint64, symbol string, at time.Time) (decimal.Decimal, uint64, error) {
+	snapshot, err := j.context.GetBalanceSnapshotDAO().GetLatestSnapshot(ctx, userID, symbol, gtime.New(latestUsableSnapshotDate(at)))
+	if err != nil {
+		return decimal.Zero, 0, err
+	}
+
+	var since *gtime.Time
+	if snapshot != nil && snapshot.SnapshotDate != nil {
+		since = gtime.New(snapshotEnd(snapshot.SnapshotDate.Time))
+	}
+	last, err := j.context.GetTransactionDAO().GetLastTransactionBetween(ctx, userID, symbol, since, gtime.New(at))
+	if err != nil {
+		return decimal.Zero, 0, err
+	}
+
+	balance, lastTransactionID := resolveBalanceAt(snapshot, last)
+	return balance, lastTransactionID, nil
+}
+
+// latestUsableSnapshotDate 返回计算 at 时刻余额可用的最晚快照日期
+// 日期为 D 的快照表示 D+1 零点的余额，因此只能使用 at 所在日期前一天及更早的快照
+func latestUsableSnapshotDate(at time.Time) time.Time {
+	return startOfDay(at).AddDate(0, 0, -1)
+}
+
+// snapshotEnd 返回快照日期对应的截止时刻（次日零点）
+func snapshotEnd(date time.Time) time.Time {
+	return startOfDay(date).AddDate(0, 0, 1)
+}
+
+// resolveBalanceAt 根据快照和快照之后的最后一笔交易确定余额，交易优先于快照
+func resolveBalanceAt(snapshot *entity.BalanceSnapshots, last *entity.Transactions) (decimal.Decimal, uint64) {
+	if last != nil {
+		return last.BalanceAfter, last.TransactionId
+	}
+	if snapshot != nil {
+		return snapshot.Balance, snapshot.LastTransactionId
+	}
+	return decimal.Zero, 0
+}
+
+// startOfDay 返回本地时区当天零点
+func startOfDay(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// convertToBalanceSnapshotInfo 转换余额快照
+func convertToBalanceSnapshotInfo(snapshot *entity.BalanceSnapshots) *BalanceSnapshotInfo {
+	info := &BalanceSnapshotInfo{
+		UserID:            snapshot.UserId,
+		TokenSymbol:       snapshot.Symbol,
+		Balance:           snapshot.Balance,
+		LastTransactionID: snapshot.LastTransactionId,
+	}
+	if snapshot.SnapshotDate != nil {
+		info.Date = snapshot.SnapshotDate.Format("Y-m-d")
+	}
+	return info
+}
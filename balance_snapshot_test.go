@@ -0,0 +1,62 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/entity"
+)
+
+func TestStartOfDay(t *testing.T) {
+	at := time.Date(2026, 3, 15, 23, 59, 59, 999, time.Local)
+	want := time.Date(2026, 3, 15, 0, 0, 0, 0, time.Local)
+	if got := startOfDay(at); !got.Equal(want) {
+		t.Errorf("startOfDay(%v) = %v, want %v", at, got, want)
+	}
+	if got := startOfDay(want); !got.Equal(want) {
+		t.Errorf("startOfDay(midnight) = %v, want %v", got, want)
+	}
+}
+
+func TestSnapshotDates(t *testing.T) {
+	// 2026-03-16 零点的余额可以使用 2026-03-15 的快照，其截止时刻正是 2026-03-16 零点
+	at := time.Date(2026, 3, 16, 0, 0, 0, 0, time.Local)
+	date := latestUsableSnapshotDate(at)
+	if want := time.Date(2026, 3, 15, 0, 0, 0, 0, time.Local); !date.Equal(want) {
+		t.Errorf("latestUsableSnapshotDate = %v, want %v", date, want)
+	}
+	if end := snapshotEnd(date); !end.Equal(at) {
+		t.Errorf("snapshotEnd = %v, want %v", end, at)
+	}
+}
+
+func TestResolveBalanceAt(t *testing.T) {
+	snapshot := &entity.BalanceSnapshots{
+		SnapshotDate:      gtime.New(time.Date(2026, 3, 15, 0, 0, 0, 0, time.Local)),
+		Balance:           decimal.NewFromInt(100),
+		LastTransactionId: 10,
+	}
+	last := &entity.Transactions{TransactionId: 12, BalanceAfter: decimal.NewFromInt(80)}
+
+	tests := []struct {
+		name     string
+		snapshot *entity.BalanceSnapshots
+		last     *entity.Transactions
+		balance  decimal.Decimal
+		id       uint64
+	}{
+		{"no data", nil, nil, decimal.Zero, 0},
+		{"snapshot only", snapshot, nil, decimal.NewFromInt(100), 10},
+		{"transaction only", nil, last, decimal.NewFromInt(80), 12},
+		{"transaction after snapshot", snapshot, last, decimal.NewFromInt(80), 12},
+	}
+	for _, tt := range tests {
+		balance, id := resolveBalanceAt(tt.snapshot, tt.last)
+		if !balance.Equal(tt.balance) || id != tt.id {
+			t.Errorf("%s: got %s/%d, want %s/%d", tt.name, balance, id, tt.balance, tt.id)
+		}
+	}
+}
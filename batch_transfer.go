@@ -0,0 +1,434 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/constants"
+	"github.com/yalks/wallet/entity"
+	"github.com/yalks/wallet/logic"
+)
+
+// batchLeg 校验后的单笔批量转账
+type batchLeg struct {
+	Index       int
+	ToUserID    uint64
+	TokenSymbol string
+	Amount      decimal.Decimal
+	Description string
+	Metadata    map[string]string
+	Callback    map[string]string
+}
+
+// ProcessBatchTransferInTx 在事务中执行批量转账（请求由 constants.BuildBatchTransfer 构建）
+// 执行前统一校验整批请求及发送方各代币的总余额；同一发送方重复提交同一批次ID时直接返回首次执行的结果
+func (m *walletManager) ProcessBatchTransferInTx(ctx context.Context, tx gdb.TX, req *BatchTransferRequest) (*BatchTransferResult, error) {
+	if req == nil {
+		return nil, gerror.New("批量转账请求不能为空")
+	}
+	if tx == nil {
+		return nil, gerror.New("批量转账必须在数据库事务中执行")
+	}
+
+	mode := req.Mode
+	if mode == "" {
+		mode = BatchModeAllOrNothing
+	}
+	if mode != BatchModeAllOrNothing && mode != BatchModeBestEffort {
+		return nil, gerror.Newf("无效的批量转账模式: %s", mode)
+	}
+
+	// 1. 整批校验
+	batchID, fromUserID, legs, err := m.prepareBatchTransfer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 批次幂等检查
+	dao := logic.GetSharedContext().GetBatchTransferDAO()
+	existing, err := dao.GetBatchTransferByBatchID(ctx, tx, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if err := checkBatchTransferOwner(existing, fromUserID); err != nil {
+			return nil, err
+		}
+		g.Log().Infof(ctx, "幂等性检查: 批量转账已执行 BatchID=%s", batchID)
+		return convertToBatchTransferResult(existing)
+	}
+
+	// 3. 发送方总余额校验（按代币汇总）
+	if err := m.validateBatchBalance(ctx, fromUserID, legs); err != nil {
+		return nil, err
+	}
+
+	// 4. 逐笔执行
+	result := &BatchTransferResult{
+		BatchID:    batchID,
+		FromUserID: fromUserID,
+		Mode:       mode,
+		Legs:       make([]*BatchTransferLegResult, 0, len(legs)),
+	}
+	totalAmount := decimal.Zero
+
+	for _, leg := range legs {
+		legResult := &BatchTransferLegResult{
+			Index:       leg.Index,
+			ToUserID:    leg.ToUserID,
+			TokenSymbol: leg.TokenSymbol,
+			Amount:      leg.Amount,
+		}
+		result.Legs = append(result.Legs, legResult)
+
+		savepoint := fmt.Sprintf("batch_leg_%d", leg.Index)
+		queue := afterCommitQueueFromCtx(ctx)
+		queueMark := 0
+		if mode == BatchModeBestEffort {
+			if err := tx.SavePoint(savepoint); err != nil {
+				return nil, gerror.Wrapf(err, "创建事务保存点失败: %s", savepoint)
+			}
+			if queue != nil {
+				queueMark = queue.mark()
+			}
+		}
+
+		transfer, err := m.executeBatchLeg(ctx, tx, batchID, fromUserID, leg, req)
+		if err != nil {
+			legResult.Error = err.Error()
+			result.Failed++
+
+			if mode == BatchModeAllOrNothing {
+				return result, gerror.Wrapf(err, "批量转账第%d笔执行失败，整批回滚", leg.Index)
+			}
+			if rbErr := tx.RollbackTo(savepoint); rbErr != nil {
+				return nil, gerror.Wrapf(rbErr, "回滚到事务保存点失败: %s", savepoint)
+			}
+			// 已回滚的转账腿不执行 AfterCommit 钩子
+			if queue != nil {
+				queue.truncate(queueMark)
+			}
+			g.Log().Warningf(ctx, "批量转账单笔失败已跳过: BatchID=%s, Index=%d, Error=%v", batchID, leg.Index, err)
+			if err := enqueueWebhookInTx(ctx, tx, leg.Callback, batchLegEvent(batchID, fromUserID, leg, legResult)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		legResult.Success = true
+		legResult.FromTransactionID = transfer.FromTransactionID
+		legResult.ToTransactionID = transfer.ToTransactionID
+		legResult.FromBalanceAfter = transfer.FromBalanceAfter
+		legResult.ToBalanceAfter = transfer.ToBalanceAfter
+		result.Succeeded++
+		totalAmount = totalAmount.Add(leg.Amount)
+
+		if err := enqueueWebhookInTx(ctx, tx, leg.Callback, batchLegEvent(batchID, fromUserID, leg, legResult)); err != nil {
+			return nil, err
+		}
+	}
+
+	// 5. 记录批次结果（与转账在同一事务中提交）
+	report, err := json.Marshal(result.Legs)
+	if err != nil {
+		return nil, gerror.Wrap(err, "序列化批量转账结果失败")
+	}
+	if err := dao.CreateBatchTransfer(ctx, tx, &entity.BatchTransfers{
+		BatchId:        batchID,
+		FromUserId:     uint(fromUserID),
+		Mode:           string(mode),
+		LegCount:       len(legs),
+		SucceededCount: result.Succeeded,
+		FailedCount:    result.Failed,
+		TotalAmount:    totalAmount,
+		Report:         string(report),
+		CreatedAt:      gtime.Now(),
+	}); err != nil {
+		return nil, err
+	}
+
+	g.Log().Infof(ctx, "批量转账完成: BatchID=%s, Mode=%s, Succeeded=%d, Failed=%d", batchID, mode, result.Succeeded, result.Failed)
+	return result, nil
+}
+
+// prepareBatchTransfer 将转出/转入请求按序号配对并校验，返回批次ID、发送方与待执行列表
+func (m *walletManager) prepareBatchTransfer(ctx context.Context, req *BatchTransferRequest) (string, uint64, []*batchLeg, error) {
+	if len(req.Requests) == 0 {
+		return "", 0, nil, gerror.New("批量转账请求列表不能为空")
+	}
+
+	var (
+		errs       []string
+		batchID    = req.BatchID
+		fromUserID uint64
+		outs       = make(map[int]*constants.TransactionRequestEnhanced)
+		ins        = make(map[int]*constants.TransactionRequestEnhanced)
+		references = make(map[string]struct{})
+	)
+
+	for i, r := range req.Requests {
+		if r == nil {
+			errs = append(errs, fmt.Sprintf("请求%d为空", i))
+			continue
+		}
+
+		index, err := strconv.Atoi(fmt.Sprintf("%v", r.Metadata[constants.MetadataKeyBatchIndex]))
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("请求%d缺少有效的批次序号 (%s)", i, constants.MetadataKeyBatchIndex))
+			continue
+		}
+
+		if reference, ok := r.Metadata[constants.MetadataKeyBatchReference]; ok {
+			references[fmt.Sprintf("%v", reference)] = struct{}{}
+		}
+
+		switch r.FundType {
+		case constants.FundTypeTransferOut:
+			if _, dup := outs[index]; dup {
+				errs = append(errs, fmt.Sprintf("第%d笔存在重复的转出请求", index))
+				continue
+			}
+			outs[index] = r
+			if fromUserID == 0 {
+				fromUserID = uint64(r.UserID)
+			} else if fromUserID != uint64(r.UserID) {
+				errs = append(errs, fmt.Sprintf("第%d笔的发送方与批次不一致: %d", index, r.UserID))
+			}
+		case constants.FundTypeTransferIn:
+			if _, dup := ins[index]; dup {
+				errs = append(errs, fmt.Sprintf("第%d笔存在重复的转入请求", index))
+				continue
+			}
+			ins[index] = r
+		default:
+			errs = append(errs, fmt.Sprintf("第%d笔的资金类型无效: %s", index, r.FundType))
+		}
+	}
+
+	// 未显式指定批次ID时使用请求共享的批次引用号
+	if batchID == "" {
+		switch len(references) {
+		case 0:
+			errs = append(errs, "缺少批次ID（请设置 BatchID 或使用 constants.BuildBatchTransfer 构建请求）")
+		case 1:
+			for reference := range references {
+				batchID = reference
+			}
+		default:
+			errs = append(errs, "请求的批次引用号不一致")
+		}
+	}
+
+	indexes := make([]int, 0, len(outs))
+	for index := range outs {
+		indexes = append(indexes, index)
+	}
+	for index := range ins {
+		if _, ok := outs[index]; !ok {
+			errs = append(errs, fmt.Sprintf("第%d笔缺少转出请求", index))
+		}
+	}
+	sort.Ints(indexes)
+
+	symbols := make(map[int64]string)
+	legs := make([]*batchLeg, 0, len(indexes))
+	for _, index := range indexes {
+		out, in := outs[index], ins[index]
+		if in == nil {
+			errs = append(errs, fmt.Sprintf("第%d笔缺少转入请求", index))
+			continue
+		}
+
+		amount, err := decimal.NewFromString(out.Amount)
+		if err != nil || amount.LessThanOrEqual(decimal.Zero) {
+			errs = append(errs, fmt.Sprintf("第%d笔金额无效: %s", index, out.Amount))
+			continue
+		}
+		if inAmount, err := decimal.NewFromString(in.Amount); err != nil || !inAmount.Equal(amount) {
+			errs = append(errs, fmt.Sprintf("第%d笔转出与转入金额不一致: %s != %s", index, out.Amount, in.Amount))
+			continue
+		}
+		if out.TokenID != in.TokenID {
+			errs = append(errs, fmt.Sprintf("第%d笔转出与转入代币不一致: %d != %d", index, out.TokenID, in.TokenID))
+			continue
+		}
+		if in.UserID == out.UserID {
+			errs = append(errs, fmt.Sprintf("第%d笔不能转账给自己", index))
+			continue
+		}
+
+		symbol, ok := symbols[out.TokenID]
+		if !ok {
+			token, err := m.tokenLogic.GetTokenByID(ctx, uint(out.TokenID))
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("第%d笔代币不存在: TokenID=%d", index, out.TokenID))
+				continue
+			}
+			symbol = token.Symbol
+			symbols[out.TokenID] = symbol
+		}
+
+		metadata := make(map[string]string, len(out.Metadata))
+		for k, v := range out.Metadata {
+			metadata[k] = fmt.Sprintf("%v", v)
+		}
+
+		legs = append(legs, &batchLeg{
+			Index:       index,
+			ToUserID:    uint64(in.UserID),
+			TokenSymbol: symbol,
+			Amount:      amount,
+			Description: out.Description,
+			Metadata:    metadata,
+			Callback:    out.Callback,
+		})
+	}
+
+	if len(errs) > 0 {
+		return "", 0, nil, gerror.Newf("批量转账校验失败: %s", strings.Join(errs, "; "))
+	}
+	return batchID, fromUserID, legs, nil
+}
+
+// validateBatchBalance 校验发送方余额是否足以覆盖整批转账
+func (m *walletManager) validateBatchBalance(ctx context.Context, fromUserID uint64, legs []*batchLeg) error {
+	totals := make(map[string]decimal.Decimal)
+	for _, leg := range legs {
+		totals[leg.TokenSymbol] = totals[leg.TokenSymbol].Add(leg.Amount)
+	}
+
+	for symbol, total := range totals {
+		available, _, err := m.balanceLogic.GetBalance(ctx, fromUserID, symbol)
+		if err != nil {
+			return gerror.Wrapf(err, "获取发送方余额失败: UserID=%d, Symbol=%s", fromUserID, symbol)
+		}
+		if available.LessThan(total) {
+			return gerror.NewCodef(logic.CodeInsufficientBalance, "批量转账余额不足: UserID=%d, Symbol=%s, 可用=%s, 需要=%s",
+				fromUserID, symbol, available.String(), total.String())
+		}
+	}
+	return nil
+}
+
+// executeBatchLeg 执行单笔批量转账（发送方扣款 + 接收方加款）
+func (m *walletManager) executeBatchLeg(ctx context.Context, tx gdb.TX, batchID string, fromUserID uint64, leg *batchLeg, req *BatchTransferRequest) (*TransferOperationResult, error) {
+	debitMetadata := make(map[string]string, len(leg.Metadata)+3)
+	for k, v := range leg.Metadata {
+		debitMetadata[k] = v
+	}
+	debitMetadata[constants.MetadataKeyBatchReference] = batchID
+	debitMetadata[constants.MetadataKeyBatchIndex] = strconv.Itoa(leg.Index)
+	debitMetadata["target_user_id"] = fmt.Sprintf("%d", leg.ToUserID)
+
+	creditMetadata := map[string]string{
+		constants.MetadataKeyBatchReference: batchID,
+		constants.MetadataKeyBatchIndex:     strconv.Itoa(leg.Index),
+		"from_user_id":                      fmt.Sprintf("%d", fromUserID),
+	}
+
+	description := leg.Description
+	if description == "" {
+		description = req.Description
+	}
+
+	debitResult, err := m.processFundOperationInTxInternal(ctx, tx, &FundOperationRequest{
+		UserID:           fromUserID,
+		TokenSymbol:      leg.TokenSymbol,
+		Amount:           leg.Amount,
+		BusinessID:       fmt.Sprintf("%s_%d_debit", batchID, leg.Index),
+		FundType:         constants.FundTypeTransferOut,
+		Description:      fmt.Sprintf("批量转账给用户%d: %s", leg.ToUserID, description),
+		Metadata:         debitMetadata,
+		RequestSource:    req.RequestSource,
+		RequestIP:        req.RequestIP,
+		RequestUserAgent: req.RequestUserAgent,
+	})
+	if err != nil {
+		return nil, gerror.Wrap(err, "转账扣款失败")
+	}
+
+	creditResult, err := m.processFundOperationInTxInternal(ctx, tx, &FundOperationRequest{
+		UserID:           leg.ToUserID,
+		TokenSymbol:      leg.TokenSymbol,
+		Amount:           leg.Amount,
+		BusinessID:       fmt.Sprintf("%s_%d_credit", batchID, leg.Index),
+		FundType:         constants.FundTypeTransferIn,
+		Description:      fmt.Sprintf("收到用户%d批量转账: %s", fromUserID, description),
+		Metadata:         creditMetadata,
+		RequestSource:    req.RequestSource,
+		RequestIP:        req.RequestIP,
+		RequestUserAgent: req.RequestUserAgent,
+	})
+	if err != nil {
+		return nil, gerror.Wrap(err, "转账加款失败")
+	}
+
+	result := &TransferOperationResult{
+		FromTransactionID: debitResult.TransactionID,
+		ToTransactionID:   creditResult.TransactionID,
+		FromBalanceAfter:  debitResult.BalanceAfter,
+		ToBalanceAfter:    creditResult.BalanceAfter,
+	}
+	businessID := fmt.Sprintf("%s_%d", batchID, leg.Index)
+	if err := m.recordTransferCompleted(ctx, tx, businessID, fromUserID, leg.ToUserID, leg.TokenSymbol, leg.Amount, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// batchLegEvent 构建单笔批量转账的回调事件（以发送方转出为准）
+func batchLegEvent(batchID string, fromUserID uint64, leg *batchLeg, legResult *BatchTransferLegResult) *WebhookEvent {
+	event := &WebhookEvent{
+		Event:         WebhookEventTransactionCompleted,
+		BusinessID:    fmt.Sprintf("%s_%d", batchID, leg.Index),
+		TransactionID: legResult.FromTransactionID,
+		UserID:        fromUserID,
+		TokenSymbol:   leg.TokenSymbol,
+		Amount:        leg.Amount.String(),
+		FundType:      string(constants.FundTypeTransferOut),
+		Status:        string(constants.TransactionStatusCompleted),
+		Reference:     batchID,
+	}
+	if !legResult.Success {
+		event.Event = WebhookEventTransactionFailed
+		event.Status = string(constants.TransactionStatusFailed)
+		event.Error = legResult.Error
+	}
+	return event
+}
+
+// checkBatchTransferOwner 批次ID已被其他发送方使用时返回 CodeIdempotencyConflict 错误，不返回其他发送方的执行结果
+func checkBatchTransferOwner(existing *entity.BatchTransfers, fromUserID uint64) error {
+	if uint64(existing.FromUserId) != fromUserID {
+		return gerror.NewCodef(logic.CodeIdempotencyConflict, "批次ID已被其他发送方使用: BatchID=%s", existing.BatchId)
+	}
+	return nil
+}
+
+// convertToBatchTransferResult 将已保存的批次记录还原为执行结果
+func convertToBatchTransferResult(record *entity.BatchTransfers) (*BatchTransferResult, error) {
+	result := &BatchTransferResult{
+		BatchID:    record.BatchId,
+		FromUserID: uint64(record.FromUserId),
+		Mode:       BatchTransferMode(record.Mode),
+		Succeeded:  record.SucceededCount,
+		Failed:     record.FailedCount,
+		Duplicate:  true,
+	}
+	if record.Report != "" {
+		if err := json.Unmarshal([]byte(record.Report), &result.Legs); err != nil {
+			return nil, gerror.Wrapf(err, "解析批量转账结果失败: BatchID=%s", record.BatchId)
+		}
+	}
+	return result, nil
+}
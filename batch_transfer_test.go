@@ -0,0 +1,41 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+
+	"github.com/yalks/wallet/entity"
+	"github.com/yalks/wallet/logic"
+)
+
+func TestCheckBatchTransferOwner(t *testing.T) {
+	existing := &entity.BatchTransfers{BatchId: "payroll-2026-10", FromUserId: 7}
+	if err := checkBatchTransferOwner(existing, 7); err != nil {
+		t.Errorf("same sender: %v", err)
+	}
+	if err := checkBatchTransferOwner(existing, 8); gerror.Code(err) != logic.CodeIdempotencyConflict {
+		t.Errorf("other sender: err = %v", err)
+	}
+}
+
+func TestConvertToBatchTransferResult(t *testing.T) {
+	result, err := convertToBatchTransferResult(&entity.BatchTransfers{
+		BatchId:        "payroll-2026-10",
+		FromUserId:     7,
+		Mode:           string(BatchModeBestEffort),
+		SucceededCount: 1,
+		FailedCount:    1,
+		Report:         `[{"index":0,"to_user_id":8,"success":true},{"index":1,"to_user_id":9,"success":false,"error":"余额不足"}]`,
+	})
+	if err != nil {
+		t.Fatalf("convertToBatchTransferResult: %v", err)
+	}
+	if !result.Duplicate || result.FromUserID != 7 || result.Mode != BatchModeBestEffort || len(result.Legs) != 2 ||
+		!result.Legs[0].Success || result.Legs[1].Error != "余额不足" {
+		t.Errorf("result = %+v", result)
+	}
+	if _, err := convertToBatchTransferResult(&entity.BatchTransfers{Report: "{"}); err == nil {
+		t.Error("invalid report accepted")
+	}
+}
@@ -0,0 +1,318 @@
+package wallet
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+
+	"github.com/yalks/wallet/constants"
+	"github.com/yalks/wallet/entity"
+	"github.com/yalks/wallet/logic"
+)
+
+// PayoutFormat 批量发放输入格式
+type PayoutFormat = logic.PayoutFormat
+
+const (
+	PayoutFormatCSV  = logic.PayoutFormatCSV  // CSV，首行为表头: user_id,telegram_id,username,token,amount,fund_type,memo
+	PayoutFormatJSON = logic.PayoutFormatJSON // JSON 对象数组，键名同 CSV 表头
+)
+
+const (
+	defaultPayoutChunkSize = 100
+	bulkPayoutProcessing   = "processing"
+	bulkPayoutCompleted    = "completed"
+)
+
+// BulkPayoutValidationError 批量发放输入校验失败，包含全部错误行
+type BulkPayoutValidationError struct {
+	Errors []*logic.PayoutRowError
+}
+
+// Error 实现 error 接口
+func (e *BulkPayoutValidationError) Error() string {
+	const maxShown = 20
+	messages := make([]string, 0, maxShown)
+	for i, rowErr := range e.Errors {
+		if i == maxShown {
+			break
+		}
+		messages = append(messages, rowErr.Error())
+	}
+	msg := fmt.Sprintf("批量发放输入校验失败，共%d处错误: %s", len(e.Errors), strings.Join(messages, "; "))
+	if len(e.Errors) > maxShown {
+		msg += "; ..."
+	}
+	return msg
+}
+
+// resolvedPayoutRow 已解析用户与代币的发放行
+type resolvedPayoutRow struct {
+	*logic.PayoutRow
+	UserID      uint64
+	TokenSymbol string
+}
+
+// RunBulkPayout 执行批量发放：先校验全部行，再分批执行；同一 JobID 重复调用时从上次中断处继续
+func (m *walletManager) RunBulkPayout(ctx context.Context, req *BulkPayoutRequest) (*BulkPayoutResult, error) {
+	if req == nil || req.Input == nil {
+		return nil, gerror.New("批量发放请求与输入不能为空")
+	}
+	if req.JobID == "" {
+		return nil, gerror.New("批量发放任务ID不能为空")
+	}
+
+	chunkSize := req.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultPayoutChunkSize
+	}
+	// 1. 读取并解析输入
+	data, err := io.ReadAll(req.Input)
+	if err != nil {
+		return nil, gerror.Wrap(err, "读取批量发放输入失败")
+	}
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+
+	rows, rowErrors, err := logic.ParsePayoutRows(req.Format, data, req.DefaultFundType)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 解析用户与代币，全部行校验通过后才开始执行
+	resolved, resolveErrors := m.resolvePayoutRows(ctx, rows)
+	rowErrors = append(rowErrors, resolveErrors...)
+	if len(rowErrors) > 0 {
+		sort.SliceStable(rowErrors, func(i, j int) bool { return rowErrors[i].Line < rowErrors[j].Line })
+		return nil, &BulkPayoutValidationError{Errors: rowErrors}
+	}
+
+	// 3. 创建或恢复任务
+	dao := logic.GetSharedContext().GetBulkPayoutDAO()
+	job, err := dao.GetJob(ctx, req.JobID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		job = &entity.BulkPayoutJobs{
+			JobId:     req.JobID,
+			Format:    string(req.Format),
+			Checksum:  checksum,
+			TotalRows: len(resolved),
+			Status:    bulkPayoutProcessing,
+			CreatedAt: gtime.Now(),
+			UpdatedAt: gtime.Now(),
+		}
+		if err := dao.CreateJob(ctx, job); err != nil {
+			return nil, err
+		}
+	} else if job.Checksum != checksum {
+		return nil, gerror.Newf("任务 %s 已存在且输入内容不同，请使用新的任务ID", req.JobID)
+	}
+
+	result := &BulkPayoutResult{
+		JobID:     req.JobID,
+		TotalRows: len(resolved),
+		Resumed:   job.ProcessedRows,
+		Succeeded: job.SucceededRows,
+		Failed:    job.FailedRows,
+	}
+	if job.ProcessedRows > 0 {
+		g.Log().Infof(ctx, "批量发放从断点继续: JobID=%s, Processed=%d/%d", req.JobID, job.ProcessedRows, len(resolved))
+	}
+
+	// 4. 分批执行，每批一个数据库事务，批内单行失败回滚到保存点
+	for start := job.ProcessedRows; start < len(resolved); start += chunkSize {
+		end := start + chunkSize
+		if end > len(resolved) {
+			end = len(resolved)
+		}
+
+		succeeded, failed := result.Succeeded, result.Failed
+		err := m.RunInTransaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+			for _, row := range resolved[start:end] {
+				record, err := m.executePayoutRow(ctx, tx, req, row)
+				if err != nil {
+					return err
+				}
+				if record.Status == string(constants.TransactionStatusCompleted) {
+					succeeded++
+				} else {
+					failed++
+				}
+			}
+
+			return dao.UpdateJob(ctx, tx, req.JobID, map[string]any{
+				"processed_rows": end,
+				"succeeded_rows": succeeded,
+				"failed_rows":    failed,
+			})
+		})
+		if err != nil {
+			return result, gerror.Wrapf(err, "批量发放执行中断: JobID=%s, 已处理=%d/%d，可使用相同任务ID继续", req.JobID, start, len(resolved))
+		}
+
+		result.Succeeded, result.Failed = succeeded, failed
+		g.Log().Infof(ctx, "批量发放进度: JobID=%s, Processed=%d/%d, Succeeded=%d, Failed=%d",
+			req.JobID, end, len(resolved), succeeded, failed)
+	}
+
+	if job.Status != bulkPayoutCompleted {
+		if err := dao.UpdateJob(ctx, nil, req.JobID, map[string]any{"status": bulkPayoutCompleted}); err != nil {
+			return result, err
+		}
+	}
+	result.Status = bulkPayoutCompleted
+
+	// 5. 输出结果文件
+	if req.ResultWriter != nil {
+		if err := m.writeBulkPayoutResult(ctx, req.JobID, req.ResultWriter); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// resolvePayoutRows 通过 IUserLogic 解析用户，并校验代币存在
+func (m *walletManager) resolvePayoutRows(ctx context.Context, rows []*logic.PayoutRow) ([]*resolvedPayoutRow, []*logic.PayoutRowError) {
+	resolved := make([]*resolvedPayoutRow, 0, len(rows))
+	var rowErrors []*logic.PayoutRowError
+	tokens := make(map[string]error)
+
+	for _, row := range rows {
+		var (
+			user *entity.Users
+			err  error
+		)
+		switch {
+		case row.UserID != 0:
+			user, err = m.userLogic.GetUserByID(ctx, row.UserID)
+		case row.TelegramID != 0:
+			user, err = m.userLogic.GetUserByTelegramID(ctx, row.TelegramID)
+		default:
+			user, err = m.userLogic.GetUserByUsername(ctx, row.Username)
+		}
+		if err != nil {
+			rowErrors = append(rowErrors, &logic.PayoutRowError{Line: row.Line, Message: fmt.Sprintf("用户解析失败: %v", err)})
+			continue
+		}
+
+		tokenErr, checked := tokens[row.Token]
+		if !checked {
+			_, tokenErr = m.tokenLogic.GetTokenBySymbol(ctx, row.Token)
+			tokens[row.Token] = tokenErr
+		}
+		if tokenErr != nil {
+			rowErrors = append(rowErrors, &logic.PayoutRowError{Line: row.Line, Message: fmt.Sprintf("代币不存在: %s", row.Token)})
+			continue
+		}
+
+		resolved = append(resolved, &resolvedPayoutRow{
+			PayoutRow:   row,
+			UserID:      user.Id,
+			TokenSymbol: row.Token,
+		})
+	}
+	return resolved, rowErrors
+}
+
+// executePayoutRow 在事务中执行单行发放并记录结果，单行失败不影响同批其他行
+func (m *walletManager) executePayoutRow(ctx context.Context, tx gdb.TX, req *BulkPayoutRequest, row *resolvedPayoutRow) (*entity.BulkPayoutRows, error) {
+	businessID := fmt.Sprintf("payout_%s_%d", req.JobID, row.Line)
+	savepoint := fmt.Sprintf("payout_row_%d", row.Line)
+	if err := tx.SavePoint(savepoint); err != nil {
+		return nil, gerror.Wrapf(err, "创建事务保存点失败: %s", savepoint)
+	}
+
+	record := &entity.BulkPayoutRows{
+		JobId:      req.JobID,
+		Line:       row.Line,
+		UserId:     row.UserID,
+		Symbol:     row.TokenSymbol,
+		Amount:     row.Amount,
+		FundType:   string(row.FundType),
+		BusinessId: businessID,
+		CreatedAt:  gtime.Now(),
+	}
+
+	requestSource := req.RequestSource
+	if requestSource == "" {
+		requestSource = "admin"
+	}
+	opResult, err := m.processFundOperationInTxInternal(ctx, tx, &FundOperationRequest{
+		UserID:      row.UserID,
+		TokenSymbol: row.TokenSymbol,
+		Amount:      row.Amount,
+		BusinessID:  businessID,
+		FundType:    row.FundType,
+		Direction:   constants.FundDirectionIn,
+		Description: row.Memo,
+		Metadata: map[string]string{
+			"payout_job_id": req.JobID,
+			"payout_line":   strconv.Itoa(row.Line),
+			"memo":          row.Memo,
+		},
+		RequestSource: requestSource,
+	})
+	if err != nil {
+		if rbErr := tx.RollbackTo(savepoint); rbErr != nil {
+			return nil, gerror.Wrapf(rbErr, "回滚到事务保存点失败: %s", savepoint)
+		}
+		record.Status = string(constants.TransactionStatusFailed)
+		record.Error = err.Error()
+		g.Log().Warningf(ctx, "批量发放单行失败: JobID=%s, Line=%d, Error=%v", req.JobID, row.Line, err)
+	} else {
+		record.Status = string(constants.TransactionStatusCompleted)
+		record.TransactionId, _ = strconv.ParseUint(opResult.TransactionID, 10, 64)
+	}
+
+	if err := logic.GetSharedContext().GetBulkPayoutDAO().CreateRow(ctx, tx, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// writeBulkPayoutResult 以 CSV 格式输出任务的逐行结果
+func (m *walletManager) writeBulkPayoutResult(ctx context.Context, jobID string, w io.Writer) error {
+	rows, err := logic.GetSharedContext().GetBulkPayoutDAO().ListRows(ctx, jobID)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	_ = writer.Write([]string{"line", "user_id", "token", "amount", "fund_type", "business_id", "status", "transaction_id", "error"})
+	for _, row := range rows {
+		transactionID := ""
+		if row.TransactionId != 0 {
+			transactionID = strconv.FormatUint(row.TransactionId, 10)
+		}
+		_ = writer.Write([]string{
+			strconv.Itoa(row.Line),
+			strconv.FormatUint(row.UserId, 10),
+			row.Symbol,
+			row.Amount.String(),
+			row.FundType,
+			row.BusinessId,
+			row.Status,
+			transactionID,
+			row.Error,
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return gerror.Wrap(err, "写入批量发放结果文件失败")
+	}
+	return nil
+}
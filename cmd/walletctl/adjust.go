@@ -0,0 +1,317 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/os/gcmd"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet"
+	"github.com/yalks/wallet/constants"
+)
+
+// 调账命令的公共选项
+var (
+	yesArgument          = gcmd.Argument{Name: "yes", Short: "y", Orphan: true, Brief: "跳过确认提示"}
+	adjustmentIDArgument = gcmd.Argument{Name: "id", Brief: "调账申请ID"}
+)
+
+// adjustmentCommand walletctl adjust：后台调账申请、审批和查询
+func adjustmentCommand() *gcmd.Command {
+	command := &gcmd.Command{
+		Name:  "adjust",
+		Usage: "walletctl adjust COMMAND [OPTION]",
+		Brief: "后台调账",
+		Description: "调账通过审批流程执行：submit 提交申请，审批人数达到要求后 approve 会在同一事务中执行资金操作。" +
+			"submit、approve 和 reject 执行前需要确认，--yes 跳过确认。" +
+			"操作人取当前操作系统用户（通过 sudo 运行时取 SUDO_USER），不能由选项指定",
+	}
+	_ = command.AddCommand(
+		&gcmd.Command{
+			Name:  "submit",
+			Usage: "walletctl adjust submit (--user-id ID | --telegram-id ID | --username NAME) --token SYMBOL --amount AMOUNT --reason TEXT",
+			Brief: "提交调账申请",
+			Arguments: withArguments(userArguments, []gcmd.Argument{
+				{Name: "token", Short: "t", Brief: "代币符号"},
+				{Name: "amount", Short: "a", Brief: "调账金额（正数）"},
+				{Name: "fund-type", Default: string(constants.FundTypeAdminAdd), Brief: "admin_add、admin_deduct 或 system_adjustment"},
+				{Name: "direction", Brief: "资金方向 in 或 out，system_adjustment 必填"},
+				{Name: "reason", Brief: "调账原因（必填）"},
+				{Name: "attachment", Brief: "附件引用（工单号、文件地址等）"},
+				yesArgument,
+			}),
+			Func: submitAdjustment,
+		},
+		&gcmd.Command{
+			Name:      "approve",
+			Usage:     "walletctl adjust approve --id ID [--comment TEXT]",
+			Brief:     "审批调账申请",
+			Arguments: withArguments([]gcmd.Argument{adjustmentIDArgument, {Name: "comment", Brief: "审批备注"}, yesArgument}),
+			Func:      approveAdjustment,
+		},
+		&gcmd.Command{
+			Name:      "reject",
+			Usage:     "walletctl adjust reject --id ID --reason TEXT",
+			Brief:     "驳回调账申请",
+			Arguments: withArguments([]gcmd.Argument{adjustmentIDArgument, {Name: "reason", Brief: "驳回原因"}, yesArgument}),
+			Func:      rejectAdjustment,
+		},
+		&gcmd.Command{
+			Name:  "list",
+			Usage: "walletctl adjust list [--status pending|executed|rejected|all]",
+			Brief: "查询调账申请列表",
+			Arguments: withArguments([]gcmd.Argument{
+				{Name: "status", Default: string(wallet.AdjustmentStatusPending), Brief: "申请状态，all 表示不限"},
+				{Name: "limit", Short: "n", Default: "50", Brief: "条数"},
+				{Name: "offset", Default: "0", Brief: "偏移量"},
+			}),
+			Func: listAdjustments,
+		},
+		&gcmd.Command{
+			Name:      "get",
+			Usage:     "walletctl adjust get --id ID",
+			Brief:     "查询调账申请详情和审批记录",
+			Arguments: withArguments([]gcmd.Argument{adjustmentIDArgument}),
+			Func:      getAdjustment,
+		},
+	)
+	return command
+}
+
+// submitAdjustment walletctl adjust submit
+func submitAdjustment(ctx context.Context, parser *gcmd.Parser) error {
+	out, err := setup(ctx, parser)
+	if err != nil {
+		return err
+	}
+	operator, err := currentOperator()
+	if err != nil {
+		return err
+	}
+	user, err := resolveUser(ctx, parser)
+	if err != nil {
+		return err
+	}
+	token, err := requireOption(parser, "token")
+	if err != nil {
+		return err
+	}
+	value, err := requireOption(parser, "amount")
+	if err != nil {
+		return err
+	}
+	amount, err := decimal.NewFromString(value)
+	if err != nil {
+		return gerror.NewCodef(gcode.CodeInvalidParameter, "无效的金额: %s", value)
+	}
+
+	req := &wallet.AdjustmentSubmitRequest{
+		OperatorID:    operator,
+		UserID:        user.Id,
+		TokenSymbol:   token,
+		FundType:      constants.FundType(parser.GetOpt("fund-type", string(constants.FundTypeAdminAdd)).String()),
+		Direction:     constants.FundDirection(parser.GetOpt("direction").String()),
+		Amount:        amount,
+		Reason:        parser.GetOpt("reason").String(),
+		AttachmentRef: parser.GetOpt("attachment").String(),
+	}
+	direction, err := constants.ResolveFundDirection(req.FundType, req.Direction)
+	if err != nil {
+		return gerror.WrapCode(gcode.CodeInvalidParameter, err, "资金方向无效")
+	}
+	summary := fmt.Sprintf("提交调账申请: 用户 %d (%s), %s %s %s, 资金类型 %s, 原因: %s",
+		user.Id, user.Name, direction, req.Amount.String(), req.TokenSymbol, req.FundType, req.Reason)
+	if err := confirm(parser, os.Stdin, summary); err != nil {
+		return err
+	}
+
+	info, err := wallet.Manager().SubmitAdjustment(ctx, req)
+	if err != nil {
+		return err
+	}
+	return out.print(info, adjustmentTable([]*wallet.AdjustmentInfo{info}))
+}
+
+// approveAdjustment walletctl adjust approve
+func approveAdjustment(ctx context.Context, parser *gcmd.Parser) error {
+	out, err := setup(ctx, parser)
+	if err != nil {
+		return err
+	}
+	request, operator, err := loadAdjustmentAction(ctx, parser)
+	if err != nil {
+		return err
+	}
+	if operator == request.SubmittedBy {
+		return gerror.NewCodef(gcode.CodeNotAuthorized, "不能审批自己提交的调账申请: ID=%d, Operator=%s", request.ID, operator)
+	}
+	summary := fmt.Sprintf("审批调账申请 #%d: 用户 %d, %s %s %s, 已审批 %d/%d",
+		request.ID, request.UserID, request.Direction, request.Amount.String(), request.TokenSymbol, request.ApprovalCount, request.RequiredApprovals)
+	if err := confirm(parser, os.Stdin, summary); err != nil {
+		return err
+	}
+
+	info, err := wallet.Manager().ApproveAdjustment(ctx, request.ID, operator, parser.GetOpt("comment").String())
+	if err != nil {
+		return err
+	}
+	return out.print(info, adjustmentTable([]*wallet.AdjustmentInfo{info}))
+}
+
+// rejectAdjustment walletctl adjust reject
+func rejectAdjustment(ctx context.Context, parser *gcmd.Parser) error {
+	out, err := setup(ctx, parser)
+	if err != nil {
+		return err
+	}
+	request, operator, err := loadAdjustmentAction(ctx, parser)
+	if err != nil {
+		return err
+	}
+	reason, err := requireOption(parser, "reason")
+	if err != nil {
+		return err
+	}
+	summary := fmt.Sprintf("驳回调账申请 #%d: 用户 %d, %s %s %s, 原因: %s",
+		request.ID, request.UserID, request.Direction, request.Amount.String(), request.TokenSymbol, reason)
+	if err := confirm(parser, os.Stdin, summary); err != nil {
+		return err
+	}
+
+	info, err := wallet.Manager().RejectAdjustment(ctx, request.ID, operator, reason)
+	if err != nil {
+		return err
+	}
+	return out.print(info, adjustmentTable([]*wallet.AdjustmentInfo{info}))
+}
+
+// loadAdjustmentAction 读取审批和驳回共用的 --id 和当前操作人，并加载调账申请用于确认提示
+func loadAdjustmentAction(ctx context.Context, parser *gcmd.Parser) (*wallet.AdjustmentInfo, string, error) {
+	value, err := requireOption(parser, "id")
+	if err != nil {
+		return nil, "", err
+	}
+	requestID, err := parsePositiveInt("id", value)
+	if err != nil {
+		return nil, "", err
+	}
+	operator, err := currentOperator()
+	if err != nil {
+		return nil, "", err
+	}
+	request, err := wallet.Manager().GetAdjustment(ctx, uint64(requestID))
+	if err != nil {
+		return nil, "", err
+	}
+	return request, operator, nil
+}
+
+// currentOperator 以当前操作系统用户作为调账操作人，避免通过选项冒充其他管理员
+func currentOperator() (string, error) {
+	current, err := user.Current()
+	if err != nil {
+		return "", gerror.Wrap(err, "获取当前操作系统用户失败")
+	}
+	operator := resolveOperator(current.Username, current.Uid, os.Getenv("SUDO_USER"))
+	if operator == "" {
+		return "", gerror.NewCode(gcode.CodeNotAuthorized, "无法确定当前操作人")
+	}
+	return operator, nil
+}
+
+// resolveOperator 确定操作人：以 root 身份通过 sudo 运行时取发起 sudo 的用户，否则取当前用户
+func resolveOperator(username, uid, sudoUser string) string {
+	if uid == "0" && sudoUser != "" && sudoUser != "root" {
+		return sudoUser
+	}
+	return username
+}
+
+// listAdjustments walletctl adjust list
+func listAdjustments(ctx context.Context, parser *gcmd.Parser) error {
+	out, err := setup(ctx, parser)
+	if err != nil {
+		return err
+	}
+	status := wallet.AdjustmentStatus(parser.GetOpt("status", string(wallet.AdjustmentStatusPending)).String())
+	if status == "all" {
+		status = ""
+	}
+	limit, err := parsePositiveInt("limit", parser.GetOpt("limit", "50").String())
+	if err != nil {
+		return err
+	}
+	offset, err := strconv.Atoi(parser.GetOpt("offset", "0").String())
+	if err != nil || offset < 0 {
+		return gerror.NewCodef(gcode.CodeInvalidParameter, "无效的 offset: %s", parser.GetOpt("offset").String())
+	}
+
+	infos, err := wallet.Manager().ListAdjustments(ctx, status, int(limit), offset)
+	if err != nil {
+		return err
+	}
+	return out.print(infos, adjustmentTable(infos))
+}
+
+// getAdjustment walletctl adjust get
+func getAdjustment(ctx context.Context, parser *gcmd.Parser) error {
+	out, err := setup(ctx, parser)
+	if err != nil {
+		return err
+	}
+	value, err := requireOption(parser, "id")
+	if err != nil {
+		return err
+	}
+	requestID, err := parsePositiveInt("id", value)
+	if err != nil {
+		return err
+	}
+	info, err := wallet.Manager().GetAdjustment(ctx, uint64(requestID))
+	if err != nil {
+		return err
+	}
+	if err := out.print(info, adjustmentTable([]*wallet.AdjustmentInfo{info})); err != nil {
+		return err
+	}
+	if out.format == outputTable && len(info.Actions) > 0 {
+		fmt.Fprintln(out.out)
+		actions := &table{Headers: []string{"ADMIN", "ACTION", "COMMENT", "TIME"}}
+		for _, action := range info.Actions {
+			actions.Rows = append(actions.Rows, []string{action.AdminID, action.Action, action.Comment, action.CreatedAt})
+		}
+		return writeTable(out.out, actions)
+	}
+	return nil
+}
+
+// adjustmentTable 调账申请表格
+func adjustmentTable(infos []*wallet.AdjustmentInfo) *table {
+	t := &table{Headers: []string{"ID", "USER", "TOKEN", "FUND_TYPE", "DIRECTION", "AMOUNT", "APPROVALS", "STATUS", "SUBMITTED_BY", "TRANSACTION", "CREATED_AT"}}
+	for _, info := range infos {
+		transactionID := ""
+		if info.TransactionID != 0 {
+			transactionID = strconv.FormatUint(info.TransactionID, 10)
+		}
+		t.Rows = append(t.Rows, []string{
+			strconv.FormatUint(info.ID, 10),
+			strconv.FormatUint(info.UserID, 10),
+			info.TokenSymbol,
+			string(info.FundType),
+			string(info.Direction),
+			info.Amount.String(),
+			fmt.Sprintf("%d/%d", info.ApprovalCount, info.RequiredApprovals),
+			string(info.Status),
+			info.SubmittedBy,
+			transactionID,
+			info.CreatedAt,
+		})
+	}
+	return t
+}
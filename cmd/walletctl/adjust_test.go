@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestResolveOperator(t *testing.T) {
+	tests := []struct {
+		username string
+		uid      string
+		sudoUser string
+		expected string
+	}{
+		{"alice", "1000", "", "alice"},
+		{"alice", "1000", "bob", "alice"}, // 非 root 时忽略 SUDO_USER
+		{"root", "0", "bob", "bob"},
+		{"root", "0", "", "root"},
+		{"root", "0", "root", "root"},
+	}
+
+	for _, tt := range tests {
+		if got := resolveOperator(tt.username, tt.uid, tt.sudoUser); got != tt.expected {
+			t.Errorf("resolveOperator(%s, %s, %s) = %s, want %s", tt.username, tt.uid, tt.sudoUser, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/gogf/gf/v2/os/gcmd"
+
+	"github.com/yalks/wallet"
+)
+
+// balanceOutput balance 命令的 JSON 输出
+type balanceOutput struct {
+	UserID     uint64                `json:"user_id"`
+	TelegramID int64                 `json:"telegram_id,omitempty"`
+	Name       string                `json:"name,omitempty"`
+	Balances   []*wallet.BalanceInfo `json:"balances"`
+}
+
+// balanceCommand walletctl balance：查询用户已有钱包的余额，不会创建钱包
+func balanceCommand() *gcmd.Command {
+	return &gcmd.Command{
+		Name:  "balance",
+		Usage: "walletctl balance (--user-id ID | --telegram-id ID | --username NAME) [--token SYMBOL]",
+		Brief: "查询用户余额",
+		Arguments: withArguments(userArguments, []gcmd.Argument{
+			{Name: "token", Short: "t", Brief: "只显示该代币"},
+		}),
+		Func: func(ctx context.Context, parser *gcmd.Parser) error {
+			out, err := setup(ctx, parser)
+			if err != nil {
+				return err
+			}
+			user, err := resolveUser(ctx, parser)
+			if err != nil {
+				return err
+			}
+			balances, err := wallet.Manager().ListUserBalances(ctx, user.Id)
+			if err != nil {
+				return err
+			}
+			balances = filterBalances(balances, parser.GetOpt("token").String())
+
+			return out.print(&balanceOutput{
+				UserID:     user.Id,
+				TelegramID: user.TelegramId,
+				Name:       user.Name,
+				Balances:   balances,
+			}, balanceTable(balances))
+		},
+	}
+}
+
+// filterBalances 按代币符号过滤余额（不区分大小写），符号为空时不过滤
+func filterBalances(balances []*wallet.BalanceInfo, token string) []*wallet.BalanceInfo {
+	if token == "" {
+		return balances
+	}
+	filtered := make([]*wallet.BalanceInfo, 0, 1)
+	for _, balance := range balances {
+		if strings.EqualFold(balance.TokenSymbol, token) {
+			filtered = append(filtered, balance)
+		}
+	}
+	return filtered
+}
+
+// balanceTable 余额表格
+func balanceTable(balances []*wallet.BalanceInfo) *table {
+	t := &table{Headers: []string{"USER", "TOKEN", "AVAILABLE", "FROZEN", "TOTAL", "UPDATED"}}
+	for _, balance := range balances {
+		t.Rows = append(t.Rows, []string{
+			strconv.FormatUint(balance.UserID, 10),
+			balance.TokenSymbol,
+			balance.AvailableBalance.String(),
+			balance.FrozenBalance.String(),
+			balance.TotalBalance.String(),
+			balance.LastSyncTime,
+		})
+	}
+	return t
+}
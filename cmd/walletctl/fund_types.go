@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/gogf/gf/v2/os/gcmd"
+
+	"github.com/yalks/wallet/constants"
+)
+
+// fundTypeOutput fund-types 命令的 JSON 输出
+type fundTypeOutput struct {
+	Type           constants.FundType      `json:"type"`
+	Direction      constants.FundDirection `json:"direction,omitempty"`
+	Bidirectional  bool                    `json:"bidirectional"`
+	Category       string                  `json:"category"`
+	Description    string                  `json:"description"`
+	Flags          []string                `json:"flags,omitempty"`
+	AllowedSources []string                `json:"allowed_sources,omitempty"`
+	MinAmount      string                  `json:"min_amount,omitempty"`
+	MaxAmount      string                  `json:"max_amount,omitempty"`
+}
+
+// fundTypeFlagNames 资金类型能力标记的名称
+var fundTypeFlagNames = []struct {
+	flag constants.FundTypeFlag
+	name string
+}{
+	{constants.FundTypeFlagRequiresApproval, "requires_approval"},
+	{constants.FundTypeFlagSchedulable, "schedulable"},
+	{constants.FundTypeFlagBulkPayout, "bulk_payout"},
+}
+
+// fundTypesCommand walletctl fund-types：列出内置和配置注册的资金类型
+func fundTypesCommand() *gcmd.Command {
+	return &gcmd.Command{
+		Name:  "fund-types",
+		Usage: "walletctl fund-types [--category CATEGORY]",
+		Brief: "列出资金类型（含 wallet.fundTypes 中注册的自定义类型）",
+		Arguments: withArguments([]gcmd.Argument{
+			{Name: "category", Brief: "只显示该分类"},
+		}),
+		Func: func(ctx context.Context, parser *gcmd.Parser) error {
+			out, err := setup(ctx, parser)
+			if err != nil {
+				return err
+			}
+			fundTypes := listFundTypes(parser.GetOpt("category").String())
+			return out.print(fundTypes, fundTypeTable(fundTypes))
+		},
+	}
+}
+
+// listFundTypes 按分类和类型名排序的资金类型列表，分类为空时不过滤
+func listFundTypes(category string) []*fundTypeOutput {
+	fundTypes := make([]*fundTypeOutput, 0)
+	for _, fundType := range constants.GetAllFundTypes() {
+		info, ok := constants.GetFundTypeInfo(fundType)
+		if !ok || (category != "" && !strings.EqualFold(info.Category, category)) {
+			continue
+		}
+		item := &fundTypeOutput{
+			Type:           info.Type,
+			Direction:      info.Direction,
+			Bidirectional:  info.Bidirectional,
+			Category:       info.Category,
+			Description:    info.Description,
+			AllowedSources: info.AllowedSources,
+		}
+		for _, flag := range fundTypeFlagNames {
+			if info.HasFlag(flag.flag) {
+				item.Flags = append(item.Flags, flag.name)
+			}
+		}
+		if !info.Limits.MinAmount.IsZero() {
+			item.MinAmount = info.Limits.MinAmount.String()
+		}
+		if !info.Limits.MaxAmount.IsZero() {
+			item.MaxAmount = info.Limits.MaxAmount.String()
+		}
+		fundTypes = append(fundTypes, item)
+	}
+	sort.Slice(fundTypes, func(i, j int) bool {
+		if fundTypes[i].Category != fundTypes[j].Category {
+			return fundTypes[i].Category < fundTypes[j].Category
+		}
+		return fundTypes[i].Type < fundTypes[j].Type
+	})
+	return fundTypes
+}
+
+// fundTypeTable 资金类型表格
+func fundTypeTable(fundTypes []*fundTypeOutput) *table {
+	t := &table{Headers: []string{"TYPE", "DIRECTION", "CATEGORY", "FLAGS", "SOURCES", "MIN", "MAX", "DESCRIPTION"}}
+	for _, fundType := range fundTypes {
+		direction := string(fundType.Direction)
+		if fundType.Bidirectional {
+			direction = "in/out"
+		}
+		t.Rows = append(t.Rows, []string{
+			string(fundType.Type),
+			direction,
+			fundType.Category,
+			strings.Join(fundType.Flags, ","),
+			strings.Join(fundType.AllowedSources, ","),
+			fundType.MinAmount,
+			fundType.MaxAmount,
+			fundType.Description,
+		})
+	}
+	return t
+}
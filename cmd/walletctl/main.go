@@ -0,0 +1,234 @@
+// walletctl 钱包运维命令行工具：使用 GoFrame 配置连接数据库，支持余额查询、交易查询、
+// 后台调账、对账和资金类型列表，结果可以输出为表格或 JSON。
+//
+//	walletctl balance --telegram-id 123456 --output json
+//	walletctl tx get --reference order_123
+//	walletctl adjust submit --user-id 1 --token USDT --amount 10 --reason "补偿"
+//	walletctl reconcile --audit
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/gogf/gf/contrib/drivers/mysql/v2"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcfg"
+	"github.com/gogf/gf/v2/os/gcmd"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/os/glog"
+
+	"github.com/yalks/wallet"
+	"github.com/yalks/wallet/entity"
+	"github.com/yalks/wallet/logic"
+)
+
+// errReconcileMismatch 对账发现差异，进程以退出码 2 结束
+var errReconcileMismatch = errors.New("对账发现差异")
+
+// commonArguments 所有子命令都支持的选项
+var commonArguments = []gcmd.Argument{
+	{Name: "config", Short: "c", Brief: "配置文件路径，默认使用 GoFrame 的配置文件查找规则"},
+	{Name: "output", Short: "o", Default: outputTable, Brief: "输出格式: table 或 json"},
+	{Name: "verbose", Short: "v", Orphan: true, Brief: "输出 INFO 级别日志（默认只输出警告和错误）"},
+}
+
+// userArguments 定位用户的选项，三者任选其一
+var userArguments = []gcmd.Argument{
+	{Name: "user-id", Brief: "用户ID"},
+	{Name: "telegram-id", Brief: "Telegram ID"},
+	{Name: "username", Brief: "用户名"},
+}
+
+// withArguments 拼接命令自己的选项和公共选项
+func withArguments(groups ...[]gcmd.Argument) []gcmd.Argument {
+	arguments := make([]gcmd.Argument, 0)
+	for _, group := range groups {
+		arguments = append(arguments, group...)
+	}
+	return append(arguments, commonArguments...)
+}
+
+func main() {
+	ctx := gctx.GetInitCtx()
+	root := &gcmd.Command{
+		Name:  "walletctl",
+		Usage: "walletctl COMMAND [OPTION]",
+		Brief: "钱包运维命令行工具",
+		Description: "使用 GoFrame 配置（config.yaml 中的 database 和 wallet 配置）连接钱包数据库。" +
+			"选项需写在子命令之后，例如 walletctl balance --user-id 1 --output json",
+	}
+	if err := root.AddCommand(
+		balanceCommand(),
+		transactionCommand(),
+		adjustmentCommand(),
+		reconcileCommand(),
+		fundTypesCommand(),
+	); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if err := root.RunWithError(ctx); err != nil {
+		if errors.Is(err, errReconcileMismatch) {
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// setup 加载配置、初始化钱包管理器并创建输出器；日志输出到标准错误，避免混入 JSON 输出
+func setup(ctx context.Context, parser *gcmd.Parser) (*printer, error) {
+	out, err := newPrinter(os.Stdout, parser.GetOpt("output", outputTable).String())
+	if err != nil {
+		return nil, err
+	}
+
+	if file := parser.GetOpt("config").String(); file != "" {
+		if err := useConfigFile(file); err != nil {
+			return nil, err
+		}
+	}
+
+	logger := g.Log()
+	logger.SetWriter(os.Stderr)
+	logger.SetStdoutPrint(false)
+	if parser.GetOpt("verbose") == nil {
+		logger.SetLevel(glog.LEVEL_WARN)
+	}
+
+	if err := wallet.Initialize(ctx); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// useConfigFile 使用指定的配置文件
+func useConfigFile(file string) error {
+	path, err := filepath.Abs(file)
+	if err != nil {
+		return gerror.Wrapf(err, "解析配置文件路径失败: %s", file)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return gerror.Wrapf(err, "配置文件不存在: %s", file)
+	}
+	adapter, ok := g.Cfg().GetAdapter().(*gcfg.AdapterFile)
+	if !ok {
+		return gerror.New("当前配置适配器不支持指定配置文件")
+	}
+	adapter.SetFileName(path)
+	return nil
+}
+
+// resolveUser 按 --user-id、--telegram-id 或 --username 查找用户，必须且只能指定其中之一
+func resolveUser(ctx context.Context, parser *gcmd.Parser) (*entity.Users, error) {
+	var given []string
+	for _, name := range []string{"user-id", "telegram-id", "username"} {
+		if parser.GetOpt(name).String() != "" {
+			given = append(given, "--"+name)
+		}
+	}
+	if len(given) != 1 {
+		return nil, gerror.NewCode(gcode.CodeMissingParameter, "请指定 --user-id、--telegram-id 或 --username 之一")
+	}
+
+	users := logic.NewUserLogic()
+	switch given[0] {
+	case "--user-id":
+		userID, err := parsePositiveInt("user-id", parser.GetOpt("user-id").String())
+		if err != nil {
+			return nil, err
+		}
+		return users.GetUserByID(ctx, uint64(userID))
+	case "--telegram-id":
+		telegramID, err := parsePositiveInt("telegram-id", parser.GetOpt("telegram-id").String())
+		if err != nil {
+			return nil, err
+		}
+		return users.GetUserByTelegramID(ctx, telegramID)
+	default:
+		return users.GetUserByUsername(ctx, parser.GetOpt("username").String())
+	}
+}
+
+// confirm 在标准错误输出操作摘要并等待输入 y 确认；指定 --yes 时跳过
+func confirm(parser *gcmd.Parser, in io.Reader, summary string) error {
+	if parser.GetOpt("yes") != nil {
+		return nil
+	}
+	fmt.Fprintln(os.Stderr, summary)
+	fmt.Fprint(os.Stderr, "确认执行? [y/N] ")
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return gerror.Wrap(err, "读取确认输入失败")
+	}
+	if !isConfirmed(answer) {
+		return gerror.NewCode(gcode.CodeOperationFailed, "操作已取消")
+	}
+	return nil
+}
+
+// isConfirmed 输入 y 或 yes（不区分大小写）表示确认
+func isConfirmed(answer string) bool {
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// requireOption 读取必填选项
+func requireOption(parser *gcmd.Parser, name string) (string, error) {
+	value := strings.TrimSpace(parser.GetOpt(name).String())
+	if value == "" {
+		return "", gerror.NewCodef(gcode.CodeMissingParameter, "缺少选项 --%s", name)
+	}
+	return value, nil
+}
+
+// parsePositiveInt 解析正整数选项
+func parsePositiveInt(name, value string) (int64, error) {
+	n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || n <= 0 {
+		return 0, gerror.NewCodef(gcode.CodeInvalidParameter, "--%s 必须是正整数: %s", name, value)
+	}
+	return n, nil
+}
+
+// parseTime 解析时间选项，支持 YYYY-MM-DD（本地时区零点）和 RFC 3339；为空时返回 nil
+func parseTime(name, value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, gerror.NewCodef(gcode.CodeInvalidParameter, "--%s 必须是 YYYY-MM-DD 或 RFC 3339 时间: %s", name, value)
+	}
+	return &t, nil
+}
+
+// splitList 拆分逗号分隔的选项值，忽略空项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+)
+
+// 输出格式
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// table 表格输出的表头和数据行
+type table struct {
+	Headers []string
+	Rows    [][]string
+}
+
+// printer 按 --output 指定的格式输出命令结果：json 输出完整结构，table 输出对齐的文本表格
+type printer struct {
+	out    io.Writer
+	format string
+}
+
+// newPrinter 创建输出器，格式为空时使用 table
+func newPrinter(out io.Writer, format string) (*printer, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	switch format {
+	case "":
+		format = outputTable
+	case outputTable, outputJSON:
+	default:
+		return nil, gerror.NewCodef(gcode.CodeInvalidParameter, "不支持的输出格式: %s（可选 table 或 json）", format)
+	}
+	return &printer{out: out, format: format}, nil
+}
+
+// print 输出结果；json 格式输出 value，table 格式输出 t
+func (p *printer) print(value interface{}, t *table) error {
+	if p.format == outputJSON {
+		encoder := json.NewEncoder(p.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+	return writeTable(p.out, t)
+}
+
+// writeTable 输出对齐的文本表格，没有数据行时只输出表头
+func writeTable(out io.Writer, t *table) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	if len(t.Headers) > 0 {
+		fmt.Fprintln(w, strings.Join(t.Headers, "\t"))
+	}
+	for _, row := range t.Rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			if cell == "" {
+				cell = "-"
+			}
+			// 单元格中的换行和制表符会破坏对齐
+			cells[i] = strings.NewReplacer("\n", " ", "\t", " ").Replace(cell)
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet"
+	"github.com/yalks/wallet/constants"
+)
+
+func TestPrinter(t *testing.T) {
+	if _, err := newPrinter(&bytes.Buffer{}, "yaml"); err == nil {
+		t.Fatal("unsupported format accepted")
+	}
+
+	var buf bytes.Buffer
+	out, err := newPrinter(&buf, "")
+	if err != nil || out.format != outputTable {
+		t.Fatalf("default printer = %+v, %v", out, err)
+	}
+	err = out.print(nil, &table{
+		Headers: []string{"ID", "NOTE"},
+		Rows:    [][]string{{"1", "a\tb"}, {"22", ""}},
+	})
+	if err != nil {
+		t.Fatalf("print table: %v", err)
+	}
+	want := "ID  NOTE\n1   a b\n22  -\n"
+	if buf.String() != want {
+		t.Errorf("table = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	out, _ = newPrinter(&buf, "JSON")
+	if err := out.print(map[string]int{"checked": 2}, nil); err != nil {
+		t.Fatalf("print json: %v", err)
+	}
+	if buf.String() != "{\n  \"checked\": 2\n}\n" {
+		t.Errorf("json = %q", buf.String())
+	}
+}
+
+func TestFilterBalances(t *testing.T) {
+	balances := []*wallet.BalanceInfo{
+		{TokenSymbol: "USDT", AvailableBalance: decimal.NewFromInt(1)},
+		{TokenSymbol: "TRX", AvailableBalance: decimal.NewFromInt(2)},
+	}
+	if got := filterBalances(balances, ""); len(got) != 2 {
+		t.Errorf("no filter = %d balances", len(got))
+	}
+	if got := filterBalances(balances, "usdt"); len(got) != 1 || got[0].TokenSymbol != "USDT" {
+		t.Errorf("usdt filter = %+v", got)
+	}
+	if got := filterBalances(balances, "BTC"); len(got) != 0 {
+		t.Errorf("btc filter = %+v", got)
+	}
+}
+
+func TestListFundTypes(t *testing.T) {
+	all := listFundTypes("")
+	if len(all) != len(constants.GetAllFundTypes()) {
+		t.Fatalf("listed %d fund types, registered %d", len(all), len(constants.GetAllFundTypes()))
+	}
+	admin := listFundTypes("ADMIN")
+	if len(admin) == 0 {
+		t.Fatal("no admin fund types")
+	}
+	for _, fundType := range admin {
+		if fundType.Category != "admin" {
+			t.Errorf("category filter returned %s (%s)", fundType.Type, fundType.Category)
+		}
+		if !strings.Contains(strings.Join(fundType.Flags, ","), "requires_approval") {
+			t.Errorf("%s flags = %v", fundType.Type, fundType.Flags)
+		}
+	}
+}
+
+func TestOptionParsing(t *testing.T) {
+	for answer, want := range map[string]bool{"y\n": true, " YES \n": true, "n\n": false, "": false, "yep": false} {
+		if isConfirmed(answer) != want {
+			t.Errorf("isConfirmed(%q) = %v", answer, !want)
+		}
+	}
+
+	if got := splitList(" deposit, ,withdraw "); len(got) != 2 || got[0] != "deposit" || got[1] != "withdraw" {
+		t.Errorf("splitList = %v", got)
+	}
+
+	if n, err := parsePositiveInt("id", "42"); err != nil || n != 42 {
+		t.Errorf("parsePositiveInt = %d, %v", n, err)
+	}
+	for _, value := range []string{"0", "-1", "abc", ""} {
+		if _, err := parsePositiveInt("id", value); err == nil {
+			t.Errorf("parsePositiveInt(%q) accepted", value)
+		}
+	}
+
+	if ts, err := parseTime("start", ""); ts != nil || err != nil {
+		t.Errorf("empty time = %v, %v", ts, err)
+	}
+	if ts, err := parseTime("start", "2024-03-01"); err != nil || ts.Day() != 1 || ts.Hour() != 0 {
+		t.Errorf("date = %v, %v", ts, err)
+	}
+	if ts, err := parseTime("start", "2024-03-01T08:00:00Z"); err != nil || ts.UTC().Hour() != 8 {
+		t.Errorf("rfc3339 = %v, %v", ts, err)
+	}
+	if _, err := parseTime("start", "yesterday"); err == nil {
+		t.Error("invalid time accepted")
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/gogf/gf/v2/os/gcmd"
+
+	"github.com/yalks/wallet"
+)
+
+// reconcileCommand walletctl reconcile：比较钱包余额与账本，发现差异时退出码为 2
+func reconcileCommand() *gcmd.Command {
+	return &gcmd.Command{
+		Name:  "reconcile",
+		Usage: "walletctl reconcile [--user-id ID] [--token SYMBOL] [--audit]",
+		Brief: "对账：比较钱包余额与账本最后一笔成功交易的操作后余额",
+		Description: "不指定用户和代币时检查全部钱包。--audit 同时校验每个钱包的审计哈希链。" +
+			"全部一致时退出码为 0，发现差异时为 2",
+		Arguments: withArguments([]gcmd.Argument{
+			{Name: "user-id", Brief: "只检查该用户的钱包"},
+			{Name: "token", Short: "t", Brief: "只检查该代币的钱包"},
+			{Name: "audit", Orphan: true, Brief: "同时校验审计哈希链"},
+		}),
+		Func: func(ctx context.Context, parser *gcmd.Parser) error {
+			out, err := setup(ctx, parser)
+			if err != nil {
+				return err
+			}
+			req := &wallet.ReconcileRequest{
+				TokenSymbol: parser.GetOpt("token").String(),
+				VerifyAudit: parser.GetOpt("audit") != nil,
+			}
+			if value := parser.GetOpt("user-id").String(); value != "" {
+				userID, err := parsePositiveInt("user-id", value)
+				if err != nil {
+					return err
+				}
+				req.UserID = uint64(userID)
+			}
+
+			report, err := wallet.Manager().Reconcile(ctx, req)
+			if err != nil {
+				return err
+			}
+			if err := out.print(report, mismatchTable(report.Mismatches)); err != nil {
+				return err
+			}
+			if out.format == outputTable {
+				if len(report.AuditFailures) > 0 {
+					fmt.Fprintln(out.out)
+					if err := writeTable(out.out, auditFailureTable(report.AuditFailures)); err != nil {
+						return err
+					}
+				}
+				fmt.Fprintf(os.Stderr, "已检查 %d 个钱包，余额差异 %d 个，审计链断裂 %d 个\n",
+					report.Checked, len(report.Mismatches), len(report.AuditFailures))
+			}
+			if !report.OK() {
+				return errReconcileMismatch
+			}
+			return nil
+		},
+	}
+}
+
+// mismatchTable 余额差异表格
+func mismatchTable(mismatches []*wallet.BalanceMismatch) *table {
+	t := &table{Headers: []string{"USER", "TOKEN", "WALLET", "LEDGER", "DIFFERENCE", "LAST_TRANSACTION"}}
+	for _, mismatch := range mismatches {
+		lastTransactionID := ""
+		if mismatch.LastTransactionID != 0 {
+			lastTransactionID = strconv.FormatUint(mismatch.LastTransactionID, 10)
+		}
+		t.Rows = append(t.Rows, []string{
+			strconv.FormatUint(mismatch.UserID, 10),
+			mismatch.TokenSymbol,
+			mismatch.WalletBalance.String(),
+			mismatch.LedgerBalance.String(),
+			mismatch.Difference.String(),
+			lastTransactionID,
+		})
+	}
+	return t
+}
+
+// auditFailureTable 审计哈希链断裂表格
+func auditFailureTable(reports []*wallet.AuditVerificationReport) *table {
+	t := &table{Headers: []string{"USER", "TOKEN", "BROKEN_SEQUENCE", "BROKEN_TRANSACTION", "REASON"}}
+	for _, report := range reports {
+		t.Rows = append(t.Rows, []string{
+			strconv.FormatUint(report.UserID, 10),
+			report.TokenSymbol,
+			strconv.FormatUint(report.BrokenSequence, 10),
+			strconv.FormatUint(report.BrokenTransactionID, 10),
+			report.Reason,
+		})
+	}
+	return t
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/os/gcmd"
+
+	"github.com/yalks/wallet"
+	"github.com/yalks/wallet/constants"
+)
+
+// transactionCommand walletctl tx：交易历史和交易详情
+func transactionCommand() *gcmd.Command {
+	command := &gcmd.Command{
+		Name:  "tx",
+		Usage: "walletctl tx COMMAND [OPTION]",
+		Brief: "查询交易记录",
+	}
+	_ = command.AddCommand(
+		&gcmd.Command{
+			Name:  "list",
+			Usage: "walletctl tx list (--user-id ID | --telegram-id ID | --username NAME) [OPTION]",
+			Brief: "按条件查询用户的交易历史（按时间倒序）",
+			Arguments: withArguments(userArguments, []gcmd.Argument{
+				{Name: "token", Short: "t", Brief: "代币符号"},
+				{Name: "fund-type", Brief: "资金类型，多个用逗号分隔"},
+				{Name: "status", Brief: "交易状态，多个用逗号分隔"},
+				{Name: "start", Brief: "开始时间（含），YYYY-MM-DD 或 RFC 3339"},
+				{Name: "end", Brief: "结束时间（不含），YYYY-MM-DD 或 RFC 3339"},
+				{Name: "limit", Short: "n", Brief: "每页条数（默认20，最大200）"},
+				{Name: "cursor", Brief: "上一页输出的下一页游标"},
+			}),
+			Func: listTransactions,
+		},
+		&gcmd.Command{
+			Name:  "get",
+			Usage: "walletctl tx get (--id ID | --reference REFERENCE)",
+			Brief: "按交易ID或业务引用查询交易详情",
+			Arguments: withArguments([]gcmd.Argument{
+				{Name: "id", Brief: "交易ID"},
+				{Name: "reference", Short: "r", Brief: "业务引用（business_id）"},
+			}),
+			Func: getTransaction,
+		},
+	)
+	return command
+}
+
+// listTransactions walletctl tx list
+func listTransactions(ctx context.Context, parser *gcmd.Parser) error {
+	out, err := setup(ctx, parser)
+	if err != nil {
+		return err
+	}
+	user, err := resolveUser(ctx, parser)
+	if err != nil {
+		return err
+	}
+
+	query := &wallet.TransactionQuery{
+		UserID:      int64(user.Id),
+		TokenSymbol: parser.GetOpt("token").String(),
+		Cursor:      parser.GetOpt("cursor").String(),
+	}
+	for _, fundType := range splitList(parser.GetOpt("fund-type").String()) {
+		query.FundTypes = append(query.FundTypes, constants.FundType(fundType))
+	}
+	for _, status := range splitList(parser.GetOpt("status").String()) {
+		query.Statuses = append(query.Statuses, constants.TransactionStatus(status))
+	}
+	if query.StartTime, err = parseTime("start", parser.GetOpt("start").String()); err != nil {
+		return err
+	}
+	if query.EndTime, err = parseTime("end", parser.GetOpt("end").String()); err != nil {
+		return err
+	}
+	if value := parser.GetOpt("limit").String(); value != "" {
+		limit, err := parsePositiveInt("limit", value)
+		if err != nil {
+			return err
+		}
+		query.Limit = int(limit)
+	}
+
+	page, err := wallet.Manager().SearchTransactions(ctx, query)
+	if err != nil {
+		return err
+	}
+	if err := out.print(page, transactionTable(page.Records)); err != nil {
+		return err
+	}
+	if out.format == outputTable && page.HasMore {
+		fmt.Fprintf(os.Stderr, "共 %d 条，下一页: --cursor %s\n", page.Total, page.NextCursor)
+	}
+	return nil
+}
+
+// getTransaction walletctl tx get
+func getTransaction(ctx context.Context, parser *gcmd.Parser) error {
+	out, err := setup(ctx, parser)
+	if err != nil {
+		return err
+	}
+
+	var (
+		record    *wallet.TransactionRecord
+		id        = parser.GetOpt("id").String()
+		reference = parser.GetOpt("reference").String()
+		manager   = wallet.NewTransactionManager()
+	)
+	switch {
+	case id != "" && reference == "":
+		transactionID, err := parsePositiveInt("id", id)
+		if err != nil {
+			return err
+		}
+		record, err = manager.GetTransactionByID(ctx, transactionID)
+		if err != nil {
+			return err
+		}
+	case reference != "" && id == "":
+		record, err = manager.GetTransactionByReference(ctx, reference)
+		if err != nil {
+			return err
+		}
+		if record == nil {
+			return gerror.NewCodef(gcode.CodeNotFound, "交易记录不存在: Reference=%s", reference)
+		}
+	default:
+		return gerror.NewCode(gcode.CodeMissingParameter, "请指定 --id 或 --reference 之一")
+	}
+	return out.print(record, transactionTable([]*wallet.TransactionRecord{record}))
+}
+
+// transactionTable 交易记录表格
+func transactionTable(records []*wallet.TransactionRecord) *table {
+	t := &table{Headers: []string{"ID", "USER", "FUND_TYPE", "DIRECTION", "AMOUNT", "STATUS", "REFERENCE", "CREATED_AT"}}
+	for _, record := range records {
+		t.Rows = append(t.Rows, []string{
+			strconv.FormatInt(record.ID, 10),
+			strconv.FormatInt(record.UserID, 10),
+			string(record.FundType),
+			string(record.Direction),
+			record.Amount,
+			string(record.Status),
+			record.Reference,
+			record.CreatedAt,
+		})
+	}
+	return t
+}
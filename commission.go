@@ -0,0 +1,62 @@
+package wallet
+
+import (
+	"context"
+
+	"github.com/yalks/wallet/constants"
+	"github.com/yalks/wallet/entity"
+	"github.com/yalks/wallet/logic"
+)
+
+// CommissionChain 佣金分配所沿的关系链
+type CommissionChain = logic.CommissionChain
+
+const (
+	CommissionChainReferral = logic.CommissionChainReferral // 推荐链
+	CommissionChainAgent    = logic.CommissionChainAgent    // 代理链
+)
+
+// GetCommissionsBySource 获取源交易产生的全部佣金（按层级排序）
+func (m *walletManager) GetCommissionsBySource(ctx context.Context, sourceTransactionID uint64) ([]*CommissionInfo, error) {
+	records, err := logic.GetSharedContext().GetCommissionDAO().ListBySourceTransaction(ctx, sourceTransactionID)
+	if err != nil {
+		return nil, err
+	}
+	return convertToCommissionInfos(records), nil
+}
+
+// ListCommissions 获取受益人获得的佣金（tokenSymbol 为空时不限代币，按时间倒序）
+func (m *walletManager) ListCommissions(ctx context.Context, beneficiaryID uint64, tokenSymbol string, limit, offset int) ([]*CommissionInfo, error) {
+	records, err := logic.GetSharedContext().GetCommissionDAO().ListByBeneficiary(ctx, beneficiaryID, tokenSymbol, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return convertToCommissionInfos(records), nil
+}
+
+// convertToCommissionInfos 转换佣金记录
+func convertToCommissionInfos(records []*entity.CommissionRecords) []*CommissionInfo {
+	infos := make([]*CommissionInfo, 0, len(records))
+	for _, record := range records {
+		info := &CommissionInfo{
+			ID:                  record.Id,
+			SourceTransactionID: record.SourceTransactionId,
+			SourceUserID:        record.SourceUserId,
+			SourceFundType:      constants.FundType(record.SourceFundType),
+			SourceAmount:        record.SourceAmount,
+			TokenSymbol:         record.Symbol,
+			BeneficiaryID:       record.BeneficiaryId,
+			Level:               record.Level,
+			Chain:               record.Chain,
+			Rate:                record.Rate,
+			Amount:              record.Amount,
+			FundType:            constants.FundType(record.FundType),
+			TransactionID:       record.TransactionId,
+		}
+		if record.CreatedAt != nil {
+			info.CreatedAt = record.CreatedAt.String()
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
@@ -1,7 +1,10 @@
 package constants
 
 import (
+	"sync"
 	"testing"
+
+	"github.com/shopspring/decimal"
 )
 
 func TestOperationTypes(t *testing.T) {
@@ -194,6 +197,182 @@ func TestValidationFunctions(t *testing.T) {
 	}
 }
 
+func TestResolveFundDirection(t *testing.T) {
+	tests := []struct {
+		name      string
+		fundType  FundType
+		requested FundDirection
+		expected  FundDirection
+		wantErr   bool
+	}{
+		{"fixed type without direction", FundTypeDeposit, "", FundDirectionIn, false},
+		{"fixed type with matching direction", FundTypeWithdraw, FundDirectionOut, FundDirectionOut, false},
+		{"fixed type with conflicting direction", FundTypeDeposit, FundDirectionOut, "", true},
+		{"bidirectional type in", FundTypeSystemAdjustment, FundDirectionIn, FundDirectionIn, false},
+		{"bidirectional type out", FundTypeSystemAdjustment, FundDirectionOut, FundDirectionOut, false},
+		{"bidirectional type without direction", FundTypeSystemAdjustment, "", "", true},
+		{"invalid direction", FundTypeSystemAdjustment, "sideways", "", true},
+		{"unknown fund type", "unknown", FundDirectionIn, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			direction, err := ResolveFundDirection(tt.fundType, tt.requested)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveFundDirection(%s, %q) error = %v, wantErr %v", tt.fundType, tt.requested, err, tt.wantErr)
+			}
+			if direction != tt.expected {
+				t.Errorf("ResolveFundDirection(%s, %q) = %s, want %s", tt.fundType, tt.requested, direction, tt.expected)
+			}
+		})
+	}
+
+	if !IsBidirectionalFundType(FundTypeSystemAdjustment) || IsBidirectionalFundType(FundTypeDeposit) {
+		t.Error("only system adjustment should be bidirectional")
+	}
+}
+
+func TestRegisterFundType(t *testing.T) {
+	gameReward := FundTypeInfo{
+		Type:           "test_game_reward",
+		Direction:      FundDirectionIn,
+		Description:    "Game reward",
+		Category:       "test_game",
+		Flags:          FundTypeFlagSchedulable | FundTypeFlagBulkPayout,
+		AllowedSources: []string{"api", "Admin"},
+		Limits:         FundTypeLimits{MinAmount: decimal.RequireFromString("0.01"), MaxAmount: decimal.RequireFromString("500")},
+	}
+	if err := RegisterFundType(gameReward); err != nil {
+		t.Fatalf("RegisterFundType() error = %v", err)
+	}
+	if err := RegisterFundType(gameReward); err != nil {
+		t.Errorf("registering an identical definition again should succeed, got %v", err)
+	}
+
+	info, exists := GetFundTypeInfo("test_game_reward")
+	if !exists || !info.HasFlag(FundTypeFlagBulkPayout) || info.HasFlag(FundTypeFlagRequiresApproval) {
+		t.Fatalf("unexpected registered info: %+v", info)
+	}
+	if GetFundDirection("test_game_reward") != FundDirectionIn {
+		t.Error("registered fund type should keep its direction")
+	}
+	if types := GetFundTypesByCategory("test_game"); len(types) != 1 || types[0] != "test_game_reward" {
+		t.Errorf("GetFundTypesByCategory() = %v", types)
+	}
+
+	conflicting := gameReward
+	conflicting.Direction = FundDirectionOut
+	if err := RegisterFundType(conflicting); err == nil {
+		t.Error("expected conflict for a different definition")
+	}
+	if err := RegisterFundType(FundTypeInfo{Type: FundTypeDeposit, Direction: FundDirectionIn, Category: "wallet"}); err == nil {
+		t.Error("expected conflict when redefining a built-in fund type")
+	}
+
+	invalid := []FundTypeInfo{
+		{Type: "Bad-Name", Direction: FundDirectionIn, Category: "x"},
+		{Type: "test_no_category", Direction: FundDirectionIn},
+		{Type: "test_no_direction", Category: "x"},
+		{Type: "test_bidirectional_fixed", Direction: FundDirectionIn, Bidirectional: true, Category: "x"},
+		{Type: "test_bad_flags", Direction: FundDirectionIn, Category: "x", Flags: 1 << 20},
+		{Type: "test_approval_schedulable", Direction: FundDirectionIn, Category: "x", Flags: FundTypeFlagRequiresApproval | FundTypeFlagSchedulable},
+		{Type: "test_approval_bulk", Direction: FundDirectionIn, Category: "x", Flags: FundTypeFlagRequiresApproval | FundTypeFlagBulkPayout},
+		{Type: "test_bad_limits", Direction: FundDirectionIn, Category: "x", Limits: FundTypeLimits{MinAmount: decimal.NewFromInt(10), MaxAmount: decimal.NewFromInt(1)}},
+	}
+	for _, info := range invalid {
+		if err := RegisterFundType(info); err == nil {
+			t.Errorf("expected validation error for %+v", info)
+		}
+		if IsValidFundType(info.Type) && info.Type != "" {
+			t.Errorf("invalid definition %s should not be registered", info.Type)
+		}
+	}
+}
+
+func TestBuiltinFundTypesAreValid(t *testing.T) {
+	for _, fundType := range GetAllFundTypes() {
+		info, _ := GetFundTypeInfo(fundType)
+		if err := validateFundTypeInfo(info); err != nil {
+			t.Errorf("built-in fund type %s: %v", fundType, err)
+		}
+	}
+}
+
+func TestRegisterFundType_Concurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			direction := FundDirectionIn
+			if i%2 == 1 {
+				direction = FundDirectionOut
+			}
+			errs <- RegisterFundType(FundTypeInfo{Type: "test_concurrent", Direction: direction, Category: "test"})
+			_ = GetAllFundTypes()
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+		}
+	}
+	// Only the first definition wins; every identical registration after it succeeds
+	if succeeded != 10 {
+		t.Errorf("expected 10 successful registrations, got %d", succeeded)
+	}
+}
+
+func TestValidateFundTypeUsage(t *testing.T) {
+	err := RegisterFundType(FundTypeInfo{
+		Type:           "test_merchant_settlement",
+		Direction:      FundDirectionIn,
+		Category:       "test_merchant",
+		AllowedSources: []string{"admin"},
+		Limits:         FundTypeLimits{MaxAmount: decimal.NewFromInt(100)},
+	})
+	if err != nil {
+		t.Fatalf("RegisterFundType() error = %v", err)
+	}
+
+	tests := []struct {
+		source  string
+		amount  int64
+		wantErr bool
+	}{
+		{"admin", 50, false},
+		{"ADMIN", 100, false},
+		{"api", 50, true},
+		{"", 50, true},
+		{"admin", 101, true},
+	}
+	for _, tt := range tests {
+		err := ValidateFundTypeUsage("test_merchant_settlement", tt.source, decimal.NewFromInt(tt.amount))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateFundTypeUsage(%q, %d) error = %v, wantErr %v", tt.source, tt.amount, err, tt.wantErr)
+		}
+	}
+
+	if err := ValidateFundTypeUsage(FundTypeDeposit, "", decimal.NewFromInt(1)); err != nil {
+		t.Errorf("built-in fund types should have no restrictions, got %v", err)
+	}
+}
+
+func TestParseFundTypeFlags(t *testing.T) {
+	flags, err := ParseFundTypeFlags([]string{"schedulable", " BULK_PAYOUT "})
+	if err != nil || flags != FundTypeFlagSchedulable|FundTypeFlagBulkPayout {
+		t.Errorf("ParseFundTypeFlags() = %b, %v", flags, err)
+	}
+	if _, err := ParseFundTypeFlags([]string{"teleport"}); err == nil {
+		t.Error("expected error for unknown flag")
+	}
+}
+
 // Benchmark tests
 func BenchmarkOperationTypeComparison(b *testing.B) {
 	opType := OperationTypeCredit
@@ -1,5 +1,15 @@
 package constants
 
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
 // FundType represents the type of fund transaction
 type FundType string
 
@@ -48,14 +58,98 @@ const (
 	FundDirectionOut FundDirection = "out" // 资金流出
 )
 
+// FundTypeFlag is a capability flag of a fund type
+type FundTypeFlag uint32
+
+const (
+	FundTypeFlagRequiresApproval FundTypeFlag = 1 << iota // 需要通过后台调账审批流程执行
+	FundTypeFlagSchedulable                               // 可用于定时和循环操作
+	FundTypeFlagBulkPayout                                // 可用于批量发放
+)
+
+// fundTypeFlagNames maps config names to fund type flags
+var fundTypeFlagNames = map[string]FundTypeFlag{
+	"requires_approval": FundTypeFlagRequiresApproval,
+	"schedulable":       FundTypeFlagSchedulable,
+	"bulk_payout":       FundTypeFlagBulkPayout,
+}
+
+// ParseFundTypeFlags parses flag names (requires_approval, schedulable, bulk_payout)
+func ParseFundTypeFlags(names []string) (FundTypeFlag, error) {
+	var flags FundTypeFlag
+	for _, name := range names {
+		flag, ok := fundTypeFlagNames[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return 0, fmt.Errorf("unknown fund type flag: %s", name)
+		}
+		flags |= flag
+	}
+	return flags, nil
+}
+
+// FundTypeLimits contains the default per-operation amount limits of a fund type; zero means no limit
+type FundTypeLimits struct {
+	MinAmount decimal.Decimal
+	MaxAmount decimal.Decimal
+}
+
 // FundTypeInfo contains metadata about a fund type
 type FundTypeInfo struct {
-	Type        FundType
-	Direction   FundDirection
-	Description string
-	Category    string
+	Type           FundType
+	Direction      FundDirection // Fixed direction; empty for bidirectional fund types
+	Bidirectional  bool          // Direction must be given per request (in or out)
+	Description    string
+	Category       string
+	Flags          FundTypeFlag   // Capability flags
+	AllowedSources []string       // Request sources allowed to use the fund type; empty allows all
+	Limits         FundTypeLimits // Default per-operation amount limits
 }
 
+// HasFlag checks if the fund type has the given capability flag
+func (i FundTypeInfo) HasFlag(flag FundTypeFlag) bool {
+	return i.Flags&flag == flag
+}
+
+// AllowsSource checks if the request source may use the fund type
+func (i FundTypeInfo) AllowsSource(source string) bool {
+	if len(i.AllowedSources) == 0 {
+		return true
+	}
+	for _, allowed := range i.AllowedSources {
+		if strings.EqualFold(allowed, source) {
+			return true
+		}
+	}
+	return false
+}
+
+// equal checks if two definitions are identical (used to allow idempotent re-registration)
+func (i FundTypeInfo) equal(other FundTypeInfo) bool {
+	if i.Type != other.Type || i.Direction != other.Direction || i.Bidirectional != other.Bidirectional ||
+		i.Description != other.Description || i.Category != other.Category || i.Flags != other.Flags ||
+		!i.Limits.MinAmount.Equal(other.Limits.MinAmount) || !i.Limits.MaxAmount.Equal(other.Limits.MaxAmount) ||
+		len(i.AllowedSources) != len(other.AllowedSources) {
+		return false
+	}
+	for idx := range i.AllowedSources {
+		if i.AllowedSources[idx] != other.AllowedSources[idx] {
+			return false
+		}
+	}
+	return true
+}
+
+// clone returns a copy that does not share the AllowedSources slice
+func (i FundTypeInfo) clone() FundTypeInfo {
+	if i.AllowedSources != nil {
+		i.AllowedSources = append([]string(nil), i.AllowedSources...)
+	}
+	return i
+}
+
+// fundTypeMu guards fundTypeRegistry, which RegisterFundType extends at runtime
+var fundTypeMu sync.RWMutex
+
 // fundTypeRegistry maps fund types to their info
 var fundTypeRegistry = map[FundType]FundTypeInfo{
 	// Red Packet operations
@@ -64,24 +158,28 @@ var fundTypeRegistry = map[FundType]FundTypeInfo{
 		Direction:   FundDirectionOut,
 		Description: "Red packet creation fee",
 		Category:    "red_packet",
+		Flags:       FundTypeFlagSchedulable,
 	},
 	FundTypeRedPacketClaim: {
 		Type:        FundTypeRedPacketClaim,
 		Direction:   FundDirectionIn,
 		Description: "Red packet claim",
 		Category:    "red_packet",
+		Flags:       FundTypeFlagSchedulable | FundTypeFlagBulkPayout,
 	},
 	FundTypeRedPacketRefund: {
 		Type:        FundTypeRedPacketRefund,
 		Direction:   FundDirectionIn,
 		Description: "Red packet expiry refund",
 		Category:    "red_packet",
+		Flags:       FundTypeFlagSchedulable | FundTypeFlagBulkPayout,
 	},
 	FundTypeRedPacketCancel: {
 		Type:        FundTypeRedPacketCancel,
 		Direction:   FundDirectionIn,
 		Description: "Red packet manual cancel refund",
 		Category:    "red_packet",
+		Flags:       FundTypeFlagSchedulable | FundTypeFlagBulkPayout,
 	},
 
 	// Transfer operations
@@ -90,18 +188,21 @@ var fundTypeRegistry = map[FundType]FundTypeInfo{
 		Direction:   FundDirectionOut,
 		Description: "Transfer initiation",
 		Category:    "transfer",
+		Flags:       FundTypeFlagSchedulable,
 	},
 	FundTypeTransferIn: {
 		Type:        FundTypeTransferIn,
 		Direction:   FundDirectionIn,
 		Description: "Transfer receipt",
 		Category:    "transfer",
+		Flags:       FundTypeFlagSchedulable | FundTypeFlagBulkPayout,
 	},
 	FundTypeTransferExpired: {
 		Type:        FundTypeTransferExpired,
 		Direction:   FundDirectionIn,
 		Description: "Transfer expiry refund",
 		Category:    "transfer",
+		Flags:       FundTypeFlagSchedulable | FundTypeFlagBulkPayout,
 	},
 
 	// Payment operations
@@ -110,18 +211,21 @@ var fundTypeRegistry = map[FundType]FundTypeInfo{
 		Direction:   FundDirectionIn,
 		Description: "Payment request",
 		Category:    "payment",
+		Flags:       FundTypeFlagSchedulable | FundTypeFlagBulkPayout,
 	},
 	FundTypePaymentOut: {
 		Type:        FundTypePaymentOut,
 		Direction:   FundDirectionOut,
 		Description: "Payment made",
 		Category:    "payment",
+		Flags:       FundTypeFlagSchedulable,
 	},
 	FundTypePaymentIn: {
 		Type:        FundTypePaymentIn,
 		Direction:   FundDirectionIn,
 		Description: "Payment received",
 		Category:    "payment",
+		Flags:       FundTypeFlagSchedulable | FundTypeFlagBulkPayout,
 	},
 
 	// Deposit and Withdraw
@@ -130,18 +234,21 @@ var fundTypeRegistry = map[FundType]FundTypeInfo{
 		Direction:   FundDirectionIn,
 		Description: "Deposit",
 		Category:    "wallet",
+		Flags:       FundTypeFlagSchedulable | FundTypeFlagBulkPayout,
 	},
 	FundTypeWithdraw: {
 		Type:        FundTypeWithdraw,
 		Direction:   FundDirectionOut,
 		Description: "Withdrawal",
 		Category:    "wallet",
+		Flags:       FundTypeFlagSchedulable,
 	},
 	FundTypeWithdrawRefund: {
 		Type:        FundTypeWithdrawRefund,
 		Direction:   FundDirectionIn,
 		Description: "Withdrawal refund",
 		Category:    "wallet",
+		Flags:       FundTypeFlagSchedulable | FundTypeFlagBulkPayout,
 	},
 
 	// Admin operations
@@ -150,12 +257,14 @@ var fundTypeRegistry = map[FundType]FundTypeInfo{
 		Direction:   FundDirectionIn,
 		Description: "Admin balance addition",
 		Category:    "admin",
+		Flags:       FundTypeFlagRequiresApproval,
 	},
 	FundTypeAdminDeduct: {
 		Type:        FundTypeAdminDeduct,
 		Direction:   FundDirectionOut,
 		Description: "Admin balance deduction",
 		Category:    "admin",
+		Flags:       FundTypeFlagRequiresApproval,
 	},
 
 	// Exchange operations
@@ -164,12 +273,14 @@ var fundTypeRegistry = map[FundType]FundTypeInfo{
 		Direction:   FundDirectionOut,
 		Description: "Exchange deduction",
 		Category:    "exchange",
+		Flags:       FundTypeFlagSchedulable,
 	},
 	FundTypeExchangeIn: {
 		Type:        FundTypeExchangeIn,
 		Direction:   FundDirectionIn,
 		Description: "Exchange addition",
 		Category:    "exchange",
+		Flags:       FundTypeFlagSchedulable | FundTypeFlagBulkPayout,
 	},
 
 	// Others
@@ -178,57 +289,200 @@ var fundTypeRegistry = map[FundType]FundTypeInfo{
 		Direction:   FundDirectionIn,
 		Description: "Commission earned",
 		Category:    "bonus",
+		Flags:       FundTypeFlagSchedulable | FundTypeFlagBulkPayout,
 	},
 	FundTypeReferralBonus: {
 		Type:        FundTypeReferralBonus,
 		Direction:   FundDirectionIn,
 		Description: "Referral bonus",
 		Category:    "bonus",
+		Flags:       FundTypeFlagSchedulable | FundTypeFlagBulkPayout,
 	},
 	FundTypeSystemAdjustment: {
-		Type:        FundTypeSystemAdjustment,
-		Direction:   FundDirectionIn, // Can be both, default to in
-		Description: "System adjustment",
-		Category:    "system",
+		Type:          FundTypeSystemAdjustment,
+		Bidirectional: true, // Corrections can add or deduct funds
+		Description:   "System adjustment",
+		Category:      "system",
+		Flags:         FundTypeFlagRequiresApproval,
 	},
 }
 
 // GetFundTypeInfo returns the info for a given fund type
 func GetFundTypeInfo(fundType FundType) (FundTypeInfo, bool) {
+	fundTypeMu.RLock()
+	defer fundTypeMu.RUnlock()
 	info, exists := fundTypeRegistry[fundType]
-	return info, exists
+	return info.clone(), exists
 }
 
-// GetFundDirection returns the direction for a given fund type
+// GetFundDirection returns the fixed direction for a given fund type.
+// It returns an empty direction for unknown and bidirectional fund types; use ResolveFundDirection
+// when the request may carry an explicit direction
 func GetFundDirection(fundType FundType) FundDirection {
-	if info, exists := fundTypeRegistry[fundType]; exists {
+	if info, exists := GetFundTypeInfo(fundType); exists {
 		return info.Direction
 	}
 	return ""
 }
 
+// IsBidirectionalFundType checks if the direction of a fund type is given per request
+func IsBidirectionalFundType(fundType FundType) bool {
+	info, exists := GetFundTypeInfo(fundType)
+	return exists && info.Bidirectional
+}
+
+// IsValidFundDirection checks if a direction is in or out
+func IsValidFundDirection(direction FundDirection) bool {
+	return direction == FundDirectionIn || direction == FundDirectionOut
+}
+
+// ResolveFundDirection returns the effective direction of an operation.
+// Bidirectional fund types require an explicit in/out direction; for other fund types
+// the requested direction is optional but must match the registered one
+func ResolveFundDirection(fundType FundType, requested FundDirection) (FundDirection, error) {
+	info, exists := GetFundTypeInfo(fundType)
+	if !exists {
+		return "", fmt.Errorf("invalid fund type: %s", fundType)
+	}
+	if requested != "" && !IsValidFundDirection(requested) {
+		return "", fmt.Errorf("invalid fund direction: %s", requested)
+	}
+
+	if info.Bidirectional {
+		if requested == "" {
+			return "", fmt.Errorf("fund type %s requires an explicit direction (in or out)", fundType)
+		}
+		return requested, nil
+	}
+	if requested != "" && requested != info.Direction {
+		return "", fmt.Errorf("fund type %s only supports direction %s, got %s", fundType, info.Direction, requested)
+	}
+	return info.Direction, nil
+}
+
 // IsValidFundType checks if a fund type is valid
 func IsValidFundType(fundType FundType) bool {
-	_, exists := fundTypeRegistry[fundType]
+	_, exists := GetFundTypeInfo(fundType)
 	return exists
 }
 
-// GetFundTypesByCategory returns all fund types for a given category
+// GetFundTypesByCategory returns all fund types for a given category, sorted by name
 func GetFundTypesByCategory(category string) []FundType {
+	fundTypeMu.RLock()
+	defer fundTypeMu.RUnlock()
 	var types []FundType
 	for _, info := range fundTypeRegistry {
 		if info.Category == category {
 			types = append(types, info.Type)
 		}
 	}
+	sortFundTypes(types)
 	return types
 }
 
-// GetAllFundTypes returns all registered fund types
+// GetAllFundTypes returns all registered fund types, sorted by name
 func GetAllFundTypes() []FundType {
+	fundTypeMu.RLock()
+	defer fundTypeMu.RUnlock()
 	var types []FundType
 	for fundType := range fundTypeRegistry {
 		types = append(types, fundType)
 	}
+	sortFundTypes(types)
 	return types
+}
+
+// sortFundTypes sorts fund types by name for stable output
+func sortFundTypes(types []FundType) {
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+}
+
+// fundTypePattern restricts fund type names to what fits the transactions.type column
+var fundTypePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// RegisterFundType registers a business fund type at runtime.
+// Registering an identical definition again is a no-op; a different definition for an existing
+// type (including the built-in ones) is rejected as a conflict
+func RegisterFundType(info FundTypeInfo) error {
+	info = info.clone()
+	info.Category = strings.TrimSpace(info.Category)
+	info.Description = strings.TrimSpace(info.Description)
+	for idx, source := range info.AllowedSources {
+		info.AllowedSources[idx] = strings.ToLower(strings.TrimSpace(source))
+	}
+	if err := validateFundTypeInfo(info); err != nil {
+		return err
+	}
+
+	fundTypeMu.Lock()
+	defer fundTypeMu.Unlock()
+	if existing, exists := fundTypeRegistry[info.Type]; exists {
+		if existing.equal(info) {
+			return nil
+		}
+		return fmt.Errorf("fund type %s is already registered with a different definition", info.Type)
+	}
+	fundTypeRegistry[info.Type] = info
+	return nil
+}
+
+// validateFundTypeInfo validates a fund type definition before registration
+func validateFundTypeInfo(info FundTypeInfo) error {
+	if !fundTypePattern.MatchString(string(info.Type)) {
+		return fmt.Errorf("invalid fund type name %q: use lowercase letters, digits and underscores (max 50)", info.Type)
+	}
+	if info.Category == "" {
+		return fmt.Errorf("fund type %s: category is required", info.Type)
+	}
+	if info.Bidirectional {
+		if info.Direction != "" {
+			return fmt.Errorf("fund type %s: bidirectional fund types must not have a fixed direction", info.Type)
+		}
+	} else if !IsValidFundDirection(info.Direction) {
+		return fmt.Errorf("fund type %s: invalid direction %q", info.Type, info.Direction)
+	}
+
+	var known FundTypeFlag
+	for _, flag := range fundTypeFlagNames {
+		known |= flag
+	}
+	if info.Flags&^known != 0 {
+		return fmt.Errorf("fund type %s: unknown flags %b", info.Type, info.Flags&^known)
+	}
+	// 定时、循环和批量发放不经过调账审批流程，需要审批的类型在这些路径上必然失败
+	if info.HasFlag(FundTypeFlagRequiresApproval) && (info.HasFlag(FundTypeFlagSchedulable) || info.HasFlag(FundTypeFlagBulkPayout)) {
+		return fmt.Errorf("fund type %s: requires_approval cannot be combined with schedulable or bulk_payout", info.Type)
+	}
+	for _, source := range info.AllowedSources {
+		if source == "" {
+			return fmt.Errorf("fund type %s: allowed sources must not be empty", info.Type)
+		}
+	}
+
+	limits := info.Limits
+	if limits.MinAmount.IsNegative() || limits.MaxAmount.IsNegative() {
+		return fmt.Errorf("fund type %s: limits must not be negative", info.Type)
+	}
+	if limits.MaxAmount.IsPositive() && limits.MinAmount.GreaterThan(limits.MaxAmount) {
+		return fmt.Errorf("fund type %s: min amount %s exceeds max amount %s", info.Type, limits.MinAmount, limits.MaxAmount)
+	}
+	return nil
+}
+
+// ValidateFundTypeUsage checks the request source permission and the default amount limits of a fund type
+func ValidateFundTypeUsage(fundType FundType, source string, amount decimal.Decimal) error {
+	info, exists := GetFundTypeInfo(fundType)
+	if !exists {
+		return fmt.Errorf("invalid fund type: %s", fundType)
+	}
+	if !info.AllowsSource(source) {
+		return fmt.Errorf("request source %q is not allowed to use fund type %s (allowed: %s)", source, fundType, strings.Join(info.AllowedSources, ", "))
+	}
+	if info.Limits.MinAmount.IsPositive() && amount.LessThan(info.Limits.MinAmount) {
+		return fmt.Errorf("amount %s is below the minimum %s for fund type %s", amount, info.Limits.MinAmount, fundType)
+	}
+	if info.Limits.MaxAmount.IsPositive() && amount.GreaterThan(info.Limits.MaxAmount) {
+		return fmt.Errorf("amount %s exceeds the maximum %s for fund type %s", amount, info.Limits.MaxAmount, fundType)
+	}
+	return nil
 }
\ No newline at end of file
@@ -0,0 +1,19 @@
+package constants
+
+// Metadata keys shared between transaction builders and the wallet manager
+const (
+	MetadataKeyAddress        = "address"         // 提现目标地址
+	MetadataKeyBatchReference = "batch_reference" // 批量转账共享引用号
+	MetadataKeyBatchIndex     = "batch_index"     // 批量转账中的序号
+	MetadataKeyAdjustmentID   = "adjustment_id"   // 调账申请ID
+	MetadataKeyAttachmentRef  = "attachment_ref"  // 调账附件引用
+	MetadataKeySubmittedBy    = "submitted_by"    // 调账提交人
+	MetadataKeyApprovedBy     = "approved_by"     // 调账审批人（逗号分隔）
+	MetadataKeyDirection      = "direction"       // 双向资金类型的资金方向（定时/循环操作持久化）
+)
+
+// Callback keys set by TransactionBuilderEnhanced.WithCallback
+const (
+	CallbackKeyURL    = "url"    // 回调地址
+	CallbackKeyMethod = "method" // 回调方法 (GET/POST)
+)
@@ -17,6 +17,7 @@ type TransactionRequest struct {
 	Amount       string
 	TokenID      int64
 	FundType     FundType
+	Direction    FundDirection // Required for bidirectional fund types, optional otherwise
 	Reference    string
 	Description  string
 	RelatedID    int64  // Related entity ID (e.g., red packet ID, transfer ID)
@@ -89,6 +90,14 @@ func (b *TransactionBuilder) WithFundType(fundType FundType) *TransactionBuilder
 	return b
 }
 
+// WithDirection sets the fund direction (required for bidirectional fund types)
+func (b *TransactionBuilder) WithDirection(direction FundDirection) *TransactionBuilder {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.request.Direction = direction
+	return b
+}
+
 // WithReference sets the reference
 func (b *TransactionBuilder) WithReference(reference string) *TransactionBuilder {
 	b.mu.Lock()
@@ -225,6 +234,11 @@ func (b *TransactionBuilder) Build() (*TransactionRequest, error) {
 	if !IsValidFundType(b.request.FundType) {
 		return nil, fmt.Errorf("invalid fund type: %s", b.request.FundType)
 	}
+	direction, err := ResolveFundDirection(b.request.FundType, b.request.Direction)
+	if err != nil {
+		return nil, err
+	}
+	b.request.Direction = direction
 
 	// Auto-generate reference if not provided
 	if b.request.Reference == "" {
@@ -251,7 +265,7 @@ func (b *TransactionBuilder) Build() (*TransactionRequest, error) {
 // validateAmount 验证金额格式和范围
 func (b *TransactionBuilder) validateAmount(amount string) error {
 	// 检查基本格式
-	if matched, _ := regexp.MatchString(`^[0-9]+(\.[0-9]+)?$`, amount); !matched {
+	if matched, _ := regexp.MatchString(`^[0-9]+(\.[0-9]*)?$`, amount); !matched {
 		return fmt.Errorf("must be a positive number")
 	}
 	
@@ -328,6 +342,7 @@ type FundOperationBuilder struct {
 	amount           decimal.Decimal
 	businessID       string
 	fundType         FundType
+	direction        FundDirection
 	description      string
 	metadata         map[string]string
 	relatedID        int64
@@ -384,6 +399,14 @@ func (b *FundOperationBuilder) WithFundType(fundType FundType) *FundOperationBui
 	return b
 }
 
+// WithDirection 设置资金方向（双向资金类型必填）
+func (b *FundOperationBuilder) WithDirection(direction FundDirection) *FundOperationBuilder {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.direction = direction
+	return b
+}
+
 // WithDescription 设置操作描述
 func (b *FundOperationBuilder) WithDescription(description string) *FundOperationBuilder {
 	b.mu.Lock()
@@ -441,6 +464,7 @@ type FundOperationRequest struct {
 	Amount      decimal.Decimal    `json:"amount" validate:"required,gt=0"`
 	BusinessID  string             `json:"business_id" validate:"required"`
 	FundType    FundType           `json:"fund_type" validate:"required"`
+	Direction   FundDirection      `json:"direction,omitempty"` // 双向资金类型必填
 	Description string             `json:"description"`
 	Metadata    map[string]string  `json:"metadata,omitempty"`
 	RelatedID   int64              `json:"related_id,omitempty"`
@@ -470,6 +494,10 @@ func (b *FundOperationBuilder) Build() (*FundOperationRequest, error) {
 	if !IsValidFundType(b.fundType) {
 		return nil, fmt.Errorf("invalid fund type: %s", b.fundType)
 	}
+	direction, err := ResolveFundDirection(b.fundType, b.direction)
+	if err != nil {
+		return nil, err
+	}
 	
 	// 创建元数据副本
 	metadata := make(map[string]string)
@@ -483,6 +511,7 @@ func (b *FundOperationBuilder) Build() (*FundOperationRequest, error) {
 		Amount:           b.amount,
 		BusinessID:       b.businessID,
 		FundType:         b.fundType,
+		Direction:        direction,
 		Description:      b.description,
 		Metadata:         metadata,
 		RelatedID:        b.relatedID,
@@ -546,6 +575,6 @@ func BuildWithdrawTransaction(userID, walletID int64, amount string, tokenID int
 		WithFundType(FundTypeWithdraw).
 		WithRelatedID(withdrawID).
 		WithMetadata("withdraw_id", withdrawID).
-		WithMetadata("address", address).
+		WithMetadata(MetadataKeyAddress, address).
 		Build()
 }
\ No newline at end of file
@@ -35,6 +35,7 @@ type TransactionRequestEnhanced struct {
 	Amount       string
 	TokenID      int64
 	FundType     FundType
+	Direction    FundDirection // 资金方向（双向资金类型必填）
 	Reference    string
 	Description  string
 	RelatedID    int64
@@ -199,6 +200,18 @@ func (b *TransactionBuilderEnhanced) WithFundType(fundType FundType) *Transactio
 	return b
 }
 
+// WithDirection 设置资金方向（双向资金类型必填）
+func (b *TransactionBuilderEnhanced) WithDirection(direction FundDirection) *TransactionBuilderEnhanced {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if direction != "" && !IsValidFundDirection(direction) {
+		b.errors = append(b.errors, fmt.Sprintf("invalid fund direction: %s", direction))
+	}
+	b.request.Direction = direction
+	return b
+}
+
 // WithReference 设置引用号（支持自定义验证）
 func (b *TransactionBuilderEnhanced) WithReference(reference string) *TransactionBuilderEnhanced {
 	b.mu.Lock()
@@ -386,6 +399,11 @@ func (b *TransactionBuilderEnhanced) Build() (*TransactionRequestEnhanced, error
 	if !IsValidFundType(b.request.FundType) {
 		return nil, fmt.Errorf("valid fund type is required")
 	}
+	direction, err := ResolveFundDirection(b.request.FundType, b.request.Direction)
+	if err != nil {
+		return nil, err
+	}
+	b.request.Direction = direction
 	
 	// 生成默认值
 	if b.request.Reference == "" {
@@ -478,13 +496,32 @@ func (b *TransactionBuilderEnhanced) Clone() *TransactionBuilderEnhanced {
 
 // 便捷构建器函数
 
-// BuildBatchTransfer 批量转账构建器
+// BuildBatchTransfer 批量转账构建器（自动生成共享的批次引用号）
 func BuildBatchTransfer(fromUserID, fromWalletID int64, transfers []struct {
 	ToUserID   int64
 	ToWalletID int64
 	Amount     string
 	TokenID    int64
 }) ([]*TransactionRequestEnhanced, error) {
+	reference := fmt.Sprintf("batch_%d_%d", fromUserID, time.Now().UnixNano())
+	return BuildBatchTransferWithReference(reference, fromUserID, fromWalletID, transfers)
+}
+
+// BuildBatchTransferWithReference 使用指定批次引用号构建批量转账，
+// 所有转出/转入请求共享该引用号（元数据 batch_reference），引用号同时作为批次幂等键
+func BuildBatchTransferWithReference(reference string, fromUserID, fromWalletID int64, transfers []struct {
+	ToUserID   int64
+	ToWalletID int64
+	Amount     string
+	TokenID    int64
+}) ([]*TransactionRequestEnhanced, error) {
+	if reference == "" {
+		return nil, fmt.Errorf("batch reference is required")
+	}
+	if len(transfers) == 0 {
+		return nil, fmt.Errorf("batch transfer requires at least one transfer")
+	}
+	
 	requests := make([]*TransactionRequestEnhanced, 0, len(transfers)*2)
 	
 	for i, transfer := range transfers {
@@ -495,8 +532,10 @@ func BuildBatchTransfer(fromUserID, fromWalletID int64, transfers []struct {
 			WithAmount(transfer.Amount).
 			WithToken(transfer.TokenID).
 			WithFundType(FundTypeTransferOut).
+			WithReference(fmt.Sprintf("%s_%d_out", reference, i)).
 			WithMetadata(map[string]interface{}{
-				"batch_index": i,
+				MetadataKeyBatchReference: reference,
+				MetadataKeyBatchIndex:     i,
 				"to_user_id":  transfer.ToUserID,
 				"to_wallet_id": transfer.ToWalletID,
 			}).
@@ -514,8 +553,10 @@ func BuildBatchTransfer(fromUserID, fromWalletID int64, transfers []struct {
 			WithAmount(transfer.Amount).
 			WithToken(transfer.TokenID).
 			WithFundType(FundTypeTransferIn).
+			WithReference(fmt.Sprintf("%s_%d_in", reference, i)).
 			WithMetadata(map[string]interface{}{
-				"batch_index": i,
+				MetadataKeyBatchReference: reference,
+				MetadataKeyBatchIndex:     i,
 				"from_user_id": fromUserID,
 				"from_wallet_id": fromWalletID,
 			}).
@@ -553,4 +594,17 @@ func BuildRecurringTransaction(builder *TransactionBuilderEnhanced, interval tim
 		WithTags("recurring").
 		WithPriority(7). // 较高优先级
 		Build()
+}
+
+// BuildRecurringCronTransaction 构建按 cron 表达式执行的循环交易（count 为 0 表示不限次数）
+func BuildRecurringCronTransaction(builder *TransactionBuilderEnhanced, cronExpr string, count int) (*TransactionRequestEnhanced, error) {
+	return builder.
+		WithMetadata(map[string]interface{}{
+			"recurring_cron": cronExpr,
+			"recurring_count": count,
+			"recurring_status": "active",
+		}).
+		WithTags("recurring").
+		WithPriority(7). // 较高优先级
+		Build()
 }
\ No newline at end of file
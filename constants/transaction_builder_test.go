@@ -6,6 +6,7 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -371,12 +372,13 @@ func TestTransactionBuilder_SpecificBuilders(t *testing.T) {
 	})
 	
 	t.Run("TransferOut", func(t *testing.T) {
-		req, err := BuildTransferOutTransaction(123, 456, "50", 1, 999, "recipient_user")
+		req, err := BuildTransferOutTransaction(123, 456, "50", 1, 999, 321, "recipient_user")
 		require.NoError(t, err)
 		assert.Equal(t, FundTypeTransferOut, req.FundType)
 		assert.Equal(t, int64(999), req.RelatedID)
 		assert.Equal(t, int64(999), req.Metadata["transfer_id"])
 		assert.Equal(t, "recipient_user", req.Metadata["recipient"])
+		assert.Equal(t, int64(321), req.TargetUserID)
 	})
 	
 	t.Run("Deposit", func(t *testing.T) {
@@ -477,4 +479,82 @@ func BenchmarkTransactionBuilder_ConcurrentBuild(b *testing.B) {
 			i++
 		}
 	})
-}
\ No newline at end of file
+}
+
+func TestBuildBatchTransfer_SharedReference(t *testing.T) {
+	transfers := []struct {
+		ToUserID   int64
+		ToWalletID int64
+		Amount     string
+		TokenID    int64
+	}{
+		{ToUserID: 2, ToWalletID: 20, Amount: "10", TokenID: 1},
+		{ToUserID: 3, ToWalletID: 30, Amount: "5.5", TokenID: 1},
+	}
+
+	requests, err := BuildBatchTransferWithReference("payroll_2024_01", 1, 10, transfers)
+	require.NoError(t, err)
+	require.Len(t, requests, 4)
+
+	for i, req := range requests {
+		assert.Equal(t, "payroll_2024_01", req.Metadata[MetadataKeyBatchReference])
+		assert.Equal(t, i/2, req.Metadata[MetadataKeyBatchIndex])
+	}
+	assert.Equal(t, FundTypeTransferOut, requests[0].FundType)
+	assert.Equal(t, "payroll_2024_01_0_out", requests[0].Reference)
+	assert.Equal(t, FundTypeTransferIn, requests[1].FundType)
+	assert.Equal(t, "payroll_2024_01_0_in", requests[1].Reference)
+
+	generated, err := BuildBatchTransfer(1, 10, transfers)
+	require.NoError(t, err)
+	reference := generated[0].Metadata[MetadataKeyBatchReference]
+	assert.NotEmpty(t, reference)
+	assert.Equal(t, reference, generated[3].Metadata[MetadataKeyBatchReference])
+
+	_, err = BuildBatchTransferWithReference("", 1, 10, transfers)
+	assert.Error(t, err)
+}
+
+func TestBuilders_Direction(t *testing.T) {
+	base := func() *TransactionBuilder {
+		return NewTransactionBuilder().
+			WithUser(1).
+			WithWallet(1).
+			WithAmount("10").
+			WithToken(1)
+	}
+
+	_, err := base().WithFundType(FundTypeSystemAdjustment).Build()
+	assert.Error(t, err, "bidirectional fund type requires a direction")
+
+	req, err := base().WithFundType(FundTypeSystemAdjustment).WithDirection(FundDirectionOut).Build()
+	require.NoError(t, err)
+	assert.Equal(t, FundDirectionOut, req.Direction)
+
+	req, err = base().WithFundType(FundTypeDeposit).Build()
+	require.NoError(t, err)
+	assert.Equal(t, FundDirectionIn, req.Direction, "fixed direction is filled in")
+
+	_, err = base().WithFundType(FundTypeDeposit).WithDirection(FundDirectionOut).Build()
+	assert.Error(t, err, "conflicting direction is rejected")
+
+	op, err := NewFundOperationBuilder().
+		WithUser(1).
+		WithTokenSymbol("USDT").
+		WithAmount(decimal.NewFromInt(5)).
+		WithBusinessID("adj_1").
+		WithFundType(FundTypeSystemAdjustment).
+		WithDirection(FundDirectionIn).
+		Build()
+	require.NoError(t, err)
+	assert.Equal(t, FundDirectionIn, op.Direction)
+
+	_, err = NewTransactionBuilderEnhanced().
+		WithUser(1).
+		WithWallet(1).
+		WithAmount("10").
+		WithToken(1).
+		WithFundType(FundTypeSystemAdjustment).
+		Build()
+	assert.Error(t, err)
+}
@@ -0,0 +1,136 @@
+package dao
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+
+	"github.com/yalks/wallet/entity"
+)
+
+// IAdjustmentDAO 调账审批数据访问接口
+type IAdjustmentDAO interface {
+	// CreateRequest 创建调账申请，返回申请ID
+	CreateRequest(ctx context.Context, tx gdb.TX, request *entity.AdjustmentRequests) (uint64, error)
+	// GetRequest 获取调账申请，不存在时返回 nil
+	GetRequest(ctx context.Context, tx gdb.TX, requestID uint64) (*entity.AdjustmentRequests, error)
+	// ListRequests 获取调账申请（status 为空时不限状态）
+	ListRequests(ctx context.Context, status string, limit, offset int) ([]*entity.AdjustmentRequests, error)
+	// UpdatePendingRequest 更新仍处于待审批且审批人数未变化的申请，返回是否更新成功（用于并发审批冲突检测）
+	UpdatePendingRequest(ctx context.Context, tx gdb.TX, requestID uint64, approvalCount int, data map[string]any) (bool, error)
+	// CreateAction 记录审批操作（同一申请每位管理员只能操作一次）
+	CreateAction(ctx context.Context, tx gdb.TX, action *entity.AdjustmentActions) error
+	// HasAction 检查管理员是否已操作过该申请
+	HasAction(ctx context.Context, tx gdb.TX, requestID uint64, adminID string) (bool, error)
+	// ListActions 获取申请的全部审批操作（按时间排序）
+	ListActions(ctx context.Context, requestID uint64) ([]*entity.AdjustmentActions, error)
+}
+
+type adjustmentDAO struct{}
+
+// NewAdjustmentDAO 创建调账审批DAO实例
+func NewAdjustmentDAO() IAdjustmentDAO {
+	return &adjustmentDAO{}
+}
+
+// model 获取指定表的模型，tx 不为空时在事务中执行
+func (d *adjustmentDAO) model(ctx context.Context, tx gdb.TX, table string) *gdb.Model {
+	if tx != nil {
+		return g.Model(table).Ctx(ctx).TX(tx)
+	}
+	return g.Model(table).Ctx(ctx)
+}
+
+// CreateRequest 创建调账申请，返回申请ID
+func (d *adjustmentDAO) CreateRequest(ctx context.Context, tx gdb.TX, request *entity.AdjustmentRequests) (uint64, error) {
+	result, err := d.model(ctx, tx, "adjustment_requests").Insert(request)
+	if err != nil {
+		return 0, gerror.Wrapf(err, "创建调账申请失败: UserID=%d, Symbol=%s", request.UserId, request.Symbol)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, gerror.Wrap(err, "获取调账申请ID失败")
+	}
+	return uint64(id), nil
+}
+
+// GetRequest 获取调账申请，不存在时返回 nil
+func (d *adjustmentDAO) GetRequest(ctx context.Context, tx gdb.TX, requestID uint64) (*entity.AdjustmentRequests, error) {
+	var request *entity.AdjustmentRequests
+	err := d.model(ctx, tx, "adjustment_requests").Where("id = ?", requestID).Scan(&request)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "查询调账申请失败: RequestID=%d", requestID)
+	}
+	return request, nil
+}
+
+// ListRequests 获取调账申请（status 为空时不限状态）
+func (d *adjustmentDAO) ListRequests(ctx context.Context, status string, limit, offset int) ([]*entity.AdjustmentRequests, error) {
+	model := g.Model("adjustment_requests").Ctx(ctx).OrderDesc("id")
+	if status != "" {
+		model = model.Where("status = ?", status)
+	}
+	if limit > 0 {
+		model = model.Limit(limit)
+	}
+	if offset > 0 {
+		model = model.Offset(offset)
+	}
+
+	var requests []*entity.AdjustmentRequests
+	if err := model.Scan(&requests); err != nil {
+		return nil, gerror.Wrap(err, "查询调账申请失败")
+	}
+	return requests, nil
+}
+
+// UpdatePendingRequest 更新仍处于待审批且审批人数未变化的申请，返回是否更新成功（用于并发审批冲突检测）
+func (d *adjustmentDAO) UpdatePendingRequest(ctx context.Context, tx gdb.TX, requestID uint64, approvalCount int, data map[string]any) (bool, error) {
+	data["updated_at"] = gtime.Now()
+	result, err := d.model(ctx, tx, "adjustment_requests").
+		Where("id = ? AND status = ? AND approval_count = ?", requestID, "pending", approvalCount).
+		Update(data)
+	if err != nil {
+		return false, gerror.Wrapf(err, "更新调账申请失败: RequestID=%d", requestID)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, gerror.Wrap(err, "获取更新结果失败")
+	}
+	return affected > 0, nil
+}
+
+// CreateAction 记录审批操作（同一申请每位管理员只能操作一次）
+func (d *adjustmentDAO) CreateAction(ctx context.Context, tx gdb.TX, action *entity.AdjustmentActions) error {
+	if _, err := d.model(ctx, tx, "adjustment_actions").Insert(action); err != nil {
+		return gerror.Wrapf(err, "记录调账审批操作失败: RequestID=%d, Admin=%s", action.RequestId, action.AdminId)
+	}
+	return nil
+}
+
+// HasAction 检查管理员是否已操作过该申请
+func (d *adjustmentDAO) HasAction(ctx context.Context, tx gdb.TX, requestID uint64, adminID string) (bool, error) {
+	count, err := d.model(ctx, tx, "adjustment_actions").
+		Where("request_id = ? AND admin_id = ?", requestID, adminID).
+		Count()
+	if err != nil {
+		return false, gerror.Wrapf(err, "查询调账审批操作失败: RequestID=%d", requestID)
+	}
+	return count > 0, nil
+}
+
+// ListActions 获取申请的全部审批操作（按时间排序）
+func (d *adjustmentDAO) ListActions(ctx context.Context, requestID uint64) ([]*entity.AdjustmentActions, error) {
+	var actions []*entity.AdjustmentActions
+	err := g.Model("adjustment_actions").Ctx(ctx).
+		Where("request_id = ?", requestID).
+		OrderAsc("id").
+		Scan(&actions)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "查询调账审批操作失败: RequestID=%d", requestID)
+	}
+	return actions, nil
+}
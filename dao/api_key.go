@@ -0,0 +1,131 @@
+package dao
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+
+	"github.com/yalks/wallet/entity"
+)
+
+// IAPIKeyDAO API Key 与请求随机数数据访问接口
+type IAPIKeyDAO interface {
+	// Create 创建 API Key
+	Create(ctx context.Context, record *entity.ApiKeys) (uint64, error)
+	// GetByKeyID 根据 Key ID 获取 API Key，不存在时返回 nil
+	GetByKeyID(ctx context.Context, keyID string) (*entity.ApiKeys, error)
+	// List 获取 API Key 列表（按创建顺序），includeRevoked 为 false 时只返回有效的 Key
+	List(ctx context.Context, includeRevoked bool) ([]*entity.ApiKeys, error)
+	// Revoke 吊销 API Key，返回是否有记录被吊销
+	Revoke(ctx context.Context, keyID string) (bool, error)
+	// TouchLastUsed 更新最后使用时间
+	TouchLastUsed(ctx context.Context, keyID string, usedAt *gtime.Time) error
+	// UseNonce 记录请求随机数，同一 Key 的随机数已存在时返回 false
+	UseNonce(ctx context.Context, keyID, nonce string, usedAt *gtime.Time) (bool, error)
+	// PurgeNoncesBefore 删除早于指定时间的随机数，返回删除数量
+	PurgeNoncesBefore(ctx context.Context, before *gtime.Time) (int64, error)
+}
+
+type apiKeyDAO struct{}
+
+// NewAPIKeyDAO 创建 API Key DAO 实例
+func NewAPIKeyDAO() IAPIKeyDAO {
+	return &apiKeyDAO{}
+}
+
+// Create 创建 API Key
+func (d *apiKeyDAO) Create(ctx context.Context, record *entity.ApiKeys) (uint64, error) {
+	result, err := g.Model("api_keys").Ctx(ctx).Insert(record)
+	if err != nil {
+		return 0, gerror.Wrapf(err, "创建API Key失败: Name=%s", record.Name)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, gerror.Wrap(err, "获取API Key ID失败")
+	}
+	return uint64(id), nil
+}
+
+// GetByKeyID 根据 Key ID 获取 API Key，不存在时返回 nil
+func (d *apiKeyDAO) GetByKeyID(ctx context.Context, keyID string) (*entity.ApiKeys, error) {
+	var record *entity.ApiKeys
+	err := g.Model("api_keys").Ctx(ctx).Where("key_id = ?", keyID).Scan(&record)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "查询API Key失败: KeyID=%s", keyID)
+	}
+	return record, nil
+}
+
+// List 获取 API Key 列表（按创建顺序），includeRevoked 为 false 时只返回有效的 Key
+func (d *apiKeyDAO) List(ctx context.Context, includeRevoked bool) ([]*entity.ApiKeys, error) {
+	model := g.Model("api_keys").Ctx(ctx)
+	if !includeRevoked {
+		model = model.Where("status = ?", 1)
+	}
+	var records []*entity.ApiKeys
+	if err := model.OrderAsc("id").Scan(&records); err != nil {
+		return nil, gerror.Wrap(err, "查询API Key列表失败")
+	}
+	return records, nil
+}
+
+// Revoke 吊销 API Key，返回是否有记录被吊销
+func (d *apiKeyDAO) Revoke(ctx context.Context, keyID string) (bool, error) {
+	result, err := g.Model("api_keys").Ctx(ctx).
+		Where("key_id = ? AND status = ?", keyID, 1).
+		Update(map[string]any{
+			"status":     0,
+			"revoked_at": gtime.Now(),
+		})
+	if err != nil {
+		return false, gerror.Wrapf(err, "吊销API Key失败: KeyID=%s", keyID)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, gerror.Wrap(err, "获取影响行数失败")
+	}
+	return affected > 0, nil
+}
+
+// TouchLastUsed 更新最后使用时间
+func (d *apiKeyDAO) TouchLastUsed(ctx context.Context, keyID string, usedAt *gtime.Time) error {
+	_, err := g.Model("api_keys").Ctx(ctx).
+		Where("key_id = ?", keyID).
+		Update(map[string]any{"last_used_at": usedAt})
+	if err != nil {
+		return gerror.Wrapf(err, "更新API Key使用时间失败: KeyID=%s", keyID)
+	}
+	return nil
+}
+
+// UseNonce 记录请求随机数，依赖 (key_id, nonce) 唯一约束；同一 Key 的随机数已存在时返回 false
+func (d *apiKeyDAO) UseNonce(ctx context.Context, keyID, nonce string, usedAt *gtime.Time) (bool, error) {
+	result, err := g.Model("api_key_nonces").Ctx(ctx).InsertIgnore(&entity.ApiKeyNonces{
+		KeyId:     keyID,
+		Nonce:     nonce,
+		CreatedAt: usedAt,
+	})
+	if err != nil {
+		return false, gerror.Wrapf(err, "记录请求随机数失败: KeyID=%s", keyID)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, gerror.Wrap(err, "获取影响行数失败")
+	}
+	return affected > 0, nil
+}
+
+// PurgeNoncesBefore 删除早于指定时间的随机数，返回删除数量
+func (d *apiKeyDAO) PurgeNoncesBefore(ctx context.Context, before *gtime.Time) (int64, error) {
+	result, err := g.Model("api_key_nonces").Ctx(ctx).Where("created_at < ?", before).Delete()
+	if err != nil {
+		return 0, gerror.Wrap(err, "清理请求随机数失败")
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, gerror.Wrap(err, "获取影响行数失败")
+	}
+	return affected, nil
+}
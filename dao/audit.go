@@ -0,0 +1,185 @@
+package dao
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+
+	"github.com/yalks/wallet/entity"
+)
+
+// IAuditDAO 审计哈希链数据访问接口（audit_entries 只追加，不提供更新和删除）
+type IAuditDAO interface {
+	// GetChainHead 获取并锁定哈希链的最后一条记录，链为空时返回 nil
+	GetChainHead(ctx context.Context, tx gdb.TX, chainID string) (*entity.AuditEntries, error)
+	// CreateEntry 追加审计记录（与业务操作在同一事务中）
+	CreateEntry(ctx context.Context, tx gdb.TX, entry *entity.AuditEntries) error
+	// ListChainEntries 按序号获取哈希链中 afterSequence 之后的记录
+	ListChainEntries(ctx context.Context, chainID string, afterSequence uint64, limit int) ([]*entity.AuditEntries, error)
+	// ListEntriesAfterID 按ID顺序获取 afterID 之后的审计记录（用于生成检查点）
+	ListEntriesAfterID(ctx context.Context, afterID uint64, limit int) ([]*entity.AuditEntries, error)
+	// GetTransaction 在事务中读取刚写入的交易记录（以数据库实际存储的值计算哈希）
+	GetTransaction(ctx context.Context, tx gdb.TX, transactionID uint64) (*entity.Transactions, error)
+	// GetTransactionsByIDs 批量获取交易记录（用于校验被审计内容）
+	GetTransactionsByIDs(ctx context.Context, ids []uint64) ([]*entity.Transactions, error)
+	// CountTransactionsBetween 统计钱包中ID在 [fromID, toID] 区间的交易数量（用于发现未进入哈希链的交易）
+	CountTransactionsBetween(ctx context.Context, userID uint64, symbol string, fromID, toID uint64) (int, error)
+
+	// GetLastCheckpoint 获取最新的检查点，不存在时返回 nil
+	GetLastCheckpoint(ctx context.Context) (*entity.AuditCheckpoints, error)
+	// CreateCheckpoint 写入检查点
+	CreateCheckpoint(ctx context.Context, checkpoint *entity.AuditCheckpoints) (uint64, error)
+	// ListCheckpoints 按ID顺序获取检查点
+	ListCheckpoints(ctx context.Context, afterID uint64, limit int) ([]*entity.AuditCheckpoints, error)
+}
+
+type auditDAO struct{}
+
+// NewAuditDAO 创建审计DAO实例
+func NewAuditDAO() IAuditDAO {
+	return &auditDAO{}
+}
+
+// model 获取 audit_entries 模型，tx 不为空时在事务中执行
+func (d *auditDAO) model(ctx context.Context, tx gdb.TX) *gdb.Model {
+	if tx != nil {
+		return g.Model("audit_entries").Ctx(ctx).TX(tx)
+	}
+	return g.Model("audit_entries").Ctx(ctx)
+}
+
+// GetChainHead 获取并锁定哈希链的最后一条记录，链为空时返回 nil；
+// 锁定读会同时锁住链头之后的间隙，同一条链的并发追加在此排队，而不是读到相同的链头后在 (chain_id, sequence) 唯一键上冲突
+func (d *auditDAO) GetChainHead(ctx context.Context, tx gdb.TX, chainID string) (*entity.AuditEntries, error) {
+	var entry *entity.AuditEntries
+	err := lockForUpdate(d.model(ctx, tx)).
+		Where("chain_id = ?", chainID).
+		OrderDesc("sequence").
+		Limit(1).
+		Scan(&entry)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "查询审计链失败: ChainID=%s", chainID)
+	}
+	return entry, nil
+}
+
+// CreateEntry 追加审计记录（与业务操作在同一事务中）
+func (d *auditDAO) CreateEntry(ctx context.Context, tx gdb.TX, entry *entity.AuditEntries) error {
+	if _, err := d.model(ctx, tx).Insert(entry); err != nil {
+		return gerror.Wrapf(err, "写入审计记录失败: ChainID=%s, Sequence=%d", entry.ChainId, entry.Sequence)
+	}
+	return nil
+}
+
+// ListChainEntries 按序号获取哈希链中 afterSequence 之后的记录
+func (d *auditDAO) ListChainEntries(ctx context.Context, chainID string, afterSequence uint64, limit int) ([]*entity.AuditEntries, error) {
+	var entries []*entity.AuditEntries
+	err := g.Model("audit_entries").Ctx(ctx).
+		Where("chain_id = ? AND sequence > ?", chainID, afterSequence).
+		OrderAsc("sequence").
+		Limit(limit).
+		Scan(&entries)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "查询审计链失败: ChainID=%s", chainID)
+	}
+	return entries, nil
+}
+
+// ListEntriesAfterID 按ID顺序获取 afterID 之后的审计记录（用于生成检查点）
+func (d *auditDAO) ListEntriesAfterID(ctx context.Context, afterID uint64, limit int) ([]*entity.AuditEntries, error) {
+	var entries []*entity.AuditEntries
+	err := g.Model("audit_entries").Ctx(ctx).
+		Where("id > ?", afterID).
+		OrderAsc("id").
+		Limit(limit).
+		Scan(&entries)
+	if err != nil {
+		return nil, gerror.Wrap(err, "查询审计记录失败")
+	}
+	return entries, nil
+}
+
+// GetTransaction 在事务中读取刚写入的交易记录（以数据库实际存储的值计算哈希）
+func (d *auditDAO) GetTransaction(ctx context.Context, tx gdb.TX, transactionID uint64) (*entity.Transactions, error) {
+	model := g.Model("transactions").Ctx(ctx)
+	if tx != nil {
+		model = model.TX(tx)
+	}
+	var transaction *entity.Transactions
+	if err := model.Where("transaction_id = ?", transactionID).Scan(&transaction); err != nil {
+		return nil, gerror.Wrapf(err, "查询交易记录失败: TransactionID=%d", transactionID)
+	}
+	if transaction == nil {
+		return nil, gerror.Newf("交易记录不存在: TransactionID=%d", transactionID)
+	}
+	return transaction, nil
+}
+
+// GetTransactionsByIDs 批量获取交易记录（用于校验被审计内容）
+func (d *auditDAO) GetTransactionsByIDs(ctx context.Context, ids []uint64) ([]*entity.Transactions, error) {
+	var transactions []*entity.Transactions
+	if len(ids) == 0 {
+		return transactions, nil
+	}
+	// 软删除的记录同样需要校验，因此不使用软删除过滤
+	err := g.Model("transactions").Ctx(ctx).Unscoped().
+		WhereIn("transaction_id", ids).
+		Scan(&transactions)
+	if err != nil {
+		return nil, gerror.Wrap(err, "查询交易记录失败")
+	}
+	return transactions, nil
+}
+
+// CountTransactionsBetween 统计钱包中ID在 [fromID, toID] 区间的交易数量（用于发现未进入哈希链的交易）
+func (d *auditDAO) CountTransactionsBetween(ctx context.Context, userID uint64, symbol string, fromID, toID uint64) (int, error) {
+	count, err := g.Model("transactions").Ctx(ctx).Unscoped().
+		Where("user_id = ? AND symbol = ? AND transaction_id BETWEEN ? AND ?", userID, symbol, fromID, toID).
+		Count()
+	if err != nil {
+		return 0, gerror.Wrapf(err, "统计交易数量失败: UserID=%d, Symbol=%s", userID, symbol)
+	}
+	return count, nil
+}
+
+// GetLastCheckpoint 获取最新的检查点，不存在时返回 nil
+func (d *auditDAO) GetLastCheckpoint(ctx context.Context) (*entity.AuditCheckpoints, error) {
+	var checkpoint *entity.AuditCheckpoints
+	err := g.Model("audit_checkpoints").Ctx(ctx).
+		OrderDesc("id").
+		Limit(1).
+		Scan(&checkpoint)
+	if err != nil {
+		return nil, gerror.Wrap(err, "查询审计检查点失败")
+	}
+	return checkpoint, nil
+}
+
+// CreateCheckpoint 写入检查点
+func (d *auditDAO) CreateCheckpoint(ctx context.Context, checkpoint *entity.AuditCheckpoints) (uint64, error) {
+	result, err := g.Model("audit_checkpoints").Ctx(ctx).Insert(checkpoint)
+	if err != nil {
+		return 0, gerror.Wrap(err, "写入审计检查点失败")
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, gerror.Wrap(err, "获取审计检查点ID失败")
+	}
+	return uint64(id), nil
+}
+
+// ListCheckpoints 按ID顺序获取检查点
+func (d *auditDAO) ListCheckpoints(ctx context.Context, afterID uint64, limit int) ([]*entity.AuditCheckpoints, error) {
+	var checkpoints []*entity.AuditCheckpoints
+	err := g.Model("audit_checkpoints").Ctx(ctx).
+		Where("id > ?", afterID).
+		OrderAsc("id").
+		Limit(limit).
+		Scan(&checkpoints)
+	if err != nil {
+		return nil, gerror.Wrap(err, "查询审计检查点失败")
+	}
+	return checkpoints, nil
+}
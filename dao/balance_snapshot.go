@@ -0,0 +1,99 @@
+package dao
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+
+	"github.com/yalks/wallet/entity"
+)
+
+// snapshotDateLayout 快照日期格式
+const snapshotDateLayout = "Y-m-d"
+
+// IBalanceSnapshotDAO 余额快照数据访问接口
+type IBalanceSnapshotDAO interface {
+	// SaveSnapshot 写入快照，同一用户、代币、日期的快照已存在时覆盖
+	SaveSnapshot(ctx context.Context, snapshot *entity.BalanceSnapshots) error
+	// GetLatestSnapshot 获取指定日期（含）之前最近的快照，不存在时返回 nil
+	GetLatestSnapshot(ctx context.Context, userID uint64, symbol string, onOrBefore *gtime.Time) (*entity.BalanceSnapshots, error)
+	// ListSnapshotsByDate 获取某日的全部快照（symbol 为空时不限代币），按用户ID排序
+	ListSnapshotsByDate(ctx context.Context, date *gtime.Time, symbol string, limit, offset int) ([]*entity.BalanceSnapshots, error)
+}
+
+type balanceSnapshotDAO struct{}
+
+// NewBalanceSnapshotDAO 创建余额快照DAO实例
+func NewBalanceSnapshotDAO() IBalanceSnapshotDAO {
+	return &balanceSnapshotDAO{}
+}
+
+// SaveSnapshot 写入快照，同一用户、代币、日期的快照已存在时覆盖
+func (d *balanceSnapshotDAO) SaveSnapshot(ctx context.Context, snapshot *entity.BalanceSnapshots) error {
+	date := snapshot.SnapshotDate.Format(snapshotDateLayout)
+	now := gtime.Now()
+	model := g.Model("balance_snapshots").Ctx(ctx).
+		Where("user_id = ? AND symbol = ? AND snapshot_date = ?", snapshot.UserId, snapshot.Symbol, date)
+
+	count, err := model.Count()
+	if err != nil {
+		return gerror.Wrapf(err, "查询余额快照失败: UserID=%d, Symbol=%s, Date=%s", snapshot.UserId, snapshot.Symbol, date)
+	}
+	if count > 0 {
+		_, err = model.Update(map[string]any{
+			"balance":             snapshot.Balance,
+			"last_transaction_id": snapshot.LastTransactionId,
+			"updated_at":          now,
+		})
+		if err != nil {
+			return gerror.Wrapf(err, "更新余额快照失败: UserID=%d, Symbol=%s, Date=%s", snapshot.UserId, snapshot.Symbol, date)
+		}
+		return nil
+	}
+
+	snapshot.CreatedAt = now
+	snapshot.UpdatedAt = now
+	if _, err := g.Model("balance_snapshots").Ctx(ctx).Insert(snapshot); err != nil {
+		return gerror.Wrapf(err, "写入余额快照失败: UserID=%d, Symbol=%s, Date=%s", snapshot.UserId, snapshot.Symbol, date)
+	}
+	return nil
+}
+
+// GetLatestSnapshot 获取指定日期（含）之前最近的快照，不存在时返回 nil
+func (d *balanceSnapshotDAO) GetLatestSnapshot(ctx context.Context, userID uint64, symbol string, onOrBefore *gtime.Time) (*entity.BalanceSnapshots, error) {
+	var snapshot *entity.BalanceSnapshots
+	err := g.Model("balance_snapshots").Ctx(ctx).
+		Where("user_id = ? AND symbol = ? AND snapshot_date <= ?", userID, symbol, onOrBefore.Format(snapshotDateLayout)).
+		OrderDesc("snapshot_date").
+		Limit(1).
+		Scan(&snapshot)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "查询余额快照失败: UserID=%d, Symbol=%s", userID, symbol)
+	}
+	return snapshot, nil
+}
+
+// ListSnapshotsByDate 获取某日的全部快照（symbol 为空时不限代币），按用户ID排序
+func (d *balanceSnapshotDAO) ListSnapshotsByDate(ctx context.Context, date *gtime.Time, symbol string, limit, offset int) ([]*entity.BalanceSnapshots, error) {
+	model := g.Model("balance_snapshots").Ctx(ctx).
+		Where("snapshot_date = ?", date.Format(snapshotDateLayout)).
+		OrderAsc("user_id").
+		OrderAsc("symbol")
+	if symbol != "" {
+		model = model.Where("symbol = ?", symbol)
+	}
+	if limit > 0 {
+		model = model.Limit(limit)
+	}
+	if offset > 0 {
+		model = model.Offset(offset)
+	}
+
+	var snapshots []*entity.BalanceSnapshots
+	if err := model.Scan(&snapshots); err != nil {
+		return nil, gerror.Wrapf(err, "查询余额快照失败: Date=%s", date.Format(snapshotDateLayout))
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,57 @@
+package dao
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+
+	"github.com/yalks/wallet/entity"
+)
+
+// IBatchTransferDAO 批量转账数据访问接口
+type IBatchTransferDAO interface {
+	// GetBatchTransferByBatchID 通过批次ID获取批量转账记录
+	GetBatchTransferByBatchID(ctx context.Context, tx gdb.TX, batchID string) (*entity.BatchTransfers, error)
+	// CreateBatchTransfer 创建批量转账记录
+	CreateBatchTransfer(ctx context.Context, tx gdb.TX, record *entity.BatchTransfers) error
+}
+
+type batchTransferDAO struct{}
+
+// NewBatchTransferDAO 创建批量转账DAO实例
+func NewBatchTransferDAO() IBatchTransferDAO {
+	return &batchTransferDAO{}
+}
+
+// GetBatchTransferByBatchID 通过批次ID获取批量转账记录
+func (d *batchTransferDAO) GetBatchTransferByBatchID(ctx context.Context, tx gdb.TX, batchID string) (*entity.BatchTransfers, error) {
+	var db *gdb.Model
+	if tx != nil {
+		db = g.Model("batch_transfers").Ctx(ctx).TX(tx)
+	} else {
+		db = g.Model("batch_transfers").Ctx(ctx)
+	}
+
+	var record *entity.BatchTransfers
+	if err := db.Where("batch_id = ?", batchID).Scan(&record); err != nil {
+		return nil, gerror.Wrapf(err, "查询批量转账记录失败: BatchID=%s", batchID)
+	}
+	return record, nil
+}
+
+// CreateBatchTransfer 创建批量转账记录
+func (d *batchTransferDAO) CreateBatchTransfer(ctx context.Context, tx gdb.TX, record *entity.BatchTransfers) error {
+	var db *gdb.Model
+	if tx != nil {
+		db = g.Model("batch_transfers").Ctx(ctx).TX(tx)
+	} else {
+		db = g.Model("batch_transfers").Ctx(ctx)
+	}
+
+	if _, err := db.Insert(record); err != nil {
+		return gerror.Wrapf(err, "创建批量转账记录失败: BatchID=%s", record.BatchId)
+	}
+	return nil
+}
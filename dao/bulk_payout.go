@@ -0,0 +1,95 @@
+package dao
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+
+	"github.com/yalks/wallet/entity"
+)
+
+// IBulkPayoutDAO 批量发放数据访问接口
+type IBulkPayoutDAO interface {
+	// GetJob 通过任务ID获取批量发放任务
+	GetJob(ctx context.Context, jobID string) (*entity.BulkPayoutJobs, error)
+	// CreateJob 创建批量发放任务
+	CreateJob(ctx context.Context, job *entity.BulkPayoutJobs) error
+	// UpdateJob 更新批量发放任务
+	UpdateJob(ctx context.Context, tx gdb.TX, jobID string, data map[string]any) error
+	// CreateRow 记录单行执行结果
+	CreateRow(ctx context.Context, tx gdb.TX, row *entity.BulkPayoutRows) error
+	// ListRows 获取任务的全部行结果（按行号排序）
+	ListRows(ctx context.Context, jobID string) ([]*entity.BulkPayoutRows, error)
+}
+
+type bulkPayoutDAO struct{}
+
+// NewBulkPayoutDAO 创建批量发放DAO实例
+func NewBulkPayoutDAO() IBulkPayoutDAO {
+	return &bulkPayoutDAO{}
+}
+
+// GetJob 通过任务ID获取批量发放任务
+func (d *bulkPayoutDAO) GetJob(ctx context.Context, jobID string) (*entity.BulkPayoutJobs, error) {
+	var job *entity.BulkPayoutJobs
+	err := g.Model("bulk_payout_jobs").Ctx(ctx).Where("job_id = ?", jobID).Scan(&job)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "查询批量发放任务失败: JobID=%s", jobID)
+	}
+	return job, nil
+}
+
+// CreateJob 创建批量发放任务
+func (d *bulkPayoutDAO) CreateJob(ctx context.Context, job *entity.BulkPayoutJobs) error {
+	if _, err := g.Model("bulk_payout_jobs").Ctx(ctx).Insert(job); err != nil {
+		return gerror.Wrapf(err, "创建批量发放任务失败: JobID=%s", job.JobId)
+	}
+	return nil
+}
+
+// UpdateJob 更新批量发放任务
+func (d *bulkPayoutDAO) UpdateJob(ctx context.Context, tx gdb.TX, jobID string, data map[string]any) error {
+	var db *gdb.Model
+	if tx != nil {
+		db = g.Model("bulk_payout_jobs").Ctx(ctx).TX(tx)
+	} else {
+		db = g.Model("bulk_payout_jobs").Ctx(ctx)
+	}
+
+	data["updated_at"] = gtime.Now()
+	if _, err := db.Where("job_id = ?", jobID).Update(data); err != nil {
+		return gerror.Wrapf(err, "更新批量发放任务失败: JobID=%s", jobID)
+	}
+	return nil
+}
+
+// CreateRow 记录单行执行结果
+func (d *bulkPayoutDAO) CreateRow(ctx context.Context, tx gdb.TX, row *entity.BulkPayoutRows) error {
+	var db *gdb.Model
+	if tx != nil {
+		db = g.Model("bulk_payout_rows").Ctx(ctx).TX(tx)
+	} else {
+		db = g.Model("bulk_payout_rows").Ctx(ctx)
+	}
+
+	if _, err := db.Insert(row); err != nil {
+		return gerror.Wrapf(err, "记录批量发放行结果失败: JobID=%s, Line=%d", row.JobId, row.Line)
+	}
+	return nil
+}
+
+// ListRows 获取任务的全部行结果（按行号排序）
+func (d *bulkPayoutDAO) ListRows(ctx context.Context, jobID string) ([]*entity.BulkPayoutRows, error) {
+	var rows []*entity.BulkPayoutRows
+	err := g.Model("bulk_payout_rows").Ctx(ctx).
+		Where("job_id = ?", jobID).
+		OrderAsc("line").
+		Scan(&rows)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "查询批量发放行结果失败: JobID=%s", jobID)
+	}
+	return rows, nil
+}
@@ -0,0 +1,113 @@
+package dao
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/entity"
+)
+
+// ICommissionDAO 佣金记录数据访问接口
+type ICommissionDAO interface {
+	// CreateRecord 创建佣金记录
+	CreateRecord(ctx context.Context, tx gdb.TX, record *entity.CommissionRecords) error
+	// HasSourceRecords 检查源交易是否已分配过佣金
+	HasSourceRecords(ctx context.Context, tx gdb.TX, sourceTransactionID uint64) (bool, error)
+	// SumBeneficiaryAmountSince 统计受益人自指定时间起获得的佣金总额
+	SumBeneficiaryAmountSince(ctx context.Context, tx gdb.TX, beneficiaryID uint64, symbol string, since *gtime.Time) (decimal.Decimal, error)
+	// ListBySourceTransaction 获取源交易产生的全部佣金记录（按层级排序）
+	ListBySourceTransaction(ctx context.Context, sourceTransactionID uint64) ([]*entity.CommissionRecords, error)
+	// ListByBeneficiary 获取受益人的佣金记录（symbol 为空时不限代币，按时间倒序）
+	ListByBeneficiary(ctx context.Context, beneficiaryID uint64, symbol string, limit, offset int) ([]*entity.CommissionRecords, error)
+}
+
+type commissionDAO struct{}
+
+// NewCommissionDAO 创建佣金记录DAO实例
+func NewCommissionDAO() ICommissionDAO {
+	return &commissionDAO{}
+}
+
+// model 获取佣金记录表模型，tx 不为空时在事务中执行
+func (d *commissionDAO) model(ctx context.Context, tx gdb.TX) *gdb.Model {
+	if tx != nil {
+		return g.Model("commission_records").Ctx(ctx).TX(tx)
+	}
+	return g.Model("commission_records").Ctx(ctx)
+}
+
+// CreateRecord 创建佣金记录
+func (d *commissionDAO) CreateRecord(ctx context.Context, tx gdb.TX, record *entity.CommissionRecords) error {
+	result, err := d.model(ctx, tx).Insert(record)
+	if err != nil {
+		return gerror.Wrapf(err, "创建佣金记录失败: SourceTransactionID=%d, Level=%d", record.SourceTransactionId, record.Level)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return gerror.Wrap(err, "获取佣金记录ID失败")
+	}
+	record.Id = uint64(id)
+	return nil
+}
+
+// HasSourceRecords 检查源交易是否已分配过佣金
+func (d *commissionDAO) HasSourceRecords(ctx context.Context, tx gdb.TX, sourceTransactionID uint64) (bool, error) {
+	count, err := d.model(ctx, tx).Where("source_transaction_id = ?", sourceTransactionID).Count()
+	if err != nil {
+		return false, gerror.Wrapf(err, "查询佣金记录失败: SourceTransactionID=%d", sourceTransactionID)
+	}
+	return count > 0, nil
+}
+
+// SumBeneficiaryAmountSince 统计受益人自指定时间起获得的佣金总额
+func (d *commissionDAO) SumBeneficiaryAmountSince(ctx context.Context, tx gdb.TX, beneficiaryID uint64, symbol string, since *gtime.Time) (decimal.Decimal, error) {
+	value, err := d.model(ctx, tx).
+		Where("beneficiary_id = ? AND symbol = ? AND created_at >= ?", beneficiaryID, symbol, since).
+		Value("COALESCE(SUM(amount), 0)")
+	if err != nil {
+		return decimal.Zero, gerror.Wrapf(err, "统计佣金总额失败: BeneficiaryID=%d, Symbol=%s", beneficiaryID, symbol)
+	}
+	total, err := decimal.NewFromString(value.String())
+	if err != nil {
+		return decimal.Zero, gerror.Wrapf(err, "解析佣金总额失败: %s", value.String())
+	}
+	return total, nil
+}
+
+// ListBySourceTransaction 获取源交易产生的全部佣金记录（按层级排序）
+func (d *commissionDAO) ListBySourceTransaction(ctx context.Context, sourceTransactionID uint64) ([]*entity.CommissionRecords, error) {
+	var records []*entity.CommissionRecords
+	err := d.model(ctx, nil).
+		Where("source_transaction_id = ?", sourceTransactionID).
+		OrderAsc("level").
+		Scan(&records)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "查询佣金记录失败: SourceTransactionID=%d", sourceTransactionID)
+	}
+	return records, nil
+}
+
+// ListByBeneficiary 获取受益人的佣金记录（symbol 为空时不限代币，按时间倒序）
+func (d *commissionDAO) ListByBeneficiary(ctx context.Context, beneficiaryID uint64, symbol string, limit, offset int) ([]*entity.CommissionRecords, error) {
+	model := d.model(ctx, nil).Where("beneficiary_id = ?", beneficiaryID).OrderDesc("id")
+	if symbol != "" {
+		model = model.Where("symbol = ?", symbol)
+	}
+	if limit > 0 {
+		model = model.Limit(limit)
+	}
+	if offset > 0 {
+		model = model.Offset(offset)
+	}
+
+	var records []*entity.CommissionRecords
+	if err := model.Scan(&records); err != nil {
+		return nil, gerror.Wrapf(err, "查询佣金记录失败: BeneficiaryID=%d", beneficiaryID)
+	}
+	return records, nil
+}
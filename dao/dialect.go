@@ -0,0 +1,53 @@
+package dao
+
+import (
+	"strings"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// 需要按数据库区分写法的 SQL 方言
+const (
+	dialectMySQL  = "mysql"
+	dialectSQLite = "sqlite"
+)
+
+// sqlDialect 返回数据库类型（gdb 配置的 type）对应的 SQL 方言，不支持的类型返回空字符串
+func sqlDialect(dbType string) string {
+	switch strings.ToLower(dbType) {
+	case "mysql", "mariadb", "tidb":
+		return dialectMySQL
+	case "sqlite":
+		return dialectSQLite
+	default:
+		return ""
+	}
+}
+
+// currentDialect 获取当前数据库的 SQL 方言
+func currentDialect() string {
+	return sqlDialect(g.DB().GetConfig().Type)
+}
+
+// supportsRowLocks 方言是否支持行级锁定读 (FOR UPDATE / LOCK IN SHARE MODE)；
+// SQLite 没有行锁语法，同一时间只允许一个写事务，读到旧快照的事务在写入时会失败而不会覆盖新数据
+func supportsRowLocks(dialect string) bool {
+	return dialect != dialectSQLite
+}
+
+// lockForUpdate 对查询加排他锁，数据库不支持行锁时原样返回
+func lockForUpdate(model *gdb.Model) *gdb.Model {
+	if supportsRowLocks(currentDialect()) {
+		return model.LockUpdate()
+	}
+	return model
+}
+
+// lockShared 对查询加共享锁：锁定读返回最新已提交的数据而不是事务快照，数据库不支持行锁时原样返回
+func lockShared(model *gdb.Model) *gdb.Model {
+	if supportsRowLocks(currentDialect()) {
+		return model.LockShared()
+	}
+	return model
+}
@@ -0,0 +1,168 @@
+package dao
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+
+	"github.com/yalks/wallet/entity"
+)
+
+// IDomainEventDAO 领域事件出站表数据访问接口
+type IDomainEventDAO interface {
+	// ExistsEventKey 检查去重键是否已存在
+	ExistsEventKey(ctx context.Context, tx gdb.TX, eventKey string) (bool, error)
+	// NextSequence 锁定并获取聚合的下一个序号
+	NextSequence(ctx context.Context, tx gdb.TX, aggregateID string) (uint64, error)
+	// CreateEvent 写入领域事件（与业务操作在同一事务中）
+	CreateEvent(ctx context.Context, tx gdb.TX, event *entity.DomainEvents) (int64, error)
+	// ListPendingEvents 按事件ID顺序获取待发布事件（包含尚未到重试时间的事件，用于保持聚合内顺序）
+	ListPendingEvents(ctx context.Context, limit int) ([]*entity.DomainEvents, error)
+	// MarkPublished 标记事件已发布
+	MarkPublished(ctx context.Context, id int64) error
+	// MarkFailed 记录发布失败并设置下次发布时间
+	MarkFailed(ctx context.Context, id int64, attempts int, nextAttemptAt *gtime.Time, lastError string) error
+	// GetMaxEventID 获取当前最大的事件ID，没有事件时返回 0
+	GetMaxEventID(ctx context.Context) (int64, error)
+	// ListEventsAfter 按事件ID顺序获取 afterID 之后的事件（不区分发布状态）
+	ListEventsAfter(ctx context.Context, afterID int64, limit int) ([]*entity.DomainEvents, error)
+	// ListUserEventsBetween 按事件ID顺序获取用户在 (afterID, untilID] 范围内的事件
+	ListUserEventsBetween(ctx context.Context, userID uint64, afterID, untilID int64, limit int) ([]*entity.DomainEvents, error)
+}
+
+type domainEventDAO struct{}
+
+// NewDomainEventDAO 创建领域事件DAO实例
+func NewDomainEventDAO() IDomainEventDAO {
+	return &domainEventDAO{}
+}
+
+// model 获取 domain_events 模型，tx 不为空时在事务中执行
+func (d *domainEventDAO) model(ctx context.Context, tx gdb.TX) *gdb.Model {
+	if tx != nil {
+		return g.Model("domain_events").Ctx(ctx).TX(tx)
+	}
+	return g.Model("domain_events").Ctx(ctx)
+}
+
+// ExistsEventKey 检查去重键是否已存在
+func (d *domainEventDAO) ExistsEventKey(ctx context.Context, tx gdb.TX, eventKey string) (bool, error) {
+	count, err := d.model(ctx, tx).Where("event_key = ?", eventKey).Count()
+	if err != nil {
+		return false, gerror.Wrapf(err, "查询领域事件失败: EventKey=%s", eventKey)
+	}
+	return count > 0, nil
+}
+
+// NextSequence 获取聚合的下一个序号；锁定聚合的最后一条事件（及其后的间隙），
+// 同一聚合的并发写入在此排队，而不是读到相同的最大序号后在 (aggregate_id, sequence) 唯一键上冲突
+func (d *domainEventDAO) NextSequence(ctx context.Context, tx gdb.TX, aggregateID string) (uint64, error) {
+	value, err := lockForUpdate(d.model(ctx, tx)).
+		Fields("sequence").
+		Where("aggregate_id = ?", aggregateID).
+		OrderDesc("sequence").
+		Limit(1).
+		Value()
+	if err != nil {
+		return 0, gerror.Wrapf(err, "查询聚合事件序号失败: AggregateID=%s", aggregateID)
+	}
+	return value.Uint64() + 1, nil
+}
+
+// CreateEvent 写入领域事件（与业务操作在同一事务中）
+func (d *domainEventDAO) CreateEvent(ctx context.Context, tx gdb.TX, event *entity.DomainEvents) (int64, error) {
+	result, err := d.model(ctx, tx).Insert(event)
+	if err != nil {
+		return 0, gerror.Wrapf(err, "写入领域事件失败: Type=%s, AggregateID=%s, Sequence=%d", event.EventType, event.AggregateId, event.Sequence)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, gerror.Wrap(err, "获取领域事件ID失败")
+	}
+	return id, nil
+}
+
+// ListPendingEvents 按事件ID顺序获取待发布事件（包含尚未到重试时间的事件，用于保持聚合内顺序）
+func (d *domainEventDAO) ListPendingEvents(ctx context.Context, limit int) ([]*entity.DomainEvents, error) {
+	var events []*entity.DomainEvents
+	err := g.Model("domain_events").Ctx(ctx).
+		Where("status = ?", "pending").
+		OrderAsc("id").
+		Limit(limit).
+		Scan(&events)
+	if err != nil {
+		return nil, gerror.Wrap(err, "查询待发布领域事件失败")
+	}
+	return events, nil
+}
+
+// MarkPublished 标记事件已发布
+func (d *domainEventDAO) MarkPublished(ctx context.Context, id int64) error {
+	_, err := g.Model("domain_events").Ctx(ctx).
+		Where("id = ?", id).
+		Update(map[string]any{
+			"status":       "published",
+			"last_error":   "",
+			"published_at": gtime.Now(),
+		})
+	if err != nil {
+		return gerror.Wrapf(err, "标记领域事件已发布失败: ID=%d", id)
+	}
+	return nil
+}
+
+// MarkFailed 记录发布失败并设置下次发布时间
+func (d *domainEventDAO) MarkFailed(ctx context.Context, id int64, attempts int, nextAttemptAt *gtime.Time, lastError string) error {
+	_, err := g.Model("domain_events").Ctx(ctx).
+		Where("id = ?", id).
+		Update(map[string]any{
+			"attempts":        attempts,
+			"next_attempt_at": nextAttemptAt,
+			"last_error":      lastError,
+		})
+	if err != nil {
+		return gerror.Wrapf(err, "记录领域事件发布失败: ID=%d", id)
+	}
+	return nil
+}
+
+// GetMaxEventID 获取当前最大的事件ID，没有事件时返回 0
+func (d *domainEventDAO) GetMaxEventID(ctx context.Context) (int64, error) {
+	value, err := g.Model("domain_events").Ctx(ctx).Max("id")
+	if err != nil {
+		return 0, gerror.Wrap(err, "查询最大领域事件ID失败")
+	}
+	return int64(value), nil
+}
+
+// ListEventsAfter 按事件ID顺序获取 afterID 之后的事件（不区分发布状态）
+func (d *domainEventDAO) ListEventsAfter(ctx context.Context, afterID int64, limit int) ([]*entity.DomainEvents, error) {
+	var events []*entity.DomainEvents
+	err := g.Model("domain_events").Ctx(ctx).
+		Where("id > ?", afterID).
+		OrderAsc("id").
+		Limit(limit).
+		Scan(&events)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "查询领域事件失败: AfterID=%d", afterID)
+	}
+	return events, nil
+}
+
+// ListUserEventsBetween 按事件ID顺序获取用户在 (afterID, untilID] 范围内的事件
+func (d *domainEventDAO) ListUserEventsBetween(ctx context.Context, userID uint64, afterID, untilID int64, limit int) ([]*entity.DomainEvents, error) {
+	var events []*entity.DomainEvents
+	err := g.Model("domain_events").Ctx(ctx).
+		Where("user_id = ? AND id > ? AND id <= ?", userID, afterID, untilID).
+		OrderAsc("id").
+		Limit(limit).
+		Scan(&events)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "查询用户领域事件失败: UserID=%d, AfterID=%d", userID, afterID)
+	}
+	return events, nil
+}
@@ -0,0 +1,24 @@
+package dao
+
+import "strings"
+
+// duplicateKeyMessages 各数据库驱动违反唯一约束时的错误信息
+var duplicateKeyMessages = []string{
+	"Error 1062",               // MySQL / MariaDB / TiDB
+	"Duplicate entry",          // MySQL / MariaDB / TiDB
+	"UNIQUE constraint failed", // SQLite
+}
+
+// IsDuplicateKeyError 判断错误链中是否有违反唯一约束的数据库错误
+func IsDuplicateKeyError(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := err.Error()
+	for _, duplicate := range duplicateKeyMessages {
+		if strings.Contains(message, duplicate) {
+			return true
+		}
+	}
+	return false
+}
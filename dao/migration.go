@@ -0,0 +1,159 @@
+package dao
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+
+	"github.com/yalks/wallet/entity"
+)
+
+// migrationTableDDL 迁移版本表，按迁移脚本目录区分数据库类型
+var migrationTableDDL = map[string]string{
+	"mysql": "CREATE TABLE IF NOT EXISTS `schema_migrations` (" +
+		"`version` INT NOT NULL COMMENT '迁移版本号 (主键)', " +
+		"`name` VARCHAR(255) NOT NULL DEFAULT '' COMMENT '迁移名称', " +
+		"`checksum` VARCHAR(64) NOT NULL DEFAULT '' COMMENT '迁移脚本 SHA-256', " +
+		"`applied_at` DATETIME NULL DEFAULT NULL COMMENT '执行时间', " +
+		"PRIMARY KEY (`version`)" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='数据库迁移版本'",
+	"sqlite": "CREATE TABLE IF NOT EXISTS schema_migrations (" +
+		"version INTEGER PRIMARY KEY, " +
+		"name TEXT NOT NULL DEFAULT '', " +
+		"checksum TEXT NOT NULL DEFAULT '', " +
+		"applied_at DATETIME NULL DEFAULT NULL)",
+}
+
+// IMigrationDAO 数据库迁移与结构检查数据访问接口
+type IMigrationDAO interface {
+	// GetDBType 获取当前数据库类型（gdb 配置的 type）
+	GetDBType(ctx context.Context) string
+	// EnsureMigrationTable 创建迁移版本表（已存在时跳过）
+	EnsureMigrationTable(ctx context.Context, dialect string) error
+	// ListApplied 获取已执行的迁移（按版本号升序），迁移版本表不存在时返回空列表
+	ListApplied(ctx context.Context) ([]*entity.SchemaMigrations, error)
+	// Apply 在同一事务中执行迁移语句并记录版本。
+	// MySQL 的 DDL 会隐式提交，失败时已执行的语句不会回滚，迁移脚本需可重复执行
+	Apply(ctx context.Context, version int, name, checksum string, statements []string) error
+	// GetTableColumns 获取指定表的列名（表名 -> 列名集合，均为小写），不存在的表不在结果中
+	GetTableColumns(ctx context.Context, tables []string) (map[string]map[string]bool, error)
+}
+
+type migrationDAO struct{}
+
+// NewMigrationDAO 创建迁移DAO实例
+func NewMigrationDAO() IMigrationDAO {
+	return &migrationDAO{}
+}
+
+// GetDBType 获取当前数据库类型（gdb 配置的 type）
+func (d *migrationDAO) GetDBType(ctx context.Context) string {
+	return g.DB().GetConfig().Type
+}
+
+// EnsureMigrationTable 创建迁移版本表（已存在时跳过）
+func (d *migrationDAO) EnsureMigrationTable(ctx context.Context, dialect string) error {
+	ddl, ok := migrationTableDDL[dialect]
+	if !ok {
+		return gerror.Newf("不支持的迁移数据库类型: %s", dialect)
+	}
+	if _, err := g.DB().Exec(ctx, ddl); err != nil {
+		return gerror.Wrap(err, "创建迁移版本表失败")
+	}
+	return nil
+}
+
+// ListApplied 获取已执行的迁移（按版本号升序），迁移版本表不存在时返回空列表
+func (d *migrationDAO) ListApplied(ctx context.Context) ([]*entity.SchemaMigrations, error) {
+	tables, err := g.DB().Tables(ctx)
+	if err != nil {
+		return nil, gerror.Wrap(err, "查询数据库表失败")
+	}
+	found := false
+	for _, table := range tables {
+		if strings.EqualFold(table, "schema_migrations") {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+
+	var applied []*entity.SchemaMigrations
+	if err := g.Model("schema_migrations").Ctx(ctx).OrderAsc("version").Scan(&applied); err != nil {
+		return nil, gerror.Wrap(err, "查询已执行的迁移失败")
+	}
+	return applied, nil
+}
+
+// Apply 在同一事务中执行迁移语句并记录版本
+func (d *migrationDAO) Apply(ctx context.Context, version int, name, checksum string, statements []string) error {
+	err := g.DB().Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		for _, statement := range statements {
+			if _, err := tx.Exec(statement); err != nil {
+				return gerror.Wrapf(err, "执行迁移语句失败: %s", firstLine(statement))
+			}
+		}
+		_, err := tx.Model("schema_migrations").Ctx(ctx).Insert(&entity.SchemaMigrations{
+			Version:   version,
+			Name:      name,
+			Checksum:  checksum,
+			AppliedAt: gtime.Now(),
+		})
+		return err
+	})
+	if err != nil {
+		return gerror.Wrapf(err, "执行迁移失败: Version=%d, Name=%s", version, name)
+	}
+	// 表结构已变化，清除 gdb 缓存的字段信息
+	if err := g.DB().GetCore().ClearTableFieldsAll(ctx); err != nil {
+		g.Log().Warningf(ctx, "清除表字段缓存失败: %v", err)
+	}
+	return nil
+}
+
+// GetTableColumns 获取指定表的列名（表名 -> 列名集合，均为小写），不存在的表不在结果中
+func (d *migrationDAO) GetTableColumns(ctx context.Context, tables []string) (map[string]map[string]bool, error) {
+	existing, err := g.DB().Tables(ctx)
+	if err != nil {
+		return nil, gerror.Wrap(err, "查询数据库表失败")
+	}
+	exists := make(map[string]string, len(existing))
+	for _, table := range existing {
+		exists[strings.ToLower(table)] = table
+	}
+
+	result := make(map[string]map[string]bool)
+	for _, table := range tables {
+		name, ok := exists[strings.ToLower(table)]
+		if !ok {
+			continue
+		}
+		if err := g.DB().GetCore().ClearTableFields(ctx, name); err != nil {
+			return nil, gerror.Wrapf(err, "清除表字段缓存失败: %s", name)
+		}
+		fields, err := g.DB().TableFields(ctx, name)
+		if err != nil {
+			return nil, gerror.Wrapf(err, "查询表字段失败: %s", name)
+		}
+		columns := make(map[string]bool, len(fields))
+		for field := range fields {
+			columns[strings.ToLower(field)] = true
+		}
+		result[strings.ToLower(table)] = columns
+	}
+	return result, nil
+}
+
+// firstLine 返回语句的第一行，用于错误信息
+func firstLine(statement string) string {
+	if i := strings.IndexByte(statement, '\n'); i >= 0 {
+		return strings.TrimSpace(statement[:i])
+	}
+	return statement
+}
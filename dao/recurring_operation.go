@@ -0,0 +1,199 @@
+package dao
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+
+	"github.com/yalks/wallet/entity"
+)
+
+// IRecurringOperationDAO 循环操作数据访问接口
+type IRecurringOperationDAO interface {
+	// CreateRecurringOperation 创建循环操作记录
+	CreateRecurringOperation(ctx context.Context, tx gdb.TX, op *entity.RecurringOperations) (uint64, error)
+	// GetRecurringOperationByID 通过ID获取循环操作
+	GetRecurringOperationByID(ctx context.Context, id uint64) (*entity.RecurringOperations, error)
+	// GetRecurringOperationByBusinessID 通过基础业务ID获取循环操作
+	GetRecurringOperationByBusinessID(ctx context.Context, businessID string) (*entity.RecurringOperations, error)
+	// ListDueRecurringOperations 获取已到期的活跃循环操作
+	ListDueRecurringOperations(ctx context.Context, now *gtime.Time, limit int) ([]*entity.RecurringOperations, error)
+	// ListRecurringOperations 获取用户循环操作列表（status 为空时返回全部状态）
+	ListRecurringOperations(ctx context.Context, userID uint64, status string, limit, offset int) ([]*entity.RecurringOperations, error)
+	// AdvanceRecurringOperation 仅当状态为 active 且执行次数未变化时推进循环操作，返回是否更新成功
+	AdvanceRecurringOperation(ctx context.Context, tx gdb.TX, id uint64, expectedRunCount int, data map[string]any) (bool, error)
+	// UpdateRecurringOperationIfStatus 仅当当前状态匹配时更新，返回是否更新成功
+	UpdateRecurringOperationIfStatus(ctx context.Context, tx gdb.TX, id uint64, currentStatus string, data map[string]any) (bool, error)
+
+	// CreateRecurringRun 记录一次执行结果
+	CreateRecurringRun(ctx context.Context, tx gdb.TX, run *entity.RecurringRuns) error
+	// ListRecurringRuns 获取循环操作的执行历史（按执行次序倒序）
+	ListRecurringRuns(ctx context.Context, recurringID uint64, limit, offset int) ([]*entity.RecurringRuns, error)
+}
+
+type recurringOperationDAO struct{}
+
+// NewRecurringOperationDAO 创建循环操作DAO实例
+func NewRecurringOperationDAO() IRecurringOperationDAO {
+	return &recurringOperationDAO{}
+}
+
+// CreateRecurringOperation 创建循环操作记录
+func (d *recurringOperationDAO) CreateRecurringOperation(ctx context.Context, tx gdb.TX, op *entity.RecurringOperations) (uint64, error) {
+	var db *gdb.Model
+	if tx != nil {
+		db = g.Model("recurring_operations").Ctx(ctx).TX(tx)
+	} else {
+		db = g.Model("recurring_operations").Ctx(ctx)
+	}
+
+	result, err := db.Insert(op)
+	if err != nil {
+		return 0, gerror.Wrapf(err, "创建循环操作失败: UserID=%d, BusinessID=%s", op.UserId, op.BusinessId)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, gerror.Wrap(err, "获取循环操作ID失败")
+	}
+	return uint64(id), nil
+}
+
+// GetRecurringOperationByID 通过ID获取循环操作
+func (d *recurringOperationDAO) GetRecurringOperationByID(ctx context.Context, id uint64) (*entity.RecurringOperations, error) {
+	var op *entity.RecurringOperations
+	err := g.Model("recurring_operations").Ctx(ctx).Where("id = ?", id).Scan(&op)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "查询循环操作失败: ID=%d", id)
+	}
+	return op, nil
+}
+
+// GetRecurringOperationByBusinessID 通过基础业务ID获取循环操作
+func (d *recurringOperationDAO) GetRecurringOperationByBusinessID(ctx context.Context, businessID string) (*entity.RecurringOperations, error) {
+	var op *entity.RecurringOperations
+	err := g.Model("recurring_operations").Ctx(ctx).Where("business_id = ?", businessID).Scan(&op)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "查询循环操作失败: BusinessID=%s", businessID)
+	}
+	return op, nil
+}
+
+// ListDueRecurringOperations 获取已到期的活跃循环操作
+func (d *recurringOperationDAO) ListDueRecurringOperations(ctx context.Context, now *gtime.Time, limit int) ([]*entity.RecurringOperations, error) {
+	var ops []*entity.RecurringOperations
+	err := g.Model("recurring_operations").Ctx(ctx).
+		Where("status = ? AND next_run_at <= ?", "active", now).
+		OrderDesc("priority").
+		OrderAsc("next_run_at").
+		OrderAsc("id").
+		Limit(limit).
+		Scan(&ops)
+	if err != nil {
+		return nil, gerror.Wrap(err, "查询到期循环操作失败")
+	}
+	return ops, nil
+}
+
+// ListRecurringOperations 获取用户循环操作列表（status 为空时返回全部状态）
+func (d *recurringOperationDAO) ListRecurringOperations(ctx context.Context, userID uint64, status string, limit, offset int) ([]*entity.RecurringOperations, error) {
+	model := g.Model("recurring_operations").Ctx(ctx).
+		Where("user_id = ?", userID).
+		OrderDesc("id")
+	if status != "" {
+		model = model.Where("status = ?", status)
+	}
+	if limit > 0 {
+		model = model.Limit(limit)
+	}
+	if offset > 0 {
+		model = model.Offset(offset)
+	}
+
+	var ops []*entity.RecurringOperations
+	if err := model.Scan(&ops); err != nil {
+		return nil, gerror.Wrapf(err, "查询循环操作列表失败: UserID=%d", userID)
+	}
+	return ops, nil
+}
+
+// AdvanceRecurringOperation 仅当状态为 active 且执行次数未变化时推进循环操作，返回是否更新成功
+func (d *recurringOperationDAO) AdvanceRecurringOperation(ctx context.Context, tx gdb.TX, id uint64, expectedRunCount int, data map[string]any) (bool, error) {
+	var db *gdb.Model
+	if tx != nil {
+		db = g.Model("recurring_operations").Ctx(ctx).TX(tx)
+	} else {
+		db = g.Model("recurring_operations").Ctx(ctx)
+	}
+
+	data["updated_at"] = gtime.Now()
+	result, err := db.Where("id = ? AND status = ? AND run_count = ?", id, "active", expectedRunCount).Update(data)
+	if err != nil {
+		return false, gerror.Wrapf(err, "推进循环操作失败: ID=%d", id)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, gerror.Wrap(err, "获取影响行数失败")
+	}
+	return affected == 1, nil
+}
+
+// UpdateRecurringOperationIfStatus 仅当当前状态匹配时更新，返回是否更新成功
+func (d *recurringOperationDAO) UpdateRecurringOperationIfStatus(ctx context.Context, tx gdb.TX, id uint64, currentStatus string, data map[string]any) (bool, error) {
+	var db *gdb.Model
+	if tx != nil {
+		db = g.Model("recurring_operations").Ctx(ctx).TX(tx)
+	} else {
+		db = g.Model("recurring_operations").Ctx(ctx)
+	}
+
+	data["updated_at"] = gtime.Now()
+	result, err := db.Where("id = ? AND status = ?", id, currentStatus).Update(data)
+	if err != nil {
+		return false, gerror.Wrapf(err, "更新循环操作状态失败: ID=%d", id)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, gerror.Wrap(err, "获取影响行数失败")
+	}
+	return affected == 1, nil
+}
+
+// CreateRecurringRun 记录一次执行结果
+func (d *recurringOperationDAO) CreateRecurringRun(ctx context.Context, tx gdb.TX, run *entity.RecurringRuns) error {
+	var db *gdb.Model
+	if tx != nil {
+		db = g.Model("recurring_runs").Ctx(ctx).TX(tx)
+	} else {
+		db = g.Model("recurring_runs").Ctx(ctx)
+	}
+
+	if _, err := db.Insert(run); err != nil {
+		return gerror.Wrapf(err, "记录循环操作执行结果失败: RecurringID=%d, Occurrence=%d", run.RecurringId, run.Occurrence)
+	}
+	return nil
+}
+
+// ListRecurringRuns 获取循环操作的执行历史（按执行次序倒序）
+func (d *recurringOperationDAO) ListRecurringRuns(ctx context.Context, recurringID uint64, limit, offset int) ([]*entity.RecurringRuns, error) {
+	model := g.Model("recurring_runs").Ctx(ctx).
+		Where("recurring_id = ?", recurringID).
+		OrderDesc("occurrence")
+	if limit > 0 {
+		model = model.Limit(limit)
+	}
+	if offset > 0 {
+		model = model.Offset(offset)
+	}
+
+	var runs []*entity.RecurringRuns
+	if err := model.Scan(&runs); err != nil {
+		return nil, gerror.Wrapf(err, "查询循环操作执行历史失败: RecurringID=%d", recurringID)
+	}
+	return runs, nil
+}
@@ -0,0 +1,189 @@
+package dao
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+
+	"github.com/yalks/wallet/entity"
+)
+
+// IScheduledOperationDAO 定时操作数据访问接口
+type IScheduledOperationDAO interface {
+	// CreateScheduledOperation 创建定时操作记录
+	CreateScheduledOperation(ctx context.Context, tx gdb.TX, op *entity.ScheduledOperations) (uint64, error)
+	// GetScheduledOperationByID 通过ID获取定时操作
+	GetScheduledOperationByID(ctx context.Context, id uint64) (*entity.ScheduledOperations, error)
+	// GetScheduledOperationByBusinessID 通过业务ID获取定时操作
+	GetScheduledOperationByBusinessID(ctx context.Context, businessID string) (*entity.ScheduledOperations, error)
+	// ListDueScheduledOperations 获取已到期待执行的定时操作
+	ListDueScheduledOperations(ctx context.Context, now *gtime.Time, limit int) ([]*entity.ScheduledOperations, error)
+	// ListScheduledOperations 获取用户定时操作列表（status 为空时返回全部状态）
+	ListScheduledOperations(ctx context.Context, userID uint64, status string, limit, offset int) ([]*entity.ScheduledOperations, error)
+	// ClaimScheduledOperation 抢占待执行的定时操作（pending -> processing）并记录抢占时间，返回是否抢占成功
+	ClaimScheduledOperation(ctx context.Context, id uint64, claimedAt *gtime.Time) (bool, error)
+	// ReclaimStaleScheduledOperations 将抢占时间早于 claimedBefore 仍在处理中的定时操作重新置为待执行，返回数量
+	ReclaimStaleScheduledOperations(ctx context.Context, claimedBefore *gtime.Time) (int64, error)
+	// UpdateScheduledOperation 更新定时操作字段
+	UpdateScheduledOperation(ctx context.Context, tx gdb.TX, id uint64, data map[string]any) error
+	// UpdateScheduledOperationIfStatus 仅当当前状态匹配时更新，返回是否更新成功
+	UpdateScheduledOperationIfStatus(ctx context.Context, tx gdb.TX, id uint64, currentStatus string, data map[string]any) (bool, error)
+}
+
+type scheduledOperationDAO struct{}
+
+// NewScheduledOperationDAO 创建定时操作DAO实例
+func NewScheduledOperationDAO() IScheduledOperationDAO {
+	return &scheduledOperationDAO{}
+}
+
+// CreateScheduledOperation 创建定时操作记录
+func (d *scheduledOperationDAO) CreateScheduledOperation(ctx context.Context, tx gdb.TX, op *entity.ScheduledOperations) (uint64, error) {
+	var db *gdb.Model
+	if tx != nil {
+		db = g.Model("scheduled_operations").Ctx(ctx).TX(tx)
+	} else {
+		db = g.Model("scheduled_operations").Ctx(ctx)
+	}
+
+	result, err := db.Insert(op)
+	if err != nil {
+		return 0, gerror.Wrapf(err, "创建定时操作失败: UserID=%d, BusinessID=%s", op.UserId, op.BusinessId)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, gerror.Wrap(err, "获取定时操作ID失败")
+	}
+	return uint64(id), nil
+}
+
+// GetScheduledOperationByID 通过ID获取定时操作
+func (d *scheduledOperationDAO) GetScheduledOperationByID(ctx context.Context, id uint64) (*entity.ScheduledOperations, error) {
+	var op *entity.ScheduledOperations
+	err := g.Model("scheduled_operations").Ctx(ctx).Where("id = ?", id).Scan(&op)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "查询定时操作失败: ID=%d", id)
+	}
+	return op, nil
+}
+
+// GetScheduledOperationByBusinessID 通过业务ID获取定时操作
+func (d *scheduledOperationDAO) GetScheduledOperationByBusinessID(ctx context.Context, businessID string) (*entity.ScheduledOperations, error) {
+	var op *entity.ScheduledOperations
+	err := g.Model("scheduled_operations").Ctx(ctx).Where("business_id = ?", businessID).Scan(&op)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "查询定时操作失败: BusinessID=%s", businessID)
+	}
+	return op, nil
+}
+
+// ListDueScheduledOperations 获取已到期待执行的定时操作
+func (d *scheduledOperationDAO) ListDueScheduledOperations(ctx context.Context, now *gtime.Time, limit int) ([]*entity.ScheduledOperations, error) {
+	var ops []*entity.ScheduledOperations
+	err := g.Model("scheduled_operations").Ctx(ctx).
+		Where("status = ? AND next_run_at <= ?", "pending", now).
+		OrderDesc("priority").
+		OrderAsc("next_run_at").
+		OrderAsc("id").
+		Limit(limit).
+		Scan(&ops)
+	if err != nil {
+		return nil, gerror.Wrap(err, "查询到期定时操作失败")
+	}
+	return ops, nil
+}
+
+// ListScheduledOperations 获取用户定时操作列表（status 为空时返回全部状态）
+func (d *scheduledOperationDAO) ListScheduledOperations(ctx context.Context, userID uint64, status string, limit, offset int) ([]*entity.ScheduledOperations, error) {
+	model := g.Model("scheduled_operations").Ctx(ctx).
+		Where("user_id = ?", userID).
+		OrderDesc("scheduled_at").
+		OrderDesc("id")
+	if status != "" {
+		model = model.Where("status = ?", status)
+	}
+	if limit > 0 {
+		model = model.Limit(limit)
+	}
+	if offset > 0 {
+		model = model.Offset(offset)
+	}
+
+	var ops []*entity.ScheduledOperations
+	if err := model.Scan(&ops); err != nil {
+		return nil, gerror.Wrapf(err, "查询定时操作列表失败: UserID=%d", userID)
+	}
+	return ops, nil
+}
+
+// ClaimScheduledOperation 抢占待执行的定时操作（pending -> processing）并记录抢占时间，返回是否抢占成功
+func (d *scheduledOperationDAO) ClaimScheduledOperation(ctx context.Context, id uint64, claimedAt *gtime.Time) (bool, error) {
+	return d.UpdateScheduledOperationIfStatus(ctx, nil, id, "pending", map[string]any{
+		"status":     "processing",
+		"claimed_at": claimedAt,
+	})
+}
+
+// ReclaimStaleScheduledOperations 将抢占时间早于 claimedBefore 仍在处理中的定时操作重新置为待执行，返回数量。
+// 没有抢占时间的处理中记录（租约引入之前抢占）同样视为已超时
+func (d *scheduledOperationDAO) ReclaimStaleScheduledOperations(ctx context.Context, claimedBefore *gtime.Time) (int64, error) {
+	result, err := g.Model("scheduled_operations").Ctx(ctx).
+		Where("status = ?", "processing").
+		Where("(claimed_at IS NULL OR claimed_at < ?)", claimedBefore).
+		Update(map[string]any{
+			"status":     "pending",
+			"claimed_at": nil,
+			"updated_at": gtime.Now(),
+		})
+	if err != nil {
+		return 0, gerror.Wrap(err, "重新排队超时的定时操作失败")
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, gerror.Wrap(err, "获取影响行数失败")
+	}
+	return affected, nil
+}
+
+// UpdateScheduledOperation 更新定时操作字段
+func (d *scheduledOperationDAO) UpdateScheduledOperation(ctx context.Context, tx gdb.TX, id uint64, data map[string]any) error {
+	var db *gdb.Model
+	if tx != nil {
+		db = g.Model("scheduled_operations").Ctx(ctx).TX(tx)
+	} else {
+		db = g.Model("scheduled_operations").Ctx(ctx)
+	}
+
+	data["updated_at"] = gtime.Now()
+	_, err := db.Where("id = ?", id).Update(data)
+	if err != nil {
+		return gerror.Wrapf(err, "更新定时操作失败: ID=%d", id)
+	}
+	return nil
+}
+
+// UpdateScheduledOperationIfStatus 仅当当前状态匹配时更新，返回是否更新成功
+func (d *scheduledOperationDAO) UpdateScheduledOperationIfStatus(ctx context.Context, tx gdb.TX, id uint64, currentStatus string, data map[string]any) (bool, error) {
+	var db *gdb.Model
+	if tx != nil {
+		db = g.Model("scheduled_operations").Ctx(ctx).TX(tx)
+	} else {
+		db = g.Model("scheduled_operations").Ctx(ctx)
+	}
+
+	data["updated_at"] = gtime.Now()
+	result, err := db.Where("id = ? AND status = ?", id, currentStatus).Update(data)
+	if err != nil {
+		return false, gerror.Wrapf(err, "更新定时操作状态失败: ID=%d", id)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, gerror.Wrap(err, "获取影响行数失败")
+	}
+	return affected == 1, nil
+}
@@ -7,18 +7,61 @@ import (
 	"github.com/gogf/gf/v2/errors/gerror"
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/shopspring/decimal"
 
 	"github.com/yalks/wallet/entity"
 )
 
 // ITransactionDAO 交易数据访问接口
 type ITransactionDAO interface {
-	// CreateTransaction 创建交易记录
+	// CreateTransaction 创建交易记录，没有幂等键时 idempotency_key 写入 NULL
 	CreateTransaction(ctx context.Context, tx gdb.TX, transaction *entity.Transactions) (int64, error)
 	// GetTransactionByBusinessID 通过业务ID获取交易
 	GetTransactionByBusinessID(ctx context.Context, businessID string) (*entity.Transactions, error)
+	// GetTransactionByIdempotencyKey 通过客户端幂等键获取交易，幂等键为空时返回 nil
+	GetTransactionByIdempotencyKey(ctx context.Context, idempotencyKey string) (*entity.Transactions, error)
 	// UpdateTransactionStatus 更新交易状态
 	UpdateTransactionStatus(ctx context.Context, tx gdb.TX, transactionID int64, status string) error
+	// UpdateRequestOptions 保存增强版请求的幂等键、优先级和过期时间
+	UpdateRequestOptions(ctx context.Context, tx gdb.TX, transactionID uint64, idempotencyKey string, priority int, expireAt *gtime.Time) error
+	// SearchTransactions 按条件查询交易，按 (created_at, transaction_id) 键集分页
+	SearchTransactions(ctx context.Context, filter *TransactionFilter, limit int) ([]*entity.Transactions, error)
+	// CountTransactions 统计满足条件的交易数量（忽略分页游标）
+	CountTransactions(ctx context.Context, filter *TransactionFilter) (int, error)
+	// GetLastTransactionBefore 获取用户某代币可用余额在指定时间之前的最后一笔成功交易，不存在时返回 nil
+	GetLastTransactionBefore(ctx context.Context, userID uint64, symbol string, before *gtime.Time) (*entity.Transactions, error)
+	// GetLastTransactionBetween 获取用户某代币可用余额在 [since, before) 内的最后一笔成功交易，since 为 nil 时不限下界，不存在时返回 nil
+	GetLastTransactionBetween(ctx context.Context, userID uint64, symbol string, since, before *gtime.Time) (*entity.Transactions, error)
+	// GetAmountStats 统计满足条件的交易数量和平均金额（忽略分页游标）
+	GetAmountStats(ctx context.Context, filter *TransactionFilter) (int, decimal.Decimal, error)
+}
+
+// TransactionFilter 交易查询条件，零值字段不参与过滤
+type TransactionFilter struct {
+	UserID            uint64
+	TokenID           uint
+	Symbol            string
+	Direction         string
+	WalletType        string
+	Types             []string // 交易类型（任一匹配）
+	Statuses          []uint   // 交易状态（任一匹配）
+	MinAmount         *decimal.Decimal
+	MaxAmount         *decimal.Decimal
+	StartTime         *gtime.Time // created_at >= StartTime
+	EndTime           *gtime.Time // created_at < EndTime
+	RelatedEntityID   uint64
+	RelatedEntityType string
+	TargetUserID      uint
+	RequestSource     string
+	RequestIP         string
+	Tags              []string          // 必须包含全部标签
+	MetadataKeys      []string          // 请求元数据必须包含的键
+	Metadata          map[string]string // 请求元数据键值必须相等
+
+	// 键集分页：返回排在 (AfterCreatedAt, AfterID) 之后的记录
+	AfterCreatedAt *gtime.Time
+	AfterID        uint64
+	Ascending      bool // 默认按 (created_at, transaction_id) 倒序
 }
 
 type transactionDAO struct{}
@@ -37,6 +80,10 @@ func (d *transactionDAO) CreateTransaction(ctx context.Context, tx gdb.TX, trans
 		db = g.Model("transactions").Ctx(ctx)
 	}
 
+	// 没有幂等键时写入 NULL，避免空字符串违反 idempotency_key 唯一约束
+	if transaction.IdempotencyKey == "" {
+		db = db.FieldsEx("idempotency_key")
+	}
 	result, err := db.Insert(transaction)
 	if err != nil {
 		return 0, gerror.Wrapf(err, "创建交易记录失败: UserID=%d", transaction.UserId)
@@ -63,6 +110,21 @@ func (d *transactionDAO) GetTransactionByBusinessID(ctx context.Context, busines
 	return transaction, nil
 }
 
+// GetTransactionByIdempotencyKey 通过客户端幂等键获取交易，幂等键为空时返回 nil
+func (d *transactionDAO) GetTransactionByIdempotencyKey(ctx context.Context, idempotencyKey string) (*entity.Transactions, error) {
+	if idempotencyKey == "" {
+		return nil, nil
+	}
+	var transaction *entity.Transactions
+	err := g.Model("transactions").Ctx(ctx).
+		Where("idempotency_key = ?", idempotencyKey).
+		Scan(&transaction)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "查询交易失败: IdempotencyKey=%s", idempotencyKey)
+	}
+	return transaction, nil
+}
+
 // UpdateTransactionStatus 更新交易状态
 func (d *transactionDAO) UpdateTransactionStatus(ctx context.Context, tx gdb.TX, transactionID int64, status string) error {
 	var db *gdb.Model
@@ -81,3 +143,167 @@ func (d *transactionDAO) UpdateTransactionStatus(ctx context.Context, tx gdb.TX,
 	}
 	return nil
 }
+
+// UpdateRequestOptions 保存增强版请求的幂等键、优先级和过期时间；幂等键被并发请求占用时违反唯一约束
+func (d *transactionDAO) UpdateRequestOptions(ctx context.Context, tx gdb.TX, transactionID uint64, idempotencyKey string, priority int, expireAt *gtime.Time) error {
+	var db *gdb.Model
+	if tx != nil {
+		db = g.Model("transactions").Ctx(ctx).TX(tx)
+	} else {
+		db = g.Model("transactions").Ctx(ctx)
+	}
+
+	_, err := db.Where("transaction_id = ?", transactionID).Update(map[string]any{
+		"idempotency_key": idempotencyKey,
+		"priority":        priority,
+		"expire_at":       expireAt,
+		"updated_at":      gtime.Now(),
+	})
+	if err != nil {
+		return gerror.Wrapf(err, "保存交易请求属性失败: TransactionID=%d", transactionID)
+	}
+	return nil
+}
+
+// SearchTransactions 按条件查询交易，按 (created_at, transaction_id) 键集分页
+func (d *transactionDAO) SearchTransactions(ctx context.Context, filter *TransactionFilter, limit int) ([]*entity.Transactions, error) {
+	model := applyTransactionFilter(g.Model("transactions").Ctx(ctx), filter)
+
+	if filter.AfterCreatedAt != nil {
+		op := "<"
+		if filter.Ascending {
+			op = ">"
+		}
+		model = model.Where("(created_at "+op+" ? OR (created_at = ? AND transaction_id "+op+" ?))",
+			filter.AfterCreatedAt, filter.AfterCreatedAt, filter.AfterID)
+	}
+	if filter.Ascending {
+		model = model.OrderAsc("created_at").OrderAsc("transaction_id")
+	} else {
+		model = model.OrderDesc("created_at").OrderDesc("transaction_id")
+	}
+	if limit > 0 {
+		model = model.Limit(limit)
+	}
+
+	var transactions []*entity.Transactions
+	if err := model.Scan(&transactions); err != nil {
+		return nil, gerror.Wrap(err, "查询交易失败")
+	}
+	return transactions, nil
+}
+
+// CountTransactions 统计满足条件的交易数量（忽略分页游标）
+func (d *transactionDAO) CountTransactions(ctx context.Context, filter *TransactionFilter) (int, error) {
+	count, err := applyTransactionFilter(g.Model("transactions").Ctx(ctx), filter).Count()
+	if err != nil {
+		return 0, gerror.Wrap(err, "统计交易数量失败")
+	}
+	return count, nil
+}
+
+// GetLastTransactionBefore 获取用户某代币可用余额在指定时间之前的最后一笔成功交易，不存在时返回 nil
+func (d *transactionDAO) GetLastTransactionBefore(ctx context.Context, userID uint64, symbol string, before *gtime.Time) (*entity.Transactions, error) {
+	return d.GetLastTransactionBetween(ctx, userID, symbol, nil, before)
+}
+
+// GetLastTransactionBetween 获取用户某代币可用余额在 [since, before) 内的最后一笔成功交易，since 为 nil 时不限下界，不存在时返回 nil
+func (d *transactionDAO) GetLastTransactionBetween(ctx context.Context, userID uint64, symbol string, since, before *gtime.Time) (*entity.Transactions, error) {
+	model := g.Model("transactions").Ctx(ctx).
+		Where("user_id = ? AND symbol = ? AND wallet_type = ? AND status = 1", userID, symbol, "available").
+		Where("created_at < ?", before)
+	if since != nil {
+		model = model.Where("created_at >= ?", since)
+	}
+
+	var transaction *entity.Transactions
+	err := model.
+		OrderDesc("created_at").
+		OrderDesc("transaction_id").
+		Limit(1).
+		Scan(&transaction)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "查询历史交易失败: UserID=%d, Symbol=%s", userID, symbol)
+	}
+	return transaction, nil
+}
+
+// GetAmountStats 统计满足条件的交易数量和平均金额（忽略分页游标）
+func (d *transactionDAO) GetAmountStats(ctx context.Context, filter *TransactionFilter) (int, decimal.Decimal, error) {
+	record, err := applyTransactionFilter(g.Model("transactions").Ctx(ctx), filter).
+		Fields("COUNT(1) AS total, COALESCE(AVG(amount), 0) AS average").
+		One()
+	if err != nil {
+		return 0, decimal.Zero, gerror.Wrap(err, "统计交易金额失败")
+	}
+	if record.IsEmpty() {
+		return 0, decimal.Zero, nil
+	}
+	average, err := decimal.NewFromString(record["average"].String())
+	if err != nil {
+		return 0, decimal.Zero, gerror.Wrapf(err, "解析平均金额失败: %s", record["average"].String())
+	}
+	return record["total"].Int(), average, nil
+}
+
+// applyTransactionFilter 将查询条件应用到模型；元数据条件使用 JSON_EXTRACT（MySQL 与 SQLite 均支持）
+func applyTransactionFilter(model *gdb.Model, filter *TransactionFilter) *gdb.Model {
+	if filter.UserID != 0 {
+		model = model.Where("user_id = ?", filter.UserID)
+	}
+	if filter.TokenID != 0 {
+		model = model.Where("token_id = ?", filter.TokenID)
+	}
+	if filter.Symbol != "" {
+		model = model.Where("symbol = ?", filter.Symbol)
+	}
+	if filter.Direction != "" {
+		model = model.Where("direction = ?", filter.Direction)
+	}
+	if filter.WalletType != "" {
+		model = model.Where("wallet_type = ?", filter.WalletType)
+	}
+	if len(filter.Types) > 0 {
+		model = model.WhereIn("type", filter.Types)
+	}
+	if len(filter.Statuses) > 0 {
+		model = model.WhereIn("status", filter.Statuses)
+	}
+	if filter.MinAmount != nil {
+		model = model.Where("amount >= ?", filter.MinAmount.String())
+	}
+	if filter.MaxAmount != nil {
+		model = model.Where("amount <= ?", filter.MaxAmount.String())
+	}
+	if filter.StartTime != nil {
+		model = model.Where("created_at >= ?", filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		model = model.Where("created_at < ?", filter.EndTime)
+	}
+	if filter.RelatedEntityID != 0 {
+		model = model.Where("related_entity_id = ?", filter.RelatedEntityID)
+	}
+	if filter.RelatedEntityType != "" {
+		model = model.Where("related_entity_type = ?", filter.RelatedEntityType)
+	}
+	if filter.TargetUserID != 0 {
+		model = model.Where("target_user_id = ?", filter.TargetUserID)
+	}
+	if filter.RequestSource != "" {
+		model = model.Where("request_source = ?", filter.RequestSource)
+	}
+	if filter.RequestIP != "" {
+		model = model.Where("request_ip = ?", filter.RequestIP)
+	}
+	for _, tag := range filter.Tags {
+		model = model.Where("transaction_id IN (SELECT transaction_id FROM transaction_tags WHERE tag = ?)", tag)
+	}
+	for _, key := range filter.MetadataKeys {
+		model = model.Where("JSON_EXTRACT(request_metadata, ?) IS NOT NULL", "$."+key)
+	}
+	for key, value := range filter.Metadata {
+		model = model.Where("JSON_EXTRACT(request_metadata, ?) = ?", "$."+key, value)
+	}
+	return model
+}
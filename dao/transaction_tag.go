@@ -0,0 +1,110 @@
+package dao
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+
+	"github.com/yalks/wallet/entity"
+)
+
+// ITransactionTagDAO 交易标签数据访问接口
+type ITransactionTagDAO interface {
+	// CreateTags 为交易写入标签，已存在的标签忽略
+	CreateTags(ctx context.Context, tx gdb.TX, transactionID, userID uint64, tags []string) error
+	// ListTagsByTransactionIDs 批量获取交易标签
+	ListTagsByTransactionIDs(ctx context.Context, transactionIDs []uint64) (map[uint64][]string, error)
+	// ListTransactionIDsByTag 按标签查询交易ID（userID 为 0 时不限用户），按交易ID倒序
+	ListTransactionIDsByTag(ctx context.Context, userID uint64, tag string, limit, offset int) ([]uint64, error)
+}
+
+type transactionTagDAO struct{}
+
+// NewTransactionTagDAO 创建交易标签DAO实例
+func NewTransactionTagDAO() ITransactionTagDAO {
+	return &transactionTagDAO{}
+}
+
+// CreateTags 为交易写入标签
+func (d *transactionTagDAO) CreateTags(ctx context.Context, tx gdb.TX, transactionID, userID uint64, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	var db *gdb.Model
+	if tx != nil {
+		db = g.Model("transaction_tags").Ctx(ctx).TX(tx)
+	} else {
+		db = g.Model("transaction_tags").Ctx(ctx)
+	}
+
+	now := gtime.Now()
+	records := make([]*entity.TransactionTags, 0, len(tags))
+	for _, tag := range tags {
+		records = append(records, &entity.TransactionTags{
+			TransactionId: transactionID,
+			UserId:        userID,
+			Tag:           tag,
+			CreatedAt:     now,
+		})
+	}
+
+	// (transaction_id, tag) 唯一，幂等重放时忽略已存在的标签
+	if _, err := db.InsertIgnore(records); err != nil {
+		return gerror.Wrapf(err, "写入交易标签失败: TransactionID=%d", transactionID)
+	}
+	return nil
+}
+
+// ListTagsByTransactionIDs 批量获取交易标签
+func (d *transactionTagDAO) ListTagsByTransactionIDs(ctx context.Context, transactionIDs []uint64) (map[uint64][]string, error) {
+	result := make(map[uint64][]string, len(transactionIDs))
+	if len(transactionIDs) == 0 {
+		return result, nil
+	}
+
+	var records []*entity.TransactionTags
+	err := g.Model("transaction_tags").Ctx(ctx).
+		WhereIn("transaction_id", transactionIDs).
+		OrderAsc("id").
+		Scan(&records)
+	if err != nil {
+		return nil, gerror.Wrap(err, "查询交易标签失败")
+	}
+
+	for _, record := range records {
+		result[record.TransactionId] = append(result[record.TransactionId], record.Tag)
+	}
+	return result, nil
+}
+
+// ListTransactionIDsByTag 按标签查询交易ID（userID 为 0 时不限用户），按交易ID倒序
+func (d *transactionTagDAO) ListTransactionIDsByTag(ctx context.Context, userID uint64, tag string, limit, offset int) ([]uint64, error) {
+	model := g.Model("transaction_tags").Ctx(ctx).
+		Fields("transaction_id").
+		Where("tag = ?", tag).
+		OrderDesc("transaction_id")
+	if userID != 0 {
+		model = model.Where("user_id = ?", userID)
+	}
+	if limit > 0 {
+		model = model.Limit(limit)
+	}
+	if offset > 0 {
+		model = model.Offset(offset)
+	}
+
+	values, err := model.Array()
+	if err != nil {
+		return nil, gerror.Wrapf(err, "按标签查询交易失败: Tag=%s", tag)
+	}
+
+	ids := make([]uint64, 0, len(values))
+	for _, value := range values {
+		ids = append(ids, value.Uint64())
+	}
+	return ids, nil
+}
@@ -0,0 +1,176 @@
+package dao
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/entity"
+)
+
+// IVelocityDAO 限额计数器数据访问接口
+type IVelocityDAO interface {
+	// AddInTx 将一笔交易累加到对应的分钟桶，桶不存在时创建
+	AddInTx(ctx context.Context, tx gdb.TX, counter *entity.VelocityCounters) error
+	// SumInTx 统计满足条件的交易笔数和金额合计
+	SumInTx(ctx context.Context, tx gdb.TX, filter *VelocityCounterFilter) (int, decimal.Decimal, error)
+	// ListBucketsInTx 按分钟桶汇总满足条件的交易（按桶时间升序）
+	ListBucketsInTx(ctx context.Context, tx gdb.TX, filter *VelocityCounterFilter) ([]*VelocityBucket, error)
+	// DeleteInTx 删除用户某代币自指定分钟桶起的全部计数器
+	DeleteInTx(ctx context.Context, tx gdb.TX, userID uint64, symbol string, fromBucket int64) error
+	// InsertInTx 批量写入计数器
+	InsertInTx(ctx context.Context, tx gdb.TX, counters []*entity.VelocityCounters) error
+	// PurgeBefore 删除早于指定分钟桶的计数器，返回删除数量
+	PurgeBefore(ctx context.Context, beforeBucket int64) (int64, error)
+}
+
+// VelocityCounterFilter 限额计数器查询条件
+type VelocityCounterFilter struct {
+	UserID        uint64
+	Symbol        string
+	FundTypes     []string // 为空时不限资金类型
+	RequestSource string   // 为空时不限请求来源
+	FromBucket    int64    // bucket_start >= FromBucket
+}
+
+// VelocityBucket 分钟桶汇总
+type VelocityBucket struct {
+	BucketStart int64           `json:"bucketStart" orm:"bucket_start"`
+	TxCount     int             `json:"txCount"     orm:"tx_count"`
+	Amount      decimal.Decimal `json:"amount"      orm:"amount"`
+}
+
+type velocityDAO struct{}
+
+// NewVelocityDAO 创建限额计数器DAO实例
+func NewVelocityDAO() IVelocityDAO {
+	return &velocityDAO{}
+}
+
+// model 获取限额计数器表模型，tx 不为空时在事务中执行
+func (d *velocityDAO) model(ctx context.Context, tx gdb.TX) *gdb.Model {
+	if tx != nil {
+		return g.Model("velocity_counters").Ctx(ctx).TX(tx)
+	}
+	return g.Model("velocity_counters").Ctx(ctx)
+}
+
+// filtered 应用查询条件
+func (d *velocityDAO) filtered(ctx context.Context, tx gdb.TX, filter *VelocityCounterFilter) *gdb.Model {
+	model := d.model(ctx, tx).
+		Where("user_id = ? AND symbol = ? AND bucket_start >= ?", filter.UserID, filter.Symbol, filter.FromBucket)
+	if len(filter.FundTypes) > 0 {
+		model = model.WhereIn("fund_type", filter.FundTypes)
+	}
+	if filter.RequestSource != "" {
+		model = model.Where("request_source = ?", filter.RequestSource)
+	}
+	return model
+}
+
+// locked 应用查询条件，在事务中时加共享锁
+func (d *velocityDAO) locked(ctx context.Context, tx gdb.TX, filter *VelocityCounterFilter) *gdb.Model {
+	if tx == nil {
+		return d.filtered(ctx, tx, filter)
+	}
+	return lockShared(d.filtered(ctx, tx, filter))
+}
+
+// velocityUpsertSQL 各数据库的计数器累加语句，桶内首笔交易并发插入时不会违反 uk_counter
+var velocityUpsertSQL = map[string]string{
+	dialectMySQL: "INSERT INTO velocity_counters (user_id, symbol, fund_type, request_source, bucket_start, tx_count, amount, updated_at) " +
+		"VALUES (?, ?, ?, ?, ?, ?, ?, ?) " +
+		"ON DUPLICATE KEY UPDATE tx_count = tx_count + VALUES(tx_count), amount = amount + VALUES(amount), updated_at = VALUES(updated_at)",
+	dialectSQLite: "INSERT INTO velocity_counters (user_id, symbol, fund_type, request_source, bucket_start, tx_count, amount, updated_at) " +
+		"VALUES (?, ?, ?, ?, ?, ?, ?, ?) " +
+		"ON CONFLICT (user_id, symbol, fund_type, request_source, bucket_start) " +
+		"DO UPDATE SET tx_count = tx_count + excluded.tx_count, amount = amount + excluded.amount, updated_at = excluded.updated_at",
+}
+
+// AddInTx 将一笔交易累加到对应的分钟桶（MySQL 使用 ON DUPLICATE KEY UPDATE，SQLite 使用 ON CONFLICT DO UPDATE）
+func (d *velocityDAO) AddInTx(ctx context.Context, tx gdb.TX, counter *entity.VelocityCounters) error {
+	upsert, ok := velocityUpsertSQL[currentDialect()]
+	if !ok {
+		return gerror.Newf("限额计数器不支持的数据库类型: %s", g.DB().GetConfig().Type)
+	}
+	counter.UpdatedAt = gtime.Now()
+	args := []interface{}{
+		counter.UserId, counter.Symbol, counter.FundType, counter.RequestSource,
+		counter.BucketStart, counter.TxCount, counter.Amount.String(), counter.UpdatedAt.String(),
+	}
+	var err error
+	if tx != nil {
+		_, err = tx.Ctx(ctx).Exec(upsert, args...)
+	} else {
+		_, err = g.DB().Exec(ctx, upsert, args...)
+	}
+	if err != nil {
+		return gerror.Wrapf(err, "累加限额计数器失败: UserID=%d, Symbol=%s", counter.UserId, counter.Symbol)
+	}
+	return nil
+}
+
+// SumInTx 统计满足条件的交易笔数和金额合计；在事务中使用锁定读，结果不受事务快照影响
+func (d *velocityDAO) SumInTx(ctx context.Context, tx gdb.TX, filter *VelocityCounterFilter) (int, decimal.Decimal, error) {
+	var total VelocityBucket
+	err := d.locked(ctx, tx, filter).
+		Fields("COALESCE(SUM(tx_count), 0) AS tx_count, COALESCE(SUM(amount), 0) AS amount").
+		Scan(&total)
+	if err != nil {
+		return 0, decimal.Zero, gerror.Wrapf(err, "统计限额计数器失败: UserID=%d, Symbol=%s", filter.UserID, filter.Symbol)
+	}
+	return total.TxCount, total.Amount, nil
+}
+
+// ListBucketsInTx 按分钟桶汇总满足条件的交易（按桶时间升序）；在事务中使用锁定读
+func (d *velocityDAO) ListBucketsInTx(ctx context.Context, tx gdb.TX, filter *VelocityCounterFilter) ([]*VelocityBucket, error) {
+	var buckets []*VelocityBucket
+	err := d.locked(ctx, tx, filter).
+		Fields("bucket_start, SUM(tx_count) AS tx_count, SUM(amount) AS amount").
+		Group("bucket_start").
+		OrderAsc("bucket_start").
+		Scan(&buckets)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "查询限额计数器失败: UserID=%d, Symbol=%s", filter.UserID, filter.Symbol)
+	}
+	return buckets, nil
+}
+
+// DeleteInTx 删除用户某代币自指定分钟桶起的全部计数器
+func (d *velocityDAO) DeleteInTx(ctx context.Context, tx gdb.TX, userID uint64, symbol string, fromBucket int64) error {
+	_, err := d.model(ctx, tx).
+		Where("user_id = ? AND symbol = ? AND bucket_start >= ?", userID, symbol, fromBucket).
+		Delete()
+	if err != nil {
+		return gerror.Wrapf(err, "删除限额计数器失败: UserID=%d, Symbol=%s", userID, symbol)
+	}
+	return nil
+}
+
+// InsertInTx 批量写入计数器
+func (d *velocityDAO) InsertInTx(ctx context.Context, tx gdb.TX, counters []*entity.VelocityCounters) error {
+	if len(counters) == 0 {
+		return nil
+	}
+	if _, err := d.model(ctx, tx).Insert(counters); err != nil {
+		return gerror.Wrap(err, "批量写入限额计数器失败")
+	}
+	return nil
+}
+
+// PurgeBefore 删除早于指定分钟桶的计数器，返回删除数量
+func (d *velocityDAO) PurgeBefore(ctx context.Context, beforeBucket int64) (int64, error) {
+	result, err := d.model(ctx, nil).Where("bucket_start < ?", beforeBucket).Delete()
+	if err != nil {
+		return 0, gerror.Wrap(err, "清理限额计数器失败")
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, gerror.Wrap(err, "获取影响行数失败")
+	}
+	return affected, nil
+}
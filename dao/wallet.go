@@ -15,12 +15,18 @@ import (
 type IWalletDAO interface {
 	// GetWalletByUserIDAndSymbol 通过用户ID和代币符号获取钱包
 	GetWalletByUserIDAndSymbol(ctx context.Context, userID uint64, symbol string) (*entity.Wallets, error)
+	// LockWalletInTx 在事务中锁定用户某代币的钱包行 (SELECT ... FOR UPDATE)，钱包不存在时返回 nil
+	LockWalletInTx(ctx context.Context, tx gdb.TX, userID uint64, symbol string) (*entity.Wallets, error)
 	// CreateWalletRecord 创建钱包记录
 	CreateWalletRecord(ctx context.Context, tx gdb.TX, wallet *entity.Wallets) error
 	// UpdateWalletBalance 更新钱包余额
 	UpdateWalletBalance(ctx context.Context, tx gdb.TX, walletID uint, availableBalance, frozenBalance int64) error
 	// GetAllWallets 获取所有钱包记录
 	GetAllWallets(ctx context.Context) ([]*entity.Wallets, error)
+	// ListWalletsAfter 按钱包ID顺序分页获取钱包记录（afterWalletID 之后的 limit 条）
+	ListWalletsAfter(ctx context.Context, afterWalletID int, limit int) ([]*entity.Wallets, error)
+	// GetUserWallets 获取用户的全部钱包（按代币符号排序）
+	GetUserWallets(ctx context.Context, userID uint64) ([]*entity.Wallets, error)
 }
 
 type walletDAO struct{}
@@ -42,6 +48,24 @@ func (d *walletDAO) GetWalletByUserIDAndSymbol(ctx context.Context, userID uint6
 	return wallet, nil
 }
 
+// LockWalletInTx 在事务中锁定用户某代币的钱包行 (SELECT ... FOR UPDATE，SQLite 不加行锁)，钱包不存在时返回 nil
+func (d *walletDAO) LockWalletInTx(ctx context.Context, tx gdb.TX, userID uint64, symbol string) (*entity.Wallets, error) {
+	var db *gdb.Model
+	if tx != nil {
+		db = g.Model("wallets").Ctx(ctx).TX(tx)
+	} else {
+		db = g.Model("wallets").Ctx(ctx)
+	}
+
+	var wallet *entity.Wallets
+	err := lockForUpdate(db.Where("user_id = ? AND symbol = ? AND deleted_at IS NULL", userID, symbol)).
+		Scan(&wallet)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "锁定钱包失败: UserID=%d, Symbol=%s", userID, symbol)
+	}
+	return wallet, nil
+}
+
 // CreateWalletRecord 创建钱包记录
 func (d *walletDAO) CreateWalletRecord(ctx context.Context, tx gdb.TX, wallet *entity.Wallets) error {
 	var db *gdb.Model
@@ -89,3 +113,30 @@ func (d *walletDAO) GetAllWallets(ctx context.Context) ([]*entity.Wallets, error
 	}
 	return wallets, nil
 }
+
+// ListWalletsAfter 按钱包ID顺序分页获取钱包记录（afterWalletID 之后的 limit 条）
+func (d *walletDAO) ListWalletsAfter(ctx context.Context, afterWalletID int, limit int) ([]*entity.Wallets, error) {
+	var wallets []*entity.Wallets
+	err := g.Model("wallets").Ctx(ctx).
+		Where("wallet_id > ? AND deleted_at IS NULL", afterWalletID).
+		OrderAsc("wallet_id").
+		Limit(limit).
+		Scan(&wallets)
+	if err != nil {
+		return nil, gerror.Wrap(err, "查询钱包记录失败")
+	}
+	return wallets, nil
+}
+
+// GetUserWallets 获取用户的全部钱包（按代币符号排序）
+func (d *walletDAO) GetUserWallets(ctx context.Context, userID uint64) ([]*entity.Wallets, error) {
+	var wallets []*entity.Wallets
+	err := g.Model("wallets").Ctx(ctx).
+		Where("user_id = ? AND deleted_at IS NULL", userID).
+		OrderAsc("symbol").
+		Scan(&wallets)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "查询用户钱包失败: UserID=%d", userID)
+	}
+	return wallets, nil
+}
@@ -0,0 +1,179 @@
+package dao
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+
+	"github.com/yalks/wallet/entity"
+)
+
+// IWebhookDAO Webhook 出站与死信数据访问接口
+type IWebhookDAO interface {
+	// CreateOutbox 写入出站记录（通常与业务操作在同一事务中）
+	CreateOutbox(ctx context.Context, tx gdb.TX, record *entity.WebhookOutbox) (uint64, error)
+	// ListDueOutbox 获取到期待投递的出站记录
+	ListDueOutbox(ctx context.Context, now *gtime.Time, limit int) ([]*entity.WebhookOutbox, error)
+	// ClaimOutbox 抢占出站记录：尝试次数加一并将下次投递时间推迟到租约结束，返回是否抢占成功
+	ClaimOutbox(ctx context.Context, id uint64, expectedAttempts int, leaseUntil *gtime.Time) (bool, error)
+	// UpdateOutbox 更新出站记录
+	UpdateOutbox(ctx context.Context, id uint64, data map[string]any) error
+	// MoveToDeadLetter 将出站记录移入死信表
+	MoveToDeadLetter(ctx context.Context, record *entity.WebhookOutbox) error
+	// ListDeadLetters 获取死信列表
+	ListDeadLetters(ctx context.Context, limit, offset int) ([]*entity.WebhookDeadLetters, error)
+	// RequeueDeadLetter 将死信重新放回出站表等待投递
+	RequeueDeadLetter(ctx context.Context, id uint64) error
+}
+
+type webhookDAO struct{}
+
+// NewWebhookDAO 创建Webhook DAO实例
+func NewWebhookDAO() IWebhookDAO {
+	return &webhookDAO{}
+}
+
+// CreateOutbox 写入出站记录（通常与业务操作在同一事务中）
+func (d *webhookDAO) CreateOutbox(ctx context.Context, tx gdb.TX, record *entity.WebhookOutbox) (uint64, error) {
+	var db *gdb.Model
+	if tx != nil {
+		db = g.Model("webhook_outbox").Ctx(ctx).TX(tx)
+	} else {
+		db = g.Model("webhook_outbox").Ctx(ctx)
+	}
+
+	result, err := db.Insert(record)
+	if err != nil {
+		return 0, gerror.Wrapf(err, "写入Webhook出站记录失败: Event=%s, BusinessID=%s", record.Event, record.BusinessId)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, gerror.Wrap(err, "获取Webhook出站记录ID失败")
+	}
+	return uint64(id), nil
+}
+
+// ListDueOutbox 获取到期待投递的出站记录
+func (d *webhookDAO) ListDueOutbox(ctx context.Context, now *gtime.Time, limit int) ([]*entity.WebhookOutbox, error) {
+	var records []*entity.WebhookOutbox
+	err := g.Model("webhook_outbox").Ctx(ctx).
+		Where("status = ? AND next_attempt_at <= ?", "pending", now).
+		OrderAsc("next_attempt_at").
+		OrderAsc("id").
+		Limit(limit).
+		Scan(&records)
+	if err != nil {
+		return nil, gerror.Wrap(err, "查询待投递Webhook失败")
+	}
+	return records, nil
+}
+
+// ClaimOutbox 抢占出站记录：尝试次数加一并将下次投递时间推迟到租约结束，返回是否抢占成功
+func (d *webhookDAO) ClaimOutbox(ctx context.Context, id uint64, expectedAttempts int, leaseUntil *gtime.Time) (bool, error) {
+	result, err := g.Model("webhook_outbox").Ctx(ctx).
+		Where("id = ? AND status = ? AND attempts = ?", id, "pending", expectedAttempts).
+		Update(map[string]any{
+			"attempts":        expectedAttempts + 1,
+			"next_attempt_at": leaseUntil,
+			"updated_at":      gtime.Now(),
+		})
+	if err != nil {
+		return false, gerror.Wrapf(err, "抢占Webhook出站记录失败: ID=%d", id)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, gerror.Wrap(err, "获取影响行数失败")
+	}
+	return affected == 1, nil
+}
+
+// UpdateOutbox 更新出站记录
+func (d *webhookDAO) UpdateOutbox(ctx context.Context, id uint64, data map[string]any) error {
+	data["updated_at"] = gtime.Now()
+	if _, err := g.Model("webhook_outbox").Ctx(ctx).Where("id = ?", id).Update(data); err != nil {
+		return gerror.Wrapf(err, "更新Webhook出站记录失败: ID=%d", id)
+	}
+	return nil
+}
+
+// MoveToDeadLetter 将出站记录移入死信表
+func (d *webhookDAO) MoveToDeadLetter(ctx context.Context, record *entity.WebhookOutbox) error {
+	return g.DB().Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		_, err := g.Model("webhook_dead_letters").Ctx(ctx).TX(tx).Insert(&entity.WebhookDeadLetters{
+			OutboxId:       record.Id,
+			Event:          record.Event,
+			BusinessId:     record.BusinessId,
+			Url:            record.Url,
+			Method:         record.Method,
+			Payload:        record.Payload,
+			Attempts:       record.Attempts,
+			LastStatusCode: record.LastStatusCode,
+			LastError:      record.LastError,
+			CreatedAt:      gtime.Now(),
+		})
+		if err != nil {
+			return gerror.Wrapf(err, "写入Webhook死信失败: OutboxID=%d", record.Id)
+		}
+
+		if _, err := g.Model("webhook_outbox").Ctx(ctx).TX(tx).Where("id = ?", record.Id).Delete(); err != nil {
+			return gerror.Wrapf(err, "删除Webhook出站记录失败: ID=%d", record.Id)
+		}
+		return nil
+	})
+}
+
+// ListDeadLetters 获取死信列表
+func (d *webhookDAO) ListDeadLetters(ctx context.Context, limit, offset int) ([]*entity.WebhookDeadLetters, error) {
+	model := g.Model("webhook_dead_letters").Ctx(ctx).OrderDesc("id")
+	if limit > 0 {
+		model = model.Limit(limit)
+	}
+	if offset > 0 {
+		model = model.Offset(offset)
+	}
+
+	var records []*entity.WebhookDeadLetters
+	if err := model.Scan(&records); err != nil {
+		return nil, gerror.Wrap(err, "查询Webhook死信失败")
+	}
+	return records, nil
+}
+
+// RequeueDeadLetter 将死信重新放回出站表等待投递
+func (d *webhookDAO) RequeueDeadLetter(ctx context.Context, id uint64) error {
+	return g.DB().Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		var dead *entity.WebhookDeadLetters
+		if err := g.Model("webhook_dead_letters").Ctx(ctx).TX(tx).Where("id = ?", id).Scan(&dead); err != nil {
+			return gerror.Wrapf(err, "查询Webhook死信失败: ID=%d", id)
+		}
+		if dead == nil {
+			return gerror.Newf("Webhook死信不存在: ID=%d", id)
+		}
+
+		now := gtime.Now()
+		_, err := g.Model("webhook_outbox").Ctx(ctx).TX(tx).Insert(&entity.WebhookOutbox{
+			Event:         dead.Event,
+			BusinessId:    dead.BusinessId,
+			Url:           dead.Url,
+			Method:        dead.Method,
+			Payload:       dead.Payload,
+			Status:        "pending",
+			NextAttemptAt: now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+		if err != nil {
+			return gerror.Wrapf(err, "重新写入Webhook出站记录失败: DeadLetterID=%d", id)
+		}
+
+		if _, err := g.Model("webhook_dead_letters").Ctx(ctx).TX(tx).Where("id = ?", id).Delete(); err != nil {
+			return gerror.Wrapf(err, "删除Webhook死信失败: ID=%d", id)
+		}
+		return nil
+	})
+}
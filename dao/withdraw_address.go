@@ -0,0 +1,162 @@
+package dao
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+
+	"github.com/yalks/wallet/entity"
+)
+
+// IWithdrawAddressDAO 提现地址簿数据访问接口
+type IWithdrawAddressDAO interface {
+	// CreateAddress 创建地址簿记录
+	CreateAddress(ctx context.Context, tx gdb.TX, address *entity.WithdrawAddresses) (uint64, error)
+	// GetAddressByID 通过ID获取地址簿记录
+	GetAddressByID(ctx context.Context, userID uint64, addressID uint64) (*entity.WithdrawAddresses, error)
+	// GetAddress 通过网络和地址获取地址簿记录
+	GetAddress(ctx context.Context, userID uint64, network, address string) (*entity.WithdrawAddresses, error)
+	// ListAddresses 获取用户地址簿（network 为空时返回全部网络）
+	ListAddresses(ctx context.Context, userID uint64, network string) ([]*entity.WithdrawAddresses, error)
+	// DeleteAddress 软删除地址簿记录
+	DeleteAddress(ctx context.Context, tx gdb.TX, userID uint64, addressID uint64) error
+	// GetSettings 获取用户提现地址设置
+	GetSettings(ctx context.Context, userID uint64) (*entity.WithdrawAddressSettings, error)
+	// SaveSettings 保存用户提现地址设置
+	SaveSettings(ctx context.Context, tx gdb.TX, settings *entity.WithdrawAddressSettings) error
+	// GetLatestChangeTime 获取用户地址簿最近一次新增或删除的时间（含已删除记录），无记录时返回 nil
+	GetLatestChangeTime(ctx context.Context, userID uint64) (*gtime.Time, error)
+}
+
+type withdrawAddressDAO struct{}
+
+// NewWithdrawAddressDAO 创建提现地址簿DAO实例
+func NewWithdrawAddressDAO() IWithdrawAddressDAO {
+	return &withdrawAddressDAO{}
+}
+
+// CreateAddress 创建地址簿记录
+func (d *withdrawAddressDAO) CreateAddress(ctx context.Context, tx gdb.TX, address *entity.WithdrawAddresses) (uint64, error) {
+	var db *gdb.Model
+	if tx != nil {
+		db = g.Model("withdraw_addresses").Ctx(ctx).TX(tx)
+	} else {
+		db = g.Model("withdraw_addresses").Ctx(ctx)
+	}
+
+	result, err := db.Insert(address)
+	if err != nil {
+		return 0, gerror.Wrapf(err, "创建地址簿记录失败: UserID=%d, Network=%s", address.UserId, address.Network)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, gerror.Wrap(err, "获取地址簿记录ID失败")
+	}
+	return uint64(id), nil
+}
+
+// GetAddressByID 通过ID获取地址簿记录
+func (d *withdrawAddressDAO) GetAddressByID(ctx context.Context, userID uint64, addressID uint64) (*entity.WithdrawAddresses, error) {
+	var address *entity.WithdrawAddresses
+	err := g.Model("withdraw_addresses").Ctx(ctx).
+		Where("id = ? AND user_id = ? AND status = 1 AND deleted_at IS NULL", addressID, userID).
+		Scan(&address)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "查询地址簿记录失败: UserID=%d, AddressID=%d", userID, addressID)
+	}
+	return address, nil
+}
+
+// GetAddress 通过网络和地址获取地址簿记录
+func (d *withdrawAddressDAO) GetAddress(ctx context.Context, userID uint64, network, address string) (*entity.WithdrawAddresses, error) {
+	var record *entity.WithdrawAddresses
+	err := g.Model("withdraw_addresses").Ctx(ctx).
+		Where("user_id = ? AND network = ? AND address = ? AND status = 1 AND deleted_at IS NULL", userID, network, address).
+		Scan(&record)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "查询地址簿记录失败: UserID=%d, Network=%s", userID, network)
+	}
+	return record, nil
+}
+
+// ListAddresses 获取用户地址簿（network 为空时返回全部网络）
+func (d *withdrawAddressDAO) ListAddresses(ctx context.Context, userID uint64, network string) ([]*entity.WithdrawAddresses, error) {
+	model := g.Model("withdraw_addresses").Ctx(ctx).
+		Where("user_id = ? AND status = 1 AND deleted_at IS NULL", userID).
+		OrderDesc("id")
+	if network != "" {
+		model = model.Where("network = ?", network)
+	}
+
+	var addresses []*entity.WithdrawAddresses
+	if err := model.Scan(&addresses); err != nil {
+		return nil, gerror.Wrapf(err, "查询地址簿失败: UserID=%d", userID)
+	}
+	return addresses, nil
+}
+
+// DeleteAddress 软删除地址簿记录
+func (d *withdrawAddressDAO) DeleteAddress(ctx context.Context, tx gdb.TX, userID uint64, addressID uint64) error {
+	var db *gdb.Model
+	if tx != nil {
+		db = g.Model("withdraw_addresses").Ctx(ctx).TX(tx)
+	} else {
+		db = g.Model("withdraw_addresses").Ctx(ctx)
+	}
+
+	_, err := db.Where("id = ? AND user_id = ?", addressID, userID).Update(map[string]any{
+		"status":     0,
+		"updated_at": gtime.Now(),
+		"deleted_at": gtime.Now(),
+	})
+	if err != nil {
+		return gerror.Wrapf(err, "删除地址簿记录失败: UserID=%d, AddressID=%d", userID, addressID)
+	}
+	return nil
+}
+
+// GetSettings 获取用户提现地址设置
+func (d *withdrawAddressDAO) GetSettings(ctx context.Context, userID uint64) (*entity.WithdrawAddressSettings, error) {
+	var settings *entity.WithdrawAddressSettings
+	err := g.Model("withdraw_address_settings").Ctx(ctx).
+		Where("user_id = ?", userID).
+		Scan(&settings)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "查询提现地址设置失败: UserID=%d", userID)
+	}
+	return settings, nil
+}
+
+// SaveSettings 保存用户提现地址设置
+func (d *withdrawAddressDAO) SaveSettings(ctx context.Context, tx gdb.TX, settings *entity.WithdrawAddressSettings) error {
+	var db *gdb.Model
+	if tx != nil {
+		db = g.Model("withdraw_address_settings").Ctx(ctx).TX(tx)
+	} else {
+		db = g.Model("withdraw_address_settings").Ctx(ctx)
+	}
+
+	_, err := db.Save(settings)
+	if err != nil {
+		return gerror.Wrapf(err, "保存提现地址设置失败: UserID=%d", settings.UserId)
+	}
+	return nil
+}
+
+// GetLatestChangeTime 获取用户地址簿最近一次新增或删除的时间（含已删除记录），无记录时返回 nil
+func (d *withdrawAddressDAO) GetLatestChangeTime(ctx context.Context, userID uint64) (*gtime.Time, error) {
+	value, err := g.Model("withdraw_addresses").Ctx(ctx).Unscoped().
+		Where("user_id = ?", userID).
+		Value("MAX(COALESCE(updated_at, created_at))")
+	if err != nil {
+		return nil, gerror.Wrapf(err, "查询地址簿变更时间失败: UserID=%d", userID)
+	}
+	if value == nil || value.IsEmpty() {
+		return nil, nil
+	}
+	return value.GTime(), nil
+}
@@ -46,4 +46,10 @@ type Transactions struct {
 	ExchangeRate         decimal.Decimal `json:"exchangeRate"         orm:"exchange_rate"          description:"汇率 (如果涉及币种转换)"`                                                                             // 汇率 (如果涉及币种转换)
 	TargetUserId         uint            `json:"targetUserId"         orm:"target_user_id"         description:"目标用户ID (转账、红包等操作的接收方)"`                                                                    // 目标用户ID (转账、红包等操作的接收方)
 	TargetUsername       string          `json:"targetUsername"       orm:"target_username"        description:"目标用户名 (转账、红包等操作的接收方用户名)"`                                                               // 目标用户名 (转账、红包等操作的接收方用户名)
+	IdempotencyKey       string          `json:"idempotencyKey"       orm:"idempotency_key"        description:"客户端幂等键 (唯一，与引用号独立)"`                                                                    // 客户端幂等键 (唯一，与引用号独立)
+	Priority             int             `json:"priority"             orm:"priority"               description:"优先级 (1-10, 越大越优先)"`                                                                     // 优先级 (1-10, 越大越优先)
+	ExpireAt             *gtime.Time     `json:"expireAt"             orm:"expire_at"              description:"请求过期时间"`                                                                                // 请求过期时间
+	RiskScore            int             `json:"riskScore"            orm:"risk_score"             description:"风控评分"`                                                                                  // 风控评分
+	RiskDecision         string          `json:"riskDecision"         orm:"risk_decision"          description:"风控决策: allow, challenge (已验证支付密码); 未评估时为空"`                                              // 风控决策: allow, challenge (已验证支付密码); 未评估时为空
+	RiskReasons          string          `json:"riskReasons"          orm:"risk_reasons"           description:"触发的风控规则 (逗号分隔)"`                                                                        // 触发的风控规则 (逗号分隔)
 }
@@ -0,0 +1,208 @@
+package wallet
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/os/gtimer"
+
+	"github.com/yalks/wallet/logic"
+)
+
+// Publisher 领域事件发布器（进程内通道、本地文件、HTTP 实现见 logic.NewChannelPublisher 等）
+type Publisher = logic.Publisher
+
+// DomainEvent 领域事件
+type DomainEvent = logic.DomainEvent
+
+// EventRelayConfig 领域事件中继配置（对应配置项 wallet.events）
+type EventRelayConfig struct {
+	PollInterval   time.Duration `json:"pollInterval"`   // 扫描出站表的间隔
+	BatchSize      int           `json:"batchSize"`      // 每次扫描的最大事件数
+	PublishTimeout time.Duration `json:"publishTimeout"` // 单个事件发布超时
+	BaseBackoff    time.Duration `json:"baseBackoff"`    // 发布失败后的首次重试等待时间，之后按指数增长
+	MaxBackoff     time.Duration `json:"maxBackoff"`     // 最大重试等待时间
+}
+
+// DefaultEventRelayConfig 默认领域事件中继配置
+func DefaultEventRelayConfig() EventRelayConfig {
+	return EventRelayConfig{
+		PollInterval:   time.Second,
+		BatchSize:      200,
+		PublishTimeout: 10 * time.Second,
+		BaseBackoff:    time.Second,
+		MaxBackoff:     5 * time.Minute,
+	}
+}
+
+// eventRelay 领域事件中继：按事件ID顺序读取出站表并发布，保证至少一次、同一钱包内有序
+type eventRelay struct {
+	context *logic.SharedLogicContext
+	config  EventRelayConfig
+
+	mu        sync.Mutex
+	publisher Publisher
+	entry     *gtimer.Entry
+
+	// relayMu 保证同一进程内只有一轮发布在执行。不同进程之间没有互斥，
+	// 多个实例同时运行中继时同一钱包的事件可能乱序发布，因此只能在一个实例上启动中继
+	relayMu sync.Mutex
+}
+
+// newEventRelay 创建领域事件中继
+func newEventRelay(ctx context.Context) *eventRelay {
+	return &eventRelay{
+		context: logic.GetSharedContext(),
+		config:  loadEventRelayConfig(ctx),
+	}
+}
+
+// loadEventRelayConfig 从配置中读取领域事件中继配置，缺省项使用默认值
+func loadEventRelayConfig(ctx context.Context) EventRelayConfig {
+	config := DefaultEventRelayConfig()
+
+	value, err := g.Cfg().Get(ctx, "wallet.events")
+	if err != nil || value == nil || value.IsEmpty() {
+		return config
+	}
+
+	raw := value.MapStrVar()
+	durations := map[string]*time.Duration{
+		"pollInterval":   &config.PollInterval,
+		"publishTimeout": &config.PublishTimeout,
+		"baseBackoff":    &config.BaseBackoff,
+		"maxBackoff":     &config.MaxBackoff,
+	}
+	for key, target := range durations {
+		if v, ok := raw[key]; ok {
+			if d, err := time.ParseDuration(v.String()); err == nil && d > 0 {
+				*target = d
+			}
+		}
+	}
+	if v, ok := raw["batchSize"]; ok && v.Int() > 0 {
+		config.BatchSize = v.Int()
+	}
+
+	return config
+}
+
+// SetEventPublisher 设置领域事件发布器
+func (m *walletManager) SetEventPublisher(publisher Publisher) {
+	m.events.mu.Lock()
+	defer m.events.mu.Unlock()
+	m.events.publisher = publisher
+}
+
+// RelayPendingEvents 发布出站表中待发布的领域事件，返回本次发布成功的数量。
+// 与 StartEventRelay 相同，同一时间只能有一个实例发布
+func (m *walletManager) RelayPendingEvents(ctx context.Context) (int, error) {
+	return m.events.relay(ctx)
+}
+
+// StartEventRelay 启动后台领域事件中继。中继只在进程内互斥，部署多个实例时只在其中一个实例上启动
+func (m *walletManager) StartEventRelay(ctx context.Context) error {
+	return m.events.start(ctx)
+}
+
+// StopEventRelay 停止后台领域事件中继
+func (m *walletManager) StopEventRelay(ctx context.Context) {
+	m.events.stop(ctx)
+}
+
+// start 启动后台中继
+func (r *eventRelay) start(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.entry != nil {
+		return gerror.New("领域事件中继已启动")
+	}
+	if r.publisher == nil {
+		return gerror.New("未设置领域事件发布器，请先调用 SetEventPublisher")
+	}
+
+	r.entry = gtimer.AddSingleton(ctx, r.config.PollInterval, func(ctx context.Context) {
+		if _, err := r.relay(ctx); err != nil {
+			g.Log().Errorf(ctx, "发布领域事件失败: %v", err)
+		}
+	})
+
+	g.Log().Infof(ctx, "领域事件中继已启动: PollInterval=%s, BatchSize=%d", r.config.PollInterval, r.config.BatchSize)
+	return nil
+}
+
+// stop 停止后台中继
+func (r *eventRelay) stop(ctx context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.entry != nil {
+		r.entry.Close()
+		r.entry = nil
+		g.Log().Info(ctx, "领域事件中继已停止")
+	}
+}
+
+// relay 按事件ID顺序发布待发布事件；某钱包的事件发布失败或未到重试时间时，
+// 本轮跳过该钱包后续的全部事件，保证同一钱包内严格按序号发布
+func (r *eventRelay) relay(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	publisher := r.publisher
+	r.mu.Unlock()
+	if publisher == nil {
+		return 0, gerror.New("未设置领域事件发布器，请先调用 SetEventPublisher")
+	}
+
+	r.relayMu.Lock()
+	defer r.relayMu.Unlock()
+
+	dao := r.context.GetDomainEventDAO()
+	records, err := dao.ListPendingEvents(ctx, r.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	blocked := make(map[string]bool)
+	now := gtime.Now()
+	for _, record := range records {
+		if blocked[record.AggregateId] {
+			continue
+		}
+		if record.NextAttemptAt != nil && record.NextAttemptAt.After(now) {
+			blocked[record.AggregateId] = true
+			continue
+		}
+
+		publishCtx, cancel := context.WithTimeout(ctx, r.config.PublishTimeout)
+		err := publisher.Publish(publishCtx, logic.ConvertToDomainEvent(record))
+		cancel()
+
+		if err != nil {
+			blocked[record.AggregateId] = true
+			attempts := record.Attempts + 1
+			nextAttemptAt := gtime.Now().Add(logic.WebhookBackoff(attempts, r.config.BaseBackoff, r.config.MaxBackoff))
+			if markErr := dao.MarkFailed(ctx, record.Id, attempts, nextAttemptAt, err.Error()); markErr != nil {
+				g.Log().Errorf(ctx, "记录领域事件发布失败出错: ID=%d, Error=%v", record.Id, markErr)
+			}
+			g.Log().Warningf(ctx, "领域事件发布失败，稍后重试: ID=%d, Aggregate=%s, Sequence=%d, Attempts=%d, Error=%v",
+				record.Id, record.AggregateId, record.Sequence, attempts, err)
+			continue
+		}
+
+		// 已发布但未能标记时事件会再次发布（至少一次），因此阻塞该钱包避免本轮乱序
+		if err := dao.MarkPublished(ctx, record.Id); err != nil {
+			blocked[record.AggregateId] = true
+			g.Log().Errorf(ctx, "标记领域事件已发布失败: ID=%d, Error=%v", record.Id, err)
+			continue
+		}
+		published++
+	}
+
+	return published, nil
+}
@@ -0,0 +1,72 @@
+package wallet
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/constants"
+)
+
+// loadFundTypeConfig 从配置中读取自定义资金类型（对应配置项 wallet.fundTypes）
+func loadFundTypeConfig(ctx context.Context) ([]constants.FundTypeInfo, error) {
+	value, err := g.Cfg().Get(ctx, "wallet.fundTypes")
+	if err != nil {
+		return nil, gerror.Wrap(err, "读取自定义资金类型配置失败")
+	}
+	if value == nil || value.IsEmpty() {
+		return nil, nil
+	}
+
+	var infos []constants.FundTypeInfo
+	for i, item := range value.Maps() {
+		info := constants.FundTypeInfo{
+			Type:           constants.FundType(gconv.String(item["type"])),
+			Direction:      constants.FundDirection(gconv.String(item["direction"])),
+			Bidirectional:  gconv.Bool(item["bidirectional"]),
+			Description:    gconv.String(item["description"]),
+			Category:       gconv.String(item["category"]),
+			AllowedSources: gconv.Strings(item["allowedSources"]),
+		}
+
+		info.Flags, err = constants.ParseFundTypeFlags(gconv.Strings(item["flags"]))
+		if err != nil {
+			return nil, gerror.Wrapf(err, "自定义资金类型配置无效: wallet.fundTypes[%d]", i)
+		}
+		if info.Limits.MinAmount, err = parseFundTypeLimit(item["minAmount"]); err != nil {
+			return nil, gerror.Wrapf(err, "自定义资金类型最小金额无效: wallet.fundTypes[%d]", i)
+		}
+		if info.Limits.MaxAmount, err = parseFundTypeLimit(item["maxAmount"]); err != nil {
+			return nil, gerror.Wrapf(err, "自定义资金类型最大金额无效: wallet.fundTypes[%d]", i)
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// parseFundTypeLimit 解析金额限制，未配置时为 0（不限制）
+func parseFundTypeLimit(value interface{}) (decimal.Decimal, error) {
+	raw := gconv.String(value)
+	if raw == "" {
+		return decimal.Zero, nil
+	}
+	return decimal.NewFromString(raw)
+}
+
+// registerConfiguredFundTypes 注册配置文件中的自定义资金类型，定义冲突时初始化失败
+func registerConfiguredFundTypes(ctx context.Context) error {
+	infos, err := loadFundTypeConfig(ctx)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		if err := constants.RegisterFundType(info); err != nil {
+			return gerror.Wrapf(err, "注册自定义资金类型失败: %s", info.Type)
+		}
+		g.Log().Infof(ctx, "已注册自定义资金类型: Type=%s, Category=%s, Direction=%s", info.Type, info.Category, info.Direction)
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package wallet
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+
+	"github.com/yalks/wallet/constants"
+)
+
+// HookStage 资金操作钩子阶段
+type HookStage string
+
+const (
+	HookStageBeforeValidate HookStage = "before_validate" // 校验前，可修改请求
+	HookStageBeforeExecute  HookStage = "before_execute"  // 校验后、余额变动前，可否决或修改请求
+	HookStageAfterExecute   HookStage = "after_execute"   // 余额变动后，在同一事务中执行，返回错误会回滚整个事务
+	HookStageAfterCommit    HookStage = "after_commit"    // 事务提交后执行，错误只记录日志
+)
+
+// HookContext 钩子收到的操作上下文
+type HookContext struct {
+	Stage     HookStage               // 当前阶段
+	Tx        gdb.TX                  // 当前事务，AfterCommit 阶段为 nil
+	Request   *FundOperationRequest   // 完整请求，BeforeValidate 和 BeforeExecute 阶段可修改
+	Direction constants.FundDirection // 解析后的资金方向，BeforeValidate 阶段为空
+	Result    *FundOperationResult    // 操作结果，仅 AfterExecute 和 AfterCommit 阶段有值
+}
+
+// FundOperationHook 资金操作钩子，返回错误时中止操作（AfterCommit 阶段除外）
+type FundOperationHook func(ctx context.Context, hc *HookContext) error
+
+// HookRegistration 钩子注册信息
+type HookRegistration struct {
+	Name       string               // 钩子名称，出现在日志和错误信息中
+	Stage      HookStage            // 执行阶段
+	FundTypes  []constants.FundType // 适用的资金类型
+	Categories []string             // 适用的资金类型分类；与 FundTypes 都为空时适用于全部资金类型
+	Priority   int                  // 数值越小越先执行，相同时按注册顺序
+	Hook       FundOperationHook    // 钩子函数
+}
+
+// registeredHook 已注册的钩子
+type registeredHook struct {
+	id  uint64
+	reg HookRegistration
+}
+
+// matches 检查钩子是否适用于资金类型
+func (h *registeredHook) matches(fundType constants.FundType) bool {
+	if len(h.reg.FundTypes) == 0 && len(h.reg.Categories) == 0 {
+		return true
+	}
+	for _, t := range h.reg.FundTypes {
+		if t == fundType {
+			return true
+		}
+	}
+	if info, ok := constants.GetFundTypeInfo(fundType); ok {
+		for _, category := range h.reg.Categories {
+			if category == info.Category {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hookRegistry 钩子注册表，按 (Priority, 注册顺序) 排序
+type hookRegistry struct {
+	mu     sync.RWMutex
+	nextID uint64
+	hooks  []*registeredHook
+}
+
+// newHookRegistry 创建钩子注册表
+func newHookRegistry() *hookRegistry {
+	return &hookRegistry{}
+}
+
+// register 注册钩子，返回钩子ID
+func (r *hookRegistry) register(reg *HookRegistration) (uint64, error) {
+	if reg == nil || reg.Hook == nil {
+		return 0, gerror.New("钩子函数不能为空")
+	}
+	switch reg.Stage {
+	case HookStageBeforeValidate, HookStageBeforeExecute, HookStageAfterExecute, HookStageAfterCommit:
+	default:
+		return 0, gerror.Newf("无效的钩子阶段: %s", reg.Stage)
+	}
+	for _, fundType := range reg.FundTypes {
+		if !constants.IsValidFundType(fundType) {
+			return 0, gerror.Newf("钩子 %s 的资金类型无效: %s", reg.Name, fundType)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	hook := &registeredHook{id: r.nextID, reg: *reg}
+	hook.reg.FundTypes = append([]constants.FundType(nil), reg.FundTypes...)
+	hook.reg.Categories = append([]string(nil), reg.Categories...)
+	r.hooks = append(r.hooks, hook)
+	sort.SliceStable(r.hooks, func(i, j int) bool {
+		return r.hooks[i].reg.Priority < r.hooks[j].reg.Priority
+	})
+	return hook.id, nil
+}
+
+// unregister 注销钩子，钩子不存在时返回 false
+func (r *hookRegistry) unregister(id uint64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, hook := range r.hooks {
+		if hook.id == id {
+			r.hooks = append(r.hooks[:i:i], r.hooks[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// match 返回某阶段适用于资金类型的钩子（已排序的快照）
+func (r *hookRegistry) match(stage HookStage, fundType constants.FundType) []*registeredHook {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var matched []*registeredHook
+	for _, hook := range r.hooks {
+		if hook.reg.Stage == stage && hook.matches(fundType) {
+			matched = append(matched, hook)
+		}
+	}
+	return matched
+}
+
+// run 依次执行某阶段的钩子，遇到第一个错误即停止并返回
+func (r *hookRegistry) run(ctx context.Context, hc *HookContext) error {
+	for _, hook := range r.match(hc.Stage, hc.Request.FundType) {
+		if err := hook.reg.Hook(ctx, hc); err != nil {
+			return gerror.Wrapf(err, "%s 钩子 %s 中止了操作", hc.Stage, hook.reg.Name)
+		}
+	}
+	return nil
+}
+
+// afterCommitKey 上下文中提交后回调队列的键
+type afterCommitKey struct{}
+
+// afterCommitQueue 事务提交后执行的回调队列
+type afterCommitQueue struct {
+	mu        sync.Mutex
+	callbacks []func(ctx context.Context)
+}
+
+// afterCommitQueueFromCtx 获取上下文中的提交后回调队列，不存在时返回 nil
+func afterCommitQueueFromCtx(ctx context.Context) *afterCommitQueue {
+	queue, _ := ctx.Value(afterCommitKey{}).(*afterCommitQueue)
+	return queue
+}
+
+// mark 返回当前队列长度，配合 truncate 在回滚到保存点时丢弃之后加入的回调
+func (q *afterCommitQueue) mark() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.callbacks)
+}
+
+// truncate 丢弃 mark 之后加入的回调
+func (q *afterCommitQueue) truncate(mark int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if mark < len(q.callbacks) {
+		q.callbacks = q.callbacks[:mark]
+	}
+}
+
+// push 加入回调
+func (q *afterCommitQueue) push(callback func(ctx context.Context)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.callbacks = append(q.callbacks, callback)
+}
+
+// drain 按加入顺序执行全部回调
+func (q *afterCommitQueue) drain(ctx context.Context) {
+	q.mu.Lock()
+	callbacks := q.callbacks
+	q.callbacks = nil
+	q.mu.Unlock()
+	for _, callback := range callbacks {
+		callback(ctx)
+	}
+}
+
+// RegisterHook 注册资金操作钩子，返回可用于注销的钩子ID
+func (m *walletManager) RegisterHook(reg *HookRegistration) (uint64, error) {
+	return m.hooks.register(reg)
+}
+
+// UnregisterHook 注销资金操作钩子，钩子不存在时返回 false
+func (m *walletManager) UnregisterHook(id uint64) bool {
+	return m.hooks.unregister(id)
+}
+
+// RunInTransaction 在数据库事务中执行 fn，提交成功后执行期间排队的 AfterCommit 钩子；
+// 嵌套调用时复用外层事务，由最外层负责执行回调
+func (m *walletManager) RunInTransaction(ctx context.Context, fn func(ctx context.Context, tx gdb.TX) error) error {
+	if afterCommitQueueFromCtx(ctx) != nil {
+		return g.DB().Transaction(ctx, fn)
+	}
+
+	queue := &afterCommitQueue{}
+	if err := g.DB().Transaction(context.WithValue(ctx, afterCommitKey{}, queue), fn); err != nil {
+		return err
+	}
+	queue.drain(ctx)
+	m.notifySubscriptions()
+	return nil
+}
+
+// runBeforeHooks 执行 BeforeValidate 或 BeforeExecute 阶段的钩子
+func (m *walletManager) runBeforeHooks(ctx context.Context, tx gdb.TX, stage HookStage, req *FundOperationRequest, direction constants.FundDirection) error {
+	return m.hooks.run(ctx, &HookContext{Stage: stage, Tx: tx, Request: req, Direction: direction})
+}
+
+// runAfterHooks 执行 AfterExecute 阶段的钩子，并将 AfterCommit 钩子加入提交后回调队列
+func (m *walletManager) runAfterHooks(ctx context.Context, tx gdb.TX, req *FundOperationRequest, direction constants.FundDirection, result *FundOperationResult) error {
+	err := m.hooks.run(ctx, &HookContext{Stage: HookStageAfterExecute, Tx: tx, Request: req, Direction: direction, Result: result})
+	if err != nil {
+		return err
+	}
+
+	hooks := m.hooks.match(HookStageAfterCommit, req.FundType)
+	if len(hooks) == 0 {
+		return nil
+	}
+	queue := afterCommitQueueFromCtx(ctx)
+	if queue == nil {
+		return gerror.Newf("资金类型 %s 注册了 AfterCommit 钩子，事务必须通过 RunInTransaction 开启", req.FundType)
+	}
+	hc := &HookContext{Stage: HookStageAfterCommit, Request: req, Direction: direction, Result: result}
+	queue.push(func(ctx context.Context) {
+		for _, hook := range hooks {
+			if err := hook.reg.Hook(ctx, hc); err != nil {
+				g.Log().Errorf(ctx, "AfterCommit 钩子执行失败: Hook=%s, TransactionID=%s, Error=%v", hook.reg.Name, result.TransactionID, err)
+			}
+		}
+	})
+	return nil
+}
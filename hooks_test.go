@@ -0,0 +1,109 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yalks/wallet/constants"
+)
+
+func TestHookRegistry_OrderAndMatching(t *testing.T) {
+	registry := newHookRegistry()
+	var calls []string
+	record := func(name string) FundOperationHook {
+		return func(ctx context.Context, hc *HookContext) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	mustRegister := func(reg *HookRegistration) uint64 {
+		id, err := registry.register(reg)
+		if err != nil {
+			t.Fatalf("register %s: %v", reg.Name, err)
+		}
+		return id
+	}
+	mustRegister(&HookRegistration{Name: "global_late", Stage: HookStageBeforeExecute, Priority: 10, Hook: record("global_late")})
+	mustRegister(&HookRegistration{Name: "withdraw", Stage: HookStageBeforeExecute, FundTypes: []constants.FundType{constants.FundTypeWithdraw}, Hook: record("withdraw")})
+	mustRegister(&HookRegistration{Name: "wallet", Stage: HookStageBeforeExecute, Categories: []string{"wallet"}, Hook: record("wallet")})
+	mustRegister(&HookRegistration{Name: "transfer", Stage: HookStageBeforeExecute, Categories: []string{"transfer"}, Hook: record("transfer")})
+	mustRegister(&HookRegistration{Name: "early", Stage: HookStageBeforeExecute, Priority: -1, Hook: record("early")})
+	afterID := mustRegister(&HookRegistration{Name: "after", Stage: HookStageAfterExecute, Hook: record("after")})
+
+	req := &FundOperationRequest{FundType: constants.FundTypeWithdraw}
+	if err := registry.run(context.Background(), &HookContext{Stage: HookStageBeforeExecute, Request: req}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(calls, ","); got != "early,withdraw,wallet,global_late" {
+		t.Errorf("unexpected order: %s", got)
+	}
+
+	if !registry.unregister(afterID) || registry.unregister(afterID) {
+		t.Error("unregister should succeed once")
+	}
+	if len(registry.match(HookStageAfterExecute, constants.FundTypeWithdraw)) != 0 {
+		t.Error("unregistered hook still matched")
+	}
+}
+
+func TestHookRegistry_ErrorStopsChain(t *testing.T) {
+	registry := newHookRegistry()
+	veto := errors.New("amount not allowed")
+	ranAfter := false
+	_, _ = registry.register(&HookRegistration{Name: "veto", Stage: HookStageBeforeExecute, Hook: func(ctx context.Context, hc *HookContext) error {
+		return veto
+	}})
+	_, _ = registry.register(&HookRegistration{Name: "next", Stage: HookStageBeforeExecute, Hook: func(ctx context.Context, hc *HookContext) error {
+		ranAfter = true
+		return nil
+	}})
+
+	err := registry.run(context.Background(), &HookContext{Stage: HookStageBeforeExecute, Request: &FundOperationRequest{FundType: constants.FundTypeDeposit}})
+	if !errors.Is(err, veto) || !strings.Contains(err.Error(), "veto") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if ranAfter {
+		t.Error("hooks after a failing hook must not run")
+	}
+}
+
+func TestHookRegistry_Validation(t *testing.T) {
+	registry := newHookRegistry()
+	invalid := []*HookRegistration{
+		nil,
+		{Name: "no_func", Stage: HookStageBeforeExecute},
+		{Name: "bad_stage", Stage: "during", Hook: func(context.Context, *HookContext) error { return nil }},
+		{Name: "bad_type", Stage: HookStageAfterCommit, FundTypes: []constants.FundType{"unknown"}, Hook: func(context.Context, *HookContext) error { return nil }},
+	}
+	for i, reg := range invalid {
+		if _, err := registry.register(reg); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+func TestAfterCommitQueue(t *testing.T) {
+	queue := &afterCommitQueue{}
+	var calls []int
+	queue.push(func(context.Context) { calls = append(calls, 1) })
+	mark := queue.mark()
+	queue.push(func(context.Context) { calls = append(calls, 2) })
+	queue.truncate(mark)
+	queue.push(func(context.Context) { calls = append(calls, 3) })
+
+	ctx := context.WithValue(context.Background(), afterCommitKey{}, queue)
+	if afterCommitQueueFromCtx(ctx) != queue || afterCommitQueueFromCtx(context.Background()) != nil {
+		t.Fatal("queue not found in context")
+	}
+	queue.drain(ctx)
+	if len(calls) != 2 || calls[0] != 1 || calls[1] != 3 {
+		t.Errorf("unexpected calls: %v", calls)
+	}
+	queue.drain(ctx)
+	if len(calls) != 2 {
+		t.Error("callbacks must run only once")
+	}
+}
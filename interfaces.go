@@ -2,6 +2,8 @@ package wallet
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/yalks/wallet/constants"
 
@@ -12,14 +14,15 @@ import (
 // FundOperationRequest 资金操作请求
 // Deprecated: Use constants.FundOperationRequest instead
 type FundOperationRequest struct {
-	UserID      uint64             `json:"user_id" validate:"required"`      // 用户ID
-	TokenSymbol string             `json:"token_symbol" validate:"required"` // 代币符号
-	Amount      decimal.Decimal    `json:"amount" validate:"required,gt=0"`  // 操作金额（用户友好格式）
-	BusinessID  string             `json:"business_id" validate:"required"`  // 业务ID（用于幂等性）
-	FundType    constants.FundType `json:"fund_type" validate:"required"`    // 资金类型
-	Description string             `json:"description"`                      // 操作描述
-	Metadata    map[string]string  `json:"metadata,omitempty"`               // 扩展元数据
-	RelatedID   int64              `json:"related_id,omitempty"`             // 关联ID（如红包ID、转账ID等）
+	UserID      uint64                  `json:"user_id" validate:"required"`      // 用户ID
+	TokenSymbol string                  `json:"token_symbol" validate:"required"` // 代币符号
+	Amount      decimal.Decimal         `json:"amount" validate:"required,gt=0"`  // 操作金额（用户友好格式）
+	BusinessID  string                  `json:"business_id" validate:"required"`  // 业务ID（用于幂等性）
+	FundType    constants.FundType      `json:"fund_type" validate:"required"`    // 资金类型
+	Direction   constants.FundDirection `json:"direction,omitempty"`              // 资金方向（双向资金类型必填，其他类型可选且必须与类型方向一致）
+	Description string                  `json:"description"`                      // 操作描述
+	Metadata    map[string]string       `json:"metadata,omitempty"`               // 扩展元数据
+	RelatedID   int64                   `json:"related_id,omitempty"`             // 关联ID（如红包ID、转账ID等）
 	
 	// Request context fields (optional)
 	RequestSource    string `json:"request_source,omitempty"`     // 请求来源 (telegram, web, api, admin)
@@ -55,6 +58,7 @@ type FundOperationResult struct {
 	BalanceAfter  decimal.Decimal `json:"balance_after"`  // 操作后余额
 	RawAmount     decimal.Decimal `json:"raw_amount"`     // 原始金额（发送给远程钱包的格式）
 	Successful    bool            `json:"successful"`     // 是否成功
+	Replayed      bool            `json:"replayed"`       // 是否为幂等重放（返回已存在的交易，未再次执行）
 }
 
 // BalanceInfo 余额信息
@@ -83,6 +87,9 @@ type IWalletManager interface {
 	// Use constants.FundOperationBuilder to create the request
 	ProcessFundOperationInTx(ctx context.Context, tx gdb.TX, req *constants.FundOperationRequest) (*FundOperationResult, error)
 
+	// 资金操作预估：解析方向并校验资金类型限制和策略规则，返回执行后的余额，不变动余额
+	QuoteFundOperation(ctx context.Context, req *constants.FundOperationRequest) (*FundOperationQuote, error)
+
 	// 推荐使用：转账操作（双方交易的原子操作）
 	ProcessTransferInTx(ctx context.Context, tx gdb.TX, req *TransferOperationRequest) (*TransferOperationResult, error)
 
@@ -91,6 +98,280 @@ type IWalletManager interface {
 
 	// 推荐使用：使用 FundOperationBuilder 创建资金操作
 	ProcessFundOperationWithBuilder(ctx context.Context, tx gdb.TX, builder *constants.FundOperationBuilder) (*FundOperationResult, error)
+
+	// 增强版交易：使用 TransactionBuilderEnhanced 构建请求，幂等键独立于引用号，支持过期时间、标签和优先级；
+	// 元数据包含 scheduled_at 时进入定时队列
+	CreateTransactionEnhanced(ctx context.Context, req *constants.TransactionRequestEnhanced) (*EnhancedTransactionResult, error)
+	GetTransactionsByTag(ctx context.Context, userID int64, tag string, limit, offset int) ([]*TransactionRecord, error)
+
+	// 交易查询：按代币、方向、资金类型或分类、状态、金额与时间范围、关联实体、交易对手、请求来源、标签和元数据过滤，
+	// 按 (created_at, transaction_id) 键集分页并返回总数
+	SearchTransactions(ctx context.Context, query *TransactionQuery) (*TransactionPage, error)
+
+	// 对账单：流式导出期初余额、逐笔交易（累计余额、手续费、交易对手）和期末余额，支持 CSV 与 JSON Lines
+	ExportStatement(ctx context.Context, userID uint64, tokenSymbol string, from, to time.Time, format StatementFormat, w io.Writer) (*StatementSummary, error)
+
+	// 历史余额：由交易链计算任意时刻的余额；每日快照任务写入各钱包日终余额，供历史查询和月末报表使用
+	GetBalanceAt(ctx context.Context, userID uint64, tokenSymbol string, at time.Time) (decimal.Decimal, error)
+	CreateBalanceSnapshots(ctx context.Context, date time.Time) (int, error)
+	ListBalanceSnapshots(ctx context.Context, date time.Time, tokenSymbol string, limit, offset int) ([]*BalanceSnapshotInfo, error)
+	StartBalanceSnapshotJob(ctx context.Context) error
+	StopBalanceSnapshotJob(ctx context.Context)
+
+	// 审计哈希链：每笔交易记录和状态变更按钱包追加链式哈希；校验器报告第一处断裂，签名检查点可导出供离线核验
+	VerifyAuditChain(ctx context.Context, userID uint64, tokenSymbol string) (*AuditVerificationReport, error)
+	CreateAuditCheckpoint(ctx context.Context) (*AuditCheckpointInfo, error)
+	ExportAuditCheckpoints(ctx context.Context, w io.Writer) (int, error)
+	StartAuditCheckpointJob(ctx context.Context) error
+	StopAuditCheckpointJob(ctx context.Context)
+
+	// 后台调账双人复核：admin_add、admin_deduct、system_adjustment 由操作员提交、其他管理员审批，
+	// 超过金额阈值需要多人审批，审批通过时才执行资金操作；每一步都记录管理员身份
+	SubmitAdjustment(ctx context.Context, req *AdjustmentSubmitRequest) (*AdjustmentInfo, error)
+	ApproveAdjustment(ctx context.Context, requestID uint64, approverID, comment string) (*AdjustmentInfo, error)
+	RejectAdjustment(ctx context.Context, requestID uint64, approverID, reason string) (*AdjustmentInfo, error)
+	GetAdjustment(ctx context.Context, requestID uint64) (*AdjustmentInfo, error)
+	ListAdjustments(ctx context.Context, status AdjustmentStatus, limit, offset int) ([]*AdjustmentInfo, error)
+
+	// 多级佣金：符合 wallet.commission 规则的资金操作完成时，在同一事务中沿推荐链或代理链分配佣金，
+	// 每笔佣金都记录其源交易
+	GetCommissionsBySource(ctx context.Context, sourceTransactionID uint64) ([]*CommissionInfo, error)
+	ListCommissions(ctx context.Context, beneficiaryID uint64, tokenSymbol string, limit, offset int) ([]*CommissionInfo, error)
+
+	// 资金操作钩子：按资金类型或分类注册，在 ProcessFundOperationInTx 的各阶段按 (Priority, 注册顺序) 执行；
+	// 注册了 AfterCommit 钩子的资金类型需通过 RunInTransaction 开启事务
+	RegisterHook(reg *HookRegistration) (uint64, error)
+	UnregisterHook(id uint64) bool
+	RunInTransaction(ctx context.Context, fn func(ctx context.Context, tx gdb.TX) error) error
+
+	// 交易限额：按 wallet.limits 规则在滚动窗口内限制笔数和金额，超出时返回 *VelocityLimitError（含重置时间）
+	GetVelocityUsage(ctx context.Context, userID uint64, tokenSymbol string) ([]*VelocityUsage, error)
+	RebuildVelocityCounters(ctx context.Context, userID uint64, tokenSymbol string) error
+	PurgeVelocityCounters(ctx context.Context) (int64, error)
+
+	// 策略规则：wallet.policies 中的声明式规则在每笔操作校验时执行，命中时返回 *PolicyError；
+	// DryRunPolicies 用历史交易试运行规则，不影响任何数据
+	DryRunPolicies(ctx context.Context, req *PolicyDryRunRequest) (*PolicyDryRunReport, error)
+
+	// API Key：密钥只在创建时返回一次，数据库只保存其哈希；AuthenticateAPIKey 校验 HMAC 签名、
+	// 时间戳、随机数（防重放）和每分钟请求数，权限范围由调用方通过 APIKey.Scope.Authorize 校验
+	CreateAPIKey(ctx context.Context, req *CreateAPIKeyRequest) (*APIKeyCredentials, error)
+	ListAPIKeys(ctx context.Context, includeRevoked bool) ([]*APIKey, error)
+	RevokeAPIKey(ctx context.Context, keyID string) error
+	AuthenticateAPIKey(ctx context.Context, req *APIKeyRequest) (*APIKey, error)
+	PurgeAPIKeyNonces(ctx context.Context) (int64, error)
+
+	// 对账：比较钱包余额与账本最后一笔成功交易的操作后余额，可选校验审计哈希链；
+	// ListUserBalances 只读取已存在的钱包，不会像 GetBalance 那样自动创建
+	Reconcile(ctx context.Context, req *ReconcileRequest) (*ReconciliationReport, error)
+	ListUserBalances(ctx context.Context, userID uint64) ([]*BalanceInfo, error)
+
+	// 订阅：按事件ID顺序推送用户已提交的余额与交易变动（领域事件），lastEventID 大于 0 时先补发之后的事件
+	Subscribe(ctx context.Context, userID uint64, lastEventID int64) (*Subscription, error)
+
+	// 提现地址簿：按网络校验地址格式，支持白名单模式和新地址冷静期
+	AddWithdrawAddress(ctx context.Context, userID uint64, tokenSymbol, address, label string) (*WithdrawAddressInfo, error)
+	RemoveWithdrawAddress(ctx context.Context, userID uint64, addressID uint64) error
+	ListWithdrawAddresses(ctx context.Context, userID uint64, network string) ([]*WithdrawAddressInfo, error)
+	SetWithdrawWhitelistOnly(ctx context.Context, userID uint64, enabled bool) error
+
+	// 定时交易：使用 constants.BuildScheduledTransaction 构建请求，到期后由调度器执行
+	ScheduleTransaction(ctx context.Context, req *constants.TransactionRequestEnhanced) (*ScheduledOperationInfo, error)
+	ListScheduledOperations(ctx context.Context, userID uint64, status constants.TransactionStatus, limit, offset int) ([]*ScheduledOperationInfo, error)
+	CancelScheduledOperation(ctx context.Context, userID uint64, operationID uint64) error
+	RunDueScheduledOperations(ctx context.Context) (int, error)
+	StartScheduler(ctx context.Context) error
+	StopScheduler(ctx context.Context)
+
+	// 循环交易：支持固定间隔或 cron 调度、执行次数限制、暂停/恢复/取消，每次执行派生独立业务ID
+	CreateRecurringTransaction(ctx context.Context, req *constants.TransactionRequestEnhanced) (*RecurringOperationInfo, error)
+	ListRecurringTransactions(ctx context.Context, userID uint64, status RecurringStatus, limit, offset int) ([]*RecurringOperationInfo, error)
+	ListRecurringRuns(ctx context.Context, userID uint64, recurringID uint64, limit, offset int) ([]*RecurringRunInfo, error)
+	PauseRecurringTransaction(ctx context.Context, userID uint64, recurringID uint64) error
+	ResumeRecurringTransaction(ctx context.Context, userID uint64, recurringID uint64) error
+	CancelRecurringTransaction(ctx context.Context, userID uint64, recurringID uint64) error
+	RunDueRecurringTransactions(ctx context.Context) (int, error)
+
+	// 批量转账：整批预校验（含发送方总余额），单事务执行，支持全部成功或尽力而为模式，按批次ID幂等
+	ProcessBatchTransferInTx(ctx context.Context, tx gdb.TX, req *BatchTransferRequest) (*BatchTransferResult, error)
+
+	// 批量发放：CSV/JSON 输入，先校验全部行（带行号），分批执行并可断点续传，行级幂等，可输出结果文件
+	RunBulkPayout(ctx context.Context, req *BulkPayoutRequest) (*BulkPayoutResult, error)
+
+	// Webhook 回调：WithCallback 设置的回调与业务操作在同一事务写入出站表，异步签名投递，失败指数退避重试后进入死信
+	DeliverPendingWebhooks(ctx context.Context) (int, error)
+	StartWebhookDispatcher(ctx context.Context) error
+	StopWebhookDispatcher(ctx context.Context)
+	ListWebhookDeadLetters(ctx context.Context, limit, offset int) ([]*WebhookDeadLetterInfo, error)
+	RequeueWebhookDeadLetter(ctx context.Context, deadLetterID uint64) error
+
+	// 领域事件：余额变动、转账、状态变更事件与业务操作在同一事务写入出站表，由中继按钱包内顺序至少一次发布
+	// 中继只在进程内互斥，多实例部署时只能在一个实例上运行 StartEventRelay / RelayPendingEvents
+	SetEventPublisher(publisher Publisher)
+	RelayPendingEvents(ctx context.Context) (int, error)
+	StartEventRelay(ctx context.Context) error
+	StopEventRelay(ctx context.Context)
+}
+
+// WithdrawAddressInfo 提现地址簿条目
+type WithdrawAddressInfo struct {
+	ID            uint64 `json:"id"`             // 地址记录ID
+	UserID        uint64 `json:"user_id"`        // 用户ID
+	Network       string `json:"network"`        // 所属网络/链
+	TokenStandard string `json:"token_standard"` // 代币标准
+	Address       string `json:"address"`        // 提现地址
+	Label         string `json:"label"`          // 地址备注
+	ActivatedAt   string `json:"activated_at"`   // 冷静期结束时间
+	Active        bool   `json:"active"`         // 是否已过冷静期
+	CreatedAt     string `json:"created_at"`     // 创建时间
+}
+
+// ScheduledOperationInfo 定时操作信息
+type ScheduledOperationInfo struct {
+	ID            uint64                      `json:"id"`             // 定时操作ID
+	UserID        uint64                      `json:"user_id"`        // 用户ID
+	TokenSymbol   string                      `json:"token_symbol"`   // 代币符号
+	Amount        decimal.Decimal             `json:"amount"`         // 金额
+	FundType      constants.FundType          `json:"fund_type"`      // 资金类型
+	BusinessID    string                      `json:"business_id"`    // 执行时使用的业务ID（幂等）
+	Reference     string                      `json:"reference"`      // 引用号
+	Priority      int                         `json:"priority"`       // 优先级
+	Status        constants.TransactionStatus `json:"status"`         // 状态
+	ScheduledAt   string                      `json:"scheduled_at"`   // 计划执行时间
+	ExpireAt      string                      `json:"expire_at"`      // 过期时间
+	Attempts      int                         `json:"attempts"`       // 已尝试次数
+	LastError     string                      `json:"last_error"`     // 最近一次错误
+	TransactionID uint64                      `json:"transaction_id"` // 执行成功后的交易ID
+	ExecutedAt    string                      `json:"executed_at"`    // 执行时间
+	Tags          []string                    `json:"tags,omitempty"` // 标签
+}
+
+// BatchTransferMode 批量转账执行模式
+type BatchTransferMode string
+
+const (
+	BatchModeAllOrNothing BatchTransferMode = "all_or_nothing" // 任一笔失败则整批失败（调用方回滚事务）
+	BatchModeBestEffort   BatchTransferMode = "best_effort"    // 失败的单笔回滚到保存点，其余继续执行
+)
+
+// BatchTransferRequest 批量转账请求
+type BatchTransferRequest struct {
+	BatchID     string                                  `json:"batch_id"`    // 批次ID（幂等键），为空时使用请求共享的 batch_reference
+	Mode        BatchTransferMode                       `json:"mode"`        // 执行模式，默认 all_or_nothing
+	Requests    []*constants.TransactionRequestEnhanced `json:"requests"`    // constants.BuildBatchTransfer 构建的转出/转入请求
+	Description string                                  `json:"description"` // 默认描述
+
+	// Request context fields (optional)
+	RequestSource    string `json:"request_source,omitempty"`     // 请求来源 (telegram, web, api, admin)
+	RequestIP        string `json:"request_ip,omitempty"`         // 用户IP地址
+	RequestUserAgent string `json:"request_user_agent,omitempty"` // 用户User-Agent
+}
+
+// BatchTransferLegResult 批量转账单笔结果
+type BatchTransferLegResult struct {
+	Index             int             `json:"index"`               // 批次内序号
+	ToUserID          uint64          `json:"to_user_id"`          // 接收方用户ID
+	TokenSymbol       string          `json:"token_symbol"`        // 代币符号
+	Amount            decimal.Decimal `json:"amount"`              // 转账金额
+	Success           bool            `json:"success"`             // 是否成功
+	FromTransactionID string          `json:"from_transaction_id"` // 发送方交易ID
+	ToTransactionID   string          `json:"to_transaction_id"`   // 接收方交易ID
+	FromBalanceAfter  decimal.Decimal `json:"from_balance_after"`  // 发送方转账后余额
+	ToBalanceAfter    decimal.Decimal `json:"to_balance_after"`    // 接收方转账后余额
+	Error             string          `json:"error,omitempty"`     // 失败原因
+}
+
+// BatchTransferResult 批量转账结果
+type BatchTransferResult struct {
+	BatchID    string                    `json:"batch_id"`     // 批次ID
+	FromUserID uint64                    `json:"from_user_id"` // 发送方用户ID
+	Mode       BatchTransferMode         `json:"mode"`         // 执行模式
+	Succeeded  int                       `json:"succeeded"`    // 成功笔数
+	Failed     int                       `json:"failed"`       // 失败笔数
+	Duplicate  bool                      `json:"duplicate"`    // 是否为重复提交（返回首次执行结果）
+	Legs       []*BatchTransferLegResult `json:"legs"`         // 逐笔结果
+}
+
+// BulkPayoutRequest 批量发放请求
+type BulkPayoutRequest struct {
+	JobID           string             `json:"job_id"`            // 任务ID（断点续传与行级幂等的依据）
+	Format          PayoutFormat       `json:"format"`            // 输入格式: csv, json
+	Input           io.Reader          `json:"-"`                 // 输入内容
+	DefaultFundType constants.FundType `json:"default_fund_type"` // 行未指定资金类型时使用；为空时每行都必须指定资金类型
+	ChunkSize       int                `json:"chunk_size"`        // 每个数据库事务处理的行数，默认 100
+	ResultWriter    io.Writer          `json:"-"`                 // 结果文件输出（CSV，可选）
+	RequestSource   string             `json:"request_source"`    // 请求来源，默认 admin
+}
+
+// BulkPayoutResult 批量发放结果
+type BulkPayoutResult struct {
+	JobID     string `json:"job_id"`     // 任务ID
+	TotalRows int    `json:"total_rows"` // 总行数
+	Resumed   int    `json:"resumed"`    // 本次调用前已处理的行数
+	Succeeded int    `json:"succeeded"`  // 成功行数（含之前的调用）
+	Failed    int    `json:"failed"`     // 失败行数（含之前的调用）
+	Status    string `json:"status"`     // 任务状态
+}
+
+// WebhookEvent Webhook 事件负载
+type WebhookEvent struct {
+	Event         string `json:"event"`                    // 事件类型: transaction.completed, transaction.failed
+	BusinessID    string `json:"business_id"`              // 业务ID
+	TransactionID string `json:"transaction_id,omitempty"` // 交易ID（成功时）
+	UserID        uint64 `json:"user_id"`                  // 用户ID
+	TokenSymbol   string `json:"token_symbol"`             // 代币符号
+	Amount        string `json:"amount"`                   // 金额
+	FundType      string `json:"fund_type"`                // 资金类型
+	Status        string `json:"status"`                   // 交易状态
+	Reference     string `json:"reference,omitempty"`      // 交易引用号
+	Error         string `json:"error,omitempty"`          // 失败原因（失败时）
+	OccurredAt    string `json:"occurred_at"`              // 事件发生时间 (RFC3339)
+}
+
+// WebhookDeadLetterInfo Webhook 死信信息
+type WebhookDeadLetterInfo struct {
+	ID             uint64 `json:"id"`               // 死信ID
+	Event          string `json:"event"`            // 事件类型
+	BusinessID     string `json:"business_id"`      // 业务ID
+	URL            string `json:"url"`              // 回调地址
+	Method         string `json:"method"`           // 回调方法
+	Payload        string `json:"payload"`          // 事件负载 (JSON)
+	Attempts       int    `json:"attempts"`         // 已尝试次数
+	LastStatusCode int    `json:"last_status_code"` // 最后一次响应状态码
+	LastError      string `json:"last_error"`       // 最后一次失败原因
+	CreatedAt      string `json:"created_at"`       // 进入死信时间
+}
+
+// RecurringOperationInfo 循环操作信息
+type RecurringOperationInfo struct {
+	ID                  uint64             `json:"id"`                   // 循环操作ID
+	UserID              uint64             `json:"user_id"`              // 用户ID
+	TokenSymbol         string             `json:"token_symbol"`         // 代币符号
+	Amount              decimal.Decimal    `json:"amount"`               // 每次执行金额
+	FundType            constants.FundType `json:"fund_type"`            // 资金类型
+	BusinessID          string             `json:"business_id"`          // 基础业务ID
+	ScheduleType        string             `json:"schedule_type"`        // 调度类型: interval, cron
+	ScheduleSpec        string             `json:"schedule_spec"`        // 调度规则
+	TotalCount          int                `json:"total_count"`          // 计划执行总次数（0 表示不限）
+	RemainingCount      int                `json:"remaining_count"`      // 剩余执行次数
+	RunCount            int                `json:"run_count"`            // 已执行次数（含失败）
+	ConsecutiveFailures int                `json:"consecutive_failures"` // 连续失败次数
+	Status              RecurringStatus    `json:"status"`               // 状态
+	NextRunAt           string             `json:"next_run_at"`          // 下次执行时间
+	LastRunAt           string             `json:"last_run_at"`          // 最近一次执行时间
+}
+
+// RecurringRunInfo 循环操作单次执行记录
+type RecurringRunInfo struct {
+	ID            uint64                      `json:"id"`             // 记录ID
+	RecurringID   uint64                      `json:"recurring_id"`   // 循环操作ID
+	Occurrence    int                         `json:"occurrence"`     // 第几次执行
+	BusinessID    string                      `json:"business_id"`    // 本次执行的业务ID
+	Status        constants.TransactionStatus `json:"status"`         // 执行结果
+	TransactionID uint64                      `json:"transaction_id"` // 成功时的交易ID
+	Error         string                      `json:"error"`          // 失败原因
+	PlannedAt     string                      `json:"planned_at"`     // 计划执行时间
+	ExecutedAt    string                      `json:"executed_at"`    // 实际执行时间
 }
 
 // TransferOperationResult 转账操作结果
@@ -115,6 +396,161 @@ type ITransactionManager interface {
 
 	// 查询交易历史
 	GetUserTransactionHistory(ctx context.Context, userID int64, fundType constants.FundType, limit, offset int) ([]*TransactionRecord, error)
+
+	// 按标签查询交易
+	GetTransactionsByTag(ctx context.Context, userID int64, tag string, limit, offset int) ([]*TransactionRecord, error)
+
+	// 多条件查询交易（键集分页）
+	SearchTransactions(ctx context.Context, query *TransactionQuery) (*TransactionPage, error)
+}
+
+// TransactionQuery 交易查询条件，零值字段不参与过滤
+type TransactionQuery struct {
+	UserID            int64                         `json:"user_id,omitempty"`             // 用户ID
+	TokenID           int64                         `json:"token_id,omitempty"`            // 代币ID
+	TokenSymbol       string                        `json:"token_symbol,omitempty"`        // 代币符号
+	Direction         constants.FundDirection       `json:"direction,omitempty"`           // 资金方向
+	FundTypes         []constants.FundType          `json:"fund_types,omitempty"`          // 资金类型（任一匹配）
+	FundCategory      string                        `json:"fund_category,omitempty"`       // 资金类型分类（与 FundTypes 同时设置时取交集）
+	Statuses          []constants.TransactionStatus `json:"statuses,omitempty"`            // 交易状态（任一匹配）
+	MinAmount         string                        `json:"min_amount,omitempty"`          // 最小金额（含）
+	MaxAmount         string                        `json:"max_amount,omitempty"`          // 最大金额（含）
+	StartTime         *time.Time                    `json:"start_time,omitempty"`          // 开始时间（含）
+	EndTime           *time.Time                    `json:"end_time,omitempty"`            // 结束时间（不含）
+	RelatedEntityID   int64                         `json:"related_entity_id,omitempty"`   // 关联实体ID
+	RelatedEntityType string                        `json:"related_entity_type,omitempty"` // 关联实体类型
+	CounterpartyID    int64                         `json:"counterparty_id,omitempty"`     // 交易对手（目标用户ID）
+	RequestSource     string                        `json:"request_source,omitempty"`      // 请求来源
+	Tags              []string                      `json:"tags,omitempty"`                // 标签（必须全部包含）
+	MetadataKeys      []string                      `json:"metadata_keys,omitempty"`       // 请求元数据必须包含的键
+	Metadata          map[string]string             `json:"metadata,omitempty"`            // 请求元数据键值必须相等
+	Ascending         bool                          `json:"ascending,omitempty"`           // 按时间正序（默认倒序）
+	Cursor            string                        `json:"cursor,omitempty"`              // 上一页返回的 NextCursor
+	Limit             int                           `json:"limit,omitempty"`               // 每页条数（默认20，最大200）
+}
+
+// StatementSummary 对账单汇总
+type StatementSummary struct {
+	UserID           uint64          `json:"user_id"`           // 用户ID
+	TokenSymbol      string          `json:"token_symbol"`      // 代币符号
+	OpeningBalance   decimal.Decimal `json:"opening_balance"`   // 期初余额
+	ClosingBalance   decimal.Decimal `json:"closing_balance"`   // 期末余额（期初余额加减区间内全部交易）
+	TotalIn          decimal.Decimal `json:"total_in"`          // 区间内入账合计
+	TotalOut         decimal.Decimal `json:"total_out"`         // 区间内出账合计
+	TotalFees        decimal.Decimal `json:"total_fees"`        // 区间内手续费合计
+	TransactionCount int             `json:"transaction_count"` // 交易笔数
+}
+
+// BalanceSnapshotInfo 日终余额快照
+type BalanceSnapshotInfo struct {
+	UserID            uint64          `json:"user_id"`             // 用户ID
+	TokenSymbol       string          `json:"token_symbol"`        // 代币符号
+	Date              string          `json:"date"`                // 快照日期 (YYYY-MM-DD)
+	Balance           decimal.Decimal `json:"balance"`             // 日终可用余额
+	LastTransactionID uint64          `json:"last_transaction_id"` // 截至日终的最后一笔交易ID
+}
+
+// AdjustmentSubmitRequest 后台调账申请
+type AdjustmentSubmitRequest struct {
+	OperatorID    string                  `json:"operator_id"`              // 提交人（管理员标识）
+	UserID        uint64                  `json:"user_id"`                  // 被调账用户ID
+	TokenSymbol   string                  `json:"token_symbol"`             // 代币符号
+	FundType      constants.FundType      `json:"fund_type"`                // admin_add、admin_deduct 或 system_adjustment
+	Direction     constants.FundDirection `json:"direction,omitempty"`      // 资金方向，system_adjustment 必填（in 或 out）
+	Amount        decimal.Decimal         `json:"amount"`                   // 调账金额（绝对值）
+	Reason        string                  `json:"reason"`                   // 调账原因（必填）
+	AttachmentRef string                  `json:"attachment_ref,omitempty"` // 附件引用（工单号、文件地址等）
+	Metadata      map[string]string       `json:"metadata,omitempty"`       // 扩展元数据，执行时写入交易记录
+}
+
+// AdjustmentInfo 后台调账申请
+type AdjustmentInfo struct {
+	ID                uint64                  `json:"id"`                       // 申请ID
+	UserID            uint64                  `json:"user_id"`                  // 被调账用户ID
+	TokenSymbol       string                  `json:"token_symbol"`             // 代币符号
+	FundType          constants.FundType      `json:"fund_type"`                // 资金类型
+	Direction         constants.FundDirection `json:"direction"`                // 资金方向
+	Amount            decimal.Decimal         `json:"amount"`                   // 调账金额
+	Reason            string                  `json:"reason"`                   // 调账原因
+	AttachmentRef     string                  `json:"attachment_ref,omitempty"` // 附件引用
+	Metadata          map[string]string       `json:"metadata,omitempty"`       // 扩展元数据
+	RequiredApprovals int                     `json:"required_approvals"`       // 所需审批人数
+	ApprovalCount     int                     `json:"approval_count"`           // 已审批人数
+	Status            AdjustmentStatus        `json:"status"`                   // 状态
+	SubmittedBy       string                  `json:"submitted_by"`             // 提交人
+	TransactionID     uint64                  `json:"transaction_id,omitempty"` // 执行后的交易ID
+	CreatedAt         string                  `json:"created_at"`               // 提交时间
+	CompletedAt       string                  `json:"completed_at,omitempty"`   // 执行或驳回时间
+	Actions           []*AdjustmentActionInfo `json:"actions,omitempty"`        // 审批记录（仅 GetAdjustment 返回）
+}
+
+// CommissionInfo 佣金记录
+type CommissionInfo struct {
+	ID                  uint64             `json:"id"`                    // 佣金记录ID
+	SourceTransactionID uint64             `json:"source_transaction_id"` // 产生佣金的源交易ID
+	SourceUserID        uint64             `json:"source_user_id"`        // 源交易用户ID
+	SourceFundType      constants.FundType `json:"source_fund_type"`      // 源交易资金类型
+	SourceAmount        decimal.Decimal    `json:"source_amount"`         // 源交易金额
+	TokenSymbol         string             `json:"token_symbol"`          // 代币符号
+	BeneficiaryID       uint64             `json:"beneficiary_id"`        // 受益人用户ID
+	Level               int                `json:"level"`                 // 层级（1 为直接上级）
+	Chain               string             `json:"chain"`                 // 关系链：referral 或 agent
+	Rate                decimal.Decimal    `json:"rate"`                  // 佣金比例
+	Amount              decimal.Decimal    `json:"amount"`                // 佣金金额（已应用上限）
+	FundType            constants.FundType `json:"fund_type"`             // 佣金入账资金类型
+	TransactionID       uint64             `json:"transaction_id"`        // 佣金入账交易ID
+	CreatedAt           string             `json:"created_at"`            // 分配时间
+}
+
+// AdjustmentActionInfo 调账审批记录
+type AdjustmentActionInfo struct {
+	AdminID   string `json:"admin_id"`          // 管理员标识
+	Action    string `json:"action"`            // submit、approve 或 reject
+	Comment   string `json:"comment,omitempty"` // 备注或驳回原因
+	CreatedAt string `json:"created_at"`        // 操作时间
+}
+
+// AuditVerificationReport 审计哈希链校验结果
+type AuditVerificationReport struct {
+	UserID              uint64 `json:"user_id"`                         // 用户ID
+	TokenSymbol         string `json:"token_symbol"`                    // 代币符号
+	ChainID             string `json:"chain_id"`                        // 哈希链ID
+	Valid               bool   `json:"valid"`                           // 是否完整
+	LastValidSequence   uint64 `json:"last_valid_sequence"`             // 校验通过的最后序号
+	LastValidHash       string `json:"last_valid_hash,omitempty"`       // 校验通过的最后哈希
+	BrokenSequence      uint64 `json:"broken_sequence,omitempty"`       // 第一处断裂的序号
+	BrokenTransactionID uint64 `json:"broken_transaction_id,omitempty"` // 第一处断裂涉及的交易ID
+	Reason              string `json:"reason,omitempty"`                // 断裂原因（AuditBroken* 常量）
+}
+
+// AuditCheckpointInfo 审计检查点
+type AuditCheckpointInfo struct {
+	ID          uint64    `json:"id"`            // 检查点ID
+	LastEntryID uint64    `json:"last_entry_id"` // 覆盖的最后一条审计记录ID
+	EntryCount  int64     `json:"entry_count"`   // 本检查点新增覆盖的审计记录数
+	PrevRoot    string    `json:"prev_root"`     // 上一个检查点的根哈希
+	Root        string    `json:"root"`          // 根哈希
+	PublicKey   string    `json:"public_key"`    // Ed25519 公钥 (base64)
+	Signature   string    `json:"signature"`     // 对 "last_entry_id|entry_count|prev_root|root" 的签名 (base64)
+	CreatedAt   time.Time `json:"created_at"`    // 生成时间
+}
+
+// TransactionPage 交易查询结果页
+type TransactionPage struct {
+	Records    []*TransactionRecord `json:"records"`               // 当前页记录
+	Total      int                  `json:"total"`                 // 满足条件的总数（不受游标影响）
+	NextCursor string               `json:"next_cursor,omitempty"` // 下一页游标，没有更多记录时为空
+	HasMore    bool                 `json:"has_more"`              // 是否还有更多记录
+}
+
+// EnhancedTransactionResult 增强版交易创建结果
+type EnhancedTransactionResult struct {
+	TransactionID        int64    `json:"transaction_id"`                   // 交易ID（立即执行时）
+	Duplicate            bool     `json:"duplicate"`                        // 是否为重复提交（返回首次创建的交易）
+	Queued               bool     `json:"queued"`                           // 是否已进入定时队列
+	ScheduledOperationID uint64   `json:"scheduled_operation_id,omitempty"` // 定时操作ID（进入队列时）
+	Priority             int      `json:"priority"`                         // 优先级
+	Tags                 []string `json:"tags,omitempty"`                   // 标签
 }
 
 // TransactionRecord 交易记录
@@ -123,6 +559,7 @@ type TransactionRecord struct {
 	UserID      int64                       `json:"user_id"`
 	WalletID    int64                       `json:"wallet_id"`
 	TokenID     int64                       `json:"token_id"`
+	TokenSymbol string                      `json:"token_symbol"`
 	Amount      string                      `json:"amount"`
 	FundType    constants.FundType          `json:"fund_type"`
 	Direction   constants.FundDirection     `json:"direction"`
@@ -133,4 +570,10 @@ type TransactionRecord struct {
 	Metadata    map[string]interface{}      `json:"metadata"`
 	CreatedAt   string                      `json:"created_at"`
 	UpdatedAt   string                      `json:"updated_at"`
+
+	IdempotencyKey string   `json:"idempotency_key,omitempty"`
+	Priority       int      `json:"priority,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+	RiskScore      int      `json:"risk_score,omitempty"`
+	RiskDecision   string   `json:"risk_decision,omitempty"` // allow 或 challenge；未评估时为空
 }
@@ -0,0 +1,344 @@
+package logic
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// AddressFamily 地址格式族
+type AddressFamily string
+
+const (
+	AddressFamilyEVM     AddressFamily = "evm"     // 以太坊及兼容链 (EIP-55 校验)
+	AddressFamilyTron    AddressFamily = "tron"    // 波场 (base58check, 0x41 前缀)
+	AddressFamilyBitcoin AddressFamily = "bitcoin" // 比特币 (base58check / bech32 / bech32m)
+	AddressFamilySolana  AddressFamily = "solana"  // Solana (base58 32字节公钥)
+	AddressFamilyUnknown AddressFamily = "unknown" // 未知网络，仅做基础校验
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var (
+	evmAddressPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+
+	// evmNetworks EVM 兼容网络名称（小写）
+	evmNetworks = map[string]bool{
+		"ethereum": true, "eth": true, "erc20": true,
+		"bsc": true, "bnb": true, "bep20": true, "binance smart chain": true,
+		"polygon": true, "matic": true, "arbitrum": true, "optimism": true,
+		"avalanche": true, "avax": true, "base": true, "linea": true,
+	}
+)
+
+// ResolveAddressFamily 根据代币所属网络和代币标准确定地址格式族
+func ResolveAddressFamily(network, tokenStandard string) AddressFamily {
+	network = strings.ToLower(strings.TrimSpace(network))
+	standard := strings.ToUpper(strings.TrimSpace(tokenStandard))
+
+	switch standard {
+	case "ERC20", "BEP20":
+		return AddressFamilyEVM
+	case "TRC20", "TRC10":
+		return AddressFamilyTron
+	case "SPL":
+		return AddressFamilySolana
+	}
+
+	switch {
+	case evmNetworks[network]:
+		return AddressFamilyEVM
+	case network == "tron" || network == "trx":
+		return AddressFamilyTron
+	case network == "bitcoin" || network == "btc":
+		return AddressFamilyBitcoin
+	case network == "solana" || network == "sol":
+		return AddressFamilySolana
+	default:
+		return AddressFamilyUnknown
+	}
+}
+
+// ValidateAddress 按网络/代币标准校验提现地址格式
+func ValidateAddress(network, tokenStandard, address string) error {
+	if address == "" {
+		return fmt.Errorf("address is required")
+	}
+	if strings.TrimSpace(address) != address || strings.ContainsAny(address, " \t\r\n") {
+		return fmt.Errorf("address contains whitespace")
+	}
+
+	switch ResolveAddressFamily(network, tokenStandard) {
+	case AddressFamilyEVM:
+		return validateEVMAddress(address)
+	case AddressFamilyTron:
+		return validateTronAddress(address)
+	case AddressFamilyBitcoin:
+		return validateBitcoinAddress(address)
+	case AddressFamilySolana:
+		return validateSolanaAddress(address)
+	default:
+		if len(address) > 128 {
+			return fmt.Errorf("address exceeds 128 characters")
+		}
+		return nil
+	}
+}
+
+// NormalizeAddress 将已通过校验的地址转换为规范格式，用于地址簿存储和比较
+// EVM 地址统一为 EIP-55 校验格式，bech32 地址统一为小写，其他格式区分大小写保持原样
+func NormalizeAddress(network, tokenStandard, address string) string {
+	switch ResolveAddressFamily(network, tokenStandard) {
+	case AddressFamilyEVM:
+		if evmAddressPattern.MatchString(address) {
+			return toChecksumAddress(address[2:])
+		}
+	case AddressFamilyBitcoin:
+		lower := strings.ToLower(address)
+		if strings.HasPrefix(lower, "bc1") || strings.HasPrefix(lower, "tb1") {
+			return lower
+		}
+	}
+	return address
+}
+
+// validateEVMAddress 校验EVM地址，大小写混合时按 EIP-55 校验
+func validateEVMAddress(address string) error {
+	if !evmAddressPattern.MatchString(address) {
+		return fmt.Errorf("invalid EVM address format: %s", address)
+	}
+
+	body := address[2:]
+	if body == strings.ToLower(body) || body == strings.ToUpper(body) {
+		// 全小写或全大写地址不携带校验信息
+		return nil
+	}
+
+	if toChecksumAddress(body) != address {
+		return fmt.Errorf("invalid EIP-55 checksum: %s", address)
+	}
+	return nil
+}
+
+// toChecksumAddress 计算 EIP-55 校验格式地址
+func toChecksumAddress(body string) string {
+	lower := strings.ToLower(body)
+	hash := hex.EncodeToString(keccak256([]byte(lower)))
+
+	result := make([]byte, len(lower))
+	for i := 0; i < len(lower); i++ {
+		c := lower[i]
+		if c >= 'a' && c <= 'f' && hash[i] >= '8' {
+			c -= 'a' - 'A'
+		}
+		result[i] = c
+	}
+	return "0x" + string(result)
+}
+
+// validateTronAddress 校验波场地址
+func validateTronAddress(address string) error {
+	payload, err := decodeBase58Check(address)
+	if err != nil {
+		return fmt.Errorf("invalid Tron address: %v", err)
+	}
+	if len(payload) != 21 || payload[0] != 0x41 {
+		return fmt.Errorf("invalid Tron address prefix: %s", address)
+	}
+	return nil
+}
+
+// validateBitcoinAddress 校验比特币地址（P2PKH/P2SH/SegWit/Taproot）
+func validateBitcoinAddress(address string) error {
+	lower := strings.ToLower(address)
+	if strings.HasPrefix(lower, "bc1") || strings.HasPrefix(lower, "tb1") {
+		return validateSegwitAddress(address)
+	}
+
+	payload, err := decodeBase58Check(address)
+	if err != nil {
+		return fmt.Errorf("invalid Bitcoin address: %v", err)
+	}
+	if len(payload) != 21 {
+		return fmt.Errorf("invalid Bitcoin address length: %s", address)
+	}
+	switch payload[0] {
+	case 0x00, 0x05, 0x6f, 0xc4: // mainnet P2PKH/P2SH, testnet P2PKH/P2SH
+		return nil
+	default:
+		return fmt.Errorf("invalid Bitcoin address version: %s", address)
+	}
+}
+
+// validateSolanaAddress 校验Solana地址
+func validateSolanaAddress(address string) error {
+	decoded, err := decodeBase58(address)
+	if err != nil {
+		return fmt.Errorf("invalid Solana address: %v", err)
+	}
+	if len(decoded) != 32 {
+		return fmt.Errorf("invalid Solana address length: %s", address)
+	}
+	return nil
+}
+
+// decodeBase58 解码 base58 字符串
+func decodeBase58(s string) ([]byte, error) {
+	if s == "" {
+		return nil, fmt.Errorf("empty base58 string")
+	}
+
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for _, r := range s {
+		idx := strings.IndexRune(base58Alphabet, r)
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", r)
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(idx)))
+	}
+
+	decoded := n.Bytes()
+	leadingZeros := 0
+	for leadingZeros < len(s) && s[leadingZeros] == '1' {
+		leadingZeros++
+	}
+	return append(make([]byte, leadingZeros), decoded...), nil
+}
+
+// decodeBase58Check 解码 base58check 并校验4字节双SHA256校验和
+func decodeBase58Check(s string) ([]byte, error) {
+	decoded, err := decodeBase58(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) < 5 {
+		return nil, fmt.Errorf("base58check payload too short")
+	}
+
+	payload, checksum := decoded[:len(decoded)-4], decoded[len(decoded)-4:]
+	first := sha256.Sum256(payload)
+	second := sha256.Sum256(first[:])
+	for i := 0; i < 4; i++ {
+		if second[i] != checksum[i] {
+			return nil, fmt.Errorf("base58check checksum mismatch")
+		}
+	}
+	return payload, nil
+}
+
+const (
+	bech32Charset   = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	bech32Const     = 1
+	bech32mConst    = 0x2bc830a3
+	bech32MaxLength = 90
+)
+
+// validateSegwitAddress 校验 bech32(v0) / bech32m(v1+) 隔离见证地址
+func validateSegwitAddress(address string) error {
+	if len(address) > bech32MaxLength {
+		return fmt.Errorf("segwit address too long")
+	}
+	if strings.ToLower(address) != address && strings.ToUpper(address) != address {
+		return fmt.Errorf("segwit address has mixed case")
+	}
+	address = strings.ToLower(address)
+
+	sep := strings.LastIndexByte(address, '1')
+	if sep < 1 || sep+7 > len(address) {
+		return fmt.Errorf("invalid segwit separator position")
+	}
+	hrp, dataPart := address[:sep], address[sep+1:]
+	if hrp != "bc" && hrp != "tb" {
+		return fmt.Errorf("invalid segwit human-readable part: %s", hrp)
+	}
+
+	data := make([]byte, len(dataPart))
+	for i := 0; i < len(dataPart); i++ {
+		idx := strings.IndexByte(bech32Charset, dataPart[i])
+		if idx < 0 {
+			return fmt.Errorf("invalid bech32 character %q", dataPart[i])
+		}
+		data[i] = byte(idx)
+	}
+
+	version := data[0]
+	if version > 16 {
+		return fmt.Errorf("invalid witness version %d", version)
+	}
+	expectedConst := uint32(bech32Const)
+	if version > 0 {
+		expectedConst = bech32mConst
+	}
+	if bech32Polymod(append(bech32HrpExpand(hrp), data...)) != expectedConst {
+		return fmt.Errorf("bech32 checksum mismatch")
+	}
+
+	program, err := convertBits(data[1:len(data)-6], 5, 8, false)
+	if err != nil {
+		return err
+	}
+	if len(program) < 2 || len(program) > 40 {
+		return fmt.Errorf("invalid witness program length %d", len(program))
+	}
+	if version == 0 && len(program) != 20 && len(program) != 32 {
+		return fmt.Errorf("invalid v0 witness program length %d", len(program))
+	}
+	return nil
+}
+
+// bech32Polymod 计算 bech32 校验多项式
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+// bech32HrpExpand 展开 human-readable part 用于校验计算
+func bech32HrpExpand(hrp string) []byte {
+	result := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		result = append(result, hrp[i]>>5)
+	}
+	result = append(result, 0)
+	for i := 0; i < len(hrp); i++ {
+		result = append(result, hrp[i]&31)
+	}
+	return result
+}
+
+// convertBits 在不同位宽之间转换数据
+func convertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	acc, bits := uint32(0), uint(0)
+	maxv := uint32(1)<<toBits - 1
+	result := make([]byte, 0, len(data)*int(fromBits)/int(toBits)+1)
+	for _, v := range data {
+		acc = acc<<fromBits | uint32(v)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			result = append(result, byte(acc>>bits&maxv))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			result = append(result, byte(acc<<(toBits-bits)&maxv))
+		}
+	} else if bits >= fromBits || acc<<(toBits-bits)&maxv != 0 {
+		return nil, fmt.Errorf("invalid witness program padding")
+	}
+	return result, nil
+}
@@ -0,0 +1,98 @@
+package logic
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestKeccak256(t *testing.T) {
+	got := hex.EncodeToString(keccak256(nil))
+	want := "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"
+	if got != want {
+		t.Errorf("keccak256(\"\") = %s, want %s", got, want)
+	}
+}
+
+func TestResolveAddressFamily(t *testing.T) {
+	tests := []struct {
+		network  string
+		standard string
+		expected AddressFamily
+	}{
+		{"Ethereum", "native", AddressFamilyEVM},
+		{"Tron", "TRC20", AddressFamilyTron},
+		{"BSC", "BEP20", AddressFamilyEVM},
+		{"Bitcoin", "native", AddressFamilyBitcoin},
+		{"Solana", "SPL", AddressFamilySolana},
+		{"TON", "native", AddressFamilyUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := ResolveAddressFamily(tt.network, tt.standard); got != tt.expected {
+			t.Errorf("ResolveAddressFamily(%s, %s) = %s, want %s", tt.network, tt.standard, got, tt.expected)
+		}
+	}
+}
+
+func TestValidateAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		network  string
+		standard string
+		address  string
+		valid    bool
+	}{
+		{"evm checksum", "Ethereum", "ERC20", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", true},
+		{"evm checksum 2", "Ethereum", "ERC20", "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359", true},
+		{"evm lowercase", "BSC", "BEP20", "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", true},
+		{"evm bad checksum", "Ethereum", "ERC20", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", false},
+		{"evm too short", "Ethereum", "ERC20", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA", false},
+		{"tron valid", "Tron", "TRC20", "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", true},
+		{"tron bad checksum", "Tron", "TRC20", "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6u", false},
+		{"tron evm address", "Tron", "TRC20", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", false},
+		{"btc p2pkh", "Bitcoin", "native", "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", true},
+		{"btc p2sh", "Bitcoin", "native", "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", true},
+		{"btc bech32", "Bitcoin", "native", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", true},
+		{"btc bech32m", "Bitcoin", "native", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", true},
+		{"btc bech32 bad checksum", "Bitcoin", "native", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5", false},
+		{"btc v1 with bech32 checksum", "Bitcoin", "native", "bc1pw508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r3zarvary0c5xw7k7grplx", false},
+		{"solana valid", "Solana", "SPL", "So11111111111111111111111111111111111111112", true},
+		{"solana invalid char", "Solana", "SPL", "So1111111111111111111111111111111111111111O", false},
+		{"empty", "Ethereum", "ERC20", "", false},
+		{"whitespace", "Unknown", "", " abc", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAddress(tt.network, tt.standard, tt.address)
+			if tt.valid && err != nil {
+				t.Errorf("expected valid address, got error: %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Errorf("expected invalid address %s", tt.address)
+			}
+		})
+	}
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		network  string
+		standard string
+		address  string
+		expected string
+	}{
+		{"BSC", "BEP20", "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+		{"Ethereum", "ERC20", "0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+		{"Ethereum", "ERC20", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+		{"Bitcoin", "native", "BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"},
+		{"Bitcoin", "native", "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"},
+		{"Solana", "SPL", "So11111111111111111111111111111111111111112", "So11111111111111111111111111111111111111112"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeAddress(tt.network, tt.standard, tt.address); got != tt.expected {
+			t.Errorf("NormalizeAddress(%s, %s) = %s, want %s", tt.network, tt.address, got, tt.expected)
+		}
+	}
+}
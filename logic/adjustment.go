@@ -0,0 +1,67 @@
+package logic
+
+import (
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/constants"
+)
+
+// AdjustmentStatus 调账申请状态
+type AdjustmentStatus string
+
+const (
+	AdjustmentStatusPending  AdjustmentStatus = "pending"  // 待审批
+	AdjustmentStatusExecuted AdjustmentStatus = "executed" // 已审批并执行
+	AdjustmentStatusRejected AdjustmentStatus = "rejected" // 已驳回
+)
+
+// AdjustmentAction 调账审批操作
+type AdjustmentAction string
+
+const (
+	AdjustmentActionSubmit  AdjustmentAction = "submit"  // 提交
+	AdjustmentActionApprove AdjustmentAction = "approve" // 审批通过
+	AdjustmentActionReject  AdjustmentAction = "reject"  // 驳回
+)
+
+// ApprovalThreshold 审批阈值：金额超过 Amount 时至少需要 Approvals 位审批人；
+// Symbol 为空时适用于所有代币，代币有专属阈值时只使用专属阈值
+type ApprovalThreshold struct {
+	Symbol    string          `json:"symbol"`
+	Amount    decimal.Decimal `json:"amount"`
+	Approvals int             `json:"approvals"`
+}
+
+// RequiresAdjustmentApproval 资金类型是否属于需要双人复核的后台调账（带 FundTypeFlagRequiresApproval 标志）
+func RequiresAdjustmentApproval(fundType constants.FundType) bool {
+	info, exists := constants.GetFundTypeInfo(fundType)
+	return exists && info.HasFlag(constants.FundTypeFlagRequiresApproval)
+}
+
+// RequiredApprovals 根据阈值计算调账所需审批人数，至少为 1
+func RequiredApprovals(thresholds []ApprovalThreshold, symbol string, amount decimal.Decimal) int {
+	hasSymbolThresholds := false
+	for _, threshold := range thresholds {
+		if threshold.Symbol != "" && strings.EqualFold(threshold.Symbol, symbol) {
+			hasSymbolThresholds = true
+			break
+		}
+	}
+
+	required := 1
+	for _, threshold := range thresholds {
+		if hasSymbolThresholds {
+			if !strings.EqualFold(threshold.Symbol, symbol) {
+				continue
+			}
+		} else if threshold.Symbol != "" {
+			continue
+		}
+		if amount.GreaterThan(threshold.Amount) && threshold.Approvals > required {
+			required = threshold.Approvals
+		}
+	}
+	return required
+}
@@ -0,0 +1,49 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/constants"
+)
+
+func TestRequiredApprovals(t *testing.T) {
+	thresholds := []ApprovalThreshold{
+		{Amount: decimal.RequireFromString("1000"), Approvals: 2},
+		{Amount: decimal.RequireFromString("100000"), Approvals: 3},
+		{Symbol: "BTC", Amount: decimal.RequireFromString("1"), Approvals: 2},
+	}
+	tests := []struct {
+		symbol   string
+		amount   string
+		expected int
+	}{
+		{"USDT", "500", 1},
+		{"USDT", "1000", 1},
+		{"USDT", "1000.01", 2},
+		{"usdt", "250000", 3},
+		{"BTC", "0.5", 1},
+		{"btc", "5000", 2},
+	}
+	for _, tt := range tests {
+		if got := RequiredApprovals(thresholds, tt.symbol, decimal.RequireFromString(tt.amount)); got != tt.expected {
+			t.Errorf("%s %s: expected %d approvals, got %d", tt.amount, tt.symbol, tt.expected, got)
+		}
+	}
+
+	if got := RequiredApprovals(nil, "USDT", decimal.RequireFromString("1e9")); got != 1 {
+		t.Errorf("expected default of 1 approval, got %d", got)
+	}
+}
+
+func TestRequiresAdjustmentApproval(t *testing.T) {
+	for _, fundType := range []constants.FundType{constants.FundTypeAdminAdd, constants.FundTypeAdminDeduct, constants.FundTypeSystemAdjustment} {
+		if !RequiresAdjustmentApproval(fundType) {
+			t.Errorf("expected %s to require approval", fundType)
+		}
+	}
+	if RequiresAdjustmentApproval(constants.FundTypeDeposit) {
+		t.Error("expected deposit not to require approval")
+	}
+}
@@ -0,0 +1,650 @@
+package logic
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/constants"
+	"github.com/yalks/wallet/entity"
+)
+
+// APIKeyPermission API Key 可调用的接口权限
+type APIKeyPermission string
+
+const (
+	APIKeyPermissionAll              APIKeyPermission = "*"                 // 全部权限
+	APIKeyPermissionBalancesRead     APIKeyPermission = "balances:read"     // 查询余额
+	APIKeyPermissionTransactionsRead APIKeyPermission = "transactions:read" // 查询交易
+	APIKeyPermissionQuotesRead       APIKeyPermission = "quotes:read"       // 预估资金操作
+	APIKeyPermissionOperationsWrite  APIKeyPermission = "operations:write"  // 执行资金操作
+	APIKeyPermissionTransfersWrite   APIKeyPermission = "transfers:write"   // 执行转账
+	APIKeyPermissionEventsRead       APIKeyPermission = "events:read"       // 订阅余额与交易变动
+)
+
+var validAPIKeyPermissions = map[APIKeyPermission]bool{
+	APIKeyPermissionAll:              true,
+	APIKeyPermissionBalancesRead:     true,
+	APIKeyPermissionTransactionsRead: true,
+	APIKeyPermissionQuotesRead:       true,
+	APIKeyPermissionOperationsWrite:  true,
+	APIKeyPermissionTransfersWrite:   true,
+	APIKeyPermissionEventsRead:       true,
+}
+
+// API Key 默认配置
+const (
+	DefaultAPIKeySignatureTolerance = 5 * time.Minute // 请求时间戳与服务器时间允许的最大偏差
+	DefaultAPIKeyRateLimit          = 600             // 每个 Key 每分钟默认最多请求数
+	MinAPIKeySecretKeyLength        = 32              // 服务端密钥最短长度
+)
+
+// APIKeyScope API Key 的权限范围；列表为空表示不限制该项
+type APIKeyScope struct {
+	Permissions []APIKeyPermission   `json:"permissions"`          // 允许调用的接口
+	FundTypes   []constants.FundType `json:"fund_types,omitempty"` // 允许的资金类型
+	Tokens      []string             `json:"tokens,omitempty"`     // 允许的代币符号
+	UserIDs     []uint64             `json:"user_ids,omitempty"`   // 允许操作和查询的用户
+	MaxAmount   decimal.Decimal      `json:"max_amount"`           // 单笔最大金额，0 表示不限
+}
+
+// APIKeyAccess 一次接口调用需要的权限
+type APIKeyAccess struct {
+	Permission  APIKeyPermission   // 接口权限
+	UserIDs     []uint64           // 涉及的用户（转账为双方）
+	TokenSymbol string             // 代币符号，为空时不校验
+	FundType    constants.FundType // 资金类型，为空时不校验
+	Amount      decimal.Decimal    // 金额，为 0 时不校验
+}
+
+// APIKey API Key 信息（不含密钥）
+type APIKey struct {
+	ID         uint64      `json:"id"`
+	KeyID      string      `json:"key_id"`
+	Name       string      `json:"name"`
+	Scope      APIKeyScope `json:"scope"`
+	RateLimit  int         `json:"rate_limit"` // 每分钟最多请求数，0 表示使用默认值
+	Active     bool        `json:"active"`
+	ExpiresAt  *gtime.Time `json:"expires_at,omitempty"`
+	LastUsedAt *gtime.Time `json:"last_used_at,omitempty"`
+	CreatedAt  *gtime.Time `json:"created_at,omitempty"`
+	RevokedAt  *gtime.Time `json:"revoked_at,omitempty"`
+}
+
+// CreateAPIKeyRequest 创建 API Key 请求
+type CreateAPIKeyRequest struct {
+	Name      string      `json:"name"`                 // 调用方名称
+	Scope     APIKeyScope `json:"scope"`                // 权限范围
+	RateLimit int         `json:"rate_limit"`           // 每分钟最多请求数，0 表示使用默认值
+	ExpiresAt *gtime.Time `json:"expires_at,omitempty"` // 过期时间，为空表示不过期
+}
+
+// APIKeyRequest 一次需要认证的 HTTP 请求
+type APIKeyRequest struct {
+	KeyID     string // X-API-Key
+	Timestamp string // X-Timestamp，Unix 秒
+	Nonce     string // X-Nonce
+	Signature string // X-Signature
+	Method    string
+	Path      string
+	RawQuery  string
+	Body      []byte
+}
+
+// APIKeyRateLimitError API Key 超出每分钟请求数
+type APIKeyRateLimitError struct {
+	KeyID      string
+	Limit      int
+	RetryAfter time.Duration
+}
+
+// Error 实现 error 接口
+func (e *APIKeyRateLimitError) Error() string {
+	return fmt.Sprintf("API Key %s 超出请求频率限制: 每分钟最多 %d 次，请在 %s 后重试", e.KeyID, e.Limit, e.RetryAfter)
+}
+
+// Code 返回错误码，便于通过 gerror.Code(err) 识别
+func (e *APIKeyRateLimitError) Code() gcode.Code {
+	return CodeAPIKeyRateLimited
+}
+
+// Validate 校验权限范围
+func (s *APIKeyScope) Validate() error {
+	if len(s.Permissions) == 0 {
+		return gerror.NewCode(gcode.CodeInvalidParameter, "API Key 至少需要一项接口权限")
+	}
+	for _, permission := range s.Permissions {
+		if !validAPIKeyPermissions[permission] {
+			return gerror.NewCodef(gcode.CodeInvalidParameter, "无效的 API Key 权限: %s", permission)
+		}
+	}
+	for _, fundType := range s.FundTypes {
+		if !constants.IsValidFundType(fundType) {
+			return gerror.NewCodef(gcode.CodeInvalidParameter, "无效的资金类型: %s", fundType)
+		}
+	}
+	for _, token := range s.Tokens {
+		if strings.TrimSpace(token) == "" || strings.Contains(token, ",") {
+			return gerror.NewCodef(gcode.CodeInvalidParameter, "无效的代币符号: %q", token)
+		}
+	}
+	for _, userID := range s.UserIDs {
+		if userID == 0 {
+			return gerror.NewCode(gcode.CodeInvalidParameter, "用户ID不能为0")
+		}
+	}
+	if s.MaxAmount.IsNegative() {
+		return gerror.NewCode(gcode.CodeInvalidParameter, "单笔最大金额不能为负数")
+	}
+	return nil
+}
+
+// Authorize 校验一次调用是否在权限范围内，超出范围时返回 CodeAPIKeyForbidden 错误
+func (s *APIKeyScope) Authorize(access *APIKeyAccess) error {
+	if !s.hasPermission(access.Permission) {
+		return gerror.NewCodef(CodeAPIKeyForbidden, "API Key 没有 %s 权限", access.Permission)
+	}
+	for _, userID := range access.UserIDs {
+		if !s.AllowsUser(userID) {
+			return gerror.NewCodef(CodeAPIKeyForbidden, "API Key 无权访问用户 %d", userID)
+		}
+	}
+	if access.TokenSymbol != "" && !s.AllowsToken(access.TokenSymbol) {
+		return gerror.NewCodef(CodeAPIKeyForbidden, "API Key 无权使用代币 %s", access.TokenSymbol)
+	}
+	if access.FundType != "" && len(s.FundTypes) > 0 {
+		allowed := false
+		for _, fundType := range s.FundTypes {
+			if fundType == access.FundType {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return gerror.NewCodef(CodeAPIKeyForbidden, "API Key 无权使用资金类型 %s", access.FundType)
+		}
+	}
+	if s.MaxAmount.IsPositive() && access.Amount.GreaterThan(s.MaxAmount) {
+		return gerror.NewCodef(CodeAPIKeyForbidden, "金额 %s 超过 API Key 单笔上限 %s", access.Amount.String(), s.MaxAmount.String())
+	}
+	return nil
+}
+
+// hasPermission 检查接口权限
+func (s *APIKeyScope) hasPermission(permission APIKeyPermission) bool {
+	for _, p := range s.Permissions {
+		if p == APIKeyPermissionAll || p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsUser 检查是否允许访问用户
+func (s *APIKeyScope) AllowsUser(userID uint64) bool {
+	if len(s.UserIDs) == 0 {
+		return true
+	}
+	for _, id := range s.UserIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsToken 检查是否允许使用代币（不区分大小写）
+func (s *APIKeyScope) AllowsToken(symbol string) bool {
+	if len(s.Tokens) == 0 {
+		return true
+	}
+	for _, token := range s.Tokens {
+		if strings.EqualFold(token, symbol) {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateAPIKeyCredentials 生成新的随机 Key ID，并用服务端密钥派生该 Key 的密钥
+func GenerateAPIKeyCredentials(serverKey string) (keyID, secret string, err error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", gerror.Wrap(err, "生成随机数失败")
+	}
+	keyID = "wk_" + hex.EncodeToString(buf)
+	return keyID, DeriveAPIKeySecret(serverKey, keyID), nil
+}
+
+// DeriveAPIKeySecret 以服务端密钥为 HMAC-SHA256 密钥从 Key ID 派生密钥。
+// 数据库不保存密钥或签名密钥，只有数据库内容无法伪造签名
+func DeriveAPIKeySecret(serverKey, keyID string) string {
+	mac := hmac.New(sha256.New, []byte(serverKey))
+	mac.Write([]byte("secret\n" + keyID))
+	return "wsk_" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// APIKeySecretVerifier 计算保存在 api_keys.secret_hash 中的密钥校验值，用于发现服务端密钥已更换或记录不是由当前密钥创建
+func APIKeySecretVerifier(serverKey, secret string) string {
+	mac := hmac.New(sha256.New, []byte(serverKey))
+	mac.Write([]byte("verifier\n" + secret))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// HashAPIKeySecret 计算密钥的 SHA-256 哈希（十六进制），作为请求签名的 HMAC 密钥
+func HashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIRequestSignature 计算请求签名：以密钥哈希为 HMAC-SHA256 密钥，对
+// "METHOD\nPATH\nRAW_QUERY\nTIMESTAMP\nNONCE\nhex(sha256(BODY))" 签名，结果为十六进制
+func APIRequestSignature(secretHash, method, path, rawQuery, timestamp, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	canonical := strings.Join([]string{
+		strings.ToUpper(method), path, rawQuery, timestamp, nonce, hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+	mac := hmac.New(sha256.New, []byte(secretHash))
+	mac.Write([]byte(canonical))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignAPIRequest 客户端使用密钥计算请求签名
+func SignAPIRequest(secret, method, path, rawQuery, timestamp, nonce string, body []byte) string {
+	return APIRequestSignature(HashAPIKeySecret(secret), method, path, rawQuery, timestamp, nonce, body)
+}
+
+// validateAPIKeyNonce 校验随机数：8 到 64 位字母、数字、- 或 _
+func validateAPIKeyNonce(nonce string) error {
+	if len(nonce) < 8 || len(nonce) > 64 {
+		return gerror.NewCode(gcode.CodeNotAuthorized, "X-Nonce 长度必须在 8 到 64 之间")
+	}
+	for _, c := range nonce {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return gerror.NewCode(gcode.CodeNotAuthorized, "X-Nonce 只能包含字母、数字、- 和 _")
+		}
+	}
+	return nil
+}
+
+// checkAPIKeyTimestamp 校验请求时间戳与当前时间的偏差
+func checkAPIKeyTimestamp(timestamp string, now time.Time, tolerance time.Duration) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return gerror.NewCode(gcode.CodeNotAuthorized, "X-Timestamp 必须是 Unix 秒")
+	}
+	skew := now.Sub(time.Unix(seconds, 0))
+	if skew > tolerance || skew < -tolerance {
+		return gerror.NewCodef(gcode.CodeNotAuthorized, "请求时间戳超出允许范围 (±%s)", tolerance)
+	}
+	return nil
+}
+
+// apiKeyRateLimiter 按 Key 的令牌桶限流器：容量为每分钟请求数，按分钟匀速补充
+type apiKeyRateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*apiKeyBucket
+}
+
+type apiKeyBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+func newAPIKeyRateLimiter() *apiKeyRateLimiter {
+	return &apiKeyRateLimiter{buckets: make(map[string]*apiKeyBucket)}
+}
+
+// allow 尝试消耗一个令牌；不足时返回需要等待的时间
+func (l *apiKeyRateLimiter) allow(keyID string, limit int, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	perSecond := float64(limit) / 60
+	bucket, ok := l.buckets[keyID]
+	if !ok {
+		bucket = &apiKeyBucket{tokens: float64(limit), updated: now}
+		l.buckets[keyID] = bucket
+	}
+	if elapsed := now.Sub(bucket.updated).Seconds(); elapsed > 0 {
+		bucket.tokens = math.Min(float64(limit), bucket.tokens+elapsed*perSecond)
+		bucket.updated = now
+	}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+	return false, time.Duration(math.Ceil((1-bucket.tokens)/perSecond)) * time.Second
+}
+
+// apiKeyContextKey 认证通过的 API Key 在 context 中的键
+type apiKeyContextKey struct{}
+
+// WithAPIKey 在 context 中记录认证通过的 API Key
+func WithAPIKey(ctx context.Context, key *APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, key)
+}
+
+// APIKeyFromContext 获取 context 中认证通过的 API Key，未认证时返回 nil
+func APIKeyFromContext(ctx context.Context) *APIKey {
+	key, _ := ctx.Value(apiKeyContextKey{}).(*APIKey)
+	return key
+}
+
+// APIKeyConfig API Key 认证配置（对应配置项 wallet.apiKeys）
+type APIKeyConfig struct {
+	SignatureTolerance time.Duration // 请求时间戳允许的偏差
+	DefaultRateLimit   int           // Key 未设置频率限制时每分钟最多请求数
+	SecretKey          string        // 派生各 Key 密钥的服务端密钥，不保存在数据库中；更换后已创建的 Key 全部失效
+}
+
+// ParseAPIKeyConfig 解析 API Key 认证配置，未配置的项使用默认值
+func ParseAPIKeyConfig(raw map[string]interface{}) (APIKeyConfig, error) {
+	config := APIKeyConfig{
+		SignatureTolerance: DefaultAPIKeySignatureTolerance,
+		DefaultRateLimit:   DefaultAPIKeyRateLimit,
+	}
+	if value := gconv.String(raw["signatureTolerance"]); value != "" {
+		duration, err := gtime.ParseDuration(value)
+		if err != nil || duration <= 0 {
+			return config, gerror.Newf("签名时间偏差无效: %s", value)
+		}
+		config.SignatureTolerance = duration
+	}
+	if value, ok := raw["defaultRateLimit"]; ok {
+		limit := gconv.Int(value)
+		if limit <= 0 {
+			return config, gerror.Newf("默认请求频率限制必须大于0: %v", value)
+		}
+		config.DefaultRateLimit = limit
+	}
+	if value := gconv.String(raw["secretKey"]); value != "" {
+		if len(value) < MinAPIKeySecretKeyLength {
+			return config, gerror.Newf("服务端密钥 secretKey 长度不能少于%d个字符", MinAPIKeySecretKeyLength)
+		}
+		config.SecretKey = value
+	}
+	return config, nil
+}
+
+// IAPIKeyLogic API Key 管理与请求认证业务逻辑接口
+type IAPIKeyLogic interface {
+	// Create 创建 API Key，返回 Key 信息和只出现这一次的密钥
+	Create(ctx context.Context, req *CreateAPIKeyRequest) (*APIKey, string, error)
+	// List 获取 API Key 列表
+	List(ctx context.Context, includeRevoked bool) ([]*APIKey, error)
+	// Revoke 吊销 API Key
+	Revoke(ctx context.Context, keyID string) error
+	// Authenticate 校验请求签名、时间戳、随机数和频率限制，返回认证通过的 API Key
+	Authenticate(ctx context.Context, req *APIKeyRequest) (*APIKey, error)
+	// PurgeNonces 清理已过重放窗口的随机数，返回删除数量
+	PurgeNonces(ctx context.Context) (int64, error)
+}
+
+type apiKeyLogic struct {
+	context *SharedLogicContext
+	limiter *apiKeyRateLimiter
+}
+
+// NewAPIKeyLogic 创建 API Key 业务逻辑实例
+func NewAPIKeyLogic() IAPIKeyLogic {
+	return &apiKeyLogic{
+		context: GetSharedContext(),
+		limiter: newAPIKeyRateLimiter(),
+	}
+}
+
+// loadConfig 读取 API Key 认证配置（wallet.apiKeys），配置无效时返回错误
+func (l *apiKeyLogic) loadConfig(ctx context.Context) (APIKeyConfig, error) {
+	value, err := g.Cfg().Get(ctx, "wallet.apiKeys")
+	if err != nil {
+		return APIKeyConfig{}, gerror.Wrap(err, "读取API Key配置失败")
+	}
+	var raw map[string]interface{}
+	if value != nil && !value.IsEmpty() {
+		raw = value.Map()
+	}
+	config, err := ParseAPIKeyConfig(raw)
+	if err != nil {
+		return APIKeyConfig{}, gerror.Wrap(err, "API Key配置无效")
+	}
+	return config, nil
+}
+
+// loadSigningConfig 读取创建和校验 API Key 需要的配置，未配置服务端密钥时返回错误
+func (l *apiKeyLogic) loadSigningConfig(ctx context.Context) (APIKeyConfig, error) {
+	config, err := l.loadConfig(ctx)
+	if err != nil {
+		return APIKeyConfig{}, err
+	}
+	if config.SecretKey == "" {
+		return APIKeyConfig{}, gerror.New("未配置 API Key 服务端密钥 wallet.apiKeys.secretKey")
+	}
+	return config, nil
+}
+
+// Create 创建 API Key，返回 Key 信息和只出现这一次的密钥
+func (l *apiKeyLogic) Create(ctx context.Context, req *CreateAPIKeyRequest) (*APIKey, string, error) {
+	if req == nil || strings.TrimSpace(req.Name) == "" {
+		return nil, "", gerror.NewCode(gcode.CodeInvalidParameter, "API Key 名称不能为空")
+	}
+	if len(req.Name) > 64 {
+		return nil, "", gerror.NewCode(gcode.CodeInvalidParameter, "API Key 名称长度不能超过64个字符")
+	}
+	if err := req.Scope.Validate(); err != nil {
+		return nil, "", err
+	}
+	if req.RateLimit < 0 {
+		return nil, "", gerror.NewCode(gcode.CodeInvalidParameter, "请求频率限制不能为负数")
+	}
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(gtime.Now()) {
+		return nil, "", gerror.NewCode(gcode.CodeInvalidParameter, "过期时间必须晚于当前时间")
+	}
+
+	config, err := l.loadSigningConfig(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	keyID, secret, err := GenerateAPIKeyCredentials(config.SecretKey)
+	if err != nil {
+		return nil, "", err
+	}
+	record := apiKeyEntity(req, keyID, APIKeySecretVerifier(config.SecretKey, secret))
+	id, err := l.context.GetAPIKeyDAO().Create(ctx, record)
+	if err != nil {
+		return nil, "", err
+	}
+	record.Id = id
+
+	g.Log().Infof(ctx, "创建API Key: KeyID=%s, Name=%s, Permissions=%s", keyID, record.Name, record.Permissions)
+	return ConvertAPIKey(record), secret, nil
+}
+
+// List 获取 API Key 列表
+func (l *apiKeyLogic) List(ctx context.Context, includeRevoked bool) ([]*APIKey, error) {
+	records, err := l.context.GetAPIKeyDAO().List(ctx, includeRevoked)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*APIKey, 0, len(records))
+	for _, record := range records {
+		keys = append(keys, ConvertAPIKey(record))
+	}
+	return keys, nil
+}
+
+// Revoke 吊销 API Key
+func (l *apiKeyLogic) Revoke(ctx context.Context, keyID string) error {
+	revoked, err := l.context.GetAPIKeyDAO().Revoke(ctx, keyID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return gerror.NewCodef(gcode.CodeNotFound, "API Key 不存在或已吊销: %s", keyID)
+	}
+	g.Log().Infof(ctx, "吊销API Key: KeyID=%s", keyID)
+	return nil
+}
+
+// Authenticate 校验请求签名、时间戳、随机数和频率限制，返回认证通过的 API Key。
+// 随机数在签名校验通过后才记录，未认证的请求不会写入数据
+func (l *apiKeyLogic) Authenticate(ctx context.Context, req *APIKeyRequest) (*APIKey, error) {
+	if req.KeyID == "" || req.Timestamp == "" || req.Nonce == "" || req.Signature == "" {
+		return nil, gerror.NewCode(gcode.CodeNotAuthorized, "缺少 X-API-Key、X-Timestamp、X-Nonce 或 X-Signature 请求头")
+	}
+	config, err := l.loadSigningConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := checkAPIKeyTimestamp(req.Timestamp, now, config.SignatureTolerance); err != nil {
+		return nil, err
+	}
+	if err := validateAPIKeyNonce(req.Nonce); err != nil {
+		return nil, err
+	}
+
+	record, err := l.context.GetAPIKeyDAO().GetByKeyID(ctx, req.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, gerror.NewCode(gcode.CodeNotAuthorized, "API Key 无效")
+	}
+	secret := DeriveAPIKeySecret(config.SecretKey, record.KeyId)
+	if !hmac.Equal([]byte(APIKeySecretVerifier(config.SecretKey, secret)), []byte(record.SecretHash)) {
+		g.Log().Warningf(ctx, "API Key 不是由当前服务端密钥创建，请重新创建: KeyID=%s", record.KeyId)
+		return nil, gerror.NewCode(gcode.CodeNotAuthorized, "API Key 无效")
+	}
+	expected := APIRequestSignature(HashAPIKeySecret(secret), req.Method, req.Path, req.RawQuery, req.Timestamp, req.Nonce, req.Body)
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(req.Signature))) {
+		return nil, gerror.NewCode(gcode.CodeNotAuthorized, "请求签名无效")
+	}
+	if record.Status != 1 {
+		return nil, gerror.NewCodef(gcode.CodeNotAuthorized, "API Key 已吊销: %s", record.KeyId)
+	}
+	if record.ExpiresAt != nil && record.ExpiresAt.Time.Before(now) {
+		return nil, gerror.NewCodef(gcode.CodeNotAuthorized, "API Key 已过期: %s", record.KeyId)
+	}
+
+	limit := record.RateLimit
+	if limit <= 0 {
+		limit = config.DefaultRateLimit
+	}
+	if ok, retryAfter := l.limiter.allow(record.KeyId, limit, now); !ok {
+		return nil, &APIKeyRateLimitError{KeyID: record.KeyId, Limit: limit, RetryAfter: retryAfter}
+	}
+
+	fresh, err := l.context.GetAPIKeyDAO().UseNonce(ctx, record.KeyId, req.Nonce, gtime.NewFromTime(now))
+	if err != nil {
+		return nil, err
+	}
+	if !fresh {
+		return nil, gerror.NewCode(gcode.CodeNotAuthorized, "X-Nonce 已使用，拒绝重放请求")
+	}
+
+	if record.LastUsedAt == nil || now.Sub(record.LastUsedAt.Time) > time.Minute {
+		if err := l.context.GetAPIKeyDAO().TouchLastUsed(ctx, record.KeyId, gtime.NewFromTime(now)); err != nil {
+			g.Log().Warningf(ctx, "更新API Key使用时间失败: %v", err)
+		}
+	}
+	return ConvertAPIKey(record), nil
+}
+
+// PurgeNonces 清理已过重放窗口的随机数：时间戳偏差最多为容忍值，随机数最多需要保留两倍容忍值
+func (l *apiKeyLogic) PurgeNonces(ctx context.Context) (int64, error) {
+	config, err := l.loadConfig(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return l.context.GetAPIKeyDAO().PurgeNoncesBefore(ctx, gtime.Now().Add(-2*config.SignatureTolerance))
+}
+
+// apiKeyEntity 构造 API Key 记录，secretHash 为密钥校验值
+func apiKeyEntity(req *CreateAPIKeyRequest, keyID, secretHash string) *entity.ApiKeys {
+	permissions := make([]string, 0, len(req.Scope.Permissions))
+	for _, permission := range req.Scope.Permissions {
+		permissions = append(permissions, string(permission))
+	}
+	fundTypes := make([]string, 0, len(req.Scope.FundTypes))
+	for _, fundType := range req.Scope.FundTypes {
+		fundTypes = append(fundTypes, string(fundType))
+	}
+	tokens := make([]string, 0, len(req.Scope.Tokens))
+	for _, token := range req.Scope.Tokens {
+		tokens = append(tokens, strings.ToUpper(strings.TrimSpace(token)))
+	}
+	userIDs := make([]string, 0, len(req.Scope.UserIDs))
+	for _, userID := range req.Scope.UserIDs {
+		userIDs = append(userIDs, strconv.FormatUint(userID, 10))
+	}
+	return &entity.ApiKeys{
+		KeyId:       keyID,
+		Name:        strings.TrimSpace(req.Name),
+		SecretHash:  secretHash,
+		Permissions: strings.Join(permissions, ","),
+		FundTypes:   strings.Join(fundTypes, ","),
+		Tokens:      strings.Join(tokens, ","),
+		UserIds:     strings.Join(userIDs, ","),
+		MaxAmount:   req.Scope.MaxAmount,
+		RateLimit:   req.RateLimit,
+		Status:      1,
+		ExpiresAt:   req.ExpiresAt,
+		CreatedAt:   gtime.Now(),
+	}
+}
+
+// ConvertAPIKey 将 API Key 记录转换为 API Key 信息
+func ConvertAPIKey(record *entity.ApiKeys) *APIKey {
+	key := &APIKey{
+		ID:         record.Id,
+		KeyID:      record.KeyId,
+		Name:       record.Name,
+		RateLimit:  record.RateLimit,
+		Active:     record.Status == 1 && (record.ExpiresAt == nil || record.ExpiresAt.After(gtime.Now())),
+		ExpiresAt:  record.ExpiresAt,
+		LastUsedAt: record.LastUsedAt,
+		CreatedAt:  record.CreatedAt,
+		RevokedAt:  record.RevokedAt,
+		Scope: APIKeyScope{
+			Tokens:    splitAPIKeyList(record.Tokens),
+			MaxAmount: record.MaxAmount,
+		},
+	}
+	for _, permission := range splitAPIKeyList(record.Permissions) {
+		key.Scope.Permissions = append(key.Scope.Permissions, APIKeyPermission(permission))
+	}
+	for _, fundType := range splitAPIKeyList(record.FundTypes) {
+		key.Scope.FundTypes = append(key.Scope.FundTypes, constants.FundType(fundType))
+	}
+	for _, userID := range splitAPIKeyList(record.UserIds) {
+		key.Scope.UserIDs = append(key.Scope.UserIDs, gconv.Uint64(userID))
+	}
+	return key
+}
+
+// splitAPIKeyList 拆分逗号分隔的列表，忽略空项
+func splitAPIKeyList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,221 @@
+package logic
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/constants"
+)
+
+func TestAPIKeyScopeValidate(t *testing.T) {
+	valid := APIKeyScope{
+		Permissions: []APIKeyPermission{APIKeyPermissionOperationsWrite},
+		FundTypes:   []constants.FundType{constants.FundTypeDeposit},
+		Tokens:      []string{"USDT"},
+		UserIDs:     []uint64{1},
+		MaxAmount:   decimal.NewFromInt(100),
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("valid scope: %v", err)
+	}
+
+	invalid := []APIKeyScope{
+		{},
+		{Permissions: []APIKeyPermission{"balances:write"}},
+		{Permissions: []APIKeyPermission{APIKeyPermissionAll}, FundTypes: []constants.FundType{"unknown_type"}},
+		{Permissions: []APIKeyPermission{APIKeyPermissionAll}, Tokens: []string{"USDT,BTC"}},
+		{Permissions: []APIKeyPermission{APIKeyPermissionAll}, UserIDs: []uint64{0}},
+		{Permissions: []APIKeyPermission{APIKeyPermissionAll}, MaxAmount: decimal.NewFromInt(-1)},
+	}
+	for i, scope := range invalid {
+		if err := scope.Validate(); gerror.Code(err) != gcode.CodeInvalidParameter {
+			t.Errorf("scope %d: error = %v", i, err)
+		}
+	}
+}
+
+func TestAPIKeyScopeAuthorize(t *testing.T) {
+	scope := APIKeyScope{
+		Permissions: []APIKeyPermission{APIKeyPermissionOperationsWrite, APIKeyPermissionBalancesRead},
+		FundTypes:   []constants.FundType{constants.FundTypeDeposit},
+		Tokens:      []string{"USDT"},
+		UserIDs:     []uint64{1, 2},
+		MaxAmount:   decimal.NewFromInt(100),
+	}
+	allowed := &APIKeyAccess{
+		Permission:  APIKeyPermissionOperationsWrite,
+		UserIDs:     []uint64{2},
+		TokenSymbol: "usdt",
+		FundType:    constants.FundTypeDeposit,
+		Amount:      decimal.NewFromInt(100),
+	}
+	if err := scope.Authorize(allowed); err != nil {
+		t.Fatalf("allowed access: %v", err)
+	}
+
+	denied := map[string]*APIKeyAccess{
+		"permission": {Permission: APIKeyPermissionTransfersWrite},
+		"user":       {Permission: APIKeyPermissionBalancesRead, UserIDs: []uint64{1, 3}},
+		"token":      {Permission: APIKeyPermissionBalancesRead, TokenSymbol: "BTC"},
+		"fund type":  {Permission: APIKeyPermissionOperationsWrite, FundType: constants.FundTypeWithdraw},
+		"amount":     {Permission: APIKeyPermissionOperationsWrite, Amount: decimal.RequireFromString("100.01")},
+	}
+	for name, access := range denied {
+		if err := scope.Authorize(access); gerror.Code(err) != CodeAPIKeyForbidden {
+			t.Errorf("%s: error = %v", name, err)
+		}
+	}
+
+	all := APIKeyScope{Permissions: []APIKeyPermission{APIKeyPermissionAll}}
+	if err := all.Authorize(&APIKeyAccess{Permission: APIKeyPermissionTransfersWrite, UserIDs: []uint64{9}, Amount: decimal.NewFromInt(1e9)}); err != nil {
+		t.Errorf("unrestricted scope: %v", err)
+	}
+}
+
+func TestAPIRequestSignature(t *testing.T) {
+	_, secret, err := GenerateAPIKeyCredentials(testAPIKeySecretKey)
+	if err != nil {
+		t.Fatalf("GenerateAPIKeyCredentials: %v", err)
+	}
+	body := []byte(`{"amount":"1"}`)
+	signature := SignAPIRequest(secret, "post", "/api/v1/operations", "", "1700000000", "nonce-0001", body)
+	if signature != APIRequestSignature(HashAPIKeySecret(secret), "POST", "/api/v1/operations", "", "1700000000", "nonce-0001", body) {
+		t.Fatal("client and server signatures differ")
+	}
+	changed := []string{
+		APIRequestSignature(HashAPIKeySecret(secret), "POST", "/api/v1/transfers", "", "1700000000", "nonce-0001", body),
+		APIRequestSignature(HashAPIKeySecret(secret), "POST", "/api/v1/operations", "a=1", "1700000000", "nonce-0001", body),
+		APIRequestSignature(HashAPIKeySecret(secret), "POST", "/api/v1/operations", "", "1700000001", "nonce-0001", body),
+		APIRequestSignature(HashAPIKeySecret(secret), "POST", "/api/v1/operations", "", "1700000000", "nonce-0002", body),
+		APIRequestSignature(HashAPIKeySecret(secret), "POST", "/api/v1/operations", "", "1700000000", "nonce-0001", []byte(`{"amount":"2"}`)),
+		APIRequestSignature(HashAPIKeySecret(secret+"x"), "POST", "/api/v1/operations", "", "1700000000", "nonce-0001", body),
+	}
+	for i, other := range changed {
+		if other == signature {
+			t.Errorf("change %d did not change the signature", i)
+		}
+	}
+}
+
+// testAPIKeySecretKey 测试用服务端密钥
+const testAPIKeySecretKey = "0123456789abcdef0123456789abcdef"
+
+func TestDeriveAPIKeySecret(t *testing.T) {
+	keyID, secret, err := GenerateAPIKeyCredentials(testAPIKeySecretKey)
+	if err != nil {
+		t.Fatalf("GenerateAPIKeyCredentials: %v", err)
+	}
+	if !strings.HasPrefix(keyID, "wk_") || !strings.HasPrefix(secret, "wsk_") || secret != DeriveAPIKeySecret(testAPIKeySecretKey, keyID) {
+		t.Fatalf("keyID = %s, secret = %s", keyID, secret)
+	}
+	if DeriveAPIKeySecret(testAPIKeySecretKey+"x", keyID) == secret || DeriveAPIKeySecret(testAPIKeySecretKey, keyID+"x") == secret {
+		t.Error("secret does not depend on the server key and key ID")
+	}
+
+	// 数据库只保存校验值，它既不是签名密钥也不能推出密钥
+	verifier := APIKeySecretVerifier(testAPIKeySecretKey, secret)
+	if verifier == HashAPIKeySecret(secret) || strings.Contains(verifier, strings.TrimPrefix(secret, "wsk_")) {
+		t.Errorf("verifier %s reveals the signing key", verifier)
+	}
+	if APIKeySecretVerifier(testAPIKeySecretKey+"x", secret) == verifier {
+		t.Error("verifier does not depend on the server key")
+	}
+}
+
+func TestCheckAPIKeyTimestamp(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	for _, ts := range []int64{1700000000, 1700000000 - 300, 1700000000 + 300} {
+		if err := checkAPIKeyTimestamp(strconv.FormatInt(ts, 10), now, 5*time.Minute); err != nil {
+			t.Errorf("timestamp %d: %v", ts, err)
+		}
+	}
+	for _, ts := range []string{"1700000301", "1699999699", "abc", ""} {
+		if err := checkAPIKeyTimestamp(ts, now, 5*time.Minute); gerror.Code(err) != gcode.CodeNotAuthorized {
+			t.Errorf("timestamp %q: error = %v", ts, err)
+		}
+	}
+}
+
+func TestValidateAPIKeyNonce(t *testing.T) {
+	for _, nonce := range []string{"abcdefgh", "0f7c-4e1b_9a", strings.Repeat("A", 64)} {
+		if err := validateAPIKeyNonce(nonce); err != nil {
+			t.Errorf("nonce %q: %v", nonce, err)
+		}
+	}
+	for _, nonce := range []string{"short", "has space!", strings.Repeat("a", 65)} {
+		if err := validateAPIKeyNonce(nonce); err == nil {
+			t.Errorf("nonce %q accepted", nonce)
+		}
+	}
+}
+
+func TestAPIKeyRateLimiter(t *testing.T) {
+	limiter := newAPIKeyRateLimiter()
+	now := time.Unix(1700000000, 0)
+	for i := 0; i < 3; i++ {
+		if ok, _ := limiter.allow("wk_a", 3, now); !ok {
+			t.Fatalf("request %d rejected", i)
+		}
+	}
+	ok, retryAfter := limiter.allow("wk_a", 3, now)
+	if ok || retryAfter != 20*time.Second {
+		t.Fatalf("fourth request = %v, retry after %s", ok, retryAfter)
+	}
+	if ok, _ := limiter.allow("wk_b", 3, now); !ok {
+		t.Error("keys share a bucket")
+	}
+	if ok, _ := limiter.allow("wk_a", 3, now.Add(20*time.Second)); !ok {
+		t.Error("token not refilled after 20s")
+	}
+}
+
+func TestParseAPIKeyConfig(t *testing.T) {
+	config, err := ParseAPIKeyConfig(nil)
+	if err != nil || config.SignatureTolerance != DefaultAPIKeySignatureTolerance || config.DefaultRateLimit != DefaultAPIKeyRateLimit {
+		t.Fatalf("defaults = %+v, %v", config, err)
+	}
+	config, err = ParseAPIKeyConfig(map[string]interface{}{"signatureTolerance": "2m", "defaultRateLimit": 60, "secretKey": testAPIKeySecretKey})
+	if err != nil || config.SignatureTolerance != 2*time.Minute || config.DefaultRateLimit != 60 || config.SecretKey != testAPIKeySecretKey {
+		t.Fatalf("config = %+v, %v", config, err)
+	}
+	for _, raw := range []map[string]interface{}{
+		{"signatureTolerance": "soon"},
+		{"signatureTolerance": "-1m"},
+		{"defaultRateLimit": 0},
+		{"secretKey": "too-short"},
+	} {
+		if _, err := ParseAPIKeyConfig(raw); err == nil {
+			t.Errorf("config %v accepted", raw)
+		}
+	}
+}
+
+func TestAPIKeyEntityRoundTrip(t *testing.T) {
+	req := &CreateAPIKeyRequest{
+		Name: " red-packet ",
+		Scope: APIKeyScope{
+			Permissions: []APIKeyPermission{APIKeyPermissionOperationsWrite, APIKeyPermissionBalancesRead},
+			FundTypes:   []constants.FundType{constants.FundTypeRedPacketCreate, constants.FundTypeRedPacketClaim},
+			Tokens:      []string{"usdt"},
+			UserIDs:     []uint64{7, 8},
+			MaxAmount:   decimal.NewFromInt(50),
+		},
+		RateLimit: 120,
+	}
+	record := apiKeyEntity(req, "wk_0123456789abcdef", APIKeySecretVerifier(testAPIKeySecretKey, "secret"))
+	if record.Name != "red-packet" || record.Tokens != "USDT" || record.UserIds != "7,8" || record.Status != 1 {
+		t.Fatalf("record = %+v", record)
+	}
+	key := ConvertAPIKey(record)
+	if !key.Active || key.KeyID != "wk_0123456789abcdef" || key.RateLimit != 120 ||
+		len(key.Scope.Permissions) != 2 || len(key.Scope.FundTypes) != 2 || key.Scope.Tokens[0] != "USDT" ||
+		len(key.Scope.UserIDs) != 2 || key.Scope.UserIDs[1] != 8 || !key.Scope.MaxAmount.Equal(decimal.NewFromInt(50)) {
+		t.Errorf("key = %+v", key)
+	}
+}
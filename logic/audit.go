@@ -0,0 +1,240 @@
+package logic
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/entity"
+)
+
+// AuditEntryType 审计记录类型
+type AuditEntryType string
+
+const (
+	AuditEntryTransaction  AuditEntryType = "transaction"   // 交易记录写入
+	AuditEntryStatusChange AuditEntryType = "status_change" // 交易状态变更
+)
+
+// auditTimeLayout 审计内容中的时间格式（与数据库 datetime 精度一致）
+const auditTimeLayout = "Y-m-d H:i:s"
+
+// TransactionAuditPayload 交易记录的审计内容，只包含写入后不应再变化的字段；
+// Status 为写入时的状态，之后的变化由状态变更记录追加
+type TransactionAuditPayload struct {
+	TransactionID        uint64          `json:"transaction_id"`
+	UserID               uint            `json:"user_id"`
+	TokenID              uint            `json:"token_id"`
+	Symbol               string          `json:"symbol"`
+	Type                 string          `json:"type"`
+	WalletType           string          `json:"wallet_type"`
+	Direction            string          `json:"direction"`
+	Amount               decimal.Decimal `json:"amount"`
+	BalanceBefore        decimal.Decimal `json:"balance_before"`
+	BalanceAfter         decimal.Decimal `json:"balance_after"`
+	FeeAmount            decimal.Decimal `json:"fee_amount"`
+	BusinessID           string          `json:"business_id"`
+	RelatedTransactionID uint64          `json:"related_transaction_id"`
+	RelatedEntityID      uint64          `json:"related_entity_id"`
+	RelatedEntityType    string          `json:"related_entity_type"`
+	TargetUserID         uint            `json:"target_user_id"`
+	Memo                 string          `json:"memo"`
+	CreatedAt            string          `json:"created_at"`
+	Status               uint            `json:"status"`
+}
+
+// StatusChangeAuditPayload 交易状态变更的审计内容（数据库中的状态值）
+type StatusChangeAuditPayload struct {
+	TransactionID uint64 `json:"transaction_id"`
+	OldStatus     uint   `json:"old_status"`
+	NewStatus     uint   `json:"new_status"`
+}
+
+// NewTransactionAuditPayload 根据交易记录生成审计内容，status 为需要记录的状态
+func NewTransactionAuditPayload(transaction *entity.Transactions, status uint) *TransactionAuditPayload {
+	payload := &TransactionAuditPayload{
+		TransactionID:        transaction.TransactionId,
+		UserID:               transaction.UserId,
+		TokenID:              transaction.TokenId,
+		Symbol:               transaction.Symbol,
+		Type:                 transaction.Type,
+		WalletType:           transaction.WalletType,
+		Direction:            transaction.Direction,
+		Amount:               transaction.Amount,
+		BalanceBefore:        transaction.BalanceBefore,
+		BalanceAfter:         transaction.BalanceAfter,
+		FeeAmount:            transaction.FeeAmount,
+		BusinessID:           transaction.BusinessId,
+		RelatedTransactionID: transaction.RelatedTransactionId,
+		RelatedEntityID:      transaction.RelatedEntityId,
+		RelatedEntityType:    transaction.RelatedEntityType,
+		TargetUserID:         transaction.TargetUserId,
+		Memo:                 transaction.Memo,
+		Status:               status,
+	}
+	if transaction.CreatedAt != nil {
+		payload.CreatedAt = transaction.CreatedAt.Format(auditTimeLayout)
+	}
+	return payload
+}
+
+// EncodeAuditPayload 将审计内容序列化为规范化 JSON（字段顺序固定）
+func EncodeAuditPayload(payload any) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", gerror.Wrap(err, "序列化审计内容失败")
+	}
+	return string(data), nil
+}
+
+// AuditEntryHash 计算审计记录哈希: hex(SHA-256(prevHash \n chainID \n sequence \n entryType \n payload))
+func AuditEntryHash(prevHash, chainID string, sequence uint64, entryType AuditEntryType, payload string) string {
+	h := sha256.New()
+	h.Write([]byte(prevHash))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(chainID))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(strconv.FormatUint(sequence, 10)))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(entryType))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(payload))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// AdvanceCheckpointRoot 在检查点根哈希上累加一条审计记录哈希
+func AdvanceCheckpointRoot(root, entryHash string) string {
+	sum := sha256.Sum256([]byte(root + entryHash))
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditCheckpointMessage 检查点签名的规范化消息
+func AuditCheckpointMessage(checkpoint *entity.AuditCheckpoints) []byte {
+	return []byte(strconv.FormatUint(checkpoint.LastEntryId, 10) + "|" +
+		strconv.FormatInt(checkpoint.EntryCount, 10) + "|" +
+		checkpoint.PrevRoot + "|" + checkpoint.Root)
+}
+
+// ParseAuditSigningKey 解析检查点签名私钥（base64 编码的 32 字节 Ed25519 种子）
+func ParseAuditSigningKey(encoded string) (ed25519.PrivateKey, error) {
+	seed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, gerror.Wrap(err, "审计签名私钥不是有效的 base64")
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, gerror.Newf("审计签名私钥长度错误: 需要 %d 字节, 实际 %d 字节", ed25519.SeedSize, len(seed))
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// SignAuditCheckpoint 对检查点签名，填充 PublicKey 和 Signature
+func SignAuditCheckpoint(checkpoint *entity.AuditCheckpoints, key ed25519.PrivateKey) {
+	checkpoint.PublicKey = base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey))
+	checkpoint.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, AuditCheckpointMessage(checkpoint)))
+}
+
+// VerifyAuditCheckpoint 使用检查点中记录的公钥校验签名
+func VerifyAuditCheckpoint(checkpoint *entity.AuditCheckpoints) bool {
+	publicKey, err := base64.StdEncoding.DecodeString(checkpoint.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return false
+	}
+	signature, err := base64.StdEncoding.DecodeString(checkpoint.Signature)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(publicKey, AuditCheckpointMessage(checkpoint), signature)
+}
+
+// IAuditLogic 审计哈希链业务逻辑接口
+type IAuditLogic interface {
+	// RecordTransactionInTx 在业务事务中为刚写入的交易记录追加审计记录。
+	// tx 为空时使用 ctx 中携带的事务（g.DB().Transaction 回调内的 ctx），两者都没有时返回错误
+	RecordTransactionInTx(ctx context.Context, tx gdb.TX, transactionID uint64) error
+	// RecordStatusChangeInTx 在业务事务中为交易状态变更追加审计记录，不在事务中时返回错误
+	RecordStatusChangeInTx(ctx context.Context, tx gdb.TX, transaction *entity.Transactions, newStatus uint) error
+}
+
+type auditLogic struct {
+	context *SharedLogicContext
+}
+
+// NewAuditLogic 创建审计哈希链业务逻辑实例
+func NewAuditLogic() IAuditLogic {
+	return &auditLogic{
+		context: GetSharedContext(),
+	}
+}
+
+// RecordTransactionInTx 在业务事务中为刚写入的交易记录追加审计记录
+func (l *auditLogic) RecordTransactionInTx(ctx context.Context, tx gdb.TX, transactionID uint64) error {
+	if !inTransaction(ctx, tx) {
+		return gerror.New("审计记录必须在业务事务中写入")
+	}
+	// 重新读取交易记录，以数据库实际存储的值（时间精度、金额精度）计算哈希
+	transaction, err := l.context.GetAuditDAO().GetTransaction(ctx, tx, transactionID)
+	if err != nil {
+		return err
+	}
+
+	payload, err := EncodeAuditPayload(NewTransactionAuditPayload(transaction, transaction.Status))
+	if err != nil {
+		return err
+	}
+	return l.append(ctx, tx, WalletAggregateID(uint64(transaction.UserId), transaction.Symbol), AuditEntryTransaction, transactionID, payload)
+}
+
+// RecordStatusChangeInTx 在业务事务中为交易状态变更追加审计记录
+func (l *auditLogic) RecordStatusChangeInTx(ctx context.Context, tx gdb.TX, transaction *entity.Transactions, newStatus uint) error {
+	if !inTransaction(ctx, tx) {
+		return gerror.New("审计记录必须在业务事务中写入")
+	}
+	payload, err := EncodeAuditPayload(&StatusChangeAuditPayload{
+		TransactionID: transaction.TransactionId,
+		OldStatus:     transaction.Status,
+		NewStatus:     newStatus,
+	})
+	if err != nil {
+		return err
+	}
+	return l.append(ctx, tx, WalletAggregateID(uint64(transaction.UserId), transaction.Symbol), AuditEntryStatusChange, transaction.TransactionId, payload)
+}
+
+// append 追加审计记录：链内序号在业务事务中基于锁定的链头分配，同一条链的并发追加依次执行；
+// (chain_id, sequence) 唯一约束兜底，即使绕过锁也只会使事务失败而不会产生分叉
+func (l *auditLogic) append(ctx context.Context, tx gdb.TX, chainID string, entryType AuditEntryType, refID uint64, payload string) error {
+	dao := l.context.GetAuditDAO()
+	head, err := dao.GetChainHead(ctx, tx, chainID)
+	if err != nil {
+		return err
+	}
+
+	var (
+		sequence uint64 = 1
+		prevHash string
+	)
+	if head != nil {
+		sequence = head.Sequence + 1
+		prevHash = head.Hash
+	}
+
+	return dao.CreateEntry(ctx, tx, &entity.AuditEntries{
+		ChainId:   chainID,
+		Sequence:  sequence,
+		EntryType: string(entryType),
+		RefId:     refID,
+		Payload:   payload,
+		PrevHash:  prevHash,
+		Hash:      AuditEntryHash(prevHash, chainID, sequence, entryType, payload),
+		CreatedAt: gtime.Now(),
+	})
+}
@@ -0,0 +1,97 @@
+package logic
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/entity"
+)
+
+func TestAuditEntryHash(t *testing.T) {
+	first := AuditEntryHash("", "1001:USDT", 1, AuditEntryTransaction, `{"transaction_id":1}`)
+	if len(first) != 64 {
+		t.Fatalf("expected hex sha256, got %q", first)
+	}
+	if first != AuditEntryHash("", "1001:USDT", 1, AuditEntryTransaction, `{"transaction_id":1}`) {
+		t.Error("hash is not deterministic")
+	}
+
+	changed := []string{
+		AuditEntryHash("x", "1001:USDT", 1, AuditEntryTransaction, `{"transaction_id":1}`),
+		AuditEntryHash("", "1002:USDT", 1, AuditEntryTransaction, `{"transaction_id":1}`),
+		AuditEntryHash("", "1001:USDT", 2, AuditEntryTransaction, `{"transaction_id":1}`),
+		AuditEntryHash("", "1001:USDT", 1, AuditEntryStatusChange, `{"transaction_id":1}`),
+		AuditEntryHash("", "1001:USDT", 1, AuditEntryTransaction, `{"transaction_id":2}`),
+	}
+	for i, hash := range changed {
+		if hash == first {
+			t.Errorf("case %d: expected different hash", i)
+		}
+	}
+}
+
+func TestTransactionAuditPayload(t *testing.T) {
+	transaction := &entity.Transactions{
+		TransactionId: 42,
+		UserId:        1001,
+		Symbol:        "USDT",
+		Type:          "deposit",
+		Direction:     "in",
+		Amount:        decimal.RequireFromString("100.500000000000000000"),
+		BalanceAfter:  decimal.RequireFromString("100.5"),
+		Status:        1,
+		CreatedAt:     gtime.NewFromStr("2024-01-02 03:04:05"),
+		UpdatedAt:     gtime.Now(),
+		RequestIp:     "127.0.0.1",
+	}
+	payload, err := EncodeAuditPayload(NewTransactionAuditPayload(transaction, transaction.Status))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(payload, `"amount":"100.5"`) || !strings.Contains(payload, `"created_at":"2024-01-02 03:04:05"`) {
+		t.Errorf("unexpected payload: %s", payload)
+	}
+
+	// 只有被审计的字段变化才会改变审计内容
+	transaction.UpdatedAt = gtime.Now()
+	transaction.RequestIp = "10.0.0.1"
+	if again, _ := EncodeAuditPayload(NewTransactionAuditPayload(transaction, 1)); again != payload {
+		t.Errorf("payload changed by non-audited fields: %s", again)
+	}
+	transaction.BalanceAfter = decimal.RequireFromString("1000.5")
+	if again, _ := EncodeAuditPayload(NewTransactionAuditPayload(transaction, 1)); again == payload {
+		t.Error("expected payload to change with balance_after")
+	}
+}
+
+func TestAuditCheckpointSignature(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+	key, err := ParseAuditSigningKey(base64.StdEncoding.EncodeToString(seed))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	root := AdvanceCheckpointRoot(AdvanceCheckpointRoot("", "a"), "b")
+	checkpoint := &entity.AuditCheckpoints{LastEntryId: 2, EntryCount: 2, Root: root}
+	SignAuditCheckpoint(checkpoint, key)
+	if !VerifyAuditCheckpoint(checkpoint) {
+		t.Fatal("expected valid signature")
+	}
+
+	checkpoint.Root = AdvanceCheckpointRoot(AdvanceCheckpointRoot("", "b"), "a")
+	if VerifyAuditCheckpoint(checkpoint) {
+		t.Error("expected signature to fail after root changed")
+	}
+
+	if _, err := ParseAuditSigningKey("c2hvcnQ="); err == nil {
+		t.Error("expected error for short key")
+	}
+}
@@ -20,6 +20,8 @@ type IBalanceLogic interface {
 	GetLocalBalance(ctx context.Context, userID uint64, tokenSymbol string) (availableBalance, frozenBalance decimal.Decimal, err error)
 	// GetRemoteBalance 获取远程钱包余额
 	GetRemoteBalance(ctx context.Context, userID uint64, tokenSymbol string) (decimal.Decimal, error)
+	// LockBalanceInTx 在事务中锁定钱包行并读取最新可用余额，钱包不存在时 exists 为 false
+	LockBalanceInTx(ctx context.Context, tx gdb.TX, userID uint64, tokenSymbol string) (availableBalance decimal.Decimal, exists bool, err error)
 	// UpdateLocalBalance 更新本地钱包余额
 	UpdateLocalBalance(ctx context.Context, tx gdb.TX, userID uint64, tokenSymbol string, availableBalance, frozenBalance decimal.Decimal) error
 	// SyncBalanceFromRemote 从远程同步余额到本地
@@ -92,6 +94,23 @@ func (l *balanceLogic) GetLocalBalance(ctx context.Context, userID uint64, token
 	return availableBalance, frozenBalance, nil
 }
 
+// LockBalanceInTx 在事务中锁定钱包行并读取最新可用余额；锁定读不受事务快照影响，
+// 持有锁期间同一钱包的其他操作无法修改余额
+func (l *balanceLogic) LockBalanceInTx(ctx context.Context, tx gdb.TX, userID uint64, tokenSymbol string) (availableBalance decimal.Decimal, exists bool, err error) {
+	wallet, err := l.context.GetWalletDAO().LockWalletInTx(ctx, tx, userID, tokenSymbol)
+	if err != nil {
+		return decimal.Zero, false, err
+	}
+	if wallet == nil {
+		return decimal.Zero, false, nil
+	}
+	availableBalance, err = l.tokenLogic.ConvertDBStorageToBalance(ctx, wallet.AvailableBalance, tokenSymbol)
+	if err != nil {
+		return decimal.Zero, false, gerror.Wrapf(err, "转换可用余额失败: UserID=%d, Symbol=%s", userID, tokenSymbol)
+	}
+	return availableBalance, true, nil
+}
+
 // GetRemoteBalance 获取远程钱包余额 - 暂时返回错误，使用本地事务
 func (l *balanceLogic) GetRemoteBalance(ctx context.Context, userID uint64, tokenSymbol string) (decimal.Decimal, error) {
 	// 暂时不支持远程查询，直接返回错误
@@ -0,0 +1,417 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/constants"
+	"github.com/yalks/wallet/entity"
+)
+
+// CommissionChain 佣金分配所沿的关系链
+type CommissionChain string
+
+const (
+	CommissionChainReferral CommissionChain = "referral" // 推荐链：逐级沿 recommend_id 向上
+	CommissionChainAgent    CommissionChain = "agent"    // 代理链：first_id、second_id、third_id
+)
+
+// 佣金交易元数据键
+const (
+	CommissionMetadataSourceTransactionID = "commission_source_transaction_id" // 源交易ID
+	CommissionMetadataSourceUserID        = "commission_source_user_id"        // 源交易用户ID
+	CommissionMetadataLevel               = "commission_level"                 // 佣金层级
+)
+
+// CommissionRule 佣金规则：源资金类型（和代币）匹配时，按层级比例向上分配
+type CommissionRule struct {
+	FundType           constants.FundType `json:"fundType"`           // 触发佣金的源资金类型
+	Symbol             string             `json:"symbol"`             // 代币符号，为空时适用于所有代币
+	Chain              CommissionChain    `json:"chain"`              // 关系链，默认 referral
+	CommissionFundType constants.FundType `json:"commissionFundType"` // 佣金入账资金类型，默认 commission
+	Rates              []decimal.Decimal  `json:"rates"`              // 各层级佣金比例，第一个为直接上级
+	MinSourceAmount    decimal.Decimal    `json:"minSourceAmount"`    // 源交易最低金额，低于时不分配
+	MaxAmount          decimal.Decimal    `json:"maxAmount"`          // 单笔佣金上限，0 表示不限
+}
+
+// CommissionConfig 佣金引擎配置（对应配置项 wallet.commission）
+type CommissionConfig struct {
+	Enabled        bool             `json:"enabled"`        // 是否启用佣金分配
+	DailyCap       decimal.Decimal  `json:"dailyCap"`       // 每位受益人每个代币每自然日的佣金上限，0 表示不限
+	ExcludeUserIDs []uint64         `json:"excludeUserIds"` // 不触发也不获得佣金的用户
+	ExcludeSources []string         `json:"excludeSources"` // 不触发佣金的请求来源
+	Rules          []CommissionRule `json:"rules"`          // 佣金规则
+}
+
+// CommissionSource 触发佣金分配的源交易
+type CommissionSource struct {
+	TransactionID uint64             // 源交易ID
+	UserID        uint64             // 源交易用户ID
+	TokenSymbol   string             // 代币符号
+	FundType      constants.FundType // 源资金类型
+	Amount        decimal.Decimal    // 源交易金额
+	RequestSource string             // 请求来源
+}
+
+// ParseCommissionConfig 解析佣金配置并校验规则
+func ParseCommissionConfig(raw map[string]interface{}) (CommissionConfig, error) {
+	config := CommissionConfig{
+		Enabled:        gconv.Bool(raw["enabled"]),
+		ExcludeUserIDs: gconv.Uint64s(raw["excludeUserIds"]),
+	}
+	for _, source := range gconv.Strings(raw["excludeSources"]) {
+		config.ExcludeSources = append(config.ExcludeSources, strings.ToLower(strings.TrimSpace(source)))
+	}
+
+	var err error
+	if config.DailyCap, err = parseCommissionAmount(raw["dailyCap"]); err != nil {
+		return config, gerror.Wrap(err, "无效的佣金每日上限")
+	}
+
+	for i, item := range gconv.Maps(raw["rules"]) {
+		rule := CommissionRule{
+			FundType:           constants.FundType(gconv.String(item["fundType"])),
+			Symbol:             gconv.String(item["symbol"]),
+			Chain:              CommissionChain(gconv.String(item["chain"])),
+			CommissionFundType: constants.FundType(gconv.String(item["commissionFundType"])),
+		}
+		if rule.Chain == "" {
+			rule.Chain = CommissionChainReferral
+		}
+		if rule.CommissionFundType == "" {
+			rule.CommissionFundType = constants.FundTypeCommission
+		}
+		for _, rate := range gconv.Strings(item["rates"]) {
+			value, err := decimal.NewFromString(rate)
+			if err != nil {
+				return config, gerror.Wrapf(err, "佣金规则 %d 的比例无效: %s", i, rate)
+			}
+			rule.Rates = append(rule.Rates, value)
+		}
+		if rule.MinSourceAmount, err = parseCommissionAmount(item["minSourceAmount"]); err != nil {
+			return config, gerror.Wrapf(err, "佣金规则 %d 的最低金额无效", i)
+		}
+		if rule.MaxAmount, err = parseCommissionAmount(item["maxAmount"]); err != nil {
+			return config, gerror.Wrapf(err, "佣金规则 %d 的单笔上限无效", i)
+		}
+		config.Rules = append(config.Rules, rule)
+	}
+
+	return config, ValidateCommissionRules(config.Rules)
+}
+
+// parseCommissionAmount 解析非负金额，未配置时为 0
+func parseCommissionAmount(value interface{}) (decimal.Decimal, error) {
+	raw := gconv.String(value)
+	if raw == "" {
+		return decimal.Zero, nil
+	}
+	amount, err := decimal.NewFromString(raw)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if amount.IsNegative() {
+		return decimal.Zero, gerror.Newf("金额不能为负数: %s", raw)
+	}
+	return amount, nil
+}
+
+// ValidateCommissionRules 校验佣金规则；佣金入账资金类型不能再触发佣金，避免循环分配
+func ValidateCommissionRules(rules []CommissionRule) error {
+	payoutTypes := make(map[constants.FundType]bool)
+	for _, rule := range rules {
+		payoutTypes[rule.CommissionFundType] = true
+	}
+
+	for i, rule := range rules {
+		if !constants.IsValidFundType(rule.FundType) {
+			return gerror.Newf("佣金规则 %d 的源资金类型无效: %s", i, rule.FundType)
+		}
+		if payoutTypes[rule.FundType] {
+			return gerror.Newf("佣金规则 %d: 佣金入账资金类型 %s 不能作为源资金类型", i, rule.FundType)
+		}
+		if constants.GetFundDirection(rule.CommissionFundType) != constants.FundDirectionIn {
+			return gerror.Newf("佣金规则 %d 的佣金入账资金类型必须是入账类型: %s", i, rule.CommissionFundType)
+		}
+		if rule.Chain != CommissionChainReferral && rule.Chain != CommissionChainAgent {
+			return gerror.Newf("佣金规则 %d 的关系链无效: %s", i, rule.Chain)
+		}
+		if len(rule.Rates) == 0 {
+			return gerror.Newf("佣金规则 %d 至少需要一个层级比例", i)
+		}
+		if rule.Chain == CommissionChainAgent && len(rule.Rates) > 3 {
+			return gerror.Newf("佣金规则 %d: 代理链最多 3 级", i)
+		}
+		for level, rate := range rule.Rates {
+			if rate.IsNegative() || rate.GreaterThanOrEqual(decimal.NewFromInt(1)) {
+				return gerror.Newf("佣金规则 %d 第 %d 级比例必须在 [0, 1) 之间: %s", i, level+1, rate)
+			}
+		}
+	}
+	return nil
+}
+
+// MatchCommissionRule 查找源交易适用的佣金规则，代币专属规则优先于通用规则
+func MatchCommissionRule(rules []CommissionRule, fundType constants.FundType, symbol string) *CommissionRule {
+	var generic *CommissionRule
+	for i := range rules {
+		rule := &rules[i]
+		if rule.FundType != fundType {
+			continue
+		}
+		if rule.Symbol == "" {
+			if generic == nil {
+				generic = rule
+			}
+			continue
+		}
+		if strings.EqualFold(rule.Symbol, symbol) {
+			return rule
+		}
+	}
+	return generic
+}
+
+// AgentChain 返回用户的代理链（一级、二级、三级），未设置的层级为 0，不向前补位
+func AgentChain(user *entity.Users) []uint64 {
+	return []uint64{uint64(user.FirstId), uint64(user.SecondId), uint64(user.ThirdId)}
+}
+
+// CalculateCommission 计算第 level 级（从 1 开始）佣金：按比例计算、按代币精度截断并应用单笔上限
+func CalculateCommission(rule *CommissionRule, level int, sourceAmount decimal.Decimal, precision int32) decimal.Decimal {
+	if level < 1 || level > len(rule.Rates) {
+		return decimal.Zero
+	}
+	amount := sourceAmount.Mul(rule.Rates[level-1]).Truncate(precision)
+	if rule.MaxAmount.IsPositive() && amount.GreaterThan(rule.MaxAmount) {
+		amount = rule.MaxAmount
+	}
+	return amount
+}
+
+// ApplyCommissionDailyCap 按受益人当日已获得的佣金应用每日上限
+func ApplyCommissionDailyCap(amount, paidToday, dailyCap decimal.Decimal) decimal.Decimal {
+	if !dailyCap.IsPositive() {
+		return amount
+	}
+	remaining := dailyCap.Sub(paidToday)
+	if !remaining.IsPositive() {
+		return decimal.Zero
+	}
+	return decimal.Min(amount, remaining)
+}
+
+// excludesUser 检查用户是否被排除
+func (c *CommissionConfig) excludesUser(userID uint64) bool {
+	for _, id := range c.ExcludeUserIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// excludesSource 检查请求来源是否被排除
+func (c *CommissionConfig) excludesSource(source string) bool {
+	source = strings.ToLower(source)
+	for _, excluded := range c.ExcludeSources {
+		if excluded == source {
+			return true
+		}
+	}
+	return false
+}
+
+// ICommissionLogic 多级佣金分配业务逻辑接口
+type ICommissionLogic interface {
+	// DistributeInTx 在源交易所在的事务中沿关系链分配佣金，返回生成的佣金记录；
+	// 未启用、无匹配规则或源交易已分配过时不做任何操作
+	DistributeInTx(ctx context.Context, tx gdb.TX, source *CommissionSource) ([]*entity.CommissionRecords, error)
+}
+
+type commissionLogic struct {
+	tokenLogic     ITokenLogic
+	operationLogic IOperationLogic
+	context        *SharedLogicContext
+}
+
+// NewCommissionLogic 创建佣金分配业务逻辑实例
+func NewCommissionLogic() ICommissionLogic {
+	return &commissionLogic{
+		tokenLogic:     NewTokenLogic(),
+		operationLogic: NewOperationLogic(),
+		context:        GetSharedContext(),
+	}
+}
+
+// loadConfig 读取佣金配置（wallet.commission），配置无效时不分配佣金
+func (l *commissionLogic) loadConfig(ctx context.Context) CommissionConfig {
+	value, err := g.Cfg().Get(ctx, "wallet.commission")
+	if err != nil || value == nil || value.IsEmpty() {
+		return CommissionConfig{}
+	}
+	config, err := ParseCommissionConfig(value.Map())
+	if err != nil {
+		g.Log().Errorf(ctx, "佣金配置无效，已停用佣金分配: %v", err)
+		return CommissionConfig{}
+	}
+	return config
+}
+
+// DistributeInTx 在源交易所在的事务中沿关系链分配佣金
+func (l *commissionLogic) DistributeInTx(ctx context.Context, tx gdb.TX, source *CommissionSource) ([]*entity.CommissionRecords, error) {
+	config := l.loadConfig(ctx)
+	if !config.Enabled {
+		return nil, nil
+	}
+	rule := MatchCommissionRule(config.Rules, source.FundType, source.TokenSymbol)
+	if rule == nil || source.Amount.LessThan(rule.MinSourceAmount) {
+		return nil, nil
+	}
+	if config.excludesUser(source.UserID) || config.excludesSource(source.RequestSource) {
+		return nil, nil
+	}
+
+	dao := l.context.GetCommissionDAO()
+	distributed, err := dao.HasSourceRecords(ctx, tx, source.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	if distributed {
+		return nil, nil
+	}
+
+	beneficiaries, err := l.resolveChain(ctx, source.UserID, rule.Chain, len(rule.Rates))
+	if err != nil {
+		return nil, err
+	}
+	if len(beneficiaries) == 0 {
+		return nil, nil
+	}
+	token, err := l.tokenLogic.GetTokenBySymbol(ctx, source.TokenSymbol)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "获取代币信息失败: Symbol=%s", source.TokenSymbol)
+	}
+
+	var records []*entity.CommissionRecords
+	for i, beneficiary := range beneficiaries {
+		level := i + 1
+		// 已停用、被排除或回到自身的层级不发放，也不向上顺延
+		if beneficiary == nil || beneficiary.IsStop == 1 || beneficiary.Id == source.UserID || config.excludesUser(beneficiary.Id) {
+			continue
+		}
+
+		amount := CalculateCommission(rule, level, source.Amount, int32(token.Decimals))
+		if config.DailyCap.IsPositive() && amount.IsPositive() {
+			paidToday, err := dao.SumBeneficiaryAmountSince(ctx, tx, beneficiary.Id, token.Symbol, gtime.Now().StartOfDay())
+			if err != nil {
+				return nil, err
+			}
+			amount = ApplyCommissionDailyCap(amount, paidToday, config.DailyCap)
+		}
+		if !amount.IsPositive() {
+			continue
+		}
+
+		result, err := l.operationLogic.ExecuteInTx(ctx, tx, &FinancialOperationRequest{
+			UserID:        beneficiary.Id,
+			TokenSymbol:   token.Symbol,
+			Amount:        amount,
+			OperationType: OperationTypeCredit,
+			FundType:      string(rule.CommissionFundType),
+			BusinessID:    fmt.Sprintf("commission_%d_%d", source.TransactionID, level),
+			Description:   fmt.Sprintf("Level %d commission from transaction %d", level, source.TransactionID),
+			Metadata: map[string]string{
+				CommissionMetadataSourceTransactionID: strconv.FormatUint(source.TransactionID, 10),
+				CommissionMetadataSourceUserID:        strconv.FormatUint(source.UserID, 10),
+				CommissionMetadataLevel:               strconv.Itoa(level),
+			},
+		})
+		if err != nil {
+			return nil, gerror.Wrapf(err, "佣金入账失败: Level=%d, BeneficiaryID=%d", level, beneficiary.Id)
+		}
+
+		record := &entity.CommissionRecords{
+			SourceTransactionId: source.TransactionID,
+			SourceUserId:        source.UserID,
+			SourceFundType:      string(source.FundType),
+			SourceAmount:        source.Amount,
+			Symbol:              token.Symbol,
+			BeneficiaryId:       beneficiary.Id,
+			Level:               level,
+			Chain:               string(rule.Chain),
+			Rate:                rule.Rates[i],
+			Amount:              amount,
+			FundType:            string(rule.CommissionFundType),
+			TransactionId:       uint64(result.TransactionID),
+			CreatedAt:           gtime.Now(),
+		}
+		if err := dao.CreateRecord(ctx, tx, record); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	if len(records) > 0 {
+		g.Log().Infof(ctx, "佣金分配完成: SourceTransactionID=%d, FundType=%s, Levels=%d", source.TransactionID, source.FundType, len(records))
+	}
+	return records, nil
+}
+
+// resolveChain 解析关系链上的受益人，返回值下标对应层级（不存在的用户为 nil）
+func (l *commissionLogic) resolveChain(ctx context.Context, userID uint64, chain CommissionChain, levels int) ([]*entity.Users, error) {
+	userDAO := l.context.GetUserDAO()
+	user, err := userDAO.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, gerror.Newf("用户不存在: UserID=%d", userID)
+	}
+
+	var beneficiaries []*entity.Users
+	if chain == CommissionChainAgent {
+		ids := AgentChain(user)
+		if len(ids) > levels {
+			ids = ids[:levels]
+		}
+		for _, id := range ids {
+			if id == 0 {
+				beneficiaries = append(beneficiaries, nil)
+				continue
+			}
+			beneficiary, err := userDAO.GetUserByID(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			beneficiaries = append(beneficiaries, beneficiary)
+		}
+		return beneficiaries, nil
+	}
+
+	// 推荐链：逐级向上查找推荐人，遇到循环引用或推荐人不存在时停止
+	visited := map[uint64]bool{user.Id: true}
+	current := user
+	for len(beneficiaries) < levels && current.RecommendId != 0 && !visited[current.RecommendId] {
+		visited[current.RecommendId] = true
+		parent, err := userDAO.GetUserByID(ctx, current.RecommendId)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil {
+			break
+		}
+		beneficiaries = append(beneficiaries, parent)
+		current = parent
+	}
+	return beneficiaries, nil
+}
@@ -0,0 +1,122 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/constants"
+	"github.com/yalks/wallet/entity"
+)
+
+func TestParseCommissionConfig(t *testing.T) {
+	config, err := ParseCommissionConfig(map[string]interface{}{
+		"enabled":        true,
+		"dailyCap":       "100",
+		"excludeUserIds": []interface{}{7, "8"},
+		"excludeSources": []interface{}{" Admin "},
+		"rules": []interface{}{
+			map[string]interface{}{
+				"fundType":        "deposit",
+				"rates":           []interface{}{"0.01", "0.005"},
+				"minSourceAmount": "10",
+				"maxAmount":       "5",
+			},
+			map[string]interface{}{
+				"fundType":           "withdraw",
+				"symbol":             "USDT",
+				"chain":              "agent",
+				"commissionFundType": "referral_bonus",
+				"rates":              []interface{}{"0.002"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !config.Enabled || !config.DailyCap.Equal(decimal.NewFromInt(100)) || len(config.Rules) != 2 {
+		t.Fatalf("unexpected config: %+v", config)
+	}
+	if !config.excludesUser(8) || !config.excludesSource("admin") || config.excludesSource("api") {
+		t.Error("exclusions not parsed")
+	}
+	first := config.Rules[0]
+	if first.Chain != CommissionChainReferral || first.CommissionFundType != constants.FundTypeCommission || len(first.Rates) != 2 {
+		t.Errorf("defaults not applied: %+v", first)
+	}
+
+	invalid := []map[string]interface{}{
+		{"rules": []interface{}{map[string]interface{}{"fundType": "unknown", "rates": []interface{}{"0.1"}}}},
+		{"rules": []interface{}{map[string]interface{}{"fundType": "deposit"}}},
+		{"rules": []interface{}{map[string]interface{}{"fundType": "deposit", "rates": []interface{}{"1.5"}}}},
+		{"rules": []interface{}{map[string]interface{}{"fundType": "deposit", "chain": "agent", "rates": []interface{}{"0.1", "0.1", "0.1", "0.1"}}}},
+		{"rules": []interface{}{map[string]interface{}{"fundType": "commission", "rates": []interface{}{"0.1"}}}},
+		{"rules": []interface{}{map[string]interface{}{"fundType": "deposit", "commissionFundType": "withdraw", "rates": []interface{}{"0.1"}}}},
+		{"dailyCap": "-1"},
+	}
+	for i, raw := range invalid {
+		if _, err := ParseCommissionConfig(raw); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+func TestMatchCommissionRule(t *testing.T) {
+	rules := []CommissionRule{
+		{FundType: constants.FundTypeDeposit, Rates: []decimal.Decimal{decimal.RequireFromString("0.01")}},
+		{FundType: constants.FundTypeDeposit, Symbol: "BTC", Rates: []decimal.Decimal{decimal.RequireFromString("0.02")}},
+	}
+	if rule := MatchCommissionRule(rules, constants.FundTypeDeposit, "btc"); rule == nil || rule.Symbol != "BTC" {
+		t.Errorf("expected token-specific rule, got %+v", rule)
+	}
+	if rule := MatchCommissionRule(rules, constants.FundTypeDeposit, "USDT"); rule == nil || rule.Symbol != "" {
+		t.Errorf("expected generic rule, got %+v", rule)
+	}
+	if rule := MatchCommissionRule(rules, constants.FundTypeWithdraw, "USDT"); rule != nil {
+		t.Errorf("expected no rule, got %+v", rule)
+	}
+}
+
+func TestCalculateCommission(t *testing.T) {
+	rule := &CommissionRule{
+		Rates:     []decimal.Decimal{decimal.RequireFromString("0.01"), decimal.RequireFromString("0.005")},
+		MaxAmount: decimal.RequireFromString("3"),
+	}
+	tests := []struct {
+		level    int
+		amount   string
+		expected string
+	}{
+		{1, "100", "1"},
+		{2, "100", "0.5"},
+		{1, "1000", "3"},
+		{2, "0.333333", "0.001666"},
+		{3, "100", "0"},
+	}
+	for _, tt := range tests {
+		got := CalculateCommission(rule, tt.level, decimal.RequireFromString(tt.amount), 6)
+		if !got.Equal(decimal.RequireFromString(tt.expected)) {
+			t.Errorf("level %d of %s: expected %s, got %s", tt.level, tt.amount, tt.expected, got)
+		}
+	}
+}
+
+func TestApplyCommissionDailyCap(t *testing.T) {
+	d := decimal.RequireFromString
+	if got := ApplyCommissionDailyCap(d("5"), d("98"), d("100")); !got.Equal(d("2")) {
+		t.Errorf("expected remaining cap of 2, got %s", got)
+	}
+	if got := ApplyCommissionDailyCap(d("5"), d("100"), d("100")); !got.IsZero() {
+		t.Errorf("expected zero after cap reached, got %s", got)
+	}
+	if got := ApplyCommissionDailyCap(d("5"), d("1000"), decimal.Zero); !got.Equal(d("5")) {
+		t.Errorf("expected no cap, got %s", got)
+	}
+}
+
+func TestAgentChain(t *testing.T) {
+	chain := AgentChain(&entity.Users{FirstId: 3, SecondId: 0, ThirdId: 9})
+	if len(chain) != 3 || chain[0] != 3 || chain[1] != 0 || chain[2] != 9 {
+		t.Errorf("unexpected agent chain: %v", chain)
+	}
+}
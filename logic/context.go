@@ -19,6 +19,23 @@ type SharedLogicContext struct {
 	walletDAO      dao.IWalletDAO
 	transactionDAO dao.ITransactionDAO
 
+	// 扩展DAO
+	withdrawAddressDAO    dao.IWithdrawAddressDAO
+	scheduledOperationDAO dao.IScheduledOperationDAO
+	recurringOperationDAO dao.IRecurringOperationDAO
+	batchTransferDAO      dao.IBatchTransferDAO
+	bulkPayoutDAO         dao.IBulkPayoutDAO
+	webhookDAO            dao.IWebhookDAO
+	domainEventDAO        dao.IDomainEventDAO
+	transactionTagDAO     dao.ITransactionTagDAO
+	balanceSnapshotDAO    dao.IBalanceSnapshotDAO
+	auditDAO              dao.IAuditDAO
+	adjustmentDAO         dao.IAdjustmentDAO
+	commissionDAO         dao.ICommissionDAO
+	velocityDAO           dao.IVelocityDAO
+	apiKeyDAO             dao.IAPIKeyDAO
+	migrationDAO          dao.IMigrationDAO
+
 	// 钱包SDK - 暂时禁用远程钱包功能
 	// walletSDK ledgerwalletsdk.IWallet
 
@@ -40,6 +57,22 @@ func GetSharedContext() *SharedLogicContext {
 			tokenDAO:       dao.NewTokenDAO(),
 			walletDAO:      dao.NewWalletDAO(),
 			transactionDAO: dao.NewTransactionDAO(),
+
+			withdrawAddressDAO:    dao.NewWithdrawAddressDAO(),
+			scheduledOperationDAO: dao.NewScheduledOperationDAO(),
+			recurringOperationDAO: dao.NewRecurringOperationDAO(),
+			batchTransferDAO:      dao.NewBatchTransferDAO(),
+			bulkPayoutDAO:         dao.NewBulkPayoutDAO(),
+			webhookDAO:            dao.NewWebhookDAO(),
+			domainEventDAO:        dao.NewDomainEventDAO(),
+			transactionTagDAO:     dao.NewTransactionTagDAO(),
+			balanceSnapshotDAO:    dao.NewBalanceSnapshotDAO(),
+			auditDAO:              dao.NewAuditDAO(),
+			adjustmentDAO:         dao.NewAdjustmentDAO(),
+			commissionDAO:         dao.NewCommissionDAO(),
+			velocityDAO:           dao.NewVelocityDAO(),
+			apiKeyDAO:             dao.NewAPIKeyDAO(),
+			migrationDAO:          dao.NewMigrationDAO(),
 		}
 		// sharedContext.initWalletSDK() // 暂时禁用远程钱包SDK初始化
 		sharedContext.initialized = true
@@ -85,6 +118,111 @@ func (c *SharedLogicContext) GetTransactionDAO() dao.ITransactionDAO {
 	return c.transactionDAO
 }
 
+// GetWithdrawAddressDAO 获取提现地址簿DAO
+func (c *SharedLogicContext) GetWithdrawAddressDAO() dao.IWithdrawAddressDAO {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.withdrawAddressDAO
+}
+
+// GetScheduledOperationDAO 获取定时操作DAO
+func (c *SharedLogicContext) GetScheduledOperationDAO() dao.IScheduledOperationDAO {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.scheduledOperationDAO
+}
+
+// GetRecurringOperationDAO 获取循环操作DAO
+func (c *SharedLogicContext) GetRecurringOperationDAO() dao.IRecurringOperationDAO {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.recurringOperationDAO
+}
+
+// GetBatchTransferDAO 获取批量转账DAO
+func (c *SharedLogicContext) GetBatchTransferDAO() dao.IBatchTransferDAO {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.batchTransferDAO
+}
+
+// GetBulkPayoutDAO 获取批量发放DAO
+func (c *SharedLogicContext) GetBulkPayoutDAO() dao.IBulkPayoutDAO {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.bulkPayoutDAO
+}
+
+// GetWebhookDAO 获取Webhook DAO
+func (c *SharedLogicContext) GetWebhookDAO() dao.IWebhookDAO {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.webhookDAO
+}
+
+// GetDomainEventDAO 获取领域事件DAO
+func (c *SharedLogicContext) GetDomainEventDAO() dao.IDomainEventDAO {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.domainEventDAO
+}
+
+// GetTransactionTagDAO 获取交易标签DAO
+func (c *SharedLogicContext) GetTransactionTagDAO() dao.ITransactionTagDAO {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.transactionTagDAO
+}
+
+// GetBalanceSnapshotDAO 获取余额快照DAO
+func (c *SharedLogicContext) GetBalanceSnapshotDAO() dao.IBalanceSnapshotDAO {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.balanceSnapshotDAO
+}
+
+// GetAuditDAO 获取审计DAO
+func (c *SharedLogicContext) GetAuditDAO() dao.IAuditDAO {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.auditDAO
+}
+
+// GetAdjustmentDAO 获取调账审批DAO
+func (c *SharedLogicContext) GetAdjustmentDAO() dao.IAdjustmentDAO {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.adjustmentDAO
+}
+
+// GetCommissionDAO 获取佣金记录DAO
+func (c *SharedLogicContext) GetCommissionDAO() dao.ICommissionDAO {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.commissionDAO
+}
+
+// GetVelocityDAO 获取限额计数器
+func (c *SharedLogicContext) GetVelocityDAO() dao.IVelocityDAO {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.velocityDAO
+}
+
+// GetAPIKeyDAO 获取 API Key DAO
+func (c *SharedLogicContext) GetAPIKeyDAO() dao.IAPIKeyDAO {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.apiKeyDAO
+}
+
+// GetMigrationDAO 获取数据库迁移DAO
+func (c *SharedLogicContext) GetMigrationDAO() dao.IMigrationDAO {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.migrationDAO
+}
+
 // GetWalletSDK 获取钱包SDK - 暂时禁用，返回nil
 func (c *SharedLogicContext) GetWalletSDK() any { // ledgerwalletsdk.IWallet
 	c.mu.RLock()
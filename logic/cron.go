@@ -0,0 +1,166 @@
+package logic
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+)
+
+// CronSchedule 标准 5 段 cron 表达式（分 时 日 月 周）
+type CronSchedule struct {
+	minute uint64 // bit 0-59
+	hour   uint64 // bit 0-23
+	dom    uint64 // bit 1-31
+	month  uint64 // bit 1-12
+	dow    uint64 // bit 0-6 (0 = 周日)
+
+	domAny bool // 日字段为 *
+	dowAny bool // 周字段为 *
+}
+
+// cronDescriptors 常用预定义表达式
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronSearchLimit 查找下次执行时间的最大范围，防止无法满足的表达式（如 2 月 30 日）死循环
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+// ParseCronExpression 解析 cron 表达式，支持 *、列表(,)、范围(-)、步长(/) 以及 @daily 等预定义表达式
+func ParseCronExpression(expr string) (*CronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if descriptor, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+		expr = descriptor
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, gerror.Newf("cron 表达式必须包含 5 个字段（分 时 日 月 周）: %s", expr)
+	}
+
+	schedule := &CronSchedule{
+		domAny: fields[2] == "*" || fields[2] == "?",
+		dowAny: fields[4] == "*" || fields[4] == "?",
+	}
+
+	var err error
+	if schedule.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, gerror.Wrap(err, "分钟字段无效")
+	}
+	if schedule.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, gerror.Wrap(err, "小时字段无效")
+	}
+	if schedule.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, gerror.Wrap(err, "日字段无效")
+	}
+	if schedule.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, gerror.Wrap(err, "月字段无效")
+	}
+	if schedule.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, gerror.Wrap(err, "周字段无效")
+	}
+	// 7 与 0 均表示周日
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1
+		schedule.dow &^= 1 << 7
+	}
+
+	return schedule, nil
+}
+
+// parseCronField 解析单个 cron 字段为位图
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		if part == "" {
+			return 0, gerror.Newf("空的字段片段: %s", field)
+		}
+
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			rangePart = part[:i]
+			s, err := strconv.Atoi(part[i+1:])
+			if err != nil || s <= 0 {
+				return 0, gerror.Newf("无效的步长: %s", part)
+			}
+			step = s
+		}
+
+		start, end := min, max
+		switch {
+		case rangePart == "*" || rangePart == "?":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			lo, err1 := strconv.Atoi(bounds[0])
+			hi, err2 := strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil {
+				return 0, gerror.Newf("无效的范围: %s", part)
+			}
+			start, end = lo, hi
+		default:
+			v, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, gerror.Newf("无效的数值: %s", part)
+			}
+			start = v
+			if strings.Contains(part, "/") {
+				end = max
+			} else {
+				end = v
+			}
+		}
+
+		if start < min || end > max || start > end {
+			return 0, gerror.Newf("取值超出范围 [%d-%d]: %s", min, max, part)
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// Next 返回严格晚于 t 的下一次执行时间（分钟精度，使用 t 所在时区），无法满足时返回零值
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(cronSearchLimit)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches 日与周字段的匹配规则：两者均有限定时满足任一即可（与标准 cron 一致）
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
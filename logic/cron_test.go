@@ -0,0 +1,108 @@
+package logic
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronExpression(t *testing.T) {
+	tests := []struct {
+		expr  string
+		valid bool
+	}{
+		{"* * * * *", true},
+		{"*/15 9-17 * * 1-5", true},
+		{"0 0 1,15 * *", true},
+		{"0 12 * * 7", true},
+		{"@daily", true},
+		{"@monthly", true},
+		{"0 0 * *", false},
+		{"60 * * * *", false},
+		{"0 24 * * *", false},
+		{"0 0 0 * *", false},
+		{"*/0 * * * *", false},
+		{"5-1 * * * *", false},
+		{"a * * * *", false},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseCronExpression(tt.expr)
+		if tt.valid && err != nil {
+			t.Errorf("ParseCronExpression(%q) unexpected error: %v", tt.expr, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("ParseCronExpression(%q) expected error", tt.expr)
+		}
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	base := time.Date(2024, 1, 31, 10, 7, 30, 0, time.UTC) // 周三
+	tests := []struct {
+		expr     string
+		from     time.Time
+		expected time.Time
+	}{
+		{"* * * * *", base, time.Date(2024, 1, 31, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", base, time.Date(2024, 1, 31, 10, 15, 0, 0, time.UTC)},
+		{"0 9 * * *", base, time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", base, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", base, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"30 8 * * 1", base, time.Date(2024, 2, 5, 8, 30, 0, 0, time.UTC)},
+		{"0 0 * * 0", base, time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", base, time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 1 *", base, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// 日与周同时限定时满足任一即可：2 月 1 日（周四）早于下一个周一
+		{"0 0 1 * 1", base, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		schedule, err := ParseCronExpression(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCronExpression(%q) error: %v", tt.expr, err)
+		}
+		if got := schedule.Next(tt.from); !got.Equal(tt.expected) {
+			t.Errorf("Next(%q) = %s, want %s", tt.expr, got, tt.expected)
+		}
+	}
+
+	impossible, _ := ParseCronExpression("0 0 30 2 *")
+	if got := impossible.Next(base); !got.IsZero() {
+		t.Errorf("expected zero time for impossible schedule, got %s", got)
+	}
+}
+
+func TestRecurringScheduleNextAfter(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	interval, err := ParseRecurringSchedule(RecurringScheduleInterval, "24h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := interval.NextAfter(start, start); !got.Equal(start.Add(24 * time.Hour)) {
+		t.Errorf("interval NextAfter = %s", got)
+	}
+	// 停机 3.5 天后恢复，跳过错过的执行
+	now := start.Add(84 * time.Hour)
+	if got := interval.NextAfter(start, now); !got.Equal(start.Add(96 * time.Hour)) {
+		t.Errorf("interval NextAfter after downtime = %s", got)
+	}
+
+	cron, err := ParseRecurringSchedule(RecurringScheduleCron, "0 0 1 * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cron.First(start); !got.Equal(start) {
+		t.Errorf("cron First = %s", got)
+	}
+	if got := cron.NextAfter(start, start); !got.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("cron NextAfter = %s", got)
+	}
+
+	if _, err := ParseRecurringSchedule(RecurringScheduleInterval, "30s"); err == nil {
+		t.Error("expected error for interval below minimum")
+	}
+	if _, err := ParseRecurringSchedule("weekly", "1"); err == nil {
+		t.Error("expected error for unknown schedule type")
+	}
+}
@@ -0,0 +1,182 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/entity"
+)
+
+// DomainEventType 领域事件类型
+type DomainEventType string
+
+const (
+	EventFundsCredited      DomainEventType = "funds.credited"             // 入账
+	EventFundsDebited       DomainEventType = "funds.debited"              // 出账
+	EventTransferCompleted  DomainEventType = "transfer.completed"         // 转账完成（发送方钱包）
+	EventTransactionChanged DomainEventType = "transaction.status_changed" // 交易状态变更
+)
+
+// DomainEvent 已发布的领域事件
+type DomainEvent struct {
+	ID          int64           `json:"id"`           // 事件ID（全局递增）
+	Type        DomainEventType `json:"type"`         // 事件类型
+	AggregateID string          `json:"aggregate_id"` // 聚合ID（钱包: 用户ID:代币符号）
+	Sequence    uint64          `json:"sequence"`     // 聚合内序号，同一钱包的事件按序号递增发布
+	UserID      uint64          `json:"user_id"`      // 用户ID
+	TokenSymbol string          `json:"token_symbol"` // 代币符号
+	BusinessID  string          `json:"business_id"`  // 业务ID
+	Payload     json.RawMessage `json:"payload"`      // 事件负载
+	OccurredAt  time.Time       `json:"occurred_at"`  // 事件发生时间
+}
+
+// Publisher 领域事件发布器。Publish 返回 nil 表示事件已被可靠接收；
+// 返回错误时中继会稍后重试，因此实现必须能容忍重复投递（按 ID 或聚合序号去重）
+type Publisher interface {
+	Publish(ctx context.Context, event *DomainEvent) error
+}
+
+// FundsChangedPayload 入账/出账事件负载
+type FundsChangedPayload struct {
+	TransactionID int64           `json:"transaction_id"`      // 交易ID
+	FundType      string          `json:"fund_type,omitempty"` // 资金类型或操作类型
+	Amount        decimal.Decimal `json:"amount"`              // 金额
+	BalanceBefore decimal.Decimal `json:"balance_before"`      // 操作前余额
+	BalanceAfter  decimal.Decimal `json:"balance_after"`       // 操作后余额
+	Description   string          `json:"description,omitempty"`
+}
+
+// TransferCompletedPayload 转账完成事件负载
+type TransferCompletedPayload struct {
+	FromUserID        uint64          `json:"from_user_id"`        // 发送方用户ID
+	ToUserID          uint64          `json:"to_user_id"`          // 接收方用户ID
+	Amount            decimal.Decimal `json:"amount"`              // 金额
+	FromTransactionID string          `json:"from_transaction_id"` // 发送方交易ID
+	ToTransactionID   string          `json:"to_transaction_id"`   // 接收方交易ID
+	FromBalanceAfter  decimal.Decimal `json:"from_balance_after"`  // 发送方余额
+	ToBalanceAfter    decimal.Decimal `json:"to_balance_after"`    // 接收方余额
+}
+
+// TransactionStatusChangedPayload 交易状态变更事件负载
+type TransactionStatusChangedPayload struct {
+	TransactionID int64  `json:"transaction_id"` // 交易ID
+	OldStatus     string `json:"old_status"`     // 原状态
+	NewStatus     string `json:"new_status"`     // 新状态
+}
+
+// WalletAggregateID 钱包聚合ID，同一聚合内的事件按顺序发布
+func WalletAggregateID(userID uint64, tokenSymbol string) string {
+	return fmt.Sprintf("%d:%s", userID, tokenSymbol)
+}
+
+// DomainEventKey 事件去重键：同一业务ID的同类事件只记录一次（幂等重放时不会重复产生事件）
+func DomainEventKey(eventType DomainEventType, businessID string) string {
+	return string(eventType) + ":" + businessID
+}
+
+// RecordEventRequest 记录领域事件请求
+type RecordEventRequest struct {
+	Type        DomainEventType // 事件类型
+	UserID      uint64          // 钱包所属用户
+	TokenSymbol string          // 钱包代币
+	BusinessID  string          // 业务ID（用于去重）
+	Payload     any             // 事件负载，序列化为 JSON
+}
+
+// IDomainEventLogic 领域事件业务逻辑接口
+type IDomainEventLogic interface {
+	// RecordInTx 在业务事务中写入领域事件，分配钱包内序号；同一去重键已存在时忽略。
+	// tx 为空时使用 ctx 中携带的事务（g.DB().Transaction 回调内的 ctx）；
+	// 两者都没有时返回错误，事件不会脱离业务事务单独提交
+	RecordInTx(ctx context.Context, tx gdb.TX, req *RecordEventRequest) error
+}
+
+type domainEventLogic struct {
+	context *SharedLogicContext
+}
+
+// NewDomainEventLogic 创建领域事件业务逻辑实例
+func NewDomainEventLogic() IDomainEventLogic {
+	return &domainEventLogic{
+		context: GetSharedContext(),
+	}
+}
+
+// inTransaction 判断写入是否处于事务中：tx 不为空，或 ctx 携带默认分组的事务
+func inTransaction(ctx context.Context, tx gdb.TX) bool {
+	return tx != nil || gdb.TXFromCtx(ctx, g.DB().GetGroup()) != nil
+}
+
+// RecordInTx 在业务事务中写入领域事件，分配钱包内序号；同一去重键已存在时忽略
+func (l *domainEventLogic) RecordInTx(ctx context.Context, tx gdb.TX, req *RecordEventRequest) error {
+	if !inTransaction(ctx, tx) {
+		return gerror.New("领域事件必须在业务事务中写入")
+	}
+	if req.BusinessID == "" {
+		return gerror.Newf("领域事件缺少业务ID: Type=%s", req.Type)
+	}
+
+	dao := l.context.GetDomainEventDAO()
+	eventKey := DomainEventKey(req.Type, req.BusinessID)
+	exists, err := dao.ExistsEventKey(ctx, tx, eventKey)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	payload, err := json.Marshal(req.Payload)
+	if err != nil {
+		return gerror.Wrapf(err, "序列化领域事件负载失败: Type=%s", req.Type)
+	}
+
+	// 聚合内序号在业务事务中基于锁定读分配，调用方还应先锁定钱包行；(aggregate_id, sequence) 唯一约束兜底，不会产生乱序
+	aggregateID := WalletAggregateID(req.UserID, req.TokenSymbol)
+	sequence, err := dao.NextSequence(ctx, tx, aggregateID)
+	if err != nil {
+		return err
+	}
+
+	now := gtime.Now()
+	_, err = dao.CreateEvent(ctx, tx, &entity.DomainEvents{
+		EventKey:      eventKey,
+		EventType:     string(req.Type),
+		AggregateId:   aggregateID,
+		Sequence:      sequence,
+		UserId:        req.UserID,
+		Symbol:        req.TokenSymbol,
+		BusinessId:    req.BusinessID,
+		Payload:       string(payload),
+		Status:        "pending",
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	})
+	return err
+}
+
+// ConvertToDomainEvent 将出站记录转换为发布的领域事件
+func ConvertToDomainEvent(record *entity.DomainEvents) *DomainEvent {
+	event := &DomainEvent{
+		ID:          record.Id,
+		Type:        DomainEventType(record.EventType),
+		AggregateID: record.AggregateId,
+		Sequence:    record.Sequence,
+		UserID:      record.UserId,
+		TokenSymbol: record.Symbol,
+		BusinessID:  record.BusinessId,
+		Payload:     json.RawMessage(record.Payload),
+	}
+	if record.CreatedAt != nil {
+		event.OccurredAt = record.CreatedAt.Time
+	}
+	return event
+}
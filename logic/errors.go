@@ -0,0 +1,21 @@
+package logic
+
+import (
+	"github.com/gogf/gf/v2/errors/gcode"
+)
+
+// 钱包业务错误码，可通过 gerror.Code(err) 在错误链中识别
+var (
+	CodeInsufficientBalance    = gcode.New(10001, "余额不足", nil)
+	CodeRequestExpired         = gcode.New(10002, "请求已过期", nil)
+	CodeIdempotencyConflict    = gcode.New(10003, "幂等键已被不同的请求使用", nil)
+	CodeVelocityLimit          = gcode.New(10004, "超出交易限额", nil)
+	CodeRiskBlocked            = gcode.New(10005, "交易被风控拦截", nil)
+	CodeRiskChallenge          = gcode.New(10006, "交易需要验证支付密码", nil)
+	CodePolicyDenied           = gcode.New(10007, "操作被策略拒绝", nil)
+	CodePolicyApprovalRequired = gcode.New(10008, "操作需要审批", nil)
+	CodeAPIKeyForbidden        = gcode.New(10009, "超出 API Key 权限范围", nil)
+	CodeAPIKeyRateLimited      = gcode.New(10010, "超出 API Key 请求频率限制", nil)
+	CodeSubscriptionLagged     = gcode.New(10011, "订阅消费过慢，已断开", nil)
+	CodeResumeUnavailable      = gcode.New(10012, "无法从指定事件恢复订阅", nil)
+)
@@ -0,0 +1,139 @@
+package logic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+)
+
+// 领域事件 HTTP 请求头
+const (
+	EventHeaderID       = "X-Wallet-Event-Id"       // 事件ID
+	EventHeaderSequence = "X-Wallet-Event-Sequence" // 聚合内序号
+)
+
+// ChannelPublisher 进程内通道发布器，适用于同一进程内的消费者
+type ChannelPublisher struct {
+	events chan *DomainEvent
+}
+
+// NewChannelPublisher 创建进程内通道发布器，buffer 为通道缓冲大小
+func NewChannelPublisher(buffer int) *ChannelPublisher {
+	return &ChannelPublisher{events: make(chan *DomainEvent, buffer)}
+}
+
+// Events 获取事件通道
+func (p *ChannelPublisher) Events() <-chan *DomainEvent {
+	return p.events
+}
+
+// Publish 将事件写入通道，通道已满时阻塞直到被消费或 ctx 结束
+func (p *ChannelPublisher) Publish(ctx context.Context, event *DomainEvent) error {
+	select {
+	case p.events <- event:
+		return nil
+	case <-ctx.Done():
+		return gerror.Wrap(ctx.Err(), "写入事件通道超时")
+	}
+}
+
+// FilePublisher 本地文件发布器，每个事件追加为一行 JSON（JSON Lines）并同步落盘
+type FilePublisher struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFilePublisher 创建本地文件发布器，文件不存在时自动创建
+func NewFilePublisher(path string) (*FilePublisher, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "打开事件文件失败: %s", path)
+	}
+	return &FilePublisher{file: file}, nil
+}
+
+// Publish 追加事件到文件
+func (p *FilePublisher) Publish(ctx context.Context, event *DomainEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return gerror.Wrap(err, "序列化领域事件失败")
+	}
+	line = append(line, '\n')
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, err := p.file.Write(line); err != nil {
+		return gerror.Wrapf(err, "写入事件文件失败: %s", p.file.Name())
+	}
+	if err := p.file.Sync(); err != nil {
+		return gerror.Wrapf(err, "同步事件文件失败: %s", p.file.Name())
+	}
+	return nil
+}
+
+// Close 关闭文件
+func (p *FilePublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.file.Close()
+}
+
+// HTTPPublisher HTTP 发布器，以 JSON 请求体 POST 每个事件，2xx 视为成功
+type HTTPPublisher struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// NewHTTPPublisher 创建 HTTP 发布器；secret 不为空时按 Webhook 相同规则签名
+func NewHTTPPublisher(url, secret string, timeout time.Duration) *HTTPPublisher {
+	return &HTTPPublisher{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Publish 发送事件
+func (p *HTTPPublisher) Publish(ctx context.Context, event *DomainEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return gerror.Wrap(err, "序列化领域事件失败")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return gerror.Wrapf(err, "构建事件请求失败: %s", p.url)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookHeaderEvent, string(event.Type))
+	req.Header.Set(EventHeaderID, strconv.FormatInt(event.ID, 10))
+	req.Header.Set(EventHeaderSequence, strconv.FormatUint(event.Sequence, 10))
+	if p.secret != "" {
+		timestamp := time.Now().Unix()
+		req.Header.Set(WebhookHeaderTimestamp, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(WebhookHeaderSignature, SignWebhookPayload(p.secret, timestamp, body))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return gerror.Wrapf(err, "发送事件请求失败: %s", p.url)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponseBody))
+		return gerror.Newf("事件接收方返回非成功状态: %d %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
@@ -0,0 +1,113 @@
+package logic
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func testDomainEvent(id int64, sequence uint64) *DomainEvent {
+	return &DomainEvent{
+		ID:          id,
+		Type:        EventFundsCredited,
+		AggregateID: WalletAggregateID(1001, "USDT"),
+		Sequence:    sequence,
+		UserID:      1001,
+		TokenSymbol: "USDT",
+		BusinessID:  "deposit_" + strconv.FormatInt(id, 10),
+		Payload:     json.RawMessage(`{"amount":"10"}`),
+		OccurredAt:  time.Unix(1700000000, 0).UTC(),
+	}
+}
+
+func TestChannelPublisher(t *testing.T) {
+	publisher := NewChannelPublisher(1)
+	if err := publisher.Publish(context.Background(), testDomainEvent(1, 1)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := <-publisher.Events(); got.ID != 1 {
+		t.Errorf("expected event 1, got %d", got.ID)
+	}
+
+	// 通道已满时随 ctx 超时返回错误，中继会稍后重试
+	_ = publisher.Publish(context.Background(), testDomainEvent(2, 2))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := publisher.Publish(ctx, testDomainEvent(3, 3)); err == nil {
+		t.Error("expected error when channel is full")
+	}
+}
+
+func TestFilePublisher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	publisher, err := NewFilePublisher(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := int64(1); i <= 3; i++ {
+		if err := publisher.Publish(context.Background(), testDomainEvent(i, uint64(i))); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := publisher.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer file.Close()
+
+	var sequences []uint64
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event DomainEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("invalid line %q: %v", scanner.Text(), err)
+		}
+		sequences = append(sequences, event.Sequence)
+	}
+	if len(sequences) != 3 || sequences[0] != 1 || sequences[2] != 3 {
+		t.Errorf("unexpected sequences: %v", sequences)
+	}
+}
+
+func TestHTTPPublisher(t *testing.T) {
+	var (
+		gotBody    []byte
+		gotHeaders http.Header
+		status     = http.StatusOK
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeaders = r.Header.Clone()
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	publisher := NewHTTPPublisher(server.URL, "secret", time.Second)
+	if err := publisher.Publish(context.Background(), testDomainEvent(7, 3)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotHeaders.Get(EventHeaderID) != "7" || gotHeaders.Get(EventHeaderSequence) != "3" {
+		t.Errorf("unexpected headers: %v", gotHeaders)
+	}
+	timestamp, _ := strconv.ParseInt(gotHeaders.Get(WebhookHeaderTimestamp), 10, 64)
+	if !VerifyWebhookSignature("secret", timestamp, gotBody, gotHeaders.Get(WebhookHeaderSignature), 0, time.Now()) {
+		t.Error("expected signature to verify")
+	}
+
+	status = http.StatusServiceUnavailable
+	if err := publisher.Publish(context.Background(), testDomainEvent(8, 4)); err == nil {
+		t.Error("expected error for non-2xx response")
+	}
+}
@@ -0,0 +1,295 @@
+package logic
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+// 迁移脚本支持的数据库类型
+const (
+	MigrationDialectMySQL  = "mysql"
+	MigrationDialectSQLite = "sqlite"
+)
+
+// migrationFilePattern 迁移脚本文件名：<版本号>_<名称>.sql
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.sql$`)
+
+// Migration 数据库迁移脚本
+type Migration struct {
+	Version    int      `json:"version"`  // 版本号，按升序执行
+	Name       string   `json:"name"`     // 名称（文件名去掉版本号和扩展名）
+	Checksum   string   `json:"checksum"` // 脚本内容 SHA-256，用于发现已执行的脚本被修改
+	Statements []string `json:"-"`        // 拆分后的 SQL 语句
+}
+
+// MigrationDialect 返回数据库类型（gdb 配置的 type）对应的迁移脚本目录
+func MigrationDialect(dbType string) (string, error) {
+	switch strings.ToLower(dbType) {
+	case "mysql", "mariadb", "tidb":
+		return MigrationDialectMySQL, nil
+	case "sqlite":
+		return MigrationDialectSQLite, nil
+	default:
+		return "", gerror.Newf("数据库类型 %q 没有迁移脚本，仅支持 mysql 和 sqlite", dbType)
+	}
+}
+
+// LoadMigrations 读取 dialect 目录下的迁移脚本，按版本号升序返回；版本号重复时返回错误
+func LoadMigrations(fsys fs.FS, dialect string) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, dialect)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "读取迁移脚本目录失败: %s", dialect)
+	}
+
+	var migrations []*Migration
+	versions := make(map[int]string)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, gerror.Newf("迁移脚本文件名无效: %s，应为 <版本号>_<名称>.sql", entry.Name())
+		}
+		version, err := strconv.Atoi(match[1])
+		if err != nil || version <= 0 {
+			return nil, gerror.Newf("迁移脚本版本号无效: %s", entry.Name())
+		}
+		if existing, ok := versions[version]; ok {
+			return nil, gerror.Newf("迁移脚本版本号重复: %s 和 %s", existing, entry.Name())
+		}
+		versions[version] = entry.Name()
+
+		content, err := fs.ReadFile(fsys, path.Join(dialect, entry.Name()))
+		if err != nil {
+			return nil, gerror.Wrapf(err, "读取迁移脚本失败: %s", entry.Name())
+		}
+		sum := sha256.Sum256(content)
+		migrations = append(migrations, &Migration{
+			Version:    version,
+			Name:       match[2],
+			Checksum:   hex.EncodeToString(sum[:]),
+			Statements: SplitSQLStatements(string(content)),
+		})
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// SplitSQLStatements 按分号拆分 SQL 脚本；引号内的分号和 -- 注释不作为分隔
+func SplitSQLStatements(script string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+		quote      byte
+	)
+	flush := func() {
+		if statement := strings.TrimSpace(current.String()); statement != "" {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+	}
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case quote != 0:
+			current.WriteByte(c)
+			if c == quote {
+				// 连续两个引号是转义
+				if i+1 < len(script) && script[i+1] == quote {
+					current.WriteByte(script[i+1])
+					i++
+				} else {
+					quote = 0
+				}
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			current.WriteByte(c)
+		case c == '-' && i+1 < len(script) && script[i+1] == '-':
+			for i < len(script) && script[i] != '\n' {
+				i++
+			}
+			current.WriteByte('\n')
+		case c == ';':
+			flush()
+		default:
+			current.WriteByte(c)
+		}
+	}
+	flush()
+	return statements
+}
+
+var (
+	createTablePattern = regexp.MustCompile("(?is)^CREATE\\s+TABLE\\s+(?:IF\\s+NOT\\s+EXISTS\\s+)?[`\"]?(\\w+)[`\"]?\\s*\\((.*)\\)")
+	addColumnPattern   = regexp.MustCompile("(?is)^ALTER\\s+TABLE\\s+[`\"]?(\\w+)[`\"]?\\s+ADD\\s+(?:COLUMN\\s+)?[`\"]?(\\w+)[`\"]?")
+	identifierPattern  = regexp.MustCompile(`^\w+$`)
+	constraintPrefixes = []string{"PRIMARY", "UNIQUE", "KEY", "INDEX", "CONSTRAINT", "FOREIGN", "CHECK", "FULLTEXT"}
+)
+
+// SchemaColumns 从迁移脚本的 CREATE TABLE 和 ALTER TABLE ... ADD COLUMN 语句汇总期望的表和列。
+// CREATE TABLE 中每行定义一列或一个约束
+func SchemaColumns(migrations []*Migration) map[string][]string {
+	schema := make(map[string][]string)
+	for _, migration := range migrations {
+		for _, statement := range migration.Statements {
+			if match := createTablePattern.FindStringSubmatch(statement); match != nil {
+				table := strings.ToLower(match[1])
+				for _, line := range strings.Split(match[2], "\n") {
+					if column := columnName(line); column != "" {
+						schema[table] = appendUnique(schema[table], column)
+					}
+				}
+				continue
+			}
+			if match := addColumnPattern.FindStringSubmatch(statement); match != nil {
+				table := strings.ToLower(match[1])
+				schema[table] = appendUnique(schema[table], strings.ToLower(match[2]))
+			}
+		}
+	}
+	return schema
+}
+
+// columnName 返回 CREATE TABLE 中一行定义的列名，约束和空行返回空字符串
+func columnName(line string) string {
+	fields := strings.Fields(strings.TrimSpace(line))
+	if len(fields) < 2 {
+		return ""
+	}
+	for _, prefix := range constraintPrefixes {
+		if strings.EqualFold(fields[0], prefix) {
+			return ""
+		}
+	}
+	name := strings.Trim(fields[0], "`\"")
+	if !identifierPattern.MatchString(name) {
+		return ""
+	}
+	return strings.ToLower(name)
+}
+
+func appendUnique(items []string, item string) []string {
+	for _, existing := range items {
+		if existing == item {
+			return items
+		}
+	}
+	return append(items, item)
+}
+
+// SchemaReport 数据库结构检查结果
+type SchemaReport struct {
+	Dialect         string              `json:"dialect"`          // 迁移脚本目录
+	PendingVersions []int               `json:"pending_versions"` // 尚未执行的迁移版本
+	MissingTables   []string            `json:"missing_tables"`   // 缺少的表
+	MissingColumns  map[string][]string `json:"missing_columns"`  // 已存在的表中缺少的列（表名 -> 列名）
+}
+
+// OK 表和列是否齐全（未执行的迁移不影响结果，例如由外部工具建表的数据库）
+func (r *SchemaReport) OK() bool {
+	return len(r.MissingTables) == 0 && len(r.MissingColumns) == 0
+}
+
+// String 返回缺失项的摘要，用于日志和错误信息
+func (r *SchemaReport) String() string {
+	var parts []string
+	if len(r.MissingTables) > 0 {
+		parts = append(parts, "缺少表: "+strings.Join(r.MissingTables, ", "))
+	}
+	tables := make([]string, 0, len(r.MissingColumns))
+	for table := range r.MissingColumns {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		parts = append(parts, fmt.Sprintf("表 %s 缺少列: %s", table, strings.Join(r.MissingColumns[table], ", ")))
+	}
+	if len(r.PendingVersions) > 0 {
+		versions := make([]string, len(r.PendingVersions))
+		for i, version := range r.PendingVersions {
+			versions[i] = strconv.Itoa(version)
+		}
+		parts = append(parts, "未执行的迁移版本: "+strings.Join(versions, ", "))
+	}
+	if len(parts) == 0 {
+		return "数据库结构完整"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// CompareSchema 对比期望的表结构和数据库中的实际列（表名 -> 列名集合，不存在的表不在 actual 中）
+func CompareSchema(expected map[string][]string, actual map[string]map[string]bool) (missingTables []string, missingColumns map[string][]string) {
+	missingColumns = make(map[string][]string)
+	for table, columns := range expected {
+		existing, ok := actual[table]
+		if !ok {
+			missingTables = append(missingTables, table)
+			continue
+		}
+		for _, column := range columns {
+			if !existing[column] {
+				missingColumns[table] = append(missingColumns[table], column)
+			}
+		}
+	}
+	sort.Strings(missingTables)
+	return missingTables, missingColumns
+}
+
+// PendingMigrations 返回未执行的迁移；已执行的迁移脚本内容被修改时返回错误
+func PendingMigrations(migrations []*Migration, applied map[int]string) ([]*Migration, error) {
+	var pending []*Migration
+	for _, migration := range migrations {
+		checksum, ok := applied[migration.Version]
+		if !ok {
+			pending = append(pending, migration)
+			continue
+		}
+		if checksum != migration.Checksum {
+			return nil, gerror.Newf("迁移 %d_%s 已执行但脚本内容已修改，请新增迁移版本而不是修改已执行的脚本", migration.Version, migration.Name)
+		}
+	}
+	return pending, nil
+}
+
+// 数据库结构检查模式
+const (
+	SchemaCheckWarn   = "warn"   // 记录警告日志，继续初始化
+	SchemaCheckStrict = "strict" // 缺少表或列时初始化失败
+	SchemaCheckOff    = "off"    // 不检查
+)
+
+// SchemaConfig 数据库结构配置（对应配置项 wallet.schema）
+type SchemaConfig struct {
+	AutoMigrate bool   // Initialize 时执行未执行的迁移
+	Check       string // Initialize 时的结构检查模式: warn、strict、off
+}
+
+// ParseSchemaConfig 解析数据库结构配置，未配置的项使用默认值（不自动迁移，检查结果记录为警告）
+func ParseSchemaConfig(raw map[string]interface{}) (SchemaConfig, error) {
+	config := SchemaConfig{AutoMigrate: gconv.Bool(raw["autoMigrate"]), Check: SchemaCheckWarn}
+	if value := gconv.String(raw["check"]); value != "" {
+		check := strings.ToLower(strings.TrimSpace(value))
+		switch check {
+		case SchemaCheckWarn, SchemaCheckStrict, SchemaCheckOff:
+			config.Check = check
+		default:
+			return config, gerror.Newf("数据库结构配置 check 无效: %v（可选 warn、strict、off）", value)
+		}
+	}
+	return config, nil
+}
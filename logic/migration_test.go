@@ -0,0 +1,140 @@
+package logic
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSplitSQLStatements(t *testing.T) {
+	script := `-- 注释; 不拆分
+CREATE TABLE a (
+    id INT, -- 行尾注释; 也不拆分
+    note VARCHAR(10) COMMENT 'x; y ''z'''
+);
+INSERT INTO a VALUES (1, "a;b");;
+`
+	got := SplitSQLStatements(script)
+	if len(got) != 2 {
+		t.Fatalf("statements = %q", got)
+	}
+	if want := `INSERT INTO a VALUES (1, "a;b")`; got[1] != want {
+		t.Errorf("second statement = %q, want %q", got[1], want)
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"mysql/0002_second.sql": {Data: []byte("CREATE TABLE b (id INT);\nALTER TABLE a ADD COLUMN name VARCHAR(10);")},
+		"mysql/0001_first.sql":  {Data: []byte("CREATE TABLE a (\n  id INT,\n  PRIMARY KEY (id)\n);")},
+		"mysql/README.md":       {Data: []byte("ignored")},
+	}
+	migrations, err := LoadMigrations(fsys, "mysql")
+	if err != nil {
+		t.Fatalf("LoadMigrations: %v", err)
+	}
+	if len(migrations) != 2 || migrations[0].Version != 1 || migrations[0].Name != "first" || migrations[1].Version != 2 ||
+		len(migrations[1].Statements) != 2 || migrations[0].Checksum == "" {
+		t.Fatalf("migrations = %+v", migrations)
+	}
+
+	schema := SchemaColumns(migrations)
+	if want := map[string][]string{"a": {"id", "name"}, "b": {"id"}}; !reflect.DeepEqual(schema, want) {
+		t.Errorf("schema = %v, want %v", schema, want)
+	}
+
+	for name, fsys := range map[string]fstest.MapFS{
+		"bad name":  {"mysql/first.sql": {Data: []byte("SELECT 1;")}},
+		"duplicate": {"mysql/1_a.sql": {Data: []byte("SELECT 1;")}, "mysql/0001_b.sql": {Data: []byte("SELECT 1;")}},
+		"zero":      {"mysql/0000_a.sql": {Data: []byte("SELECT 1;")}},
+		"no dir":    {"sqlite/0001_a.sql": {Data: []byte("SELECT 1;")}},
+	} {
+		if _, err := LoadMigrations(fsys, "mysql"); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestSchemaColumnsSkipsConstraints(t *testing.T) {
+	migrations := []*Migration{{Statements: []string{
+		"CREATE TABLE IF NOT EXISTS `t` (\n" +
+			"    `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '记录 ID (主键)',\n" +
+			"    `key_id` VARCHAR(64) NOT NULL DEFAULT '',\n" +
+			"    PRIMARY KEY (`id`),\n" +
+			"    UNIQUE KEY `uk_key_id` (`key_id`),\n" +
+			"    KEY `idx_x` (`id`, `key_id`)\n" +
+			") ENGINE=InnoDB COMMENT='表 (示例)'",
+		"CREATE TABLE IF NOT EXISTS s (\n    \"order\" INTEGER NOT NULL DEFAULT 0\n)",
+		"CREATE UNIQUE INDEX IF NOT EXISTS s_uk ON s (\"order\")",
+	}}}
+	want := map[string][]string{"t": {"id", "key_id"}, "s": {"order"}}
+	if got := SchemaColumns(migrations); !reflect.DeepEqual(got, want) {
+		t.Errorf("schema = %v, want %v", got, want)
+	}
+}
+
+func TestCompareSchema(t *testing.T) {
+	expected := map[string][]string{
+		"users":   {"id", "username"},
+		"wallets": {"wallet_id"},
+		"tokens":  {"token_id"},
+	}
+	actual := map[string]map[string]bool{
+		"users":  {"id": true, "name": true},
+		"tokens": {"token_id": true},
+	}
+	tables, columns := CompareSchema(expected, actual)
+	if !reflect.DeepEqual(tables, []string{"wallets"}) || !reflect.DeepEqual(columns, map[string][]string{"users": {"username"}}) {
+		t.Fatalf("missing tables = %v, columns = %v", tables, columns)
+	}
+
+	report := &SchemaReport{MissingTables: tables, MissingColumns: columns, PendingVersions: []int{2}}
+	if report.OK() {
+		t.Error("report with missing columns is OK")
+	}
+	if want := "缺少表: wallets; 表 users 缺少列: username; 未执行的迁移版本: 2"; report.String() != want {
+		t.Errorf("report = %q, want %q", report.String(), want)
+	}
+	if report := (&SchemaReport{PendingVersions: []int{1}}); !report.OK() {
+		t.Error("pending migrations alone should not fail the check")
+	}
+}
+
+func TestPendingMigrations(t *testing.T) {
+	migrations := []*Migration{
+		{Version: 1, Name: "first", Checksum: "aaa"},
+		{Version: 2, Name: "second", Checksum: "bbb"},
+	}
+	pending, err := PendingMigrations(migrations, map[int]string{1: "aaa"})
+	if err != nil || len(pending) != 1 || pending[0].Version != 2 {
+		t.Fatalf("pending = %+v, %v", pending, err)
+	}
+	if _, err := PendingMigrations(migrations, map[int]string{1: "changed"}); err == nil {
+		t.Error("modified migration accepted")
+	}
+}
+
+func TestMigrationDialect(t *testing.T) {
+	for dbType, want := range map[string]string{"mysql": MigrationDialectMySQL, "MariaDB": MigrationDialectMySQL, "sqlite": MigrationDialectSQLite} {
+		if got, err := MigrationDialect(dbType); err != nil || got != want {
+			t.Errorf("MigrationDialect(%q) = %q, %v", dbType, got, err)
+		}
+	}
+	if _, err := MigrationDialect("pgsql"); err == nil {
+		t.Error("pgsql accepted")
+	}
+}
+
+func TestParseSchemaConfig(t *testing.T) {
+	config, err := ParseSchemaConfig(nil)
+	if err != nil || config.AutoMigrate || config.Check != SchemaCheckWarn {
+		t.Fatalf("defaults = %+v, %v", config, err)
+	}
+	config, err = ParseSchemaConfig(map[string]interface{}{"autoMigrate": true, "check": "Strict"})
+	if err != nil || !config.AutoMigrate || config.Check != SchemaCheckStrict {
+		t.Fatalf("config = %+v, %v", config, err)
+	}
+	if _, err := ParseSchemaConfig(map[string]interface{}{"check": "loud"}); err == nil {
+		t.Error("invalid check mode accepted")
+	}
+}
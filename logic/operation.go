@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync/atomic"
 
 	// sdkTypes "github.com/a19ba14d/ledger-wallet-sdk/pkg/types" // 暂时注释，不使用远程 SDK
 	"github.com/gogf/gf/v2/database/gdb"
@@ -12,9 +13,14 @@ import (
 	"github.com/gogf/gf/v2/os/gtime"
 	"github.com/shopspring/decimal"
 
+	"github.com/yalks/wallet/constants"
+	"github.com/yalks/wallet/dao"
 	"github.com/yalks/wallet/entity"
 )
 
+// operationSavepointSeq 财务操作事务保存点序号，保证嵌套操作的保存点名称不重复
+var operationSavepointSeq atomic.Uint64
+
 // OperationType 操作类型
 type OperationType string
 
@@ -30,11 +36,13 @@ type FinancialOperationRequest struct {
 	TokenSymbol   string            `json:"token_symbol"`
 	Amount        decimal.Decimal   `json:"amount"`
 	OperationType OperationType     `json:"operation_type"`
+	FundType      string            `json:"fund_type,omitempty"` // 资金类型（作为交易类型记录，并计入交易限额和领域事件）
 	BusinessID    string            `json:"business_id"`
 	Description   string            `json:"description"`
 	Metadata      map[string]string `json:"metadata,omitempty"`
-	FeeAmount     decimal.Decimal   `json:"fee_amount,omitempty"` // Fee amount (trusted from request)
-	FeeType       string            `json:"fee_type,omitempty"`   // Fee type (fixed, percentage)
+	FeeAmount     decimal.Decimal   `json:"fee_amount,omitempty"`     // Fee amount (trusted from request)
+	FeeType       string            `json:"fee_type,omitempty"`       // Fee type (fixed, percentage)
+	RequestSource string            `json:"request_source,omitempty"` // 请求来源，为空时使用上下文中的来源
 }
 
 // FinancialOperationResult 财务操作结果
@@ -43,6 +51,7 @@ type FinancialOperationResult struct {
 	BalanceBefore  decimal.Decimal `json:"balance_before"`
 	BalanceAfter   decimal.Decimal `json:"balance_after"`
 	WalletResponse any             `json:"wallet_response,omitempty"`
+	Replayed       bool            `json:"replayed,omitempty"` // 幂等重放：返回已存在的交易，本次未执行
 }
 
 // IOperationLogic 操作业务逻辑接口
@@ -56,19 +65,29 @@ type IOperationLogic interface {
 }
 
 type operationLogic struct {
-	userLogic    IUserLogic
-	tokenLogic   ITokenLogic
-	balanceLogic IBalanceLogic
-	context      *SharedLogicContext
+	userLogic     IUserLogic
+	tokenLogic    ITokenLogic
+	balanceLogic  IBalanceLogic
+	eventLogic    IDomainEventLogic
+	auditLogic    IAuditLogic
+	velocityLogic IVelocityLogic
+	riskLogic     IRiskLogic
+	policyLogic   IPolicyLogic
+	context       *SharedLogicContext
 }
 
 // NewOperationLogic 创建操作业务逻辑实例
 func NewOperationLogic() IOperationLogic {
 	return &operationLogic{
-		userLogic:    NewUserLogic(),
-		tokenLogic:   NewTokenLogic(),
-		balanceLogic: NewBalanceLogic(),
-		context:      GetSharedContext(),
+		userLogic:     NewUserLogic(),
+		tokenLogic:    NewTokenLogic(),
+		balanceLogic:  NewBalanceLogic(),
+		eventLogic:    NewDomainEventLogic(),
+		auditLogic:    NewAuditLogic(),
+		velocityLogic: NewVelocityLogic(),
+		riskLogic:     NewRiskLogic(),
+		policyLogic:   NewPolicyLogic(),
+		context:       GetSharedContext(),
 	}
 }
 
@@ -79,16 +98,19 @@ func (l *operationLogic) ExecuteInTx(ctx context.Context, tx gdb.TX, req *Financ
 		return nil, gerror.Wrap(err, "参数验证失败")
 	}
 
+	// 1.1 在任何读取之前锁定钱包行：同一钱包的并发操作在此排队，
+	// 之后的幂等性检查、业务验证、限额统计和余额读取都能看到先提交的操作
+	lockedBalance, walletExists, err := l.balanceLogic.LockBalanceInTx(ctx, tx, req.UserID, req.TokenSymbol)
+	if err != nil {
+		return nil, gerror.Wrap(err, "锁定钱包失败")
+	}
+
 	// 2. 幂等性检查
 	if existingTx, err := l.context.GetTransactionDAO().GetTransactionByBusinessID(ctx, req.BusinessID); err != nil {
 		return nil, gerror.Wrap(err, "幂等性检查失败")
 	} else if existingTx != nil {
 		g.Log().Infof(ctx, "幂等性检查: 操作已存在 BusinessID=%s, TransactionID=%d", req.BusinessID, existingTx.TransactionId)
-		return &FinancialOperationResult{
-			TransactionID: int64(existingTx.TransactionId),
-			BalanceBefore: existingTx.BalanceBefore,
-			BalanceAfter:  existingTx.BalanceAfter,
-		}, nil
+		return existingOperationResult(existingTx), nil
 	}
 
 	// 3. 业务验证
@@ -96,10 +118,54 @@ func (l *operationLogic) ExecuteInTx(ctx context.Context, tx gdb.TX, req *Financ
 		return nil, err
 	}
 
-	// 4. 获取操作前余额
-	balanceBefore, _, err := l.balanceLogic.GetBalance(ctx, req.UserID, req.TokenSymbol)
-	if err != nil {
-		return nil, gerror.Wrap(err, "获取操作前余额失败")
+	// 并发请求可能同时通过上面的幂等性检查，business_id 唯一约束冲突时回滚到保存点并返回已存在的交易
+	savepoint := ""
+	if tx != nil {
+		savepoint = fmt.Sprintf("fund_op_%d", operationSavepointSeq.Add(1))
+		if err := tx.SavePoint(savepoint); err != nil {
+			return nil, gerror.Wrapf(err, "创建事务保存点失败: %s", savepoint)
+		}
+	}
+
+	// 3.1 风控评估与交易限额（未指定资金类型的内部操作不评估也不计入）
+	var assessment *RiskAssessment
+	if req.FundType != "" {
+		reqCtx := ExtractRequestContext(ctx)
+		var err error
+		assessment, err = l.riskLogic.Evaluate(ctx, &RiskOperation{
+			UserID:        req.UserID,
+			TokenSymbol:   req.TokenSymbol,
+			FundType:      constants.FundType(req.FundType),
+			Direction:     constants.FundDirection(l.getDirection(req.OperationType)),
+			Amount:        req.Amount,
+			RequestSource: l.requestSource(ctx, req),
+			IP:            reqCtx.IP,
+			UserAgent:     reqCtx.UserAgent,
+			Metadata:      reqCtx.Metadata,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		err = l.velocityLogic.ApplyInTx(ctx, tx, &VelocityOperation{
+			UserID:        req.UserID,
+			TokenSymbol:   req.TokenSymbol,
+			FundType:      constants.FundType(req.FundType),
+			RequestSource: l.requestSource(ctx, req),
+			Amount:        req.Amount,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 4. 获取操作前余额：钱包已锁定时使用锁定读取的余额，钱包不存在时创建
+	balanceBefore := lockedBalance
+	if !walletExists {
+		balanceBefore, _, err = l.balanceLogic.GetBalance(ctx, req.UserID, req.TokenSymbol)
+		if err != nil {
+			return nil, gerror.Wrap(err, "获取操作前余额失败")
+		}
 	}
 
 	// 5. 计算操作后余额
@@ -127,10 +193,43 @@ func (l *operationLogic) ExecuteInTx(ctx context.Context, tx gdb.TX, req *Financ
 	}
 
 	// 8. 创建交易记录
-	transactionID, err := l.createTransactionRecord(ctx, tx, req, balanceBefore, balanceAfter)
+	transactionID, err := l.createTransactionRecord(ctx, tx, req, balanceBefore, balanceAfter, assessment)
 	if err != nil {
+		if savepoint != "" && dao.IsDuplicateKeyError(err) {
+			return l.resolveDuplicateOperation(ctx, tx, savepoint, req.BusinessID, err)
+		}
 		return nil, gerror.Wrap(err, "创建交易记录失败")
 	}
+	if err := l.auditLogic.RecordTransactionInTx(ctx, tx, uint64(transactionID)); err != nil {
+		return nil, gerror.Wrap(err, "写入审计记录失败")
+	}
+
+	// 9. 在同一事务中写入领域事件
+	eventType := EventFundsCredited
+	if req.OperationType == OperationTypeDebit {
+		eventType = EventFundsDebited
+	}
+	fundType := req.FundType
+	if fundType == "" {
+		fundType = string(req.OperationType)
+	}
+	err = l.eventLogic.RecordInTx(ctx, tx, &RecordEventRequest{
+		Type:        eventType,
+		UserID:      req.UserID,
+		TokenSymbol: req.TokenSymbol,
+		BusinessID:  req.BusinessID,
+		Payload: &FundsChangedPayload{
+			TransactionID: transactionID,
+			FundType:      fundType,
+			Amount:        req.Amount,
+			BalanceBefore: balanceBefore,
+			BalanceAfter:  balanceAfter,
+			Description:   req.Description,
+		},
+	})
+	if err != nil {
+		return nil, gerror.Wrap(err, "写入领域事件失败")
+	}
 
 	// 回滚逻辑暂时注释，因为使用本地事务
 	// if err != nil {
@@ -156,6 +255,33 @@ func (l *operationLogic) ExecuteInTx(ctx context.Context, tx gdb.TX, req *Financ
 	return result, nil
 }
 
+// resolveDuplicateOperation 插入交易记录违反 business_id 唯一约束时，回滚本次操作的写入并返回已存在的成功交易
+func (l *operationLogic) resolveDuplicateOperation(ctx context.Context, tx gdb.TX, savepoint, businessID string, cause error) (*FinancialOperationResult, error) {
+	if err := tx.RollbackTo(savepoint); err != nil {
+		return nil, gerror.Wrapf(err, "回滚到事务保存点失败: %s", savepoint)
+	}
+	existingTx, err := l.context.GetTransactionDAO().GetTransactionByBusinessID(ctx, businessID)
+	if err != nil {
+		return nil, gerror.Wrap(err, "幂等性检查失败")
+	}
+	if existingTx == nil {
+		// 已存在的交易不是成功状态，不能作为本次操作的结果
+		return nil, gerror.WrapCodef(CodeIdempotencyConflict, cause, "业务ID已被使用: BusinessID=%s", businessID)
+	}
+	g.Log().Infof(ctx, "幂等性检查: 并发操作已写入 BusinessID=%s, TransactionID=%d", businessID, existingTx.TransactionId)
+	return existingOperationResult(existingTx), nil
+}
+
+// existingOperationResult 将已存在的交易转换为操作结果
+func existingOperationResult(existingTx *entity.Transactions) *FinancialOperationResult {
+	return &FinancialOperationResult{
+		TransactionID: int64(existingTx.TransactionId),
+		BalanceBefore: existingTx.BalanceBefore,
+		BalanceAfter:  existingTx.BalanceAfter,
+		Replayed:      true,
+	}
+}
+
 // GetUserBalance 获取用户余额
 func (l *operationLogic) GetUserBalance(ctx context.Context, userID uint64, tokenSymbol string) (decimal.Decimal, error) {
 	availableBalance, _, err := l.balanceLogic.GetBalance(ctx, userID, tokenSymbol)
@@ -185,13 +311,34 @@ func (l *operationLogic) ValidateOperation(ctx context.Context, req *FinancialOp
 		return gerror.Wrap(err, "钱包检测和创建失败")
 	}
 
-	// 4. 校验余额一致性
+	// 4. 执行策略规则（wallet.policies），元数据与交易记录中保存的一致（上下文元数据被业务元数据覆盖）
+	metadata := make(map[string]string)
+	for k, v := range ExtractRequestContext(ctx).Metadata {
+		metadata[k] = v
+	}
+	for k, v := range req.Metadata {
+		metadata[k] = v
+	}
+	err = l.policyLogic.Check(ctx, &PolicyInput{
+		UserID:        req.UserID,
+		TokenSymbol:   req.TokenSymbol,
+		FundType:      constants.FundType(req.FundType),
+		Direction:     constants.FundDirection(l.getDirection(req.OperationType)),
+		Amount:        req.Amount,
+		RequestSource: l.requestSource(ctx, req),
+		Metadata:      metadata,
+	})
+	if err != nil {
+		return err
+	}
+
+	// 5. 校验余额一致性
 	// err = l.validateBalanceConsistency(ctx, req.UserID, req.TokenSymbol)
 	// if err != nil {
 	// 	return gerror.Wrap(err, "余额一致性校验失败")
 	// }
 
-	// 5. 对于扣款操作，检查余额是否充足
+	// 6. 对于扣款操作，检查余额是否充足
 	if req.OperationType == OperationTypeDebit {
 		balance, err := l.GetUserBalance(ctx, req.UserID, req.TokenSymbol)
 		if err != nil {
@@ -199,7 +346,7 @@ func (l *operationLogic) ValidateOperation(ctx context.Context, req *FinancialOp
 		}
 
 		if balance.LessThan(req.Amount) {
-			return gerror.Newf("余额不足: 当前余额=%s, 需要金额=%s", balance.String(), req.Amount.String())
+			return gerror.NewCodef(CodeInsufficientBalance, "余额不足: 当前余额=%s, 需要金额=%s", balance.String(), req.Amount.String())
 		}
 	}
 
@@ -403,7 +550,7 @@ func (l *operationLogic) rollbackRemoteWalletOperation(ctx context.Context, req
 */
 
 // createTransactionRecord 创建交易记录
-func (l *operationLogic) createTransactionRecord(ctx context.Context, tx gdb.TX, req *FinancialOperationRequest, balanceBefore, balanceAfter decimal.Decimal) (int64, error) {
+func (l *operationLogic) createTransactionRecord(ctx context.Context, tx gdb.TX, req *FinancialOperationRequest, balanceBefore, balanceAfter decimal.Decimal, assessment *RiskAssessment) (int64, error) {
 	// 获取代币信息用于精度转换
 	token, err := l.tokenLogic.GetTokenBySymbol(ctx, req.TokenSymbol)
 	if err != nil {
@@ -420,7 +567,7 @@ func (l *operationLogic) createTransactionRecord(ctx context.Context, tx gdb.TX,
 		Amount:        req.Amount,
 		BalanceBefore: balanceBefore,
 		BalanceAfter:  balanceAfter,
-		Type:          string(req.OperationType),
+		Type:          l.transactionType(req),
 		Status:        1, // 1表示成功
 		Memo:          req.Description,
 		Symbol:        req.TokenSymbol,
@@ -434,7 +581,7 @@ func (l *operationLogic) createTransactionRecord(ctx context.Context, tx gdb.TX,
 		RequestAmount:    req.Amount, // Using the original request amount
 		RequestReference: req.BusinessID,
 		RequestMetadata:  l.mergeMetadataToJSON(reqCtx.Metadata, req.Metadata),
-		RequestSource:    reqCtx.Source,
+		RequestSource:    l.requestSource(ctx, req),
 		RequestIp:        reqCtx.IP,
 		RequestUserAgent: reqCtx.UserAgent,
 		RequestTimestamp: gtime.Now(),
@@ -451,6 +598,11 @@ func (l *operationLogic) createTransactionRecord(ctx context.Context, tx gdb.TX,
 		TargetUserId:   l.extractTargetUserId(req.Metadata),
 		TargetUsername: l.extractTargetUsername(req.Metadata),
 	}
+	if assessment != nil {
+		transaction.RiskScore = assessment.Score
+		transaction.RiskDecision = string(assessment.Decision)
+		transaction.RiskReasons = assessment.ReasonsString()
+	}
 
 	transactionID, err := l.context.GetTransactionDAO().CreateTransaction(ctx, tx, transaction)
 	if err != nil {
@@ -664,6 +816,22 @@ func (l *operationLogic) getDirection(operationType OperationType) string {
 	}
 }
 
+// transactionType 交易记录类型：优先使用资金类型，未指定时使用操作类型
+func (l *operationLogic) transactionType(req *FinancialOperationRequest) string {
+	if req.FundType != "" {
+		return req.FundType
+	}
+	return string(req.OperationType)
+}
+
+// requestSource 请求来源：优先使用请求中指定的来源，未指定时使用上下文中的来源
+func (l *operationLogic) requestSource(ctx context.Context, req *FinancialOperationRequest) string {
+	if req.RequestSource != "" {
+		return req.RequestSource
+	}
+	return ExtractRequestContext(ctx).Source
+}
+
 // extractTargetUserId 从元数据中提取目标用户ID
 func (l *operationLogic) extractTargetUserId(metadata map[string]string) uint {
 	if metadata == nil {
@@ -0,0 +1,23 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/entity"
+)
+
+func TestExistingOperationResult(t *testing.T) {
+	result := existingOperationResult(&entity.Transactions{
+		TransactionId: 42,
+		BalanceBefore: decimal.NewFromInt(10),
+		BalanceAfter:  decimal.NewFromInt(15),
+	})
+	if !result.Replayed {
+		t.Error("existing transaction not marked as replayed")
+	}
+	if result.TransactionID != 42 || !result.BalanceBefore.Equal(decimal.NewFromInt(10)) || !result.BalanceAfter.Equal(decimal.NewFromInt(15)) {
+		t.Errorf("result = %+v", result)
+	}
+}
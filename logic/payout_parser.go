@@ -0,0 +1,275 @@
+package logic
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/constants"
+)
+
+// PayoutFormat 批量发放输入格式
+type PayoutFormat string
+
+const (
+	PayoutFormatCSV  PayoutFormat = "csv"  // CSV，首行为表头
+	PayoutFormatJSON PayoutFormat = "json" // JSON 对象数组
+)
+
+// 批量发放输入字段（CSV 表头 / JSON 键名）
+const (
+	PayoutFieldUserID     = "user_id"
+	PayoutFieldTelegramID = "telegram_id"
+	PayoutFieldUsername   = "username"
+	PayoutFieldToken      = "token"
+	PayoutFieldAmount     = "amount"
+	PayoutFieldFundType   = "fund_type"
+	PayoutFieldMemo       = "memo"
+)
+
+// maxPayoutMemoLength 备注最大长度
+const maxPayoutMemoLength = 200
+
+// PayoutRow 解析后的批量发放行
+type PayoutRow struct {
+	Line       int                // 输入中的行号（从 1 开始）
+	UserID     uint64             // 用户ID（三种用户标识仅设置其一）
+	TelegramID int64              // Telegram ID
+	Username   string             // 用户名
+	Token      string             // 代币符号
+	Amount     decimal.Decimal    // 金额
+	FundType   constants.FundType // 资金类型
+	Memo       string             // 备注
+}
+
+// PayoutRowError 批量发放行错误
+type PayoutRowError struct {
+	Line    int    `json:"line"`    // 行号
+	Message string `json:"message"` // 错误信息
+}
+
+// Error 实现 error 接口
+func (e *PayoutRowError) Error() string {
+	return fmt.Sprintf("第%d行: %s", e.Line, e.Message)
+}
+
+// ParsePayoutRows 解析并校验批量发放输入，返回全部合法行与逐行错误；
+// 输入整体无法解析（如缺少表头、JSON 格式错误）时返回 error
+func ParsePayoutRows(format PayoutFormat, data []byte, defaultFundType constants.FundType) ([]*PayoutRow, []*PayoutRowError, error) {
+	var (
+		records []payoutRecord
+		err     error
+	)
+	switch format {
+	case PayoutFormatCSV:
+		records, err = readPayoutCSV(data)
+	case PayoutFormatJSON:
+		records, err = readPayoutJSON(data)
+	default:
+		return nil, nil, gerror.Newf("不支持的输入格式: %s", format)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil, gerror.New("输入中没有任何发放记录")
+	}
+
+	rows := make([]*PayoutRow, 0, len(records))
+	var rowErrors []*PayoutRowError
+	for _, record := range records {
+		row, err := parsePayoutRecord(record, defaultFundType)
+		if err != nil {
+			rowErrors = append(rowErrors, &PayoutRowError{Line: record.line, Message: err.Error()})
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, rowErrors, nil
+}
+
+// payoutRecord 原始输入记录
+type payoutRecord struct {
+	line   int
+	fields map[string]string
+}
+
+// readPayoutCSV 读取 CSV 输入
+func readPayoutCSV(data []byte) ([]payoutRecord, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, gerror.Wrap(err, "读取 CSV 表头失败")
+	}
+	columns := make([]string, len(header))
+	known := map[string]bool{
+		PayoutFieldUserID: true, PayoutFieldTelegramID: true, PayoutFieldUsername: true,
+		PayoutFieldToken: true, PayoutFieldAmount: true, PayoutFieldFundType: true, PayoutFieldMemo: true,
+	}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !known[name] {
+			return nil, gerror.Newf("CSV 表头包含未知列: %s", name)
+		}
+		columns[i] = name
+	}
+
+	var records []payoutRecord
+	for {
+		values, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, gerror.Wrap(err, "读取 CSV 失败")
+		}
+		line, _ := reader.FieldPos(0)
+
+		if len(values) == 1 && strings.TrimSpace(values[0]) == "" {
+			continue
+		}
+		if len(values) > len(columns) {
+			records = append(records, payoutRecord{line: line, fields: nil})
+			continue
+		}
+
+		fields := make(map[string]string, len(values))
+		for i, value := range values {
+			fields[columns[i]] = strings.TrimSpace(value)
+		}
+		records = append(records, payoutRecord{line: line, fields: fields})
+	}
+	return records, nil
+}
+
+// readPayoutJSON 读取 JSON 数组输入，行号为每个对象起始位置所在行
+func readPayoutJSON(data []byte) ([]payoutRecord, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil, gerror.New("JSON 输入必须是对象数组")
+	}
+
+	var records []payoutRecord
+	for decoder.More() {
+		line := lineAtOffset(data, decoder.InputOffset())
+
+		var raw map[string]interface{}
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, gerror.Wrapf(err, "第%d行 JSON 解析失败", line)
+		}
+
+		fields := make(map[string]string, len(raw))
+		for key, value := range raw {
+			if value == nil {
+				continue
+			}
+			fields[strings.ToLower(key)] = strings.TrimSpace(fmt.Sprintf("%v", value))
+		}
+		records = append(records, payoutRecord{line: line, fields: fields})
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, gerror.Wrap(err, "JSON 数组未正确结束")
+	}
+	return records, nil
+}
+
+// lineAtOffset 计算偏移位置之后第一个有效字符所在的行号
+func lineAtOffset(data []byte, offset int64) int {
+	pos := int(offset)
+	for pos < len(data) && (data[pos] == ' ' || data[pos] == '\t' || data[pos] == '\r' || data[pos] == '\n' || data[pos] == ',') {
+		pos++
+	}
+	return bytes.Count(data[:pos], []byte("\n")) + 1
+}
+
+// parsePayoutRecord 校验单条记录
+func parsePayoutRecord(record payoutRecord, defaultFundType constants.FundType) (*PayoutRow, error) {
+	if record.fields == nil {
+		return nil, gerror.New("列数超过表头")
+	}
+	row := &PayoutRow{Line: record.line}
+	f := record.fields
+
+	// 用户标识：三选一
+	identities := 0
+	if v := f[PayoutFieldUserID]; v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || id == 0 {
+			return nil, gerror.Newf("无效的用户ID: %s", v)
+		}
+		row.UserID = id
+		identities++
+	}
+	if v := f[PayoutFieldTelegramID]; v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id == 0 {
+			return nil, gerror.Newf("无效的Telegram ID: %s", v)
+		}
+		row.TelegramID = id
+		identities++
+	}
+	if v := f[PayoutFieldUsername]; v != "" {
+		row.Username = strings.TrimPrefix(v, "@")
+		identities++
+	}
+	if identities != 1 {
+		return nil, gerror.Newf("必须且只能提供 %s、%s、%s 其中之一", PayoutFieldUserID, PayoutFieldTelegramID, PayoutFieldUsername)
+	}
+
+	row.Token = f[PayoutFieldToken]
+	if row.Token == "" {
+		return nil, gerror.New("代币不能为空")
+	}
+
+	amount, err := decimal.NewFromString(f[PayoutFieldAmount])
+	if err != nil {
+		return nil, gerror.Newf("无效的金额: %s", f[PayoutFieldAmount])
+	}
+	if !amount.IsPositive() {
+		return nil, gerror.Newf("金额必须大于0: %s", f[PayoutFieldAmount])
+	}
+	row.Amount = amount
+
+	row.FundType = constants.FundType(f[PayoutFieldFundType])
+	if row.FundType == "" {
+		row.FundType = defaultFundType
+	}
+	if row.FundType == "" {
+		return nil, gerror.New("资金类型不能为空")
+	}
+	if !constants.IsValidFundType(row.FundType) {
+		return nil, gerror.Newf("无效的资金类型: %s", row.FundType)
+	}
+	// 批量发放只做入账，双向资金类型按入账处理
+	if _, err := constants.ResolveFundDirection(row.FundType, constants.FundDirectionIn); err != nil {
+		return nil, gerror.Newf("批量发放只支持入账类资金类型: %s", row.FundType)
+	}
+	info, _ := constants.GetFundTypeInfo(row.FundType)
+	// 批量发放不经过调账审批流程，需要审批的资金类型必须走审批
+	if info.HasFlag(constants.FundTypeFlagRequiresApproval) {
+		return nil, gerror.Newf("资金类型需要调账审批，不能批量发放: %s", row.FundType)
+	}
+	if !info.HasFlag(constants.FundTypeFlagBulkPayout) {
+		return nil, gerror.Newf("资金类型不支持批量发放: %s", row.FundType)
+	}
+
+	row.Memo = f[PayoutFieldMemo]
+	if len([]rune(row.Memo)) > maxPayoutMemoLength {
+		return nil, gerror.Newf("备注长度不能超过%d个字符", maxPayoutMemoLength)
+	}
+
+	return row, nil
+}
@@ -0,0 +1,112 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/yalks/wallet/constants"
+)
+
+func TestParsePayoutRowsCSV(t *testing.T) {
+	input := "user_id,telegram_id,username,token,amount,fund_type,memo\n" +
+		"1001,,,USDT,10.5,,campaign A\n" +
+		",555000111,,USDT,3,referral_bonus,\n" +
+		",,@alice,TRX,0,,zero amount\n" +
+		"1002,555,,USDT,1,,two identities\n" +
+		"\n" +
+		",,bob,USDT,2,withdraw,outgoing type\n" +
+		",,carol,,2,,\n"
+
+	rows, rowErrors, err := ParsePayoutRows(PayoutFormatCSV, []byte(input), constants.FundTypeCommission)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 valid rows, got %d", len(rows))
+	}
+	if rows[0].Line != 2 || rows[0].UserID != 1001 || rows[0].Token != "USDT" || rows[0].FundType != constants.FundTypeCommission || rows[0].Memo != "campaign A" {
+		t.Errorf("unexpected first row: %+v", rows[0])
+	}
+	if rows[1].Line != 3 || rows[1].TelegramID != 555000111 || rows[1].FundType != constants.FundTypeReferralBonus {
+		t.Errorf("unexpected second row: %+v", rows[1])
+	}
+
+	expectedLines := []int{4, 5, 7, 8}
+	if len(rowErrors) != len(expectedLines) {
+		t.Fatalf("expected %d row errors, got %d: %v", len(expectedLines), len(rowErrors), rowErrors)
+	}
+	for i, line := range expectedLines {
+		if rowErrors[i].Line != line {
+			t.Errorf("row error %d: expected line %d, got %d (%s)", i, line, rowErrors[i].Line, rowErrors[i].Message)
+		}
+	}
+}
+
+func TestParsePayoutRowsJSON(t *testing.T) {
+	input := `[
+  {"user_id": 1001, "token": "USDT", "amount": "10"},
+  {
+    "username": "alice",
+    "token": "USDT",
+    "amount": -1
+  },
+  {"telegram_id": 42, "token": "TRX", "amount": 2.25, "fund_type": "commission", "memo": "ok"}
+]`
+
+	rows, rowErrors, err := ParsePayoutRows(PayoutFormatJSON, []byte(input), constants.FundTypeCommission)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rows) != 2 || rows[0].Line != 2 || rows[1].Line != 8 {
+		t.Fatalf("unexpected rows: %+v", rows)
+	}
+	if rows[1].Amount.String() != "2.25" || rows[1].TelegramID != 42 {
+		t.Errorf("unexpected row: %+v", rows[1])
+	}
+	if len(rowErrors) != 1 || rowErrors[0].Line != 3 {
+		t.Fatalf("unexpected row errors: %v", rowErrors)
+	}
+}
+
+func TestParsePayoutRowsInvalidInput(t *testing.T) {
+	if _, _, err := ParsePayoutRows(PayoutFormatCSV, []byte("user,amount\n1,2\n"), constants.FundTypeCommission); err == nil {
+		t.Error("expected error for unknown CSV column")
+	}
+	if _, _, err := ParsePayoutRows(PayoutFormatJSON, []byte(`{"user_id": 1}`), constants.FundTypeCommission); err == nil {
+		t.Error("expected error for non-array JSON")
+	}
+	if _, _, err := ParsePayoutRows(PayoutFormatJSON, []byte(`[]`), constants.FundTypeCommission); err == nil {
+		t.Error("expected error for empty input")
+	}
+	if _, _, err := ParsePayoutRows("xml", []byte(`<a/>`), constants.FundTypeCommission); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestParsePayoutRowsFundType(t *testing.T) {
+	input := "user_id,token,amount,fund_type\n" +
+		"1,USDT,1,commission\n" +
+		"2,USDT,1,\n" +
+		"3,USDT,1,admin_add\n" +
+		"4,USDT,1,system_adjustment\n"
+
+	rows, rowErrors, err := ParsePayoutRows(PayoutFormatCSV, []byte(input), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rows) != 1 || rows[0].FundType != constants.FundTypeCommission {
+		t.Fatalf("unexpected rows: %+v", rows)
+	}
+	expectedLines := []int{3, 4, 5}
+	if len(rowErrors) != len(expectedLines) {
+		t.Fatalf("expected %d row errors, got %d: %v", len(expectedLines), len(rowErrors), rowErrors)
+	}
+	for i, line := range expectedLines {
+		if rowErrors[i].Line != line {
+			t.Errorf("row error %d: expected line %d, got %d (%s)", i, line, rowErrors[i].Line, rowErrors[i].Message)
+		}
+	}
+
+	if _, rowErrors, _ := ParsePayoutRows(PayoutFormatCSV, []byte("user_id,token,amount\n1,USDT,1\n"), constants.FundTypeAdminAdd); len(rowErrors) != 1 {
+		t.Errorf("approval fund type as default should make the row invalid, got %v", rowErrors)
+	}
+}
@@ -0,0 +1,843 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/constants"
+	"github.com/yalks/wallet/dao"
+)
+
+// PolicyAction 策略规则命中后的动作
+type PolicyAction string
+
+const (
+	PolicyActionDeny            PolicyAction = "deny"             // 拒绝操作
+	PolicyActionRequireApproval PolicyAction = "require_approval" // 操作需要审批
+)
+
+// 策略规则可使用的字段
+const (
+	PolicyFieldFundType  = "fund_type" // 资金类型
+	PolicyFieldCategory  = "category"  // 资金类型分类
+	PolicyFieldDirection = "direction" // 资金方向 in/out
+	PolicyFieldToken     = "token"     // 代币符号
+	PolicyFieldSource    = "source"    // 请求来源
+	PolicyFieldAmount    = "amount"    // 金额
+	PolicyFieldDecimals  = "decimals"  // 金额的小数位数
+	PolicyFieldUserID    = "user_id"   // 用户ID
+	PolicyFieldMetadata  = "metadata." // 请求元数据前缀，如 metadata.channel
+)
+
+// defaultPolicyRules 默认策略：单次操作金额上限和金额精度上限，可在配置中用同名规则覆盖或停用
+var defaultPolicyRules = []PolicyRuleSpec{
+	{Name: "max_amount", Rule: "deny if amount > 1000000", Message: "单次操作金额不能超过 1000000"},
+	{Name: "max_decimals", Rule: "deny if decimals > 18", Message: "金额精度过高，最多支持18位小数"},
+}
+
+// PolicyInput 策略规则评估的操作
+type PolicyInput struct {
+	UserID        uint64                  // 用户ID
+	TokenSymbol   string                  // 代币符号
+	FundType      constants.FundType      // 资金类型，内部操作为空
+	Direction     constants.FundDirection // 资金方向
+	Amount        decimal.Decimal         // 金额
+	RequestSource string                  // 请求来源
+	Metadata      map[string]string       // 请求元数据
+}
+
+// PolicyRuleSpec 策略规则配置（对应 wallet.policies.rules 中的一项）
+type PolicyRuleSpec struct {
+	Name     string `json:"name"`     // 规则名称，同名规则覆盖默认规则
+	Rule     string `json:"rule"`     // 规则文本，如 "deny withdraw if token=USDT and amount>5000"
+	Message  string `json:"message"`  // 命中时返回的说明，为空时使用规则文本
+	Disabled bool   `json:"disabled"` // 停用该规则（用于停用默认规则）
+}
+
+// PolicyRule 已解析的策略规则
+type PolicyRule struct {
+	Name      string               // 规则名称
+	Action    PolicyAction         // 命中后的动作
+	FundTypes []constants.FundType // 规则头部限定的资金类型，为空时适用于全部操作
+	Text      string               // 规则文本
+	Message   string               // 命中时返回的说明
+	condition policyExpr           // if 之后的条件，nil 表示无条件
+}
+
+// Matches 判断规则是否命中操作
+func (r *PolicyRule) Matches(input *PolicyInput) bool {
+	if len(r.FundTypes) > 0 {
+		found := false
+		for _, fundType := range r.FundTypes {
+			found = found || fundType == input.FundType
+		}
+		if !found {
+			return false
+		}
+	}
+	return r.condition == nil || r.condition.eval(input)
+}
+
+// PolicyError 操作被策略拒绝或需要审批
+type PolicyError struct {
+	Rule *PolicyRule
+}
+
+// Error 实现 error 接口
+func (e *PolicyError) Error() string {
+	message := e.Rule.Message
+	if message == "" {
+		message = e.Rule.Text
+	}
+	if e.Rule.Action == PolicyActionRequireApproval {
+		return fmt.Sprintf("操作需要审批（策略 %s）: %s", e.Rule.Name, message)
+	}
+	return fmt.Sprintf("操作被策略 %s 拒绝: %s", e.Rule.Name, message)
+}
+
+// Code 返回错误码：拒绝为 CodePolicyDenied，需要审批为 CodePolicyApprovalRequired
+func (e *PolicyError) Code() gcode.Code {
+	if e.Rule.Action == PolicyActionRequireApproval {
+		return CodePolicyApprovalRequired
+	}
+	return CodePolicyDenied
+}
+
+// ParsePolicyConfig 解析策略配置：默认规则与 rules 按名称合并，同名规则覆盖默认规则
+func ParsePolicyConfig(raw map[string]interface{}) ([]*PolicyRule, error) {
+	specs := append([]PolicyRuleSpec(nil), defaultPolicyRules...)
+	index := make(map[string]int, len(specs))
+	for i, spec := range specs {
+		index[spec.Name] = i
+	}
+
+	seen := make(map[string]bool)
+	for _, item := range gconv.Maps(raw["rules"]) {
+		spec := PolicyRuleSpec{
+			Name:     strings.TrimSpace(gconv.String(item["name"])),
+			Rule:     strings.TrimSpace(gconv.String(item["rule"])),
+			Message:  gconv.String(item["message"]),
+			Disabled: gconv.Bool(item["disabled"]),
+		}
+		if spec.Name == "" {
+			return nil, gerror.New("策略规则名称不能为空")
+		}
+		if seen[spec.Name] {
+			return nil, gerror.Newf("策略规则名称重复: %s", spec.Name)
+		}
+		seen[spec.Name] = true
+		if i, ok := index[spec.Name]; ok {
+			specs[i] = spec
+			continue
+		}
+		index[spec.Name] = len(specs)
+		specs = append(specs, spec)
+	}
+	return BuildPolicyRules(specs)
+}
+
+// BuildPolicyRules 解析策略规则配置，跳过已停用的规则
+func BuildPolicyRules(specs []PolicyRuleSpec) ([]*PolicyRule, error) {
+	rules := make([]*PolicyRule, 0, len(specs))
+	names := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		if names[spec.Name] {
+			return nil, gerror.Newf("策略规则名称重复: %s", spec.Name)
+		}
+		names[spec.Name] = true
+		if spec.Disabled {
+			continue
+		}
+		rule, err := ParsePolicyRule(spec.Name, spec.Rule)
+		if err != nil {
+			return nil, err
+		}
+		rule.Message = spec.Message
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// ParsePolicyRule 解析一条策略规则，语法为：
+//
+//	deny|require approval [资金类型[,资金类型...]] [if 条件]
+//
+// 条件由 and、or、not 和括号组合，比较运算符为 = != > >= < <= 及 in (...)、not in (...)
+func ParsePolicyRule(name, text string) (*PolicyRule, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, gerror.New("策略规则名称不能为空")
+	}
+	tokens, err := tokenizePolicy(text)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "策略规则 %s 无效", name)
+	}
+	p := &policyParser{tokens: tokens}
+	rule, err := p.parseRule()
+	if err != nil {
+		return nil, gerror.Wrapf(err, "策略规则 %s 无效", name)
+	}
+	rule.Name = name
+	rule.Text = strings.TrimSpace(text)
+	return rule, nil
+}
+
+// EvaluatePolicies 返回命中操作的全部规则（按规则顺序）
+func EvaluatePolicies(rules []*PolicyRule, input *PolicyInput) []*PolicyRule {
+	var matched []*PolicyRule
+	for _, rule := range rules {
+		if rule.Matches(input) {
+			matched = append(matched, rule)
+		}
+	}
+	return matched
+}
+
+// DecidePolicy 从命中的规则中选出生效的规则：拒绝优先于需要审批，同类取第一条；都未命中时返回 nil
+func DecidePolicy(matched []*PolicyRule) *PolicyRule {
+	var approval *PolicyRule
+	for _, rule := range matched {
+		if rule.Action == PolicyActionDeny {
+			return rule
+		}
+		if approval == nil && rule.Action == PolicyActionRequireApproval {
+			approval = rule
+		}
+	}
+	return approval
+}
+
+// operationApprovedKey 上下文中标记操作已审批的键
+type operationApprovedKey struct{}
+
+// WithOperationApproved 标记操作已经过审批，命中 require approval 策略的操作可以继续执行
+func WithOperationApproved(ctx context.Context) context.Context {
+	return context.WithValue(ctx, operationApprovedKey{}, true)
+}
+
+// IsOperationApproved 检查上下文是否已标记操作已审批
+func IsOperationApproved(ctx context.Context) bool {
+	approved, _ := ctx.Value(operationApprovedKey{}).(bool)
+	return approved
+}
+
+// policyExpr 策略条件表达式
+type policyExpr interface {
+	eval(input *PolicyInput) bool
+}
+
+type policyAnd struct{ left, right policyExpr }
+
+func (e *policyAnd) eval(input *PolicyInput) bool { return e.left.eval(input) && e.right.eval(input) }
+
+type policyOr struct{ left, right policyExpr }
+
+func (e *policyOr) eval(input *PolicyInput) bool { return e.left.eval(input) || e.right.eval(input) }
+
+type policyNot struct{ expr policyExpr }
+
+func (e *policyNot) eval(input *PolicyInput) bool { return !e.expr.eval(input) }
+
+// policyCompare 字段比较；数值字段按 decimal 比较，字符串字段忽略大小写
+type policyCompare struct {
+	field   string
+	op      string // = != > >= < <= in
+	negate  bool   // not in
+	values  []string
+	numbers []decimal.Decimal
+}
+
+func (e *policyCompare) eval(input *PolicyInput) bool {
+	if e.numbers != nil {
+		actual := policyNumberField(e.field, input)
+		if e.op == "in" {
+			found := false
+			for _, number := range e.numbers {
+				found = found || actual.Equal(number)
+			}
+			return found != e.negate
+		}
+		cmp := actual.Cmp(e.numbers[0])
+		switch e.op {
+		case "=":
+			return cmp == 0
+		case "!=":
+			return cmp != 0
+		case ">":
+			return cmp > 0
+		case ">=":
+			return cmp >= 0
+		case "<":
+			return cmp < 0
+		default:
+			return cmp <= 0
+		}
+	}
+
+	actual := policyStringField(e.field, input)
+	found := false
+	for _, value := range e.values {
+		found = found || strings.EqualFold(actual, value)
+	}
+	switch e.op {
+	case "=":
+		return found
+	case "!=":
+		return !found
+	default:
+		return found != e.negate
+	}
+}
+
+// policyNumberField 读取数值字段
+func policyNumberField(field string, input *PolicyInput) decimal.Decimal {
+	switch field {
+	case PolicyFieldAmount:
+		return input.Amount
+	case PolicyFieldDecimals:
+		if exp := input.Amount.Exponent(); exp < 0 {
+			return decimal.NewFromInt(int64(-exp))
+		}
+		return decimal.Zero
+	default:
+		return decimal.NewFromInt(int64(input.UserID))
+	}
+}
+
+// policyStringField 读取字符串字段
+func policyStringField(field string, input *PolicyInput) string {
+	switch field {
+	case PolicyFieldFundType:
+		return string(input.FundType)
+	case PolicyFieldCategory:
+		if info, ok := constants.GetFundTypeInfo(input.FundType); ok {
+			return info.Category
+		}
+		return ""
+	case PolicyFieldDirection:
+		return string(input.Direction)
+	case PolicyFieldToken:
+		return input.TokenSymbol
+	case PolicyFieldSource:
+		return input.RequestSource
+	default:
+		return input.Metadata[strings.TrimPrefix(field, PolicyFieldMetadata)]
+	}
+}
+
+// isPolicyNumberField 判断是否为数值字段
+func isPolicyNumberField(field string) bool {
+	return field == PolicyFieldAmount || field == PolicyFieldDecimals || field == PolicyFieldUserID
+}
+
+// isPolicyField 判断字段是否受支持
+func isPolicyField(field string) bool {
+	switch field {
+	case PolicyFieldFundType, PolicyFieldCategory, PolicyFieldDirection, PolicyFieldToken, PolicyFieldSource,
+		PolicyFieldAmount, PolicyFieldDecimals, PolicyFieldUserID:
+		return true
+	}
+	return strings.HasPrefix(field, PolicyFieldMetadata) && len(field) > len(PolicyFieldMetadata)
+}
+
+// validatePolicyValue 校验字符串字段的取值
+func validatePolicyValue(field, value string) error {
+	switch field {
+	case PolicyFieldFundType:
+		if !constants.IsValidFundType(constants.FundType(value)) {
+			return gerror.Newf("资金类型不存在: %s", value)
+		}
+	case PolicyFieldCategory:
+		if len(constants.GetFundTypesByCategory(value)) == 0 {
+			return gerror.Newf("资金类型分类不存在: %s", value)
+		}
+	case PolicyFieldDirection:
+		if value != string(constants.FundDirectionIn) && value != string(constants.FundDirectionOut) {
+			return gerror.Newf("资金方向必须为 in 或 out: %s", value)
+		}
+	}
+	return nil
+}
+
+// policyTokenKind 词法单元类型
+type policyTokenKind int
+
+const (
+	policyTokenWord   policyTokenKind = iota // 关键字、字段名或未加引号的值
+	policyTokenString                        // 引号中的值
+	policyTokenOp                            // 比较运算符
+	policyTokenPunct                         // 括号和逗号
+)
+
+type policyToken struct {
+	kind  policyTokenKind
+	value string
+}
+
+// tokenizePolicy 将规则文本拆分为词法单元
+func tokenizePolicy(text string) ([]policyToken, error) {
+	var tokens []policyToken
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			i++
+		case r == '(' || r == ')' || r == ',':
+			tokens = append(tokens, policyToken{kind: policyTokenPunct, value: string(r)})
+			i++
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, gerror.New("引号未闭合")
+			}
+			tokens = append(tokens, policyToken{kind: policyTokenString, value: string(runes[i+1 : end])})
+			i = end + 1
+		case r == '=' || r == '!' || r == '<' || r == '>':
+			op := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				op += "="
+			}
+			i += len(op)
+			switch op {
+			case "==":
+				op = "="
+			case "!":
+				return nil, gerror.New("无效的运算符: !")
+			}
+			tokens = append(tokens, policyToken{kind: policyTokenOp, value: op})
+		default:
+			end := i
+			for end < len(runes) && !strings.ContainsRune(" \t\n\r(),\"'=!<>", runes[end]) {
+				end++
+			}
+			tokens = append(tokens, policyToken{kind: policyTokenWord, value: string(runes[i:end])})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+// policyParser 策略规则的递归下降解析器
+type policyParser struct {
+	tokens []policyToken
+	pos    int
+}
+
+// peek 返回当前词法单元，结束时返回 nil
+func (p *policyParser) peek() *policyToken {
+	if p.pos < len(p.tokens) {
+		return &p.tokens[p.pos]
+	}
+	return nil
+}
+
+// keyword 当前词法单元是指定关键字时前进并返回 true
+func (p *policyParser) keyword(word string) bool {
+	if t := p.peek(); t != nil && t.kind == policyTokenWord && strings.EqualFold(t.value, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// punct 当前词法单元是指定符号时前进并返回 true
+func (p *policyParser) punct(value string) bool {
+	if t := p.peek(); t != nil && t.kind == policyTokenPunct && t.value == value {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// parseRule 解析动作、资金类型和条件
+func (p *policyParser) parseRule() (*PolicyRule, error) {
+	rule := &PolicyRule{}
+	switch {
+	case p.keyword("deny"):
+		rule.Action = PolicyActionDeny
+	case p.keyword("require"):
+		if !p.keyword("approval") {
+			return nil, gerror.New("require 之后必须是 approval")
+		}
+		rule.Action = PolicyActionRequireApproval
+	case p.keyword(string(PolicyActionRequireApproval)):
+		rule.Action = PolicyActionRequireApproval
+	default:
+		return nil, gerror.New("规则必须以 deny 或 require approval 开头")
+	}
+
+	for t := p.peek(); t != nil && !(t.kind == policyTokenWord && strings.EqualFold(t.value, "if")); t = p.peek() {
+		if len(rule.FundTypes) > 0 && !p.punct(",") {
+			return nil, gerror.Newf("资金类型之后应为 , 或 if: %s", t.value)
+		}
+		t = p.peek()
+		if t == nil || t.kind != policyTokenWord {
+			return nil, gerror.New("缺少资金类型")
+		}
+		if err := validatePolicyValue(PolicyFieldFundType, t.value); err != nil {
+			return nil, err
+		}
+		rule.FundTypes = append(rule.FundTypes, constants.FundType(t.value))
+		p.pos++
+	}
+
+	if p.keyword("if") {
+		condition, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.peek(); t != nil {
+			return nil, gerror.Newf("条件之后有多余内容: %s", t.value)
+		}
+		rule.condition = condition
+	}
+	return rule, nil
+}
+
+// parseOr 解析 or 连接的条件
+func (p *policyParser) parseOr() (policyExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &policyOr{left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseAnd 解析 and 连接的条件
+func (p *policyParser) parseAnd() (policyExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &policyAnd{left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseUnary 解析 not、括号和比较
+func (p *policyParser) parseUnary() (policyExpr, error) {
+	if p.keyword("not") {
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &policyNot{expr: expr}, nil
+	}
+	if p.punct("(") {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.punct(")") {
+			return nil, gerror.New("缺少 )")
+		}
+		return expr, nil
+	}
+	return p.parseCompare()
+}
+
+// parseCompare 解析字段比较
+func (p *policyParser) parseCompare() (policyExpr, error) {
+	t := p.peek()
+	if t == nil || t.kind != policyTokenWord {
+		return nil, gerror.New("缺少字段名")
+	}
+	field := strings.ToLower(t.value)
+	if strings.HasPrefix(field, PolicyFieldMetadata) {
+		field = PolicyFieldMetadata + t.value[len(PolicyFieldMetadata):]
+	}
+	if !isPolicyField(field) {
+		return nil, gerror.Newf("不支持的字段: %s", t.value)
+	}
+	p.pos++
+
+	compare := &policyCompare{field: field}
+	switch {
+	case p.keyword("in"):
+		compare.op = "in"
+	case p.keyword("not"):
+		if !p.keyword("in") {
+			return nil, gerror.Newf("字段 %s 之后的 not 必须跟 in", field)
+		}
+		compare.op, compare.negate = "in", true
+	default:
+		op := p.peek()
+		if op == nil || op.kind != policyTokenOp {
+			return nil, gerror.Newf("字段 %s 之后缺少比较运算符", field)
+		}
+		compare.op = op.value
+		p.pos++
+	}
+
+	var values []string
+	if compare.op == "in" {
+		if !p.punct("(") {
+			return nil, gerror.New("in 之后必须是 (")
+		}
+		for {
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+			if p.punct(")") {
+				break
+			}
+			if !p.punct(",") {
+				return nil, gerror.New("in 列表缺少 , 或 )")
+			}
+		}
+	} else {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = []string{value}
+	}
+
+	if isPolicyNumberField(field) {
+		compare.numbers = make([]decimal.Decimal, 0, len(values))
+		for _, value := range values {
+			number, err := decimal.NewFromString(value)
+			if err != nil {
+				return nil, gerror.Newf("字段 %s 的值必须是数字: %s", field, value)
+			}
+			compare.numbers = append(compare.numbers, number)
+		}
+		return compare, nil
+	}
+	if compare.op != "=" && compare.op != "!=" && compare.op != "in" {
+		return nil, gerror.Newf("字段 %s 只支持 =、!=、in 和 not in", field)
+	}
+	for _, value := range values {
+		if err := validatePolicyValue(field, value); err != nil {
+			return nil, err
+		}
+	}
+	compare.values = values
+	return compare, nil
+}
+
+// parseValue 解析比较值
+func (p *policyParser) parseValue() (string, error) {
+	t := p.peek()
+	if t == nil || (t.kind != policyTokenWord && t.kind != policyTokenString) {
+		return "", gerror.New("缺少比较值")
+	}
+	p.pos++
+	return t.value, nil
+}
+
+// PolicyDryRunRequest 用历史交易试运行策略规则
+type PolicyDryRunRequest struct {
+	Rules     []PolicyRuleSpec     // 待试运行的规则，为空时使用当前配置的策略（含默认规则）
+	UserID    uint64               // 只扫描该用户的交易
+	Symbol    string               // 只扫描该代币的交易
+	FundTypes []constants.FundType // 只扫描这些资金类型的交易
+	Statuses  []uint               // 只扫描这些状态的交易，为空时不限
+	StartTime *gtime.Time          // created_at >= StartTime
+	EndTime   *gtime.Time          // created_at < EndTime
+	Limit     int                  // 最多扫描的交易笔数，默认 10000
+	Samples   int                  // 每条规则最多返回的命中交易ID数，默认 20
+}
+
+// PolicyRuleStats 试运行中单条规则的命中统计
+type PolicyRuleStats struct {
+	Name     string                     `json:"name"`
+	Action   PolicyAction               `json:"action"`
+	Rule     string                     `json:"rule"`
+	Matches  int                        `json:"matches"`  // 命中的交易笔数
+	Amounts  map[string]decimal.Decimal `json:"amounts"`  // 按代币汇总的命中金额
+	Samples  []uint64                   `json:"samples"`  // 命中的交易ID（最多 Samples 条）
+	Decisive int                        `json:"decisive"` // 作为生效规则（拒绝优先）的交易笔数
+}
+
+// PolicyDryRunReport 策略试运行报告
+type PolicyDryRunReport struct {
+	Scanned          int                `json:"scanned"`           // 扫描的交易笔数
+	Denied           int                `json:"denied"`            // 会被拒绝的交易笔数
+	ApprovalRequired int                `json:"approval_required"` // 会需要审批的交易笔数（未被拒绝）
+	Truncated        bool               `json:"truncated"`         // 达到 Limit 后停止扫描
+	Rules            []*PolicyRuleStats `json:"rules"`
+}
+
+// IPolicyLogic 策略规则业务逻辑接口
+type IPolicyLogic interface {
+	// Check 按 wallet.policies 评估操作；命中拒绝规则，或命中需要审批的规则但上下文未标记已审批时返回 *PolicyError
+	Check(ctx context.Context, input *PolicyInput) error
+	// DryRun 用历史交易试运行策略规则，不影响任何数据
+	DryRun(ctx context.Context, req *PolicyDryRunRequest) (*PolicyDryRunReport, error)
+}
+
+type policyLogic struct {
+	context *SharedLogicContext
+}
+
+// NewPolicyLogic 创建策略规则业务逻辑实例
+func NewPolicyLogic() IPolicyLogic {
+	return &policyLogic{
+		context: GetSharedContext(),
+	}
+}
+
+// loadRules 读取策略配置（wallet.policies），配置无效时返回错误，避免在策略失效的情况下放行交易
+func (l *policyLogic) loadRules(ctx context.Context) ([]*PolicyRule, error) {
+	value, err := g.Cfg().Get(ctx, "wallet.policies")
+	if err != nil {
+		return nil, gerror.Wrap(err, "读取策略配置失败")
+	}
+	raw := map[string]interface{}{}
+	if value != nil && !value.IsEmpty() {
+		raw = value.Map()
+	}
+	rules, err := ParsePolicyConfig(raw)
+	if err != nil {
+		return nil, gerror.Wrap(err, "策略配置无效")
+	}
+	return rules, nil
+}
+
+// Check 按 wallet.policies 评估操作
+func (l *policyLogic) Check(ctx context.Context, input *PolicyInput) error {
+	rules, err := l.loadRules(ctx)
+	if err != nil {
+		return err
+	}
+	rule := DecidePolicy(EvaluatePolicies(rules, input))
+	if rule == nil {
+		return nil
+	}
+	if rule.Action == PolicyActionRequireApproval && IsOperationApproved(ctx) {
+		return nil
+	}
+	g.Log().Infof(ctx, "策略命中: Rule=%s, Action=%s, UserID=%d, FundType=%s, Amount=%s %s",
+		rule.Name, rule.Action, input.UserID, input.FundType, input.Amount.String(), input.TokenSymbol)
+	return &PolicyError{Rule: rule}
+}
+
+// DryRun 用历史交易试运行策略规则
+func (l *policyLogic) DryRun(ctx context.Context, req *PolicyDryRunRequest) (*PolicyDryRunReport, error) {
+	if req == nil {
+		return nil, gerror.New("试运行请求不能为空")
+	}
+	var rules []*PolicyRule
+	var err error
+	if len(req.Rules) > 0 {
+		rules, err = BuildPolicyRules(req.Rules)
+	} else {
+		rules, err = l.loadRules(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	limit, samples := req.Limit, req.Samples
+	if limit <= 0 {
+		limit = 10000
+	}
+	if samples <= 0 {
+		samples = 20
+	}
+
+	report := &PolicyDryRunReport{}
+	stats := make(map[*PolicyRule]*PolicyRuleStats, len(rules))
+	for _, rule := range rules {
+		stat := &PolicyRuleStats{Name: rule.Name, Action: rule.Action, Rule: rule.Text, Amounts: map[string]decimal.Decimal{}}
+		stats[rule] = stat
+		report.Rules = append(report.Rules, stat)
+	}
+
+	filter := &dao.TransactionFilter{
+		UserID:    req.UserID,
+		Symbol:    req.Symbol,
+		Statuses:  req.Statuses,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+		Ascending: true,
+	}
+	for _, fundType := range req.FundTypes {
+		filter.Types = append(filter.Types, string(fundType))
+	}
+
+	const pageSize = 500
+	for report.Scanned < limit {
+		size := min(pageSize, limit-report.Scanned)
+		transactions, err := l.context.GetTransactionDAO().SearchTransactions(ctx, filter, size)
+		if err != nil {
+			return nil, err
+		}
+		for _, transaction := range transactions {
+			report.Scanned++
+			input := &PolicyInput{
+				UserID:        uint64(transaction.UserId),
+				TokenSymbol:   transaction.Symbol,
+				FundType:      constants.FundType(transaction.Type),
+				Direction:     constants.FundDirection(transaction.Direction),
+				Amount:        transaction.Amount,
+				RequestSource: transaction.RequestSource,
+				Metadata:      parsePolicyMetadata(transaction.RequestMetadata),
+			}
+			matched := EvaluatePolicies(rules, input)
+			for _, rule := range matched {
+				stat := stats[rule]
+				stat.Matches++
+				stat.Amounts[transaction.Symbol] = stat.Amounts[transaction.Symbol].Add(transaction.Amount)
+				if len(stat.Samples) < samples {
+					stat.Samples = append(stat.Samples, transaction.TransactionId)
+				}
+			}
+			if decisive := DecidePolicy(matched); decisive != nil {
+				stats[decisive].Decisive++
+				if decisive.Action == PolicyActionDeny {
+					report.Denied++
+				} else {
+					report.ApprovalRequired++
+				}
+			}
+		}
+		if len(transactions) < size {
+			return report, nil
+		}
+		last := transactions[len(transactions)-1]
+		filter.AfterCreatedAt = last.CreatedAt
+		filter.AfterID = last.TransactionId
+	}
+	report.Truncated = true
+	return report, nil
+}
+
+// parsePolicyMetadata 解析交易记录中的请求元数据，无法解析时返回空
+func parsePolicyMetadata(raw string) map[string]string {
+	if raw == "" {
+		return nil
+	}
+	var metadata map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &metadata); err != nil {
+		return nil
+	}
+	return gconv.MapStrStr(metadata)
+}
@@ -0,0 +1,196 @@
+package logic
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/constants"
+)
+
+func mustParsePolicy(t *testing.T, text string) *PolicyRule {
+	t.Helper()
+	rule, err := ParsePolicyRule("test", text)
+	if err != nil {
+		t.Fatalf("parse %q: %v", text, err)
+	}
+	return rule
+}
+
+func TestParsePolicyRule(t *testing.T) {
+	rule := mustParsePolicy(t, "deny withdraw if token=USDT and amount>5000 and source=telegram")
+	if rule.Action != PolicyActionDeny || len(rule.FundTypes) != 1 || rule.FundTypes[0] != constants.FundTypeWithdraw {
+		t.Fatalf("unexpected rule: %+v", rule)
+	}
+	input := &PolicyInput{
+		TokenSymbol:   "usdt",
+		FundType:      constants.FundTypeWithdraw,
+		Amount:        decimal.NewFromInt(5001),
+		RequestSource: "Telegram",
+	}
+	if !rule.Matches(input) {
+		t.Error("expected rule to match")
+	}
+	input.Amount = decimal.NewFromInt(5000)
+	if rule.Matches(input) {
+		t.Error("amount equal to the limit must not match")
+	}
+	input.Amount, input.FundType = decimal.NewFromInt(9000), constants.FundTypeTransferOut
+	if rule.Matches(input) {
+		t.Error("rule must only match its fund types")
+	}
+
+	approval := mustParsePolicy(t, "require approval if fund_type=admin_add and amount>1000")
+	if approval.Action != PolicyActionRequireApproval || len(approval.FundTypes) != 0 {
+		t.Fatalf("unexpected rule: %+v", approval)
+	}
+	if !approval.Matches(&PolicyInput{FundType: constants.FundTypeAdminAdd, Amount: decimal.NewFromInt(1001)}) {
+		t.Error("expected approval rule to match")
+	}
+
+	invalid := []string{
+		"",
+		"allow if amount>1",
+		"require if amount>1",
+		"deny unknown_type",
+		"deny if amount>abc",
+		"deny if token>USDT",
+		"deny if colour=red",
+		"deny if direction=sideways",
+		"deny if category=unknown",
+		"deny if amount>1 and",
+		"deny if (amount>1",
+		"deny if token in (USDT",
+		"deny if token='USDT",
+		"deny if amount ! 1",
+		"deny if amount>1 extra",
+	}
+	for _, text := range invalid {
+		if _, err := ParsePolicyRule("test", text); err == nil {
+			t.Errorf("expected error for %q", text)
+		}
+	}
+}
+
+func TestPolicyConditions(t *testing.T) {
+	input := &PolicyInput{
+		UserID:        42,
+		TokenSymbol:   "BTC",
+		FundType:      constants.FundTypeTransferOut,
+		Direction:     constants.FundDirectionOut,
+		Amount:        decimal.RequireFromString("0.123"),
+		RequestSource: "web",
+		Metadata:      map[string]string{"channel": "Partner A"},
+	}
+	cases := []struct {
+		text string
+		want bool
+	}{
+		{"deny if category=transfer and direction=out", true},
+		{"deny if token in (usdt, 'btc')", true},
+		{"deny if token not in (USDT, ETH)", true},
+		{"deny if user_id in (1, 42)", true},
+		{"deny if decimals >= 3", true},
+		{"deny if decimals > 3", false},
+		{"deny if amount <= 0.123", true},
+		{"deny if amount == 0.12", false},
+		{"deny if source != web", false},
+		{"deny if metadata.channel = \"partner a\"", true},
+		{"deny if metadata.missing = x", false},
+		{"deny if token=USDT or amount<1 and source=web", true},
+		{"deny if (token=USDT or amount<1) and source=app", false},
+		{"deny if not (token=USDT)", true},
+		{"deny transfer_in, transfer_out", true},
+		{"deny transfer_in", false},
+	}
+	for _, c := range cases {
+		if got := mustParsePolicy(t, c.text).Matches(input); got != c.want {
+			t.Errorf("%q: got %v, want %v", c.text, got, c.want)
+		}
+	}
+}
+
+func TestParsePolicyConfig(t *testing.T) {
+	rules, err := ParsePolicyConfig(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	over := DecidePolicy(EvaluatePolicies(rules, &PolicyInput{Amount: decimal.NewFromInt(1000001)}))
+	if over == nil || over.Name != "max_amount" {
+		t.Errorf("default amount cap not applied: %+v", over)
+	}
+	precise := DecidePolicy(EvaluatePolicies(rules, &PolicyInput{Amount: decimal.RequireFromString("0.0000000000000000001")}))
+	if precise == nil || precise.Name != "max_decimals" {
+		t.Errorf("default precision limit not applied: %+v", precise)
+	}
+
+	rules, err = ParsePolicyConfig(map[string]interface{}{
+		"rules": []interface{}{
+			map[string]interface{}{"name": "max_amount", "rule": "deny if amount > 5000000"},
+			map[string]interface{}{"name": "max_decimals", "disabled": true},
+			map[string]interface{}{"name": "admin_add_review", "rule": "require approval if fund_type=admin_add and amount>1000"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rules) != 2 || rules[0].Text != "deny if amount > 5000000" || rules[1].Name != "admin_add_review" {
+		t.Fatalf("unexpected rules: %+v", rules)
+	}
+
+	invalid := []map[string]interface{}{
+		{"rules": []interface{}{map[string]interface{}{"rule": "deny if amount>1"}}},
+		{"rules": []interface{}{map[string]interface{}{"name": "a", "rule": "deny if amount>"}}},
+		{"rules": []interface{}{
+			map[string]interface{}{"name": "a", "rule": "deny if amount>1"},
+			map[string]interface{}{"name": "a", "rule": "deny if amount>2"},
+		}},
+	}
+	for i, raw := range invalid {
+		if _, err := ParsePolicyConfig(raw); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+func TestDecidePolicy(t *testing.T) {
+	approval := &PolicyRule{Name: "review", Action: PolicyActionRequireApproval}
+	deny := &PolicyRule{Name: "block", Action: PolicyActionDeny, Text: "deny withdraw"}
+	if DecidePolicy([]*PolicyRule{approval, deny}) != deny {
+		t.Error("deny must take precedence over approval")
+	}
+	if DecidePolicy([]*PolicyRule{approval}) != approval || DecidePolicy(nil) != nil {
+		t.Error("unexpected decision")
+	}
+
+	err := gerror.Wrap(&PolicyError{Rule: deny}, "增加资金操作失败")
+	if gerror.Code(err) != CodePolicyDenied || !strings.Contains(err.Error(), "deny withdraw") {
+		t.Errorf("unexpected deny error: %v", err)
+	}
+	if gerror.Code(&PolicyError{Rule: approval}) != CodePolicyApprovalRequired {
+		t.Error("unexpected approval error code")
+	}
+}
+
+func TestOperationApproved(t *testing.T) {
+	ctx := context.Background()
+	if IsOperationApproved(ctx) {
+		t.Error("unexpected approved flag")
+	}
+	if !IsOperationApproved(WithOperationApproved(ctx)) {
+		t.Error("approved flag not set")
+	}
+}
+
+func TestParsePolicyMetadata(t *testing.T) {
+	metadata := parsePolicyMetadata(`{"channel":"partner","level":3}`)
+	if metadata["channel"] != "partner" || metadata["level"] != "3" {
+		t.Errorf("unexpected metadata: %v", metadata)
+	}
+	if parsePolicyMetadata("not json") != nil || parsePolicyMetadata("") != nil {
+		t.Error("invalid metadata should be ignored")
+	}
+}
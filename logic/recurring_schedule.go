@@ -0,0 +1,77 @@
+package logic
+
+import (
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+)
+
+// 循环调度类型
+const (
+	RecurringScheduleInterval = "interval" // 固定间隔
+	RecurringScheduleCron     = "cron"     // cron 表达式
+)
+
+// minRecurringInterval 允许的最小执行间隔
+const minRecurringInterval = time.Minute
+
+// RecurringSchedule 循环操作的调度规则
+type RecurringSchedule struct {
+	Type string // 调度类型: interval, cron
+	Spec string // 间隔时长或 cron 表达式
+
+	interval time.Duration
+	cron     *CronSchedule
+}
+
+// ParseRecurringSchedule 解析调度规则
+func ParseRecurringSchedule(scheduleType, spec string) (*RecurringSchedule, error) {
+	schedule := &RecurringSchedule{Type: scheduleType, Spec: spec}
+
+	switch scheduleType {
+	case RecurringScheduleInterval:
+		interval, err := time.ParseDuration(spec)
+		if err != nil {
+			return nil, gerror.Wrapf(err, "无效的执行间隔: %s", spec)
+		}
+		if interval < minRecurringInterval {
+			return nil, gerror.Newf("执行间隔不能小于 %s: %s", minRecurringInterval, spec)
+		}
+		schedule.interval = interval
+	case RecurringScheduleCron:
+		cron, err := ParseCronExpression(spec)
+		if err != nil {
+			return nil, err
+		}
+		schedule.cron = cron
+	default:
+		return nil, gerror.Newf("不支持的调度类型: %s", scheduleType)
+	}
+
+	return schedule, nil
+}
+
+// First 返回 start 之后（含 start）的首次执行时间
+func (s *RecurringSchedule) First(start time.Time) time.Time {
+	if s.cron != nil {
+		return s.cron.Next(start.Add(-time.Minute))
+	}
+	return start
+}
+
+// NextAfter 根据上次计划时间计算下一次执行时间，停机期间错过的执行会被跳过，结果总是晚于 now
+func (s *RecurringSchedule) NextAfter(previous, now time.Time) time.Time {
+	if s.cron != nil {
+		if previous.After(now) {
+			return s.cron.Next(previous)
+		}
+		return s.cron.Next(now)
+	}
+
+	next := previous.Add(s.interval)
+	if !next.After(now) {
+		missed := now.Sub(previous) / s.interval
+		next = previous.Add((missed + 1) * s.interval)
+	}
+	return next
+}
@@ -0,0 +1,528 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/constants"
+	"github.com/yalks/wallet/dao"
+)
+
+// RiskDecision 风控决策
+type RiskDecision string
+
+const (
+	RiskDecisionAllow     RiskDecision = "allow"     // 放行
+	RiskDecisionChallenge RiskDecision = "challenge" // 需要验证支付密码
+	RiskDecisionBlock     RiskDecision = "block"     // 拦截
+)
+
+// 内置风控规则名称
+const (
+	RiskRuleNewIP         = "new_ip"         // 用户首次使用的 IP
+	RiskRuleIPVelocity    = "ip_velocity"    // 同一 IP 短时间内交易过多
+	RiskRuleLargeAmount   = "large_amount"   // 金额远高于用户历史平均
+	RiskRuleAddressChange = "address_change" // 提现前刚修改过地址簿
+)
+
+// RiskOperation 待评估的资金操作
+type RiskOperation struct {
+	UserID        uint64                  // 用户ID
+	TokenSymbol   string                  // 代币符号
+	FundType      constants.FundType      // 资金类型
+	Direction     constants.FundDirection // 资金方向
+	Amount        decimal.Decimal         // 交易金额
+	RequestSource string                  // 请求来源
+	IP            string                  // 请求IP
+	UserAgent     string                  // 请求 User-Agent
+	Metadata      map[string]string       // 请求上下文元数据（设备等）
+}
+
+// RiskSignals 风控信号：待评估的操作及从历史数据中计算的特征
+type RiskSignals struct {
+	*RiskOperation
+	Now              time.Time
+	NewIP            bool            // 回看期内用户有历史交易，但从未使用过该 IP
+	IPOperationCount int             // IP 速率窗口内该 IP 发起的交易数（所有用户）
+	HistoryCount     int             // 回看期内用户该代币同方向的成功交易笔数
+	HistoryAverage   decimal.Decimal // 回看期内用户该代币同方向的平均交易金额
+	AddressChangedAt *time.Time      // 用户提现地址簿最近一次新增或删除的时间
+}
+
+// RiskRule 风控规则：返回本次操作的风险分，0 表示未触发
+type RiskRule interface {
+	// Name 规则名称，记录在交易的 risk_reasons 中
+	Name() string
+	// Score 根据风控信号计算风险分
+	Score(ctx context.Context, signals *RiskSignals) (int, error)
+}
+
+// NewIPRiskRule 用户使用了回看期内从未出现过的 IP
+type NewIPRiskRule struct {
+	Points int
+}
+
+// Name 实现 RiskRule
+func (r *NewIPRiskRule) Name() string { return RiskRuleNewIP }
+
+// Score 实现 RiskRule
+func (r *NewIPRiskRule) Score(_ context.Context, signals *RiskSignals) (int, error) {
+	if signals.NewIP {
+		return r.Points, nil
+	}
+	return 0, nil
+}
+
+// IPVelocityRiskRule 同一 IP 在窗口内发起的交易数达到阈值（含本次）
+type IPVelocityRiskRule struct {
+	Window    time.Duration
+	Threshold int
+	Points    int
+}
+
+// Name 实现 RiskRule
+func (r *IPVelocityRiskRule) Name() string { return RiskRuleIPVelocity }
+
+// Score 实现 RiskRule
+func (r *IPVelocityRiskRule) Score(_ context.Context, signals *RiskSignals) (int, error) {
+	if signals.IP != "" && r.Threshold > 0 && signals.IPOperationCount+1 >= r.Threshold {
+		return r.Points, nil
+	}
+	return 0, nil
+}
+
+// LargeAmountRiskRule 金额超过用户历史平均金额的指定倍数；历史交易不足时不触发
+type LargeAmountRiskRule struct {
+	Multiplier decimal.Decimal
+	MinHistory int
+	Points     int
+}
+
+// Name 实现 RiskRule
+func (r *LargeAmountRiskRule) Name() string { return RiskRuleLargeAmount }
+
+// Score 实现 RiskRule
+func (r *LargeAmountRiskRule) Score(_ context.Context, signals *RiskSignals) (int, error) {
+	if signals.HistoryCount == 0 || signals.HistoryCount < r.MinHistory {
+		return 0, nil
+	}
+	if signals.Amount.GreaterThan(signals.HistoryAverage.Mul(r.Multiplier)) {
+		return r.Points, nil
+	}
+	return 0, nil
+}
+
+// AddressChangeRiskRule 提现前指定时间内修改过提现地址簿
+type AddressChangeRiskRule struct {
+	Within time.Duration
+	Points int
+}
+
+// Name 实现 RiskRule
+func (r *AddressChangeRiskRule) Name() string { return RiskRuleAddressChange }
+
+// Score 实现 RiskRule
+func (r *AddressChangeRiskRule) Score(_ context.Context, signals *RiskSignals) (int, error) {
+	if signals.FundType != constants.FundTypeWithdraw || signals.AddressChangedAt == nil {
+		return 0, nil
+	}
+	if signals.Now.Sub(*signals.AddressChangedAt) < r.Within {
+		return r.Points, nil
+	}
+	return 0, nil
+}
+
+// RiskConfig 风控配置（对应配置项 wallet.risk）；内置规则分值为 0 时不启用
+type RiskConfig struct {
+	Enabled        bool                      `json:"enabled"`        // 是否启用风控评估
+	Directions     []constants.FundDirection `json:"directions"`     // 需要评估的资金方向，默认仅 out
+	ExcludeSources []string                  `json:"excludeSources"` // 不评估的请求来源
+	ChallengeScore int                       `json:"challengeScore"` // 达到该分值时需要验证支付密码
+	BlockScore     int                       `json:"blockScore"`     // 达到该分值时拦截
+	Lookback       time.Duration             `json:"lookback"`       // 新 IP 和历史金额的回看期
+	NewIP          NewIPRiskRule             `json:"newIp"`
+	IPVelocity     IPVelocityRiskRule        `json:"ipVelocity"`
+	LargeAmount    LargeAmountRiskRule       `json:"largeAmount"`
+	AddressChange  AddressChangeRiskRule     `json:"addressChange"`
+}
+
+// RiskAssessment 风控评估结果
+type RiskAssessment struct {
+	Score    int          `json:"score"`
+	Decision RiskDecision `json:"decision"`
+	Reasons  []string     `json:"reasons,omitempty"` // 触发的规则名称
+}
+
+// ReasonsString 返回逗号分隔的触发规则，用于写入交易记录
+func (a *RiskAssessment) ReasonsString() string {
+	return strings.Join(a.Reasons, ",")
+}
+
+// RiskError 风控拦截或需要验证支付密码
+type RiskError struct {
+	Assessment *RiskAssessment
+}
+
+// Error 实现 error 接口
+func (e *RiskError) Error() string {
+	if e.Assessment.Decision == RiskDecisionBlock {
+		return fmt.Sprintf("交易被风控拦截: 风险分=%d, 触发规则=%s", e.Assessment.Score, e.Assessment.ReasonsString())
+	}
+	return fmt.Sprintf("交易需要验证支付密码: 风险分=%d, 触发规则=%s", e.Assessment.Score, e.Assessment.ReasonsString())
+}
+
+// Code 返回错误码：拦截为 CodeRiskBlocked，需要验证为 CodeRiskChallenge
+func (e *RiskError) Code() gcode.Code {
+	if e.Assessment.Decision == RiskDecisionBlock {
+		return CodeRiskBlocked
+	}
+	return CodeRiskChallenge
+}
+
+// ParseRiskConfig 解析风控配置并应用默认值
+func ParseRiskConfig(raw map[string]interface{}) (RiskConfig, error) {
+	config := RiskConfig{
+		Enabled:        gconv.Bool(raw["enabled"]),
+		ChallengeScore: 50,
+		BlockScore:     100,
+		Lookback:       30 * 24 * time.Hour,
+		IPVelocity:     IPVelocityRiskRule{Window: time.Hour},
+		LargeAmount:    LargeAmountRiskRule{Multiplier: decimal.NewFromInt(5), MinHistory: 3},
+		AddressChange:  AddressChangeRiskRule{Within: 24 * time.Hour},
+	}
+	for _, direction := range gconv.Strings(raw["directions"]) {
+		config.Directions = append(config.Directions, constants.FundDirection(direction))
+	}
+	if len(config.Directions) == 0 {
+		config.Directions = []constants.FundDirection{constants.FundDirectionOut}
+	}
+	for _, source := range gconv.Strings(raw["excludeSources"]) {
+		config.ExcludeSources = append(config.ExcludeSources, strings.ToLower(strings.TrimSpace(source)))
+	}
+	if v, ok := raw["challengeScore"]; ok {
+		config.ChallengeScore = gconv.Int(v)
+	}
+	if v, ok := raw["blockScore"]; ok {
+		config.BlockScore = gconv.Int(v)
+	}
+
+	var err error
+	if config.Lookback, err = parseRiskDuration(raw["lookback"], config.Lookback); err != nil {
+		return config, gerror.Wrap(err, "无效的风控回看期")
+	}
+
+	newIP := gconv.Map(raw["newIp"])
+	config.NewIP.Points = gconv.Int(newIP["score"])
+
+	ipVelocity := gconv.Map(raw["ipVelocity"])
+	config.IPVelocity.Threshold = gconv.Int(ipVelocity["threshold"])
+	config.IPVelocity.Points = gconv.Int(ipVelocity["score"])
+	if config.IPVelocity.Window, err = parseRiskDuration(ipVelocity["window"], config.IPVelocity.Window); err != nil {
+		return config, gerror.Wrap(err, "无效的 IP 速率窗口")
+	}
+
+	largeAmount := gconv.Map(raw["largeAmount"])
+	config.LargeAmount.Points = gconv.Int(largeAmount["score"])
+	if v, ok := largeAmount["minHistory"]; ok {
+		config.LargeAmount.MinHistory = gconv.Int(v)
+	}
+	if v := gconv.String(largeAmount["multiplier"]); v != "" {
+		if config.LargeAmount.Multiplier, err = decimal.NewFromString(v); err != nil {
+			return config, gerror.Wrapf(err, "无效的大额倍数: %s", v)
+		}
+	}
+
+	addressChange := gconv.Map(raw["addressChange"])
+	config.AddressChange.Points = gconv.Int(addressChange["score"])
+	if config.AddressChange.Within, err = parseRiskDuration(addressChange["within"], config.AddressChange.Within); err != nil {
+		return config, gerror.Wrap(err, "无效的地址簿变更时间")
+	}
+
+	return config, ValidateRiskConfig(&config)
+}
+
+// parseRiskDuration 解析时长，未配置时使用默认值
+func parseRiskDuration(value interface{}, fallback time.Duration) (time.Duration, error) {
+	raw := gconv.String(value)
+	if raw == "" {
+		return fallback, nil
+	}
+	return gtime.ParseDuration(raw)
+}
+
+// ValidateRiskConfig 校验风控配置
+func ValidateRiskConfig(config *RiskConfig) error {
+	for _, direction := range config.Directions {
+		if !constants.IsValidFundDirection(direction) {
+			return gerror.Newf("风控配置的资金方向无效: %s", direction)
+		}
+	}
+	if config.ChallengeScore <= 0 || config.BlockScore <= 0 {
+		return gerror.New("风控阈值必须大于 0")
+	}
+	if config.BlockScore < config.ChallengeScore {
+		return gerror.Newf("拦截阈值 %d 不能小于验证阈值 %d", config.BlockScore, config.ChallengeScore)
+	}
+	if config.Lookback <= 0 || config.IPVelocity.Window <= 0 || config.AddressChange.Within <= 0 {
+		return gerror.New("风控时间窗口必须大于 0")
+	}
+	if config.NewIP.Points < 0 || config.IPVelocity.Points < 0 || config.LargeAmount.Points < 0 || config.AddressChange.Points < 0 {
+		return gerror.New("风控规则分值不能为负数")
+	}
+	if config.IPVelocity.Points > 0 && config.IPVelocity.Threshold <= 0 {
+		return gerror.New("IP 速率规则需要大于 0 的阈值")
+	}
+	if config.LargeAmount.Points > 0 && !config.LargeAmount.Multiplier.IsPositive() {
+		return gerror.New("大额规则的倍数必须大于 0")
+	}
+	return nil
+}
+
+// applies 检查操作是否需要评估
+func (c *RiskConfig) applies(op *RiskOperation) bool {
+	source := strings.ToLower(op.RequestSource)
+	for _, excluded := range c.ExcludeSources {
+		if excluded == source {
+			return false
+		}
+	}
+	for _, direction := range c.Directions {
+		if direction == op.Direction {
+			return true
+		}
+	}
+	return false
+}
+
+// builtinRules 返回分值大于 0 的内置规则
+func (c *RiskConfig) builtinRules() []RiskRule {
+	var rules []RiskRule
+	if c.NewIP.Points > 0 {
+		rules = append(rules, &c.NewIP)
+	}
+	if c.IPVelocity.Points > 0 {
+		rules = append(rules, &c.IPVelocity)
+	}
+	if c.LargeAmount.Points > 0 {
+		rules = append(rules, &c.LargeAmount)
+	}
+	if c.AddressChange.Points > 0 {
+		rules = append(rules, &c.AddressChange)
+	}
+	return rules
+}
+
+// DecideRisk 按风险分和阈值得出决策
+func DecideRisk(score, challengeScore, blockScore int) RiskDecision {
+	switch {
+	case score >= blockScore:
+		return RiskDecisionBlock
+	case score >= challengeScore:
+		return RiskDecisionChallenge
+	default:
+		return RiskDecisionAllow
+	}
+}
+
+// ScoreRisk 依次执行规则并汇总风险分
+func ScoreRisk(ctx context.Context, rules []RiskRule, signals *RiskSignals, challengeScore, blockScore int) (*RiskAssessment, error) {
+	assessment := &RiskAssessment{}
+	for _, rule := range rules {
+		score, err := rule.Score(ctx, signals)
+		if err != nil {
+			return nil, gerror.Wrapf(err, "风控规则执行失败: %s", rule.Name())
+		}
+		if score > 0 {
+			assessment.Score += score
+			assessment.Reasons = append(assessment.Reasons, rule.Name())
+		}
+	}
+	assessment.Decision = DecideRisk(assessment.Score, challengeScore, blockScore)
+	return assessment, nil
+}
+
+var (
+	riskRuleMu     sync.RWMutex
+	customRiskRule []RiskRule
+)
+
+// RegisterRiskRule 注册自定义风控规则，与内置规则一起参与评分；名称不能与已有规则重复
+func RegisterRiskRule(rule RiskRule) error {
+	if rule == nil || rule.Name() == "" {
+		return gerror.New("风控规则名称不能为空")
+	}
+	switch rule.Name() {
+	case RiskRuleNewIP, RiskRuleIPVelocity, RiskRuleLargeAmount, RiskRuleAddressChange:
+		return gerror.Newf("风控规则名称与内置规则冲突: %s", rule.Name())
+	}
+
+	riskRuleMu.Lock()
+	defer riskRuleMu.Unlock()
+	for _, existing := range customRiskRule {
+		if existing.Name() == rule.Name() {
+			return gerror.Newf("风控规则已注册: %s", rule.Name())
+		}
+	}
+	customRiskRule = append(customRiskRule, rule)
+	return nil
+}
+
+// registeredRiskRules 返回已注册的自定义风控规则
+func registeredRiskRules() []RiskRule {
+	riskRuleMu.RLock()
+	defer riskRuleMu.RUnlock()
+	return append([]RiskRule(nil), customRiskRule...)
+}
+
+// paymentVerifiedKey 上下文中标记支付密码已验证的键
+type paymentVerifiedKey struct{}
+
+// WithPaymentPasswordVerified 标记调用方已验证用户的支付密码，风控决策为 challenge 的操作可以继续执行
+func WithPaymentPasswordVerified(ctx context.Context) context.Context {
+	return context.WithValue(ctx, paymentVerifiedKey{}, true)
+}
+
+// IsPaymentPasswordVerified 检查上下文是否已标记支付密码验证通过
+func IsPaymentPasswordVerified(ctx context.Context) bool {
+	verified, _ := ctx.Value(paymentVerifiedKey{}).(bool)
+	return verified
+}
+
+// IRiskLogic 风控评估业务逻辑接口
+type IRiskLogic interface {
+	// Evaluate 评估资金操作的风险；未启用或无需评估时返回 nil。
+	// 决策为 block，或为 challenge 但上下文未标记支付密码已验证时，返回 *RiskError
+	Evaluate(ctx context.Context, op *RiskOperation) (*RiskAssessment, error)
+}
+
+type riskLogic struct {
+	context *SharedLogicContext
+}
+
+// NewRiskLogic 创建风控评估业务逻辑实例
+func NewRiskLogic() IRiskLogic {
+	return &riskLogic{
+		context: GetSharedContext(),
+	}
+}
+
+// loadConfig 读取风控配置（wallet.risk），配置无效时返回错误，避免在风控失效的情况下放行交易
+func (l *riskLogic) loadConfig(ctx context.Context) (RiskConfig, error) {
+	value, err := g.Cfg().Get(ctx, "wallet.risk")
+	if err != nil {
+		return RiskConfig{}, gerror.Wrap(err, "读取风控配置失败")
+	}
+	if value == nil || value.IsEmpty() {
+		return RiskConfig{}, nil
+	}
+	config, err := ParseRiskConfig(value.Map())
+	if err != nil {
+		return RiskConfig{}, gerror.Wrap(err, "风控配置无效")
+	}
+	return config, nil
+}
+
+// Evaluate 评估资金操作的风险
+func (l *riskLogic) Evaluate(ctx context.Context, op *RiskOperation) (*RiskAssessment, error) {
+	config, err := l.loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !config.Enabled || !config.applies(op) {
+		return nil, nil
+	}
+
+	rules := append(config.builtinRules(), registeredRiskRules()...)
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	signals, err := l.collectSignals(ctx, &config, op)
+	if err != nil {
+		return nil, err
+	}
+	assessment, err := ScoreRisk(ctx, rules, signals, config.ChallengeScore, config.BlockScore)
+	if err != nil {
+		return nil, err
+	}
+
+	if assessment.Decision != RiskDecisionAllow {
+		g.Log().Infof(ctx, "风控评估: UserID=%d, FundType=%s, Amount=%s, Score=%d, Decision=%s, Reasons=%s",
+			op.UserID, op.FundType, op.Amount.String(), assessment.Score, assessment.Decision, assessment.ReasonsString())
+	}
+	switch assessment.Decision {
+	case RiskDecisionBlock:
+		return assessment, &RiskError{Assessment: assessment}
+	case RiskDecisionChallenge:
+		if !IsPaymentPasswordVerified(ctx) {
+			return assessment, &RiskError{Assessment: assessment}
+		}
+	}
+	return assessment, nil
+}
+
+// collectSignals 从交易表和地址簿中计算风控信号
+func (l *riskLogic) collectSignals(ctx context.Context, config *RiskConfig, op *RiskOperation) (*RiskSignals, error) {
+	now := time.Now()
+	signals := &RiskSignals{RiskOperation: op, Now: now, HistoryAverage: decimal.Zero}
+	transactionDAO := l.context.GetTransactionDAO()
+	lookbackStart := gtime.New(now.Add(-config.Lookback))
+
+	if config.NewIP.Points > 0 || config.LargeAmount.Points > 0 {
+		count, average, err := transactionDAO.GetAmountStats(ctx, &dao.TransactionFilter{
+			UserID:    op.UserID,
+			Symbol:    op.TokenSymbol,
+			Direction: string(op.Direction),
+			Statuses:  []uint{1},
+			StartTime: lookbackStart,
+		})
+		if err != nil {
+			return nil, err
+		}
+		signals.HistoryCount, signals.HistoryAverage = count, average
+	}
+
+	if op.IP != "" && config.NewIP.Points > 0 && signals.HistoryCount > 0 {
+		count, err := transactionDAO.CountTransactions(ctx, &dao.TransactionFilter{
+			UserID:    op.UserID,
+			RequestIP: op.IP,
+			StartTime: lookbackStart,
+		})
+		if err != nil {
+			return nil, err
+		}
+		signals.NewIP = count == 0
+	}
+
+	if op.IP != "" && config.IPVelocity.Points > 0 {
+		count, err := transactionDAO.CountTransactions(ctx, &dao.TransactionFilter{
+			RequestIP: op.IP,
+			StartTime: gtime.New(now.Add(-config.IPVelocity.Window)),
+		})
+		if err != nil {
+			return nil, err
+		}
+		signals.IPOperationCount = count
+	}
+
+	if op.FundType == constants.FundTypeWithdraw {
+		changedAt, err := l.context.GetWithdrawAddressDAO().GetLatestChangeTime(ctx, op.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if changedAt != nil {
+			t := changedAt.Time
+			signals.AddressChangedAt = &t
+		}
+	}
+	return signals, nil
+}
@@ -0,0 +1,172 @@
+package logic
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/constants"
+)
+
+func TestParseRiskConfig(t *testing.T) {
+	config, err := ParseRiskConfig(map[string]interface{}{
+		"enabled":        true,
+		"excludeSources": []interface{}{" Admin "},
+		"challengeScore": 40,
+		"blockScore":     90,
+		"lookback":       "7d",
+		"newIp":          map[string]interface{}{"score": 30},
+		"ipVelocity":     map[string]interface{}{"window": "10m", "threshold": 5, "score": 40},
+		"largeAmount":    map[string]interface{}{"multiplier": "3", "score": 30},
+		"addressChange":  map[string]interface{}{"score": 50},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !config.Enabled || config.ChallengeScore != 40 || config.BlockScore != 90 || config.Lookback != 7*24*time.Hour {
+		t.Errorf("unexpected config: %+v", config)
+	}
+	if len(config.Directions) != 1 || config.Directions[0] != constants.FundDirectionOut {
+		t.Errorf("directions should default to out: %v", config.Directions)
+	}
+	if config.IPVelocity.Window != 10*time.Minute || config.LargeAmount.MinHistory != 3 || config.AddressChange.Within != 24*time.Hour {
+		t.Errorf("defaults not applied: %+v", config)
+	}
+	if len(config.builtinRules()) != 4 {
+		t.Errorf("expected 4 enabled rules, got %d", len(config.builtinRules()))
+	}
+	if config.applies(&RiskOperation{Direction: constants.FundDirectionOut, RequestSource: "admin"}) {
+		t.Error("excluded source must not be evaluated")
+	}
+	if config.applies(&RiskOperation{Direction: constants.FundDirectionIn, RequestSource: "web"}) {
+		t.Error("credits are not evaluated by default")
+	}
+
+	invalid := []map[string]interface{}{
+		{"directions": []interface{}{"sideways"}},
+		{"challengeScore": 80, "blockScore": 50},
+		{"challengeScore": 0},
+		{"ipVelocity": map[string]interface{}{"score": 10}},
+		{"largeAmount": map[string]interface{}{"score": 10, "multiplier": "0"}},
+		{"newIp": map[string]interface{}{"score": -1}},
+		{"lookback": "soon"},
+	}
+	for i, raw := range invalid {
+		if _, err := ParseRiskConfig(raw); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+func TestBuiltinRiskRules(t *testing.T) {
+	now := time.Now()
+	changedAt := now.Add(-time.Hour)
+	signals := &RiskSignals{
+		RiskOperation: &RiskOperation{
+			FundType: constants.FundTypeWithdraw,
+			Amount:   decimal.NewFromInt(600),
+			IP:       "10.0.0.1",
+		},
+		Now:              now,
+		NewIP:            true,
+		IPOperationCount: 4,
+		HistoryCount:     3,
+		HistoryAverage:   decimal.NewFromInt(100),
+		AddressChangedAt: &changedAt,
+	}
+	ctx := context.Background()
+
+	cases := []struct {
+		rule RiskRule
+		want int
+	}{
+		{&NewIPRiskRule{Points: 30}, 30},
+		{&IPVelocityRiskRule{Threshold: 5, Points: 20}, 20},
+		{&IPVelocityRiskRule{Threshold: 6, Points: 20}, 0},
+		{&LargeAmountRiskRule{Multiplier: decimal.NewFromInt(5), MinHistory: 3, Points: 25}, 25},
+		{&LargeAmountRiskRule{Multiplier: decimal.NewFromInt(6), MinHistory: 3, Points: 25}, 0},
+		{&LargeAmountRiskRule{Multiplier: decimal.NewFromInt(5), MinHistory: 4, Points: 25}, 0},
+		{&AddressChangeRiskRule{Within: 24 * time.Hour, Points: 50}, 50},
+		{&AddressChangeRiskRule{Within: 30 * time.Minute, Points: 50}, 0},
+	}
+	for i, c := range cases {
+		got, err := c.rule.Score(ctx, signals)
+		if err != nil || got != c.want {
+			t.Errorf("case %d (%s): got %d, %v; want %d", i, c.rule.Name(), got, err, c.want)
+		}
+	}
+
+	signals.FundType = constants.FundTypeTransferOut
+	if got, _ := (&AddressChangeRiskRule{Within: 24 * time.Hour, Points: 50}).Score(ctx, signals); got != 0 {
+		t.Error("address change rule only applies to withdrawals")
+	}
+}
+
+func TestScoreRisk(t *testing.T) {
+	signals := &RiskSignals{RiskOperation: &RiskOperation{IP: "10.0.0.1"}, NewIP: true, IPOperationCount: 10}
+	rules := []RiskRule{
+		&NewIPRiskRule{Points: 30},
+		&IPVelocityRiskRule{Threshold: 5, Points: 40},
+		&LargeAmountRiskRule{Multiplier: decimal.NewFromInt(5), Points: 40},
+	}
+	assessment, err := ScoreRisk(context.Background(), rules, signals, 50, 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if assessment.Score != 70 || assessment.Decision != RiskDecisionChallenge || assessment.ReasonsString() != "new_ip,ip_velocity" {
+		t.Errorf("unexpected assessment: %+v", assessment)
+	}
+
+	if DecideRisk(100, 50, 100) != RiskDecisionBlock || DecideRisk(49, 50, 100) != RiskDecisionAllow {
+		t.Error("unexpected decision thresholds")
+	}
+}
+
+func TestRiskError(t *testing.T) {
+	blocked := &RiskError{Assessment: &RiskAssessment{Score: 120, Decision: RiskDecisionBlock, Reasons: []string{"new_ip"}}}
+	if gerror.Code(gerror.Wrap(blocked, "扣除资金操作失败")) != CodeRiskBlocked {
+		t.Error("block error code lost after wrapping")
+	}
+	challenge := &RiskError{Assessment: &RiskAssessment{Score: 60, Decision: RiskDecisionChallenge}}
+	if gerror.Code(challenge) != CodeRiskChallenge {
+		t.Error("unexpected challenge error code")
+	}
+}
+
+type testRiskRule struct{ name string }
+
+func (r *testRiskRule) Name() string { return r.name }
+
+func (r *testRiskRule) Score(context.Context, *RiskSignals) (int, error) { return 0, nil }
+
+func TestRegisterRiskRule(t *testing.T) {
+	if err := RegisterRiskRule(&testRiskRule{name: RiskRuleNewIP}); err == nil {
+		t.Error("expected conflict with built-in rule")
+	}
+	if err := RegisterRiskRule(&testRiskRule{name: "test_device"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := RegisterRiskRule(&testRiskRule{name: "test_device"}); err == nil {
+		t.Error("expected duplicate registration error")
+	}
+	found := false
+	for _, rule := range registeredRiskRules() {
+		found = found || rule.Name() == "test_device"
+	}
+	if !found {
+		t.Error("registered rule missing")
+	}
+}
+
+func TestPaymentPasswordVerified(t *testing.T) {
+	ctx := context.Background()
+	if IsPaymentPasswordVerified(ctx) {
+		t.Error("unexpected verified flag")
+	}
+	if !IsPaymentPasswordVerified(WithPaymentPasswordVerified(ctx)) {
+		t.Error("verified flag not set")
+	}
+}
@@ -0,0 +1,260 @@
+package logic
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/shopspring/decimal"
+)
+
+// StatementFormat 对账单输出格式
+type StatementFormat string
+
+const (
+	StatementFormatCSV   StatementFormat = "csv"   // CSV，首行为表头
+	StatementFormatJSONL StatementFormat = "jsonl" // JSON Lines，每行一个 JSON 对象
+)
+
+// statementTimeLayout 对账单中的时间格式
+const statementTimeLayout = "2006-01-02 15:04:05"
+
+// 对账单记录类型
+const (
+	StatementRecordOpening     = "opening"     // 期初余额
+	StatementRecordTransaction = "transaction" // 交易明细
+	StatementRecordClosing     = "closing"     // 期末余额
+)
+
+// NumberFormat 数字格式（小数点与千位分隔符）
+type NumberFormat struct {
+	DecimalSeparator string
+	GroupSeparator   string
+}
+
+// 按语言区分的数字格式，未列出的语言使用英文格式；空格分组使用不换行空格
+var (
+	numberFormatDot   = NumberFormat{DecimalSeparator: ".", GroupSeparator: ","}
+	numberFormatComma = NumberFormat{DecimalSeparator: ",", GroupSeparator: "."}
+	numberFormatSpace = NumberFormat{DecimalSeparator: ",", GroupSeparator: "\u00a0"}
+	numberFormatSwiss = NumberFormat{DecimalSeparator: ".", GroupSeparator: "'"}
+
+	languageNumberFormats = map[string]NumberFormat{
+		"de": numberFormatComma, "es": numberFormatComma, "it": numberFormatComma,
+		"pt": numberFormatComma, "nl": numberFormatComma, "id": numberFormatComma,
+		"vi": numberFormatComma, "tr": numberFormatComma, "da": numberFormatComma,
+		"fr": numberFormatSpace, "ru": numberFormatSpace, "uk": numberFormatSpace,
+		"pl": numberFormatSpace, "cs": numberFormatSpace, "sv": numberFormatSpace,
+		"fi": numberFormatSpace, "nb": numberFormatSpace,
+		"de-ch": numberFormatSwiss,
+	}
+)
+
+// LocaleNumberFormat 根据用户语言（如 zh-CN、de、pt_BR）返回数字格式
+func LocaleNumberFormat(language string) NumberFormat {
+	language = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(language), "_", "-"))
+	if f, ok := languageNumberFormats[language]; ok {
+		return f
+	}
+	base, _, _ := strings.Cut(language, "-")
+	if f, ok := languageNumberFormats[base]; ok {
+		return f
+	}
+	return numberFormatDot
+}
+
+// Format 按指定小数位数格式化金额
+func (f NumberFormat) Format(value decimal.Decimal, places int32) string {
+	text := value.StringFixed(places)
+
+	sign := ""
+	if strings.HasPrefix(text, "-") {
+		sign, text = "-", text[1:]
+	}
+	integer, fraction, _ := strings.Cut(text, ".")
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(f.GroupSeparator)
+		}
+		b.WriteRune(digit)
+	}
+	if fraction != "" {
+		b.WriteString(f.DecimalSeparator)
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
+
+// StatementEntry 对账单交易明细
+type StatementEntry struct {
+	TransactionID    uint64
+	Time             time.Time
+	FundType         string
+	Direction        string
+	Amount           decimal.Decimal
+	Fee              decimal.Decimal
+	Balance          decimal.Decimal // 该笔交易后的累计余额
+	CounterpartyID   uint64
+	CounterpartyName string
+	Reference        string
+	Memo             string
+}
+
+// StatementWriter 对账单流式输出：依次写入期初余额、交易明细和期末余额，不在内存中保存明细
+type StatementWriter interface {
+	WriteOpening(at time.Time, balance decimal.Decimal) error
+	WriteEntry(entry *StatementEntry) error
+	WriteClosing(at time.Time, balance decimal.Decimal) error
+}
+
+// NewStatementWriter 创建对账单输出；places 为代币精度
+func NewStatementWriter(format StatementFormat, w io.Writer, tokenSymbol string, numberFormat NumberFormat, places int32) (StatementWriter, error) {
+	base := statementFormatter{symbol: tokenSymbol, numbers: numberFormat, places: places}
+	switch format {
+	case StatementFormatCSV:
+		return &csvStatementWriter{statementFormatter: base, writer: csv.NewWriter(w)}, nil
+	case StatementFormatJSONL:
+		return &jsonlStatementWriter{statementFormatter: base, encoder: json.NewEncoder(w)}, nil
+	default:
+		return nil, gerror.Newf("不支持的对账单格式: %s", format)
+	}
+}
+
+// statementFormatter 对账单字段格式化
+type statementFormatter struct {
+	symbol  string
+	numbers NumberFormat
+	places  int32
+}
+
+func (f statementFormatter) amount(value decimal.Decimal) string {
+	return f.numbers.Format(value, f.places)
+}
+
+func formatStatementTime(t time.Time) string {
+	return t.Format(statementTimeLayout)
+}
+
+// csvStatementHeader CSV 对账单表头
+var csvStatementHeader = []string{
+	"record_type", "time", "transaction_id", "token", "fund_type", "direction", "amount", "fee", "balance",
+	"counterparty_id", "counterparty", "reference", "memo",
+}
+
+type csvStatementWriter struct {
+	statementFormatter
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+func (w *csvStatementWriter) write(record []string) error {
+	if !w.headerWritten {
+		w.headerWritten = true
+		if err := w.writer.Write(csvStatementHeader); err != nil {
+			return gerror.Wrap(err, "写入对账单失败")
+		}
+	}
+	if err := w.writer.Write(record); err != nil {
+		return gerror.Wrap(err, "写入对账单失败")
+	}
+	return nil
+}
+
+func (w *csvStatementWriter) WriteOpening(at time.Time, balance decimal.Decimal) error {
+	return w.write([]string{StatementRecordOpening, formatStatementTime(at), "", w.symbol, "", "", "", "", w.amount(balance), "", "", "", ""})
+}
+
+func (w *csvStatementWriter) WriteEntry(entry *StatementEntry) error {
+	counterpartyID := ""
+	if entry.CounterpartyID != 0 {
+		counterpartyID = strconv.FormatUint(entry.CounterpartyID, 10)
+	}
+	return w.write([]string{
+		StatementRecordTransaction,
+		formatStatementTime(entry.Time),
+		strconv.FormatUint(entry.TransactionID, 10),
+		w.symbol,
+		entry.FundType,
+		entry.Direction,
+		w.amount(entry.Amount),
+		w.amount(entry.Fee),
+		w.amount(entry.Balance),
+		counterpartyID,
+		entry.CounterpartyName,
+		entry.Reference,
+		entry.Memo,
+	})
+}
+
+func (w *csvStatementWriter) WriteClosing(at time.Time, balance decimal.Decimal) error {
+	if err := w.write([]string{StatementRecordClosing, formatStatementTime(at), "", w.symbol, "", "", "", "", w.amount(balance), "", "", "", ""}); err != nil {
+		return err
+	}
+	w.writer.Flush()
+	if err := w.writer.Error(); err != nil {
+		return gerror.Wrap(err, "写入对账单失败")
+	}
+	return nil
+}
+
+// statementLine JSON Lines 对账单行
+type statementLine struct {
+	Type             string `json:"type"`
+	Time             string `json:"time"`
+	TransactionID    uint64 `json:"transaction_id,omitempty"`
+	Token            string `json:"token"`
+	FundType         string `json:"fund_type,omitempty"`
+	Direction        string `json:"direction,omitempty"`
+	Amount           string `json:"amount,omitempty"`
+	Fee              string `json:"fee,omitempty"`
+	Balance          string `json:"balance"`
+	CounterpartyID   uint64 `json:"counterparty_id,omitempty"`
+	CounterpartyName string `json:"counterparty,omitempty"`
+	Reference        string `json:"reference,omitempty"`
+	Memo             string `json:"memo,omitempty"`
+}
+
+type jsonlStatementWriter struct {
+	statementFormatter
+	encoder *json.Encoder
+}
+
+func (w *jsonlStatementWriter) write(line *statementLine) error {
+	if err := w.encoder.Encode(line); err != nil {
+		return gerror.Wrap(err, "写入对账单失败")
+	}
+	return nil
+}
+
+func (w *jsonlStatementWriter) WriteOpening(at time.Time, balance decimal.Decimal) error {
+	return w.write(&statementLine{Type: StatementRecordOpening, Time: formatStatementTime(at), Token: w.symbol, Balance: w.amount(balance)})
+}
+
+func (w *jsonlStatementWriter) WriteEntry(entry *StatementEntry) error {
+	return w.write(&statementLine{
+		Type:             StatementRecordTransaction,
+		Time:             formatStatementTime(entry.Time),
+		TransactionID:    entry.TransactionID,
+		Token:            w.symbol,
+		FundType:         entry.FundType,
+		Direction:        entry.Direction,
+		Amount:           w.amount(entry.Amount),
+		Fee:              w.amount(entry.Fee),
+		Balance:          w.amount(entry.Balance),
+		CounterpartyID:   entry.CounterpartyID,
+		CounterpartyName: entry.CounterpartyName,
+		Reference:        entry.Reference,
+		Memo:             entry.Memo,
+	})
+}
+
+func (w *jsonlStatementWriter) WriteClosing(at time.Time, balance decimal.Decimal) error {
+	return w.write(&statementLine{Type: StatementRecordClosing, Time: formatStatementTime(at), Token: w.symbol, Balance: w.amount(balance)})
+}
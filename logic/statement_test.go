@@ -0,0 +1,107 @@
+package logic
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestLocaleNumberFormat(t *testing.T) {
+	value := decimal.RequireFromString("-1234567.891")
+	tests := []struct {
+		language string
+		expected string
+	}{
+		{"", "-1,234,567.89"},
+		{"zh-CN", "-1,234,567.89"},
+		{"de", "-1.234.567,89"},
+		{"pt_BR", "-1.234.567,89"},
+		{"fr", "-1\u00a0234\u00a0567,89"},
+		{"de-CH", "-1'234'567.89"},
+	}
+	for _, tt := range tests {
+		if got := LocaleNumberFormat(tt.language).Format(value, 2); got != tt.expected {
+			t.Errorf("language %q: expected %s, got %s", tt.language, tt.expected, got)
+		}
+	}
+
+	if got := LocaleNumberFormat("en").Format(decimal.RequireFromString("999"), 0); got != "999" {
+		t.Errorf("expected 999, got %s", got)
+	}
+}
+
+func writeTestStatement(t *testing.T, format StatementFormat, language string) string {
+	var buf bytes.Buffer
+	writer, err := NewStatementWriter(format, &buf, "USDT", LocaleNumberFormat(language), 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := writer.WriteOpening(at, decimal.RequireFromString("1000")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := writer.WriteEntry(&StatementEntry{
+		TransactionID:    42,
+		Time:             at.Add(time.Hour),
+		FundType:         "transfer_out",
+		Direction:        "out",
+		Amount:           decimal.RequireFromString("250.5"),
+		Fee:              decimal.RequireFromString("1"),
+		Balance:          decimal.RequireFromString("749.5"),
+		CounterpartyID:   7,
+		CounterpartyName: "bob",
+		Memo:             "rent, january",
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := writer.WriteClosing(at.Add(24*time.Hour), decimal.RequireFromString("749.5")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return buf.String()
+}
+
+func TestCSVStatementWriter(t *testing.T) {
+	records, err := csv.NewReader(strings.NewReader(writeTestStatement(t, StatementFormatCSV, "de"))).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid csv: %v", err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("expected header and 3 rows, got %d", len(records))
+	}
+	if records[1][0] != StatementRecordOpening || records[1][8] != "1.000,00" {
+		t.Errorf("unexpected opening row: %v", records[1])
+	}
+	if records[2][6] != "250,50" || records[2][8] != "749,50" || records[2][9] != "7" || records[2][12] != "rent, january" {
+		t.Errorf("unexpected transaction row: %v", records[2])
+	}
+	if records[3][0] != StatementRecordClosing || records[3][8] != "749,50" {
+		t.Errorf("unexpected closing row: %v", records[3])
+	}
+}
+
+func TestJSONLStatementWriter(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(writeTestStatement(t, StatementFormatJSONL, "en")), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d", len(lines))
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatalf("invalid line: %v", err)
+	}
+	if entry["type"] != StatementRecordTransaction || entry["balance"] != "749.50" || entry["counterparty"] != "bob" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+}
+
+func TestNewStatementWriterUnsupportedFormat(t *testing.T) {
+	if _, err := NewStatementWriter("xml", &bytes.Buffer{}, "USDT", LocaleNumberFormat(""), 2); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
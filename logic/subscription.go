@@ -0,0 +1,140 @@
+package logic
+
+import (
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+// 余额与交易订阅默认配置
+const (
+	DefaultSubscriptionPollInterval = 500 * time.Millisecond
+	DefaultSubscriptionGapTimeout   = 5 * time.Second
+	DefaultSubscriptionBatchSize    = 500
+	DefaultSubscriptionBufferSize   = 256
+	DefaultSubscriptionMaxReplay    = 1000
+)
+
+// SubscriptionConfig 余额与交易订阅配置（对应配置项 wallet.subscriptions）
+type SubscriptionConfig struct {
+	PollInterval time.Duration // 扫描领域事件表的间隔；本进程内 RunInTransaction 提交后会立即扫描
+	GapTimeout   time.Duration // 事件ID空洞（事务未提交或已回滚）的最长等待时间，超时后跳过
+	BatchSize    int           // 每次扫描的最大事件数
+	BufferSize   int           // 每个订阅的缓冲事件数，消费跟不上时订阅被关闭
+	MaxReplay    int           // 断线重连时最多补发的事件数
+}
+
+// ParseSubscriptionConfig 解析订阅配置，未配置的项使用默认值
+func ParseSubscriptionConfig(raw map[string]interface{}) (SubscriptionConfig, error) {
+	config := SubscriptionConfig{
+		PollInterval: DefaultSubscriptionPollInterval,
+		GapTimeout:   DefaultSubscriptionGapTimeout,
+		BatchSize:    DefaultSubscriptionBatchSize,
+		BufferSize:   DefaultSubscriptionBufferSize,
+		MaxReplay:    DefaultSubscriptionMaxReplay,
+	}
+	durations := []struct {
+		key    string
+		target *time.Duration
+	}{
+		{"pollInterval", &config.PollInterval},
+		{"gapTimeout", &config.GapTimeout},
+	}
+	for _, item := range durations {
+		if value := gconv.String(raw[item.key]); value != "" {
+			duration, err := gtime.ParseDuration(value)
+			if err != nil || duration <= 0 {
+				return config, gerror.Newf("订阅配置 %s 无效: %s", item.key, value)
+			}
+			*item.target = duration
+		}
+	}
+	sizes := []struct {
+		key    string
+		target *int
+	}{
+		{"batchSize", &config.BatchSize},
+		{"bufferSize", &config.BufferSize},
+		{"maxReplay", &config.MaxReplay},
+	}
+	for _, item := range sizes {
+		if value, ok := raw[item.key]; ok {
+			n := gconv.Int(value)
+			if n <= 0 {
+				return config, gerror.Newf("订阅配置 %s 必须大于0: %v", item.key, value)
+			}
+			*item.target = n
+		}
+	}
+	return config, nil
+}
+
+// EventSequencer 按全局事件ID顺序放行已提交的领域事件。
+// 事件ID在插入时分配，较小ID的事务可能晚于较大ID的事务提交；遇到ID空洞时等待空洞被填上，
+// 超过等待时间（事务已回滚或长时间未提交）后跳过，因此放行的事件ID严格递增，
+// 订阅方可以用最后收到的事件ID断线续传
+type EventSequencer struct {
+	position int64                  // 该ID及之前的事件都已放行或跳过
+	maxSeen  int64                  // 已加入的最大事件ID
+	pending  map[int64]*DomainEvent // position 之后已加入、等待放行的事件
+	holes    map[int64]time.Time    // position 之后尚未出现的ID及首次发现时间
+}
+
+// NewEventSequencer 创建事件排序器，从 position 之后的事件开始放行
+func NewEventSequencer(position int64) *EventSequencer {
+	return &EventSequencer{
+		position: position,
+		maxSeen:  position,
+		pending:  make(map[int64]*DomainEvent),
+		holes:    make(map[int64]time.Time),
+	}
+}
+
+// Position 已放行（或跳过）的最后事件ID
+func (s *EventSequencer) Position() int64 {
+	return s.position
+}
+
+// Add 加入扫描到的事件；已放行、已跳过或重复加入的事件被忽略
+func (s *EventSequencer) Add(event *DomainEvent) {
+	if event.ID <= s.position {
+		return
+	}
+	if _, ok := s.pending[event.ID]; ok {
+		return
+	}
+	s.pending[event.ID] = event
+	delete(s.holes, event.ID)
+	if event.ID > s.maxSeen {
+		s.maxSeen = event.ID
+	}
+}
+
+// Ready 按ID顺序返回可以放行的事件：从 position 开始连续的事件，等待超过 gapTimeout 的空洞被跳过
+func (s *EventSequencer) Ready(now time.Time, gapTimeout time.Duration) []*DomainEvent {
+	for id := s.position + 1; id <= s.maxSeen; id++ {
+		if _, ok := s.pending[id]; ok {
+			continue
+		}
+		if _, ok := s.holes[id]; !ok {
+			s.holes[id] = now
+		}
+	}
+
+	var ready []*DomainEvent
+	for s.position < s.maxSeen {
+		next := s.position + 1
+		if event, ok := s.pending[next]; ok {
+			ready = append(ready, event)
+			delete(s.pending, next)
+		} else if now.Sub(s.holes[next]) >= gapTimeout {
+			delete(s.holes, next)
+		} else {
+			break
+		}
+		s.position = next
+	}
+	return ready
+}
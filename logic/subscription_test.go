@@ -0,0 +1,98 @@
+package logic
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSubscriptionConfig(t *testing.T) {
+	config, err := ParseSubscriptionConfig(nil)
+	if err != nil || config.PollInterval != DefaultSubscriptionPollInterval || config.GapTimeout != DefaultSubscriptionGapTimeout ||
+		config.BatchSize != DefaultSubscriptionBatchSize || config.BufferSize != DefaultSubscriptionBufferSize || config.MaxReplay != DefaultSubscriptionMaxReplay {
+		t.Fatalf("defaults = %+v, %v", config, err)
+	}
+	config, err = ParseSubscriptionConfig(map[string]interface{}{
+		"pollInterval": "200ms",
+		"gapTimeout":   "2s",
+		"batchSize":    100,
+		"bufferSize":   "32",
+		"maxReplay":    50,
+	})
+	if err != nil || config.PollInterval != 200*time.Millisecond || config.GapTimeout != 2*time.Second ||
+		config.BatchSize != 100 || config.BufferSize != 32 || config.MaxReplay != 50 {
+		t.Fatalf("config = %+v, %v", config, err)
+	}
+	for _, raw := range []map[string]interface{}{
+		{"pollInterval": "fast"},
+		{"gapTimeout": "0s"},
+		{"batchSize": 0},
+		{"bufferSize": -1},
+		{"maxReplay": "none"},
+	} {
+		if _, err := ParseSubscriptionConfig(raw); err == nil {
+			t.Errorf("config %v accepted", raw)
+		}
+	}
+}
+
+// eventIDs 返回事件ID列表
+func eventIDs(events []*DomainEvent) []int64 {
+	ids := make([]int64, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func sameIDs(got []int64, want ...int64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestEventSequencer(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	s := NewEventSequencer(10)
+
+	// 连续的事件立即放行，position 之前和重复的事件被忽略
+	for _, id := range []int64{9, 11, 12, 12} {
+		s.Add(&DomainEvent{ID: id})
+	}
+	if got := eventIDs(s.Ready(now, 5*time.Second)); !sameIDs(got, 11, 12) || s.Position() != 12 {
+		t.Fatalf("ready = %v, position = %d", got, s.Position())
+	}
+
+	// 13 尚未提交：14、15 等待
+	s.Add(&DomainEvent{ID: 15})
+	s.Add(&DomainEvent{ID: 14})
+	if got := eventIDs(s.Ready(now, 5*time.Second)); len(got) != 0 || s.Position() != 12 {
+		t.Fatalf("ready with hole = %v, position = %d", got, s.Position())
+	}
+
+	// 13 提交后按顺序放行
+	s.Add(&DomainEvent{ID: 13})
+	if got := eventIDs(s.Ready(now.Add(time.Second), 5*time.Second)); !sameIDs(got, 13, 14, 15) || s.Position() != 15 {
+		t.Fatalf("ready after fill = %v, position = %d", got, s.Position())
+	}
+
+	// 16、17 一直没有出现（已回滚）：超时后跳过
+	s.Add(&DomainEvent{ID: 18})
+	if got := eventIDs(s.Ready(now.Add(2*time.Second), 5*time.Second)); len(got) != 0 {
+		t.Fatalf("ready before timeout = %v", got)
+	}
+	if got := eventIDs(s.Ready(now.Add(7*time.Second), 5*time.Second)); !sameIDs(got, 18) || s.Position() != 18 {
+		t.Fatalf("ready after timeout = %v, position = %d", got, s.Position())
+	}
+
+	// 跳过后才提交的事件不再放行，保证放行的ID严格递增
+	s.Add(&DomainEvent{ID: 16})
+	if got := eventIDs(s.Ready(now.Add(8*time.Second), 5*time.Second)); len(got) != 0 {
+		t.Errorf("late event released: %v", got)
+	}
+}
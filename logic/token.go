@@ -3,6 +3,7 @@ package logic
 import (
 	"context"
 
+	"github.com/gogf/gf/v2/errors/gcode"
 	"github.com/gogf/gf/v2/errors/gerror"
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/shopspring/decimal"
@@ -61,7 +62,7 @@ func (l *tokenLogic) GetTokenBySymbol(ctx context.Context, symbol string) (*enti
 		return nil, err
 	}
 	if token == nil {
-		return nil, gerror.Newf("代币不存在: Symbol=%s", symbol)
+		return nil, gerror.NewCodef(gcode.CodeNotFound, "代币不存在: Symbol=%s", symbol)
 	}
 	// Removed duplicate log block, one is sufficient.
 	if token != nil { // 确保 token 不是 nil 才打印
@@ -78,7 +79,7 @@ func (l *tokenLogic) GetTokenByID(ctx context.Context, tokenID uint) (*entity.To
 		return nil, err
 	}
 	if token == nil {
-		return nil, gerror.Newf("代币不存在: TokenID=%d", tokenID)
+		return nil, gerror.NewCodef(gcode.CodeNotFound, "代币不存在: TokenID=%d", tokenID)
 	}
 	return token, nil
 }
@@ -0,0 +1,60 @@
+package logic
+
+import (
+	"encoding/base64"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+)
+
+// transactionCursorLayout 游标中创建时间的格式（与 created_at 列精度一致）
+const transactionCursorLayout = "2006-01-02 15:04:05"
+
+// metadataKeyPattern 可用于查询的元数据键，限制字符以保证 JSON 路径安全
+var metadataKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_]{1,64}$`)
+
+// TransactionCursor 交易查询游标，指向上一页最后一条记录的 (created_at, transaction_id)
+type TransactionCursor struct {
+	CreatedAt     time.Time
+	TransactionID uint64
+}
+
+// EncodeTransactionCursor 将游标编码为不透明字符串
+func EncodeTransactionCursor(cursor TransactionCursor) string {
+	raw := cursor.CreatedAt.Format(transactionCursorLayout) + "|" + strconv.FormatUint(cursor.TransactionID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeTransactionCursor 解析 EncodeTransactionCursor 生成的游标
+func DecodeTransactionCursor(value string) (TransactionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return TransactionCursor{}, gerror.Newf("无效的分页游标: %s", value)
+	}
+
+	createdAt, id, found := strings.Cut(string(raw), "|")
+	if !found {
+		return TransactionCursor{}, gerror.Newf("无效的分页游标: %s", value)
+	}
+	t, err := time.ParseInLocation(transactionCursorLayout, createdAt, time.Local)
+	if err != nil {
+		return TransactionCursor{}, gerror.Newf("无效的分页游标: %s", value)
+	}
+	transactionID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return TransactionCursor{}, gerror.Newf("无效的分页游标: %s", value)
+	}
+
+	return TransactionCursor{CreatedAt: t, TransactionID: transactionID}, nil
+}
+
+// ValidateMetadataKey 校验用于查询的元数据键（字母、数字、下划线，最长64个字符）
+func ValidateMetadataKey(key string) error {
+	if !metadataKeyPattern.MatchString(key) {
+		return gerror.Newf("无效的元数据键: %q", key)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package logic
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTransactionCursorRoundTrip(t *testing.T) {
+	cursor := TransactionCursor{
+		CreatedAt:     time.Date(2024, 3, 1, 12, 30, 45, 0, time.Local),
+		TransactionID: 98765,
+	}
+
+	decoded, err := DecodeTransactionCursor(EncodeTransactionCursor(cursor))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !decoded.CreatedAt.Equal(cursor.CreatedAt) || decoded.TransactionID != cursor.TransactionID {
+		t.Errorf("expected %+v, got %+v", cursor, decoded)
+	}
+}
+
+func TestDecodeTransactionCursorInvalid(t *testing.T) {
+	for _, value := range []string{"", "!!!", "bm8tc2VwYXJhdG9y", "MjAyNC0wMy0wMXxhYmM"} {
+		if _, err := DecodeTransactionCursor(value); err == nil {
+			t.Errorf("expected error for %q", value)
+		}
+	}
+}
+
+func TestValidateMetadataKey(t *testing.T) {
+	for _, key := range []string{"address", "order_id", "Chain2"} {
+		if err := ValidateMetadataKey(key); err != nil {
+			t.Errorf("expected %q to be valid: %v", key, err)
+		}
+	}
+	for _, key := range []string{"", "a.b", "a'b", "$.x", "key with space"} {
+		if err := ValidateMetadataKey(key); err == nil {
+			t.Errorf("expected %q to be invalid", key)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package logic
 import (
 	"context"
 
+	"github.com/gogf/gf/v2/errors/gcode"
 	"github.com/gogf/gf/v2/errors/gerror"
 
 	"github.com/yalks/wallet/entity"
@@ -38,7 +39,7 @@ func (l *userLogic) GetUserByID(ctx context.Context, userID uint64) (*entity.Use
 		return nil, err
 	}
 	if user == nil {
-		return nil, gerror.Newf("用户不存在: UserID=%d", userID)
+		return nil, gerror.NewCodef(gcode.CodeNotFound, "用户不存在: UserID=%d", userID)
 	}
 	return user, nil
 }
@@ -50,7 +51,7 @@ func (l *userLogic) GetUserByTelegramID(ctx context.Context, telegramID int64) (
 		return nil, err
 	}
 	if user == nil {
-		return nil, gerror.Newf("用户不存在: TelegramID=%d", telegramID)
+		return nil, gerror.NewCodef(gcode.CodeNotFound, "用户不存在: TelegramID=%d", telegramID)
 	}
 	return user, nil
 }
@@ -62,7 +63,7 @@ func (l *userLogic) GetUserByUsername(ctx context.Context, username string) (*en
 		return nil, err
 	}
 	if user == nil {
-		return nil, gerror.Newf("用户不存在: Username=%s", username)
+		return nil, gerror.NewCodef(gcode.CodeNotFound, "用户不存在: Username=%s", username)
 	}
 	return user, nil
 }
@@ -2,6 +2,7 @@ package logic
 
 import (
 	"encoding/json"
+	"fmt"
 	"net"
 	"regexp"
 	"strings"
@@ -104,4 +105,31 @@ func (v *TransactionValidator) GetAllowedSources() []string {
 		sources = append(sources, source)
 	}
 	return sources
-}
\ No newline at end of file
+}
+
+// Tag limits for transaction tags
+const (
+	MaxTransactionTags      = 20 // maximum number of tags per transaction
+	MaxTransactionTagLength = 50 // maximum length of a single tag
+)
+
+// NormalizeTags trims, de-duplicates and validates transaction tags, keeping their order
+func (v *TransactionValidator) NormalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len(tag) > MaxTransactionTagLength {
+			return nil, fmt.Errorf("tag '%s' exceeds %d characters", tag, MaxTransactionTagLength)
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > MaxTransactionTags {
+		return nil, fmt.Errorf("at most %d tags are allowed", MaxTransactionTags)
+	}
+	return normalized, nil
+}
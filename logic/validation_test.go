@@ -0,0 +1,30 @@
+package logic
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	v := NewTransactionValidator()
+
+	tags, err := v.NormalizeTags([]string{" vip ", "campaign", "", "vip"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(tags, ",") != "vip,campaign" {
+		t.Errorf("unexpected tags: %v", tags)
+	}
+
+	if _, err := v.NormalizeTags([]string{strings.Repeat("x", MaxTransactionTagLength+1)}); err == nil {
+		t.Error("expected error for long tag")
+	}
+
+	many := make([]string, 0, MaxTransactionTags+1)
+	for i := 0; i <= MaxTransactionTags; i++ {
+		many = append(many, strings.Repeat("t", i+1))
+	}
+	if _, err := v.NormalizeTags(many); err == nil {
+		t.Error("expected error for too many tags")
+	}
+}
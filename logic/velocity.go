@@ -0,0 +1,537 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/constants"
+	"github.com/yalks/wallet/dao"
+	"github.com/yalks/wallet/entity"
+)
+
+// VelocityBucketSize 限额计数器的分钟桶大小；滚动窗口按整桶计算，最多多统计一个桶
+const VelocityBucketSize = time.Minute
+
+// VelocityRule 限额规则：在滚动窗口内限制每个用户每个代币的交易笔数和金额合计
+type VelocityRule struct {
+	Name          string               `json:"name"`          // 规则名称，出现在错误信息中
+	FundTypes     []constants.FundType `json:"fundTypes"`     // 适用的资金类型
+	Category      string               `json:"category"`      // 适用的资金类型分类，与 FundTypes 合并
+	Symbol        string               `json:"symbol"`        // 代币符号，为空时每个代币分别计算
+	RequestSource string               `json:"requestSource"` // 请求来源，为空时不区分来源
+	Window        time.Duration        `json:"window"`        // 滚动窗口长度，至少 1 分钟
+	MaxCount      int                  `json:"maxCount"`      // 窗口内最多交易笔数，0 表示不限
+	MaxAmount     decimal.Decimal      `json:"maxAmount"`     // 窗口内最多金额合计，0 表示不限
+}
+
+// VelocityConfig 限额配置（对应配置项 wallet.limits）
+type VelocityConfig struct {
+	Enabled bool           `json:"enabled"` // 是否启用限额
+	Rules   []VelocityRule `json:"rules"`   // 限额规则
+}
+
+// VelocityOperation 需要计入限额的一笔交易
+type VelocityOperation struct {
+	UserID        uint64             // 用户ID
+	TokenSymbol   string             // 代币符号
+	FundType      constants.FundType // 资金类型
+	RequestSource string             // 请求来源
+	Amount        decimal.Decimal    // 交易金额
+}
+
+// VelocityUsage 用户在某条限额规则下的当前用量
+type VelocityUsage struct {
+	Rule      string          `json:"rule"`
+	Window    time.Duration   `json:"window"`
+	Count     int             `json:"count"`
+	MaxCount  int             `json:"maxCount"`
+	Amount    decimal.Decimal `json:"amount"`
+	MaxAmount decimal.Decimal `json:"maxAmount"`
+	ResetAt   *time.Time      `json:"resetAt,omitempty"` // 最早一笔计入的交易移出窗口的时间，无用量时为空
+}
+
+// VelocityLimitError 超出限额错误，携带限额、当前用量和重置时间
+type VelocityLimitError struct {
+	Rule          string          // 规则名称
+	Window        time.Duration   // 滚动窗口长度
+	MaxCount      int             // 窗口内最多交易笔数
+	MaxAmount     decimal.Decimal // 窗口内最多金额合计
+	CurrentCount  int             // 窗口内已有交易笔数（不含本次）
+	CurrentAmount decimal.Decimal // 窗口内已有金额合计（不含本次）
+	Amount        decimal.Decimal // 本次交易金额
+	ResetAt       *time.Time      // 本次交易最早可以通过的时间；为空表示单笔金额已超过限额
+}
+
+// Error 实现 error 接口
+func (e *VelocityLimitError) Error() string {
+	var msg string
+	if e.MaxCount > 0 && e.CurrentCount+1 > e.MaxCount {
+		msg = fmt.Sprintf("超出限额 %s: %s 内最多 %d 笔，已有 %d 笔", e.Rule, e.Window, e.MaxCount, e.CurrentCount)
+	} else {
+		msg = fmt.Sprintf("超出限额 %s: %s 内累计金额最多 %s，已累计 %s，本次 %s",
+			e.Rule, e.Window, e.MaxAmount.String(), e.CurrentAmount.String(), e.Amount.String())
+	}
+	if e.ResetAt == nil {
+		return msg + "，单笔金额超过限额，等待重置也无法通过"
+	}
+	return msg + fmt.Sprintf("，将于 %s 重置", e.ResetAt.Format(time.RFC3339))
+}
+
+// Code 返回错误码，便于通过 gerror.Code(err) 识别
+func (e *VelocityLimitError) Code() gcode.Code {
+	return CodeVelocityLimit
+}
+
+// ParseVelocityConfig 解析限额配置并校验规则
+func ParseVelocityConfig(raw map[string]interface{}) (VelocityConfig, error) {
+	config := VelocityConfig{Enabled: gconv.Bool(raw["enabled"])}
+
+	for i, item := range gconv.Maps(raw["rules"]) {
+		rule := VelocityRule{
+			Name:          gconv.String(item["name"]),
+			Category:      gconv.String(item["category"]),
+			Symbol:        gconv.String(item["symbol"]),
+			RequestSource: strings.ToLower(strings.TrimSpace(gconv.String(item["requestSource"]))),
+			MaxCount:      gconv.Int(item["maxCount"]),
+		}
+		for _, fundType := range gconv.Strings(item["fundTypes"]) {
+			rule.FundTypes = append(rule.FundTypes, constants.FundType(fundType))
+		}
+		if window := gconv.String(item["window"]); window != "" {
+			duration, err := gtime.ParseDuration(window)
+			if err != nil {
+				return config, gerror.Wrapf(err, "限额规则 %d 的窗口无效: %s", i, window)
+			}
+			rule.Window = duration
+		}
+		if raw := gconv.String(item["maxAmount"]); raw != "" {
+			amount, err := decimal.NewFromString(raw)
+			if err != nil {
+				return config, gerror.Wrapf(err, "限额规则 %d 的金额上限无效: %s", i, raw)
+			}
+			rule.MaxAmount = amount
+		}
+		config.Rules = append(config.Rules, rule)
+	}
+
+	return config, ValidateVelocityRules(config.Rules)
+}
+
+// ValidateVelocityRules 校验限额规则
+func ValidateVelocityRules(rules []VelocityRule) error {
+	names := make(map[string]bool)
+	for i, rule := range rules {
+		if rule.Name == "" {
+			return gerror.Newf("限额规则 %d 缺少名称", i)
+		}
+		if names[rule.Name] {
+			return gerror.Newf("限额规则名称重复: %s", rule.Name)
+		}
+		names[rule.Name] = true
+
+		if len(rule.FundTypes) == 0 && rule.Category == "" {
+			return gerror.Newf("限额规则 %s 必须指定资金类型或分类", rule.Name)
+		}
+		for _, fundType := range rule.FundTypes {
+			if !constants.IsValidFundType(fundType) {
+				return gerror.Newf("限额规则 %s 的资金类型无效: %s", rule.Name, fundType)
+			}
+		}
+		if rule.Category != "" && len(constants.GetFundTypesByCategory(rule.Category)) == 0 {
+			return gerror.Newf("限额规则 %s 的资金类型分类不存在: %s", rule.Name, rule.Category)
+		}
+		if rule.Window < VelocityBucketSize {
+			return gerror.Newf("限额规则 %s 的窗口不能小于 %s", rule.Name, VelocityBucketSize)
+		}
+		if rule.MaxCount < 0 || rule.MaxAmount.IsNegative() {
+			return gerror.Newf("限额规则 %s 的上限不能为负数", rule.Name)
+		}
+		if rule.MaxCount == 0 && rule.MaxAmount.IsZero() {
+			return gerror.Newf("限额规则 %s 至少需要设置笔数或金额上限", rule.Name)
+		}
+	}
+	return nil
+}
+
+// Matches 检查交易是否适用该规则
+func (r *VelocityRule) Matches(op *VelocityOperation) bool {
+	if r.Symbol != "" && !strings.EqualFold(r.Symbol, op.TokenSymbol) {
+		return false
+	}
+	if r.RequestSource != "" && r.RequestSource != strings.ToLower(op.RequestSource) {
+		return false
+	}
+	for _, fundType := range r.FundTypes {
+		if fundType == op.FundType {
+			return true
+		}
+	}
+	if r.Category == "" {
+		return false
+	}
+	info, ok := constants.GetFundTypeInfo(op.FundType)
+	return ok && info.Category == r.Category
+}
+
+// CountedFundTypes 返回规则统计的全部资金类型（FundTypes 与分类下资金类型的并集，已排序）
+func (r *VelocityRule) CountedFundTypes() []string {
+	seen := make(map[string]bool)
+	for _, fundType := range r.FundTypes {
+		seen[string(fundType)] = true
+	}
+	if r.Category != "" {
+		for _, fundType := range constants.GetFundTypesByCategory(r.Category) {
+			seen[string(fundType)] = true
+		}
+	}
+	types := make([]string, 0, len(seen))
+	for fundType := range seen {
+		types = append(types, fundType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Exceeded 检查窗口内用量（含本次）是否超过规则上限
+func (r *VelocityRule) Exceeded(count int, amount decimal.Decimal) bool {
+	if r.MaxCount > 0 && count > r.MaxCount {
+		return true
+	}
+	return r.MaxAmount.IsPositive() && amount.GreaterThan(r.MaxAmount)
+}
+
+// VelocityBucketStart 返回时间所在分钟桶的起始时间（Unix 秒）
+func VelocityBucketStart(t time.Time) int64 {
+	size := int64(VelocityBucketSize / time.Second)
+	return t.Unix() / size * size
+}
+
+// VelocityWindowStart 返回窗口内最早计入的分钟桶；该桶结束时间晚于 now-window 即仍在窗口内
+func VelocityWindowStart(now time.Time, window time.Duration) int64 {
+	return VelocityBucketStart(now.Add(-window))
+}
+
+// VelocityBucketExpiry 返回分钟桶整体移出窗口的时间
+func VelocityBucketExpiry(bucketStart int64, window time.Duration) time.Time {
+	return time.Unix(bucketStart, 0).Add(VelocityBucketSize + window)
+}
+
+// VelocityResetAt 计算被拒绝的交易最早可以通过的时间：按时间顺序移出最早的分钟桶，直到已有用量加上本次交易不超过上限。
+// buckets 为窗口内的分钟桶（升序，不含本次交易）；单笔金额已超过上限时返回 nil
+func VelocityResetAt(rule *VelocityRule, buckets []*dao.VelocityBucket, amount decimal.Decimal, now time.Time) *time.Time {
+	if rule.MaxAmount.IsPositive() && amount.GreaterThan(rule.MaxAmount) {
+		return nil
+	}
+	count, total := 0, decimal.Zero
+	for _, bucket := range buckets {
+		count += bucket.TxCount
+		total = total.Add(bucket.Amount)
+	}
+
+	resetAt := now
+	for _, bucket := range buckets {
+		if !rule.Exceeded(count+1, total.Add(amount)) {
+			break
+		}
+		count -= bucket.TxCount
+		total = total.Sub(bucket.Amount)
+		resetAt = VelocityBucketExpiry(bucket.BucketStart, rule.Window)
+	}
+	return &resetAt
+}
+
+// excludeOperation 从分钟桶中扣除本次交易（本次交易已计入当前桶）
+func excludeOperation(buckets []*dao.VelocityBucket, bucketStart int64, amount decimal.Decimal) []*dao.VelocityBucket {
+	result := make([]*dao.VelocityBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		if bucket.BucketStart == bucketStart {
+			bucket = &dao.VelocityBucket{
+				BucketStart: bucket.BucketStart,
+				TxCount:     bucket.TxCount - 1,
+				Amount:      bucket.Amount.Sub(amount),
+			}
+			if bucket.TxCount <= 0 {
+				continue
+			}
+		}
+		result = append(result, bucket)
+	}
+	return result
+}
+
+// maxVelocityWindow 返回规则中最长的窗口
+func maxVelocityWindow(rules []VelocityRule) time.Duration {
+	var longest time.Duration
+	for _, rule := range rules {
+		if rule.Window > longest {
+			longest = rule.Window
+		}
+	}
+	return longest
+}
+
+// IVelocityLogic 交易限额业务逻辑接口
+type IVelocityLogic interface {
+	// ApplyInTx 在交易所在的事务中计入限额计数器并校验全部适用规则；
+	// 超出限额时返回 *VelocityLimitError，调用方回滚事务即撤销本次计数
+	ApplyInTx(ctx context.Context, tx gdb.TX, op *VelocityOperation) error
+	// GetUsage 获取用户某代币在各条适用规则下的当前用量
+	GetUsage(ctx context.Context, userID uint64, tokenSymbol string) ([]*VelocityUsage, error)
+	// Rebuild 按交易表重建用户某代币在最长窗口内的计数器
+	Rebuild(ctx context.Context, userID uint64, tokenSymbol string) error
+	// Purge 清理已移出所有窗口的计数器，返回删除数量
+	Purge(ctx context.Context) (int64, error)
+}
+
+type velocityLogic struct {
+	context *SharedLogicContext
+}
+
+// NewVelocityLogic 创建交易限额业务逻辑实例
+func NewVelocityLogic() IVelocityLogic {
+	return &velocityLogic{
+		context: GetSharedContext(),
+	}
+}
+
+// loadConfig 读取限额配置（wallet.limits），配置无效时返回错误，避免在限额失效的情况下放行交易
+func (l *velocityLogic) loadConfig(ctx context.Context) (VelocityConfig, error) {
+	value, err := g.Cfg().Get(ctx, "wallet.limits")
+	if err != nil {
+		return VelocityConfig{}, gerror.Wrap(err, "读取限额配置失败")
+	}
+	if value == nil || value.IsEmpty() {
+		return VelocityConfig{}, nil
+	}
+	config, err := ParseVelocityConfig(value.Map())
+	if err != nil {
+		return VelocityConfig{}, gerror.Wrap(err, "限额配置无效")
+	}
+	return config, nil
+}
+
+// ruleFilter 构造规则在当前窗口内的计数器查询条件
+func ruleFilter(rule *VelocityRule, userID uint64, symbol string, now time.Time) *dao.VelocityCounterFilter {
+	return &dao.VelocityCounterFilter{
+		UserID:        userID,
+		Symbol:        symbol,
+		FundTypes:     rule.CountedFundTypes(),
+		RequestSource: rule.RequestSource,
+		FromBucket:    VelocityWindowStart(now, rule.Window),
+	}
+}
+
+// ApplyInTx 在交易所在的事务中计入限额计数器并校验全部适用规则
+func (l *velocityLogic) ApplyInTx(ctx context.Context, tx gdb.TX, op *VelocityOperation) error {
+	config, err := l.loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if !config.Enabled {
+		return nil
+	}
+	return l.applyRulesInTx(ctx, tx, config.Rules, op, time.Now())
+}
+
+// applyRulesInTx 锁定钱包行后累加当前分钟桶，并用锁定读统计每条适用规则的窗口用量
+func (l *velocityLogic) applyRulesInTx(ctx context.Context, tx gdb.TX, rules []VelocityRule, op *VelocityOperation, now time.Time) error {
+	// 先锁定用户该代币的钱包行（调用方已锁定时不会重复等待）：规则可能跨资金类型、分类和请求来源统计，
+	// 只锁计数器行无法串行化这些交易，按 (用户, 代币) 加锁后，锁定读的统计可见先提交的用量
+	if _, err := l.context.GetWalletDAO().LockWalletInTx(ctx, tx, op.UserID, op.TokenSymbol); err != nil {
+		return err
+	}
+
+	// 累加当前分钟桶
+	bucketStart := VelocityBucketStart(now)
+	dao := l.context.GetVelocityDAO()
+	err := dao.AddInTx(ctx, tx, &entity.VelocityCounters{
+		UserId:        op.UserID,
+		Symbol:        op.TokenSymbol,
+		FundType:      string(op.FundType),
+		RequestSource: strings.ToLower(op.RequestSource),
+		BucketStart:   bucketStart,
+		TxCount:       1,
+		Amount:        op.Amount,
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Matches(op) {
+			continue
+		}
+		filter := ruleFilter(rule, op.UserID, op.TokenSymbol, now)
+		count, amount, err := dao.SumInTx(ctx, tx, filter)
+		if err != nil {
+			return err
+		}
+		if !rule.Exceeded(count, amount) {
+			continue
+		}
+
+		buckets, err := dao.ListBucketsInTx(ctx, tx, filter)
+		if err != nil {
+			return err
+		}
+		limitErr := &VelocityLimitError{
+			Rule:          rule.Name,
+			Window:        rule.Window,
+			MaxCount:      rule.MaxCount,
+			MaxAmount:     rule.MaxAmount,
+			CurrentCount:  count - 1,
+			CurrentAmount: amount.Sub(op.Amount),
+			Amount:        op.Amount,
+			ResetAt:       VelocityResetAt(rule, excludeOperation(buckets, bucketStart, op.Amount), op.Amount, now),
+		}
+		g.Log().Infof(ctx, "交易超出限额: UserID=%d, Symbol=%s, FundType=%s, Rule=%s", op.UserID, op.TokenSymbol, op.FundType, rule.Name)
+		return limitErr
+	}
+	return nil
+}
+
+// GetUsage 获取用户某代币在各条适用规则下的当前用量
+func (l *velocityLogic) GetUsage(ctx context.Context, userID uint64, tokenSymbol string) ([]*VelocityUsage, error) {
+	config, err := l.loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !config.Enabled {
+		return nil, nil
+	}
+
+	now := time.Now()
+	var usages []*VelocityUsage
+	for i := range config.Rules {
+		rule := &config.Rules[i]
+		if rule.Symbol != "" && !strings.EqualFold(rule.Symbol, tokenSymbol) {
+			continue
+		}
+		buckets, err := l.context.GetVelocityDAO().ListBucketsInTx(ctx, nil, ruleFilter(rule, userID, tokenSymbol, now))
+		if err != nil {
+			return nil, err
+		}
+
+		usage := &VelocityUsage{
+			Rule:      rule.Name,
+			Window:    rule.Window,
+			MaxCount:  rule.MaxCount,
+			Amount:    decimal.Zero,
+			MaxAmount: rule.MaxAmount,
+		}
+		for _, bucket := range buckets {
+			usage.Count += bucket.TxCount
+			usage.Amount = usage.Amount.Add(bucket.Amount)
+		}
+		if len(buckets) > 0 {
+			resetAt := VelocityBucketExpiry(buckets[0].BucketStart, rule.Window)
+			usage.ResetAt = &resetAt
+		}
+		usages = append(usages, usage)
+	}
+	return usages, nil
+}
+
+// Rebuild 按交易表重建用户某代币在最长窗口内的计数器；交易状态被改为失败等情况下用于校正计数。
+// 只统计成功且类型为已注册资金类型的交易，与 ApplyInTx 计入的范围一致
+func (l *velocityLogic) Rebuild(ctx context.Context, userID uint64, tokenSymbol string) error {
+	config, err := l.loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	window := maxVelocityWindow(config.Rules)
+	if window == 0 {
+		return nil
+	}
+	fromBucket := VelocityWindowStart(time.Now(), window)
+
+	counters, err := l.aggregateTransactions(ctx, userID, tokenSymbol, fromBucket)
+	if err != nil {
+		return err
+	}
+
+	dao := l.context.GetVelocityDAO()
+	err = g.DB().Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		if err := dao.DeleteInTx(ctx, tx, userID, tokenSymbol, fromBucket); err != nil {
+			return err
+		}
+		return dao.InsertInTx(ctx, tx, counters)
+	})
+	if err != nil {
+		return gerror.Wrapf(err, "重建限额计数器失败: UserID=%d, Symbol=%s", userID, tokenSymbol)
+	}
+	g.Log().Infof(ctx, "已重建限额计数器: UserID=%d, Symbol=%s, Buckets=%d", userID, tokenSymbol, len(counters))
+	return nil
+}
+
+// aggregateTransactions 按分钟桶汇总用户自 fromBucket 起的成功交易
+func (l *velocityLogic) aggregateTransactions(ctx context.Context, userID uint64, tokenSymbol string, fromBucket int64) ([]*entity.VelocityCounters, error) {
+	const pageSize = 500
+	filter := &dao.TransactionFilter{
+		UserID:    userID,
+		Symbol:    tokenSymbol,
+		Statuses:  []uint{1},
+		StartTime: gtime.NewFromTimeStamp(fromBucket),
+		Ascending: true,
+	}
+
+	var counters []*entity.VelocityCounters
+	index := make(map[string]*entity.VelocityCounters)
+	for {
+		transactions, err := l.context.GetTransactionDAO().SearchTransactions(ctx, filter, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, transaction := range transactions {
+			if !constants.IsValidFundType(constants.FundType(transaction.Type)) || transaction.CreatedAt == nil {
+				continue
+			}
+			source := strings.ToLower(transaction.RequestSource)
+			bucketStart := VelocityBucketStart(transaction.CreatedAt.Time)
+			key := fmt.Sprintf("%s|%s|%d", transaction.Type, source, bucketStart)
+			counter, ok := index[key]
+			if !ok {
+				counter = &entity.VelocityCounters{
+					UserId:        userID,
+					Symbol:        tokenSymbol,
+					FundType:      transaction.Type,
+					RequestSource: source,
+					BucketStart:   bucketStart,
+					Amount:        decimal.Zero,
+					UpdatedAt:     gtime.Now(),
+				}
+				index[key] = counter
+				counters = append(counters, counter)
+			}
+			counter.TxCount++
+			counter.Amount = counter.Amount.Add(transaction.Amount)
+		}
+		if len(transactions) < pageSize {
+			return counters, nil
+		}
+		last := transactions[len(transactions)-1]
+		filter.AfterCreatedAt = last.CreatedAt
+		filter.AfterID = last.TransactionId
+	}
+}
+
+// Purge 清理已移出所有窗口的计数器，返回删除数量
+func (l *velocityLogic) Purge(ctx context.Context) (int64, error) {
+	config, err := l.loadConfig(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return l.context.GetVelocityDAO().PurgeBefore(ctx, VelocityWindowStart(time.Now(), maxVelocityWindow(config.Rules)))
+}
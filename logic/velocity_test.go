@@ -0,0 +1,292 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/constants"
+	"github.com/yalks/wallet/dao"
+	"github.com/yalks/wallet/entity"
+)
+
+func TestParseVelocityConfig(t *testing.T) {
+	config, err := ParseVelocityConfig(map[string]interface{}{
+		"enabled": true,
+		"rules": []interface{}{
+			map[string]interface{}{
+				"name":      "withdraw_daily",
+				"fundTypes": []interface{}{"withdraw"},
+				"symbol":    "USDT",
+				"window":    "24h",
+				"maxCount":  10,
+				"maxAmount": "50000",
+			},
+			map[string]interface{}{
+				"name":          "transfer_hourly",
+				"category":      "transfer",
+				"requestSource": " Telegram ",
+				"window":        "1h",
+				"maxCount":      "100",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !config.Enabled || len(config.Rules) != 2 {
+		t.Fatalf("unexpected config: %+v", config)
+	}
+	first := config.Rules[0]
+	if first.Window != 24*time.Hour || first.MaxCount != 10 || !first.MaxAmount.Equal(decimal.NewFromInt(50000)) {
+		t.Errorf("unexpected rule: %+v", first)
+	}
+	if second := config.Rules[1]; second.RequestSource != "telegram" || second.MaxCount != 100 {
+		t.Errorf("unexpected rule: %+v", second)
+	}
+
+	invalid := []map[string]interface{}{
+		{"rules": []interface{}{map[string]interface{}{"fundTypes": []interface{}{"withdraw"}, "window": "1h", "maxCount": 1}}},
+		{"rules": []interface{}{map[string]interface{}{"name": "a", "window": "1h", "maxCount": 1}}},
+		{"rules": []interface{}{map[string]interface{}{"name": "a", "fundTypes": []interface{}{"unknown"}, "window": "1h", "maxCount": 1}}},
+		{"rules": []interface{}{map[string]interface{}{"name": "a", "category": "unknown", "window": "1h", "maxCount": 1}}},
+		{"rules": []interface{}{map[string]interface{}{"name": "a", "category": "wallet", "window": "30s", "maxCount": 1}}},
+		{"rules": []interface{}{map[string]interface{}{"name": "a", "category": "wallet", "window": "1h"}}},
+		{"rules": []interface{}{map[string]interface{}{"name": "a", "category": "wallet", "window": "1h", "maxAmount": "-1"}}},
+		{"rules": []interface{}{
+			map[string]interface{}{"name": "a", "category": "wallet", "window": "1h", "maxCount": 1},
+			map[string]interface{}{"name": "a", "category": "wallet", "window": "2h", "maxCount": 1},
+		}},
+	}
+	for i, raw := range invalid {
+		if _, err := ParseVelocityConfig(raw); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+func TestVelocityRule_Matches(t *testing.T) {
+	rule := &VelocityRule{Category: "transfer", Symbol: "USDT", RequestSource: "telegram"}
+	op := &VelocityOperation{TokenSymbol: "usdt", FundType: constants.FundTypeTransferOut, RequestSource: "Telegram"}
+	if !rule.Matches(op) {
+		t.Error("expected category rule to match")
+	}
+	if rule.Matches(&VelocityOperation{TokenSymbol: "USDT", FundType: constants.FundTypeTransferOut, RequestSource: "web"}) {
+		t.Error("source-specific rule must not match other sources")
+	}
+	if rule.Matches(&VelocityOperation{TokenSymbol: "BTC", FundType: constants.FundTypeTransferOut, RequestSource: "telegram"}) {
+		t.Error("symbol-specific rule must not match other tokens")
+	}
+	if rule.Matches(&VelocityOperation{TokenSymbol: "USDT", FundType: constants.FundTypeDeposit, RequestSource: "telegram"}) {
+		t.Error("category rule must not match other categories")
+	}
+
+	byType := &VelocityRule{FundTypes: []constants.FundType{constants.FundTypeWithdraw}}
+	if !byType.Matches(&VelocityOperation{TokenSymbol: "BTC", FundType: constants.FundTypeWithdraw}) {
+		t.Error("expected fund type rule to match any token")
+	}
+}
+
+func TestVelocityRule_CountedFundTypes(t *testing.T) {
+	rule := &VelocityRule{FundTypes: []constants.FundType{constants.FundTypeWithdraw}, Category: "transfer"}
+	got := strings.Join(rule.CountedFundTypes(), ",")
+	want := "transfer_expired,transfer_in,transfer_out,withdraw"
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestVelocityWindow(t *testing.T) {
+	now := time.Unix(1_700_000_130, 0) // 某分钟的第 30 秒（1_700_000_100 为桶起点）
+	if got := VelocityBucketStart(now); got != 1_700_000_100 {
+		t.Errorf("bucket start = %d", got)
+	}
+	start := VelocityWindowStart(now, time.Hour)
+	if start != 1_700_000_100-3600 {
+		t.Errorf("window start = %d", start)
+	}
+	// 最早的桶在其整体移出窗口之前一直计入
+	if expiry := VelocityBucketExpiry(start, time.Hour); !expiry.After(now) {
+		t.Errorf("earliest bucket expired too early: %s", expiry)
+	}
+}
+
+func TestVelocityResetAt(t *testing.T) {
+	now := time.Unix(1_700_003_600, 0)
+	buckets := []*dao.VelocityBucket{
+		{BucketStart: 1_700_000_000, TxCount: 2, Amount: decimal.NewFromInt(300)},
+		{BucketStart: 1_700_001_200, TxCount: 1, Amount: decimal.NewFromInt(500)},
+		{BucketStart: 1_700_003_600, TxCount: 1, Amount: decimal.NewFromInt(100)},
+	}
+
+	countRule := &VelocityRule{Window: time.Hour, MaxCount: 4}
+	got := VelocityResetAt(countRule, buckets, decimal.NewFromInt(1), now)
+	if want := time.Unix(1_700_000_000+60+3600, 0); got == nil || !got.Equal(want) {
+		t.Errorf("count reset = %v, want %s", got, want)
+	}
+
+	amountRule := &VelocityRule{Window: time.Hour, MaxAmount: decimal.NewFromInt(1000)}
+	got = VelocityResetAt(amountRule, buckets, decimal.NewFromInt(600), now)
+	if want := time.Unix(1_700_001_200+60+3600, 0); got == nil || !got.Equal(want) {
+		t.Errorf("amount reset = %v, want %s", got, want)
+	}
+
+	if got := VelocityResetAt(amountRule, buckets, decimal.NewFromInt(1001), now); got != nil {
+		t.Errorf("single amount over limit must never reset, got %v", got)
+	}
+	if got := VelocityResetAt(amountRule, nil, decimal.NewFromInt(10), now); got == nil || !got.Equal(now) {
+		t.Errorf("empty window should pass now, got %v", got)
+	}
+}
+
+func TestExcludeOperation(t *testing.T) {
+	buckets := []*dao.VelocityBucket{
+		{BucketStart: 60, TxCount: 1, Amount: decimal.NewFromInt(5)},
+		{BucketStart: 120, TxCount: 1, Amount: decimal.NewFromInt(7)},
+	}
+	got := excludeOperation(buckets, 120, decimal.NewFromInt(7))
+	if len(got) != 1 || got[0].BucketStart != 60 {
+		t.Fatalf("unexpected buckets: %+v", got)
+	}
+	if buckets[1].TxCount != 1 {
+		t.Error("input buckets must not be modified")
+	}
+}
+
+func TestVelocityLimitError(t *testing.T) {
+	resetAt := time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)
+	err := error(&VelocityLimitError{
+		Rule:         "withdraw_daily",
+		Window:       24 * time.Hour,
+		MaxCount:     10,
+		CurrentCount: 10,
+		Amount:       decimal.NewFromInt(1),
+		ResetAt:      &resetAt,
+	})
+	if !strings.Contains(err.Error(), "withdraw_daily") || !strings.Contains(err.Error(), "2026-01-02T03:04:00Z") {
+		t.Errorf("unexpected message: %s", err)
+	}
+	if gerror.Code(gerror.Wrap(err, "增加资金操作失败")) != CodeVelocityLimit {
+		t.Error("error code lost after wrapping")
+	}
+}
+
+// velocityTestDB 模拟数据库：钱包行锁在事务结束前一直持有，计数器在提交前只对本事务可见
+type velocityTestDB struct {
+	dao.IWalletDAO
+	dao.IVelocityDAO
+
+	walletLock sync.Mutex
+	mu         sync.Mutex
+	committed  []*entity.VelocityCounters
+}
+
+type velocityTestTxKey struct{}
+
+// velocityTestTx 模拟事务中未提交的计数器
+type velocityTestTx struct {
+	pending []*entity.VelocityCounters
+}
+
+func (db *velocityTestDB) LockWalletInTx(ctx context.Context, tx gdb.TX, userID uint64, symbol string) (*entity.Wallets, error) {
+	db.walletLock.Lock()
+	return &entity.Wallets{UserId: uint(userID), Symbol: symbol}, nil
+}
+
+func (db *velocityTestDB) AddInTx(ctx context.Context, tx gdb.TX, counter *entity.VelocityCounters) error {
+	testTx := ctx.Value(velocityTestTxKey{}).(*velocityTestTx)
+	testTx.pending = append(testTx.pending, counter)
+	return nil
+}
+
+func (db *velocityTestDB) SumInTx(ctx context.Context, tx gdb.TX, filter *dao.VelocityCounterFilter) (int, decimal.Decimal, error) {
+	count, amount := 0, decimal.Zero
+	for _, bucket := range db.visible(ctx) {
+		count += bucket.TxCount
+		amount = amount.Add(bucket.Amount)
+	}
+	return count, amount, nil
+}
+
+func (db *velocityTestDB) ListBucketsInTx(ctx context.Context, tx gdb.TX, filter *dao.VelocityCounterFilter) ([]*dao.VelocityBucket, error) {
+	var buckets []*dao.VelocityBucket
+	for _, counter := range db.visible(ctx) {
+		buckets = append(buckets, &dao.VelocityBucket{BucketStart: counter.BucketStart, TxCount: counter.TxCount, Amount: counter.Amount})
+	}
+	return buckets, nil
+}
+
+// visible 返回事务可见的计数器：已提交的加上本事务未提交的
+func (db *velocityTestDB) visible(ctx context.Context) []*entity.VelocityCounters {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	counters := append([]*entity.VelocityCounters{}, db.committed...)
+	return append(counters, ctx.Value(velocityTestTxKey{}).(*velocityTestTx).pending...)
+}
+
+// finish 结束事务：成功时提交计数器，并释放钱包行锁
+func (db *velocityTestDB) finish(testTx *velocityTestTx, commit bool) {
+	if commit {
+		db.mu.Lock()
+		db.committed = append(db.committed, testTx.pending...)
+		db.mu.Unlock()
+	}
+	db.walletLock.Unlock()
+}
+
+func TestApplyRulesInTx_Concurrent(t *testing.T) {
+	db := &velocityTestDB{}
+	velocity := &velocityLogic{context: &SharedLogicContext{walletDAO: db, velocityDAO: db}}
+	rules := []VelocityRule{{Name: "withdraw_hourly", FundTypes: []constants.FundType{constants.FundTypeWithdraw}, Window: time.Hour, MaxCount: 5}}
+	now := time.Date(2024, 5, 1, 12, 0, 30, 0, time.UTC)
+
+	const workers = 20
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		accepted int
+		rejected int
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			testTx := &velocityTestTx{}
+			ctx := context.WithValue(context.Background(), velocityTestTxKey{}, testTx)
+			err := velocity.applyRulesInTx(ctx, nil, rules, &VelocityOperation{
+				UserID:      1,
+				TokenSymbol: "USDT",
+				FundType:    constants.FundTypeWithdraw,
+				Amount:      decimal.NewFromInt(1),
+			}, now)
+			db.finish(testTx, err == nil)
+
+			var limitErr *VelocityLimitError
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				accepted++
+			case errors.As(err, &limitErr):
+				rejected++
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if accepted != 5 || rejected != workers-5 {
+		t.Errorf("expected 5 accepted and %d rejected, got %d and %d", workers-5, accepted, rejected)
+	}
+	if len(db.committed) != 5 {
+		t.Errorf("expected 5 committed counters, got %d", len(db.committed))
+	}
+}
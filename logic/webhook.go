@@ -0,0 +1,142 @@
+package logic
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+)
+
+// Webhook 请求头
+const (
+	WebhookHeaderEvent     = "X-Wallet-Event"     // 事件类型
+	WebhookHeaderDelivery  = "X-Wallet-Delivery"  // 投递ID（同一事件重试时不变，可用于接收方去重）
+	WebhookHeaderTimestamp = "X-Wallet-Timestamp" // 签名时间戳（Unix 秒）
+	WebhookHeaderSignature = "X-Wallet-Signature" // 签名: sha256=<hex>
+)
+
+// maxWebhookResponseBody 记录到错误信息中的响应体最大长度
+const maxWebhookResponseBody = 256
+
+// WebhookDelivery 一次 Webhook 投递
+type WebhookDelivery struct {
+	ID      string // 投递ID
+	Event   string // 事件类型
+	URL     string // 回调地址
+	Method  string // GET 或 POST
+	Payload []byte // JSON 负载
+}
+
+// SignWebhookPayload 计算签名：HMAC-SHA256(secret, "<timestamp>.<payload>")，返回 "sha256=<hex>"
+func SignWebhookPayload(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature 校验签名（供接收方使用），tolerance 为允许的时间偏差，0 表示不校验时间
+func VerifyWebhookSignature(secret string, timestamp int64, payload []byte, signature string, tolerance time.Duration, now time.Time) bool {
+	if tolerance > 0 {
+		skew := now.Sub(time.Unix(timestamp, 0))
+		if skew > tolerance || skew < -tolerance {
+			return false
+		}
+	}
+	expected := SignWebhookPayload(secret, timestamp, payload)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// DeliverWebhook 发送签名后的 Webhook 请求，2xx 视为成功；返回响应状态码（请求未发出时为 0）
+// POST 请求以 JSON 作为请求体；GET 请求将负载放在查询参数 payload 中，签名内容相同
+func DeliverWebhook(ctx context.Context, client *http.Client, secret string, delivery *WebhookDelivery, now time.Time) (int, error) {
+	if secret == "" {
+		return 0, gerror.New("未配置 Webhook 签名密钥")
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	method := delivery.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	var (
+		req *http.Request
+		err error
+	)
+	switch method {
+	case http.MethodPost:
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+	case http.MethodGet:
+		target, parseErr := url.Parse(delivery.URL)
+		if parseErr != nil {
+			return 0, gerror.Wrapf(parseErr, "无效的回调地址: %s", delivery.URL)
+		}
+		query := target.Query()
+		query.Set("payload", string(delivery.Payload))
+		target.RawQuery = query.Encode()
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	default:
+		return 0, gerror.Newf("不支持的回调方法: %s", method)
+	}
+	if err != nil {
+		return 0, gerror.Wrapf(err, "构建回调请求失败: %s", delivery.URL)
+	}
+
+	timestamp := now.Unix()
+	req.Header.Set(WebhookHeaderEvent, delivery.Event)
+	req.Header.Set(WebhookHeaderDelivery, delivery.ID)
+	req.Header.Set(WebhookHeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookHeaderSignature, SignWebhookPayload(secret, timestamp, delivery.Payload))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, gerror.Wrapf(err, "回调请求失败: %s", delivery.URL)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponseBody))
+		return resp.StatusCode, gerror.Newf("回调返回非成功状态: %d %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+// WebhookBackoff 第 attempt 次失败后的重试等待时间：base * 2^(attempt-1)，不超过 max
+func WebhookBackoff(attempt int, base, max time.Duration) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
+
+// WebhookDeliveryID 根据出站记录ID生成投递ID
+func WebhookDeliveryID(outboxID uint64) string {
+	return fmt.Sprintf("whd_%d", outboxID)
+}
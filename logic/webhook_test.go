@@ -0,0 +1,105 @@
+package logic
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWebhookSignature(t *testing.T) {
+	payload := []byte(`{"event":"transaction.completed"}`)
+	now := time.Unix(1700000000, 0)
+	signature := SignWebhookPayload("secret", now.Unix(), payload)
+
+	if !VerifyWebhookSignature("secret", now.Unix(), payload, signature, 5*time.Minute, now) {
+		t.Error("expected signature to verify")
+	}
+	if VerifyWebhookSignature("other", now.Unix(), payload, signature, 5*time.Minute, now) {
+		t.Error("expected signature with wrong secret to fail")
+	}
+	if VerifyWebhookSignature("secret", now.Unix(), []byte(`{}`), signature, 5*time.Minute, now) {
+		t.Error("expected signature for tampered payload to fail")
+	}
+	if VerifyWebhookSignature("secret", now.Unix(), payload, signature, 5*time.Minute, now.Add(10*time.Minute)) {
+		t.Error("expected stale timestamp to fail")
+	}
+}
+
+func TestDeliverWebhook(t *testing.T) {
+	var (
+		gotBody      []byte
+		gotHeaders   http.Header
+		gotPayloadQS string
+		status       = http.StatusOK
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeaders = r.Header.Clone()
+		gotBody, _ = io.ReadAll(r.Body)
+		gotPayloadQS = r.URL.Query().Get("payload")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("boom"))
+	}))
+	defer server.Close()
+
+	payload := []byte(`{"business_id":"abc"}`)
+	now := time.Unix(1700000000, 0)
+	delivery := &WebhookDelivery{ID: "whd_1", Event: "transaction.completed", URL: server.URL, Method: http.MethodPost, Payload: payload}
+
+	code, err := DeliverWebhook(context.Background(), server.Client(), "secret", delivery, now)
+	if err != nil || code != http.StatusOK {
+		t.Fatalf("expected successful delivery, got code=%d err=%v", code, err)
+	}
+	if string(gotBody) != string(payload) {
+		t.Errorf("unexpected body: %s", gotBody)
+	}
+	if gotHeaders.Get(WebhookHeaderEvent) != "transaction.completed" || gotHeaders.Get(WebhookHeaderDelivery) != "whd_1" {
+		t.Errorf("unexpected headers: %v", gotHeaders)
+	}
+	timestamp, _ := strconv.ParseInt(gotHeaders.Get(WebhookHeaderTimestamp), 10, 64)
+	if !VerifyWebhookSignature("secret", timestamp, gotBody, gotHeaders.Get(WebhookHeaderSignature), time.Minute, now) {
+		t.Error("receiver could not verify signature")
+	}
+
+	delivery.Method = http.MethodGet
+	if _, err := DeliverWebhook(context.Background(), server.Client(), "secret", delivery, now); err != nil {
+		t.Fatalf("GET delivery failed: %v", err)
+	}
+	if gotPayloadQS != string(payload) {
+		t.Errorf("unexpected GET payload: %s", gotPayloadQS)
+	}
+
+	status = http.StatusInternalServerError
+	code, err = DeliverWebhook(context.Background(), server.Client(), "secret", delivery, now)
+	if err == nil || code != http.StatusInternalServerError {
+		t.Errorf("expected failure for 500 response, got code=%d err=%v", code, err)
+	}
+
+	if _, err := DeliverWebhook(context.Background(), server.Client(), "", delivery, now); err == nil {
+		t.Error("expected error without secret")
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	base, max := 10*time.Second, 5*time.Minute
+	tests := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{0, 10 * time.Second},
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{3, 40 * time.Second},
+		{5, 160 * time.Second},
+		{6, 5 * time.Minute},
+		{50, 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := WebhookBackoff(tt.attempt, base, max); got != tt.expected {
+			t.Errorf("WebhookBackoff(%d) = %s, want %s", tt.attempt, got, tt.expected)
+		}
+	}
+}
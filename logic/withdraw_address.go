@@ -0,0 +1,226 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+
+	"github.com/yalks/wallet/entity"
+)
+
+// DefaultWithdrawAddressCoolingOff 新增地址的默认冷静期
+const DefaultWithdrawAddressCoolingOff = 24 * time.Hour
+
+// IWithdrawAddressLogic 提现地址簿业务逻辑接口
+type IWithdrawAddressLogic interface {
+	// AddAddress 添加提现地址（按代币所属网络校验格式，并进入冷静期）
+	AddAddress(ctx context.Context, userID uint64, tokenSymbol, address, label string) (*entity.WithdrawAddresses, error)
+	// RemoveAddress 删除提现地址
+	RemoveAddress(ctx context.Context, userID uint64, addressID uint64) error
+	// ListAddresses 获取用户地址簿（network 为空时返回全部网络）
+	ListAddresses(ctx context.Context, userID uint64, network string) ([]*entity.WithdrawAddresses, error)
+	// SetWhitelistOnly 开启或关闭仅白名单提现模式（开启立即生效，关闭在冷静期结束后生效）
+	SetWhitelistOnly(ctx context.Context, userID uint64, enabled bool) error
+	// IsWhitelistOnly 检查用户是否开启了仅白名单提现模式
+	IsWhitelistOnly(ctx context.Context, userID uint64) (bool, error)
+	// ValidateWithdrawDestination 校验提现目标地址是否满足地址簿规则
+	ValidateWithdrawDestination(ctx context.Context, userID uint64, token *entity.Tokens, address string) error
+}
+
+type withdrawAddressLogic struct {
+	tokenLogic ITokenLogic
+	context    *SharedLogicContext
+}
+
+// NewWithdrawAddressLogic 创建提现地址簿业务逻辑实例
+func NewWithdrawAddressLogic() IWithdrawAddressLogic {
+	return &withdrawAddressLogic{
+		tokenLogic: NewTokenLogic(),
+		context:    GetSharedContext(),
+	}
+}
+
+// AddAddress 添加提现地址（按代币所属网络校验格式，并进入冷静期）
+func (l *withdrawAddressLogic) AddAddress(ctx context.Context, userID uint64, tokenSymbol, address, label string) (*entity.WithdrawAddresses, error) {
+	if userID == 0 {
+		return nil, gerror.New("用户ID不能为空")
+	}
+	if len(label) > 64 {
+		return nil, gerror.New("地址备注长度不能超过64个字符")
+	}
+
+	token, err := l.tokenLogic.GetTokenBySymbol(ctx, tokenSymbol)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "获取代币信息失败: Symbol=%s", tokenSymbol)
+	}
+
+	if err := ValidateAddress(token.Network, token.TokenStandard, address); err != nil {
+		return nil, gerror.Wrapf(err, "提现地址格式无效: Network=%s", token.Network)
+	}
+
+	address = NormalizeAddress(token.Network, token.TokenStandard, address)
+	existing, err := l.context.GetWithdrawAddressDAO().GetAddress(ctx, userID, token.Network, address)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	now := gtime.Now()
+	record := &entity.WithdrawAddresses{
+		UserId:        uint(userID),
+		Network:       token.Network,
+		TokenStandard: token.TokenStandard,
+		Address:       address,
+		Label:         label,
+		Status:        1,
+		ActivatedAt:   now.Add(l.coolingOffPeriod(ctx)),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	id, err := l.context.GetWithdrawAddressDAO().CreateAddress(ctx, nil, record)
+	if err != nil {
+		return nil, err
+	}
+	record.Id = id
+
+	g.Log().Infof(ctx, "提现地址添加成功: UserID=%d, Network=%s, AddressID=%d, ActivatedAt=%s",
+		userID, token.Network, id, record.ActivatedAt.String())
+	return record, nil
+}
+
+// RemoveAddress 删除提现地址
+func (l *withdrawAddressLogic) RemoveAddress(ctx context.Context, userID uint64, addressID uint64) error {
+	record, err := l.context.GetWithdrawAddressDAO().GetAddressByID(ctx, userID, addressID)
+	if err != nil {
+		return err
+	}
+	if record == nil {
+		return gerror.Newf("提现地址不存在: UserID=%d, AddressID=%d", userID, addressID)
+	}
+	return l.context.GetWithdrawAddressDAO().DeleteAddress(ctx, nil, userID, addressID)
+}
+
+// ListAddresses 获取用户地址簿（network 为空时返回全部网络）
+func (l *withdrawAddressLogic) ListAddresses(ctx context.Context, userID uint64, network string) ([]*entity.WithdrawAddresses, error) {
+	return l.context.GetWithdrawAddressDAO().ListAddresses(ctx, userID, network)
+}
+
+// SetWhitelistOnly 开启或关闭仅白名单提现模式（开启立即生效，关闭在冷静期结束后生效）
+func (l *withdrawAddressLogic) SetWhitelistOnly(ctx context.Context, userID uint64, enabled bool) error {
+	if userID == 0 {
+		return gerror.New("用户ID不能为空")
+	}
+
+	settings, err := l.context.GetWithdrawAddressDAO().GetSettings(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if settings == nil {
+		settings = &entity.WithdrawAddressSettings{
+			UserId:    uint(userID),
+			CreatedAt: gtime.Now(),
+		}
+	}
+
+	now := gtime.Now()
+	applyWhitelistSetting(settings, enabled, now, l.coolingOffPeriod(ctx))
+	settings.UpdatedAt = now
+
+	if err := l.context.GetWithdrawAddressDAO().SaveSettings(ctx, nil, settings); err != nil {
+		return err
+	}
+
+	if settings.WhitelistDisableAt != nil {
+		g.Log().Infof(ctx, "提现白名单模式将在冷静期结束后关闭: UserID=%d, DisableAt=%s", userID, settings.WhitelistDisableAt.String())
+	} else {
+		g.Log().Infof(ctx, "提现白名单模式已更新: UserID=%d, WhitelistOnly=%t", userID, enabled)
+	}
+	return nil
+}
+
+// IsWhitelistOnly 检查用户是否开启了仅白名单提现模式（待关闭的设置在冷静期内仍然生效）
+func (l *withdrawAddressLogic) IsWhitelistOnly(ctx context.Context, userID uint64) (bool, error) {
+	settings, err := l.context.GetWithdrawAddressDAO().GetSettings(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return whitelistActive(settings, gtime.Now()), nil
+}
+
+// applyWhitelistSetting 更新白名单设置：开启立即生效并撤销待生效的关闭；
+// 关闭已开启的白名单时只记录冷静期结束时间，重复关闭不会推迟已有的生效时间
+func applyWhitelistSetting(settings *entity.WithdrawAddressSettings, enabled bool, now *gtime.Time, coolingOff time.Duration) {
+	switch {
+	case enabled:
+		settings.WhitelistOnly = 1
+		settings.WhitelistDisableAt = nil
+	case !whitelistActive(settings, now), coolingOff <= 0:
+		settings.WhitelistOnly = 0
+		settings.WhitelistDisableAt = nil
+	case settings.WhitelistDisableAt == nil:
+		settings.WhitelistDisableAt = now.Add(coolingOff)
+	}
+}
+
+// whitelistActive 判断白名单模式在指定时间是否生效
+func whitelistActive(settings *entity.WithdrawAddressSettings, now *gtime.Time) bool {
+	if settings == nil || settings.WhitelistOnly != 1 {
+		return false
+	}
+	return settings.WhitelistDisableAt == nil || now.Before(settings.WhitelistDisableAt)
+}
+
+// ValidateWithdrawDestination 校验提现目标地址是否满足地址簿规则
+func (l *withdrawAddressLogic) ValidateWithdrawDestination(ctx context.Context, userID uint64, token *entity.Tokens, address string) error {
+	if token == nil {
+		return gerror.New("代币信息不能为空")
+	}
+
+	// 1. 格式校验
+	if err := ValidateAddress(token.Network, token.TokenStandard, address); err != nil {
+		return gerror.Wrapf(err, "提现地址格式无效: Network=%s", token.Network)
+	}
+
+	// 2. 白名单模式下必须是地址簿中已过冷静期的地址
+	whitelistOnly, err := l.IsWhitelistOnly(ctx, userID)
+	if err != nil {
+		return gerror.Wrap(err, "获取提现白名单设置失败")
+	}
+	if !whitelistOnly {
+		return nil
+	}
+
+	address = NormalizeAddress(token.Network, token.TokenStandard, address)
+	record, err := l.context.GetWithdrawAddressDAO().GetAddress(ctx, userID, token.Network, address)
+	if err != nil {
+		return gerror.Wrap(err, "查询地址簿失败")
+	}
+	if record == nil {
+		return gerror.Newf("已开启白名单提现，目标地址不在地址簿中: Network=%s, Address=%s", token.Network, address)
+	}
+	if record.ActivatedAt != nil && gtime.Now().Before(record.ActivatedAt) {
+		return gerror.Newf("提现地址仍处于冷静期，%s 后可用: Address=%s", record.ActivatedAt.String(), address)
+	}
+
+	return nil
+}
+
+// coolingOffPeriod 读取新增地址冷静期配置（wallet.withdrawAddress.coolingOffPeriod）
+func (l *withdrawAddressLogic) coolingOffPeriod(ctx context.Context) time.Duration {
+	value, err := g.Cfg().Get(ctx, "wallet.withdrawAddress.coolingOffPeriod")
+	if err != nil || value == nil || value.IsEmpty() {
+		return DefaultWithdrawAddressCoolingOff
+	}
+	period, err := time.ParseDuration(value.String())
+	if err != nil || period < 0 {
+		g.Log().Warningf(ctx, "无效的提现地址冷静期配置: %s, 使用默认值 %s", value.String(), DefaultWithdrawAddressCoolingOff)
+		return DefaultWithdrawAddressCoolingOff
+	}
+	return period
+}
@@ -0,0 +1,51 @@
+package logic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/os/gtime"
+
+	"github.com/yalks/wallet/entity"
+)
+
+func TestApplyWhitelistSetting(t *testing.T) {
+	now := gtime.Now()
+	coolingOff := 24 * time.Hour
+	settings := &entity.WithdrawAddressSettings{}
+
+	// 开启立即生效
+	applyWhitelistSetting(settings, true, now, coolingOff)
+	if !whitelistActive(settings, now) {
+		t.Fatal("enabling did not take effect immediately")
+	}
+
+	// 关闭在冷静期结束后才生效
+	applyWhitelistSetting(settings, false, now, coolingOff)
+	if settings.WhitelistDisableAt == nil || !whitelistActive(settings, now) {
+		t.Fatalf("disabling took effect immediately: %+v", settings)
+	}
+	if whitelistActive(settings, now.Add(coolingOff)) {
+		t.Error("whitelist still active after the cooling-off period")
+	}
+
+	// 重复关闭不推迟生效时间
+	disableAt := settings.WhitelistDisableAt
+	applyWhitelistSetting(settings, false, now.Add(time.Hour), coolingOff)
+	if !settings.WhitelistDisableAt.Equal(disableAt) {
+		t.Errorf("repeated disable moved the effective time to %s", settings.WhitelistDisableAt)
+	}
+
+	// 冷静期内重新开启会撤销待生效的关闭
+	applyWhitelistSetting(settings, true, now.Add(time.Hour), coolingOff)
+	if settings.WhitelistDisableAt != nil || !whitelistActive(settings, now.Add(coolingOff)) {
+		t.Errorf("re-enabling did not cancel the pending disable: %+v", settings)
+	}
+
+	// 未开启时关闭直接生效
+	settings = &entity.WithdrawAddressSettings{}
+	applyWhitelistSetting(settings, false, now, coolingOff)
+	if settings.WhitelistOnly != 0 || settings.WhitelistDisableAt != nil {
+		t.Errorf("disabling an inactive whitelist: %+v", settings)
+	}
+}
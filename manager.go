@@ -3,6 +3,7 @@ package wallet
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/gogf/gf/v2/database/gdb"
@@ -22,23 +23,96 @@ type walletManager struct {
 	balanceLogic   logic.IBalanceLogic
 	operationLogic logic.IOperationLogic
 
+	// 提现地址簿
+	withdrawAddressLogic logic.IWithdrawAddressLogic
+	// 领域事件
+	eventLogic logic.IDomainEventLogic
+	// 多级佣金分配
+	commissionLogic logic.ICommissionLogic
+	// 交易限额
+	velocityLogic logic.IVelocityLogic
+	// 策略规则
+	policyLogic logic.IPolicyLogic
+	// API Key 认证
+	apiKeyLogic logic.IAPIKeyLogic
+
 	// 事务管理器
 	transactionManager ITransactionManager
+
+	// 定时操作调度器
+	scheduler *scheduler
+	// 循环操作执行引擎
+	recurring *recurringEngine
+	// Webhook 投递器
+	webhook *webhookDispatcher
+	// 领域事件中继
+	events *eventRelay
+	// 每日余额快照任务
+	snapshots *balanceSnapshotJob
+	// 后台调账审批流程
+	adjustments *adjustmentWorkflow
+	// 审计哈希链校验与检查点
+	audit *auditTrail
+	// 资金操作钩子
+	hooks *hookRegistry
+	// 余额与交易订阅
+	subscriptions *subscriptionHub
 }
 
 // initialize 初始化钱包管理器的各个组件
 func (m *walletManager) initialize(ctx context.Context) error {
 	g.Log().Info(ctx, "初始化钱包管理器组件...")
 
+	// 注册配置文件中的自定义资金类型（需在其他组件使用资金类型之前完成）
+	if err := registerConfiguredFundTypes(ctx); err != nil {
+		return err
+	}
+
+	// 按 wallet.schema 配置执行迁移并检查数据库结构
+	if err := prepareSchema(ctx); err != nil {
+		return err
+	}
+
 	// 初始化各个逻辑组件
 	m.userLogic = logic.NewUserLogic()
 	m.tokenLogic = logic.NewTokenLogic()
 	m.balanceLogic = logic.NewBalanceLogic()
 	m.operationLogic = logic.NewOperationLogic()
+	m.withdrawAddressLogic = logic.NewWithdrawAddressLogic()
+	m.eventLogic = logic.NewDomainEventLogic()
+	m.commissionLogic = logic.NewCommissionLogic()
+	m.velocityLogic = logic.NewVelocityLogic()
+	m.policyLogic = logic.NewPolicyLogic()
+	m.apiKeyLogic = logic.NewAPIKeyLogic()
 
 	// 初始化事务管理器
 	m.transactionManager = NewTransactionManager()
 
+	// 初始化定时操作调度器（需显式调用 StartScheduler 启动后台调度）
+	m.scheduler = newScheduler(ctx, m)
+	m.recurring = newRecurringEngine(ctx, m)
+
+	// 初始化 Webhook 投递器（需显式调用 StartWebhookDispatcher 启动后台投递）
+	m.webhook = newWebhookDispatcher(ctx)
+
+	// 初始化领域事件中继（需设置发布器并调用 StartEventRelay 启动）
+	m.events = newEventRelay(ctx)
+
+	// 初始化每日余额快照任务（需显式调用 StartBalanceSnapshotJob 启动）
+	m.snapshots = newBalanceSnapshotJob(ctx)
+
+	// 初始化审计哈希链检查点（需显式调用 StartAuditCheckpointJob 启动定时检查点）
+	m.audit = newAuditTrail(ctx)
+
+	// 初始化后台调账审批流程
+	m.adjustments = newAdjustmentWorkflow(ctx)
+
+	// 初始化资金操作钩子注册表
+	m.hooks = newHookRegistry()
+
+	// 初始化余额与交易订阅中心（第一个订阅注册时才开始扫描）
+	m.subscriptions = newSubscriptionHub(ctx)
+
 	// 逻辑组件不需要额外的初始化，它们在创建时会自动初始化
 
 	g.Log().Info(ctx, "钱包管理器组件初始化完成")
@@ -51,6 +125,12 @@ func (m *walletManager) CreditFundsInTx(ctx context.Context, tx gdb.TX, req *Fun
 	if err := m.validateRequest(req); err != nil {
 		return nil, err
 	}
+	if err := m.adjustments.authorize(ctx, req.FundType); err != nil {
+		return nil, err
+	}
+	if req.Direction != "" && req.Direction != constants.FundDirectionIn {
+		return nil, gerror.Newf("资金方向 %s 与增加资金操作不一致", req.Direction)
+	}
 
 	// 构建财务操作请求
 	financialReq := &logic.FinancialOperationRequest{
@@ -58,9 +138,11 @@ func (m *walletManager) CreditFundsInTx(ctx context.Context, tx gdb.TX, req *Fun
 		TokenSymbol:   req.TokenSymbol,
 		Amount:        req.Amount,
 		OperationType: logic.OperationTypeCredit, // 增加资金
+		FundType:      string(req.FundType),
 		BusinessID:    req.BusinessID,
 		Description:   req.Description,
 		Metadata:      req.Metadata,
+		RequestSource: req.RequestSource,
 	}
 
 	// 执行财务操作
@@ -81,6 +163,7 @@ func (m *walletManager) CreditFundsInTx(ctx context.Context, tx gdb.TX, req *Fun
 		BalanceAfter:  opResult.BalanceAfter,
 		RawAmount:     rawAmount,
 		Successful:    true,
+		Replayed:      opResult.Replayed,
 	}
 
 	g.Log().Infof(ctx, "资金增加成功: UserID=%d, Symbol=%s, Amount=%s, TransactionID=%s",
@@ -95,6 +178,12 @@ func (m *walletManager) DebitFundsInTx(ctx context.Context, tx gdb.TX, req *Fund
 	if err := m.validateRequest(req); err != nil {
 		return nil, err
 	}
+	if err := m.adjustments.authorize(ctx, req.FundType); err != nil {
+		return nil, err
+	}
+	if req.Direction != "" && req.Direction != constants.FundDirectionOut {
+		return nil, gerror.Newf("资金方向 %s 与减少资金操作不一致", req.Direction)
+	}
 
 	// 余额验证
 	balance, err := m.operationLogic.GetUserBalance(ctx, req.UserID, req.TokenSymbol)
@@ -102,7 +191,7 @@ func (m *walletManager) DebitFundsInTx(ctx context.Context, tx gdb.TX, req *Fund
 		return nil, gerror.Wrap(err, "获取用户余额失败")
 	}
 	if balance.LessThan(req.Amount) {
-		return nil, gerror.Newf("余额不足: 当前余额=%s, 需要金额=%s", balance.String(), req.Amount.String())
+		return nil, gerror.NewCodef(logic.CodeInsufficientBalance, "余额不足: 当前余额=%s, 需要金额=%s", balance.String(), req.Amount.String())
 	}
 
 	// 构建财务操作请求
@@ -111,9 +200,11 @@ func (m *walletManager) DebitFundsInTx(ctx context.Context, tx gdb.TX, req *Fund
 		TokenSymbol:   req.TokenSymbol,
 		Amount:        req.Amount,
 		OperationType: logic.OperationTypeDebit, // 减少资金
+		FundType:      string(req.FundType),
 		BusinessID:    req.BusinessID,
 		Description:   req.Description,
 		Metadata:      req.Metadata,
+		RequestSource: req.RequestSource,
 	}
 
 	// 执行财务操作
@@ -134,6 +225,7 @@ func (m *walletManager) DebitFundsInTx(ctx context.Context, tx gdb.TX, req *Fund
 		BalanceAfter:  opResult.BalanceAfter,
 		RawAmount:     rawAmount,
 		Successful:    true,
+		Replayed:      opResult.Replayed,
 	}
 
 	g.Log().Infof(ctx, "资金减少成功: UserID=%d, Symbol=%s, Amount=%s, TransactionID=%s",
@@ -151,13 +243,33 @@ func (m *walletManager) ProcessFundOperationInTx(ctx context.Context, tx gdb.TX,
 
 // processFundOperationInTxInternal 内部处理方法，使用旧的请求格式
 func (m *walletManager) processFundOperationInTxInternal(ctx context.Context, tx gdb.TX, req *FundOperationRequest) (*FundOperationResult, error) {
+	// BeforeValidate 钩子可在校验前修改请求
+	if err := m.runBeforeHooks(ctx, tx, HookStageBeforeValidate, req, ""); err != nil {
+		return nil, err
+	}
+
 	// 验证资金类型
 	if !constants.IsValidFundType(req.FundType) {
 		return nil, gerror.Newf("无效的资金类型: %s", req.FundType)
 	}
 
-	// 根据资金类型的方向决定操作
-	direction := constants.GetFundDirection(req.FundType)
+	// 提现需要校验目标地址是否满足地址簿规则
+	if req.FundType == constants.FundTypeWithdraw {
+		if err := m.validateWithdrawDestination(ctx, req.UserID, req.TokenSymbol, req.Metadata[constants.MetadataKeyAddress]); err != nil {
+			return nil, err
+		}
+	}
+
+	// 校验资金类型的请求来源权限和默认金额限制
+	if err := constants.ValidateFundTypeUsage(req.FundType, req.RequestSource, req.Amount); err != nil {
+		return nil, gerror.Wrap(err, "资金类型使用受限")
+	}
+
+	// 根据资金类型的方向决定操作（双向资金类型使用请求中指定的方向）
+	direction, err := constants.ResolveFundDirection(req.FundType, req.Direction)
+	if err != nil {
+		return nil, gerror.Wrap(err, "资金方向无效")
+	}
 
 	// 如果描述为空，使用资金类型的默认描述
 	if req.Description == "" {
@@ -166,15 +278,67 @@ func (m *walletManager) processFundOperationInTxInternal(ctx context.Context, tx
 		}
 	}
 
+	// BeforeExecute 钩子可否决或修改请求；资金类型、用户和代币不允许修改，修改后的金额和方向由后续步骤重新校验
+	fundType, userID, tokenSymbol := req.FundType, req.UserID, req.TokenSymbol
+	if err := m.runBeforeHooks(ctx, tx, HookStageBeforeExecute, req, direction); err != nil {
+		return nil, err
+	}
+	if req.FundType != fundType || req.UserID != userID || req.TokenSymbol != tokenSymbol {
+		return nil, gerror.New("BeforeExecute 钩子不能修改资金类型、用户或代币")
+	}
+	if err := constants.ValidateFundTypeUsage(req.FundType, req.RequestSource, req.Amount); err != nil {
+		return nil, gerror.Wrap(err, "资金类型使用受限")
+	}
+
 	// 根据方向执行相应操作
+	var result *FundOperationResult
 	switch direction {
 	case constants.FundDirectionIn:
-		return m.CreditFundsInTx(ctx, tx, req)
+		result, err = m.CreditFundsInTx(ctx, tx, req)
 	case constants.FundDirectionOut:
-		return m.DebitFundsInTx(ctx, tx, req)
+		result, err = m.DebitFundsInTx(ctx, tx, req)
 	default:
 		return nil, gerror.Newf("资金类型 %s 的方向未定义", req.FundType)
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 幂等重放返回的是已执行过的交易，佣金和 After 钩子在首次执行时已经运行过
+	if result.Replayed {
+		return result, nil
+	}
+
+	// 在同一事务中沿关系链分配佣金
+	if err := m.distributeCommissionsInTx(ctx, tx, req, result.TransactionID); err != nil {
+		return nil, err
+	}
+
+	// AfterExecute 钩子在同一事务中执行，AfterCommit 钩子在事务提交后执行
+	if err := m.runAfterHooks(ctx, tx, req, direction, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// distributeCommissionsInTx 为已完成的资金操作分配多级佣金
+func (m *walletManager) distributeCommissionsInTx(ctx context.Context, tx gdb.TX, req *FundOperationRequest, transactionID string) error {
+	id, err := strconv.ParseUint(transactionID, 10, 64)
+	if err != nil {
+		return gerror.Wrapf(err, "无效的交易ID: %s", transactionID)
+	}
+	_, err = m.commissionLogic.DistributeInTx(ctx, tx, &logic.CommissionSource{
+		TransactionID: id,
+		UserID:        req.UserID,
+		TokenSymbol:   req.TokenSymbol,
+		FundType:      req.FundType,
+		Amount:        req.Amount,
+		RequestSource: req.RequestSource,
+	})
+	if err != nil {
+		return gerror.Wrap(err, "分配佣金失败")
+	}
+	return nil
 }
 
 // GetBalance 获取余额
@@ -213,12 +377,6 @@ func (m *walletManager) validateRequest(req *FundOperationRequest) error {
 		return gerror.New("业务ID不能为空")
 	}
 
-	// 安全检查：金额上限验证
-	maxAmount := decimal.NewFromFloat(1000000) // 设置最大金额为100万
-	if req.Amount.GreaterThan(maxAmount) {
-		return gerror.Newf("单次操作金额不能超过 %s", maxAmount.String())
-	}
-
 	// 安全检查：业务ID格式验证（防止SQL注入）
 	if len(req.BusinessID) > 255 {
 		return gerror.New("业务ID长度不能超过255个字符")
@@ -236,11 +394,7 @@ func (m *walletManager) validateRequest(req *FundOperationRequest) error {
 		return gerror.New("代币符号长度不能超过20个字符")
 	}
 
-	// 检查精度是否合理（防止精度攻击）
-	if req.Amount.Exponent() < -18 {
-		return gerror.New("金额精度过高，最多支持18位小数")
-	}
-
+	// 金额上限和精度由策略规则校验（wallet.policies 默认规则 max_amount、max_decimals）
 	return nil
 }
 
@@ -303,19 +457,56 @@ func (m *walletManager) ProcessTransferInTx(ctx context.Context, tx gdb.TX, req
 		return nil, gerror.Wrap(err, "转账加款失败")
 	}
 
-	return &TransferOperationResult{
+	result := &TransferOperationResult{
 		FromTransactionID: debitResult.TransactionID,
 		ToTransactionID:   creditResult.TransactionID,
 		FromBalanceAfter:  debitResult.BalanceAfter,
 		ToBalanceAfter:    creditResult.BalanceAfter,
-	}, nil
+	}
+	if err := m.recordTransferCompleted(ctx, tx, req.BusinessID, req.FromUserID, req.ToUserID, req.TokenSymbol, req.Amount, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// recordTransferCompleted 在转账事务中写入转账完成事件（归属发送方钱包）
+func (m *walletManager) recordTransferCompleted(ctx context.Context, tx gdb.TX, businessID string, fromUserID, toUserID uint64, tokenSymbol string, amount decimal.Decimal, result *TransferOperationResult) error {
+	err := m.eventLogic.RecordInTx(ctx, tx, &logic.RecordEventRequest{
+		Type:        logic.EventTransferCompleted,
+		UserID:      fromUserID,
+		TokenSymbol: tokenSymbol,
+		BusinessID:  businessID,
+		Payload: &logic.TransferCompletedPayload{
+			FromUserID:        fromUserID,
+			ToUserID:          toUserID,
+			Amount:            amount,
+			FromTransactionID: result.FromTransactionID,
+			ToTransactionID:   result.ToTransactionID,
+			FromBalanceAfter:  result.FromBalanceAfter,
+			ToBalanceAfter:    result.ToBalanceAfter,
+		},
+	})
+	if err != nil {
+		return gerror.Wrap(err, "写入转账完成事件失败")
+	}
+	return nil
 }
 
 // CreateTransactionWithBuilder 使用 TransactionBuilder 创建交易
 func (m *walletManager) CreateTransactionWithBuilder(ctx context.Context, txReq *constants.TransactionRequest) (int64, error) {
+	if txReq != nil {
+		if err := m.adjustments.authorize(ctx, txReq.FundType); err != nil {
+			return 0, err
+		}
+	}
 	return m.transactionManager.CreateTransaction(ctx, txReq)
 }
 
+// GetTransactionsByTag 按标签查询交易
+func (m *walletManager) GetTransactionsByTag(ctx context.Context, userID int64, tag string, limit, offset int) ([]*TransactionRecord, error) {
+	return m.transactionManager.GetTransactionsByTag(ctx, userID, tag, limit, offset)
+}
+
 // ProcessFundOperationWithBuilder 使用 FundOperationBuilder 创建资金操作
 func (m *walletManager) ProcessFundOperationWithBuilder(ctx context.Context, tx gdb.TX, builder *constants.FundOperationBuilder) (*FundOperationResult, error) {
 	// 构建请求
@@ -336,6 +527,7 @@ func convertToOldFundOperationRequest(req *constants.FundOperationRequest) *Fund
 		Amount:           req.Amount,
 		BusinessID:       req.BusinessID,
 		FundType:         req.FundType,
+		Direction:        req.Direction,
 		Description:      req.Description,
 		Metadata:         req.Metadata,
 		RelatedID:        req.RelatedID,
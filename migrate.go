@@ -0,0 +1,158 @@
+package wallet
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+
+	"github.com/yalks/wallet/logic"
+	"github.com/yalks/wallet/migrations"
+)
+
+// 数据库迁移与结构检查相关类型
+type (
+	Migration    = logic.Migration
+	SchemaReport = logic.SchemaReport
+	SchemaConfig = logic.SchemaConfig
+)
+
+// Migrate 按版本顺序执行内嵌的数据库迁移脚本（migrations 目录），返回本次执行的迁移。
+// 根据 gdb 配置的数据库类型选择 mysql 或 sqlite 脚本，已执行的版本记录在 schema_migrations 表中。
+// 建表使用 IF NOT EXISTS，已有的表不会被修改，其中缺少的列由 CheckSchema 报告。
+// 不依赖 Initialize，可在其之前调用；多个进程不要同时执行
+func Migrate(ctx context.Context) ([]*Migration, error) {
+	migrationDAO := logic.GetSharedContext().GetMigrationDAO()
+	dialect, all, err := loadMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := migrationDAO.EnsureMigrationTable(ctx, dialect); err != nil {
+		return nil, err
+	}
+	pending, err := pendingMigrations(ctx, all)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, migration := range pending {
+		g.Log().Infof(ctx, "执行数据库迁移: Version=%d, Name=%s", migration.Version, migration.Name)
+		if err := migrationDAO.Apply(ctx, migration.Version, migration.Name, migration.Checksum, migration.Statements); err != nil {
+			return nil, err
+		}
+	}
+	if len(pending) > 0 {
+		g.Log().Infof(ctx, "数据库迁移完成: 执行 %d 个版本", len(pending))
+	}
+	return pending, nil
+}
+
+// CheckSchema 对比迁移脚本定义的表和列与数据库中的实际结构，报告缺少的表、列和未执行的迁移版本。
+// 不修改数据，不依赖 Initialize
+func CheckSchema(ctx context.Context) (*SchemaReport, error) {
+	dialect, all, err := loadMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := pendingMigrations(ctx, all)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := logic.SchemaColumns(all)
+	tables := make([]string, 0, len(expected))
+	for table := range expected {
+		tables = append(tables, table)
+	}
+	actual, err := logic.GetSharedContext().GetMigrationDAO().GetTableColumns(ctx, tables)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SchemaReport{Dialect: dialect}
+	report.MissingTables, report.MissingColumns = logic.CompareSchema(expected, actual)
+	for _, migration := range pending {
+		report.PendingVersions = append(report.PendingVersions, migration.Version)
+	}
+	return report, nil
+}
+
+// loadMigrations 读取当前数据库类型的迁移脚本
+func loadMigrations(ctx context.Context) (string, []*Migration, error) {
+	dialect, err := logic.MigrationDialect(logic.GetSharedContext().GetMigrationDAO().GetDBType(ctx))
+	if err != nil {
+		return "", nil, err
+	}
+	all, err := logic.LoadMigrations(migrations.FS, dialect)
+	if err != nil {
+		return "", nil, err
+	}
+	return dialect, all, nil
+}
+
+// pendingMigrations 返回未执行的迁移
+func pendingMigrations(ctx context.Context, all []*Migration) ([]*Migration, error) {
+	records, err := logic.GetSharedContext().GetMigrationDAO().ListApplied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	applied := make(map[int]string, len(records))
+	for _, record := range records {
+		applied[record.Version] = record.Checksum
+	}
+	return logic.PendingMigrations(all, applied)
+}
+
+// loadSchemaConfig 读取数据库结构配置（wallet.schema），配置无效时返回错误
+func loadSchemaConfig(ctx context.Context) (logic.SchemaConfig, error) {
+	value, err := g.Cfg().Get(ctx, "wallet.schema")
+	if err != nil {
+		return logic.SchemaConfig{}, gerror.Wrap(err, "读取数据库结构配置失败")
+	}
+	var raw map[string]interface{}
+	if value != nil && !value.IsEmpty() {
+		raw = value.Map()
+	}
+	config, err := logic.ParseSchemaConfig(raw)
+	if err != nil {
+		return logic.SchemaConfig{}, gerror.Wrap(err, "数据库结构配置无效")
+	}
+	return config, nil
+}
+
+// prepareSchema Initialize 时按 wallet.schema 配置执行迁移并检查数据库结构：
+// warn 模式下缺少的表和列记录为警告日志，strict 模式下返回错误
+func prepareSchema(ctx context.Context) error {
+	config, err := loadSchemaConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if config.AutoMigrate {
+		if _, err := Migrate(ctx); err != nil {
+			return err
+		}
+	}
+	if config.Check == logic.SchemaCheckOff {
+		return nil
+	}
+
+	report, err := CheckSchema(ctx)
+	if err != nil {
+		if config.Check == logic.SchemaCheckStrict {
+			return gerror.Wrap(err, "检查数据库结构失败")
+		}
+		g.Log().Warningf(ctx, "检查数据库结构失败: %v", err)
+		return nil
+	}
+	if !report.OK() {
+		if config.Check == logic.SchemaCheckStrict {
+			return gerror.Newf("数据库结构不完整，请执行 wallet.Migrate 或手动补齐: %s", report)
+		}
+		g.Log().Warningf(ctx, "数据库结构不完整，相关功能可能无法使用（执行 wallet.Migrate 或手动补齐）: %s", report)
+		return nil
+	}
+	if len(report.PendingVersions) > 0 {
+		g.Log().Infof(ctx, "数据库结构完整，%s", report)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package wallet
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/yalks/wallet/entity"
+	"github.com/yalks/wallet/logic"
+	"github.com/yalks/wallet/migrations"
+)
+
+// migratedTables 迁移脚本创建的表及其实体
+var migratedTables = map[string]interface{}{
+	"users":                     entity.Users{},
+	"tokens":                    entity.Tokens{},
+	"wallets":                   entity.Wallets{},
+	"transactions":              entity.Transactions{},
+	"transaction_tags":          entity.TransactionTags{},
+	"withdraw_addresses":        entity.WithdrawAddresses{},
+	"withdraw_address_settings": entity.WithdrawAddressSettings{},
+	"scheduled_operations":      entity.ScheduledOperations{},
+	"recurring_operations":      entity.RecurringOperations{},
+	"recurring_runs":            entity.RecurringRuns{},
+	"batch_transfers":           entity.BatchTransfers{},
+	"bulk_payout_jobs":          entity.BulkPayoutJobs{},
+	"bulk_payout_rows":          entity.BulkPayoutRows{},
+	"webhook_outbox":            entity.WebhookOutbox{},
+	"webhook_dead_letters":      entity.WebhookDeadLetters{},
+	"domain_events":             entity.DomainEvents{},
+	"balance_snapshots":         entity.BalanceSnapshots{},
+	"audit_entries":             entity.AuditEntries{},
+	"audit_checkpoints":         entity.AuditCheckpoints{},
+	"adjustment_requests":       entity.AdjustmentRequests{},
+	"adjustment_actions":        entity.AdjustmentActions{},
+	"commission_records":        entity.CommissionRecords{},
+	"velocity_counters":         entity.VelocityCounters{},
+	"api_keys":                  entity.ApiKeys{},
+	"api_key_nonces":            entity.ApiKeyNonces{},
+}
+
+// loadSchema 读取内嵌迁移脚本定义的表结构
+func loadSchema(t *testing.T, dialect string) map[string][]string {
+	t.Helper()
+	all, err := logic.LoadMigrations(migrations.FS, dialect)
+	if err != nil {
+		t.Fatalf("LoadMigrations(%s): %v", dialect, err)
+	}
+	if len(all) == 0 {
+		t.Fatalf("no %s migrations", dialect)
+	}
+	return logic.SchemaColumns(all)
+}
+
+func TestMigrationsCoverEntities(t *testing.T) {
+	for _, dialect := range []string{logic.MigrationDialectMySQL, logic.MigrationDialectSQLite} {
+		schema := loadSchema(t, dialect)
+		if len(schema) != len(migratedTables) {
+			t.Errorf("%s: %d tables, want %d", dialect, len(schema), len(migratedTables))
+		}
+		for table, record := range migratedTables {
+			columns := make(map[string]bool)
+			for _, column := range schema[table] {
+				columns[column] = true
+			}
+			typ := reflect.TypeOf(record)
+			for i := 0; i < typ.NumField(); i++ {
+				if column := typ.Field(i).Tag.Get("orm"); !columns[column] {
+					t.Errorf("%s: table %s is missing column %s of entity.%s", dialect, table, column, typ.Name())
+				}
+			}
+		}
+	}
+}
+
+func TestMigrationDialectsMatch(t *testing.T) {
+	mysql, sqlite := loadSchema(t, logic.MigrationDialectMySQL), loadSchema(t, logic.MigrationDialectSQLite)
+	for table, columns := range mysql {
+		got, want := append([]string(nil), sqlite[table]...), append([]string(nil), columns...)
+		sort.Strings(got)
+		sort.Strings(want)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("table %s: sqlite columns %v, mysql columns %v", table, got, want)
+		}
+	}
+}
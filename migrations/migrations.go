@@ -0,0 +1,16 @@
+// Package migrations embeds the versioned SQL migrations of the wallet module.
+//
+// Each database type has its own directory (mysql, sqlite) of files named
+// <version>_<name>.sql. wallet.Migrate runs the pending files in version order
+// and records them in the schema_migrations table. Tables and indexes are
+// created with IF NOT EXISTS, so the first migration can also be run against a
+// database whose tables were created by hand. Applied files must never be
+// edited; add a new version instead.
+package migrations
+
+import "embed"
+
+// FS holds the migration files, mysql/*.sql and sqlite/*.sql.
+//
+//go:embed mysql/*.sql sqlite/*.sql
+var FS embed.FS
@@ -0,0 +1,176 @@
+-- 核心表：用户、代币、钱包、交易及交易标签
+-- 需要 InnoDB 和 utf8mb4（MySQL 5.7+ 或 MariaDB 10.3+）
+
+CREATE TABLE IF NOT EXISTS `users` (
+    `id`                                BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `telegram_id`                       BIGINT NOT NULL DEFAULT 0,
+    `name`                              VARCHAR(255) NOT NULL DEFAULT '',
+    `username`                          VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'Telegram 用户名',
+    `email`                             VARCHAR(255) NOT NULL DEFAULT '',
+    `email_verified_at`                 DATETIME NULL DEFAULT NULL,
+    `password`                          VARCHAR(255) NOT NULL DEFAULT '' COMMENT '用户登录密码（经过哈希处理）',
+    `remember_token`                    VARCHAR(100) NOT NULL DEFAULT '',
+    `account`                           VARCHAR(255) NOT NULL DEFAULT '' COMMENT '唯一用户账户标识符（例如，用于登录）',
+    `account_type`                      INT NOT NULL DEFAULT 0 COMMENT '账户类型 1 用户 2商户 3 代理',
+    `invite_code`                       VARCHAR(64) NOT NULL DEFAULT '' COMMENT '用户唯一邀请码（用于分享给其他人）',
+    `area_code`                         VARCHAR(16) NOT NULL DEFAULT '' COMMENT '电话区号',
+    `phone`                             VARCHAR(32) NOT NULL DEFAULT '' COMMENT '用户电话号码',
+    `avatar`                            VARCHAR(512) NOT NULL DEFAULT '' COMMENT '用户头像URL或路径',
+    `nickname`                          VARCHAR(255) NOT NULL DEFAULT '' COMMENT '用户显示昵称',
+    `payment_password`                  VARCHAR(255) NULL DEFAULT NULL COMMENT '支付密码（经过哈希处理，未设置时为NULL）',
+    `recommend_id`                      BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '推荐该用户的用户ID（关联users.id）',
+    `deep`                              INT NOT NULL DEFAULT 0 COMMENT '推荐结构中的层级深度',
+    `recommend_relationship`            VARCHAR(1024) NOT NULL DEFAULT '' COMMENT '表示客户推荐层级关系的路径（例如，/1/5/10/）',
+    `agent_relationship`                VARCHAR(1024) NOT NULL DEFAULT '' COMMENT '表示代理推荐层级关系的路径',
+    `first_id`                          INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '关联的代理一级ID',
+    `second_id`                         INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '关联的代理二级ID',
+    `third_id`                          INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '关联的代理三级ID',
+    `is_stop`                           TINYINT NOT NULL DEFAULT 0 COMMENT '用户账户暂停状态：0=活跃，1=已暂停',
+    `current_token`                     VARCHAR(512) NOT NULL DEFAULT '' COMMENT '最后使用的认证令牌（例如，API令牌）',
+    `last_login_time`                   DATETIME NULL DEFAULT NULL COMMENT '最后一次成功登录的时间戳',
+    `reason`                            TEXT NULL COMMENT '暂停或其他状态变更的原因',
+    `created_at`                        DATETIME NULL DEFAULT NULL,
+    `updated_at`                        DATETIME NULL DEFAULT NULL,
+    `deleted_at`                        DATETIME NULL DEFAULT NULL COMMENT '软删除的时间戳',
+    `google2fa_secret`                  VARCHAR(255) NOT NULL DEFAULT '' COMMENT '谷歌2fa密钥',
+    `google2fa_enabled`                 TINYINT NOT NULL DEFAULT 0 COMMENT '谷歌2fa是否启用',
+    `is_payment_password`               TINYINT NOT NULL DEFAULT 0 COMMENT '是否开启免密支付',
+    `payment_password_amount`           DECIMAL(36,18) NOT NULL DEFAULT 0 COMMENT '免密支付额度',
+    `backup_accounts`                   TEXT NULL COMMENT '备用账户',
+    `language`                          VARCHAR(16) NOT NULL DEFAULT '' COMMENT '语言',
+    `red_packet_permission`             TINYINT NOT NULL DEFAULT 0 COMMENT '红包权限',
+    `transfer_permission`               TINYINT NOT NULL DEFAULT 0 COMMENT '转账权限',
+    `withdraw_permission`               TINYINT NOT NULL DEFAULT 0 COMMENT '提现权限',
+    `flash_trade_permission`            TINYINT NOT NULL DEFAULT 0 COMMENT '闪兑权限',
+    `recharge_permission`               TINYINT NOT NULL DEFAULT 0 COMMENT '充值权限',
+    `receive_permission`                TINYINT NOT NULL DEFAULT 0 COMMENT '收款权限',
+    `reset_payment_password_permission` TINYINT NOT NULL DEFAULT 0 COMMENT '重置支付密码权限：0=无权限，1=有权限',
+    `main_wallet_id`                    VARCHAR(64) NOT NULL DEFAULT '' COMMENT '钱包id',
+    PRIMARY KEY (`id`),
+    KEY `idx_telegram_id` (`telegram_id`),
+    KEY `idx_username` (`username`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户表';
+
+CREATE TABLE IF NOT EXISTS `tokens` (
+    `token_id`                    INT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '代币内部 ID (主键)',
+    `symbol`                      VARCHAR(32) NOT NULL DEFAULT '' COMMENT '代币符号 (例如: USDT, BTC, ETH)',
+    `name`                        VARCHAR(255) NOT NULL DEFAULT '' COMMENT '代币名称 (例如: Tether, Bitcoin, Ethereum)',
+    `network`                     VARCHAR(64) NOT NULL DEFAULT '' COMMENT '所属网络/链 (例如: Ethereum, Tron, Bitcoin, BSC, Solana)',
+    `chain_id`                    INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '链 ID (主要用于 EVM 兼容链)',
+    `confirmations`               INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '确认次数',
+    `contract_address`            VARCHAR(255) NULL DEFAULT NULL COMMENT '代币合约地址 (对于非原生代币). 对于原生代币为 NULL.',
+    `token_standard`              VARCHAR(32) NOT NULL DEFAULT '' COMMENT '代币标准 (例如: native, ERC20, TRC20, BEP20, SPL)',
+    `decimals`                    INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '代币精度/小数位数',
+    `is_stablecoin`               TINYINT NOT NULL DEFAULT 0 COMMENT '是否为稳定币',
+    `is_fiat`                     TINYINT NOT NULL DEFAULT 0 COMMENT '是否为法币',
+    `is_active`                   TINYINT NOT NULL DEFAULT 1 COMMENT '代币是否在系统内激活可用 (总开关)',
+    `allow_deposit`               TINYINT NOT NULL DEFAULT 0 COMMENT '是否允许充值 (设为 FALSE 即表示充值维护中)',
+    `allow_withdraw`              TINYINT NOT NULL DEFAULT 0 COMMENT '是否允许提现 (设为 FALSE 即表示提现维护中)',
+    `allow_transfer`              TINYINT NOT NULL DEFAULT 0 COMMENT '是否允许内部转账',
+    `allow_receive`               TINYINT NOT NULL DEFAULT 0 COMMENT '是否允许内部收款 (通常与 allow_transfer 联动或独立控制)',
+    `allow_red_packet`            TINYINT NOT NULL DEFAULT 0 COMMENT '是否允许发红包',
+    `allow_trading`               TINYINT NOT NULL DEFAULT 0 COMMENT '是否允许在交易对中使用',
+    `maintenance_message`         TEXT NULL COMMENT '维护信息 (当充值/提现/其他功能禁用时显示)',
+    `withdrawal_fee_type`         VARCHAR(16) NOT NULL DEFAULT '' COMMENT '提币手续费类型: fixed-固定金额, percent-百分比',
+    `withdrawal_fee_amount`       DECIMAL(36,18) NOT NULL DEFAULT 0 COMMENT '提币手续费金额/比例 (根据 fee_type 决定)',
+    `min_deposit_amount`          DECIMAL(36,18) NULL DEFAULT NULL COMMENT '单笔最小充值金额',
+    `max_deposit_amount`          DECIMAL(36,18) NULL DEFAULT NULL COMMENT '单笔最大充值金额 (NULL 表示无限制)',
+    `min_withdrawal_amount`       DECIMAL(36,18) NULL DEFAULT NULL COMMENT '单笔最小提币金额',
+    `max_withdrawal_amount`       DECIMAL(36,18) NULL DEFAULT NULL COMMENT '单笔最大提币金额 (NULL 表示无限制)',
+    `min_transfer_amount`         DECIMAL(36,18) NULL DEFAULT NULL COMMENT '单笔最小转账金额',
+    `max_transfer_amount`         DECIMAL(36,18) NULL DEFAULT NULL COMMENT '单笔最大转账金额 (NULL 表示无限制)',
+    `min_receive_amount`          DECIMAL(36,18) NULL DEFAULT NULL COMMENT '单笔最小收款金额',
+    `max_receive_amount`          DECIMAL(36,18) NULL DEFAULT NULL COMMENT '单笔最大收款金额 (NULL 表示无限制)',
+    `min_red_packet_amount`       DECIMAL(36,18) NULL DEFAULT NULL COMMENT '单个红包最小金额',
+    `max_red_packet_amount`       DECIMAL(36,18) NULL DEFAULT NULL COMMENT '单个红包最大金额 (NULL 表示无限制)',
+    `max_red_packet_count`        BIGINT NULL DEFAULT NULL COMMENT '单次发放红包最大个数 (NULL 表示无限制)',
+    `max_red_packet_total_amount` DECIMAL(36,18) NULL DEFAULT NULL COMMENT '单次发放红包最大总金额 (NULL 表示无限制)',
+    `logo_url`                    VARCHAR(512) NOT NULL DEFAULT '' COMMENT '代币 Logo 图片 URL',
+    `project_website`             VARCHAR(512) NOT NULL DEFAULT '' COMMENT '项目官方网站 URL',
+    `description`                 TEXT NULL COMMENT '代币或项目描述',
+    `order`                       INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '排序字段 (用于前端展示时的排序)',
+    `status`                      TINYINT NOT NULL DEFAULT 1 COMMENT '状态 0-下架 1-上架',
+    `created_at`                  DATETIME NULL DEFAULT NULL COMMENT '创建时间',
+    `updated_at`                  DATETIME NULL DEFAULT NULL COMMENT '最后更新时间',
+    `deleted_at`                  DATETIME NULL DEFAULT NULL COMMENT '软删除时间',
+    PRIMARY KEY (`token_id`),
+    KEY `idx_symbol` (`symbol`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='代币表';
+
+CREATE TABLE IF NOT EXISTS `wallets` (
+    `wallet_id`         INT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '钱包记录唯一 ID',
+    `ledger_id`         VARCHAR(64) NOT NULL DEFAULT '' COMMENT 'ledger 钱包id',
+    `user_id`           INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '用户 ID (外键, 关联 users.user_id)',
+    `token_id`          INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '用户 ID (外键, 关联 users.user_id)',
+    `available_balance` BIGINT NOT NULL DEFAULT 0 COMMENT '可用余额',
+    `frozen_balance`    BIGINT NOT NULL DEFAULT 0 COMMENT '冻结余额 (例如: 挂单中, 提现处理中)',
+    `decimal_places`    INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '精度',
+    `created_at`        DATETIME NULL DEFAULT NULL COMMENT '钱包记录创建时间',
+    `updated_at`        DATETIME NULL DEFAULT NULL COMMENT '余额最后更新时间',
+    `deleted_at`        DATETIME NULL DEFAULT NULL COMMENT '软删除的时间戳',
+    `telegram_id`       BIGINT NOT NULL DEFAULT 0,
+    `type`              VARCHAR(50) NOT NULL DEFAULT '' COMMENT '类型',
+    `symbol`            VARCHAR(32) NOT NULL DEFAULT '' COMMENT '代币符号 (例如: USDT, BTC, ETH)',
+    PRIMARY KEY (`wallet_id`),
+    UNIQUE KEY `uk_user_symbol` (`user_id`, `symbol`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='钱包表';
+
+CREATE TABLE IF NOT EXISTS `transactions` (
+    `transaction_id`         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '交易记录 ID (主键)',
+    `user_id`                INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '关联用户 ID',
+    `username`               INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'telegra username',
+    `token_id`               INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '关联代币 ID',
+    `type`                   VARCHAR(50) NOT NULL DEFAULT '' COMMENT '交易类型: deposit, withdrawal, transfer, red_packet, payment, commission, system_adjust, etc.',
+    `wallet_type`            VARCHAR(16) NOT NULL DEFAULT '' COMMENT '钱包类型:  冻结frozen ，余额 available',
+    `direction`              VARCHAR(8) NOT NULL DEFAULT '' COMMENT '资金方向: in-增加, out-减少',
+    `amount`                 DECIMAL(36,18) NOT NULL DEFAULT 0 COMMENT '交易金额 (绝对值)',
+    `balance_before`         DECIMAL(36,18) NOT NULL DEFAULT 0 COMMENT '交易前余额快照 (对应钱包)',
+    `balance_after`          DECIMAL(36,18) NOT NULL DEFAULT 0 COMMENT '交易后余额快照 (对应钱包)',
+    `related_transaction_id` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '关联交易 ID (例如: 转账的对方记录)',
+    `related_entity_id`      BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '关联实体 ID (例如: 红包 ID, 提现订单 ID)',
+    `related_entity_type`    VARCHAR(64) NOT NULL DEFAULT '' COMMENT '关联实体类型 (例如: red_packet, withdrawal_order)',
+    `status`                 TINYINT UNSIGNED NOT NULL DEFAULT 1 COMMENT '交易状态: 1-成功, 0-失败',
+    `memo`                   TEXT NULL COMMENT '交易备注/消息 (例如: 管理员调账原因)',
+    `created_at`             DATETIME NULL DEFAULT NULL COMMENT '创建时间',
+    `updated_at`             DATETIME NULL DEFAULT NULL COMMENT '最后更新时间',
+    `deleted_at`             DATETIME NULL DEFAULT NULL COMMENT '软删除时间',
+    `symbol`                 VARCHAR(32) NOT NULL DEFAULT '' COMMENT '代币符号 (例如: USDT, BTC, ETH)',
+    `business_id`            VARCHAR(255) NOT NULL DEFAULT '' COMMENT '业务唯一标识符，用于幂等性检查',
+    `request_amount`         DECIMAL(36,18) NOT NULL DEFAULT 0 COMMENT '用户请求的原始金额 (用户输入的金额)',
+    `request_reference`      VARCHAR(255) NOT NULL DEFAULT '' COMMENT '用户请求的参考信息 (如转账备注、提现地址等)',
+    `request_metadata`       TEXT NULL COMMENT '用户请求的元数据 (JSON格式存储扩展信息)',
+    `request_source`         VARCHAR(32) NOT NULL DEFAULT '' COMMENT '请求来源 (telegram, web, api, admin等)',
+    `request_ip`             VARCHAR(64) NOT NULL DEFAULT '' COMMENT '用户请求的IP地址',
+    `request_user_agent`     VARCHAR(512) NOT NULL DEFAULT '' COMMENT '用户请求的User-Agent',
+    `request_timestamp`      DATETIME NULL DEFAULT NULL COMMENT '用户发起请求的时间戳',
+    `processed_at`           DATETIME NULL DEFAULT NULL COMMENT '交易处理完成时间',
+    `fee_amount`             DECIMAL(36,18) NOT NULL DEFAULT 0 COMMENT '手续费金额',
+    `fee_type`               VARCHAR(16) NOT NULL DEFAULT '' COMMENT '手续费类型 (fixed, percentage)',
+    `exchange_rate`          DECIMAL(36,18) NOT NULL DEFAULT 0 COMMENT '汇率 (如果涉及币种转换)',
+    `target_user_id`         INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '目标用户ID (转账、红包等操作的接收方)',
+    `target_username`        VARCHAR(255) NOT NULL DEFAULT '' COMMENT '目标用户名 (转账、红包等操作的接收方用户名)',
+    `idempotency_key`        VARCHAR(255) NULL DEFAULT NULL COMMENT '客户端幂等键 (唯一，与引用号独立)',
+    `priority`               TINYINT NOT NULL DEFAULT 5 COMMENT '优先级 (1-10, 越大越优先)',
+    `expire_at`              DATETIME NULL DEFAULT NULL COMMENT '请求过期时间',
+    `risk_score`             INT NOT NULL DEFAULT 0 COMMENT '风控评分',
+    `risk_decision`          VARCHAR(16) NOT NULL DEFAULT '' COMMENT '风控决策: allow, challenge (已验证支付密码); 未评估时为空',
+    `risk_reasons`           VARCHAR(512) NOT NULL DEFAULT '' COMMENT '触发的风控规则 (逗号分隔)',
+    PRIMARY KEY (`transaction_id`),
+    UNIQUE KEY `uk_business_id` (`business_id`),
+    UNIQUE KEY `uk_idempotency_key` (`idempotency_key`),
+    KEY `idx_request_reference` (`request_reference`),
+    KEY `idx_user_symbol` (`user_id`, `symbol`, `created_at`),
+    KEY `idx_created_at` (`created_at`, `transaction_id`),
+    KEY `idx_request_ip` (`request_ip`, `created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='交易记录表';
+
+CREATE TABLE IF NOT EXISTS `transaction_tags` (
+    `id`             BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '标签记录 ID (主键)',
+    `transaction_id` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '关联交易 ID (与 tag 联合唯一)',
+    `user_id`        BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '交易所属用户 ID',
+    `tag`            VARCHAR(64) NOT NULL DEFAULT '' COMMENT '标签',
+    `created_at`     DATETIME NULL DEFAULT NULL COMMENT '创建时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_transaction_tag` (`transaction_id`, `tag`),
+    KEY `idx_tag` (`tag`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='交易标签表';
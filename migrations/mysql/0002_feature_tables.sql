@@ -0,0 +1,346 @@
+-- 功能表：提现地址簿、定时与循环操作、批量转账与发放、Webhook、领域事件、快照、审计、调账审批、佣金、频率限制、API Key
+
+CREATE TABLE IF NOT EXISTS `withdraw_addresses` (
+    `id`             BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '地址记录 ID (主键)',
+    `user_id`        INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '关联用户 ID',
+    `network`        VARCHAR(64) NOT NULL DEFAULT '' COMMENT '所属网络/链 (与 tokens.network 一致)',
+    `token_standard` VARCHAR(32) NOT NULL DEFAULT '' COMMENT '代币标准 (例如: native, ERC20, TRC20)',
+    `address`        VARCHAR(255) NOT NULL DEFAULT '' COMMENT '提现目标地址',
+    `label`          VARCHAR(255) NOT NULL DEFAULT '' COMMENT '地址备注',
+    `status`         TINYINT NOT NULL DEFAULT 1 COMMENT '状态 0-已删除 1-正常',
+    `activated_at`   DATETIME NULL DEFAULT NULL COMMENT '冷静期结束时间 (此后才可用于白名单提现)',
+    `created_at`     DATETIME NULL DEFAULT NULL COMMENT '创建时间',
+    `updated_at`     DATETIME NULL DEFAULT NULL COMMENT '最后更新时间',
+    `deleted_at`     DATETIME NULL DEFAULT NULL COMMENT '软删除时间',
+    PRIMARY KEY (`id`),
+    KEY `idx_user_network_address` (`user_id`, `network`, `address`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='提现地址簿';
+
+CREATE TABLE IF NOT EXISTS `withdraw_address_settings` (
+    `user_id`              INT UNSIGNED NOT NULL COMMENT '关联用户 ID (主键)',
+    `whitelist_only`       TINYINT NOT NULL DEFAULT 0 COMMENT '是否仅允许提现到白名单地址',
+    `whitelist_disable_at` DATETIME NULL DEFAULT NULL COMMENT '关闭白名单模式的生效时间 (冷静期结束)',
+    `created_at`           DATETIME NULL DEFAULT NULL COMMENT '创建时间',
+    `updated_at`           DATETIME NULL DEFAULT NULL COMMENT '最后更新时间',
+    PRIMARY KEY (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='提现地址设置';
+
+CREATE TABLE IF NOT EXISTS `scheduled_operations` (
+    `id`             BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '定时操作 ID (主键)',
+    `user_id`        INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '关联用户 ID',
+    `token_id`       INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '关联代币 ID',
+    `symbol`         VARCHAR(32) NOT NULL DEFAULT '' COMMENT '代币符号 (例如: USDT, BTC, ETH)',
+    `amount`         DECIMAL(36,18) NOT NULL DEFAULT 0 COMMENT '操作金额',
+    `fund_type`      VARCHAR(50) NOT NULL DEFAULT '' COMMENT '资金类型',
+    `business_id`    VARCHAR(255) NOT NULL DEFAULT '' COMMENT '执行时使用的稳定业务ID (幂等键)',
+    `reference`      VARCHAR(255) NOT NULL DEFAULT '' COMMENT '交易引用号',
+    `description`    TEXT NULL COMMENT '操作描述',
+    `metadata`       TEXT NULL COMMENT '扩展元数据 (JSON格式)',
+    `callback`       TEXT NULL COMMENT '回调信息 (JSON格式，包含 url/method)',
+    `tags`           TEXT NULL COMMENT '交易标签 (JSON数组，执行后写入 transaction_tags)',
+    `related_id`     BIGINT NOT NULL DEFAULT 0 COMMENT '关联实体 ID',
+    `priority`       INT NOT NULL DEFAULT 0 COMMENT '优先级 (1-10, 越大越优先)',
+    `status`         VARCHAR(32) NOT NULL DEFAULT '' COMMENT '状态: pending, processing, completed, failed, cancelled, expired',
+    `scheduled_at`   DATETIME NULL DEFAULT NULL COMMENT '计划执行时间',
+    `expire_at`      DATETIME NULL DEFAULT NULL COMMENT '过期时间 (超过后不再执行)',
+    `next_run_at`    DATETIME NULL DEFAULT NULL COMMENT '下次尝试执行时间',
+    `claimed_at`     DATETIME NULL DEFAULT NULL COMMENT '调度器抢占时间 (处理中超过租约时长后重新排队)',
+    `attempts`       INT NOT NULL DEFAULT 0 COMMENT '已尝试次数',
+    `last_error`     TEXT NULL COMMENT '最后一次失败原因',
+    `transaction_id` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '执行成功后的交易记录 ID',
+    `executed_at`    DATETIME NULL DEFAULT NULL COMMENT '执行完成时间',
+    `created_at`     DATETIME NULL DEFAULT NULL COMMENT '创建时间',
+    `updated_at`     DATETIME NULL DEFAULT NULL COMMENT '最后更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_business_id` (`business_id`),
+    KEY `idx_status_next_run` (`status`, `next_run_at`),
+    KEY `idx_status_claimed` (`status`, `claimed_at`),
+    KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='定时资金操作';
+
+CREATE TABLE IF NOT EXISTS `recurring_operations` (
+    `id`                   BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '循环操作 ID (主键)',
+    `user_id`              INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '关联用户 ID',
+    `token_id`             INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '关联代币 ID',
+    `symbol`               VARCHAR(32) NOT NULL DEFAULT '' COMMENT '代币符号',
+    `amount`               DECIMAL(36,18) NOT NULL DEFAULT 0 COMMENT '每次执行金额',
+    `fund_type`            VARCHAR(50) NOT NULL DEFAULT '' COMMENT '资金类型',
+    `business_id`          VARCHAR(255) NOT NULL DEFAULT '' COMMENT '基础业务ID (每次执行派生独立业务ID)',
+    `reference`            VARCHAR(255) NOT NULL DEFAULT '' COMMENT '交易引用号',
+    `description`          TEXT NULL COMMENT '交易描述',
+    `metadata`             TEXT NULL COMMENT '交易元数据 (JSON)',
+    `callback`             TEXT NULL COMMENT '回调信息 (JSON格式，包含 url/method)',
+    `related_id`           BIGINT NOT NULL DEFAULT 0 COMMENT '关联实体 ID',
+    `priority`             INT NOT NULL DEFAULT 0 COMMENT '优先级 (1-10)',
+    `schedule_type`        VARCHAR(16) NOT NULL DEFAULT '' COMMENT '调度类型: interval, cron',
+    `schedule_spec`        VARCHAR(128) NOT NULL DEFAULT '' COMMENT '调度规则 (间隔时长或 cron 表达式)',
+    `total_count`          INT NOT NULL DEFAULT 0 COMMENT '计划执行总次数 (0 表示不限)',
+    `remaining_count`      INT NOT NULL DEFAULT 0 COMMENT '剩余执行次数 (总次数为 0 时不使用)',
+    `run_count`            INT NOT NULL DEFAULT 0 COMMENT '已执行次数 (含失败)',
+    `consecutive_failures` INT NOT NULL DEFAULT 0 COMMENT '连续失败次数',
+    `status`               VARCHAR(32) NOT NULL DEFAULT '' COMMENT '状态: active, paused, cancelled, completed',
+    `next_run_at`          DATETIME NULL DEFAULT NULL COMMENT '下次执行时间',
+    `last_run_at`          DATETIME NULL DEFAULT NULL COMMENT '最近一次执行时间',
+    `created_at`           DATETIME NULL DEFAULT NULL COMMENT '创建时间',
+    `updated_at`           DATETIME NULL DEFAULT NULL COMMENT '最后更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_business_id` (`business_id`),
+    KEY `idx_status_next_run` (`status`, `next_run_at`),
+    KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='循环资金操作';
+
+CREATE TABLE IF NOT EXISTS `recurring_runs` (
+    `id`             BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '执行记录 ID (主键)',
+    `recurring_id`   BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '关联循环操作 ID',
+    `occurrence`     INT NOT NULL DEFAULT 0 COMMENT '第几次执行 (从 1 开始)',
+    `business_id`    VARCHAR(255) NOT NULL DEFAULT '' COMMENT '本次执行派生的业务ID',
+    `planned_at`     DATETIME NULL DEFAULT NULL COMMENT '计划执行时间',
+    `status`         VARCHAR(32) NOT NULL DEFAULT '' COMMENT '执行结果: completed, failed',
+    `transaction_id` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '成功时关联的交易 ID',
+    `error`          TEXT NULL COMMENT '失败原因',
+    `created_at`     DATETIME NULL DEFAULT NULL COMMENT '执行时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_recurring_occurrence` (`recurring_id`, `occurrence`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='循环操作执行记录';
+
+CREATE TABLE IF NOT EXISTS `batch_transfers` (
+    `id`              BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '批量转账记录 ID (主键)',
+    `batch_id`        VARCHAR(255) NOT NULL DEFAULT '' COMMENT '批次ID (幂等键, 唯一)',
+    `from_user_id`    INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '发送方用户 ID',
+    `mode`            VARCHAR(32) NOT NULL DEFAULT '' COMMENT '执行模式: all_or_nothing, best_effort',
+    `leg_count`       INT NOT NULL DEFAULT 0 COMMENT '转账笔数',
+    `succeeded_count` INT NOT NULL DEFAULT 0 COMMENT '成功笔数',
+    `failed_count`    INT NOT NULL DEFAULT 0 COMMENT '失败笔数',
+    `total_amount`    DECIMAL(36,18) NOT NULL DEFAULT 0 COMMENT '成功转出总额',
+    `report`          MEDIUMTEXT NULL COMMENT '逐笔执行结果 (JSON)',
+    `created_at`      DATETIME NULL DEFAULT NULL COMMENT '创建时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_batch_id` (`batch_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='批量转账记录';
+
+CREATE TABLE IF NOT EXISTS `bulk_payout_jobs` (
+    `id`             BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '任务记录 ID (主键)',
+    `job_id`         VARCHAR(128) NOT NULL DEFAULT '' COMMENT '任务ID (唯一, 用于断点续传)',
+    `format`         VARCHAR(8) NOT NULL DEFAULT '' COMMENT '输入格式: csv, json',
+    `checksum`       VARCHAR(64) NOT NULL DEFAULT '' COMMENT '输入内容 SHA-256',
+    `total_rows`     INT NOT NULL DEFAULT 0 COMMENT '总行数',
+    `processed_rows` INT NOT NULL DEFAULT 0 COMMENT '已处理行数 (断点位置)',
+    `succeeded_rows` INT NOT NULL DEFAULT 0 COMMENT '成功行数',
+    `failed_rows`    INT NOT NULL DEFAULT 0 COMMENT '失败行数',
+    `status`         VARCHAR(32) NOT NULL DEFAULT '' COMMENT '状态: processing, completed',
+    `created_at`     DATETIME NULL DEFAULT NULL COMMENT '创建时间',
+    `updated_at`     DATETIME NULL DEFAULT NULL COMMENT '最后更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_job_id` (`job_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='批量发放任务';
+
+CREATE TABLE IF NOT EXISTS `bulk_payout_rows` (
+    `id`             BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '行记录 ID (主键)',
+    `job_id`         VARCHAR(128) NOT NULL DEFAULT '' COMMENT '所属任务ID',
+    `line`           INT NOT NULL DEFAULT 0 COMMENT '输入行号',
+    `user_id`        BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '解析后的用户 ID',
+    `symbol`         VARCHAR(32) NOT NULL DEFAULT '' COMMENT '代币符号',
+    `amount`         DECIMAL(36,18) NOT NULL DEFAULT 0 COMMENT '发放金额',
+    `fund_type`      VARCHAR(50) NOT NULL DEFAULT '' COMMENT '资金类型',
+    `business_id`    VARCHAR(255) NOT NULL DEFAULT '' COMMENT '行级业务ID (幂等)',
+    `status`         VARCHAR(32) NOT NULL DEFAULT '' COMMENT '执行结果: completed, failed',
+    `transaction_id` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '成功时关联的交易 ID',
+    `error`          TEXT NULL COMMENT '失败原因',
+    `created_at`     DATETIME NULL DEFAULT NULL COMMENT '执行时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_job_line` (`job_id`, `line`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='批量发放行结果';
+
+CREATE TABLE IF NOT EXISTS `webhook_outbox` (
+    `id`               BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '出站记录 ID (主键)',
+    `event`            VARCHAR(64) NOT NULL DEFAULT '' COMMENT '事件类型',
+    `business_id`      VARCHAR(255) NOT NULL DEFAULT '' COMMENT '关联业务ID',
+    `url`              VARCHAR(1024) NOT NULL DEFAULT '' COMMENT '回调地址',
+    `method`           VARCHAR(16) NOT NULL DEFAULT '' COMMENT '回调方法: GET, POST',
+    `payload`          MEDIUMTEXT NULL COMMENT 'JSON 负载',
+    `status`           VARCHAR(32) NOT NULL DEFAULT '' COMMENT '状态: pending, delivered',
+    `attempts`         INT NOT NULL DEFAULT 0 COMMENT '已尝试次数',
+    `next_attempt_at`  DATETIME NULL DEFAULT NULL COMMENT '下次投递时间',
+    `last_status_code` INT NOT NULL DEFAULT 0 COMMENT '最近一次响应状态码',
+    `last_error`       TEXT NULL COMMENT '最近一次错误',
+    `delivered_at`     DATETIME NULL DEFAULT NULL COMMENT '投递成功时间',
+    `created_at`       DATETIME NULL DEFAULT NULL COMMENT '创建时间',
+    `updated_at`       DATETIME NULL DEFAULT NULL COMMENT '最后更新时间',
+    PRIMARY KEY (`id`),
+    KEY `idx_status_next_attempt` (`status`, `next_attempt_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Webhook 出站表';
+
+CREATE TABLE IF NOT EXISTS `webhook_dead_letters` (
+    `id`               BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '死信记录 ID (主键)',
+    `outbox_id`        BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '原出站记录 ID',
+    `event`            VARCHAR(64) NOT NULL DEFAULT '' COMMENT '事件类型',
+    `business_id`      VARCHAR(255) NOT NULL DEFAULT '' COMMENT '关联业务ID',
+    `url`              VARCHAR(1024) NOT NULL DEFAULT '' COMMENT '回调地址',
+    `method`           VARCHAR(16) NOT NULL DEFAULT '' COMMENT '回调方法: GET, POST',
+    `payload`          MEDIUMTEXT NULL COMMENT 'JSON 负载',
+    `attempts`         INT NOT NULL DEFAULT 0 COMMENT '已尝试次数',
+    `last_status_code` INT NOT NULL DEFAULT 0 COMMENT '最后一次响应状态码',
+    `last_error`       TEXT NULL COMMENT '最后一次错误',
+    `created_at`       DATETIME NULL DEFAULT NULL COMMENT '进入死信时间',
+    PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Webhook 死信表';
+
+CREATE TABLE IF NOT EXISTS `domain_events` (
+    `id`              BIGINT NOT NULL AUTO_INCREMENT COMMENT '事件 ID (主键，全局递增)',
+    `event_key`       VARCHAR(255) NOT NULL DEFAULT '' COMMENT '去重键 (事件类型:业务ID，唯一)',
+    `event_type`      VARCHAR(64) NOT NULL DEFAULT '' COMMENT '事件类型',
+    `aggregate_id`    VARCHAR(128) NOT NULL DEFAULT '' COMMENT '聚合 ID (钱包: 用户ID:代币符号)',
+    `sequence`        BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '聚合内序号 (从1开始，与 aggregate_id 联合唯一)',
+    `user_id`         BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '关联用户 ID',
+    `symbol`          VARCHAR(32) NOT NULL DEFAULT '' COMMENT '代币符号',
+    `business_id`     VARCHAR(255) NOT NULL DEFAULT '' COMMENT '关联业务ID',
+    `payload`         MEDIUMTEXT NULL COMMENT '事件负载 (JSON)',
+    `status`          VARCHAR(32) NOT NULL DEFAULT '' COMMENT '状态: pending, published',
+    `attempts`        INT NOT NULL DEFAULT 0 COMMENT '发布失败次数',
+    `next_attempt_at` DATETIME NULL DEFAULT NULL COMMENT '下次发布时间',
+    `last_error`      TEXT NULL COMMENT '最后一次发布失败原因',
+    `published_at`    DATETIME NULL DEFAULT NULL COMMENT '发布成功时间',
+    `created_at`      DATETIME NULL DEFAULT NULL COMMENT '创建时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_event_key` (`event_key`),
+    UNIQUE KEY `uk_aggregate_sequence` (`aggregate_id`, `sequence`),
+    KEY `idx_status` (`status`),
+    KEY `idx_user_id` (`user_id`, `id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='领域事件出站表';
+
+CREATE TABLE IF NOT EXISTS `balance_snapshots` (
+    `id`                  BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '快照 ID (主键)',
+    `user_id`             BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '用户 ID',
+    `symbol`              VARCHAR(32) NOT NULL DEFAULT '' COMMENT '代币符号',
+    `snapshot_date`       DATE NULL DEFAULT NULL COMMENT '快照日期 (当日日终余额，与 user_id、symbol 联合唯一)',
+    `balance`             DECIMAL(36,18) NOT NULL DEFAULT 0 COMMENT '日终可用余额',
+    `last_transaction_id` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '截至日终的最后一笔交易 ID',
+    `created_at`          DATETIME NULL DEFAULT NULL COMMENT '创建时间',
+    `updated_at`          DATETIME NULL DEFAULT NULL COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_user_symbol_date` (`user_id`, `symbol`, `snapshot_date`),
+    KEY `idx_snapshot_date` (`snapshot_date`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='每日余额快照';
+
+CREATE TABLE IF NOT EXISTS `audit_entries` (
+    `id`         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '审计记录 ID (主键，全局递增，只追加)',
+    `chain_id`   VARCHAR(128) NOT NULL DEFAULT '' COMMENT '哈希链 ID (钱包: 用户ID:代币符号)',
+    `sequence`   BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '链内序号 (从1开始，与 chain_id 联合唯一)',
+    `entry_type` VARCHAR(32) NOT NULL DEFAULT '' COMMENT '记录类型: transaction, status_change',
+    `ref_id`     BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '关联交易 ID',
+    `payload`    MEDIUMTEXT NULL COMMENT '被审计内容 (规范化 JSON)',
+    `prev_hash`  VARCHAR(64) NOT NULL DEFAULT '' COMMENT '上一条记录的哈希 (链首为空)',
+    `hash`       VARCHAR(64) NOT NULL DEFAULT '' COMMENT '本记录哈希 SHA-256(prev_hash, chain_id, sequence, entry_type, payload)',
+    `created_at` DATETIME NULL DEFAULT NULL COMMENT '创建时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_chain_sequence` (`chain_id`, `sequence`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='审计哈希链';
+
+CREATE TABLE IF NOT EXISTS `audit_checkpoints` (
+    `id`            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '检查点 ID (主键)',
+    `last_entry_id` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '覆盖的最后一条审计记录 ID',
+    `entry_count`   BIGINT NOT NULL DEFAULT 0 COMMENT '本检查点新增覆盖的审计记录数',
+    `prev_root`     VARCHAR(64) NOT NULL DEFAULT '' COMMENT '上一个检查点的根哈希',
+    `root`          VARCHAR(64) NOT NULL DEFAULT '' COMMENT '根哈希 (在上一个根哈希上依次累加审计记录哈希)',
+    `public_key`    VARCHAR(128) NOT NULL DEFAULT '' COMMENT '签名公钥 (Ed25519, base64)',
+    `signature`     VARCHAR(128) NOT NULL DEFAULT '' COMMENT '签名 (Ed25519, base64)',
+    `created_at`    DATETIME NULL DEFAULT NULL COMMENT '创建时间',
+    PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='审计签名检查点';
+
+CREATE TABLE IF NOT EXISTS `adjustment_requests` (
+    `id`                 BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '调账申请 ID (主键)',
+    `user_id`            BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '被调账用户 ID',
+    `symbol`             VARCHAR(32) NOT NULL DEFAULT '' COMMENT '代币符号',
+    `fund_type`          VARCHAR(50) NOT NULL DEFAULT '' COMMENT '资金类型: admin_add, admin_deduct, system_adjustment',
+    `direction`          VARCHAR(8) NOT NULL DEFAULT '' COMMENT '资金方向: in, out',
+    `amount`             DECIMAL(36,18) NOT NULL DEFAULT 0 COMMENT '调账金额 (绝对值)',
+    `reason`             TEXT NULL COMMENT '调账原因',
+    `attachment_ref`     VARCHAR(512) NOT NULL DEFAULT '' COMMENT '附件引用 (工单号、文件地址等)',
+    `metadata`           TEXT NULL COMMENT '扩展元数据 (JSON)',
+    `required_approvals` INT NOT NULL DEFAULT 0 COMMENT '所需审批人数',
+    `approval_count`     INT NOT NULL DEFAULT 0 COMMENT '已审批人数',
+    `status`             VARCHAR(32) NOT NULL DEFAULT '' COMMENT '状态: pending, executed, rejected',
+    `submitted_by`       VARCHAR(128) NOT NULL DEFAULT '' COMMENT '提交人 (管理员标识)',
+    `transaction_id`     BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '执行后的交易 ID',
+    `created_at`         DATETIME NULL DEFAULT NULL COMMENT '提交时间',
+    `updated_at`         DATETIME NULL DEFAULT NULL COMMENT '最后更新时间',
+    `completed_at`       DATETIME NULL DEFAULT NULL COMMENT '执行或驳回时间',
+    PRIMARY KEY (`id`),
+    KEY `idx_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='调账申请';
+
+CREATE TABLE IF NOT EXISTS `adjustment_actions` (
+    `id`         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '操作记录 ID (主键)',
+    `request_id` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '调账申请 ID (与 admin_id 联合唯一)',
+    `admin_id`   VARCHAR(128) NOT NULL DEFAULT '' COMMENT '管理员标识',
+    `action`     VARCHAR(16) NOT NULL DEFAULT '' COMMENT '操作: submit, approve, reject',
+    `comment`    TEXT NULL COMMENT '备注或驳回原因',
+    `created_at` DATETIME NULL DEFAULT NULL COMMENT '操作时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_request_admin` (`request_id`, `admin_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='调账审批操作';
+
+CREATE TABLE IF NOT EXISTS `commission_records` (
+    `id`                    BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '佣金记录 ID (主键)',
+    `source_transaction_id` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '产生佣金的源交易 ID',
+    `source_user_id`        BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '源交易用户 ID',
+    `source_fund_type`      VARCHAR(50) NOT NULL DEFAULT '' COMMENT '源交易资金类型',
+    `source_amount`         DECIMAL(36,18) NOT NULL DEFAULT 0 COMMENT '源交易金额',
+    `symbol`                VARCHAR(32) NOT NULL DEFAULT '' COMMENT '代币符号',
+    `beneficiary_id`        BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '佣金受益人用户 ID',
+    `level`                 INT NOT NULL DEFAULT 0 COMMENT '推荐层级 (1 为直接上级)',
+    `chain`                 VARCHAR(16) NOT NULL DEFAULT '' COMMENT '关系链: referral, agent',
+    `rate`                  DECIMAL(36,18) NOT NULL DEFAULT 0 COMMENT '佣金比例',
+    `amount`                DECIMAL(36,18) NOT NULL DEFAULT 0 COMMENT '佣金金额 (已应用上限)',
+    `fund_type`             VARCHAR(50) NOT NULL DEFAULT '' COMMENT '佣金入账资金类型',
+    `transaction_id`        BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '佣金入账交易 ID',
+    `created_at`            DATETIME NULL DEFAULT NULL COMMENT '创建时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_source_level` (`source_transaction_id`, `level`),
+    KEY `idx_beneficiary` (`beneficiary_id`, `symbol`, `created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='佣金记录';
+
+CREATE TABLE IF NOT EXISTS `velocity_counters` (
+    `id`             BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '计数器 ID (主键)',
+    `user_id`        BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '用户 ID',
+    `symbol`         VARCHAR(32) NOT NULL DEFAULT '' COMMENT '代币符号',
+    `fund_type`      VARCHAR(50) NOT NULL DEFAULT '' COMMENT '资金类型',
+    `request_source` VARCHAR(32) NOT NULL DEFAULT '' COMMENT '请求来源 (小写)',
+    `bucket_start`   BIGINT NOT NULL DEFAULT 0 COMMENT '分钟桶起始时间 (Unix 秒)',
+    `tx_count`       INT NOT NULL DEFAULT 0 COMMENT '桶内交易笔数',
+    `amount`         DECIMAL(36,18) NOT NULL DEFAULT 0 COMMENT '桶内交易金额合计',
+    `updated_at`     DATETIME NULL DEFAULT NULL COMMENT '最后更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_counter` (`user_id`, `symbol`, `fund_type`, `request_source`, `bucket_start`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='频率限制计数器';
+
+CREATE TABLE IF NOT EXISTS `api_keys` (
+    `id`           BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'API Key 记录 ID (主键)',
+    `key_id`       VARCHAR(64) NOT NULL DEFAULT '' COMMENT '公开的 Key ID (唯一)',
+    `name`         VARCHAR(255) NOT NULL DEFAULT '' COMMENT '调用方名称',
+    `secret_hash`  VARCHAR(64) NOT NULL DEFAULT '' COMMENT '服务端密钥计算的 HMAC 校验值',
+    `permissions`  TEXT NULL COMMENT '允许的接口权限, 逗号分隔',
+    `fund_types`   TEXT NULL COMMENT '允许的资金类型, 逗号分隔, 为空不限',
+    `tokens`       TEXT NULL COMMENT '允许的代币符号, 逗号分隔, 为空不限',
+    `user_ids`     TEXT NULL COMMENT '允许操作的用户 ID, 逗号分隔, 为空不限',
+    `max_amount`   DECIMAL(36,18) NOT NULL DEFAULT 0 COMMENT '单笔最大金额, 0 表示不限',
+    `rate_limit`   INT NOT NULL DEFAULT 0 COMMENT '每分钟最多请求数, 0 表示使用默认值',
+    `status`       TINYINT NOT NULL DEFAULT 1 COMMENT '状态: 1-有效, 0-已吊销',
+    `expires_at`   DATETIME NULL DEFAULT NULL COMMENT '过期时间, 为空表示不过期',
+    `last_used_at` DATETIME NULL DEFAULT NULL COMMENT '最后使用时间',
+    `created_at`   DATETIME NULL DEFAULT NULL COMMENT '创建时间',
+    `revoked_at`   DATETIME NULL DEFAULT NULL COMMENT '吊销时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_key_id` (`key_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='API Key';
+
+CREATE TABLE IF NOT EXISTS `api_key_nonces` (
+    `id`         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '记录 ID (主键)',
+    `key_id`     VARCHAR(64) NOT NULL DEFAULT '' COMMENT 'Key ID',
+    `nonce`      VARCHAR(64) NOT NULL DEFAULT '' COMMENT '请求随机数, 与 key_id 联合唯一',
+    `created_at` DATETIME NULL DEFAULT NULL COMMENT '首次使用时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_key_nonce` (`key_id`, `nonce`),
+    KEY `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='API Key 请求随机数';
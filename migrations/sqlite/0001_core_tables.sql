@@ -0,0 +1,171 @@
+-- 核心表：用户、代币、钱包、交易及交易标签
+-- SQLite 适用于开发和测试：金额列为 NUMERIC，超过 15 位有效数字时会丢失精度，生产环境请使用 MySQL
+
+CREATE TABLE IF NOT EXISTS users (
+    id                                 INTEGER PRIMARY KEY AUTOINCREMENT,
+    telegram_id                        INTEGER NOT NULL DEFAULT 0,
+    name                               TEXT NOT NULL DEFAULT '',
+    username                           TEXT NOT NULL DEFAULT '',
+    email                              TEXT NOT NULL DEFAULT '',
+    email_verified_at                  DATETIME NULL DEFAULT NULL,
+    password                           TEXT NOT NULL DEFAULT '',
+    remember_token                     TEXT NOT NULL DEFAULT '',
+    account                            TEXT NOT NULL DEFAULT '',
+    account_type                       INTEGER NOT NULL DEFAULT 0,
+    invite_code                        TEXT NOT NULL DEFAULT '',
+    area_code                          TEXT NOT NULL DEFAULT '',
+    phone                              TEXT NOT NULL DEFAULT '',
+    avatar                             TEXT NOT NULL DEFAULT '',
+    nickname                           TEXT NOT NULL DEFAULT '',
+    payment_password                   TEXT NULL DEFAULT NULL,
+    recommend_id                       INTEGER NOT NULL DEFAULT 0,
+    deep                               INTEGER NOT NULL DEFAULT 0,
+    recommend_relationship             TEXT NOT NULL DEFAULT '',
+    agent_relationship                 TEXT NOT NULL DEFAULT '',
+    first_id                           INTEGER NOT NULL DEFAULT 0,
+    second_id                          INTEGER NOT NULL DEFAULT 0,
+    third_id                           INTEGER NOT NULL DEFAULT 0,
+    is_stop                            INTEGER NOT NULL DEFAULT 0,
+    current_token                      TEXT NOT NULL DEFAULT '',
+    last_login_time                    DATETIME NULL DEFAULT NULL,
+    reason                             TEXT NULL,
+    created_at                         DATETIME NULL DEFAULT NULL,
+    updated_at                         DATETIME NULL DEFAULT NULL,
+    deleted_at                         DATETIME NULL DEFAULT NULL,
+    google2fa_secret                   TEXT NOT NULL DEFAULT '',
+    google2fa_enabled                  INTEGER NOT NULL DEFAULT 0,
+    is_payment_password                INTEGER NOT NULL DEFAULT 0,
+    payment_password_amount            NUMERIC NOT NULL DEFAULT 0,
+    backup_accounts                    TEXT NULL,
+    language                           TEXT NOT NULL DEFAULT '',
+    red_packet_permission              INTEGER NOT NULL DEFAULT 0,
+    transfer_permission                INTEGER NOT NULL DEFAULT 0,
+    withdraw_permission                INTEGER NOT NULL DEFAULT 0,
+    flash_trade_permission             INTEGER NOT NULL DEFAULT 0,
+    recharge_permission                INTEGER NOT NULL DEFAULT 0,
+    receive_permission                 INTEGER NOT NULL DEFAULT 0,
+    reset_payment_password_permission  INTEGER NOT NULL DEFAULT 0,
+    main_wallet_id                     TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS users_idx_telegram_id ON users (telegram_id);
+CREATE INDEX IF NOT EXISTS users_idx_username ON users (username);
+
+CREATE TABLE IF NOT EXISTS tokens (
+    token_id                       INTEGER PRIMARY KEY AUTOINCREMENT,
+    symbol                         TEXT NOT NULL DEFAULT '',
+    name                           TEXT NOT NULL DEFAULT '',
+    network                        TEXT NOT NULL DEFAULT '',
+    chain_id                       INTEGER NOT NULL DEFAULT 0,
+    confirmations                  INTEGER NOT NULL DEFAULT 0,
+    contract_address               TEXT NULL DEFAULT NULL,
+    token_standard                 TEXT NOT NULL DEFAULT '',
+    decimals                       INTEGER NOT NULL DEFAULT 0,
+    is_stablecoin                  INTEGER NOT NULL DEFAULT 0,
+    is_fiat                        INTEGER NOT NULL DEFAULT 0,
+    is_active                      INTEGER NOT NULL DEFAULT 1,
+    allow_deposit                  INTEGER NOT NULL DEFAULT 0,
+    allow_withdraw                 INTEGER NOT NULL DEFAULT 0,
+    allow_transfer                 INTEGER NOT NULL DEFAULT 0,
+    allow_receive                  INTEGER NOT NULL DEFAULT 0,
+    allow_red_packet               INTEGER NOT NULL DEFAULT 0,
+    allow_trading                  INTEGER NOT NULL DEFAULT 0,
+    maintenance_message            TEXT NULL,
+    withdrawal_fee_type            TEXT NOT NULL DEFAULT '',
+    withdrawal_fee_amount          NUMERIC NOT NULL DEFAULT 0,
+    min_deposit_amount             NUMERIC NULL DEFAULT NULL,
+    max_deposit_amount             NUMERIC NULL DEFAULT NULL,
+    min_withdrawal_amount          NUMERIC NULL DEFAULT NULL,
+    max_withdrawal_amount          NUMERIC NULL DEFAULT NULL,
+    min_transfer_amount            NUMERIC NULL DEFAULT NULL,
+    max_transfer_amount            NUMERIC NULL DEFAULT NULL,
+    min_receive_amount             NUMERIC NULL DEFAULT NULL,
+    max_receive_amount             NUMERIC NULL DEFAULT NULL,
+    min_red_packet_amount          NUMERIC NULL DEFAULT NULL,
+    max_red_packet_amount          NUMERIC NULL DEFAULT NULL,
+    max_red_packet_count           INTEGER NULL DEFAULT NULL,
+    max_red_packet_total_amount    NUMERIC NULL DEFAULT NULL,
+    logo_url                       TEXT NOT NULL DEFAULT '',
+    project_website                TEXT NOT NULL DEFAULT '',
+    description                    TEXT NULL,
+    "order"                        INTEGER NOT NULL DEFAULT 0,
+    status                         INTEGER NOT NULL DEFAULT 1,
+    created_at                     DATETIME NULL DEFAULT NULL,
+    updated_at                     DATETIME NULL DEFAULT NULL,
+    deleted_at                     DATETIME NULL DEFAULT NULL
+);
+CREATE INDEX IF NOT EXISTS tokens_idx_symbol ON tokens (symbol);
+
+CREATE TABLE IF NOT EXISTS wallets (
+    wallet_id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    ledger_id          TEXT NOT NULL DEFAULT '',
+    user_id            INTEGER NOT NULL DEFAULT 0,
+    token_id           INTEGER NOT NULL DEFAULT 0,
+    available_balance  INTEGER NOT NULL DEFAULT 0,
+    frozen_balance     INTEGER NOT NULL DEFAULT 0,
+    decimal_places     INTEGER NOT NULL DEFAULT 0,
+    created_at         DATETIME NULL DEFAULT NULL,
+    updated_at         DATETIME NULL DEFAULT NULL,
+    deleted_at         DATETIME NULL DEFAULT NULL,
+    telegram_id        INTEGER NOT NULL DEFAULT 0,
+    type               TEXT NOT NULL DEFAULT '',
+    symbol             TEXT NOT NULL DEFAULT ''
+);
+CREATE UNIQUE INDEX IF NOT EXISTS wallets_uk_user_symbol ON wallets (user_id, symbol);
+
+CREATE TABLE IF NOT EXISTS transactions (
+    transaction_id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id                 INTEGER NOT NULL DEFAULT 0,
+    username                INTEGER NOT NULL DEFAULT 0,
+    token_id                INTEGER NOT NULL DEFAULT 0,
+    type                    TEXT NOT NULL DEFAULT '',
+    wallet_type             TEXT NOT NULL DEFAULT '',
+    direction               TEXT NOT NULL DEFAULT '',
+    amount                  NUMERIC NOT NULL DEFAULT 0,
+    balance_before          NUMERIC NOT NULL DEFAULT 0,
+    balance_after           NUMERIC NOT NULL DEFAULT 0,
+    related_transaction_id  INTEGER NOT NULL DEFAULT 0,
+    related_entity_id       INTEGER NOT NULL DEFAULT 0,
+    related_entity_type     TEXT NOT NULL DEFAULT '',
+    status                  INTEGER NOT NULL DEFAULT 1,
+    memo                    TEXT NULL,
+    created_at              DATETIME NULL DEFAULT NULL,
+    updated_at              DATETIME NULL DEFAULT NULL,
+    deleted_at              DATETIME NULL DEFAULT NULL,
+    symbol                  TEXT NOT NULL DEFAULT '',
+    business_id             TEXT NOT NULL DEFAULT '',
+    request_amount          NUMERIC NOT NULL DEFAULT 0,
+    request_reference       TEXT NOT NULL DEFAULT '',
+    request_metadata        TEXT NULL,
+    request_source          TEXT NOT NULL DEFAULT '',
+    request_ip              TEXT NOT NULL DEFAULT '',
+    request_user_agent      TEXT NOT NULL DEFAULT '',
+    request_timestamp       DATETIME NULL DEFAULT NULL,
+    processed_at            DATETIME NULL DEFAULT NULL,
+    fee_amount              NUMERIC NOT NULL DEFAULT 0,
+    fee_type                TEXT NOT NULL DEFAULT '',
+    exchange_rate           NUMERIC NOT NULL DEFAULT 0,
+    target_user_id          INTEGER NOT NULL DEFAULT 0,
+    target_username         TEXT NOT NULL DEFAULT '',
+    idempotency_key         TEXT NULL DEFAULT NULL,
+    priority                INTEGER NOT NULL DEFAULT 5,
+    expire_at               DATETIME NULL DEFAULT NULL,
+    risk_score              INTEGER NOT NULL DEFAULT 0,
+    risk_decision           TEXT NOT NULL DEFAULT '',
+    risk_reasons            TEXT NOT NULL DEFAULT ''
+);
+CREATE UNIQUE INDEX IF NOT EXISTS transactions_uk_business_id ON transactions (business_id);
+CREATE UNIQUE INDEX IF NOT EXISTS transactions_uk_idempotency_key ON transactions (idempotency_key);
+CREATE INDEX IF NOT EXISTS transactions_idx_request_reference ON transactions (request_reference);
+CREATE INDEX IF NOT EXISTS transactions_idx_user_symbol ON transactions (user_id, symbol, created_at);
+CREATE INDEX IF NOT EXISTS transactions_idx_created_at ON transactions (created_at, transaction_id);
+CREATE INDEX IF NOT EXISTS transactions_idx_request_ip ON transactions (request_ip, created_at);
+
+CREATE TABLE IF NOT EXISTS transaction_tags (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    transaction_id  INTEGER NOT NULL DEFAULT 0,
+    user_id         INTEGER NOT NULL DEFAULT 0,
+    tag             TEXT NOT NULL DEFAULT '',
+    created_at      DATETIME NULL DEFAULT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS transaction_tags_uk_transaction_tag ON transaction_tags (transaction_id, tag);
+CREATE INDEX IF NOT EXISTS transaction_tags_idx_tag ON transaction_tags (tag);
@@ -0,0 +1,326 @@
+-- 功能表：提现地址簿、定时与循环操作、批量转账与发放、Webhook、领域事件、快照、审计、调账审批、佣金、频率限制、API Key
+
+CREATE TABLE IF NOT EXISTS withdraw_addresses (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id         INTEGER NOT NULL DEFAULT 0,
+    network         TEXT NOT NULL DEFAULT '',
+    token_standard  TEXT NOT NULL DEFAULT '',
+    address         TEXT NOT NULL DEFAULT '',
+    label           TEXT NOT NULL DEFAULT '',
+    status          INTEGER NOT NULL DEFAULT 1,
+    activated_at    DATETIME NULL DEFAULT NULL,
+    created_at      DATETIME NULL DEFAULT NULL,
+    updated_at      DATETIME NULL DEFAULT NULL,
+    deleted_at      DATETIME NULL DEFAULT NULL
+);
+CREATE INDEX IF NOT EXISTS withdraw_addresses_idx_user_network_address ON withdraw_addresses (user_id, network, address);
+
+CREATE TABLE IF NOT EXISTS withdraw_address_settings (
+    user_id               INTEGER PRIMARY KEY,
+    whitelist_only        INTEGER NOT NULL DEFAULT 0,
+    whitelist_disable_at  DATETIME NULL DEFAULT NULL,
+    created_at            DATETIME NULL DEFAULT NULL,
+    updated_at            DATETIME NULL DEFAULT NULL
+);
+
+CREATE TABLE IF NOT EXISTS scheduled_operations (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id         INTEGER NOT NULL DEFAULT 0,
+    token_id        INTEGER NOT NULL DEFAULT 0,
+    symbol          TEXT NOT NULL DEFAULT '',
+    amount          NUMERIC NOT NULL DEFAULT 0,
+    fund_type       TEXT NOT NULL DEFAULT '',
+    business_id     TEXT NOT NULL DEFAULT '',
+    reference       TEXT NOT NULL DEFAULT '',
+    description     TEXT NULL,
+    metadata        TEXT NULL,
+    callback        TEXT NULL,
+    tags            TEXT NULL,
+    related_id      INTEGER NOT NULL DEFAULT 0,
+    priority        INTEGER NOT NULL DEFAULT 0,
+    status          TEXT NOT NULL DEFAULT '',
+    scheduled_at    DATETIME NULL DEFAULT NULL,
+    expire_at       DATETIME NULL DEFAULT NULL,
+    next_run_at     DATETIME NULL DEFAULT NULL,
+    claimed_at      DATETIME NULL DEFAULT NULL,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    last_error      TEXT NULL,
+    transaction_id  INTEGER NOT NULL DEFAULT 0,
+    executed_at     DATETIME NULL DEFAULT NULL,
+    created_at      DATETIME NULL DEFAULT NULL,
+    updated_at      DATETIME NULL DEFAULT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS scheduled_operations_uk_business_id ON scheduled_operations (business_id);
+CREATE INDEX IF NOT EXISTS scheduled_operations_idx_status_next_run ON scheduled_operations (status, next_run_at);
+CREATE INDEX IF NOT EXISTS scheduled_operations_idx_status_claimed ON scheduled_operations (status, claimed_at);
+CREATE INDEX IF NOT EXISTS scheduled_operations_idx_user_id ON scheduled_operations (user_id);
+
+CREATE TABLE IF NOT EXISTS recurring_operations (
+    id                    INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id               INTEGER NOT NULL DEFAULT 0,
+    token_id              INTEGER NOT NULL DEFAULT 0,
+    symbol                TEXT NOT NULL DEFAULT '',
+    amount                NUMERIC NOT NULL DEFAULT 0,
+    fund_type             TEXT NOT NULL DEFAULT '',
+    business_id           TEXT NOT NULL DEFAULT '',
+    reference             TEXT NOT NULL DEFAULT '',
+    description           TEXT NULL,
+    metadata              TEXT NULL,
+    callback              TEXT NULL,
+    related_id            INTEGER NOT NULL DEFAULT 0,
+    priority              INTEGER NOT NULL DEFAULT 0,
+    schedule_type         TEXT NOT NULL DEFAULT '',
+    schedule_spec         TEXT NOT NULL DEFAULT '',
+    total_count           INTEGER NOT NULL DEFAULT 0,
+    remaining_count       INTEGER NOT NULL DEFAULT 0,
+    run_count             INTEGER NOT NULL DEFAULT 0,
+    consecutive_failures  INTEGER NOT NULL DEFAULT 0,
+    status                TEXT NOT NULL DEFAULT '',
+    next_run_at           DATETIME NULL DEFAULT NULL,
+    last_run_at           DATETIME NULL DEFAULT NULL,
+    created_at            DATETIME NULL DEFAULT NULL,
+    updated_at            DATETIME NULL DEFAULT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS recurring_operations_uk_business_id ON recurring_operations (business_id);
+CREATE INDEX IF NOT EXISTS recurring_operations_idx_status_next_run ON recurring_operations (status, next_run_at);
+CREATE INDEX IF NOT EXISTS recurring_operations_idx_user_id ON recurring_operations (user_id);
+
+CREATE TABLE IF NOT EXISTS recurring_runs (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    recurring_id    INTEGER NOT NULL DEFAULT 0,
+    occurrence      INTEGER NOT NULL DEFAULT 0,
+    business_id     TEXT NOT NULL DEFAULT '',
+    planned_at      DATETIME NULL DEFAULT NULL,
+    status          TEXT NOT NULL DEFAULT '',
+    transaction_id  INTEGER NOT NULL DEFAULT 0,
+    error           TEXT NULL,
+    created_at      DATETIME NULL DEFAULT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS recurring_runs_uk_recurring_occurrence ON recurring_runs (recurring_id, occurrence);
+
+CREATE TABLE IF NOT EXISTS batch_transfers (
+    id               INTEGER PRIMARY KEY AUTOINCREMENT,
+    batch_id         TEXT NOT NULL DEFAULT '',
+    from_user_id     INTEGER NOT NULL DEFAULT 0,
+    mode             TEXT NOT NULL DEFAULT '',
+    leg_count        INTEGER NOT NULL DEFAULT 0,
+    succeeded_count  INTEGER NOT NULL DEFAULT 0,
+    failed_count     INTEGER NOT NULL DEFAULT 0,
+    total_amount     NUMERIC NOT NULL DEFAULT 0,
+    report           TEXT NULL,
+    created_at       DATETIME NULL DEFAULT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS batch_transfers_uk_batch_id ON batch_transfers (batch_id);
+
+CREATE TABLE IF NOT EXISTS bulk_payout_jobs (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id          TEXT NOT NULL DEFAULT '',
+    format          TEXT NOT NULL DEFAULT '',
+    checksum        TEXT NOT NULL DEFAULT '',
+    total_rows      INTEGER NOT NULL DEFAULT 0,
+    processed_rows  INTEGER NOT NULL DEFAULT 0,
+    succeeded_rows  INTEGER NOT NULL DEFAULT 0,
+    failed_rows     INTEGER NOT NULL DEFAULT 0,
+    status          TEXT NOT NULL DEFAULT '',
+    created_at      DATETIME NULL DEFAULT NULL,
+    updated_at      DATETIME NULL DEFAULT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS bulk_payout_jobs_uk_job_id ON bulk_payout_jobs (job_id);
+
+CREATE TABLE IF NOT EXISTS bulk_payout_rows (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id          TEXT NOT NULL DEFAULT '',
+    line            INTEGER NOT NULL DEFAULT 0,
+    user_id         INTEGER NOT NULL DEFAULT 0,
+    symbol          TEXT NOT NULL DEFAULT '',
+    amount          NUMERIC NOT NULL DEFAULT 0,
+    fund_type       TEXT NOT NULL DEFAULT '',
+    business_id     TEXT NOT NULL DEFAULT '',
+    status          TEXT NOT NULL DEFAULT '',
+    transaction_id  INTEGER NOT NULL DEFAULT 0,
+    error           TEXT NULL,
+    created_at      DATETIME NULL DEFAULT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS bulk_payout_rows_uk_job_line ON bulk_payout_rows (job_id, line);
+
+CREATE TABLE IF NOT EXISTS webhook_outbox (
+    id                INTEGER PRIMARY KEY AUTOINCREMENT,
+    event             TEXT NOT NULL DEFAULT '',
+    business_id       TEXT NOT NULL DEFAULT '',
+    url               TEXT NOT NULL DEFAULT '',
+    method            TEXT NOT NULL DEFAULT '',
+    payload           TEXT NULL,
+    status            TEXT NOT NULL DEFAULT '',
+    attempts          INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at   DATETIME NULL DEFAULT NULL,
+    last_status_code  INTEGER NOT NULL DEFAULT 0,
+    last_error        TEXT NULL,
+    delivered_at      DATETIME NULL DEFAULT NULL,
+    created_at        DATETIME NULL DEFAULT NULL,
+    updated_at        DATETIME NULL DEFAULT NULL
+);
+CREATE INDEX IF NOT EXISTS webhook_outbox_idx_status_next_attempt ON webhook_outbox (status, next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS webhook_dead_letters (
+    id                INTEGER PRIMARY KEY AUTOINCREMENT,
+    outbox_id         INTEGER NOT NULL DEFAULT 0,
+    event             TEXT NOT NULL DEFAULT '',
+    business_id       TEXT NOT NULL DEFAULT '',
+    url               TEXT NOT NULL DEFAULT '',
+    method            TEXT NOT NULL DEFAULT '',
+    payload           TEXT NULL,
+    attempts          INTEGER NOT NULL DEFAULT 0,
+    last_status_code  INTEGER NOT NULL DEFAULT 0,
+    last_error        TEXT NULL,
+    created_at        DATETIME NULL DEFAULT NULL
+);
+
+CREATE TABLE IF NOT EXISTS domain_events (
+    id               INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_key        TEXT NOT NULL DEFAULT '',
+    event_type       TEXT NOT NULL DEFAULT '',
+    aggregate_id     TEXT NOT NULL DEFAULT '',
+    sequence         INTEGER NOT NULL DEFAULT 0,
+    user_id          INTEGER NOT NULL DEFAULT 0,
+    symbol           TEXT NOT NULL DEFAULT '',
+    business_id      TEXT NOT NULL DEFAULT '',
+    payload          TEXT NULL,
+    status           TEXT NOT NULL DEFAULT '',
+    attempts         INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at  DATETIME NULL DEFAULT NULL,
+    last_error       TEXT NULL,
+    published_at     DATETIME NULL DEFAULT NULL,
+    created_at       DATETIME NULL DEFAULT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS domain_events_uk_event_key ON domain_events (event_key);
+CREATE UNIQUE INDEX IF NOT EXISTS domain_events_uk_aggregate_sequence ON domain_events (aggregate_id, sequence);
+CREATE INDEX IF NOT EXISTS domain_events_idx_status ON domain_events (status);
+CREATE INDEX IF NOT EXISTS domain_events_idx_user_id ON domain_events (user_id, id);
+
+CREATE TABLE IF NOT EXISTS balance_snapshots (
+    id                   INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id              INTEGER NOT NULL DEFAULT 0,
+    symbol               TEXT NOT NULL DEFAULT '',
+    snapshot_date        DATE NULL DEFAULT NULL,
+    balance              NUMERIC NOT NULL DEFAULT 0,
+    last_transaction_id  INTEGER NOT NULL DEFAULT 0,
+    created_at           DATETIME NULL DEFAULT NULL,
+    updated_at           DATETIME NULL DEFAULT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS balance_snapshots_uk_user_symbol_date ON balance_snapshots (user_id, symbol, snapshot_date);
+CREATE INDEX IF NOT EXISTS balance_snapshots_idx_snapshot_date ON balance_snapshots (snapshot_date);
+
+CREATE TABLE IF NOT EXISTS audit_entries (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    chain_id    TEXT NOT NULL DEFAULT '',
+    sequence    INTEGER NOT NULL DEFAULT 0,
+    entry_type  TEXT NOT NULL DEFAULT '',
+    ref_id      INTEGER NOT NULL DEFAULT 0,
+    payload     TEXT NULL,
+    prev_hash   TEXT NOT NULL DEFAULT '',
+    hash        TEXT NOT NULL DEFAULT '',
+    created_at  DATETIME NULL DEFAULT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS audit_entries_uk_chain_sequence ON audit_entries (chain_id, sequence);
+
+CREATE TABLE IF NOT EXISTS audit_checkpoints (
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    last_entry_id  INTEGER NOT NULL DEFAULT 0,
+    entry_count    INTEGER NOT NULL DEFAULT 0,
+    prev_root      TEXT NOT NULL DEFAULT '',
+    root           TEXT NOT NULL DEFAULT '',
+    public_key     TEXT NOT NULL DEFAULT '',
+    signature      TEXT NOT NULL DEFAULT '',
+    created_at     DATETIME NULL DEFAULT NULL
+);
+
+CREATE TABLE IF NOT EXISTS adjustment_requests (
+    id                  INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id             INTEGER NOT NULL DEFAULT 0,
+    symbol              TEXT NOT NULL DEFAULT '',
+    fund_type           TEXT NOT NULL DEFAULT '',
+    direction           TEXT NOT NULL DEFAULT '',
+    amount              NUMERIC NOT NULL DEFAULT 0,
+    reason              TEXT NULL,
+    attachment_ref      TEXT NOT NULL DEFAULT '',
+    metadata            TEXT NULL,
+    required_approvals  INTEGER NOT NULL DEFAULT 0,
+    approval_count      INTEGER NOT NULL DEFAULT 0,
+    status              TEXT NOT NULL DEFAULT '',
+    submitted_by        TEXT NOT NULL DEFAULT '',
+    transaction_id      INTEGER NOT NULL DEFAULT 0,
+    created_at          DATETIME NULL DEFAULT NULL,
+    updated_at          DATETIME NULL DEFAULT NULL,
+    completed_at        DATETIME NULL DEFAULT NULL
+);
+CREATE INDEX IF NOT EXISTS adjustment_requests_idx_status ON adjustment_requests (status);
+
+CREATE TABLE IF NOT EXISTS adjustment_actions (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    request_id  INTEGER NOT NULL DEFAULT 0,
+    admin_id    TEXT NOT NULL DEFAULT '',
+    action      TEXT NOT NULL DEFAULT '',
+    comment     TEXT NULL,
+    created_at  DATETIME NULL DEFAULT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS adjustment_actions_uk_request_admin ON adjustment_actions (request_id, admin_id);
+
+CREATE TABLE IF NOT EXISTS commission_records (
+    id                     INTEGER PRIMARY KEY AUTOINCREMENT,
+    source_transaction_id  INTEGER NOT NULL DEFAULT 0,
+    source_user_id         INTEGER NOT NULL DEFAULT 0,
+    source_fund_type       TEXT NOT NULL DEFAULT '',
+    source_amount          NUMERIC NOT NULL DEFAULT 0,
+    symbol                 TEXT NOT NULL DEFAULT '',
+    beneficiary_id         INTEGER NOT NULL DEFAULT 0,
+    level                  INTEGER NOT NULL DEFAULT 0,
+    chain                  TEXT NOT NULL DEFAULT '',
+    rate                   NUMERIC NOT NULL DEFAULT 0,
+    amount                 NUMERIC NOT NULL DEFAULT 0,
+    fund_type              TEXT NOT NULL DEFAULT '',
+    transaction_id         INTEGER NOT NULL DEFAULT 0,
+    created_at             DATETIME NULL DEFAULT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS commission_records_uk_source_level ON commission_records (source_transaction_id, level);
+CREATE INDEX IF NOT EXISTS commission_records_idx_beneficiary ON commission_records (beneficiary_id, symbol, created_at);
+
+CREATE TABLE IF NOT EXISTS velocity_counters (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id         INTEGER NOT NULL DEFAULT 0,
+    symbol          TEXT NOT NULL DEFAULT '',
+    fund_type       TEXT NOT NULL DEFAULT '',
+    request_source  TEXT NOT NULL DEFAULT '',
+    bucket_start    INTEGER NOT NULL DEFAULT 0,
+    tx_count        INTEGER NOT NULL DEFAULT 0,
+    amount          NUMERIC NOT NULL DEFAULT 0,
+    updated_at      DATETIME NULL DEFAULT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS velocity_counters_uk_counter ON velocity_counters (user_id, symbol, fund_type, request_source, bucket_start);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    key_id        TEXT NOT NULL DEFAULT '',
+    name          TEXT NOT NULL DEFAULT '',
+    secret_hash   TEXT NOT NULL DEFAULT '',
+    permissions   TEXT NULL,
+    fund_types    TEXT NULL,
+    tokens        TEXT NULL,
+    user_ids      TEXT NULL,
+    max_amount    NUMERIC NOT NULL DEFAULT 0,
+    rate_limit    INTEGER NOT NULL DEFAULT 0,
+    status        INTEGER NOT NULL DEFAULT 1,
+    expires_at    DATETIME NULL DEFAULT NULL,
+    last_used_at  DATETIME NULL DEFAULT NULL,
+    created_at    DATETIME NULL DEFAULT NULL,
+    revoked_at    DATETIME NULL DEFAULT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS api_keys_uk_key_id ON api_keys (key_id);
+
+CREATE TABLE IF NOT EXISTS api_key_nonces (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    key_id      TEXT NOT NULL DEFAULT '',
+    nonce       TEXT NOT NULL DEFAULT '',
+    created_at  DATETIME NULL DEFAULT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS api_key_nonces_uk_key_nonce ON api_key_nonces (key_id, nonce);
+CREATE INDEX IF NOT EXISTS api_key_nonces_idx_created_at ON api_key_nonces (created_at);
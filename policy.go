@@ -0,0 +1,58 @@
+package wallet
+
+import (
+	"context"
+
+	"github.com/yalks/wallet/logic"
+)
+
+// PolicyAction 策略规则命中后的动作
+type PolicyAction = logic.PolicyAction
+
+const (
+	PolicyActionDeny            = logic.PolicyActionDeny            // 拒绝操作
+	PolicyActionRequireApproval = logic.PolicyActionRequireApproval // 操作需要审批
+)
+
+// PolicyRuleSpec 策略规则配置
+type PolicyRuleSpec = logic.PolicyRuleSpec
+
+// PolicyRule 已解析的策略规则
+type PolicyRule = logic.PolicyRule
+
+// PolicyInput 策略规则评估的操作
+type PolicyInput = logic.PolicyInput
+
+// PolicyError 操作被策略拒绝或需要审批，Rule 为生效的规则
+type PolicyError = logic.PolicyError
+
+// PolicyDryRunRequest 策略试运行请求
+type PolicyDryRunRequest = logic.PolicyDryRunRequest
+
+// PolicyDryRunReport 策略试运行报告
+type PolicyDryRunReport = logic.PolicyDryRunReport
+
+// PolicyRuleStats 试运行中单条规则的命中统计
+type PolicyRuleStats = logic.PolicyRuleStats
+
+// 策略错误码，可通过 gerror.Code(err) 识别
+var (
+	CodePolicyDenied           = logic.CodePolicyDenied
+	CodePolicyApprovalRequired = logic.CodePolicyApprovalRequired
+)
+
+// ParsePolicyRule 解析一条策略规则，可用于在写入配置前校验规则文本
+func ParsePolicyRule(name, text string) (*PolicyRule, error) {
+	return logic.ParsePolicyRule(name, text)
+}
+
+// WithOperationApproved 标记操作已在业务系统中审批；命中 require approval 策略的操作需要携带该标记执行。
+// 通过 ApproveAdjustment 执行的调账会自动携带该标记
+func WithOperationApproved(ctx context.Context) context.Context {
+	return logic.WithOperationApproved(ctx)
+}
+
+// DryRunPolicies 用历史交易试运行策略规则，统计每条规则会命中的交易
+func (m *walletManager) DryRunPolicies(ctx context.Context, req *PolicyDryRunRequest) (*PolicyDryRunReport, error) {
+	return m.policyLogic.DryRun(ctx, req)
+}
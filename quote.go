@@ -0,0 +1,115 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/constants"
+	"github.com/yalks/wallet/logic"
+)
+
+// FundOperationQuote 资金操作预估结果，不变动余额
+type FundOperationQuote struct {
+	UserID        uint64                  `json:"user_id"`               // 用户ID
+	TokenSymbol   string                  `json:"token_symbol"`          // 代币符号
+	FundType      constants.FundType      `json:"fund_type"`             // 资金类型
+	Direction     constants.FundDirection `json:"direction"`             // 解析后的资金方向
+	Amount        decimal.Decimal         `json:"amount"`                // 操作金额
+	RawAmount     decimal.Decimal         `json:"raw_amount"`            // 原始金额（最小单位）
+	BalanceBefore decimal.Decimal         `json:"balance_before"`        // 当前可用余额
+	BalanceAfter  decimal.Decimal         `json:"balance_after"`         // 执行后的可用余额
+	Sufficient    bool                    `json:"sufficient"`            // 余额是否充足（入账总是充足）
+	Allowed       bool                    `json:"allowed"`               // 是否预计可以执行
+	PolicyRule    string                  `json:"policy_rule,omitempty"` // 命中的策略规则
+	Reason        string                  `json:"reason,omitempty"`      // 预计无法执行的原因
+}
+
+// QuoteFundOperation 预估资金操作：解析方向，校验资金类型限制和策略规则，计算执行后的余额。
+// 风控评分和交易限额只在执行时评估，不在预估结果中
+func (m *walletManager) QuoteFundOperation(ctx context.Context, req *constants.FundOperationRequest) (*FundOperationQuote, error) {
+	if req == nil {
+		return nil, gerror.New("资金操作请求不能为空")
+	}
+	if req.UserID == 0 {
+		return nil, gerror.New("用户ID不能为空")
+	}
+	if req.TokenSymbol == "" {
+		return nil, gerror.New("代币符号不能为空")
+	}
+	if req.Amount.LessThanOrEqual(decimal.Zero) {
+		return nil, gerror.New("金额必须大于0")
+	}
+	if !constants.IsValidFundType(req.FundType) {
+		return nil, gerror.Newf("无效的资金类型: %s", req.FundType)
+	}
+	direction, err := constants.ResolveFundDirection(req.FundType, req.Direction)
+	if err != nil {
+		return nil, gerror.Wrap(err, "资金方向无效")
+	}
+	if _, err := m.userLogic.GetUserByID(ctx, req.UserID); err != nil {
+		return nil, gerror.Wrapf(err, "获取用户信息失败: UserID=%d", req.UserID)
+	}
+	rawAmount, err := m.tokenLogic.ConvertBalanceToRaw(ctx, req.Amount, req.TokenSymbol)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "转换金额到原始格式失败: Amount=%s, Symbol=%s", req.Amount.String(), req.TokenSymbol)
+	}
+	balance, err := m.GetBalance(ctx, req.UserID, req.TokenSymbol)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := &FundOperationQuote{
+		UserID:        req.UserID,
+		TokenSymbol:   req.TokenSymbol,
+		FundType:      req.FundType,
+		Direction:     direction,
+		Amount:        req.Amount,
+		RawAmount:     rawAmount,
+		BalanceBefore: balance.AvailableBalance,
+		BalanceAfter:  balance.AvailableBalance.Add(req.Amount),
+		Sufficient:    true,
+		Allowed:       true,
+	}
+	if direction == constants.FundDirectionOut {
+		quote.BalanceAfter = balance.AvailableBalance.Sub(req.Amount)
+		quote.Sufficient = !balance.AvailableBalance.LessThan(req.Amount)
+	}
+
+	source := req.RequestSource
+	if source == "" {
+		source = logic.ExtractRequestContext(ctx).Source
+	}
+	if err := constants.ValidateFundTypeUsage(req.FundType, source, req.Amount); err != nil {
+		quote.Allowed, quote.Reason = false, err.Error()
+		return quote, nil
+	}
+	metadata := make(map[string]string)
+	for k, v := range logic.ExtractRequestContext(ctx).Metadata {
+		metadata[k] = v
+	}
+	for k, v := range req.Metadata {
+		metadata[k] = v
+	}
+	err = m.policyLogic.Check(ctx, &logic.PolicyInput{
+		UserID:        req.UserID,
+		TokenSymbol:   req.TokenSymbol,
+		FundType:      req.FundType,
+		Direction:     direction,
+		Amount:        req.Amount,
+		RequestSource: source,
+		Metadata:      metadata,
+	})
+	var policyErr *PolicyError
+	switch {
+	case errors.As(err, &policyErr):
+		quote.Allowed, quote.PolicyRule, quote.Reason = false, policyErr.Rule.Name, policyErr.Error()
+	case err != nil:
+		return nil, err
+	case !quote.Sufficient:
+		quote.Allowed, quote.Reason = false, "余额不足"
+	}
+	return quote, nil
+}
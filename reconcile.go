@@ -0,0 +1,196 @@
+package wallet
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/entity"
+	"github.com/yalks/wallet/logic"
+)
+
+// reconcileBatchSize 对账时每次读取的钱包数
+const reconcileBatchSize = 500
+
+// ReconcileRequest 对账请求；UserID 和 TokenSymbol 为空时检查全部钱包
+type ReconcileRequest struct {
+	UserID      uint64 `json:"user_id,omitempty"`      // 只检查该用户的钱包
+	TokenSymbol string `json:"token_symbol,omitempty"` // 只检查该代币的钱包
+	VerifyAudit bool   `json:"verify_audit"`           // 同时校验每个钱包的审计哈希链
+}
+
+// BalanceMismatch 钱包余额与账本不一致
+type BalanceMismatch struct {
+	UserID            uint64          `json:"user_id"`             // 用户ID
+	TokenSymbol       string          `json:"token_symbol"`        // 代币符号
+	WalletBalance     decimal.Decimal `json:"wallet_balance"`      // 钱包表中的可用余额
+	LedgerBalance     decimal.Decimal `json:"ledger_balance"`      // 最后一笔成功交易的操作后余额，没有交易时为 0
+	Difference        decimal.Decimal `json:"difference"`          // WalletBalance - LedgerBalance
+	LastTransactionID uint64          `json:"last_transaction_id"` // 最后一笔成功交易ID
+}
+
+// ReconciliationReport 对账报告
+type ReconciliationReport struct {
+	Checked       int                        `json:"checked"`                  // 检查的钱包数
+	Mismatches    []*BalanceMismatch         `json:"mismatches"`               // 余额不一致的钱包
+	AuditFailures []*AuditVerificationReport `json:"audit_failures,omitempty"` // 审计哈希链断裂的钱包
+	StartedAt     string                     `json:"started_at"`               // 开始时间
+	FinishedAt    string                     `json:"finished_at"`              // 结束时间
+}
+
+// OK 是否全部一致
+func (r *ReconciliationReport) OK() bool {
+	return len(r.Mismatches) == 0 && len(r.AuditFailures) == 0
+}
+
+// ListUserBalances 获取用户全部钱包的余额，不会创建钱包
+func (m *walletManager) ListUserBalances(ctx context.Context, userID uint64) ([]*BalanceInfo, error) {
+	wallets, err := logic.GetSharedContext().GetWalletDAO().GetUserWallets(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([]*BalanceInfo, 0, len(wallets))
+	for _, wallet := range wallets {
+		available, err := m.tokenLogic.ConvertDBStorageToBalance(ctx, wallet.AvailableBalance, wallet.Symbol)
+		if err != nil {
+			return nil, gerror.Wrapf(err, "转换可用余额失败: UserID=%d, Symbol=%s", userID, wallet.Symbol)
+		}
+		frozen, err := m.tokenLogic.ConvertDBStorageToBalance(ctx, wallet.FrozenBalance, wallet.Symbol)
+		if err != nil {
+			return nil, gerror.Wrapf(err, "转换冻结余额失败: UserID=%d, Symbol=%s", userID, wallet.Symbol)
+		}
+		info := &BalanceInfo{
+			UserID:           userID,
+			TokenSymbol:      wallet.Symbol,
+			AvailableBalance: available,
+			FrozenBalance:    frozen,
+			TotalBalance:     available.Add(frozen),
+		}
+		if wallet.UpdatedAt != nil {
+			info.LastSyncTime = wallet.UpdatedAt.Format("Y-m-d H:i:s")
+		}
+		balances = append(balances, info)
+	}
+	return balances, nil
+}
+
+// Reconcile 对账：逐个钱包比较钱包表中的可用余额与账本中最后一笔成功交易的操作后余额。
+// 不一致的钱包会重新读取一次再确认，避免把进行中的操作误报为差异；可选同时校验审计哈希链
+func (m *walletManager) Reconcile(ctx context.Context, req *ReconcileRequest) (*ReconciliationReport, error) {
+	if req == nil {
+		req = &ReconcileRequest{}
+	}
+	report := &ReconciliationReport{
+		Mismatches: []*BalanceMismatch{},
+		StartedAt:  time.Now().Format(time.RFC3339),
+	}
+
+	check := func(wallets []*entity.Wallets) error {
+		for _, wallet := range wallets {
+			if req.TokenSymbol != "" && !strings.EqualFold(wallet.Symbol, req.TokenSymbol) {
+				continue
+			}
+			report.Checked++
+			mismatch, err := m.reconcileWallet(ctx, uint64(wallet.UserId), wallet.Symbol, wallet)
+			if err != nil {
+				return err
+			}
+			if mismatch != nil {
+				g.Log().Warningf(ctx, "对账发现余额差异: UserID=%d, Symbol=%s, Wallet=%s, Ledger=%s",
+					mismatch.UserID, mismatch.TokenSymbol, mismatch.WalletBalance.String(), mismatch.LedgerBalance.String())
+				report.Mismatches = append(report.Mismatches, mismatch)
+			}
+			if req.VerifyAudit {
+				audit, err := m.VerifyAuditChain(ctx, uint64(wallet.UserId), wallet.Symbol)
+				if err != nil {
+					return err
+				}
+				if !audit.Valid {
+					report.AuditFailures = append(report.AuditFailures, audit)
+				}
+			}
+		}
+		return nil
+	}
+
+	walletDAO := logic.GetSharedContext().GetWalletDAO()
+	if req.UserID != 0 {
+		wallets, err := walletDAO.GetUserWallets(ctx, req.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if err := check(wallets); err != nil {
+			return nil, err
+		}
+	} else {
+		afterWalletID := 0
+		for {
+			wallets, err := walletDAO.ListWalletsAfter(ctx, afterWalletID, reconcileBatchSize)
+			if err != nil {
+				return nil, err
+			}
+			if err := check(wallets); err != nil {
+				return nil, err
+			}
+			if len(wallets) < reconcileBatchSize {
+				break
+			}
+			afterWalletID = wallets[len(wallets)-1].WalletId
+		}
+	}
+
+	report.FinishedAt = time.Now().Format(time.RFC3339)
+	g.Log().Infof(ctx, "对账完成: Checked=%d, Mismatches=%d, AuditFailures=%d", report.Checked, len(report.Mismatches), len(report.AuditFailures))
+	return report, nil
+}
+
+// reconcileWallet 比较单个钱包的余额与账本，不一致时重新读取一次再确认
+func (m *walletManager) reconcileWallet(ctx context.Context, userID uint64, symbol string, wallet *entity.Wallets) (*BalanceMismatch, error) {
+	for attempt := 0; ; attempt++ {
+		mismatch, err := m.compareWalletWithLedger(ctx, userID, symbol, wallet)
+		if err != nil || mismatch == nil || attempt > 0 {
+			return mismatch, err
+		}
+		wallet, err = logic.GetSharedContext().GetWalletDAO().GetWalletByUserIDAndSymbol(ctx, userID, symbol)
+		if err != nil {
+			return nil, err
+		}
+		if wallet == nil {
+			return nil, nil
+		}
+	}
+}
+
+// compareWalletWithLedger 比较钱包可用余额与最后一笔成功交易的操作后余额
+func (m *walletManager) compareWalletWithLedger(ctx context.Context, userID uint64, symbol string, wallet *entity.Wallets) (*BalanceMismatch, error) {
+	walletBalance, err := m.tokenLogic.ConvertDBStorageToBalance(ctx, wallet.AvailableBalance, symbol)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "转换可用余额失败: UserID=%d, Symbol=%s", userID, symbol)
+	}
+	last, err := logic.GetSharedContext().GetTransactionDAO().GetLastTransactionBefore(ctx, userID, symbol, gtime.Now().Add(time.Minute))
+	if err != nil {
+		return nil, err
+	}
+
+	mismatch := &BalanceMismatch{
+		UserID:        userID,
+		TokenSymbol:   symbol,
+		WalletBalance: walletBalance,
+		LedgerBalance: decimal.Zero,
+	}
+	if last != nil {
+		mismatch.LedgerBalance = last.BalanceAfter
+		mismatch.LastTransactionID = last.TransactionId
+	}
+	if mismatch.WalletBalance.Equal(mismatch.LedgerBalance) {
+		return nil, nil
+	}
+	mismatch.Difference = mismatch.WalletBalance.Sub(mismatch.LedgerBalance)
+	return mismatch, nil
+}
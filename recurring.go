@@ -0,0 +1,587 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/constants"
+	"github.com/yalks/wallet/entity"
+	"github.com/yalks/wallet/logic"
+)
+
+// RecurringStatus 循环操作状态
+type RecurringStatus string
+
+const (
+	RecurringStatusActive    RecurringStatus = "active"    // 运行中
+	RecurringStatusPaused    RecurringStatus = "paused"    // 已暂停
+	RecurringStatusCancelled RecurringStatus = "cancelled" // 已取消
+	RecurringStatusCompleted RecurringStatus = "completed" // 已完成（执行次数用尽）
+)
+
+// RecurringConfig 循环操作配置（对应配置项 wallet.recurring）
+type RecurringConfig struct {
+	BatchSize              int `json:"batchSize"`              // 每次扫描处理的最大数量
+	MaxConsecutiveFailures int `json:"maxConsecutiveFailures"` // 连续失败达到该次数后自动暂停
+}
+
+// DefaultRecurringConfig 默认循环操作配置
+func DefaultRecurringConfig() RecurringConfig {
+	return RecurringConfig{
+		BatchSize:              50,
+		MaxConsecutiveFailures: 3,
+	}
+}
+
+// errRecurringAdvanced 循环操作已被其他实例推进
+var errRecurringAdvanced = gerror.New("循环操作已被其他实例处理")
+
+// recurringEngine 循环操作执行引擎
+type recurringEngine struct {
+	manager *walletManager
+	context *logic.SharedLogicContext
+	config  RecurringConfig
+}
+
+// newRecurringEngine 创建循环操作执行引擎
+func newRecurringEngine(ctx context.Context, manager *walletManager) *recurringEngine {
+	return &recurringEngine{
+		manager: manager,
+		context: logic.GetSharedContext(),
+		config:  loadRecurringConfig(ctx),
+	}
+}
+
+// loadRecurringConfig 从配置中读取循环操作配置，缺省项使用默认值
+func loadRecurringConfig(ctx context.Context) RecurringConfig {
+	config := DefaultRecurringConfig()
+
+	value, err := g.Cfg().Get(ctx, "wallet.recurring")
+	if err != nil || value == nil || value.IsEmpty() {
+		return config
+	}
+
+	raw := value.MapStrVar()
+	if v, ok := raw["batchSize"]; ok && v.Int() > 0 {
+		config.BatchSize = v.Int()
+	}
+	if v, ok := raw["maxConsecutiveFailures"]; ok && v.Int() > 0 {
+		config.MaxConsecutiveFailures = v.Int()
+	}
+
+	return config
+}
+
+// CreateRecurringTransaction 创建循环交易（由 constants.BuildRecurringTransaction 或 BuildRecurringCronTransaction 构建）
+func (m *walletManager) CreateRecurringTransaction(ctx context.Context, req *constants.TransactionRequestEnhanced) (*RecurringOperationInfo, error) {
+	if req == nil {
+		return nil, gerror.New("循环交易请求不能为空")
+	}
+	if err := m.adjustments.authorize(ctx, req.FundType); err != nil {
+		return nil, err
+	}
+	if _, err := constants.ResolveFundDirection(req.FundType, req.Direction); err != nil {
+		return nil, gerror.Wrap(err, "资金方向无效")
+	}
+	if info, _ := constants.GetFundTypeInfo(req.FundType); !info.HasFlag(constants.FundTypeFlagSchedulable) {
+		return nil, gerror.Newf("资金类型不支持定时或循环执行: %s", req.FundType)
+	}
+
+	// 1. 解析调度规则
+	scheduleType, scheduleSpec, err := parseRecurringSpec(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := logic.ParseRecurringSchedule(scheduleType, scheduleSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	totalCount := 0
+	if v, ok := req.Metadata["recurring_count"]; ok {
+		totalCount, err = strconv.Atoi(fmt.Sprintf("%v", v))
+		if err != nil || totalCount < 0 {
+			return nil, gerror.Newf("无效的执行次数: %v", v)
+		}
+	}
+
+	start := time.Now()
+	if v, ok := req.Metadata["recurring_start_at"]; ok {
+		start, err = parseScheduledAt(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	firstRunAt := schedule.First(start)
+	if firstRunAt.IsZero() {
+		return nil, gerror.Newf("调度规则没有可执行的时间: %s", scheduleSpec)
+	}
+
+	// 2. 校验金额与代币
+	amount, err := decimal.NewFromString(req.Amount)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "无效的金额格式: %s", req.Amount)
+	}
+
+	token, err := m.tokenLogic.GetTokenByID(ctx, uint(req.TokenID))
+	if err != nil {
+		return nil, gerror.Wrapf(err, "获取代币信息失败: TokenID=%d", req.TokenID)
+	}
+
+	// 3. 幂等：同一请求重复提交只创建一条循环操作
+	idempotencyKey := req.IdempotencyKey
+	if idempotencyKey == "" {
+		idempotencyKey = req.Reference
+	}
+	businessID := "recurring_" + idempotencyKey
+
+	dao := m.recurring.context.GetRecurringOperationDAO()
+	existing, err := dao.GetRecurringOperationByBusinessID(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if err := checkRecurringOperationOwner(existing, uint64(req.UserID)); err != nil {
+			return nil, err
+		}
+		g.Log().Infof(ctx, "循环操作已存在，返回现有记录: BusinessID=%s, ID=%d", businessID, existing.Id)
+		return convertToRecurringOperationInfo(existing), nil
+	}
+
+	metadata, err := encodeOperationMetadata(req.Metadata, req.Direction)
+	if err != nil {
+		return nil, err
+	}
+
+	now := gtime.Now()
+	op := &entity.RecurringOperations{
+		UserId:         uint(req.UserID),
+		TokenId:        token.TokenId,
+		Symbol:         token.Symbol,
+		Amount:         amount,
+		FundType:       string(req.FundType),
+		BusinessId:     businessID,
+		Reference:      req.Reference,
+		Description:    req.Description,
+		Metadata:       metadata,
+		Callback:       encodeCallback(req.Callback),
+		RelatedId:      req.RelatedID,
+		Priority:       req.Priority,
+		ScheduleType:   scheduleType,
+		ScheduleSpec:   scheduleSpec,
+		TotalCount:     totalCount,
+		RemainingCount: totalCount,
+		Status:         string(RecurringStatusActive),
+		NextRunAt:      gtime.New(firstRunAt),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	id, err := dao.CreateRecurringOperation(ctx, nil, op)
+	if err != nil {
+		return nil, gerror.Wrap(err, "保存循环操作失败")
+	}
+	op.Id = id
+
+	g.Log().Infof(ctx, "循环操作创建成功: ID=%d, UserID=%d, FundType=%s, Amount=%s %s, Schedule=%s(%s), Count=%d, FirstRunAt=%s",
+		id, req.UserID, req.FundType, amount.String(), token.Symbol, scheduleType, scheduleSpec, totalCount, firstRunAt.Format(time.RFC3339))
+
+	return convertToRecurringOperationInfo(op), nil
+}
+
+// checkRecurringOperationOwner 幂等键对应的循环操作属于其他用户时返回 CodeIdempotencyConflict 错误，
+// 避免向调用方返回其他用户的记录
+func checkRecurringOperationOwner(existing *entity.RecurringOperations, userID uint64) error {
+	if uint64(existing.UserId) != userID {
+		return gerror.NewCodef(logic.CodeIdempotencyConflict, "幂等键已被其他用户的循环操作使用: BusinessID=%s", existing.BusinessId)
+	}
+	return nil
+}
+
+// ListRecurringTransactions 获取用户循环交易列表
+func (m *walletManager) ListRecurringTransactions(ctx context.Context, userID uint64, status RecurringStatus, limit, offset int) ([]*RecurringOperationInfo, error) {
+	ops, err := m.recurring.context.GetRecurringOperationDAO().ListRecurringOperations(ctx, userID, string(status), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*RecurringOperationInfo, 0, len(ops))
+	for _, op := range ops {
+		infos = append(infos, convertToRecurringOperationInfo(op))
+	}
+	return infos, nil
+}
+
+// ListRecurringRuns 获取循环交易的执行历史
+func (m *walletManager) ListRecurringRuns(ctx context.Context, userID uint64, recurringID uint64, limit, offset int) ([]*RecurringRunInfo, error) {
+	if _, err := m.recurring.getOwned(ctx, userID, recurringID); err != nil {
+		return nil, err
+	}
+
+	runs, err := m.recurring.context.GetRecurringOperationDAO().ListRecurringRuns(ctx, recurringID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*RecurringRunInfo, 0, len(runs))
+	for _, run := range runs {
+		infos = append(infos, convertToRecurringRunInfo(run))
+	}
+	return infos, nil
+}
+
+// PauseRecurringTransaction 暂停循环交易
+func (m *walletManager) PauseRecurringTransaction(ctx context.Context, userID uint64, recurringID uint64) error {
+	op, err := m.recurring.getOwned(ctx, userID, recurringID)
+	if err != nil {
+		return err
+	}
+
+	paused, err := m.recurring.context.GetRecurringOperationDAO().UpdateRecurringOperationIfStatus(ctx, nil, recurringID, string(RecurringStatusActive), map[string]any{
+		"status": string(RecurringStatusPaused),
+	})
+	if err != nil {
+		return err
+	}
+	if !paused {
+		return gerror.Newf("循环操作当前状态不可暂停: ID=%d, Status=%s", recurringID, op.Status)
+	}
+
+	g.Log().Infof(ctx, "循环操作已暂停: ID=%d, UserID=%d", recurringID, userID)
+	return nil
+}
+
+// ResumeRecurringTransaction 恢复已暂停的循环交易，暂停期间错过的执行不会补执行
+func (m *walletManager) ResumeRecurringTransaction(ctx context.Context, userID uint64, recurringID uint64) error {
+	op, err := m.recurring.getOwned(ctx, userID, recurringID)
+	if err != nil {
+		return err
+	}
+
+	schedule, err := logic.ParseRecurringSchedule(op.ScheduleType, op.ScheduleSpec)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	nextRunAt := now
+	if op.NextRunAt != nil {
+		nextRunAt = op.NextRunAt.Time
+		if !nextRunAt.After(now) {
+			nextRunAt = schedule.NextAfter(nextRunAt, now)
+		}
+	}
+	if nextRunAt.IsZero() {
+		return gerror.Newf("调度规则没有可执行的时间: ID=%d", recurringID)
+	}
+
+	resumed, err := m.recurring.context.GetRecurringOperationDAO().UpdateRecurringOperationIfStatus(ctx, nil, recurringID, string(RecurringStatusPaused), map[string]any{
+		"status":               string(RecurringStatusActive),
+		"next_run_at":          gtime.New(nextRunAt),
+		"consecutive_failures": 0,
+	})
+	if err != nil {
+		return err
+	}
+	if !resumed {
+		return gerror.Newf("循环操作当前状态不可恢复: ID=%d, Status=%s", recurringID, op.Status)
+	}
+
+	g.Log().Infof(ctx, "循环操作已恢复: ID=%d, UserID=%d, NextRunAt=%s", recurringID, userID, nextRunAt.Format(time.RFC3339))
+	return nil
+}
+
+// CancelRecurringTransaction 取消循环交易（运行中或已暂停均可取消）
+func (m *walletManager) CancelRecurringTransaction(ctx context.Context, userID uint64, recurringID uint64) error {
+	op, err := m.recurring.getOwned(ctx, userID, recurringID)
+	if err != nil {
+		return err
+	}
+
+	dao := m.recurring.context.GetRecurringOperationDAO()
+	for _, status := range []RecurringStatus{RecurringStatusActive, RecurringStatusPaused} {
+		cancelled, err := dao.UpdateRecurringOperationIfStatus(ctx, nil, recurringID, string(status), map[string]any{
+			"status": string(RecurringStatusCancelled),
+		})
+		if err != nil {
+			return err
+		}
+		if cancelled {
+			g.Log().Infof(ctx, "循环操作已取消: ID=%d, UserID=%d", recurringID, userID)
+			return nil
+		}
+	}
+
+	return gerror.Newf("循环操作当前状态不可取消: ID=%d, Status=%s", recurringID, op.Status)
+}
+
+// RunDueRecurringTransactions 执行所有已到期的循环交易，返回本次执行的数量
+func (m *walletManager) RunDueRecurringTransactions(ctx context.Context) (int, error) {
+	return m.recurring.runDue(ctx)
+}
+
+// getOwned 获取属于指定用户的循环操作
+func (e *recurringEngine) getOwned(ctx context.Context, userID uint64, recurringID uint64) (*entity.RecurringOperations, error) {
+	op, err := e.context.GetRecurringOperationDAO().GetRecurringOperationByID(ctx, recurringID)
+	if err != nil {
+		return nil, err
+	}
+	if op == nil || uint64(op.UserId) != userID {
+		return nil, gerror.Newf("循环操作不存在: UserID=%d, ID=%d", userID, recurringID)
+	}
+	return op, nil
+}
+
+// runDue 扫描并执行到期的循环操作
+func (e *recurringEngine) runDue(ctx context.Context) (int, error) {
+	ops, err := e.context.GetRecurringOperationDAO().ListDueRecurringOperations(ctx, gtime.Now(), e.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, op := range ops {
+		if e.execute(ctx, op) {
+			processed++
+		}
+	}
+	return processed, nil
+}
+
+// execute 执行一次循环操作并推进调度，返回本实例是否执行了该次操作
+func (e *recurringEngine) execute(ctx context.Context, op *entity.RecurringOperations) bool {
+	dao := e.context.GetRecurringOperationDAO()
+
+	schedule, err := logic.ParseRecurringSchedule(op.ScheduleType, op.ScheduleSpec)
+	if err != nil {
+		g.Log().Errorf(ctx, "循环操作调度规则无效: ID=%d, Error=%v", op.Id, err)
+		return false
+	}
+
+	// 1. 计算本次执行的派生业务ID与下次执行时间
+	occurrence := op.RunCount + 1
+	businessID := fmt.Sprintf("%s_%d", op.BusinessId, occurrence)
+	plannedAt := op.NextRunAt
+	now := gtime.Now()
+	nextRunAt := schedule.NextAfter(plannedAt.Time, now.Time)
+
+	run := &entity.RecurringRuns{
+		RecurringId: op.Id,
+		Occurrence:  occurrence,
+		BusinessId:  businessID,
+		PlannedAt:   plannedAt,
+		CreatedAt:   now,
+	}
+
+	// 2. 构建资金操作请求
+	builder := constants.NewFundOperationBuilder().
+		WithUser(uint64(op.UserId)).
+		WithTokenSymbol(op.Symbol).
+		WithAmount(op.Amount).
+		WithBusinessID(businessID).
+		WithFundType(constants.FundType(op.FundType)).
+		WithDescription(op.Description).
+		WithRelatedID(op.RelatedId).
+		WithMetadata("recurring_id", fmt.Sprintf("%d", op.Id)).
+		WithMetadata("recurring_occurrence", fmt.Sprintf("%d", occurrence))
+	for k, v := range decodeOperationMetadata(ctx, op.Metadata) {
+		builder.WithMetadata(k, v)
+		if k == constants.MetadataKeyDirection {
+			builder.WithDirection(constants.FundDirection(v))
+		}
+	}
+	req, err := builder.Build()
+
+	// 3. 在同一数据库事务中推进调度、执行资金操作并记录执行结果
+	if err == nil {
+		err = e.manager.RunInTransaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+			success, _ := planRecurringAdvance(op, now, nextRunAt, true, e.config.MaxConsecutiveFailures)
+			advanced, err := dao.AdvanceRecurringOperation(ctx, tx, op.Id, op.RunCount, success)
+			if err != nil {
+				return err
+			}
+			if !advanced {
+				return errRecurringAdvanced
+			}
+
+			result, err := e.manager.ProcessFundOperationInTx(ctx, tx, req)
+			if err != nil {
+				return err
+			}
+
+			run.Status = string(constants.TransactionStatusCompleted)
+			run.TransactionId, _ = strconv.ParseUint(result.TransactionID, 10, 64)
+			if err := dao.CreateRecurringRun(ctx, tx, run); err != nil {
+				return err
+			}
+
+			event := recurringRunEvent(op, run)
+			event.TransactionID = result.TransactionID
+			return enqueueWebhookInTx(ctx, tx, decodeCallback(op.Callback), event)
+		})
+		if err == nil {
+			g.Log().Infof(ctx, "循环操作执行成功: ID=%d, Occurrence=%d, BusinessID=%s", op.Id, occurrence, businessID)
+			return true
+		}
+		if err == errRecurringAdvanced {
+			return false
+		}
+	}
+
+	// 4. 执行失败：记录失败结果并推进到下一次（不消耗剩余次数），连续失败过多时自动暂停
+	advance, failures := planRecurringAdvance(op, now, nextRunAt, false, e.config.MaxConsecutiveFailures)
+	run.Status = string(constants.TransactionStatusFailed)
+	run.Error = err.Error()
+
+	txErr := g.DB().Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		advanced, err := dao.AdvanceRecurringOperation(ctx, tx, op.Id, op.RunCount, advance)
+		if err != nil {
+			return err
+		}
+		if !advanced {
+			return errRecurringAdvanced
+		}
+		if err := dao.CreateRecurringRun(ctx, tx, run); err != nil {
+			return err
+		}
+		return enqueueWebhookInTx(ctx, tx, decodeCallback(op.Callback), recurringRunEvent(op, run))
+	})
+	if txErr == errRecurringAdvanced {
+		return false
+	}
+	if txErr != nil {
+		g.Log().Errorf(ctx, "记录循环操作失败结果失败: ID=%d, Occurrence=%d, Error=%v", op.Id, occurrence, txErr)
+		return false
+	}
+
+	g.Log().Errorf(ctx, "循环操作执行失败: ID=%d, Occurrence=%d, ConsecutiveFailures=%d, Error=%v", op.Id, occurrence, failures, err)
+	if advance["status"] == string(RecurringStatusPaused) {
+		g.Log().Warningf(ctx, "循环操作连续失败 %d 次，已自动暂停: ID=%d", failures, op.Id)
+	}
+	return true
+}
+
+// planRecurringAdvance 计算一次执行后推进调度需要更新的字段，同时返回新的连续失败次数
+// 只有执行成功才消耗剩余次数并可能因次数用尽而完成；失败只推进执行时间，
+// 调度规则不再产生下次执行时间时结束，连续失败达到上限时自动暂停
+func planRecurringAdvance(op *entity.RecurringOperations, now *gtime.Time, nextRunAt time.Time, succeeded bool, maxConsecutiveFailures int) (map[string]any, int) {
+	advance := map[string]any{
+		"run_count":   op.RunCount + 1,
+		"last_run_at": now,
+		"next_run_at": gtime.New(nextRunAt),
+	}
+	finished := false
+	if nextRunAt.IsZero() {
+		advance["next_run_at"] = nil
+		finished = true
+	}
+
+	failures := 0
+	if succeeded {
+		if op.TotalCount > 0 {
+			advance["remaining_count"] = op.RemainingCount - 1
+			finished = finished || op.RemainingCount-1 <= 0
+		}
+	} else {
+		failures = op.ConsecutiveFailures + 1
+	}
+	advance["consecutive_failures"] = failures
+
+	switch {
+	case finished:
+		advance["status"] = string(RecurringStatusCompleted)
+	case failures >= maxConsecutiveFailures && !succeeded:
+		advance["status"] = string(RecurringStatusPaused)
+	}
+	return advance, failures
+}
+
+// recurringRunEvent 构建单次循环执行的回调事件
+func recurringRunEvent(op *entity.RecurringOperations, run *entity.RecurringRuns) *WebhookEvent {
+	event := WebhookEventTransactionFailed
+	if run.Status == string(constants.TransactionStatusCompleted) {
+		event = WebhookEventTransactionCompleted
+	}
+	return &WebhookEvent{
+		Event:       event,
+		BusinessID:  run.BusinessId,
+		UserID:      uint64(op.UserId),
+		TokenSymbol: op.Symbol,
+		Amount:      op.Amount.String(),
+		FundType:    op.FundType,
+		Status:      run.Status,
+		Reference:   op.Reference,
+		Error:       run.Error,
+	}
+}
+
+// parseRecurringSpec 从元数据中解析调度类型与规则
+func parseRecurringSpec(metadata map[string]interface{}) (string, string, error) {
+	interval, hasInterval := metadata["recurring_interval"]
+	cron, hasCron := metadata["recurring_cron"]
+
+	switch {
+	case hasInterval && hasCron:
+		return "", "", gerror.New("recurring_interval 与 recurring_cron 不能同时设置")
+	case hasInterval:
+		return logic.RecurringScheduleInterval, fmt.Sprintf("%v", interval), nil
+	case hasCron:
+		return logic.RecurringScheduleCron, fmt.Sprintf("%v", cron), nil
+	default:
+		return "", "", gerror.New("循环交易缺少调度规则 (recurring_interval 或 recurring_cron)，请使用 constants.BuildRecurringTransaction 构建")
+	}
+}
+
+// convertToRecurringOperationInfo 转换实体为循环操作信息
+func convertToRecurringOperationInfo(op *entity.RecurringOperations) *RecurringOperationInfo {
+	info := &RecurringOperationInfo{
+		ID:                  op.Id,
+		UserID:              uint64(op.UserId),
+		TokenSymbol:         op.Symbol,
+		Amount:              op.Amount,
+		FundType:            constants.FundType(op.FundType),
+		BusinessID:          op.BusinessId,
+		ScheduleType:        op.ScheduleType,
+		ScheduleSpec:        op.ScheduleSpec,
+		TotalCount:          op.TotalCount,
+		RemainingCount:      op.RemainingCount,
+		RunCount:            op.RunCount,
+		ConsecutiveFailures: op.ConsecutiveFailures,
+		Status:              RecurringStatus(op.Status),
+	}
+	if op.NextRunAt != nil {
+		info.NextRunAt = op.NextRunAt.String()
+	}
+	if op.LastRunAt != nil {
+		info.LastRunAt = op.LastRunAt.String()
+	}
+	return info
+}
+
+// convertToRecurringRunInfo 转换实体为执行记录
+func convertToRecurringRunInfo(run *entity.RecurringRuns) *RecurringRunInfo {
+	info := &RecurringRunInfo{
+		ID:            run.Id,
+		RecurringID:   run.RecurringId,
+		Occurrence:    run.Occurrence,
+		BusinessID:    run.BusinessId,
+		Status:        constants.TransactionStatus(run.Status),
+		TransactionID: run.TransactionId,
+		Error:         run.Error,
+	}
+	if run.PlannedAt != nil {
+		info.PlannedAt = run.PlannedAt.String()
+	}
+	if run.CreatedAt != nil {
+		info.ExecutedAt = run.CreatedAt.String()
+	}
+	return info
+}
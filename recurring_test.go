@@ -0,0 +1,83 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/os/gtime"
+
+	"github.com/yalks/wallet/entity"
+	"github.com/yalks/wallet/logic"
+)
+
+func TestCheckRecurringOperationOwner(t *testing.T) {
+	existing := &entity.RecurringOperations{UserId: 7, BusinessId: "recurring_ref-1"}
+	if err := checkRecurringOperationOwner(existing, 7); err != nil {
+		t.Errorf("same user: %v", err)
+	}
+	if err := checkRecurringOperationOwner(existing, 8); gerror.Code(err) != logic.CodeIdempotencyConflict {
+		t.Errorf("other user: err = %v", err)
+	}
+}
+
+func TestPlanRecurringAdvanceSuccess(t *testing.T) {
+	now := gtime.Now()
+	next := now.Time.Add(time.Hour)
+	op := &entity.RecurringOperations{TotalCount: 3, RemainingCount: 2, RunCount: 1, ConsecutiveFailures: 2}
+
+	advance, failures := planRecurringAdvance(op, now, next, true, 3)
+	if failures != 0 || advance["consecutive_failures"] != 0 {
+		t.Errorf("failures = %d, advance = %v", failures, advance)
+	}
+	if advance["run_count"] != 2 || advance["remaining_count"] != 1 {
+		t.Errorf("advance = %v", advance)
+	}
+	if _, ok := advance["status"]; ok {
+		t.Errorf("status changed: %v", advance["status"])
+	}
+
+	// 最后一次成功执行后完成
+	op.RemainingCount = 1
+	advance, _ = planRecurringAdvance(op, now, next, true, 3)
+	if advance["remaining_count"] != 0 || advance["status"] != string(RecurringStatusCompleted) {
+		t.Errorf("last run: advance = %v", advance)
+	}
+}
+
+func TestPlanRecurringAdvanceFailure(t *testing.T) {
+	now := gtime.Now()
+	next := now.Time.Add(time.Hour)
+
+	// 失败不消耗剩余次数，即使是最后一次也不会完成
+	op := &entity.RecurringOperations{TotalCount: 3, RemainingCount: 1, RunCount: 2}
+	advance, failures := planRecurringAdvance(op, now, next, false, 3)
+	if failures != 1 || advance["consecutive_failures"] != 1 || advance["run_count"] != 3 {
+		t.Errorf("failures = %d, advance = %v", failures, advance)
+	}
+	if _, ok := advance["remaining_count"]; ok {
+		t.Errorf("failure decremented remaining_count: %v", advance)
+	}
+	if _, ok := advance["status"]; ok {
+		t.Errorf("failure changed status: %v", advance["status"])
+	}
+
+	// 连续失败达到上限时暂停
+	op.ConsecutiveFailures = 2
+	advance, failures = planRecurringAdvance(op, now, next, false, 3)
+	if failures != 3 || advance["status"] != string(RecurringStatusPaused) {
+		t.Errorf("failures = %d, advance = %v", failures, advance)
+	}
+}
+
+func TestPlanRecurringAdvanceScheduleExhausted(t *testing.T) {
+	now := gtime.Now()
+	op := &entity.RecurringOperations{ConsecutiveFailures: 5}
+
+	for _, succeeded := range []bool{true, false} {
+		advance, _ := planRecurringAdvance(op, now, time.Time{}, succeeded, 3)
+		if advance["next_run_at"] != nil || advance["status"] != string(RecurringStatusCompleted) {
+			t.Errorf("succeeded=%v: advance = %v", succeeded, advance)
+		}
+	}
+}
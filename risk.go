@@ -0,0 +1,45 @@
+package wallet
+
+import (
+	"context"
+
+	"github.com/yalks/wallet/logic"
+)
+
+// RiskDecision 风控决策
+type RiskDecision = logic.RiskDecision
+
+const (
+	RiskDecisionAllow     = logic.RiskDecisionAllow     // 放行
+	RiskDecisionChallenge = logic.RiskDecisionChallenge // 需要验证支付密码
+	RiskDecisionBlock     = logic.RiskDecisionBlock     // 拦截
+)
+
+// RiskRule 风控规则，可通过 RegisterRiskRule 注册自定义规则
+type RiskRule = logic.RiskRule
+
+// RiskSignals 风控规则可使用的信号
+type RiskSignals = logic.RiskSignals
+
+// RiskAssessment 风控评估结果
+type RiskAssessment = logic.RiskAssessment
+
+// RiskError 风控拦截或需要验证支付密码，Assessment 中包含风险分和触发的规则
+type RiskError = logic.RiskError
+
+// 风控错误码，可通过 gerror.Code(err) 识别
+var (
+	CodeRiskBlocked   = logic.CodeRiskBlocked
+	CodeRiskChallenge = logic.CodeRiskChallenge
+)
+
+// RegisterRiskRule 注册自定义风控规则，与 wallet.risk 中启用的内置规则一起参与评分
+func RegisterRiskRule(rule RiskRule) error {
+	return logic.RegisterRiskRule(rule)
+}
+
+// WithPaymentPasswordVerified 标记调用方已验证用户的支付密码；
+// 风控决策为 challenge 的操作需要携带该标记重新提交
+func WithPaymentPasswordVerified(ctx context.Context) context.Context {
+	return logic.WithPaymentPasswordVerified(ctx)
+}
@@ -0,0 +1,580 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/container/gvar"
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/os/gtimer"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/constants"
+	"github.com/yalks/wallet/entity"
+	"github.com/yalks/wallet/logic"
+)
+
+// InsufficientFundsPolicy 定时操作执行时余额不足的处理策略
+type InsufficientFundsPolicy string
+
+const (
+	InsufficientFundsRetry InsufficientFundsPolicy = "retry" // 按重试间隔重新排队，超过最大次数后失败
+	InsufficientFundsFail  InsufficientFundsPolicy = "fail"  // 直接标记为失败
+)
+
+// SchedulerConfig 定时操作调度器配置（对应配置项 wallet.scheduler）
+type SchedulerConfig struct {
+	PollInterval            time.Duration           `json:"pollInterval"`            // 扫描到期操作的间隔
+	BatchSize               int                     `json:"batchSize"`               // 每次扫描处理的最大数量
+	InsufficientFundsPolicy InsufficientFundsPolicy `json:"insufficientFundsPolicy"` // 余额不足处理策略
+	RetryInterval           time.Duration           `json:"retryInterval"`           // 余额不足重试间隔
+	MaxAttempts             int                     `json:"maxAttempts"`             // 最大尝试次数
+	LeaseTimeout            time.Duration           `json:"leaseTimeout"`            // 抢占后超过该时长仍在处理中的操作重新排队（进程崩溃恢复）
+}
+
+// DefaultSchedulerConfig 默认调度器配置
+func DefaultSchedulerConfig() SchedulerConfig {
+	return SchedulerConfig{
+		PollInterval:            10 * time.Second,
+		BatchSize:               50,
+		InsufficientFundsPolicy: InsufficientFundsRetry,
+		RetryInterval:           5 * time.Minute,
+		MaxAttempts:             3,
+		LeaseTimeout:            10 * time.Minute,
+	}
+}
+
+// scheduler 定时操作调度器
+type scheduler struct {
+	manager *walletManager
+	context *logic.SharedLogicContext
+	config  SchedulerConfig
+
+	mu    sync.Mutex
+	entry *gtimer.Entry
+}
+
+// newScheduler 创建定时操作调度器
+func newScheduler(ctx context.Context, manager *walletManager) *scheduler {
+	return &scheduler{
+		manager: manager,
+		context: logic.GetSharedContext(),
+		config:  loadSchedulerConfig(ctx),
+	}
+}
+
+// loadSchedulerConfig 从配置中读取调度器配置，缺省项使用默认值
+func loadSchedulerConfig(ctx context.Context) SchedulerConfig {
+	value, err := g.Cfg().Get(ctx, "wallet.scheduler")
+	if err != nil || value == nil || value.IsEmpty() {
+		return DefaultSchedulerConfig()
+	}
+	return parseSchedulerConfig(ctx, value.MapStrVar())
+}
+
+// parseSchedulerConfig 解析调度器配置，缺省或无效的项使用默认值
+func parseSchedulerConfig(ctx context.Context, raw map[string]*gvar.Var) SchedulerConfig {
+	config := DefaultSchedulerConfig()
+	if v, ok := raw["pollInterval"]; ok {
+		if d, err := time.ParseDuration(v.String()); err == nil && d > 0 {
+			config.PollInterval = d
+		}
+	}
+	if v, ok := raw["batchSize"]; ok && v.Int() > 0 {
+		config.BatchSize = v.Int()
+	}
+	if v, ok := raw["insufficientFundsPolicy"]; ok {
+		switch policy := InsufficientFundsPolicy(v.String()); policy {
+		case InsufficientFundsRetry, InsufficientFundsFail:
+			config.InsufficientFundsPolicy = policy
+		default:
+			g.Log().Warningf(ctx, "无效的余额不足处理策略: %s, 使用默认值 %s", policy, config.InsufficientFundsPolicy)
+		}
+	}
+	if v, ok := raw["retryInterval"]; ok {
+		if d, err := time.ParseDuration(v.String()); err == nil && d > 0 {
+			config.RetryInterval = d
+		}
+	}
+	if v, ok := raw["maxAttempts"]; ok && v.Int() > 0 {
+		config.MaxAttempts = v.Int()
+	}
+	if v, ok := raw["leaseTimeout"]; ok {
+		if d, err := time.ParseDuration(v.String()); err == nil && d > 0 {
+			config.LeaseTimeout = d
+		}
+	}
+
+	return config
+}
+
+// ScheduleTransaction 持久化定时交易（由 constants.BuildScheduledTransaction 构建）
+func (m *walletManager) ScheduleTransaction(ctx context.Context, req *constants.TransactionRequestEnhanced) (*ScheduledOperationInfo, error) {
+	if req == nil {
+		return nil, gerror.New("定时交易请求不能为空")
+	}
+	if err := m.adjustments.authorize(ctx, req.FundType); err != nil {
+		return nil, err
+	}
+	if _, err := constants.ResolveFundDirection(req.FundType, req.Direction); err != nil {
+		return nil, gerror.Wrap(err, "资金方向无效")
+	}
+	if info, _ := constants.GetFundTypeInfo(req.FundType); !info.HasFlag(constants.FundTypeFlagSchedulable) {
+		return nil, gerror.Newf("资金类型不支持定时或循环执行: %s", req.FundType)
+	}
+
+	scheduledAt, err := parseScheduledAt(req.Metadata["scheduled_at"])
+	if err != nil {
+		return nil, err
+	}
+	if req.ExpireAt != nil && !req.ExpireAt.After(scheduledAt) {
+		return nil, gerror.Newf("过期时间必须晚于计划执行时间: ScheduledAt=%s, ExpireAt=%s",
+			scheduledAt.Format(time.RFC3339), req.ExpireAt.Format(time.RFC3339))
+	}
+
+	amount, err := decimal.NewFromString(req.Amount)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "无效的金额格式: %s", req.Amount)
+	}
+
+	token, err := m.tokenLogic.GetTokenByID(ctx, uint(req.TokenID))
+	if err != nil {
+		return nil, gerror.Wrapf(err, "获取代币信息失败: TokenID=%d", req.TokenID)
+	}
+
+	// 稳定的幂等键：同一请求重复提交只会创建一条定时操作，执行时也使用同一业务ID
+	idempotencyKey := req.IdempotencyKey
+	if idempotencyKey == "" {
+		idempotencyKey = req.Reference
+	}
+	businessID := "scheduled_" + idempotencyKey
+
+	dao := m.scheduler.context.GetScheduledOperationDAO()
+	existing, err := dao.GetScheduledOperationByBusinessID(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if err := checkScheduledOperationOwner(existing, uint64(req.UserID)); err != nil {
+			return nil, err
+		}
+		g.Log().Infof(ctx, "定时操作已存在，返回现有记录: BusinessID=%s, ID=%d", businessID, existing.Id)
+		return convertToScheduledOperationInfo(existing), nil
+	}
+
+	metadata, err := encodeOperationMetadata(req.Metadata, req.Direction)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := logic.NewTransactionValidator().NormalizeTags(req.Tags)
+	if err != nil {
+		return nil, gerror.Wrap(err, "交易标签验证失败")
+	}
+
+	now := gtime.Now()
+	op := &entity.ScheduledOperations{
+		UserId:      uint(req.UserID),
+		TokenId:     token.TokenId,
+		Symbol:      token.Symbol,
+		Amount:      amount,
+		FundType:    string(req.FundType),
+		BusinessId:  businessID,
+		Reference:   req.Reference,
+		Description: req.Description,
+		Metadata:    metadata,
+		Callback:    encodeCallback(req.Callback),
+		Tags:        encodeTags(tags),
+		RelatedId:   req.RelatedID,
+		Priority:    req.Priority,
+		Status:      string(constants.TransactionStatusPending),
+		ScheduledAt: gtime.New(scheduledAt),
+		NextRunAt:   gtime.New(scheduledAt),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if req.ExpireAt != nil {
+		op.ExpireAt = gtime.New(*req.ExpireAt)
+	}
+
+	id, err := dao.CreateScheduledOperation(ctx, nil, op)
+	if err != nil {
+		return nil, gerror.Wrap(err, "保存定时操作失败")
+	}
+	op.Id = id
+
+	g.Log().Infof(ctx, "定时操作创建成功: ID=%d, UserID=%d, FundType=%s, Amount=%s %s, ScheduledAt=%s",
+		id, req.UserID, req.FundType, amount.String(), token.Symbol, scheduledAt.Format(time.RFC3339))
+
+	return convertToScheduledOperationInfo(op), nil
+}
+
+// checkScheduledOperationOwner 幂等键对应的定时操作属于其他用户时返回 CodeIdempotencyConflict 错误，
+// 避免向调用方返回其他用户的记录
+func checkScheduledOperationOwner(existing *entity.ScheduledOperations, userID uint64) error {
+	if uint64(existing.UserId) != userID {
+		return gerror.NewCodef(logic.CodeIdempotencyConflict, "幂等键已被其他用户的定时操作使用: BusinessID=%s", existing.BusinessId)
+	}
+	return nil
+}
+
+// ListScheduledOperations 获取用户定时操作列表
+func (m *walletManager) ListScheduledOperations(ctx context.Context, userID uint64, status constants.TransactionStatus, limit, offset int) ([]*ScheduledOperationInfo, error) {
+	ops, err := m.scheduler.context.GetScheduledOperationDAO().ListScheduledOperations(ctx, userID, string(status), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*ScheduledOperationInfo, 0, len(ops))
+	for _, op := range ops {
+		infos = append(infos, convertToScheduledOperationInfo(op))
+	}
+	return infos, nil
+}
+
+// CancelScheduledOperation 取消尚未执行的定时操作
+func (m *walletManager) CancelScheduledOperation(ctx context.Context, userID uint64, operationID uint64) error {
+	dao := m.scheduler.context.GetScheduledOperationDAO()
+	op, err := dao.GetScheduledOperationByID(ctx, operationID)
+	if err != nil {
+		return err
+	}
+	if op == nil || uint64(op.UserId) != userID {
+		return gerror.Newf("定时操作不存在: UserID=%d, ID=%d", userID, operationID)
+	}
+
+	cancelled, err := dao.UpdateScheduledOperationIfStatus(ctx, nil, operationID, string(constants.TransactionStatusPending), map[string]any{
+		"status": string(constants.TransactionStatusCancelled),
+	})
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return gerror.Newf("定时操作当前状态不可取消: ID=%d, Status=%s", operationID, op.Status)
+	}
+
+	g.Log().Infof(ctx, "定时操作已取消: ID=%d, UserID=%d", operationID, userID)
+	return nil
+}
+
+// RunDueScheduledOperations 执行所有已到期的定时操作，返回本次处理的数量
+func (m *walletManager) RunDueScheduledOperations(ctx context.Context) (int, error) {
+	return m.scheduler.runDue(ctx)
+}
+
+// StartScheduler 启动后台调度（定时操作与循环操作）
+func (m *walletManager) StartScheduler(ctx context.Context) error {
+	return m.scheduler.start(ctx)
+}
+
+// StopScheduler 停止后台调度
+func (m *walletManager) StopScheduler(ctx context.Context) {
+	m.scheduler.stop(ctx)
+}
+
+// start 启动后台调度
+func (s *scheduler) start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.entry != nil {
+		return gerror.New("定时操作调度器已启动")
+	}
+
+	s.entry = gtimer.AddSingleton(ctx, s.config.PollInterval, func(ctx context.Context) {
+		if _, err := s.runDue(ctx); err != nil {
+			g.Log().Errorf(ctx, "执行到期定时操作失败: %v", err)
+		}
+		if _, err := s.manager.recurring.runDue(ctx); err != nil {
+			g.Log().Errorf(ctx, "执行到期循环操作失败: %v", err)
+		}
+	})
+
+	g.Log().Infof(ctx, "定时操作调度器已启动: PollInterval=%s, Policy=%s", s.config.PollInterval, s.config.InsufficientFundsPolicy)
+	return nil
+}
+
+// stop 停止后台调度
+func (s *scheduler) stop(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.entry != nil {
+		s.entry.Close()
+		s.entry = nil
+		g.Log().Info(ctx, "定时操作调度器已停止")
+	}
+}
+
+// runDue 扫描并执行到期的定时操作。执行前先把租约已超时的处理中操作重新排队：
+// 资金操作与完成状态在同一事务提交，未提交的操作可以安全地再次执行，业务ID保证不会重复入账
+func (s *scheduler) runDue(ctx context.Context) (int, error) {
+	dao := s.context.GetScheduledOperationDAO()
+	now := gtime.Now()
+	reclaimed, err := dao.ReclaimStaleScheduledOperations(ctx, now.Add(-s.config.LeaseTimeout))
+	if err != nil {
+		return 0, err
+	}
+	if reclaimed > 0 {
+		g.Log().Warningf(ctx, "处理超时的定时操作已重新排队: Count=%d, LeaseTimeout=%s", reclaimed, s.config.LeaseTimeout)
+	}
+
+	ops, err := dao.ListDueScheduledOperations(ctx, now, s.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, op := range ops {
+		claimed, err := dao.ClaimScheduledOperation(ctx, op.Id, gtime.Now())
+		if err != nil {
+			g.Log().Errorf(ctx, "抢占定时操作失败: ID=%d, Error=%v", op.Id, err)
+			continue
+		}
+		if !claimed {
+			// 已被其他实例处理或已取消
+			continue
+		}
+
+		s.execute(ctx, op)
+		processed++
+	}
+
+	return processed, nil
+}
+
+// execute 执行单个已抢占的定时操作并记录结果
+func (s *scheduler) execute(ctx context.Context, op *entity.ScheduledOperations) {
+	dao := s.context.GetScheduledOperationDAO()
+	attempts := op.Attempts + 1
+
+	// 1. 过期检查
+	if op.ExpireAt != nil && gtime.Now().After(op.ExpireAt) {
+		s.finishWithEvent(ctx, op, map[string]any{
+			"status":     string(constants.TransactionStatusExpired),
+			"last_error": "定时操作已过期",
+		})
+		g.Log().Warningf(ctx, "定时操作已过期: ID=%d, ExpireAt=%s", op.Id, op.ExpireAt.String())
+		return
+	}
+
+	// 2. 构建资金操作请求（使用持久化的稳定业务ID保证幂等）
+	builder := constants.NewFundOperationBuilder().
+		WithUser(uint64(op.UserId)).
+		WithTokenSymbol(op.Symbol).
+		WithAmount(op.Amount).
+		WithBusinessID(op.BusinessId).
+		WithFundType(constants.FundType(op.FundType)).
+		WithDescription(op.Description).
+		WithRelatedID(op.RelatedId).
+		WithMetadata("scheduled_operation_id", fmt.Sprintf("%d", op.Id))
+
+	for k, v := range decodeOperationMetadata(ctx, op.Metadata) {
+		builder.WithMetadata(k, v)
+		if k == constants.MetadataKeyDirection {
+			builder.WithDirection(constants.FundDirection(v))
+		}
+	}
+
+	req, err := builder.Build()
+	if err != nil {
+		s.finishWithEvent(ctx, op, map[string]any{
+			"status":     string(constants.TransactionStatusFailed),
+			"attempts":   attempts,
+			"last_error": err.Error(),
+		})
+		return
+	}
+
+	// 3. 在同一数据库事务中执行资金操作并标记完成
+	err = s.manager.RunInTransaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		result, err := s.manager.ProcessFundOperationInTx(ctx, tx, req)
+		if err != nil {
+			return err
+		}
+
+		transactionID, _ := strconv.ParseUint(result.TransactionID, 10, 64)
+		if err := s.context.GetTransactionTagDAO().CreateTags(ctx, tx, transactionID, uint64(op.UserId), decodeTags(op.Tags)); err != nil {
+			return err
+		}
+		updated, err := dao.UpdateScheduledOperationIfStatus(ctx, tx, op.Id, string(constants.TransactionStatusProcessing), map[string]any{
+			"status":         string(constants.TransactionStatusCompleted),
+			"attempts":       attempts,
+			"last_error":     "",
+			"transaction_id": transactionID,
+			"executed_at":    gtime.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return gerror.Newf("定时操作租约已超时并被重新抢占: ID=%d", op.Id)
+		}
+
+		event := scheduledOperationEvent(op, string(constants.TransactionStatusCompleted), "")
+		event.TransactionID = result.TransactionID
+		return enqueueWebhookInTx(ctx, tx, decodeCallback(op.Callback), event)
+	})
+	if err == nil {
+		g.Log().Infof(ctx, "定时操作执行成功: ID=%d, BusinessID=%s", op.Id, op.BusinessId)
+		return
+	}
+
+	// 4. 失败处理：余额不足按策略重试，其他错误直接失败
+	if gerror.Code(err) == logic.CodeInsufficientBalance &&
+		s.config.InsufficientFundsPolicy == InsufficientFundsRetry &&
+		attempts < s.config.MaxAttempts {
+		nextRunAt := gtime.Now().Add(s.config.RetryInterval)
+		s.finish(ctx, op.Id, map[string]any{
+			"status":      string(constants.TransactionStatusPending),
+			"attempts":    attempts,
+			"last_error":  err.Error(),
+			"next_run_at": nextRunAt,
+		})
+		g.Log().Warningf(ctx, "定时操作余额不足，稍后重试: ID=%d, Attempts=%d, NextRunAt=%s", op.Id, attempts, nextRunAt.String())
+		return
+	}
+
+	s.finishWithEvent(ctx, op, map[string]any{
+		"status":     string(constants.TransactionStatusFailed),
+		"attempts":   attempts,
+		"last_error": err.Error(),
+	})
+	g.Log().Errorf(ctx, "定时操作执行失败: ID=%d, Attempts=%d, Error=%v", op.Id, attempts, err)
+}
+
+// finish 更新处理中的定时操作的最终状态；租约超时后已被重新抢占的操作不更新
+func (s *scheduler) finish(ctx context.Context, id uint64, data map[string]any) {
+	updated, err := s.context.GetScheduledOperationDAO().UpdateScheduledOperationIfStatus(ctx, nil, id, string(constants.TransactionStatusProcessing), data)
+	if err != nil {
+		g.Log().Errorf(ctx, "更新定时操作状态失败: ID=%d, Error=%v", id, err)
+		return
+	}
+	if !updated {
+		g.Log().Warningf(ctx, "定时操作已不在处理中，忽略本次结果: ID=%d, Status=%v", id, data["status"])
+	}
+}
+
+// finishWithEvent 更新定时操作的最终失败状态，并在同一事务中写入失败回调
+func (s *scheduler) finishWithEvent(ctx context.Context, op *entity.ScheduledOperations, data map[string]any) {
+	callback := decodeCallback(op.Callback)
+	if callback[constants.CallbackKeyURL] == "" {
+		s.finish(ctx, op.Id, data)
+		return
+	}
+
+	err := g.DB().Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		updated, err := s.context.GetScheduledOperationDAO().UpdateScheduledOperationIfStatus(ctx, tx, op.Id, string(constants.TransactionStatusProcessing), data)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			g.Log().Warningf(ctx, "定时操作已不在处理中，忽略本次结果: ID=%d, Status=%v", op.Id, data["status"])
+			return nil
+		}
+		event := scheduledOperationEvent(op, fmt.Sprintf("%v", data["status"]), fmt.Sprintf("%v", data["last_error"]))
+		return enqueueWebhookInTx(ctx, tx, callback, event)
+	})
+	if err != nil {
+		g.Log().Errorf(ctx, "更新定时操作状态失败: ID=%d, Error=%v", op.Id, err)
+	}
+}
+
+// scheduledOperationEvent 构建定时操作的回调事件
+func scheduledOperationEvent(op *entity.ScheduledOperations, status, errMsg string) *WebhookEvent {
+	event := WebhookEventTransactionFailed
+	if status == string(constants.TransactionStatusCompleted) {
+		event = WebhookEventTransactionCompleted
+	}
+	return &WebhookEvent{
+		Event:       event,
+		BusinessID:  op.BusinessId,
+		UserID:      uint64(op.UserId),
+		TokenSymbol: op.Symbol,
+		Amount:      op.Amount.String(),
+		FundType:    op.FundType,
+		Status:      status,
+		Reference:   op.Reference,
+		Error:       errMsg,
+	}
+}
+
+// encodeOperationMetadata 将交易请求元数据序列化为 JSON 字符串保存，
+// 指定了资金方向时一并保存，执行时用于双向资金类型
+func encodeOperationMetadata(metadata map[string]interface{}, direction constants.FundDirection) (string, error) {
+	values := make(map[string]string, len(metadata)+1)
+	for k, v := range metadata {
+		values[k] = fmt.Sprintf("%v", v)
+	}
+	if direction != "" {
+		values[constants.MetadataKeyDirection] = string(direction)
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", gerror.Wrap(err, "序列化元数据失败")
+	}
+	return string(data), nil
+}
+
+// decodeOperationMetadata 解析保存的元数据，解析失败时返回空元数据
+func decodeOperationMetadata(ctx context.Context, raw string) map[string]string {
+	metadata := make(map[string]string)
+	if raw == "" {
+		return metadata
+	}
+	if err := json.Unmarshal([]byte(raw), &metadata); err != nil {
+		g.Log().Warningf(ctx, "解析操作元数据失败: %v", err)
+	}
+	return metadata
+}
+
+// parseScheduledAt 解析元数据中的计划执行时间
+func parseScheduledAt(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, gerror.Wrapf(err, "无效的计划执行时间: %s", v)
+		}
+		return t, nil
+	default:
+		return time.Time{}, gerror.New("定时交易缺少计划执行时间 (scheduled_at)，请使用 constants.BuildScheduledTransaction 构建")
+	}
+}
+
+// convertToScheduledOperationInfo 转换实体为定时操作信息
+func convertToScheduledOperationInfo(op *entity.ScheduledOperations) *ScheduledOperationInfo {
+	info := &ScheduledOperationInfo{
+		ID:            op.Id,
+		UserID:        uint64(op.UserId),
+		TokenSymbol:   op.Symbol,
+		Amount:        op.Amount,
+		FundType:      constants.FundType(op.FundType),
+		BusinessID:    op.BusinessId,
+		Reference:     op.Reference,
+		Priority:      op.Priority,
+		Status:        constants.TransactionStatus(op.Status),
+		Attempts:      op.Attempts,
+		LastError:     op.LastError,
+		TransactionID: op.TransactionId,
+		Tags:          decodeTags(op.Tags),
+	}
+	if op.ScheduledAt != nil {
+		info.ScheduledAt = op.ScheduledAt.String()
+	}
+	if op.ExpireAt != nil {
+		info.ExpireAt = op.ExpireAt.String()
+	}
+	if op.ExecutedAt != nil {
+		info.ExecutedAt = op.ExecutedAt.String()
+	}
+	return info
+}
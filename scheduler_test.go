@@ -0,0 +1,79 @@
+package wallet
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/container/gvar"
+	"github.com/gogf/gf/v2/errors/gerror"
+
+	"github.com/yalks/wallet/constants"
+	"github.com/yalks/wallet/entity"
+	"github.com/yalks/wallet/logic"
+)
+
+func TestParseSchedulerConfig(t *testing.T) {
+	ctx := context.Background()
+	if config := parseSchedulerConfig(ctx, nil); config != DefaultSchedulerConfig() {
+		t.Fatalf("defaults = %+v", config)
+	}
+
+	config := parseSchedulerConfig(ctx, map[string]*gvar.Var{
+		"pollInterval":            gvar.New("30s"),
+		"insufficientFundsPolicy": gvar.New("fail"),
+		"maxAttempts":             gvar.New(5),
+		"leaseTimeout":            gvar.New("2m"),
+	})
+	if config.PollInterval != 30*time.Second || config.InsufficientFundsPolicy != InsufficientFundsFail ||
+		config.MaxAttempts != 5 || config.LeaseTimeout != 2*time.Minute {
+		t.Errorf("config = %+v", config)
+	}
+
+	// 无效的值保留默认值
+	config = parseSchedulerConfig(ctx, map[string]*gvar.Var{
+		"insufficientFundsPolicy": gvar.New("ignore"),
+		"leaseTimeout":            gvar.New("-1m"),
+	})
+	if defaults := DefaultSchedulerConfig(); config.InsufficientFundsPolicy != defaults.InsufficientFundsPolicy || config.LeaseTimeout != defaults.LeaseTimeout {
+		t.Errorf("invalid values changed the config: %+v", config)
+	}
+}
+
+func TestCheckScheduledOperationOwner(t *testing.T) {
+	existing := &entity.ScheduledOperations{UserId: 7, BusinessId: "scheduled_ref-1"}
+	if err := checkScheduledOperationOwner(existing, 7); err != nil {
+		t.Errorf("same user: %v", err)
+	}
+	if err := checkScheduledOperationOwner(existing, 8); gerror.Code(err) != logic.CodeIdempotencyConflict {
+		t.Errorf("other user: err = %v", err)
+	}
+}
+
+func TestOperationMetadataRoundTrip(t *testing.T) {
+	raw, err := encodeOperationMetadata(map[string]interface{}{"order_id": 42, "scheduled_at": "2026-01-01T00:00:00Z"}, constants.FundDirectionOut)
+	if err != nil {
+		t.Fatalf("encodeOperationMetadata: %v", err)
+	}
+	metadata := decodeOperationMetadata(context.Background(), raw)
+	if metadata["order_id"] != "42" || metadata[constants.MetadataKeyDirection] != string(constants.FundDirectionOut) {
+		t.Errorf("metadata = %v", metadata)
+	}
+	if metadata := decodeOperationMetadata(context.Background(), "not json"); len(metadata) != 0 {
+		t.Errorf("invalid metadata decoded to %v", metadata)
+	}
+}
+
+func TestParseScheduledAt(t *testing.T) {
+	want := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, value := range []interface{}{want, "2026-01-02T03:04:05Z"} {
+		if got, err := parseScheduledAt(value); err != nil || !got.Equal(want) {
+			t.Errorf("parseScheduledAt(%v) = %v, %v", value, got, err)
+		}
+	}
+	for _, value := range []interface{}{nil, "tomorrow", 1700000000} {
+		if _, err := parseScheduledAt(value); err == nil {
+			t.Errorf("parseScheduledAt(%v) accepted", value)
+		}
+	}
+}
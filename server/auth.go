@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/net/ghttp"
+
+	"github.com/yalks/wallet"
+	"github.com/yalks/wallet/logic"
+)
+
+// API Key 认证请求头
+const (
+	HeaderAPIKey    = "X-API-Key"   // Key ID
+	HeaderTimestamp = "X-Timestamp" // 请求时间，Unix 秒
+	HeaderNonce     = "X-Nonce"     // 请求随机数，同一 Key 不能重复使用
+	HeaderSignature = "X-Signature" // 请求签名，见 wallet.SignAPIRequest
+)
+
+// authMiddleware 认证 API Key 签名请求，并将 Key 的身份记录到请求上下文：
+// 请求来源固定为 api，元数据 api_key_id 和 api_key_name 随交易保存
+func (s *Server) authMiddleware(r *ghttp.Request) {
+	key, err := s.manager.AuthenticateAPIKey(r.Context(), &wallet.APIKeyRequest{
+		KeyID:     r.Header.Get(HeaderAPIKey),
+		Timestamp: r.Header.Get(HeaderTimestamp),
+		Nonce:     r.Header.Get(HeaderNonce),
+		Signature: r.Header.Get(HeaderSignature),
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		RawQuery:  r.URL.RawQuery,
+		Body:      r.GetBody(),
+	})
+	if err != nil {
+		writeError(r, err)
+		return
+	}
+
+	base := logic.ExtractRequestContext(r.Context())
+	reqCtx := *base
+	reqCtx.Source = "api"
+	reqCtx.Metadata = make(map[string]string, len(base.Metadata)+2)
+	for k, v := range base.Metadata {
+		reqCtx.Metadata[k] = v
+	}
+	reqCtx.Metadata["api_key_id"] = key.KeyID
+	reqCtx.Metadata["api_key_name"] = key.Name
+	r.SetCtx(logic.WithRequestContext(wallet.WithAPIKey(r.Context(), key), &reqCtx))
+	r.Middleware.Next()
+}
+
+// checkReservedMetadata 通过 API Key 认证的请求不能在业务元数据中覆盖 Key 的身份
+func checkReservedMetadata(ctx context.Context, metadata map[string]string) error {
+	if wallet.APIKeyFromContext(ctx) == nil {
+		return nil
+	}
+	for _, key := range []string{"api_key_id", "api_key_name"} {
+		if _, ok := metadata[key]; ok {
+			return gerror.NewCodef(gcode.CodeInvalidParameter, "metadata 不能包含保留字段 %s", key)
+		}
+	}
+	return nil
+}
+
+// authorize 校验请求的 API Key 权限范围；未启用 API Key 认证时不做限制
+func authorize(ctx context.Context, access *wallet.APIKeyAccess) error {
+	key := wallet.APIKeyFromContext(ctx)
+	if key == nil {
+		return nil
+	}
+	return key.Scope.Authorize(access)
+}
+
+// authorizeRecord 校验交易记录所属用户和代币是否在 API Key 权限范围内
+func authorizeRecord(ctx context.Context, record *wallet.TransactionRecord) error {
+	key := wallet.APIKeyFromContext(ctx)
+	if key == nil {
+		return nil
+	}
+	if record.UserID >= 0 && !key.Scope.AllowsUser(uint64(record.UserID)) {
+		return gerror.NewCodef(wallet.CodeAPIKeyForbidden, "API Key 无权访问用户 %d 的交易", record.UserID)
+	}
+	if !key.Scope.AllowsToken(record.TokenSymbol) {
+		return gerror.NewCodef(wallet.CodeAPIKeyForbidden, "API Key 无权访问代币 %s 的交易", record.TokenSymbol)
+	}
+	return nil
+}
+
+// authorizeHistory 校验交易历史查询；Key 限制了代币时查询必须指定其中之一
+func authorizeHistory(ctx context.Context, query *wallet.TransactionQuery) error {
+	if err := authorize(ctx, &wallet.APIKeyAccess{
+		Permission:  wallet.APIKeyPermissionTransactionsRead,
+		UserIDs:     []uint64{uint64(query.UserID)},
+		TokenSymbol: query.TokenSymbol,
+	}); err != nil {
+		return err
+	}
+	if key := wallet.APIKeyFromContext(ctx); key != nil && len(key.Scope.Tokens) > 0 && query.TokenSymbol == "" {
+		return gerror.NewCode(wallet.CodeAPIKeyForbidden, "API Key 只能查询指定代币的交易，请提供 token 参数")
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package server
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet"
+	"github.com/yalks/wallet/constants"
+	"github.com/yalks/wallet/logic"
+)
+
+// getBalance GET /balances/{user_id}/{token}
+func (s *Server) getBalance(r *ghttp.Request) {
+	userID, err := parseID("user_id", r.GetRouter("user_id").String())
+	if err != nil {
+		writeError(r, err)
+		return
+	}
+	token := r.GetRouter("token").String()
+	if err := authorize(r.Context(), &wallet.APIKeyAccess{
+		Permission:  wallet.APIKeyPermissionBalancesRead,
+		UserIDs:     []uint64{userID},
+		TokenSymbol: token,
+	}); err != nil {
+		writeError(r, err)
+		return
+	}
+	balance, err := s.manager.GetBalance(r.Context(), userID, token)
+	if err != nil {
+		writeError(r, err)
+		return
+	}
+	writeData(r, balance)
+}
+
+// fundOperationRequest 将请求体转换为资金操作请求，请求来源、IP 和 User-Agent 取自请求上下文
+func fundOperationRequest(ctx context.Context, body *wallet.FundOperationRequest) *constants.FundOperationRequest {
+	reqCtx := logic.ExtractRequestContext(ctx)
+	return &constants.FundOperationRequest{
+		UserID:           body.UserID,
+		TokenSymbol:      body.TokenSymbol,
+		Amount:           body.Amount,
+		BusinessID:       body.BusinessID,
+		FundType:         body.FundType,
+		Direction:        body.Direction,
+		Description:      body.Description,
+		Metadata:         body.Metadata,
+		RelatedID:        body.RelatedID,
+		RequestSource:    reqCtx.Source,
+		RequestIP:        reqCtx.IP,
+		RequestUserAgent: reqCtx.UserAgent,
+	}
+}
+
+// operationAccess 资金操作需要的 API Key 权限
+func operationAccess(permission wallet.APIKeyPermission, req *constants.FundOperationRequest) *wallet.APIKeyAccess {
+	return &wallet.APIKeyAccess{
+		Permission:  permission,
+		UserIDs:     []uint64{req.UserID},
+		TokenSymbol: req.TokenSymbol,
+		FundType:    req.FundType,
+		Amount:      req.Amount,
+	}
+}
+
+// processOperation POST /operations
+func (s *Server) processOperation(r *ghttp.Request) {
+	var body wallet.FundOperationRequest
+	if err := decodeJSON(r.GetBody(), &body); err != nil {
+		writeError(r, err)
+		return
+	}
+	businessID, err := resolveBusinessID(r.Header.Get(HeaderIdempotencyKey), body.BusinessID)
+	if err != nil {
+		writeError(r, err)
+		return
+	}
+	body.BusinessID = businessID
+	req := fundOperationRequest(r.Context(), &body)
+	if err := authorize(r.Context(), operationAccess(wallet.APIKeyPermissionOperationsWrite, req)); err != nil {
+		writeError(r, err)
+		return
+	}
+	if err := checkReservedMetadata(r.Context(), req.Metadata); err != nil {
+		writeError(r, err)
+		return
+	}
+
+	replayed, err := s.checkIdempotency(r.Context(), businessID, req.UserID, req.FundType, req.Amount)
+	if err != nil {
+		writeError(r, err)
+		return
+	}
+
+	var result *wallet.FundOperationResult
+	err = s.manager.RunInTransaction(r.Context(), func(ctx context.Context, tx gdb.TX) error {
+		var err error
+		result, err = s.manager.ProcessFundOperationInTx(ctx, tx, req)
+		return err
+	})
+	if err != nil {
+		writeError(r, err)
+		return
+	}
+	if replayed || result.Replayed {
+		r.Response.Header().Set(HeaderIdempotentReplayed, "true")
+	}
+	writeData(r, result)
+}
+
+// quoteOperation POST /quotes
+func (s *Server) quoteOperation(r *ghttp.Request) {
+	var body wallet.FundOperationRequest
+	if err := decodeJSON(r.GetBody(), &body); err != nil {
+		writeError(r, err)
+		return
+	}
+	req := fundOperationRequest(r.Context(), &body)
+	if err := authorize(r.Context(), operationAccess(wallet.APIKeyPermissionQuotesRead, req)); err != nil {
+		writeError(r, err)
+		return
+	}
+	quote, err := s.manager.QuoteFundOperation(r.Context(), req)
+	if err != nil {
+		writeError(r, err)
+		return
+	}
+	writeData(r, quote)
+}
+
+// processTransfer POST /transfers
+func (s *Server) processTransfer(r *ghttp.Request) {
+	var req wallet.TransferOperationRequest
+	if err := decodeJSON(r.GetBody(), &req); err != nil {
+		writeError(r, err)
+		return
+	}
+	businessID, err := resolveBusinessID(r.Header.Get(HeaderIdempotencyKey), req.BusinessID)
+	if err != nil {
+		writeError(r, err)
+		return
+	}
+	reqCtx := logic.ExtractRequestContext(r.Context())
+	req.BusinessID = businessID
+	req.RequestSource, req.RequestIP, req.RequestUserAgent = reqCtx.Source, reqCtx.IP, reqCtx.UserAgent
+	if err := authorize(r.Context(), &wallet.APIKeyAccess{
+		Permission:  wallet.APIKeyPermissionTransfersWrite,
+		UserIDs:     []uint64{req.FromUserID, req.ToUserID},
+		TokenSymbol: req.TokenSymbol,
+		FundType:    req.FundType,
+		Amount:      req.Amount,
+	}); err != nil {
+		writeError(r, err)
+		return
+	}
+	if err := checkReservedMetadata(r.Context(), req.Metadata); err != nil {
+		writeError(r, err)
+		return
+	}
+
+	// 转账的扣款交易使用 <business_id>_debit 作为业务ID
+	replayed, err := s.checkIdempotency(r.Context(), businessID+"_debit", req.FromUserID, req.FundType, req.Amount)
+	if err != nil {
+		writeError(r, err)
+		return
+	}
+
+	var result *wallet.TransferOperationResult
+	err = s.manager.RunInTransaction(r.Context(), func(ctx context.Context, tx gdb.TX) error {
+		var err error
+		result, err = s.manager.ProcessTransferInTx(ctx, tx, &req)
+		return err
+	})
+	if err != nil {
+		writeError(r, err)
+		return
+	}
+	if replayed {
+		r.Response.Header().Set(HeaderIdempotentReplayed, "true")
+	}
+	writeData(r, result)
+}
+
+// checkIdempotency 检查业务ID是否已执行过：已执行且请求一致时返回 true，请求不一致时返回幂等冲突错误
+func (s *Server) checkIdempotency(ctx context.Context, businessID string, userID uint64, fundType constants.FundType, amount decimal.Decimal) (bool, error) {
+	existing, err := s.transactions.GetTransactionByReference(ctx, businessID)
+	if err != nil {
+		return false, err
+	}
+	if existing == nil {
+		return false, nil
+	}
+	if idempotencyConflict(existing, userID, fundType, amount) {
+		return false, gerror.NewCodef(logic.CodeIdempotencyConflict, "幂等键已被不同的请求使用: %s, TransactionID=%d", businessID, existing.ID)
+	}
+	return true, nil
+}
+
+// getTransaction GET /transactions/{transaction_id}
+func (s *Server) getTransaction(r *ghttp.Request) {
+	transactionID, err := parseID("transaction_id", r.GetRouter("transaction_id").String())
+	if err != nil {
+		writeError(r, err)
+		return
+	}
+	if err := authorize(r.Context(), &wallet.APIKeyAccess{Permission: wallet.APIKeyPermissionTransactionsRead}); err != nil {
+		writeError(r, err)
+		return
+	}
+	record, err := s.transactions.GetTransactionByID(r.Context(), int64(transactionID))
+	if err != nil {
+		writeError(r, err)
+		return
+	}
+	if err := authorizeRecord(r.Context(), record); err != nil {
+		writeError(r, err)
+		return
+	}
+	writeData(r, record)
+}
+
+// getTransactionByReference GET /transactions/by-reference/{reference}
+func (s *Server) getTransactionByReference(r *ghttp.Request) {
+	reference := r.GetRouter("reference").String()
+	if err := authorize(r.Context(), &wallet.APIKeyAccess{Permission: wallet.APIKeyPermissionTransactionsRead}); err != nil {
+		writeError(r, err)
+		return
+	}
+	record, err := s.transactions.GetTransactionByReference(r.Context(), reference)
+	if err != nil {
+		writeError(r, err)
+		return
+	}
+	if record == nil {
+		writeError(r, gerror.NewCodef(gcode.CodeNotFound, "交易记录不存在: Reference=%s", reference))
+		return
+	}
+	if err := authorizeRecord(r.Context(), record); err != nil {
+		writeError(r, err)
+		return
+	}
+	writeData(r, record)
+}
+
+// listTransactions GET /users/{user_id}/transactions
+func (s *Server) listTransactions(r *ghttp.Request) {
+	userID, err := parseID("user_id", r.GetRouter("user_id").String())
+	if err != nil {
+		writeError(r, err)
+		return
+	}
+	query, err := parseHistoryQuery(userID, r.URL.Query())
+	if err != nil {
+		writeError(r, err)
+		return
+	}
+	if err := authorizeHistory(r.Context(), query); err != nil {
+		writeError(r, err)
+		return
+	}
+	page, err := s.manager.SearchTransactions(r.Context(), query)
+	if err != nil {
+		writeError(r, err)
+		return
+	}
+	writeData(r, page)
+}
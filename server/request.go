@@ -0,0 +1,132 @@
+package server
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet"
+	"github.com/yalks/wallet/constants"
+)
+
+// 请求和响应头
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"     // 幂等键，作为资金操作和转账的业务ID
+	HeaderIdempotentReplayed = "Idempotent-Replayed" // 幂等键已执行过时返回 true，响应为首次执行的结果
+)
+
+// decodeJSON 解析 JSON 请求体
+func decodeJSON(body []byte, v interface{}) error {
+	if len(body) == 0 {
+		return gerror.NewCode(gcode.CodeMissingParameter, "请求体不能为空")
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return gerror.WrapCode(gcode.CodeInvalidParameter, err, "请求体不是有效的 JSON")
+	}
+	return nil
+}
+
+// resolveBusinessID 合并 Idempotency-Key 请求头与请求体中的 business_id，二者都存在时必须相同
+func resolveBusinessID(header, body string) (string, error) {
+	header, body = strings.TrimSpace(header), strings.TrimSpace(body)
+	switch {
+	case header == "" && body == "":
+		return "", gerror.NewCodef(gcode.CodeMissingParameter, "缺少 %s 请求头或 business_id", HeaderIdempotencyKey)
+	case header == "":
+		return body, nil
+	case body != "" && body != header:
+		return "", gerror.NewCodef(gcode.CodeInvalidParameter, "%s 与 business_id 不一致", HeaderIdempotencyKey)
+	default:
+		return header, nil
+	}
+}
+
+// idempotencyConflict 判断已存在的交易是否与本次请求不同（用户、资金类型或金额不一致）
+func idempotencyConflict(existing *wallet.TransactionRecord, userID uint64, fundType constants.FundType, amount decimal.Decimal) bool {
+	recorded, err := decimal.NewFromString(existing.Amount)
+	if err != nil {
+		return true
+	}
+	return existing.UserID != int64(userID) || existing.FundType != fundType || !recorded.Equal(amount)
+}
+
+// parseID 解析路径中的正整数ID
+func parseID(name, value string) (uint64, error) {
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || id == 0 {
+		return 0, gerror.NewCodef(gcode.CodeInvalidParameter, "无效的 %s: %s", name, value)
+	}
+	return id, nil
+}
+
+// splitList 合并重复参数和逗号分隔的取值
+func splitList(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// parseHistoryQuery 将查询参数转换为交易查询条件；取值的业务校验由 SearchTransactions 完成
+func parseHistoryQuery(userID uint64, values url.Values) (*wallet.TransactionQuery, error) {
+	query := &wallet.TransactionQuery{
+		UserID:        int64(userID),
+		TokenSymbol:   values.Get("token"),
+		Direction:     constants.FundDirection(values.Get("direction")),
+		FundCategory:  values.Get("category"),
+		MinAmount:     values.Get("min_amount"),
+		MaxAmount:     values.Get("max_amount"),
+		RequestSource: values.Get("request_source"),
+		Tags:          splitList(values["tag"]),
+		Cursor:        values.Get("cursor"),
+	}
+	for _, fundType := range splitList(values["fund_type"]) {
+		query.FundTypes = append(query.FundTypes, constants.FundType(fundType))
+	}
+	for _, status := range splitList(values["status"]) {
+		query.Statuses = append(query.Statuses, constants.TransactionStatus(status))
+	}
+
+	for name, target := range map[string]**time.Time{"start_time": &query.StartTime, "end_time": &query.EndTime} {
+		if value := values.Get(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, gerror.NewCodef(gcode.CodeInvalidParameter, "%s 必须是 RFC 3339 时间: %s", name, value)
+			}
+			*target = &t
+		}
+	}
+	if value := values.Get("counterparty_id"); value != "" {
+		id, err := parseID("counterparty_id", value)
+		if err != nil {
+			return nil, err
+		}
+		query.CounterpartyID = int64(id)
+	}
+	if value := values.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return nil, gerror.NewCodef(gcode.CodeInvalidParameter, "无效的 limit: %s", value)
+		}
+		query.Limit = limit
+	}
+	if value := values.Get("ascending"); value != "" {
+		ascending, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, gerror.NewCodef(gcode.CodeInvalidParameter, "无效的 ascending: %s", value)
+		}
+		query.Ascending = ascending
+	}
+	return query, nil
+}
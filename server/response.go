@@ -0,0 +1,96 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+
+	"github.com/yalks/wallet/logic"
+)
+
+// Response 统一响应结构
+type Response struct {
+	Code    int         `json:"code"`           // 0 表示成功；失败时为钱包错误码，没有错误码时为 HTTP 状态码
+	Message string      `json:"message"`        // 错误信息，成功时为 ok
+	Data    interface{} `json:"data,omitempty"` // 响应数据；失败时可能包含错误详情（如限额重置时间、风控评估）
+}
+
+// StatusCode 将钱包错误映射为 HTTP 状态码；没有错误码的业务校验错误返回 422
+func StatusCode(err error) int {
+	switch gerror.Code(err) {
+	case gcode.CodeInvalidParameter, gcode.CodeMissingParameter, gcode.CodeValidationFailed:
+		return http.StatusBadRequest
+	case gcode.CodeNotAuthorized:
+		return http.StatusUnauthorized
+	case gcode.CodeNotFound:
+		return http.StatusNotFound
+	case logic.CodeIdempotencyConflict:
+		return http.StatusConflict
+	case logic.CodeResumeUnavailable:
+		return http.StatusGone
+	case logic.CodeInsufficientBalance, logic.CodeRequestExpired:
+		return http.StatusUnprocessableEntity
+	case logic.CodeVelocityLimit, logic.CodeAPIKeyRateLimited:
+		return http.StatusTooManyRequests
+	case logic.CodeRiskBlocked, logic.CodePolicyDenied, logic.CodePolicyApprovalRequired, logic.CodeAPIKeyForbidden:
+		return http.StatusForbidden
+	case logic.CodeRiskChallenge:
+		return http.StatusPreconditionRequired
+	case gcode.CodeDbOperationError, gcode.CodeInternalError, gcode.CodeInternalPanic, gcode.CodeServerBusy:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusUnprocessableEntity
+	}
+}
+
+// errorResponse 构造错误响应；服务端错误不返回内部信息
+func errorResponse(err error) (int, *Response) {
+	status := StatusCode(err)
+	response := &Response{Code: status, Message: err.Error()}
+	if code := gerror.Code(err); code != gcode.CodeNil && code.Code() >= 10000 {
+		response.Code = code.Code()
+	}
+	if status == http.StatusInternalServerError {
+		response.Message = http.StatusText(status)
+	}
+
+	var velocityErr *logic.VelocityLimitError
+	var riskErr *logic.RiskError
+	var policyErr *logic.PolicyError
+	var rateErr *logic.APIKeyRateLimitError
+	switch {
+	case errors.As(err, &velocityErr):
+		response.Data = g.Map{"rule": velocityErr.Rule, "reset_at": velocityErr.ResetAt}
+	case errors.As(err, &riskErr):
+		response.Data = riskErr.Assessment
+	case errors.As(err, &policyErr):
+		response.Data = g.Map{"rule": policyErr.Rule.Name, "action": policyErr.Rule.Action}
+	case errors.As(err, &rateErr):
+		response.Data = g.Map{"limit": rateErr.Limit, "retry_after": int(rateErr.RetryAfter.Seconds())}
+	}
+	return status, response
+}
+
+// writeData 写入成功响应
+func writeData(r *ghttp.Request, data interface{}) {
+	r.Response.WriteJson(&Response{Code: 0, Message: "ok", Data: data})
+}
+
+// writeError 写入错误响应
+func writeError(r *ghttp.Request, err error) {
+	status, response := errorResponse(err)
+	if status == http.StatusInternalServerError {
+		g.Log().Errorf(r.Context(), "HTTP 请求处理失败: %s %s, Error=%+v", r.Method, r.URL.Path, err)
+	}
+	var rateErr *logic.APIKeyRateLimitError
+	if errors.As(err, &rateErr) {
+		r.Response.Header().Set("Retry-After", strconv.Itoa(int(rateErr.RetryAfter.Seconds())))
+	}
+	r.Response.WriteHeader(status)
+	r.Response.WriteJson(response)
+}
@@ -0,0 +1,160 @@
+// Package server exposes the wallet manager as a JSON REST API on a GoFrame
+// ghttp server.
+//
+// Every response uses the envelope {"code", "message", "data"}: code is 0 on
+// success and the wallet error code (or the HTTP status) on failure. The
+// request context (source, client IP, user agent, metadata headers) is taken
+// from the HTTP request and attached to every wallet operation, and the
+// Idempotency-Key header is used as the business ID of fund operations and
+// transfers. GET /users/{user_id}/stream upgrades to a WebSocket that pushes
+// the user's committed balance and transaction events. With APIKeyAuth
+// enabled, every request must be signed with an API key, and the key's scope
+// limits the routes, users, tokens, fund types and amounts it can use. Start
+// refuses to run without APIKeyAuth unless AllowUnauthenticated is set.
+//
+// Run a standalone server after wallet.Initialize:
+//
+//	srv := server.New(wallet.Manager(), wallet.NewTransactionManager(), server.LoadConfig(ctx))
+//	if err := srv.Start(); err != nil {
+//	    return err
+//	}
+//	defer srv.Shutdown()
+//
+// or mount the routes into an existing ghttp server with Register.
+package server
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+
+	"github.com/yalks/wallet"
+	"github.com/yalks/wallet/logic"
+)
+
+// Config HTTP 服务配置（对应配置项 wallet.server）
+type Config struct {
+	Name       string `json:"name"`       // ghttp 服务名，默认 wallet
+	Address    string `json:"address"`    // 监听地址，默认 :8080
+	Prefix     string `json:"prefix"`     // 路由前缀，默认 /api/v1
+	TrustProxy bool   `json:"trustProxy"` // 是否信任 X-Forwarded-For 等代理头中的客户端 IP，仅在可信反向代理之后开启
+	APIKeyAuth bool   `json:"apiKeyAuth"` // 是否要求 API Key 签名认证，并按 Key 的权限范围限制请求
+
+	// AllowUnauthenticated 允许在未开启 APIKeyAuth 时启动服务，仅用于已由网关或 Use 中间件完成认证的部署
+	AllowUnauthenticated bool `json:"allowUnauthenticated"`
+
+	AllowedOrigins []string `json:"allowedOrigins"` // 允许建立 WebSocket 订阅的跨域来源，* 表示全部；默认只允许同源
+}
+
+// LoadConfig 从配置中读取 HTTP 服务配置，未配置的项使用默认值
+func LoadConfig(ctx context.Context) Config {
+	var config Config
+	value, err := g.Cfg().Get(ctx, "wallet.server")
+	if err == nil && value != nil && !value.IsEmpty() {
+		if err := value.Scan(&config); err != nil {
+			g.Log().Warningf(ctx, "HTTP 服务配置无效，使用默认配置: %v", err)
+			config = Config{}
+		}
+	}
+	return config.withDefaults()
+}
+
+// withDefaults 填充默认值
+func (c Config) withDefaults() Config {
+	if c.Name == "" {
+		c.Name = "wallet"
+	}
+	if c.Address == "" {
+		c.Address = ":8080"
+	}
+	if c.Prefix == "" {
+		c.Prefix = "/api/v1"
+	}
+	return c
+}
+
+// Server 钱包 REST API 服务
+type Server struct {
+	manager      wallet.IWalletManager
+	transactions wallet.ITransactionManager
+	config       Config
+	middlewares  []ghttp.HandlerFunc
+	http         *ghttp.Server
+
+	streamAuthorizer StreamAuthorizer
+}
+
+// New 创建钱包 REST API 服务
+func New(manager wallet.IWalletManager, transactions wallet.ITransactionManager, config Config) *Server {
+	return &Server{
+		manager:      manager,
+		transactions: transactions,
+		config:       config.withDefaults(),
+	}
+}
+
+// Use 添加路由中间件，在请求上下文和 API Key 认证中间件之后、处理函数之前执行；需在 Register 或 Start 之前调用
+func (s *Server) Use(middlewares ...ghttp.HandlerFunc) {
+	s.middlewares = append(s.middlewares, middlewares...)
+}
+
+// Register 在路由组上注册全部接口，可用于挂载到已有的 ghttp 服务；未开启 API Key 认证时由调用方负责认证
+func (s *Server) Register(group *ghttp.RouterGroup) {
+	group.Middleware(s.contextMiddleware)
+	if s.config.APIKeyAuth {
+		group.Middleware(s.authMiddleware)
+	} else if !s.config.AllowUnauthenticated {
+		g.Log().Warning(context.Background(), "钱包 HTTP 接口未开启 API Key 认证 (wallet.server.apiKeyAuth=false)，请确认已在上层完成认证")
+	}
+	group.Middleware(s.middlewares...)
+	group.GET("/balances/{user_id}/{token}", s.getBalance)
+	group.POST("/operations", s.processOperation)
+	group.POST("/quotes", s.quoteOperation)
+	group.POST("/transfers", s.processTransfer)
+	group.GET("/transactions/{transaction_id}", s.getTransaction)
+	group.GET("/transactions/by-reference/{reference}", s.getTransactionByReference)
+	group.GET("/users/{user_id}/transactions", s.listTransactions)
+	group.GET("/users/{user_id}/stream", s.streamEvents)
+}
+
+// Start 启动独立的 HTTP 服务（非阻塞）；未开启 API Key 认证且未设置 AllowUnauthenticated 时拒绝启动
+func (s *Server) Start() error {
+	if !s.config.APIKeyAuth && !s.config.AllowUnauthenticated {
+		return gerror.New("钱包 HTTP 服务未开启 API Key 认证，任何调用方都可以变动余额：请设置 wallet.server.apiKeyAuth=true，" +
+			"或在已由网关认证时设置 wallet.server.allowUnauthenticated=true")
+	}
+	s.http = g.Server(s.config.Name)
+	s.http.SetAddr(s.config.Address)
+	s.http.SetDumpRouterMap(false)
+	s.http.Group(s.config.Prefix, s.Register)
+	return s.http.Start()
+}
+
+// Port 返回实际监听的端口，未启动时返回 -1
+func (s *Server) Port() int {
+	if s.http == nil {
+		return -1
+	}
+	return s.http.GetListenedPort()
+}
+
+// Shutdown 停止 HTTP 服务
+func (s *Server) Shutdown() error {
+	if s.http == nil {
+		return nil
+	}
+	return s.http.Shutdown()
+}
+
+// contextMiddleware 从请求头填充 RequestContext；客户端 IP 默认取连接地址，TrustProxy 开启时取代理头
+func (s *Server) contextMiddleware(r *ghttp.Request) {
+	reqCtx := *logic.ExtractRequestContext(r.Context())
+	reqCtx.IP = r.GetRemoteIp()
+	if s.config.TrustProxy {
+		reqCtx.IP = r.GetClientIp()
+	}
+	r.SetCtx(logic.WithRequestContext(r.Context(), &reqCtx))
+	r.Middleware.Next()
+}
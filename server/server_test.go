@@ -0,0 +1,397 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet"
+	"github.com/yalks/wallet/constants"
+	"github.com/yalks/wallet/logic"
+)
+
+func TestStatusCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{gerror.NewCode(gcode.CodeInvalidParameter, "bad"), http.StatusBadRequest},
+		{gerror.NewCode(gcode.CodeNotFound, "missing"), http.StatusNotFound},
+		{gerror.Wrap(gerror.NewCode(logic.CodeInsufficientBalance, "余额不足"), "扣款失败"), http.StatusUnprocessableEntity},
+		{gerror.NewCode(logic.CodeIdempotencyConflict, "conflict"), http.StatusConflict},
+		{gerror.NewCode(logic.CodeResumeUnavailable, "resume"), http.StatusGone},
+		{gerror.NewCode(logic.CodeVelocityLimit, "limit"), http.StatusTooManyRequests},
+		{gerror.NewCode(logic.CodePolicyDenied, "denied"), http.StatusForbidden},
+		{gerror.NewCode(logic.CodeRiskChallenge, "challenge"), http.StatusPreconditionRequired},
+		{gerror.NewCode(gcode.CodeDbOperationError, "db"), http.StatusInternalServerError},
+		{gerror.New("金额必须大于0"), http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		if got := StatusCode(tt.err); got != tt.want {
+			t.Errorf("StatusCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	status, response := errorResponse(gerror.NewCode(logic.CodeInsufficientBalance, "余额不足"))
+	if status != http.StatusUnprocessableEntity || response.Code != logic.CodeInsufficientBalance.Code() || response.Message != "余额不足" {
+		t.Errorf("insufficient balance response = %d %+v", status, response)
+	}
+
+	status, response = errorResponse(gerror.NewCode(gcode.CodeDbOperationError, "connection refused"))
+	if status != http.StatusInternalServerError || response.Code != http.StatusInternalServerError || strings.Contains(response.Message, "connection") {
+		t.Errorf("internal error response leaks details: %d %+v", status, response)
+	}
+
+	rule, err := logic.ParsePolicyRule("cap", "deny if amount > 10")
+	if err != nil {
+		t.Fatalf("ParsePolicyRule: %v", err)
+	}
+	_, response = errorResponse(gerror.Wrap(&logic.PolicyError{Rule: rule}, "校验失败"))
+	if data, ok := response.Data.(map[string]interface{}); !ok || data["rule"] != "cap" {
+		t.Errorf("policy error data = %#v", response.Data)
+	}
+}
+
+func TestResolveBusinessID(t *testing.T) {
+	if id, err := resolveBusinessID("key-1", ""); err != nil || id != "key-1" {
+		t.Errorf("header only = %q, %v", id, err)
+	}
+	if id, err := resolveBusinessID("", "biz-1"); err != nil || id != "biz-1" {
+		t.Errorf("body only = %q, %v", id, err)
+	}
+	if id, err := resolveBusinessID("key-1", "key-1"); err != nil || id != "key-1" {
+		t.Errorf("matching = %q, %v", id, err)
+	}
+	if _, err := resolveBusinessID("key-1", "biz-1"); gerror.Code(err) != gcode.CodeInvalidParameter {
+		t.Errorf("mismatch error = %v", err)
+	}
+	if _, err := resolveBusinessID(" ", ""); gerror.Code(err) != gcode.CodeMissingParameter {
+		t.Errorf("missing error = %v", err)
+	}
+}
+
+func TestIdempotencyConflict(t *testing.T) {
+	existing := &wallet.TransactionRecord{UserID: 7, Amount: "10.50", FundType: constants.FundTypeDeposit}
+	if idempotencyConflict(existing, 7, constants.FundTypeDeposit, decimal.RequireFromString("10.5")) {
+		t.Error("same request reported as conflict")
+	}
+	if !idempotencyConflict(existing, 8, constants.FundTypeDeposit, decimal.RequireFromString("10.5")) {
+		t.Error("different user not reported as conflict")
+	}
+	if !idempotencyConflict(existing, 7, constants.FundTypeWithdraw, decimal.RequireFromString("10.5")) {
+		t.Error("different fund type not reported as conflict")
+	}
+	if !idempotencyConflict(existing, 7, constants.FundTypeDeposit, decimal.RequireFromString("11")) {
+		t.Error("different amount not reported as conflict")
+	}
+}
+
+func TestParseHistoryQuery(t *testing.T) {
+	values := url.Values{
+		"token":      {"USDT"},
+		"fund_type":  {"deposit,withdraw", "transfer_in"},
+		"status":     {"completed"},
+		"start_time": {"2024-01-01T00:00:00Z"},
+		"limit":      {"20"},
+		"ascending":  {"true"},
+	}
+	query, err := parseHistoryQuery(3, values)
+	if err != nil {
+		t.Fatalf("parseHistoryQuery: %v", err)
+	}
+	if query.UserID != 3 || query.TokenSymbol != "USDT" || len(query.FundTypes) != 3 || len(query.Statuses) != 1 ||
+		query.StartTime == nil || query.EndTime != nil || query.Limit != 20 || !query.Ascending {
+		t.Errorf("query = %+v", query)
+	}
+
+	for _, bad := range []url.Values{
+		{"start_time": {"yesterday"}},
+		{"limit": {"-1"}},
+		{"ascending": {"maybe"}},
+		{"counterparty_id": {"abc"}},
+	} {
+		if _, err := parseHistoryQuery(3, bad); gerror.Code(err) != gcode.CodeInvalidParameter {
+			t.Errorf("parseHistoryQuery(%v) error = %v", bad, err)
+		}
+	}
+}
+
+// stubManager 只实现测试用到的方法，其余方法调用时 panic
+type stubManager struct {
+	wallet.IWalletManager
+	requests []*constants.FundOperationRequest
+	contexts []*logic.RequestContext
+	key      *wallet.APIKey
+	secret   string
+}
+
+func (m *stubManager) GetBalance(ctx context.Context, userID uint64, tokenSymbol string) (*wallet.BalanceInfo, error) {
+	if userID != 1 {
+		return nil, gerror.NewCodef(gcode.CodeNotFound, "用户不存在: %d", userID)
+	}
+	return &wallet.BalanceInfo{UserID: userID, TokenSymbol: tokenSymbol, AvailableBalance: decimal.NewFromInt(100)}, nil
+}
+
+func (m *stubManager) RunInTransaction(ctx context.Context, fn func(ctx context.Context, tx gdb.TX) error) error {
+	return fn(ctx, nil)
+}
+
+func (m *stubManager) ProcessFundOperationInTx(ctx context.Context, tx gdb.TX, req *constants.FundOperationRequest) (*wallet.FundOperationResult, error) {
+	m.requests = append(m.requests, req)
+	m.contexts = append(m.contexts, logic.ExtractRequestContext(ctx))
+	return &wallet.FundOperationResult{TransactionID: "42", Successful: true}, nil
+}
+
+func (m *stubManager) AuthenticateAPIKey(ctx context.Context, req *wallet.APIKeyRequest) (*wallet.APIKey, error) {
+	if req.KeyID != m.key.KeyID {
+		return nil, gerror.NewCode(gcode.CodeNotAuthorized, "API Key 无效")
+	}
+	if req.Signature != wallet.SignAPIRequest(m.secret, req.Method, req.Path, req.RawQuery, req.Timestamp, req.Nonce, req.Body) {
+		return nil, gerror.NewCode(gcode.CodeNotAuthorized, "请求签名无效")
+	}
+	return m.key, nil
+}
+
+type stubTransactions struct {
+	wallet.ITransactionManager
+	records map[string]*wallet.TransactionRecord
+}
+
+func (t *stubTransactions) GetTransactionByReference(ctx context.Context, reference string) (*wallet.TransactionRecord, error) {
+	return t.records[reference], nil
+}
+
+func TestServerRoundTrip(t *testing.T) {
+	manager := &stubManager{}
+	transactions := &stubTransactions{records: map[string]*wallet.TransactionRecord{
+		"dep-1": {ID: 42, UserID: 1, Amount: "5", FundType: constants.FundTypeDeposit},
+	}}
+	srv := New(manager, transactions, Config{Name: "wallet-server-test", Address: "127.0.0.1:0", AllowUnauthenticated: true})
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer srv.Shutdown()
+	base := fmt.Sprintf("http://127.0.0.1:%d/api/v1", srv.Port())
+
+	do := func(method, path, key, body string) (*http.Response, *Response) {
+		req, err := http.NewRequest(method, base+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("NewRequest: %v", err)
+		}
+		req.Header.Set("User-Agent", "server-test")
+		if key != "" {
+			req.Header.Set(HeaderIdempotencyKey, key)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		defer resp.Body.Close()
+		raw, _ := io.ReadAll(resp.Body)
+		var envelope Response
+		if err := json.Unmarshal(raw, &envelope); err != nil {
+			t.Fatalf("%s %s: invalid body %q", method, path, raw)
+		}
+		return resp, &envelope
+	}
+
+	if resp, body := do(http.MethodGet, "/balances/1/USDT", "", ""); resp.StatusCode != http.StatusOK || body.Code != 0 {
+		t.Errorf("balance = %d %+v", resp.StatusCode, body)
+	}
+	if resp, _ := do(http.MethodGet, "/balances/2/USDT", "", ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown user status = %d", resp.StatusCode)
+	}
+	if resp, _ := do(http.MethodGet, "/balances/x/USDT", "", ""); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid user id status = %d", resp.StatusCode)
+	}
+
+	operation := `{"user_id":1,"token_symbol":"USDT","amount":"5","fund_type":"deposit","request_source":"admin"}`
+	resp, body := do(http.MethodPost, "/operations", "dep-2", operation)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get(HeaderIdempotentReplayed) != "" {
+		t.Fatalf("operation = %d %+v", resp.StatusCode, body)
+	}
+	req := manager.requests[len(manager.requests)-1]
+	if req.BusinessID != "dep-2" || req.RequestUserAgent != "server-test" || req.RequestIP == "" || req.RequestSource == "admin" {
+		t.Errorf("operation request context = %+v", req)
+	}
+
+	if resp, _ := do(http.MethodPost, "/operations", "dep-1", operation); resp.Header.Get(HeaderIdempotentReplayed) != "true" {
+		t.Errorf("replayed operation missing %s header", HeaderIdempotentReplayed)
+	}
+	conflicting := strings.Replace(operation, `"amount":"5"`, `"amount":"6"`, 1)
+	if resp, body := do(http.MethodPost, "/operations", "dep-1", conflicting); resp.StatusCode != http.StatusConflict || body.Code != logic.CodeIdempotencyConflict.Code() {
+		t.Errorf("conflicting operation = %d %+v", resp.StatusCode, body)
+	}
+	if resp, _ := do(http.MethodPost, "/operations", "", operation); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("operation without idempotency key status = %d", resp.StatusCode)
+	}
+	// 未启用 API Key 认证且未设置订阅授权时拒绝订阅
+	if resp, _ := do(http.MethodGet, "/users/1/stream", "", ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("unauthorized stream status = %d", resp.StatusCode)
+	}
+}
+
+func TestStartRequiresAuth(t *testing.T) {
+	srv := New(&stubManager{}, &stubTransactions{}, Config{Name: "wallet-server-noauth-test", Address: "127.0.0.1:0"})
+	if err := srv.Start(); err == nil {
+		srv.Shutdown()
+		t.Fatal("started without API key auth or an explicit opt-out")
+	}
+	if srv.Port() != -1 {
+		t.Errorf("port = %d, want -1", srv.Port())
+	}
+}
+
+func TestParseLastEventID(t *testing.T) {
+	tests := []struct {
+		query, header string
+		want          int64
+	}{
+		{"", "", 0},
+		{"12", "", 12},
+		{"", "7", 7},
+		{"12", "7", 12},
+	}
+	for _, tt := range tests {
+		if got, err := parseLastEventID(tt.query, tt.header); err != nil || got != tt.want {
+			t.Errorf("parseLastEventID(%q, %q) = %d, %v", tt.query, tt.header, got, err)
+		}
+	}
+	for _, bad := range []string{"-1", "abc"} {
+		if _, err := parseLastEventID(bad, ""); gerror.Code(err) != gcode.CodeInvalidParameter {
+			t.Errorf("parseLastEventID(%q) error = %v", bad, err)
+		}
+	}
+}
+
+func TestCheckOrigin(t *testing.T) {
+	srv := New(&stubManager{}, &stubTransactions{}, Config{AllowedOrigins: []string{"https://app.example.com"}})
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"", true},
+		{"https://wallet.example.com", true},
+		{"https://app.example.com", true},
+		{"https://evil.example.com", false},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, "https://wallet.example.com/api/v1/users/1/stream", nil)
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		if got := srv.checkOrigin(req); got != tt.want {
+			t.Errorf("checkOrigin(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestServerAPIKeyAuth(t *testing.T) {
+	manager := &stubManager{
+		secret: "wsk_test",
+		key: &wallet.APIKey{KeyID: "wk_test", Name: "red-packet", Scope: wallet.APIKeyScope{
+			Permissions: []wallet.APIKeyPermission{wallet.APIKeyPermissionOperationsWrite},
+			FundTypes:   []constants.FundType{constants.FundTypeRedPacketClaim},
+			UserIDs:     []uint64{1},
+			MaxAmount:   decimal.NewFromInt(10),
+		}},
+	}
+	srv := New(manager, &stubTransactions{}, Config{Name: "wallet-server-auth-test", Address: "127.0.0.1:0", APIKeyAuth: true})
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer srv.Shutdown()
+	base := fmt.Sprintf("http://127.0.0.1:%d", srv.Port())
+
+	nonce := 0
+	do := func(method, path, body string, sign bool) *http.Response {
+		req, err := http.NewRequest(method, base+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("NewRequest: %v", err)
+		}
+		if sign {
+			nonce++
+			timestamp, nonceValue := "1700000000", fmt.Sprintf("nonce-%04d", nonce)
+			req.Header.Set(HeaderAPIKey, manager.key.KeyID)
+			req.Header.Set(HeaderTimestamp, timestamp)
+			req.Header.Set(HeaderNonce, nonceValue)
+			req.Header.Set(HeaderSignature, wallet.SignAPIRequest(manager.secret, method, req.URL.Path, req.URL.RawQuery, timestamp, nonceValue, []byte(body)))
+		}
+		req.Header.Set("X-Request-Source", "admin")
+		req.Header.Set(HeaderIdempotencyKey, fmt.Sprintf("rp-%d", nonce))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	claim := `{"user_id":1,"token_symbol":"USDT","amount":"5","fund_type":"red_packet_claim"}`
+	if resp := do(http.MethodPost, "/api/v1/operations", claim, false); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("unsigned request status = %d", resp.StatusCode)
+	}
+	if resp := do(http.MethodPost, "/api/v1/operations", claim, true); resp.StatusCode != http.StatusOK {
+		t.Fatalf("signed request status = %d", resp.StatusCode)
+	}
+	req, reqCtx := manager.requests[len(manager.requests)-1], manager.contexts[len(manager.contexts)-1]
+	if req.RequestSource != "api" || reqCtx.Metadata["api_key_id"] != "wk_test" || reqCtx.Metadata["api_key_name"] != "red-packet" {
+		t.Errorf("request source = %q, metadata = %v", req.RequestSource, reqCtx.Metadata)
+	}
+
+	forbidden := map[string]string{
+		"fund type": `{"user_id":1,"token_symbol":"USDT","amount":"5","fund_type":"withdraw"}`,
+		"user":      `{"user_id":2,"token_symbol":"USDT","amount":"5","fund_type":"red_packet_claim"}`,
+		"amount":    `{"user_id":1,"token_symbol":"USDT","amount":"11","fund_type":"red_packet_claim"}`,
+	}
+	for name, body := range forbidden {
+		if resp := do(http.MethodPost, "/api/v1/operations", body, true); resp.StatusCode != http.StatusForbidden {
+			t.Errorf("%s: status = %d", name, resp.StatusCode)
+		}
+	}
+	if resp := do(http.MethodGet, "/api/v1/balances/1/USDT", "", true); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("balance without permission status = %d", resp.StatusCode)
+	}
+	if resp := do(http.MethodGet, "/api/v1/users/1/stream", "", true); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("stream without permission status = %d", resp.StatusCode)
+	}
+	spoofed := `{"user_id":1,"token_symbol":"USDT","amount":"5","fund_type":"red_packet_claim","metadata":{"api_key_id":"wk_other"}}`
+	if resp := do(http.MethodPost, "/api/v1/operations", spoofed, true); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("reserved metadata status = %d", resp.StatusCode)
+	}
+}
+
+func TestAuthorizeRecord(t *testing.T) {
+	record := &wallet.TransactionRecord{ID: 42, UserID: 1, TokenSymbol: "USDT"}
+	if err := authorizeRecord(context.Background(), record); err != nil {
+		t.Errorf("without API key: %v", err)
+	}
+
+	ctx := wallet.WithAPIKey(context.Background(), &wallet.APIKey{KeyID: "wk_test", Scope: wallet.APIKeyScope{
+		UserIDs: []uint64{1},
+		Tokens:  []string{"usdt"},
+	}})
+	if err := authorizeRecord(ctx, record); err != nil {
+		t.Errorf("allowed record: %v", err)
+	}
+	for name, denied := range map[string]*wallet.TransactionRecord{
+		"user":  {ID: 43, UserID: 2, TokenSymbol: "USDT"},
+		"token": {ID: 44, UserID: 1, TokenSymbol: "BTC"},
+	} {
+		if err := authorizeRecord(ctx, denied); gerror.Code(err) != wallet.CodeAPIKeyForbidden {
+			t.Errorf("%s: err = %v", name, err)
+		}
+	}
+}
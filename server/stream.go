@@ -0,0 +1,195 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gorilla/websocket"
+
+	"github.com/yalks/wallet"
+)
+
+// WebSocket 连接参数
+const (
+	streamWriteWait    = 10 * time.Second // 单帧写超时
+	streamPongWait     = 60 * time.Second // 等待客户端 pong 的最长时间
+	streamPingInterval = 30 * time.Second // 服务端 ping 间隔，需小于 streamPongWait
+)
+
+// HeaderLastEventID 断线重连时携带的最后事件ID，也可以用查询参数 last_event_id
+const HeaderLastEventID = "Last-Event-ID"
+
+// StreamAuthorizer 校验请求能否订阅该用户的余额与交易变动，返回错误时拒绝连接。
+// 浏览器无法对 WebSocket 握手签名，可以用它校验会话 Cookie 或查询参数中的令牌
+type StreamAuthorizer func(r *ghttp.Request, userID uint64) error
+
+// StreamMessage WebSocket 推送的消息帧
+type StreamMessage struct {
+	Type    string              `json:"type"`              // event：领域事件；error：订阅被关闭，随后服务端关闭连接
+	Event   *wallet.DomainEvent `json:"event,omitempty"`   // type=event 时的事件，事件ID用于断线续传
+	Code    int                 `json:"code,omitempty"`    // type=error 时的错误码
+	Message string              `json:"message,omitempty"` // type=error 时的错误信息
+}
+
+// SetStreamAuthorizer 设置订阅授权校验；需在 Register 或 Start 之前调用。
+// 请求通过 API Key 认证时先校验 Key 的 events:read 权限和用户范围，再调用 authorizer；
+// 既没有 API Key 也没有设置 authorizer 时拒绝订阅
+func (s *Server) SetStreamAuthorizer(authorizer StreamAuthorizer) {
+	s.streamAuthorizer = authorizer
+}
+
+// authorizeStream 校验订阅权限，返回允许推送的代币过滤函数
+func (s *Server) authorizeStream(r *ghttp.Request, userID uint64) (func(token string) bool, error) {
+	allows := func(string) bool { return true }
+	key := wallet.APIKeyFromContext(r.Context())
+	if key == nil && s.streamAuthorizer == nil {
+		return nil, gerror.NewCode(gcode.CodeNotAuthorized, "订阅需要 API Key 认证或订阅授权校验")
+	}
+	if key != nil {
+		if err := authorize(r.Context(), &wallet.APIKeyAccess{
+			Permission: wallet.APIKeyPermissionEventsRead,
+			UserIDs:    []uint64{userID},
+		}); err != nil {
+			return nil, err
+		}
+		allows = key.Scope.AllowsToken
+	}
+	if s.streamAuthorizer != nil {
+		if err := s.streamAuthorizer(r, userID); err != nil {
+			return nil, err
+		}
+	}
+	return allows, nil
+}
+
+// parseLastEventID 解析续传位置，查询参数优先于请求头；都未提供时返回 0
+func parseLastEventID(query, header string) (int64, error) {
+	value := query
+	if value == "" {
+		value = header
+	}
+	if value == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0, gerror.NewCodef(gcode.CodeInvalidParameter, "无效的 last_event_id: %s", value)
+	}
+	return id, nil
+}
+
+// checkOrigin 校验浏览器握手的 Origin：没有 Origin 的非浏览器客户端放行，
+// 同源请求放行，其余只放行 AllowedOrigins 中的来源（* 表示全部）
+func (s *Server) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, allowed := range s.config.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// streamEvents GET /users/{user_id}/stream
+// 升级为 WebSocket 连接，推送用户已提交的余额与交易变动事件
+func (s *Server) streamEvents(r *ghttp.Request) {
+	userID, err := parseID("user_id", r.GetRouter("user_id").String())
+	if err != nil {
+		writeError(r, err)
+		return
+	}
+	allows, err := s.authorizeStream(r, userID)
+	if err != nil {
+		writeError(r, err)
+		return
+	}
+	lastEventID, err := parseLastEventID(r.URL.Query().Get("last_event_id"), r.Header.Get(HeaderLastEventID))
+	if err != nil {
+		writeError(r, err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	// 握手前订阅，补发失败等错误仍以普通 HTTP 响应返回
+	sub, err := s.manager.Subscribe(ctx, userID, lastEventID)
+	if err != nil {
+		writeError(r, err)
+		return
+	}
+	defer sub.Close()
+
+	upgrader := websocket.Upgrader{CheckOrigin: s.checkOrigin}
+	conn, err := upgrader.Upgrade(r.Response.Writer, r.Request, nil)
+	if err != nil {
+		// Upgrade 已写入错误响应
+		g.Log().Debugf(ctx, "WebSocket 握手失败: UserID=%d, Error=%v", userID, err)
+		return
+	}
+	defer conn.Close()
+	g.Log().Debugf(ctx, "WebSocket 订阅已连接: UserID=%d, LastEventID=%d", userID, lastEventID)
+
+	// 读循环只处理控制帧，连接断开时结束订阅
+	go func() {
+		defer cancel()
+		conn.SetReadLimit(512)
+		conn.SetReadDeadline(time.Now().Add(streamPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(streamPongWait))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(streamPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				s.closeStream(conn, sub.Err())
+				return
+			}
+			if !allows(event.TokenSymbol) {
+				continue
+			}
+			conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+			if err := conn.WriteJSON(&StreamMessage{Type: "event", Event: event}); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteWait)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// closeStream 订阅关闭时发送错误帧（如有）并正常关闭连接
+func (s *Server) closeStream(conn *websocket.Conn, err error) {
+	conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+	closeCode := websocket.CloseNormalClosure
+	if err != nil {
+		_, response := errorResponse(err)
+		conn.WriteJSON(&StreamMessage{Type: "error", Code: response.Code, Message: response.Message})
+		closeCode = websocket.CloseTryAgainLater
+	}
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(closeCode, ""), time.Now().Add(streamWriteWait))
+}
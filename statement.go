@@ -0,0 +1,126 @@
+package wallet
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+
+	"github.com/yalks/wallet/constants"
+	"github.com/yalks/wallet/dao"
+	"github.com/yalks/wallet/logic"
+)
+
+// StatementFormat 对账单输出格式
+type StatementFormat = logic.StatementFormat
+
+const (
+	StatementFormatCSV   = logic.StatementFormatCSV   // CSV: record_type,time,transaction_id,token,fund_type,direction,amount,fee,balance,counterparty_id,counterparty,reference,memo
+	StatementFormatJSONL = logic.StatementFormatJSONL // JSON Lines，每行一个 opening/transaction/closing 对象
+)
+
+// statementPageSize 导出对账单时每次从数据库读取的交易数
+const statementPageSize = 500
+
+// ExportStatement 导出用户某代币在 [from, to) 区间的对账单：期初余额、逐笔交易（含累计余额、手续费、交易对手）
+// 和期末余额。按键集分页流式读取并写入 w，数字按用户语言（Users.Language）格式化
+func (m *walletManager) ExportStatement(ctx context.Context, userID uint64, tokenSymbol string, from, to time.Time, format StatementFormat, w io.Writer) (*StatementSummary, error) {
+	if w == nil {
+		return nil, gerror.New("对账单输出不能为空")
+	}
+	if !from.Before(to) {
+		return nil, gerror.New("开始时间必须早于结束时间")
+	}
+
+	user, err := m.userLogic.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "获取用户信息失败: UserID=%d", userID)
+	}
+	token, err := m.tokenLogic.GetTokenBySymbol(ctx, tokenSymbol)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "获取代币信息失败: Symbol=%s", tokenSymbol)
+	}
+
+	writer, err := logic.NewStatementWriter(format, w, token.Symbol, logic.LocaleNumberFormat(user.Language), int32(token.Decimals))
+	if err != nil {
+		return nil, err
+	}
+
+	// 期初余额：区间开始时刻的余额（优先使用日终快照）
+	openingBalance, _, err := m.snapshots.balanceAt(ctx, userID, token.Symbol, from)
+	if err != nil {
+		return nil, err
+	}
+	summary := &StatementSummary{UserID: userID, TokenSymbol: token.Symbol, OpeningBalance: openingBalance}
+	if err := writer.WriteOpening(from, summary.OpeningBalance); err != nil {
+		return nil, err
+	}
+
+	filter := &dao.TransactionFilter{
+		UserID:     userID,
+		Symbol:     token.Symbol,
+		WalletType: string(constants.WalletTypeAvailable),
+		Statuses:   []uint{uint(statusToInt(constants.TransactionStatusCompleted))},
+		StartTime:  gtime.New(from),
+		EndTime:    gtime.New(to),
+		Ascending:  true,
+	}
+	transactionDAO := logic.GetSharedContext().GetTransactionDAO()
+	balance := summary.OpeningBalance
+	for {
+		transactions, err := transactionDAO.SearchTransactions(ctx, filter, statementPageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tx := range transactions {
+			if constants.FundDirection(tx.Direction) == constants.FundDirectionOut {
+				balance = balance.Sub(tx.Amount)
+				summary.TotalOut = summary.TotalOut.Add(tx.Amount)
+			} else {
+				balance = balance.Add(tx.Amount)
+				summary.TotalIn = summary.TotalIn.Add(tx.Amount)
+			}
+			summary.TotalFees = summary.TotalFees.Add(tx.FeeAmount)
+			summary.TransactionCount++
+
+			entry := &logic.StatementEntry{
+				TransactionID:    tx.TransactionId,
+				FundType:         tx.Type,
+				Direction:        tx.Direction,
+				Amount:           tx.Amount,
+				Fee:              tx.FeeAmount,
+				Balance:          balance,
+				CounterpartyID:   uint64(tx.TargetUserId),
+				CounterpartyName: tx.TargetUsername,
+				Reference:        tx.BusinessId,
+				Memo:             tx.Memo,
+			}
+			if tx.CreatedAt != nil {
+				entry.Time = tx.CreatedAt.Time
+			}
+			if err := writer.WriteEntry(entry); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(transactions) < statementPageSize {
+			break
+		}
+		lastTx := transactions[len(transactions)-1]
+		filter.AfterCreatedAt = lastTx.CreatedAt
+		filter.AfterID = lastTx.TransactionId
+	}
+
+	summary.ClosingBalance = balance
+	if err := writer.WriteClosing(to, balance); err != nil {
+		return nil, err
+	}
+
+	g.Log().Infof(ctx, "对账单导出完成: UserID=%d, Symbol=%s, Format=%s, Transactions=%d",
+		userID, token.Symbol, format, summary.TransactionCount)
+	return summary, nil
+}
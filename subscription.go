@@ -0,0 +1,360 @@
+package wallet
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+
+	"github.com/yalks/wallet/logic"
+)
+
+// SubscriptionConfig 余额与交易订阅配置（对应配置项 wallet.subscriptions）
+type SubscriptionConfig = logic.SubscriptionConfig
+
+// 订阅错误码，可通过 gerror.Code(err) 识别
+var (
+	CodeSubscriptionLagged = logic.CodeSubscriptionLagged // Subscription.Err：消费过慢被断开，可用 LastEventID 重新订阅
+	CodeResumeUnavailable  = logic.CodeResumeUnavailable  // Subscribe：需要补发的事件过多，应重新获取余额后从最新位置订阅
+)
+
+// Subscription 用户的余额与交易变动订阅。
+// Events 按事件ID递增推送已提交的领域事件（funds.credited、funds.debited、transfer.completed、
+// transaction.status_changed）；订阅关闭后通道被关闭，Err 返回关闭原因
+type Subscription struct {
+	UserID uint64
+
+	hub    *subscriptionHub
+	events chan *DomainEvent
+	notify chan struct{}
+	done   chan struct{}
+	lastID atomic.Int64
+
+	mu     sync.Mutex
+	queue  []*DomainEvent
+	closed bool
+	err    error
+}
+
+// Events 事件通道
+func (s *Subscription) Events() <-chan *DomainEvent {
+	return s.events
+}
+
+// LastEventID 已推送的最后事件ID，重新订阅时传入以续传
+func (s *Subscription) LastEventID() int64 {
+	return s.lastID.Load()
+}
+
+// Err 订阅关闭原因；调用方主动关闭或 ctx 结束时为 nil
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Close 关闭订阅
+func (s *Subscription) Close() {
+	s.mu.Lock()
+	s.closeLocked(nil)
+	s.mu.Unlock()
+	s.hub.remove(s)
+}
+
+// closeLocked 标记订阅关闭，推送协程随后关闭事件通道
+func (s *Subscription) closeLocked(err error) {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.err = err
+	s.queue = nil
+	close(s.done)
+}
+
+// offer 将实时事件加入推送队列；队列已满时关闭订阅，避免慢消费者拖慢其他订阅
+func (s *Subscription) offer(event *DomainEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	if len(s.queue) >= s.hub.config.BufferSize {
+		s.closeLocked(gerror.NewCodef(CodeSubscriptionLagged, "订阅消费过慢已断开: UserID=%d, LastEventID=%d", s.UserID, s.lastID.Load()))
+		return
+	}
+	s.queue = append(s.queue, event)
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// pump 推送协程：先推送补发的历史事件，再推送实时事件；通道只在这里写入和关闭
+func (s *Subscription) pump(replay []*DomainEvent) {
+	defer close(s.events)
+	for _, event := range replay {
+		if !s.send(event) {
+			return
+		}
+	}
+	for {
+		s.mu.Lock()
+		queue := s.queue
+		s.queue = nil
+		s.mu.Unlock()
+		for _, event := range queue {
+			if !s.send(event) {
+				return
+			}
+		}
+		select {
+		case <-s.notify:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// send 推送单个事件，已推送过的事件ID被跳过
+func (s *Subscription) send(event *DomainEvent) bool {
+	if event.ID <= s.lastID.Load() {
+		return true
+	}
+	select {
+	case s.events <- event:
+		s.lastID.Store(event.ID)
+		return true
+	case <-s.done:
+		return false
+	}
+}
+
+// subscriptionHub 订阅中心：有订阅时在后台扫描领域事件表，按事件ID顺序放行并分发给对应用户的订阅
+type subscriptionHub struct {
+	context   *logic.SharedLogicContext
+	config    logic.SubscriptionConfig
+	configErr error
+	wake      chan struct{}
+
+	mu          sync.Mutex
+	subscribers map[uint64]map[*Subscription]struct{}
+	count       int
+	sequencer   *logic.EventSequencer
+	stop        chan struct{}
+}
+
+// newSubscriptionHub 创建订阅中心；配置无效时 Subscribe 返回错误
+func newSubscriptionHub(ctx context.Context) *subscriptionHub {
+	hub := &subscriptionHub{
+		context:     logic.GetSharedContext(),
+		wake:        make(chan struct{}, 1),
+		subscribers: make(map[uint64]map[*Subscription]struct{}),
+	}
+	hub.config, hub.configErr = loadSubscriptionConfig(ctx)
+	return hub
+}
+
+// loadSubscriptionConfig 读取订阅配置（wallet.subscriptions），配置无效时返回错误
+func loadSubscriptionConfig(ctx context.Context) (logic.SubscriptionConfig, error) {
+	value, err := g.Cfg().Get(ctx, "wallet.subscriptions")
+	if err != nil {
+		return logic.SubscriptionConfig{}, gerror.Wrap(err, "读取订阅配置失败")
+	}
+	var raw map[string]interface{}
+	if value != nil && !value.IsEmpty() {
+		raw = value.Map()
+	}
+	config, err := logic.ParseSubscriptionConfig(raw)
+	if err != nil {
+		return logic.SubscriptionConfig{}, gerror.Wrap(err, "订阅配置无效")
+	}
+	return config, nil
+}
+
+// Subscribe 订阅用户的余额与交易变动。lastEventID 为 0 时从当前位置开始推送；
+// 大于 0 时先补发该事件之后的历史事件，再推送实时事件，用于断线重连后续传。
+// ctx 结束或调用 Close 后订阅关闭
+func (m *walletManager) Subscribe(ctx context.Context, userID uint64, lastEventID int64) (*Subscription, error) {
+	return m.subscriptions.subscribe(ctx, userID, lastEventID)
+}
+
+// notifySubscriptions 事务提交后唤醒订阅中心立即扫描，不阻塞调用方
+func (m *walletManager) notifySubscriptions() {
+	select {
+	case m.subscriptions.wake <- struct{}{}:
+	default:
+	}
+}
+
+// subscribe 注册订阅；需要补发时先在注册的同一时刻确定补发范围 (lastEventID, position]，
+// 之后的事件由订阅中心实时推送，两部分按ID顺序衔接
+func (h *subscriptionHub) subscribe(ctx context.Context, userID uint64, lastEventID int64) (*Subscription, error) {
+	if h.configErr != nil {
+		return nil, h.configErr
+	}
+	if userID == 0 {
+		return nil, gerror.NewCode(gcode.CodeInvalidParameter, "用户ID不能为空")
+	}
+	if lastEventID < 0 {
+		return nil, gerror.NewCodef(gcode.CodeInvalidParameter, "无效的事件ID: %d", lastEventID)
+	}
+
+	sub := &Subscription{
+		UserID: userID,
+		hub:    h,
+		events: make(chan *DomainEvent, h.config.BufferSize),
+		notify: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	if err := h.startLocked(ctx); err != nil {
+		h.mu.Unlock()
+		return nil, err
+	}
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[*Subscription]struct{})
+	}
+	h.subscribers[userID][sub] = struct{}{}
+	h.count++
+	position := h.sequencer.Position()
+	h.mu.Unlock()
+
+	var replay []*DomainEvent
+	if lastEventID == 0 || lastEventID >= position {
+		sub.lastID.Store(max(lastEventID, position))
+	} else {
+		sub.lastID.Store(lastEventID)
+		records, err := h.context.GetDomainEventDAO().ListUserEventsBetween(ctx, userID, lastEventID, position, h.config.MaxReplay+1)
+		if err != nil {
+			sub.Close()
+			return nil, err
+		}
+		if len(records) > h.config.MaxReplay {
+			sub.Close()
+			return nil, gerror.NewCodef(CodeResumeUnavailable, "事件 %d 之后需要补发的事件超过 %d 个，请重新获取余额后订阅", lastEventID, h.config.MaxReplay)
+		}
+		for _, record := range records {
+			replay = append(replay, logic.ConvertToDomainEvent(record))
+		}
+	}
+
+	go sub.pump(replay)
+	go func() {
+		select {
+		case <-ctx.Done():
+			sub.Close()
+		case <-sub.done:
+		}
+	}()
+	g.Log().Debugf(ctx, "订阅已创建: UserID=%d, LastEventID=%d, Replay=%d", userID, lastEventID, len(replay))
+	return sub, nil
+}
+
+// startLocked 第一个订阅注册时从当前最大事件ID开始扫描
+func (h *subscriptionHub) startLocked(ctx context.Context) error {
+	if h.stop != nil {
+		return nil
+	}
+	position, err := h.context.GetDomainEventDAO().GetMaxEventID(ctx)
+	if err != nil {
+		return err
+	}
+	h.sequencer = logic.NewEventSequencer(position)
+	h.stop = make(chan struct{})
+	go h.run(context.WithoutCancel(ctx), h.stop)
+	g.Log().Infof(ctx, "订阅中心已启动: Position=%d, PollInterval=%s", position, h.config.PollInterval)
+	return nil
+}
+
+// remove 移除订阅，最后一个订阅移除后停止扫描
+func (h *subscriptionHub) remove(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeLocked(sub)
+}
+
+// removeLocked 移除订阅（调用方持有 h.mu）
+func (h *subscriptionHub) removeLocked(sub *Subscription) {
+	subs := h.subscribers[sub.UserID]
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.subscribers, sub.UserID)
+	}
+	h.count--
+	if h.count == 0 && h.stop != nil {
+		close(h.stop)
+		h.stop = nil
+		h.sequencer = nil
+	}
+}
+
+// run 扫描循环：按间隔扫描，或在本进程事务提交后被唤醒
+func (h *subscriptionHub) run(ctx context.Context, stop chan struct{}) {
+	ticker := time.NewTicker(h.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		case <-h.wake:
+		}
+		if err := h.poll(ctx, stop); err != nil {
+			g.Log().Warningf(ctx, "扫描订阅事件失败: %v", err)
+		}
+	}
+}
+
+// poll 读取当前位置之后的事件并按ID顺序分发
+func (h *subscriptionHub) poll(ctx context.Context, stop chan struct{}) error {
+	h.mu.Lock()
+	if h.stop != stop {
+		h.mu.Unlock()
+		return nil
+	}
+	position := h.sequencer.Position()
+	h.mu.Unlock()
+
+	records, err := h.context.GetDomainEventDAO().ListEventsAfter(ctx, position, h.config.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	// 扫描期间全部订阅已移除（可能又有新订阅重新启动），本轮结果作废
+	if h.stop != stop {
+		return nil
+	}
+	for _, record := range records {
+		h.sequencer.Add(logic.ConvertToDomainEvent(record))
+	}
+	for _, event := range h.sequencer.Ready(time.Now(), h.config.GapTimeout) {
+		for sub := range h.subscribers[event.UserID] {
+			sub.offer(event)
+			if sub.isClosed() {
+				h.removeLocked(sub)
+			}
+		}
+		if h.stop != stop {
+			return nil
+		}
+	}
+	return nil
+}
+
+// isClosed 订阅是否已关闭
+func (s *Subscription) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
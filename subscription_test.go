@@ -0,0 +1,81 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+
+	"github.com/yalks/wallet/logic"
+)
+
+// newTestSubscription 创建不依赖数据库的订阅，直接注册到订阅中心
+func newTestSubscription(bufferSize int) (*subscriptionHub, *Subscription) {
+	hub := &subscriptionHub{
+		config:      logic.SubscriptionConfig{BufferSize: bufferSize},
+		subscribers: make(map[uint64]map[*Subscription]struct{}),
+	}
+	sub := &Subscription{
+		UserID: 7,
+		hub:    hub,
+		events: make(chan *DomainEvent, bufferSize),
+		notify: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	hub.subscribers[7] = map[*Subscription]struct{}{sub: {}}
+	hub.count = 1
+	return hub, sub
+}
+
+func receive(t *testing.T, sub *Subscription) *DomainEvent {
+	t.Helper()
+	select {
+	case event := <-sub.Events():
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+		return nil
+	}
+}
+
+func TestSubscriptionReplayThenLive(t *testing.T) {
+	hub, sub := newTestSubscription(8)
+	sub.lastID.Store(2)
+
+	// 补发期间到达的实时事件排在补发事件之后，重复的事件只推送一次
+	sub.offer(&DomainEvent{ID: 5})
+	go sub.pump([]*DomainEvent{{ID: 3}, {ID: 4}})
+	sub.offer(&DomainEvent{ID: 4})
+	sub.offer(&DomainEvent{ID: 6})
+
+	for _, want := range []int64{3, 4, 5, 6} {
+		if event := receive(t, sub); event.ID != want {
+			t.Fatalf("event = %d, want %d", event.ID, want)
+		}
+	}
+	if sub.LastEventID() != 6 {
+		t.Errorf("LastEventID = %d", sub.LastEventID())
+	}
+
+	sub.Close()
+	if _, ok := <-sub.Events(); ok {
+		t.Fatal("events channel not closed")
+	}
+	if sub.Err() != nil || hub.count != 0 || len(hub.subscribers) != 0 {
+		t.Errorf("after close: err = %v, count = %d, subscribers = %d", sub.Err(), hub.count, len(hub.subscribers))
+	}
+}
+
+func TestSubscriptionLagged(t *testing.T) {
+	_, sub := newTestSubscription(1)
+	sub.offer(&DomainEvent{ID: 1})
+	sub.offer(&DomainEvent{ID: 2})
+
+	if !sub.isClosed() || gerror.Code(sub.Err()) != CodeSubscriptionLagged {
+		t.Fatalf("closed = %v, err = %v", sub.isClosed(), sub.Err())
+	}
+	sub.offer(&DomainEvent{ID: 3})
+	if len(sub.queue) != 0 {
+		t.Errorf("closed subscription queued %d events", len(sub.queue))
+	}
+}
@@ -0,0 +1,144 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcfg"
+	"github.com/gogf/gf/v2/util/gconv"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteTestDriver 测试用的 gdb SQLite 驱动（基于 mattn/go-sqlite3），在没有 MySQL 的环境下按 migrations/sqlite 建表并端到端执行资金操作
+type sqliteTestDriver struct {
+	*gdb.Core
+}
+
+// New 创建驱动实例
+func (d *sqliteTestDriver) New(core *gdb.Core, node *gdb.ConfigNode) (gdb.DB, error) {
+	return &sqliteTestDriver{Core: core}, nil
+}
+
+// Open 打开数据库连接
+func (d *sqliteTestDriver) Open(config *gdb.ConfigNode) (*sql.DB, error) {
+	return sql.Open("sqlite3", config.Name)
+}
+
+// GetChars 标识符引号
+func (d *sqliteTestDriver) GetChars() (string, string) {
+	return "`", "`"
+}
+
+// DoFilter 将 INSERT IGNORE 改写为 SQLite 的 INSERT OR IGNORE
+func (d *sqliteTestDriver) DoFilter(ctx context.Context, link gdb.Link, sql string, args []interface{}) (string, []interface{}, error) {
+	if strings.HasPrefix(sql, gdb.InsertOperationIgnore) {
+		sql = "INSERT OR IGNORE" + sql[len(gdb.InsertOperationIgnore):]
+	}
+	return d.Core.DoFilter(ctx, link, sql, args)
+}
+
+// DoInsert 去掉值为零的主键列：实体结构体插入时会带上零值主键，MySQL 视为自增，SQLite 会原样写入 0
+func (d *sqliteTestDriver) DoInsert(ctx context.Context, link gdb.Link, table string, list gdb.List, option gdb.DoInsertOption) (sql.Result, error) {
+	fields, err := d.TableFields(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range list {
+		for name, value := range item {
+			if field, ok := fields[name]; ok && field.Key == "pri" && gconv.Int64(value) == 0 {
+				delete(item, name)
+			}
+		}
+	}
+	return d.Core.DoInsert(ctx, link, table, list, option)
+}
+
+// Tables 列出全部表
+func (d *sqliteTestDriver) Tables(ctx context.Context, schema ...string) ([]string, error) {
+	result, err := d.GetAll(ctx, `SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	tables := make([]string, 0, len(result))
+	for _, record := range result {
+		tables = append(tables, record["name"].String())
+	}
+	return tables, nil
+}
+
+// TableFields 读取表字段
+func (d *sqliteTestDriver) TableFields(ctx context.Context, table string, schema ...string) (map[string]*gdb.TableField, error) {
+	result, err := d.GetAll(ctx, fmt.Sprintf("PRAGMA table_info(%s)", d.QuoteWord(table)))
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]*gdb.TableField, len(result))
+	for i, record := range result {
+		field := &gdb.TableField{
+			Index:   i,
+			Name:    record["name"].String(),
+			Type:    strings.ToLower(record["type"].String()),
+			Null:    !record["notnull"].Bool(),
+			Default: record["dflt_value"].Val(),
+		}
+		if record["pk"].Int() > 0 {
+			field.Key = "pri"
+		}
+		fields[field.Name] = field
+	}
+	return fields, nil
+}
+
+var (
+	testDBOnce sync.Once
+	testDBErr  error
+)
+
+// testConfig 测试使用的配置，未列出的配置项使用默认值
+const testConfig = `
+wallet:
+  schema:
+    check: off
+`
+
+// requireTestDB 为整个测试进程创建一个 SQLite 数据库并执行全部迁移；g.DB() 是单例，各测试使用互不重叠的用户和代币
+func requireTestDB(t *testing.T) context.Context {
+	t.Helper()
+	ctx := context.Background()
+	testDBOnce.Do(func() {
+		if adapter, ok := g.Cfg().GetAdapter().(*gcfg.AdapterFile); ok {
+			adapter.SetContent(testConfig)
+		}
+		dir, err := os.MkdirTemp("", "wallet-test-")
+		if err != nil {
+			testDBErr = err
+			return
+		}
+		if err := gdb.Register("sqlite", &sqliteTestDriver{}); err != nil {
+			testDBErr = err
+			return
+		}
+		testDBErr = gdb.SetConfig(gdb.Config{
+			gdb.DefaultGroupName: gdb.ConfigGroup{{
+				Type: "sqlite",
+				Name: "file:" + filepath.Join(dir, "wallet.db") + "?_busy_timeout=5000",
+			}},
+		})
+		if testDBErr != nil {
+			return
+		}
+		_, testDBErr = Migrate(ctx)
+	})
+	if testDBErr != nil {
+		t.Fatalf("prepare test database: %v", testDBErr)
+	}
+	return ctx
+}
@@ -0,0 +1,275 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/constants"
+	"github.com/yalks/wallet/entity"
+	"github.com/yalks/wallet/logic"
+)
+
+// defaultTransactionPriority 未指定优先级时使用的默认值（与 TransactionBuilderEnhanced 一致）
+const defaultTransactionPriority = 5
+
+// CreateTransactionEnhanced 使用增强版请求创建交易：请求元数据包含 scheduled_at 时进入定时队列
+// （按优先级调度执行），否则立即执行
+func (m *walletManager) CreateTransactionEnhanced(ctx context.Context, req *constants.TransactionRequestEnhanced) (*EnhancedTransactionResult, error) {
+	if req == nil {
+		return nil, gerror.New("交易请求不能为空")
+	}
+	if err := m.adjustments.authorize(ctx, req.FundType); err != nil {
+		return nil, err
+	}
+
+	if _, scheduled := req.Metadata["scheduled_at"]; !scheduled {
+		return m.createTransactionEnhancedNow(ctx, req)
+	}
+
+	if err := checkRequestExpiry(req.ExpireAt, time.Now()); err != nil {
+		return nil, err
+	}
+	op, err := m.ScheduleTransaction(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &EnhancedTransactionResult{
+		Queued:               true,
+		ScheduledOperationID: op.ID,
+		Priority:             op.Priority,
+		Tags:                 op.Tags,
+	}, nil
+}
+
+// createTransactionEnhancedNow 立即执行增强版请求：资金变动走 ProcessFundOperationInTx 的资金操作流程
+// （钩子、限额、风控、佣金与领域事件），幂等键独立于引用号保存，过期请求被拒绝，
+// 标签写入可查询的 transaction_tags 表，回调与交易在同一事务写入 Webhook 出站表
+func (m *walletManager) createTransactionEnhancedNow(ctx context.Context, req *constants.TransactionRequestEnhanced) (*EnhancedTransactionResult, error) {
+	if req.IdempotencyKey == "" {
+		return nil, gerror.New("幂等键不能为空")
+	}
+	if req.Reference == "" {
+		return nil, gerror.New("交易引用号不能为空")
+	}
+
+	tags, err := logic.NewTransactionValidator().NormalizeTags(req.Tags)
+	if err != nil {
+		return nil, gerror.Wrap(err, "交易标签验证失败")
+	}
+	priority, err := normalizeTransactionPriority(req.Priority)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := decimal.NewFromString(req.Amount)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "无效的金额格式: %s", req.Amount)
+	}
+	token, err := m.tokenLogic.GetTokenByID(ctx, uint(req.TokenID))
+	if err != nil {
+		return nil, gerror.Wrapf(err, "获取代币信息失败: TokenID=%d", req.TokenID)
+	}
+	fundReq := enhancedFundOperationRequest(req, token.Symbol, amount)
+
+	transactionDAO := logic.GetSharedContext().GetTransactionDAO()
+	tagDAO := logic.GetSharedContext().GetTransactionTagDAO()
+	result := &EnhancedTransactionResult{Priority: priority, Tags: tags}
+	err = m.RunInTransaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		// 1. 幂等检查：同一幂等键重复提交时返回首次结果（即使请求已过期）
+		existing, err := transactionDAO.GetTransactionByIdempotencyKey(ctx, req.IdempotencyKey)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			if err := matchIdempotentRequest(existing, req); err != nil {
+				return err
+			}
+			result.TransactionID = int64(existing.TransactionId)
+			result.Priority = existing.Priority
+			result.Duplicate = true
+			tagMap, err := tagDAO.ListTagsByTransactionIDs(ctx, []uint64{existing.TransactionId})
+			if err != nil {
+				return err
+			}
+			result.Tags = tagMap[existing.TransactionId]
+			g.Log().Infof(ctx, "幂等性检查: 交易已存在 IdempotencyKey=%s, TransactionID=%d", req.IdempotencyKey, existing.TransactionId)
+			return nil
+		}
+
+		// 2. 过期检查
+		if err := checkRequestExpiry(req.ExpireAt, time.Now()); err != nil {
+			return err
+		}
+
+		// 3. 通过资金操作流程记账；引用号即业务ID，被其他幂等键的请求占用时资金操作会以幂等重放返回
+		opResult, err := m.processFundOperationInTxInternal(ctx, tx, fundReq)
+		if err != nil {
+			return err
+		}
+		if opResult.Replayed {
+			return gerror.NewCodef(logic.CodeIdempotencyConflict, "交易引用号已被其他请求使用: Reference=%s, TransactionID=%s", req.Reference, opResult.TransactionID)
+		}
+		transactionID, err := strconv.ParseUint(opResult.TransactionID, 10, 64)
+		if err != nil {
+			return gerror.Wrapf(err, "无效的交易ID: %s", opResult.TransactionID)
+		}
+		result.TransactionID = int64(transactionID)
+
+		// 4. 保存增强版请求属性、标签并写入回调
+		var expireAt *gtime.Time
+		if req.ExpireAt != nil {
+			expireAt = gtime.New(*req.ExpireAt)
+		}
+		if err := transactionDAO.UpdateRequestOptions(ctx, tx, transactionID, req.IdempotencyKey, priority, expireAt); err != nil {
+			return err
+		}
+		if len(tags) > 0 {
+			if err := tagDAO.CreateTags(ctx, tx, transactionID, uint64(req.UserID), tags); err != nil {
+				return err
+			}
+		}
+
+		return enqueueWebhookInTx(ctx, tx, req.Callback, &WebhookEvent{
+			Event:         WebhookEventTransactionCompleted,
+			BusinessID:    req.Reference,
+			TransactionID: opResult.TransactionID,
+			UserID:        uint64(req.UserID),
+			TokenSymbol:   token.Symbol,
+			Amount:        amount.String(),
+			FundType:      string(req.FundType),
+			Status:        string(constants.TransactionStatusCompleted),
+			Reference:     req.Reference,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// enhancedFundOperationRequest 将增强版请求转换为资金操作请求，引用号作为业务ID
+func enhancedFundOperationRequest(req *constants.TransactionRequestEnhanced, tokenSymbol string, amount decimal.Decimal) *FundOperationRequest {
+	metadata := make(map[string]string, len(req.Metadata))
+	for k, v := range req.Metadata {
+		metadata[k] = fmt.Sprintf("%v", v)
+	}
+	return &FundOperationRequest{
+		UserID:      uint64(req.UserID),
+		TokenSymbol: tokenSymbol,
+		Amount:      amount,
+		BusinessID:  req.Reference,
+		FundType:    req.FundType,
+		Direction:   req.Direction,
+		Description: req.Description,
+		Metadata:    metadata,
+		RelatedID:   req.RelatedID,
+	}
+}
+
+// GetTransactionsByTag 按标签查询交易（userID 为 0 时不限用户），按交易ID倒序
+func (tm *transactionManager) GetTransactionsByTag(ctx context.Context, userID int64, tag string, limit, offset int) ([]*TransactionRecord, error) {
+	if tag == "" {
+		return nil, gerror.New("标签不能为空")
+	}
+
+	ids, err := tm.logic.GetTransactionTagDAO().ListTransactionIDsByTag(ctx, uint64(userID), tag, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []*TransactionRecord{}, nil
+	}
+
+	var transactions []*entity.Transactions
+	err = g.Model("transactions").Ctx(ctx).
+		WhereIn("transaction_id", ids).
+		OrderDesc("transaction_id").
+		Scan(&transactions)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "按标签查询交易失败: Tag=%s", tag)
+	}
+
+	return tm.convertToTransactionRecords(ctx, transactions)
+}
+
+// convertToTransactionRecords 批量转换交易记录并填充标签
+func (tm *transactionManager) convertToTransactionRecords(ctx context.Context, transactions []*entity.Transactions) ([]*TransactionRecord, error) {
+	ids := make([]uint64, 0, len(transactions))
+	for _, tx := range transactions {
+		ids = append(ids, tx.TransactionId)
+	}
+	tagMap, err := tm.logic.GetTransactionTagDAO().ListTagsByTransactionIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*TransactionRecord, 0, len(transactions))
+	for _, tx := range transactions {
+		record := tm.convertToTransactionRecord(tx)
+		record.Tags = tagMap[tx.TransactionId]
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// checkRequestExpiry 检查请求是否已过期
+func checkRequestExpiry(expireAt *time.Time, now time.Time) error {
+	if expireAt != nil && !now.Before(*expireAt) {
+		return gerror.NewCodef(logic.CodeRequestExpired, "交易请求已过期: ExpireAt=%s", expireAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// normalizeTransactionPriority 校验优先级（1-10），未设置时使用默认值
+func normalizeTransactionPriority(priority int) (int, error) {
+	if priority == 0 {
+		return defaultTransactionPriority, nil
+	}
+	if priority < 1 || priority > 10 {
+		return 0, gerror.Newf("优先级必须在1到10之间: %d", priority)
+	}
+	return priority, nil
+}
+
+// matchIdempotentRequest 校验重复提交的请求与首次请求一致，防止幂等键被不同请求复用
+func matchIdempotentRequest(existing *entity.Transactions, req *constants.TransactionRequestEnhanced) error {
+	amount, err := decimal.NewFromString(req.Amount)
+	if err != nil {
+		return gerror.Wrapf(err, "无效的金额格式: %s", req.Amount)
+	}
+	if int64(existing.UserId) != req.UserID ||
+		int64(existing.TokenId) != req.TokenID ||
+		existing.Type != string(req.FundType) ||
+		!existing.Amount.Equal(amount) {
+		return gerror.NewCodef(logic.CodeIdempotencyConflict, "幂等键已被不同的请求使用: IdempotencyKey=%s, TransactionID=%d",
+			req.IdempotencyKey, existing.TransactionId)
+	}
+	return nil
+}
+
+// encodeTags 序列化标签用于保存
+func encodeTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(tags)
+	return string(data)
+}
+
+// decodeTags 解析保存的标签
+func decodeTags(raw string) []string {
+	var tags []string
+	if raw != "" {
+		_ = json.Unmarshal([]byte(raw), &tags)
+	}
+	return tags
+}
@@ -0,0 +1,112 @@
+package wallet
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+
+	"github.com/yalks/wallet/constants"
+	"github.com/yalks/wallet/logic"
+)
+
+// newTestManager 在测试数据库上初始化钱包管理器，并写入一个用户和一个代币
+func newTestManager(t *testing.T, userID uint64, symbol string) (context.Context, *walletManager, int64) {
+	t.Helper()
+	ctx := requireTestDB(t)
+
+	m := &walletManager{}
+	if err := m.initialize(ctx); err != nil {
+		t.Fatalf("initialize manager: %v", err)
+	}
+	if _, err := g.Model("users").Ctx(ctx).Data(g.Map{"id": userID, "account": symbol + "_user"}).Insert(); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	tokenID, err := g.Model("tokens").Ctx(ctx).Data(g.Map{"symbol": symbol, "name": symbol, "decimals": 6, "is_active": 1, "status": 1}).InsertAndGetId()
+	if err != nil {
+		t.Fatalf("insert token: %v", err)
+	}
+	return ctx, m, tokenID
+}
+
+func TestCreateTransactionEnhanced_Immediate(t *testing.T) {
+	ctx, m, tokenID := newTestManager(t, 9301, "ENH")
+
+	var hooked []string
+	if _, err := m.RegisterHook(&HookRegistration{Name: "observe", Stage: HookStageBeforeValidate, Hook: func(ctx context.Context, hc *HookContext) error {
+		hooked = append(hooked, hc.Request.BusinessID)
+		return nil
+	}}); err != nil {
+		t.Fatalf("register hook: %v", err)
+	}
+
+	build := func(key string) *constants.TransactionRequestEnhanced {
+		req, err := constants.NewTransactionBuilderEnhanced().
+			WithUser(9301).
+			WithWallet(1).
+			WithAmount("100").
+			WithToken(tokenID).
+			WithFundType(constants.FundTypeDeposit).
+			WithReference("enhanced_deposit_001").
+			WithIdempotencyKey(key).
+			WithExpireAt(time.Now().Add(time.Minute)).
+			WithTags("promo").
+			WithPriority(8).
+			Build()
+		if err != nil {
+			t.Fatalf("build request: %v", err)
+		}
+		return req
+	}
+
+	result, err := m.CreateTransactionEnhanced(ctx, build("client-key-1"))
+	if err != nil {
+		t.Fatalf("CreateTransactionEnhanced() error = %v", err)
+	}
+	if result.TransactionID == 0 || result.Duplicate || result.Queued || result.Priority != 8 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if len(hooked) != 1 || hooked[0] != "enhanced_deposit_001" {
+		t.Errorf("fund operation hooks should run with the reference as business ID, got %v", hooked)
+	}
+
+	balance, err := m.GetBalance(ctx, 9301, "ENH")
+	if err != nil {
+		t.Fatalf("GetBalance() error = %v", err)
+	}
+	if balance.AvailableBalance.String() != "100" {
+		t.Errorf("expected balance 100, got %s", balance.AvailableBalance)
+	}
+
+	record, err := m.transactionManager.GetTransactionByID(ctx, result.TransactionID)
+	if err != nil {
+		t.Fatalf("GetTransactionByID() error = %v", err)
+	}
+	if len(record.Tags) != 1 || record.Tags[0] != "promo" {
+		t.Errorf("expected tag promo, got %v", record.Tags)
+	}
+
+	// 相同幂等键重复提交返回首次结果，不再记账
+	again, err := m.CreateTransactionEnhanced(ctx, build("client-key-1"))
+	if err != nil {
+		t.Fatalf("replay error = %v", err)
+	}
+	if !again.Duplicate || again.TransactionID != result.TransactionID || again.Priority != 8 {
+		t.Errorf("unexpected replay result: %+v", again)
+	}
+
+	// 引用号被其他幂等键占用
+	if _, err := m.CreateTransactionEnhanced(ctx, build("client-key-2")); gerror.Code(err) != logic.CodeIdempotencyConflict {
+		t.Errorf("expected idempotency conflict, got %v", err)
+	}
+
+	balance, err = m.GetBalance(ctx, 9301, "ENH")
+	if err != nil {
+		t.Fatalf("GetBalance() error = %v", err)
+	}
+	if balance.AvailableBalance.String() != "100" {
+		t.Errorf("balance changed by a duplicate request: %s", balance.AvailableBalance)
+	}
+}
@@ -2,17 +2,21 @@ package wallet
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 
 	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gcode"
 	"github.com/gogf/gf/v2/errors/gerror"
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/os/gtime"
 	"github.com/shopspring/decimal"
 
 	"github.com/yalks/wallet/constants"
+	"github.com/yalks/wallet/dao"
 	"github.com/yalks/wallet/entity"
 	"github.com/yalks/wallet/logic"
 )
@@ -25,6 +29,13 @@ type transactionManager struct {
 	userLogic    logic.IUserLogic
 	balanceLogic logic.IBalanceLogic
 	validator    *logic.TransactionValidator
+
+	withdrawAddressLogic logic.IWithdrawAddressLogic
+	eventLogic           logic.IDomainEventLogic
+	auditLogic           logic.IAuditLogic
+	commissionLogic      logic.ICommissionLogic
+	velocityLogic        logic.IVelocityLogic
+	riskLogic            logic.IRiskLogic
 }
 
 // NewTransactionManager 创建事务管理器
@@ -35,6 +46,13 @@ func NewTransactionManager() ITransactionManager {
 		userLogic:    logic.NewUserLogic(),
 		balanceLogic: logic.NewBalanceLogic(),
 		validator:    logic.NewTransactionValidator(),
+
+		withdrawAddressLogic: logic.NewWithdrawAddressLogic(),
+		eventLogic:           logic.NewDomainEventLogic(),
+		auditLogic:           logic.NewAuditLogic(),
+		commissionLogic:      logic.NewCommissionLogic(),
+		velocityLogic:        logic.NewVelocityLogic(),
+		riskLogic:            logic.NewRiskLogic(),
 	}
 }
 
@@ -62,116 +80,231 @@ func (tm *transactionManager) CreateTransaction(ctx context.Context, req *consta
 			return nil
 		}
 
-		// 获取用户和代币信息
-		user, err := tm.userLogic.GetUserByID(ctx, uint64(req.UserID))
+		transaction, err := tm.createTransactionInTx(ctx, tx, req)
 		if err != nil {
-			return gerror.Wrapf(err, "获取用户信息失败: UserID=%d", req.UserID)
+			return err
 		}
+		transactionID = int64(transaction.TransactionId)
+		return nil
+	})
 
-		token, err := tm.tokenLogic.GetTokenByID(ctx, uint(req.TokenID))
-		if err != nil {
-			return gerror.Wrapf(err, "获取代币信息失败: TokenID=%d", req.TokenID)
+	if err != nil {
+		// 并发请求使用相同的 Reference 时，唯一约束冲突的一方返回已提交的交易
+		if dao.IsDuplicateKeyError(err) {
+			existingTx, findErr := tm.getTransactionByReference(ctx, nil, req.Reference)
+			if findErr == nil && existingTx != nil {
+				g.Log().Infof(ctx, "交易已由并发请求创建，返回现有交易: Reference=%s, TransactionID=%d", req.Reference, existingTx.TransactionId)
+				return int64(existingTx.TransactionId), nil
+			}
 		}
+		return 0, err
+	}
 
-		// 获取当前余额
-		currentBalance, _, err := tm.balanceLogic.GetBalance(ctx, uint64(req.UserID), token.Symbol)
-		if err != nil {
-			return gerror.Wrap(err, "获取当前余额失败")
-		}
+	return transactionID, nil
+}
 
-		// 转换金额
-		amount, err := decimal.NewFromString(req.Amount)
-		if err != nil {
-			return gerror.Wrapf(err, "无效的金额格式: %s", req.Amount)
-		}
+// createTransactionInTx 在事务中创建交易记录、更新余额并写入领域事件，返回已写入的交易记录
+func (tm *transactionManager) createTransactionInTx(ctx context.Context, tx gdb.TX, req *constants.TransactionRequest) (*entity.Transactions, error) {
+	// 获取用户和代币信息
+	user, err := tm.userLogic.GetUserByID(ctx, uint64(req.UserID))
+	if err != nil {
+		return nil, gerror.Wrapf(err, "获取用户信息失败: UserID=%d", req.UserID)
+	}
 
-		// 计算新余额
-		var newBalance decimal.Decimal
-		direction := constants.GetFundDirection(req.FundType)
-		switch direction {
-		case constants.FundDirectionIn:
-			newBalance = currentBalance.Add(amount)
-		case constants.FundDirectionOut:
-			newBalance = currentBalance.Sub(amount)
-			// 检查余额是否充足
-			if newBalance.LessThan(decimal.Zero) {
-				return gerror.Newf("余额不足: 当前余额=%s, 需要金额=%s", currentBalance.String(), amount.String())
-			}
-		default:
-			return gerror.Newf("未知的资金方向: %s", direction)
-		}
+	token, err := tm.tokenLogic.GetTokenByID(ctx, uint(req.TokenID))
+	if err != nil {
+		return nil, gerror.Wrapf(err, "获取代币信息失败: TokenID=%d", req.TokenID)
+	}
 
-		// 创建交易记录
-		transaction := &entity.Transactions{
-			UserId:            uint(req.UserID),
-			TokenId:           uint(req.TokenID),
-			Amount:            amount,
-			BalanceBefore:     currentBalance,
-			BalanceAfter:      newBalance,
-			Type:              string(req.FundType),
-			Status:            1, // 1表示成功
-			Direction:         string(direction),
-			Memo:              req.Description,
-			Symbol:            token.Symbol,
-			WalletType:        "available",
-			BusinessId:        req.Reference,
-			RelatedEntityId:   uint64(req.RelatedID),
-			RelatedEntityType: "fund_operation",
-			CreatedAt:         gtime.Now(),
-			UpdatedAt:         gtime.Now(),
-
-			// New fields - User request information
-			RequestAmount:    amount, // Using the same amount as the transaction amount
-			RequestReference: req.Reference,
-			RequestMetadata:  tm.convertMetadataToJSON(req.Metadata),
-			RequestSource:    tm.validator.SanitizeRequestSource(req.RequestSource),
-			RequestIp:        req.RequestIP,
-			RequestUserAgent: req.RequestUserAgent,
-			RequestTimestamp: gtime.Now(),
-			ProcessedAt:      gtime.Now(),
-
-			// Use fee from request parameters
-			FeeAmount: tm.parseFeeAmount(req.FeeAmount),
-			FeeType:   req.FeeType,
-
-			// Exchange rate (set to 1 if no conversion)
-			ExchangeRate: decimal.NewFromInt(1),
-
-			// Target user fields for transfers
-			TargetUserId:   uint(req.TargetUserID),
-			TargetUsername: req.TargetUsername,
+	// 提现需要校验目标地址是否满足地址簿规则
+	if req.FundType == constants.FundTypeWithdraw {
+		address := ""
+		if v, ok := req.Metadata[constants.MetadataKeyAddress]; ok && v != nil {
+			address = fmt.Sprintf("%v", v)
 		}
-
-		// 插入交易记录
-		id, err := tm.logic.GetTransactionDAO().CreateTransaction(ctx, tx, transaction)
-		if err != nil {
-			return gerror.Wrap(err, "创建交易记录失败")
+		if address == "" {
+			return nil, gerror.New("提现操作必须在元数据中提供目标地址 (address)")
+		}
+		if err := tm.withdrawAddressLogic.ValidateWithdrawDestination(ctx, uint64(req.UserID), token, address); err != nil {
+			return nil, gerror.Wrap(err, "提现地址校验失败")
 		}
-		transactionID = id
+	}
 
-		// 更新本地余额
-		err = tm.balanceLogic.UpdateLocalBalance(ctx, tx, uint64(req.UserID), token.Symbol, newBalance, decimal.Zero)
+	// 锁定钱包行并获取当前余额：锁定读返回最新已提交的余额，钱包不存在时创建
+	currentBalance, walletExists, err := tm.balanceLogic.LockBalanceInTx(ctx, tx, uint64(req.UserID), token.Symbol)
+	if err != nil {
+		return nil, gerror.Wrap(err, "锁定钱包失败")
+	}
+	if !walletExists {
+		currentBalance, _, err = tm.balanceLogic.GetBalance(ctx, uint64(req.UserID), token.Symbol)
 		if err != nil {
-			return gerror.Wrap(err, "更新本地余额失败")
+			return nil, gerror.Wrap(err, "获取当前余额失败")
 		}
+	}
 
-		// 执行远程钱包操作
-		err = tm.executeRemoteOperation(ctx, user, token, amount, req.FundType, req.Metadata, req.FeeAmount, req.FeeType)
-		if err != nil {
-			return gerror.Wrap(err, "执行远程钱包操作失败")
+	// 转换金额
+	amount, err := decimal.NewFromString(req.Amount)
+	if err != nil {
+		return nil, gerror.Wrapf(err, "无效的金额格式: %s", req.Amount)
+	}
+
+	// 计算新余额
+	var newBalance decimal.Decimal
+	direction, err := constants.ResolveFundDirection(req.FundType, req.Direction)
+	if err != nil {
+		return nil, gerror.Wrap(err, "资金方向无效")
+	}
+	switch direction {
+	case constants.FundDirectionIn:
+		newBalance = currentBalance.Add(amount)
+	case constants.FundDirectionOut:
+		newBalance = currentBalance.Sub(amount)
+		// 检查余额是否充足
+		if newBalance.LessThan(decimal.Zero) {
+			return nil, gerror.NewCodef(logic.CodeInsufficientBalance, "余额不足: 当前余额=%s, 需要金额=%s", currentBalance.String(), amount.String())
 		}
+	default:
+		return nil, gerror.Newf("未知的资金方向: %s", direction)
+	}
+
+	// 风控评估
+	requestSource := tm.validator.SanitizeRequestSource(req.RequestSource)
+	assessment, err := tm.riskLogic.Evaluate(ctx, &logic.RiskOperation{
+		UserID:        uint64(req.UserID),
+		TokenSymbol:   token.Symbol,
+		FundType:      req.FundType,
+		Direction:     direction,
+		Amount:        amount,
+		RequestSource: requestSource,
+		IP:            req.RequestIP,
+		UserAgent:     req.RequestUserAgent,
+		Metadata:      logic.ExtractRequestContext(ctx).Metadata,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		g.Log().Infof(ctx, "交易创建成功: TransactionID=%d, UserID=%d, Amount=%s %s, FundType=%s",
-			transactionID, req.UserID, amount.String(), token.Symbol, req.FundType)
+	// 计入并校验交易限额
+	err = tm.velocityLogic.ApplyInTx(ctx, tx, &logic.VelocityOperation{
+		UserID:        uint64(req.UserID),
+		TokenSymbol:   token.Symbol,
+		FundType:      req.FundType,
+		RequestSource: requestSource,
+		Amount:        amount,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		return nil
+	// 创建交易记录
+	transaction := &entity.Transactions{
+		UserId:            uint(req.UserID),
+		TokenId:           uint(req.TokenID),
+		Amount:            amount,
+		BalanceBefore:     currentBalance,
+		BalanceAfter:      newBalance,
+		Type:              string(req.FundType),
+		Status:            1, // 1表示成功
+		Direction:         string(direction),
+		Memo:              req.Description,
+		Symbol:            token.Symbol,
+		WalletType:        "available",
+		BusinessId:        req.Reference,
+		RelatedEntityId:   uint64(req.RelatedID),
+		RelatedEntityType: "fund_operation",
+		CreatedAt:         gtime.Now(),
+		UpdatedAt:         gtime.Now(),
+
+		// New fields - User request information
+		RequestAmount:    amount, // Using the same amount as the transaction amount
+		RequestReference: req.Reference,
+		RequestMetadata:  tm.convertMetadataToJSON(req.Metadata),
+		RequestSource:    requestSource,
+		RequestIp:        req.RequestIP,
+		RequestUserAgent: req.RequestUserAgent,
+		RequestTimestamp: gtime.Now(),
+		ProcessedAt:      gtime.Now(),
+
+		// Use fee from request parameters
+		FeeAmount: tm.parseFeeAmount(req.FeeAmount),
+		FeeType:   req.FeeType,
+
+		// Exchange rate (set to 1 if no conversion)
+		ExchangeRate: decimal.NewFromInt(1),
+
+		// Target user fields for transfers
+		TargetUserId:   uint(req.TargetUserID),
+		TargetUsername: req.TargetUsername,
+	}
+	if assessment != nil {
+		transaction.RiskScore = assessment.Score
+		transaction.RiskDecision = string(assessment.Decision)
+		transaction.RiskReasons = assessment.ReasonsString()
+	}
+
+	// 插入交易记录
+	id, err := tm.logic.GetTransactionDAO().CreateTransaction(ctx, tx, transaction)
+	if err != nil {
+		return nil, gerror.Wrap(err, "创建交易记录失败")
+	}
+	transactionID := id
+	transaction.TransactionId = uint64(id)
+	if err := tm.auditLogic.RecordTransactionInTx(ctx, tx, transaction.TransactionId); err != nil {
+		return nil, gerror.Wrap(err, "写入审计记录失败")
+	}
+
+	// 更新本地余额
+	err = tm.balanceLogic.UpdateLocalBalance(ctx, tx, uint64(req.UserID), token.Symbol, newBalance, decimal.Zero)
+	if err != nil {
+		return nil, gerror.Wrap(err, "更新本地余额失败")
+	}
+
+	// 执行远程钱包操作
+	err = tm.executeRemoteOperation(ctx, user, token, amount, req.FundType, direction, req.Metadata, req.FeeAmount, req.FeeType)
+	if err != nil {
+		return nil, gerror.Wrap(err, "执行远程钱包操作失败")
+	}
+
+	// 在同一事务中写入领域事件
+	eventType := logic.EventFundsCredited
+	if direction == constants.FundDirectionOut {
+		eventType = logic.EventFundsDebited
+	}
+	err = tm.eventLogic.RecordInTx(ctx, tx, &logic.RecordEventRequest{
+		Type:        eventType,
+		UserID:      uint64(req.UserID),
+		TokenSymbol: token.Symbol,
+		BusinessID:  req.Reference,
+		Payload: &logic.FundsChangedPayload{
+			TransactionID: transactionID,
+			FundType:      string(req.FundType),
+			Amount:        amount,
+			BalanceBefore: currentBalance,
+			BalanceAfter:  newBalance,
+			Description:   req.Description,
+		},
 	})
+	if err != nil {
+		return nil, gerror.Wrap(err, "写入领域事件失败")
+	}
 
+	// 在同一事务中沿关系链分配佣金
+	_, err = tm.commissionLogic.DistributeInTx(ctx, tx, &logic.CommissionSource{
+		TransactionID: transaction.TransactionId,
+		UserID:        uint64(req.UserID),
+		TokenSymbol:   token.Symbol,
+		FundType:      req.FundType,
+		Amount:        amount,
+		RequestSource: req.RequestSource,
+	})
 	if err != nil {
-		return 0, err
+		return nil, gerror.Wrap(err, "分配佣金失败")
 	}
 
-	return transactionID, nil
+	g.Log().Infof(ctx, "交易创建成功: TransactionID=%d, UserID=%d, Amount=%s %s, FundType=%s",
+		transactionID, req.UserID, amount.String(), token.Symbol, req.FundType)
+
+	return transaction, nil
 }
 
 // GetTransactionByID 根据ID获取交易记录
@@ -181,14 +314,18 @@ func (tm *transactionManager) GetTransactionByID(ctx context.Context, transactio
 	err := g.Model("transactions").Ctx(ctx).
 		Where("transaction_id = ?", transactionID).
 		Scan(&tx)
-	if err != nil {
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return nil, gerror.Wrapf(err, "获取交易记录失败: TransactionID=%d", transactionID)
 	}
 	if tx.TransactionId == 0 {
-		return nil, gerror.Newf("交易记录不存在: TransactionID=%d", transactionID)
+		return nil, gerror.NewCodef(gcode.CodeNotFound, "交易记录不存在: TransactionID=%d", transactionID)
 	}
 
-	return tm.convertToTransactionRecord(&tx), nil
+	records, err := tm.convertToTransactionRecords(ctx, []*entity.Transactions{&tx})
+	if err != nil {
+		return nil, err
+	}
+	return records[0], nil
 }
 
 // GetTransactionByReference 根据引用获取交易记录
@@ -201,7 +338,11 @@ func (tm *transactionManager) GetTransactionByReference(ctx context.Context, ref
 		return nil, nil
 	}
 
-	return tm.convertToTransactionRecord(tx), nil
+	records, err := tm.convertToTransactionRecords(ctx, []*entity.Transactions{tx})
+	if err != nil {
+		return nil, err
+	}
+	return records[0], nil
 }
 
 // UpdateTransactionStatus 更新交易状态
@@ -211,7 +352,7 @@ func (tm *transactionManager) UpdateTransactionStatus(ctx context.Context, trans
 		return gerror.Newf("无效的交易状态: %s", status)
 	}
 
-	// 获取现有交易
+	// 获取交易所属的钱包
 	var existingTx entity.Transactions
 	err := g.Model("transactions").Ctx(ctx).
 		Where("transaction_id = ?", transactionID).
@@ -220,23 +361,55 @@ func (tm *transactionManager) UpdateTransactionStatus(ctx context.Context, trans
 		return gerror.Wrap(err, "获取交易记录失败")
 	}
 	if existingTx.TransactionId == 0 {
-		return gerror.Newf("交易记录不存在: TransactionID=%d", transactionID)
+		return gerror.NewCodef(gcode.CodeNotFound, "交易记录不存在: TransactionID=%d", transactionID)
 	}
 
-	// 检查当前状态是否为最终状态
-	currentStatus := constants.TransactionStatus(fmt.Sprintf("%d", existingTx.Status))
-	if constants.IsFinalStatus(currentStatus) {
-		return gerror.Newf("交易已处于最终状态，无法更新: CurrentStatus=%s", currentStatus)
-	}
+	// 更新状态，并在同一事务中写入状态变更事件
+	var currentStatus constants.TransactionStatus
+	err = g.DB().Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		// 先锁定钱包行，与同一钱包的资金操作串行写入审计链和领域事件，再在锁内重新读取交易状态
+		if _, err := tm.logic.GetWalletDAO().LockWalletInTx(ctx, tx, uint64(existingTx.UserId), existingTx.Symbol); err != nil {
+			return err
+		}
+		err := g.Model("transactions").Ctx(ctx).TX(tx).
+			Where("transaction_id = ?", transactionID).
+			Scan(&existingTx)
+		if err != nil {
+			return gerror.Wrap(err, "获取交易记录失败")
+		}
 
-	// 更新状态
-	_, err = g.Model("transactions").Ctx(ctx).
-		Where("transaction_id = ?", transactionID).
-		Update(g.Map{
-			"status":     statusToInt(status),
-			"updated_at": gtime.Now(),
-		})
+		// 检查当前状态是否为最终状态
+		currentStatus = constants.TransactionStatus(fmt.Sprintf("%d", existingTx.Status))
+		if constants.IsFinalStatus(currentStatus) {
+			return gerror.Newf("交易已处于最终状态，无法更新: CurrentStatus=%s", currentStatus)
+		}
+
+		_, err = g.Model("transactions").Ctx(ctx).TX(tx).
+			Where("transaction_id = ?", transactionID).
+			Update(g.Map{
+				"status":     statusToInt(status),
+				"updated_at": gtime.Now(),
+			})
+		if err != nil {
+			return err
+		}
 
+		if err := tm.auditLogic.RecordStatusChangeInTx(ctx, tx, &existingTx, uint(statusToInt(status))); err != nil {
+			return err
+		}
+
+		return tm.eventLogic.RecordInTx(ctx, tx, &logic.RecordEventRequest{
+			Type:        logic.EventTransactionChanged,
+			UserID:      uint64(existingTx.UserId),
+			TokenSymbol: existingTx.Symbol,
+			BusinessID:  fmt.Sprintf("%d_%s", transactionID, status),
+			Payload: &logic.TransactionStatusChangedPayload{
+				TransactionID: transactionID,
+				OldStatus:     string(currentStatus),
+				NewStatus:     string(status),
+			},
+		})
+	})
 	if err != nil {
 		return gerror.Wrapf(err, "更新交易状态失败: TransactionID=%d, Status=%s", transactionID, status)
 	}
@@ -273,12 +446,7 @@ func (tm *transactionManager) GetUserTransactionHistory(ctx context.Context, use
 	}
 
 	// 转换为交易记录
-	records := make([]*TransactionRecord, 0, len(transactions))
-	for _, tx := range transactions {
-		records = append(records, tm.convertToTransactionRecord(tx))
-	}
-
-	return records, nil
+	return tm.convertToTransactionRecords(ctx, transactions)
 }
 
 // validateTransactionRequest 验证交易请求
@@ -298,6 +466,14 @@ func (tm *transactionManager) validateTransactionRequest(req *constants.Transact
 	if !constants.IsValidFundType(req.FundType) {
 		return gerror.Newf("无效的资金类型: %s", req.FundType)
 	}
+	if _, err := constants.ResolveFundDirection(req.FundType, req.Direction); err != nil {
+		return gerror.Wrap(err, "资金方向无效")
+	}
+	if amount, err := decimal.NewFromString(req.Amount); err == nil {
+		if err := constants.ValidateFundTypeUsage(req.FundType, req.RequestSource, amount); err != nil {
+			return gerror.Wrap(err, "资金类型使用受限")
+		}
+	}
 	if req.Reference == "" {
 		return gerror.New("交易引用不能为空")
 	}
@@ -345,7 +521,7 @@ func (tm *transactionManager) getTransactionByReference(ctx context.Context, tx
 }
 
 // executeRemoteOperation 执行远程钱包操作
-func (tm *transactionManager) executeRemoteOperation(ctx context.Context, user *entity.Users, token *entity.Tokens, amount decimal.Decimal, fundType constants.FundType, metadata map[string]interface{}, feeAmount string, feeType string) error {
+func (tm *transactionManager) executeRemoteOperation(ctx context.Context, user *entity.Users, token *entity.Tokens, amount decimal.Decimal, fundType constants.FundType, direction constants.FundDirection, metadata map[string]interface{}, feeAmount string, feeType string) error {
 	walletSDK := tm.logic.GetWalletSDK()
 	if walletSDK == nil {
 		return gerror.New("钱包SDK未初始化")
@@ -369,9 +545,6 @@ func (tm *transactionManager) executeRemoteOperation(ctx context.Context, user *
 	}
 	sdkMetadata["fund_type"] = string(fundType)
 
-	// 根据资金方向执行操作
-	direction := constants.GetFundDirection(fundType)
-
 	// 直接调用logic层的operation逻辑，避免重复实现SDK调用
 	operationReq := &logic.FinancialOperationRequest{
 		UserID:      uint64(user.Id),
@@ -411,6 +584,7 @@ func (tm *transactionManager) convertToTransactionRecord(tx *entity.Transactions
 		UserID:      int64(tx.UserId),
 		WalletID:    0, // 钱包ID从实体中移除了
 		TokenID:     int64(tx.TokenId),
+		TokenSymbol: tx.Symbol,
 		Amount:      tx.Amount.String(),
 		FundType:    constants.FundType(tx.Type),
 		Direction:   constants.FundDirection(tx.Direction),
@@ -420,6 +594,11 @@ func (tm *transactionManager) convertToTransactionRecord(tx *entity.Transactions
 		RelatedID:   int64(tx.RelatedEntityId),
 		CreatedAt:   tx.CreatedAt.String(),
 		UpdatedAt:   tx.UpdatedAt.String(),
+
+		IdempotencyKey: tx.IdempotencyKey,
+		Priority:       tx.Priority,
+		RiskScore:      tx.RiskScore,
+		RiskDecision:   tx.RiskDecision,
 	}
 
 	// 元数据暂时为空，因为entity中没有Metadata字段
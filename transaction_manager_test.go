@@ -0,0 +1,75 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+
+	"github.com/yalks/wallet/constants"
+	"github.com/yalks/wallet/logic"
+)
+
+func TestUpdateTransactionStatus_SequencesFollowFundOperations(t *testing.T) {
+	ctx, m, tokenID := newTestManager(t, 9302, "UTS")
+
+	req, err := constants.NewTransactionBuilderEnhanced().
+		WithUser(9302).
+		WithWallet(1).
+		WithAmount("50").
+		WithToken(tokenID).
+		WithFundType(constants.FundTypeDeposit).
+		WithReference("status_change_deposit_001").
+		WithIdempotencyKey("status-change-key-1").
+		WithExpireAt(time.Now().Add(time.Minute)).
+		Build()
+	if err != nil {
+		t.Fatalf("build request: %v", err)
+	}
+	result, err := m.CreateTransactionEnhanced(ctx, req)
+	if err != nil {
+		t.Fatalf("CreateTransactionEnhanced() error = %v", err)
+	}
+
+	if err := m.transactionManager.UpdateTransactionStatus(ctx, result.TransactionID, constants.TransactionStatusFailed); err != nil {
+		t.Fatalf("UpdateTransactionStatus() error = %v", err)
+	}
+
+	aggregateID := logic.WalletAggregateID(9302, "UTS")
+	events, err := g.Model("domain_events").Ctx(ctx).Where("aggregate_id = ?", aggregateID).OrderAsc("id").All()
+	if err != nil {
+		t.Fatalf("list domain events: %v", err)
+	}
+	if len(events) < 2 {
+		t.Fatalf("expected the fund operation and status change events, got %d", len(events))
+	}
+	for i, event := range events {
+		if event["sequence"].Uint64() != uint64(i+1) {
+			t.Errorf("event %d: expected sequence %d, got %d", i, i+1, event["sequence"].Uint64())
+		}
+	}
+	if last := events[len(events)-1]; last["event_type"].String() != string(logic.EventTransactionChanged) {
+		t.Errorf("expected the status change event last, got %s", last["event_type"].String())
+	}
+
+	entries, err := g.Model("audit_entries").Ctx(ctx).Where("chain_id = ?", aggregateID).OrderAsc("id").All()
+	if err != nil {
+		t.Fatalf("list audit entries: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected a transaction and a status change audit entry, got %d", len(entries))
+	}
+	for i, entry := range entries {
+		if entry["sequence"].Uint64() != uint64(i+1) {
+			t.Errorf("audit entry %d: expected sequence %d, got %d", i, i+1, entry["sequence"].Uint64())
+		}
+	}
+
+	report, err := m.VerifyAuditChain(ctx, 9302, "UTS")
+	if err != nil {
+		t.Fatalf("VerifyAuditChain() error = %v", err)
+	}
+	if !report.Valid {
+		t.Errorf("audit chain should stay valid after a status change: %+v", report)
+	}
+}
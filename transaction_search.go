@@ -0,0 +1,204 @@
+package wallet
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/shopspring/decimal"
+
+	"github.com/yalks/wallet/constants"
+	"github.com/yalks/wallet/dao"
+	"github.com/yalks/wallet/logic"
+)
+
+const (
+	defaultTransactionPageSize = 20  // 默认每页条数
+	maxTransactionPageSize     = 200 // 最大每页条数
+)
+
+// SearchTransactions 多条件查询交易（键集分页）
+func (m *walletManager) SearchTransactions(ctx context.Context, query *TransactionQuery) (*TransactionPage, error) {
+	return m.transactionManager.SearchTransactions(ctx, query)
+}
+
+// SearchTransactions 多条件查询交易：按 (created_at, transaction_id) 键集分页，
+// 翻页期间有新交易写入也不会重复或遗漏记录
+func (tm *transactionManager) SearchTransactions(ctx context.Context, query *TransactionQuery) (*TransactionPage, error) {
+	filter, empty, err := buildTransactionFilter(query)
+	if err != nil {
+		return nil, err
+	}
+	if empty {
+		return &TransactionPage{Records: []*TransactionRecord{}}, nil
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultTransactionPageSize
+	}
+	if limit > maxTransactionPageSize {
+		limit = maxTransactionPageSize
+	}
+
+	transactionDAO := tm.logic.GetTransactionDAO()
+	total, err := transactionDAO.CountTransactions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// 多取一条用于判断是否还有下一页
+	transactions, err := transactionDAO.SearchTransactions(ctx, filter, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &TransactionPage{Total: total}
+	if len(transactions) > limit {
+		transactions = transactions[:limit]
+		page.HasMore = true
+		last := transactions[len(transactions)-1]
+		cursor := logic.TransactionCursor{TransactionID: last.TransactionId}
+		if last.CreatedAt != nil {
+			cursor.CreatedAt = last.CreatedAt.Time
+		}
+		page.NextCursor = logic.EncodeTransactionCursor(cursor)
+	}
+
+	page.Records, err = tm.convertToTransactionRecords(ctx, transactions)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// buildTransactionFilter 将查询条件转换为 DAO 过滤条件；资金类型与分类的交集为空时 empty 为 true
+func buildTransactionFilter(query *TransactionQuery) (filter *dao.TransactionFilter, empty bool, err error) {
+	if query == nil {
+		return nil, false, gerror.New("查询条件不能为空")
+	}
+
+	filter = &dao.TransactionFilter{
+		UserID:            uint64(query.UserID),
+		TokenID:           uint(query.TokenID),
+		Symbol:            query.TokenSymbol,
+		RelatedEntityID:   uint64(query.RelatedEntityID),
+		RelatedEntityType: query.RelatedEntityType,
+		TargetUserID:      uint(query.CounterpartyID),
+		RequestSource:     query.RequestSource,
+		Ascending:         query.Ascending,
+	}
+
+	if query.Direction != "" {
+		if query.Direction != constants.FundDirectionIn && query.Direction != constants.FundDirectionOut {
+			return nil, false, gerror.Newf("无效的资金方向: %s", query.Direction)
+		}
+		filter.Direction = string(query.Direction)
+	}
+
+	// 资金类型与分类同时指定时取交集
+	for _, fundType := range query.FundTypes {
+		if !constants.IsValidFundType(fundType) {
+			return nil, false, gerror.Newf("无效的资金类型: %s", fundType)
+		}
+	}
+	types := query.FundTypes
+	if query.FundCategory != "" {
+		categoryTypes := constants.GetFundTypesByCategory(query.FundCategory)
+		if len(categoryTypes) == 0 {
+			return nil, false, gerror.Newf("无效的资金类型分类: %s", query.FundCategory)
+		}
+		if len(types) == 0 {
+			types = categoryTypes
+		} else {
+			inCategory := make(map[constants.FundType]bool, len(categoryTypes))
+			for _, fundType := range categoryTypes {
+				inCategory[fundType] = true
+			}
+			var intersection []constants.FundType
+			for _, fundType := range types {
+				if inCategory[fundType] {
+					intersection = append(intersection, fundType)
+				}
+			}
+			if len(intersection) == 0 {
+				return filter, true, nil
+			}
+			types = intersection
+		}
+	}
+	for _, fundType := range types {
+		filter.Types = append(filter.Types, string(fundType))
+	}
+
+	seenStatus := make(map[uint]bool)
+	for _, status := range query.Statuses {
+		if !constants.IsValidTransactionStatus(status) {
+			return nil, false, gerror.Newf("无效的交易状态: %s", status)
+		}
+		value := uint(statusToInt(status))
+		if !seenStatus[value] {
+			seenStatus[value] = true
+			filter.Statuses = append(filter.Statuses, value)
+		}
+	}
+
+	if query.MinAmount != "" {
+		amount, err := decimal.NewFromString(query.MinAmount)
+		if err != nil {
+			return nil, false, gerror.Wrapf(err, "无效的最小金额: %s", query.MinAmount)
+		}
+		filter.MinAmount = &amount
+	}
+	if query.MaxAmount != "" {
+		amount, err := decimal.NewFromString(query.MaxAmount)
+		if err != nil {
+			return nil, false, gerror.Wrapf(err, "无效的最大金额: %s", query.MaxAmount)
+		}
+		filter.MaxAmount = &amount
+	}
+	if filter.MinAmount != nil && filter.MaxAmount != nil && filter.MinAmount.GreaterThan(*filter.MaxAmount) {
+		return nil, false, gerror.Newf("最小金额不能大于最大金额: %s > %s", query.MinAmount, query.MaxAmount)
+	}
+
+	if query.StartTime != nil {
+		filter.StartTime = gtime.New(*query.StartTime)
+	}
+	if query.EndTime != nil {
+		filter.EndTime = gtime.New(*query.EndTime)
+	}
+	if query.StartTime != nil && query.EndTime != nil && !query.StartTime.Before(*query.EndTime) {
+		return nil, false, gerror.New("开始时间必须早于结束时间")
+	}
+
+	if len(query.Tags) > 0 {
+		tags, err := logic.NewTransactionValidator().NormalizeTags(query.Tags)
+		if err != nil {
+			return nil, false, gerror.Wrap(err, "交易标签验证失败")
+		}
+		filter.Tags = tags
+	}
+	for _, key := range query.MetadataKeys {
+		if err := logic.ValidateMetadataKey(key); err != nil {
+			return nil, false, err
+		}
+	}
+	filter.MetadataKeys = query.MetadataKeys
+	for key := range query.Metadata {
+		if err := logic.ValidateMetadataKey(key); err != nil {
+			return nil, false, err
+		}
+	}
+	filter.Metadata = query.Metadata
+
+	if query.Cursor != "" {
+		cursor, err := logic.DecodeTransactionCursor(query.Cursor)
+		if err != nil {
+			return nil, false, err
+		}
+		filter.AfterCreatedAt = gtime.New(cursor.CreatedAt)
+		filter.AfterID = cursor.TransactionID
+	}
+
+	return filter, false, nil
+}
@@ -0,0 +1,60 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yalks/wallet/constants"
+)
+
+// TestBuildTransactionFilter verifies query validation and fund type / category handling
+func TestBuildTransactionFilter(t *testing.T) {
+	filter, empty, err := buildTransactionFilter(&TransactionQuery{
+		UserID:       1001,
+		FundCategory: "transfer",
+		FundTypes:    []constants.FundType{constants.FundTypeTransferOut, constants.FundTypeDeposit},
+		Statuses:     []constants.TransactionStatus{constants.TransactionStatusFailed, constants.TransactionStatusCancelled},
+		MinAmount:    "10",
+		MaxAmount:    "100",
+		Tags:         []string{" promo ", "promo"},
+	})
+	if err != nil || empty {
+		t.Fatalf("unexpected result: empty=%v, err=%v", empty, err)
+	}
+	if len(filter.Types) != 1 || filter.Types[0] != string(constants.FundTypeTransferOut) {
+		t.Errorf("expected intersection [transfer_out], got %v", filter.Types)
+	}
+	if len(filter.Statuses) != 1 || filter.Statuses[0] != 0 {
+		t.Errorf("expected deduplicated statuses [0], got %v", filter.Statuses)
+	}
+	if len(filter.Tags) != 1 || filter.Tags[0] != "promo" {
+		t.Errorf("expected normalized tags [promo], got %v", filter.Tags)
+	}
+
+	_, empty, err = buildTransactionFilter(&TransactionQuery{
+		FundCategory: "red_packet",
+		FundTypes:    []constants.FundType{constants.FundTypeDeposit},
+	})
+	if err != nil || !empty {
+		t.Errorf("expected empty intersection, got empty=%v, err=%v", empty, err)
+	}
+
+	now := time.Now()
+	invalid := []*TransactionQuery{
+		nil,
+		{Direction: "sideways"},
+		{FundTypes: []constants.FundType{"unknown"}},
+		{FundCategory: "unknown"},
+		{MinAmount: "100", MaxAmount: "10"},
+		{MinAmount: "abc"},
+		{StartTime: &now, EndTime: &now},
+		{MetadataKeys: []string{"a.b"}},
+		{Metadata: map[string]string{"$": "x"}},
+		{Cursor: "not-a-cursor"},
+	}
+	for i, query := range invalid {
+		if _, _, err := buildTransactionFilter(query); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
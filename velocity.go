@@ -0,0 +1,31 @@
+package wallet
+
+import (
+	"context"
+
+	"github.com/yalks/wallet/logic"
+)
+
+// VelocityUsage 用户在某条限额规则下的当前用量
+type VelocityUsage = logic.VelocityUsage
+
+// VelocityLimitError 超出交易限额错误，ResetAt 为本次交易最早可以通过的时间
+type VelocityLimitError = logic.VelocityLimitError
+
+// CodeVelocityLimit 超出交易限额错误码，可通过 gerror.Code(err) 识别
+var CodeVelocityLimit = logic.CodeVelocityLimit
+
+// GetVelocityUsage 获取用户某代币在各条适用限额规则下的当前用量
+func (m *walletManager) GetVelocityUsage(ctx context.Context, userID uint64, tokenSymbol string) ([]*VelocityUsage, error) {
+	return m.velocityLogic.GetUsage(ctx, userID, tokenSymbol)
+}
+
+// RebuildVelocityCounters 按交易表重建用户某代币的限额计数器（启用限额或修改交易状态后用于校正）
+func (m *walletManager) RebuildVelocityCounters(ctx context.Context, userID uint64, tokenSymbol string) error {
+	return m.velocityLogic.Rebuild(ctx, userID, tokenSymbol)
+}
+
+// PurgeVelocityCounters 清理已移出所有限额窗口的计数器，返回删除数量
+func (m *walletManager) PurgeVelocityCounters(ctx context.Context) (int64, error) {
+	return m.velocityLogic.Purge(ctx)
+}
@@ -0,0 +1,322 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/os/gtimer"
+
+	"github.com/yalks/wallet/constants"
+	"github.com/yalks/wallet/entity"
+	"github.com/yalks/wallet/logic"
+)
+
+// Webhook 事件类型
+const (
+	WebhookEventTransactionCompleted = "transaction.completed" // 交易执行成功
+	WebhookEventTransactionFailed    = "transaction.failed"    // 交易执行失败
+)
+
+// WebhookConfig Webhook 投递配置（对应配置项 wallet.webhook）
+type WebhookConfig struct {
+	Secret       string        `json:"secret"`       // HMAC 签名密钥
+	PollInterval time.Duration `json:"pollInterval"` // 扫描待投递记录的间隔
+	BatchSize    int           `json:"batchSize"`    // 每次扫描投递的最大数量
+	MaxAttempts  int           `json:"maxAttempts"`  // 最大尝试次数，超过后移入死信
+	BaseBackoff  time.Duration `json:"baseBackoff"`  // 首次重试等待时间，之后按指数增长
+	MaxBackoff   time.Duration `json:"maxBackoff"`   // 最大重试等待时间
+	Timeout      time.Duration `json:"timeout"`      // 单次请求超时
+}
+
+// DefaultWebhookConfig 默认 Webhook 投递配置
+func DefaultWebhookConfig() WebhookConfig {
+	return WebhookConfig{
+		PollInterval: 5 * time.Second,
+		BatchSize:    50,
+		MaxAttempts:  8,
+		BaseBackoff:  10 * time.Second,
+		MaxBackoff:   time.Hour,
+		Timeout:      10 * time.Second,
+	}
+}
+
+// webhookDispatcher Webhook 异步投递器
+type webhookDispatcher struct {
+	context *logic.SharedLogicContext
+	config  WebhookConfig
+	client  *http.Client
+
+	mu    sync.Mutex
+	entry *gtimer.Entry
+}
+
+// newWebhookDispatcher 创建 Webhook 投递器
+func newWebhookDispatcher(ctx context.Context) *webhookDispatcher {
+	config := loadWebhookConfig(ctx)
+	return &webhookDispatcher{
+		context: logic.GetSharedContext(),
+		config:  config,
+		client:  &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// loadWebhookConfig 从配置中读取 Webhook 配置，缺省项使用默认值
+func loadWebhookConfig(ctx context.Context) WebhookConfig {
+	config := DefaultWebhookConfig()
+
+	value, err := g.Cfg().Get(ctx, "wallet.webhook")
+	if err != nil || value == nil || value.IsEmpty() {
+		return config
+	}
+
+	raw := value.MapStrVar()
+	if v, ok := raw["secret"]; ok {
+		config.Secret = v.String()
+	}
+	durations := map[string]*time.Duration{
+		"pollInterval": &config.PollInterval,
+		"baseBackoff":  &config.BaseBackoff,
+		"maxBackoff":   &config.MaxBackoff,
+		"timeout":      &config.Timeout,
+	}
+	for key, target := range durations {
+		if v, ok := raw[key]; ok {
+			if d, err := time.ParseDuration(v.String()); err == nil && d > 0 {
+				*target = d
+			}
+		}
+	}
+	if v, ok := raw["batchSize"]; ok && v.Int() > 0 {
+		config.BatchSize = v.Int()
+	}
+	if v, ok := raw["maxAttempts"]; ok && v.Int() > 0 {
+		config.MaxAttempts = v.Int()
+	}
+
+	return config
+}
+
+// enqueueWebhookInTx 将回调写入出站表，tx 不为空时与业务操作在同一事务中提交；未设置回调地址时忽略
+func enqueueWebhookInTx(ctx context.Context, tx gdb.TX, callback map[string]string, event *WebhookEvent) error {
+	url := callback[constants.CallbackKeyURL]
+	if url == "" {
+		return nil
+	}
+	method := callback[constants.CallbackKeyMethod]
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	event.OccurredAt = gtime.Now().Format("c")
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return gerror.Wrap(err, "序列化Webhook负载失败")
+	}
+
+	now := gtime.Now()
+	_, err = logic.GetSharedContext().GetWebhookDAO().CreateOutbox(ctx, tx, &entity.WebhookOutbox{
+		Event:         event.Event,
+		BusinessId:    event.BusinessID,
+		Url:           url,
+		Method:        method,
+		Payload:       string(payload),
+		Status:        "pending",
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+	return err
+}
+
+// DeliverPendingWebhooks 投递所有到期的 Webhook，返回本次投递成功的数量
+func (m *walletManager) DeliverPendingWebhooks(ctx context.Context) (int, error) {
+	return m.webhook.deliverDue(ctx)
+}
+
+// StartWebhookDispatcher 启动后台 Webhook 投递
+func (m *walletManager) StartWebhookDispatcher(ctx context.Context) error {
+	return m.webhook.start(ctx)
+}
+
+// StopWebhookDispatcher 停止后台 Webhook 投递
+func (m *walletManager) StopWebhookDispatcher(ctx context.Context) {
+	m.webhook.stop(ctx)
+}
+
+// ListWebhookDeadLetters 获取投递失败的 Webhook 死信
+func (m *walletManager) ListWebhookDeadLetters(ctx context.Context, limit, offset int) ([]*WebhookDeadLetterInfo, error) {
+	records, err := m.webhook.context.GetWebhookDAO().ListDeadLetters(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*WebhookDeadLetterInfo, 0, len(records))
+	for _, record := range records {
+		info := &WebhookDeadLetterInfo{
+			ID:             record.Id,
+			Event:          record.Event,
+			BusinessID:     record.BusinessId,
+			URL:            record.Url,
+			Method:         record.Method,
+			Payload:        record.Payload,
+			Attempts:       record.Attempts,
+			LastStatusCode: record.LastStatusCode,
+			LastError:      record.LastError,
+		}
+		if record.CreatedAt != nil {
+			info.CreatedAt = record.CreatedAt.String()
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// RequeueWebhookDeadLetter 将死信重新放回投递队列
+func (m *walletManager) RequeueWebhookDeadLetter(ctx context.Context, deadLetterID uint64) error {
+	if err := m.webhook.context.GetWebhookDAO().RequeueDeadLetter(ctx, deadLetterID); err != nil {
+		return gerror.Wrap(err, "重新投递Webhook死信失败")
+	}
+	g.Log().Infof(ctx, "Webhook死信已重新排队: ID=%d", deadLetterID)
+	return nil
+}
+
+// start 启动后台投递
+func (d *webhookDispatcher) start(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.entry != nil {
+		return gerror.New("Webhook投递器已启动")
+	}
+	if d.config.Secret == "" {
+		return gerror.New("未配置 Webhook 签名密钥 (wallet.webhook.secret)")
+	}
+
+	d.entry = gtimer.AddSingleton(ctx, d.config.PollInterval, func(ctx context.Context) {
+		if _, err := d.deliverDue(ctx); err != nil {
+			g.Log().Errorf(ctx, "投递Webhook失败: %v", err)
+		}
+	})
+
+	g.Log().Infof(ctx, "Webhook投递器已启动: PollInterval=%s, MaxAttempts=%d", d.config.PollInterval, d.config.MaxAttempts)
+	return nil
+}
+
+// stop 停止后台投递
+func (d *webhookDispatcher) stop(ctx context.Context) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.entry != nil {
+		d.entry.Close()
+		d.entry = nil
+		g.Log().Info(ctx, "Webhook投递器已停止")
+	}
+}
+
+// deliverDue 投递到期的出站记录
+func (d *webhookDispatcher) deliverDue(ctx context.Context) (int, error) {
+	if d.config.Secret == "" {
+		return 0, gerror.New("未配置 Webhook 签名密钥 (wallet.webhook.secret)")
+	}
+
+	dao := d.context.GetWebhookDAO()
+	records, err := dao.ListDueOutbox(ctx, gtime.Now(), d.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, record := range records {
+		// 租约期内其他实例不会重复投递；进程异常退出时租约到期后自动重新投递（至少一次）
+		leaseUntil := gtime.Now().Add(2 * d.config.Timeout)
+		claimed, err := dao.ClaimOutbox(ctx, record.Id, record.Attempts, leaseUntil)
+		if err != nil {
+			g.Log().Errorf(ctx, "抢占Webhook出站记录失败: ID=%d, Error=%v", record.Id, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		record.Attempts++
+
+		if d.deliver(ctx, record) {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// deliver 投递单条记录并记录结果，返回是否投递成功
+func (d *webhookDispatcher) deliver(ctx context.Context, record *entity.WebhookOutbox) bool {
+	dao := d.context.GetWebhookDAO()
+
+	statusCode, err := logic.DeliverWebhook(ctx, d.client, d.config.Secret, &logic.WebhookDelivery{
+		ID:      logic.WebhookDeliveryID(record.Id),
+		Event:   record.Event,
+		URL:     record.Url,
+		Method:  record.Method,
+		Payload: []byte(record.Payload),
+	}, time.Now())
+
+	if err == nil {
+		if updateErr := dao.UpdateOutbox(ctx, record.Id, map[string]any{
+			"status":           "delivered",
+			"last_status_code": statusCode,
+			"last_error":       "",
+			"delivered_at":     gtime.Now(),
+		}); updateErr != nil {
+			g.Log().Errorf(ctx, "更新Webhook投递状态失败: ID=%d, Error=%v", record.Id, updateErr)
+		}
+		return true
+	}
+
+	record.LastStatusCode = statusCode
+	record.LastError = err.Error()
+
+	if record.Attempts >= d.config.MaxAttempts {
+		if moveErr := dao.MoveToDeadLetter(ctx, record); moveErr != nil {
+			g.Log().Errorf(ctx, "Webhook移入死信失败: ID=%d, Error=%v", record.Id, moveErr)
+			return false
+		}
+		g.Log().Errorf(ctx, "Webhook投递失败已移入死信: ID=%d, URL=%s, Attempts=%d, Error=%v", record.Id, record.Url, record.Attempts, err)
+		return false
+	}
+
+	nextAttemptAt := gtime.Now().Add(logic.WebhookBackoff(record.Attempts, d.config.BaseBackoff, d.config.MaxBackoff))
+	if updateErr := dao.UpdateOutbox(ctx, record.Id, map[string]any{
+		"next_attempt_at":  nextAttemptAt,
+		"last_status_code": statusCode,
+		"last_error":       record.LastError,
+	}); updateErr != nil {
+		g.Log().Errorf(ctx, "更新Webhook重试时间失败: ID=%d, Error=%v", record.Id, updateErr)
+	}
+	g.Log().Warningf(ctx, "Webhook投递失败，稍后重试: ID=%d, Attempts=%d, NextAttemptAt=%s, Error=%v",
+		record.Id, record.Attempts, nextAttemptAt.String(), err)
+	return false
+}
+
+// encodeCallback 序列化回调信息，未设置回调地址时返回空字符串
+func encodeCallback(callback map[string]string) string {
+	if callback[constants.CallbackKeyURL] == "" {
+		return ""
+	}
+	data, _ := json.Marshal(callback)
+	return string(data)
+}
+
+// decodeCallback 解析保存的回调信息
+func decodeCallback(raw string) map[string]string {
+	callback := make(map[string]string)
+	if raw != "" {
+		_ = json.Unmarshal([]byte(raw), &callback)
+	}
+	return callback
+}
@@ -0,0 +1,86 @@
+package wallet
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/os/gtime"
+
+	"github.com/yalks/wallet/entity"
+)
+
+// AddWithdrawAddress 添加提现地址（新地址需经过冷静期才能用于白名单提现）
+func (m *walletManager) AddWithdrawAddress(ctx context.Context, userID uint64, tokenSymbol, address, label string) (*WithdrawAddressInfo, error) {
+	record, err := m.withdrawAddressLogic.AddAddress(ctx, userID, tokenSymbol, address, label)
+	if err != nil {
+		return nil, gerror.Wrap(err, "添加提现地址失败")
+	}
+	return convertToWithdrawAddressInfo(record), nil
+}
+
+// RemoveWithdrawAddress 删除提现地址
+func (m *walletManager) RemoveWithdrawAddress(ctx context.Context, userID uint64, addressID uint64) error {
+	if err := m.withdrawAddressLogic.RemoveAddress(ctx, userID, addressID); err != nil {
+		return gerror.Wrap(err, "删除提现地址失败")
+	}
+	return nil
+}
+
+// ListWithdrawAddresses 获取用户地址簿
+func (m *walletManager) ListWithdrawAddresses(ctx context.Context, userID uint64, network string) ([]*WithdrawAddressInfo, error) {
+	records, err := m.withdrawAddressLogic.ListAddresses(ctx, userID, network)
+	if err != nil {
+		return nil, gerror.Wrap(err, "获取提现地址簿失败")
+	}
+
+	infos := make([]*WithdrawAddressInfo, 0, len(records))
+	for _, record := range records {
+		infos = append(infos, convertToWithdrawAddressInfo(record))
+	}
+	return infos, nil
+}
+
+// SetWithdrawWhitelistOnly 开启或关闭仅白名单提现模式（关闭在冷静期结束后生效）
+func (m *walletManager) SetWithdrawWhitelistOnly(ctx context.Context, userID uint64, enabled bool) error {
+	if err := m.withdrawAddressLogic.SetWhitelistOnly(ctx, userID, enabled); err != nil {
+		return gerror.Wrap(err, "设置提现白名单模式失败")
+	}
+	return nil
+}
+
+// validateWithdrawDestination 校验提现目标地址
+func (m *walletManager) validateWithdrawDestination(ctx context.Context, userID uint64, tokenSymbol, address string) error {
+	if address == "" {
+		return gerror.New("提现操作必须在元数据中提供目标地址 (address)")
+	}
+
+	token, err := m.tokenLogic.GetTokenBySymbol(ctx, tokenSymbol)
+	if err != nil {
+		return gerror.Wrapf(err, "获取代币信息失败: Symbol=%s", tokenSymbol)
+	}
+
+	if err := m.withdrawAddressLogic.ValidateWithdrawDestination(ctx, userID, token, address); err != nil {
+		return gerror.Wrap(err, "提现地址校验失败")
+	}
+	return nil
+}
+
+// convertToWithdrawAddressInfo 转换实体为地址簿条目
+func convertToWithdrawAddressInfo(record *entity.WithdrawAddresses) *WithdrawAddressInfo {
+	info := &WithdrawAddressInfo{
+		ID:            record.Id,
+		UserID:        uint64(record.UserId),
+		Network:       record.Network,
+		TokenStandard: record.TokenStandard,
+		Address:       record.Address,
+		Label:         record.Label,
+		Active:        record.ActivatedAt == nil || !gtime.Now().Before(record.ActivatedAt),
+	}
+	if record.ActivatedAt != nil {
+		info.ActivatedAt = record.ActivatedAt.String()
+	}
+	if record.CreatedAt != nil {
+		info.CreatedAt = record.CreatedAt.String()
+	}
+	return info
+}